@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package convert converts objects of the resource.k8s.io API group
+// between the different API versions. v1 is the hub: conversions between
+// two older versions go through v1.
+//
+// ResourceSlice, DeviceClass, ResourceClaim and ResourceClaimTemplate
+// (and their lists) are supported in v1beta1, v1beta2 and v1. v1alpha3
+// only has DeviceTaintRule, which does not exist in any other version,
+// so for that version only the embedded DeviceTaint can be converted.
+package convert
+
+import (
+	"fmt"
+	"reflect"
+
+	resourceapi "k8s.io/api/resource/v1"
+	resourcev1alpha3 "k8s.io/api/resource/v1alpha3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	drav1beta1 "k8s.io/dynamic-resource-allocation/api/v1beta1"
+	drav1beta2 "k8s.io/dynamic-resource-allocation/api/v1beta2"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(resourceapi.AddToScheme(scheme))
+	utilruntime.Must(drav1beta1.AddToScheme(scheme))
+	utilruntime.Must(drav1beta2.AddToScheme(scheme))
+	utilruntime.Must(resourcev1alpha3.AddToScheme(scheme))
+}
+
+// Convert converts in into out. Both must be pointers to the same kind of
+// object, for example a *v1beta1.ResourceSlice and a *v1.ResourceSlice.
+// If they have the same type, out becomes a deep copy of in.
+func Convert(in, out runtime.Object) error {
+	inGVK, err := kindOf(in)
+	if err != nil {
+		return err
+	}
+	outGVK, err := kindOf(out)
+	if err != nil {
+		return err
+	}
+	if inGVK.GroupKind() != outGVK.GroupKind() {
+		return fmt.Errorf("cannot convert %s into %s", inGVK.Kind, outGVK.Kind)
+	}
+	switch {
+	case inGVK.Version == outGVK.Version:
+		reflect.ValueOf(out).Elem().Set(reflect.ValueOf(in.DeepCopyObject()).Elem())
+		return nil
+	case inGVK.Version == resourceapi.SchemeGroupVersion.Version || outGVK.Version == resourceapi.SchemeGroupVersion.Version:
+		return scheme.Convert(in, out, nil)
+	default:
+		hub, err := scheme.New(resourceapi.SchemeGroupVersion.WithKind(inGVK.Kind))
+		if err != nil {
+			return fmt.Errorf("convert %s %s to %s: %w", inGVK.Kind, inGVK.Version, outGVK.Version, err)
+		}
+		if err := scheme.Convert(in, hub, nil); err != nil {
+			return err
+		}
+		return scheme.Convert(hub, out, nil)
+	}
+}
+
+// To converts the object into a new object of type T, for example:
+//
+//	slice, err := convert.To[resourceapi.ResourceSlice](v1beta1Slice)
+func To[T any, TP interface {
+	*T
+	runtime.Object
+}](in runtime.Object) (*T, error) {
+	out := TP(new(T))
+	if err := Convert(in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeviceTaintFromV1Alpha3 converts the taint of a v1alpha3 DeviceTaintRule
+// into the v1 type used in devices.
+func DeviceTaintFromV1Alpha3(in resourcev1alpha3.DeviceTaint) resourceapi.DeviceTaint {
+	return resourceapi.DeviceTaint{
+		Key:       in.Key,
+		Value:     in.Value,
+		Effect:    resourceapi.DeviceTaintEffect(in.Effect),
+		TimeAdded: in.TimeAdded,
+	}
+}
+
+// DeviceTaintToV1Alpha3 is the reverse of [DeviceTaintFromV1Alpha3].
+func DeviceTaintToV1Alpha3(in resourceapi.DeviceTaint) resourcev1alpha3.DeviceTaint {
+	return resourcev1alpha3.DeviceTaint{
+		Key:       in.Key,
+		Value:     in.Value,
+		Effect:    resourcev1alpha3.DeviceTaintEffect(in.Effect),
+		TimeAdded: in.TimeAdded,
+	}
+}
+
+func kindOf(obj runtime.Object) (schema.GroupVersionKind, error) {
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return gvks[0], nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	resourceapi "k8s.io/api/resource/v1"
+	resourcev1alpha3 "k8s.io/api/resource/v1alpha3"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	resourcev1beta2 "k8s.io/api/resource/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestConvert(t *testing.T) {
+	v1beta1Claim := &resourcev1beta1.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim"},
+		Spec: resourcev1beta1.ResourceClaimSpec{
+			Devices: resourcev1beta1.DeviceClaim{
+				Requests: []resourcev1beta1.DeviceRequest{{
+					Name:            "req",
+					DeviceClassName: "class",
+					AllocationMode:  resourcev1beta1.DeviceAllocationModeExactCount,
+					Count:           2,
+				}},
+			},
+		},
+	}
+	v1beta2Claim := &resourcev1beta2.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim"},
+		Spec: resourcev1beta2.ResourceClaimSpec{
+			Devices: resourcev1beta2.DeviceClaim{
+				Requests: []resourcev1beta2.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourcev1beta2.ExactDeviceRequest{
+						DeviceClassName: "class",
+						AllocationMode:  resourcev1beta2.DeviceAllocationModeExactCount,
+						Count:           2,
+					},
+				}},
+			},
+		},
+	}
+	v1Claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: "class",
+						AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+						Count:           2,
+					},
+				}},
+			},
+		},
+	}
+	v1SliceList := &resourceapi.ResourceSliceList{
+		Items: []resourceapi.ResourceSlice{{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   "driver",
+				Pool:     resourceapi.ResourcePool{Name: "pool"},
+				AllNodes: ptr.To(true),
+				Devices:  []resourceapi.Device{{Name: "dev"}},
+			},
+		}},
+	}
+	v1beta1SliceList := &resourcev1beta1.ResourceSliceList{
+		Items: []resourcev1beta1.ResourceSlice{{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+			Spec: resourcev1beta1.ResourceSliceSpec{
+				Driver:   "driver",
+				Pool:     resourcev1beta1.ResourcePool{Name: "pool"},
+				AllNodes: true,
+				Devices:  []resourcev1beta1.Device{{Name: "dev", Basic: &resourcev1beta1.BasicDevice{}}},
+			},
+		}},
+	}
+
+	testcases := map[string]struct {
+		in        runtime.Object
+		out       runtime.Object
+		expectOut runtime.Object
+		expectErr bool
+	}{
+		"v1beta1-to-v1": {
+			in:        v1beta1Claim,
+			out:       &resourceapi.ResourceClaim{},
+			expectOut: v1Claim,
+		},
+		"v1-to-v1beta2": {
+			in:        v1Claim,
+			out:       &resourcev1beta2.ResourceClaim{},
+			expectOut: v1beta2Claim,
+		},
+		"v1beta1-to-v1beta2": {
+			in:        v1beta1Claim,
+			out:       &resourcev1beta2.ResourceClaim{},
+			expectOut: v1beta2Claim,
+		},
+		"v1beta2-to-v1beta1": {
+			in:        v1beta2Claim,
+			out:       &resourcev1beta1.ResourceClaim{},
+			expectOut: v1beta1Claim,
+		},
+		"same-version": {
+			in:        v1Claim,
+			out:       &resourceapi.ResourceClaim{},
+			expectOut: v1Claim,
+		},
+		"list": {
+			in:        v1SliceList,
+			out:       &resourcev1beta1.ResourceSliceList{},
+			expectOut: v1beta1SliceList,
+		},
+		"different-kind": {
+			in:        v1Claim,
+			out:       &resourcev1beta1.ResourceSlice{},
+			expectErr: true,
+		},
+		"no-hub": {
+			in:        &resourcev1alpha3.DeviceTaintRule{},
+			out:       &resourcev1beta1.ResourceClaim{},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := Convert(tc.in, tc.out)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectOut, tc.out); diff != "" {
+				t.Fatalf("unexpected result (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("to", func(t *testing.T) {
+		claim, err := To[resourceapi.ResourceClaim](v1beta1Claim)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(v1Claim, claim); diff != "" {
+			t.Fatalf("unexpected result (-want, +got):\n%s", diff)
+		}
+	})
+}
+
+func TestDeviceTaint(t *testing.T) {
+	taint := resourcev1alpha3.DeviceTaint{
+		Key:       "example.com/taint",
+		Value:     "value",
+		Effect:    resourcev1alpha3.DeviceTaintEffectNoExecute,
+		TimeAdded: &metav1.Time{},
+	}
+	converted := DeviceTaintFromV1Alpha3(taint)
+	if converted.Effect != resourceapi.DeviceTaintEffectNoExecute {
+		t.Errorf("unexpected effect %q", converted.Effect)
+	}
+	if diff := cmp.Diff(taint, DeviceTaintToV1Alpha3(converted)); diff != "" {
+		t.Fatalf("round trip failed (-want, +got):\n%s", diff)
+	}
+}
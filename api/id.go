@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// PoolID identifies a pool of devices. Because the names are interned,
+// comparing PoolIDs is cheap and they are efficient map keys.
+type PoolID struct {
+	Driver, Pool UniqueString
+}
+
+// MakePoolID interns the names and returns the ID.
+func MakePoolID(driver, pool string) PoolID {
+	return PoolID{
+		Driver: MakeUniqueString(driver),
+		Pool:   MakeUniqueString(pool),
+	}
+}
+
+// ParsePoolID is the reverse of [PoolID.String]. Driver names cannot
+// contain a slash, so everything after the first slash is the pool name.
+func ParsePoolID(id string) (PoolID, error) {
+	driver, pool, ok := strings.Cut(id, "/")
+	if !ok || driver == "" || pool == "" {
+		return PoolID{}, fmt.Errorf("pool ID must have the format <driver>/<pool>: %q", id)
+	}
+	return MakePoolID(driver, pool), nil
+}
+
+// String returns "<driver>/<pool>".
+func (p PoolID) String() string {
+	return p.Driver.String() + "/" + p.Pool.String()
+}
+
+// Compare orders PoolIDs by driver name, then by pool name. The
+// result is -1, 0 or +1 like for [strings.Compare].
+func (p PoolID) Compare(other PoolID) int {
+	if p == other {
+		return 0
+	}
+	return cmp.Or(
+		strings.Compare(p.Driver.String(), other.Driver.String()),
+		strings.Compare(p.Pool.String(), other.Pool.String()),
+	)
+}
+
+// DeviceID identifies a device. Because the names are interned,
+// comparing DeviceIDs is cheap and they are efficient map keys.
+type DeviceID struct {
+	Driver, Pool, Device UniqueString
+}
+
+// MakeDeviceID interns the names and returns the ID.
+func MakeDeviceID(driver, pool, device string) DeviceID {
+	return DeviceID{
+		Driver: MakeUniqueString(driver),
+		Pool:   MakeUniqueString(pool),
+		Device: MakeUniqueString(device),
+	}
+}
+
+// ParseDeviceID is the reverse of [DeviceID.String]. Pool names may
+// contain slashes, but driver and device names cannot, so the driver name
+// ends at the first slash and the device name starts after the last one.
+func ParseDeviceID(id string) (DeviceID, error) {
+	driver, rest, ok := strings.Cut(id, "/")
+	index := strings.LastIndex(rest, "/")
+	if !ok || index < 0 || driver == "" || rest[:index] == "" || rest[index+1:] == "" {
+		return DeviceID{}, fmt.Errorf("device ID must have the format <driver>/<pool>/<device>: %q", id)
+	}
+	return MakeDeviceID(driver, rest[:index], rest[index+1:]), nil
+}
+
+// String returns "<driver>/<pool>/<device>".
+func (d DeviceID) String() string {
+	return d.Driver.String() + "/" + d.Pool.String() + "/" + d.Device.String()
+}
+
+// PoolID returns the ID of the pool that the device belongs to.
+func (d DeviceID) PoolID() PoolID {
+	return PoolID{Driver: d.Driver, Pool: d.Pool}
+}
+
+// Compare orders DeviceIDs by pool, then by device name. The
+// result is -1, 0 or +1 like for [strings.Compare].
+func (d DeviceID) Compare(other DeviceID) int {
+	if d == other {
+		return 0
+	}
+	return cmp.Or(
+		d.PoolID().Compare(other.PoolID()),
+		strings.Compare(d.Device.String(), other.Device.String()),
+	)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDeviceID(t *testing.T) {
+	testcases := map[string]struct {
+		id        string
+		expectID  DeviceID
+		expectErr bool
+	}{
+		"simple": {
+			id:       "driver.example.com/pool/device",
+			expectID: MakeDeviceID("driver.example.com", "pool", "device"),
+		},
+		"pool-with-slash": {
+			id:       "driver.example.com/node.example.com/sub/device",
+			expectID: MakeDeviceID("driver.example.com", "node.example.com/sub", "device"),
+		},
+		"missing-device": {
+			id:        "driver.example.com/pool",
+			expectErr: true,
+		},
+		"empty-pool": {
+			id:        "driver.example.com//device",
+			expectErr: true,
+		},
+		"empty": {
+			expectErr: true,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			id, err := ParseDeviceID(tc.id)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != tc.expectID {
+				t.Fatalf("expected %v, got %v", tc.expectID, id)
+			}
+			if id.String() != tc.id {
+				t.Fatalf("expected String to return %q, got %q", tc.id, id.String())
+			}
+		})
+	}
+}
+
+func TestPoolID(t *testing.T) {
+	id, err := ParsePoolID("driver.example.com/node.example.com/sub")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != MakePoolID("driver.example.com", "node.example.com/sub") {
+		t.Fatalf("unexpected pool ID %v", id)
+	}
+	if id != MakeDeviceID("driver.example.com", "node.example.com/sub", "device").PoolID() {
+		t.Fatal("pool ID of device does not match")
+	}
+	for _, invalid := range []string{"", "driver", "/pool", "driver/"} {
+		if _, err := ParsePoolID(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ids := []DeviceID{
+		MakeDeviceID("b", "pool", "dev-0"),
+		MakeDeviceID("a", "pool-b", "dev-0"),
+		MakeDeviceID("a", "pool-a", "dev-1"),
+		MakeDeviceID("a", "pool-a", "dev-0"),
+	}
+	slices.SortFunc(ids, DeviceID.Compare)
+	expect := []DeviceID{
+		MakeDeviceID("a", "pool-a", "dev-0"),
+		MakeDeviceID("a", "pool-a", "dev-1"),
+		MakeDeviceID("a", "pool-b", "dev-0"),
+		MakeDeviceID("b", "pool", "dev-0"),
+	}
+	if !slices.Equal(ids, expect) {
+		t.Fatalf("expected %v, got %v", expect, ids)
+	}
+	if ids[0].Compare(MakeDeviceID("a", "pool-a", "dev-0")) != 0 {
+		t.Fatal("expected equal IDs to compare as equal")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestResourceSlice(t *testing.T) {
+	device := MakeDevice("dev").IntAttribute("index", 1).Capacity("memory", "1Gi")
+	slice := MakeResourceSlice("slice").Devices(device.Obj()).Obj()
+	assert.Equal(t, DefaultDriver, slice.Spec.Driver)
+	assert.Equal(t, resourceapi.ResourcePool{Name: DefaultPool, ResourceSliceCount: 1}, slice.Spec.Pool)
+	assert.Equal(t, ptr.To(true), slice.Spec.AllNodes)
+	require.Len(t, slice.Spec.Devices, 1)
+	assert.Equal(t, ptr.To(int64(1)), slice.Spec.Devices[0].Attributes["index"].IntValue)
+	assert.Equal(t, resource.MustParse("1Gi"), slice.Spec.Devices[0].Capacity["memory"].Value)
+
+	// Modifying the wrapper must not affect devices which were already built.
+	device.StringAttribute("model", "x")
+	assert.NotContains(t, slice.Spec.Devices[0].Attributes, resourceapi.QualifiedName("model"))
+
+	slice = MakeResourceSlice("slice").NodeName("worker").Obj()
+	assert.Nil(t, slice.Spec.AllNodes)
+	assert.Equal(t, ptr.To("worker"), slice.Spec.NodeName)
+
+	assert.Equal(t, MakeResourceSlice("slice").Obj(), MakeResourceSlice("slice").Obj(), "deterministic")
+}
+
+func TestDeviceTaintRule(t *testing.T) {
+	taint := MakeDeviceTaint("example.com/taint").Value("tainted").Obj()
+	assert.Equal(t, resourceapi.DeviceTaintEffectNoSchedule, taint.Effect)
+	assert.Equal(t, &DefaultTime, taint.TimeAdded)
+
+	rule := MakeDeviceTaintRule("rule", taint).Obj()
+	assert.Nil(t, rule.Spec.DeviceSelector, "all devices")
+	assert.Equal(t, "tainted", rule.Spec.Taint.Value)
+
+	rule = MakeDeviceTaintRule("rule", taint).Driver(DefaultDriver).Pool(DefaultPool).Selectors("true").Obj()
+	assert.Equal(t, &resourcealphaapi.DeviceTaintSelector{
+		Driver: ptr.To(DefaultDriver),
+		Pool:   ptr.To(DefaultPool),
+		Selectors: []resourcealphaapi.DeviceSelector{{
+			CEL: &resourcealphaapi.CELDeviceSelector{Expression: "true"},
+		}},
+	}, rule.Spec.DeviceSelector)
+}
+
+func TestResourceClaim(t *testing.T) {
+	claim := MakeResourceClaim("claim").
+		Request("req", "class", 2).Selectors("true").
+		Allocated("worker").
+		AllocatedDevice("req", DefaultDriver, DefaultPool, "dev-0").
+		AllocatedDevice("req", DefaultDriver, DefaultPool, "dev-1").
+		ReservedFor("pod", "pod-uid").
+		Obj()
+	assert.Equal(t, DefaultNamespace, claim.Namespace)
+	assert.Equal(t, "claim-uid", string(claim.UID))
+	require.Len(t, claim.Spec.Devices.Requests, 1)
+	assert.Equal(t, int64(2), claim.Spec.Devices.Requests[0].Exactly.Count)
+	assert.Len(t, claim.Spec.Devices.Requests[0].Exactly.Selectors, 1)
+	require.NotNil(t, claim.Status.Allocation)
+	assert.Equal(t, []string{"worker"}, claim.Status.Allocation.NodeSelector.NodeSelectorTerms[0].MatchFields[0].Values)
+	assert.Len(t, claim.Status.Allocation.Devices.Results, 2)
+	assert.Len(t, claim.Status.ReservedFor, 1)
+
+	claim = MakeResourceClaim("claim").AllocatedDevice("req", DefaultDriver, DefaultPool, "dev-0").Obj()
+	assert.Nil(t, claim.Status.Allocation.NodeSelector, "available on all nodes")
+}
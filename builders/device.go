@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builders
+
+import (
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// DeviceWrapper wraps a Device.
+type DeviceWrapper struct {
+	resourceapi.Device
+}
+
+// MakeDevice creates a wrapper for a Device without attributes.
+func MakeDevice(name string) *DeviceWrapper {
+	return &DeviceWrapper{
+		resourceapi.Device{Name: name},
+	}
+}
+
+// Obj returns a copy of the inner Device. Devices are stored by value
+// in a ResourceSlice, so further changes of the wrapper do not affect
+// the result.
+func (d *DeviceWrapper) Obj() resourceapi.Device {
+	return *d.Device.DeepCopy()
+}
+
+func (d *DeviceWrapper) attribute(name resourceapi.QualifiedName, attribute resourceapi.DeviceAttribute) *DeviceWrapper {
+	if d.Attributes == nil {
+		d.Attributes = make(map[resourceapi.QualifiedName]resourceapi.DeviceAttribute)
+	}
+	d.Attributes[name] = attribute
+	return d
+}
+
+// IntAttribute sets an integer attribute in Device.Attributes.
+func (d *DeviceWrapper) IntAttribute(name resourceapi.QualifiedName, value int64) *DeviceWrapper {
+	return d.attribute(name, resourceapi.DeviceAttribute{IntValue: ptr.To(value)})
+}
+
+// StringAttribute sets a string attribute in Device.Attributes.
+func (d *DeviceWrapper) StringAttribute(name resourceapi.QualifiedName, value string) *DeviceWrapper {
+	return d.attribute(name, resourceapi.DeviceAttribute{StringValue: ptr.To(value)})
+}
+
+// BoolAttribute sets a boolean attribute in Device.Attributes.
+func (d *DeviceWrapper) BoolAttribute(name resourceapi.QualifiedName, value bool) *DeviceWrapper {
+	return d.attribute(name, resourceapi.DeviceAttribute{BoolValue: ptr.To(value)})
+}
+
+// VersionAttribute sets a semantic version attribute in Device.Attributes.
+func (d *DeviceWrapper) VersionAttribute(name resourceapi.QualifiedName, value string) *DeviceWrapper {
+	return d.attribute(name, resourceapi.DeviceAttribute{VersionValue: ptr.To(value)})
+}
+
+// Capacity sets an entry in Device.Capacity. The quantity
+// must be valid for [resource.MustParse].
+func (d *DeviceWrapper) Capacity(name resourceapi.QualifiedName, quantity string) *DeviceWrapper {
+	if d.Device.Capacity == nil {
+		d.Device.Capacity = make(map[resourceapi.QualifiedName]resourceapi.DeviceCapacity)
+	}
+	d.Device.Capacity[name] = resourceapi.DeviceCapacity{Value: resource.MustParse(quantity)}
+	return d
+}
+
+// Taints sets the value of Device.Taints.
+func (d *DeviceWrapper) Taints(taints ...resourceapi.DeviceTaint) *DeviceWrapper {
+	d.Device.Taints = taints
+	return d
+}
+
+// ConsumesCounters sets the value of Device.ConsumesCounters.
+func (d *DeviceWrapper) ConsumesCounters(consumption ...resourceapi.DeviceCounterConsumption) *DeviceWrapper {
+	d.Device.ConsumesCounters = consumption
+	return d
+}
+
+// NodeName sets the value of Device.NodeName.
+func (d *DeviceWrapper) NodeName(nodeName string) *DeviceWrapper {
+	d.Device.NodeName = ptr.To(nodeName)
+	return d
+}
+
+// AllowMultipleAllocations sets Device.AllowMultipleAllocations.
+func (d *DeviceWrapper) AllowMultipleAllocations() *DeviceWrapper {
+	d.Device.AllowMultipleAllocations = ptr.To(true)
+	return d
+}
+
+// DeviceTaintWrapper wraps a DeviceTaint.
+type DeviceTaintWrapper struct {
+	resourceapi.DeviceTaint
+}
+
+// MakeDeviceTaint creates a wrapper for a DeviceTaint with the
+// NoSchedule effect, added at [DefaultTime].
+func MakeDeviceTaint(key string) *DeviceTaintWrapper {
+	return &DeviceTaintWrapper{
+		resourceapi.DeviceTaint{
+			Key:       key,
+			Effect:    resourceapi.DeviceTaintEffectNoSchedule,
+			TimeAdded: DefaultTime.DeepCopy(),
+		},
+	}
+}
+
+// Obj returns a copy of the inner DeviceTaint.
+func (t *DeviceTaintWrapper) Obj() resourceapi.DeviceTaint {
+	return *t.DeviceTaint.DeepCopy()
+}
+
+// Value sets the value of DeviceTaint.Value.
+func (t *DeviceTaintWrapper) Value(value string) *DeviceTaintWrapper {
+	t.DeviceTaint.Value = value
+	return t
+}
+
+// Effect sets the value of DeviceTaint.Effect.
+func (t *DeviceTaintWrapper) Effect(effect resourceapi.DeviceTaintEffect) *DeviceTaintWrapper {
+	t.DeviceTaint.Effect = effect
+	return t
+}
+
+// TimeAdded sets the value of DeviceTaint.TimeAdded.
+// A nil time clears it.
+func (t *DeviceTaintWrapper) TimeAdded(timeAdded *metav1.Time) *DeviceTaintWrapper {
+	t.DeviceTaint.TimeAdded = timeAdded
+	return t
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builders
+
+import (
+	resourceapi "k8s.io/api/resource/v1"
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/api/convert"
+	"k8s.io/utils/ptr"
+)
+
+// DeviceTaintRuleWrapper wraps a DeviceTaintRule.
+type DeviceTaintRuleWrapper struct {
+	resourcealphaapi.DeviceTaintRule
+}
+
+// MakeDeviceTaintRule creates a wrapper for a DeviceTaintRule which
+// applies the taint to all devices.
+func MakeDeviceTaintRule(name string, taint resourceapi.DeviceTaint) *DeviceTaintRuleWrapper {
+	return &DeviceTaintRuleWrapper{
+		resourcealphaapi.DeviceTaintRule{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: resourcealphaapi.DeviceTaintRuleSpec{
+				Taint: convert.DeviceTaintToV1Alpha3(taint),
+			},
+		},
+	}
+}
+
+// Obj returns the inner DeviceTaintRule.
+func (r *DeviceTaintRuleWrapper) Obj() *resourcealphaapi.DeviceTaintRule {
+	return &r.DeviceTaintRule
+}
+
+// Annotation sets an annotation of the DeviceTaintRule.
+func (r *DeviceTaintRuleWrapper) Annotation(key, value string) *DeviceTaintRuleWrapper {
+	if r.Annotations == nil {
+		r.Annotations = make(map[string]string)
+	}
+	r.Annotations[key] = value
+	return r
+}
+
+func (r *DeviceTaintRuleWrapper) selector() *resourcealphaapi.DeviceTaintSelector {
+	if r.Spec.DeviceSelector == nil {
+		r.Spec.DeviceSelector = &resourcealphaapi.DeviceTaintSelector{}
+	}
+	return r.Spec.DeviceSelector
+}
+
+// Driver sets the value of DeviceTaintRule.Spec.DeviceSelector.Driver.
+func (r *DeviceTaintRuleWrapper) Driver(driver string) *DeviceTaintRuleWrapper {
+	r.selector().Driver = ptr.To(driver)
+	return r
+}
+
+// Pool sets the value of DeviceTaintRule.Spec.DeviceSelector.Pool.
+func (r *DeviceTaintRuleWrapper) Pool(pool string) *DeviceTaintRuleWrapper {
+	r.selector().Pool = ptr.To(pool)
+	return r
+}
+
+// Device sets the value of DeviceTaintRule.Spec.DeviceSelector.Device.
+func (r *DeviceTaintRuleWrapper) Device(device string) *DeviceTaintRuleWrapper {
+	r.selector().Device = ptr.To(device)
+	return r
+}
+
+// DeviceClassName sets the value of DeviceTaintRule.Spec.DeviceSelector.DeviceClassName.
+func (r *DeviceTaintRuleWrapper) DeviceClassName(deviceClassName string) *DeviceTaintRuleWrapper {
+	r.selector().DeviceClassName = ptr.To(deviceClassName)
+	return r
+}
+
+// Selectors sets DeviceTaintRule.Spec.DeviceSelector.Selectors to one
+// CEL selector per expression.
+func (r *DeviceTaintRuleWrapper) Selectors(expressions ...string) *DeviceTaintRuleWrapper {
+	selectors := make([]resourcealphaapi.DeviceSelector, 0, len(expressions))
+	for _, expression := range expressions {
+		selectors = append(selectors, resourcealphaapi.DeviceSelector{
+			CEL: &resourcealphaapi.CELDeviceSelector{Expression: expression},
+		})
+	}
+	r.selector().Selectors = selectors
+	return r
+}
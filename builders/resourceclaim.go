@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builders
+
+import (
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ResourceClaimWrapper wraps a ResourceClaim.
+type ResourceClaimWrapper struct {
+	resourceapi.ResourceClaim
+}
+
+// MakeResourceClaim creates a wrapper for a ResourceClaim without
+// requests in [DefaultNamespace]. The UID is "<name>-uid" because the
+// fake clientset does not generate UIDs.
+func MakeResourceClaim(name string) *ResourceClaimWrapper {
+	return &ResourceClaimWrapper{
+		resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: DefaultNamespace,
+				Name:      name,
+				UID:       types.UID(name + "-uid"),
+			},
+		},
+	}
+}
+
+// Obj returns the inner ResourceClaim.
+func (c *ResourceClaimWrapper) Obj() *resourceapi.ResourceClaim {
+	return &c.ResourceClaim
+}
+
+// Namespace sets the value of ResourceClaim.ObjectMeta.Namespace.
+func (c *ResourceClaimWrapper) Namespace(namespace string) *ResourceClaimWrapper {
+	c.ObjectMeta.Namespace = namespace
+	return c
+}
+
+// UID sets the value of ResourceClaim.ObjectMeta.UID.
+func (c *ResourceClaimWrapper) UID(uid types.UID) *ResourceClaimWrapper {
+	c.ObjectMeta.UID = uid
+	return c
+}
+
+// Generation sets the value of ResourceClaim.ObjectMeta.Generation.
+func (c *ResourceClaimWrapper) Generation(generation int64) *ResourceClaimWrapper {
+	c.ObjectMeta.Generation = generation
+	return c
+}
+
+// Request adds a request for count devices of the class.
+func (c *ResourceClaimWrapper) Request(name, deviceClassName string, count int64) *ResourceClaimWrapper {
+	c.Spec.Devices.Requests = append(c.Spec.Devices.Requests, resourceapi.DeviceRequest{
+		Name: name,
+		Exactly: &resourceapi.ExactDeviceRequest{
+			DeviceClassName: deviceClassName,
+			AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+			Count:           count,
+		},
+	})
+	return c
+}
+
+// RequestAll adds a request for all devices of the class.
+func (c *ResourceClaimWrapper) RequestAll(name, deviceClassName string) *ResourceClaimWrapper {
+	c.Spec.Devices.Requests = append(c.Spec.Devices.Requests, resourceapi.DeviceRequest{
+		Name: name,
+		Exactly: &resourceapi.ExactDeviceRequest{
+			DeviceClassName: deviceClassName,
+			AllocationMode:  resourceapi.DeviceAllocationModeAll,
+		},
+	})
+	return c
+}
+
+// Selectors adds CEL selectors to the most recently added request.
+// It panics if there is no such request.
+func (c *ResourceClaimWrapper) Selectors(expressions ...string) *ResourceClaimWrapper {
+	request := c.Spec.Devices.Requests[len(c.Spec.Devices.Requests)-1].Exactly
+	for _, expression := range expressions {
+		request.Selectors = append(request.Selectors, resourceapi.DeviceSelector{
+			CEL: &resourceapi.CELDeviceSelector{Expression: expression},
+		})
+	}
+	return c
+}
+
+// Allocated marks the claim as allocated on the node, without
+// devices. An empty node name means that the allocation is
+// available on all nodes.
+func (c *ResourceClaimWrapper) Allocated(nodeName string) *ResourceClaimWrapper {
+	c.Status.Allocation = &resourceapi.AllocationResult{}
+	if nodeName != "" {
+		c.Status.Allocation.NodeSelector = &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchFields: []v1.NodeSelectorRequirement{{
+					Key:      "metadata.name",
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{nodeName},
+				}},
+			}},
+		}
+	}
+	return c
+}
+
+// AllocatedDevice adds a device to the allocation result of the claim,
+// which gets created for all nodes if the claim is not allocated yet.
+func (c *ResourceClaimWrapper) AllocatedDevice(request, driver, pool, device string) *ResourceClaimWrapper {
+	if c.Status.Allocation == nil {
+		c.Allocated("")
+	}
+	c.Status.Allocation.Devices.Results = append(c.Status.Allocation.Devices.Results, resourceapi.DeviceRequestAllocationResult{
+		Request: request,
+		Driver:  driver,
+		Pool:    pool,
+		Device:  device,
+	})
+	return c
+}
+
+// ReservedFor adds the pod to ResourceClaim.Status.ReservedFor.
+func (c *ResourceClaimWrapper) ReservedFor(podName string, podUID types.UID) *ResourceClaimWrapper {
+	c.Status.ReservedFor = append(c.Status.ReservedFor, resourceapi.ResourceClaimConsumerReference{
+		Resource: "pods",
+		Name:     podName,
+		UID:      podUID,
+	})
+	return c
+}
+
+// DeviceClassWrapper wraps a DeviceClass.
+type DeviceClassWrapper struct {
+	resourceapi.DeviceClass
+}
+
+// MakeDeviceClass creates a wrapper for a DeviceClass without selectors.
+func MakeDeviceClass(name string) *DeviceClassWrapper {
+	return &DeviceClassWrapper{
+		resourceapi.DeviceClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		},
+	}
+}
+
+// Obj returns the inner DeviceClass.
+func (c *DeviceClassWrapper) Obj() *resourceapi.DeviceClass {
+	return &c.DeviceClass
+}
+
+// Selectors adds one CEL selector per expression to DeviceClass.Spec.Selectors.
+func (c *DeviceClassWrapper) Selectors(expressions ...string) *DeviceClassWrapper {
+	for _, expression := range expressions {
+		c.Spec.Selectors = append(c.Spec.Selectors, resourceapi.DeviceSelector{
+			CEL: &resourceapi.CELDeviceSelector{Expression: expression},
+		})
+	}
+	return c
+}
+
+// DriverSelector adds a CEL selector which matches all devices of the driver.
+func (c *DeviceClassWrapper) DriverSelector(driver string) *DeviceClassWrapper {
+	return c.Selectors(`device.driver == "` + driver + `"`)
+}
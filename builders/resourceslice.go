@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builders provides fluent constructors for the DRA API types
+// in tests. Each MakeX function returns a wrapper with sensible defaults
+// which can be modified with chained setters. Obj returns the result.
+//
+// All defaults are constant, so objects built the same way are
+// identical. This makes the builders suitable for comparing
+// actual against expected objects.
+package builders
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// Defaults used by the builders.
+const (
+	DefaultDriver    = "driver.example.com"
+	DefaultPool      = "pool"
+	DefaultNamespace = "default"
+)
+
+// DefaultTime is used for timestamps like DeviceTaint.TimeAdded.
+var DefaultTime = metav1.NewTime(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC))
+
+// ResourceSliceWrapper wraps a ResourceSlice.
+type ResourceSliceWrapper struct {
+	resourceapi.ResourceSlice
+}
+
+// MakeResourceSlice creates a wrapper for a ResourceSlice of [DefaultDriver]
+// with a single slice in [DefaultPool], available on all nodes.
+func MakeResourceSlice(name string) *ResourceSliceWrapper {
+	return &ResourceSliceWrapper{
+		resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver: DefaultDriver,
+				Pool: resourceapi.ResourcePool{
+					Name:               DefaultPool,
+					ResourceSliceCount: 1,
+				},
+				AllNodes: ptr.To(true),
+			},
+		},
+	}
+}
+
+// Obj returns the inner ResourceSlice.
+func (r *ResourceSliceWrapper) Obj() *resourceapi.ResourceSlice {
+	return &r.ResourceSlice
+}
+
+// Label sets a label of the ResourceSlice.
+func (r *ResourceSliceWrapper) Label(key, value string) *ResourceSliceWrapper {
+	if r.Labels == nil {
+		r.Labels = make(map[string]string)
+	}
+	r.Labels[key] = value
+	return r
+}
+
+// Driver sets the value of ResourceSlice.Spec.Driver.
+func (r *ResourceSliceWrapper) Driver(driver string) *ResourceSliceWrapper {
+	r.Spec.Driver = driver
+	return r
+}
+
+// Pool sets the value of ResourceSlice.Spec.Pool.Name.
+func (r *ResourceSliceWrapper) Pool(pool string) *ResourceSliceWrapper {
+	r.Spec.Pool.Name = pool
+	return r
+}
+
+// Generation sets the value of ResourceSlice.Spec.Pool.Generation.
+func (r *ResourceSliceWrapper) Generation(generation int64) *ResourceSliceWrapper {
+	r.Spec.Pool.Generation = generation
+	return r
+}
+
+// ResourceSliceCount sets the value of ResourceSlice.Spec.Pool.ResourceSliceCount.
+func (r *ResourceSliceWrapper) ResourceSliceCount(count int64) *ResourceSliceWrapper {
+	r.Spec.Pool.ResourceSliceCount = count
+	return r
+}
+
+// NodeName sets the value of ResourceSlice.Spec.NodeName and clears
+// the other node selection fields.
+func (r *ResourceSliceWrapper) NodeName(nodeName string) *ResourceSliceWrapper {
+	r.clearNodeSelection()
+	r.Spec.NodeName = ptr.To(nodeName)
+	return r
+}
+
+// NodeSelector sets the value of ResourceSlice.Spec.NodeSelector and clears
+// the other node selection fields.
+func (r *ResourceSliceWrapper) NodeSelector(nodeSelector *v1.NodeSelector) *ResourceSliceWrapper {
+	r.clearNodeSelection()
+	r.Spec.NodeSelector = nodeSelector
+	return r
+}
+
+// AllNodes sets ResourceSlice.Spec.AllNodes and clears the other node
+// selection fields.
+func (r *ResourceSliceWrapper) AllNodes() *ResourceSliceWrapper {
+	r.clearNodeSelection()
+	r.Spec.AllNodes = ptr.To(true)
+	return r
+}
+
+// PerDeviceNodeSelection sets ResourceSlice.Spec.PerDeviceNodeSelection and
+// clears the other node selection fields.
+func (r *ResourceSliceWrapper) PerDeviceNodeSelection() *ResourceSliceWrapper {
+	r.clearNodeSelection()
+	r.Spec.PerDeviceNodeSelection = ptr.To(true)
+	return r
+}
+
+// NoNodeSelection clears all node selection fields. The result is not
+// valid for the API server, but some tests don't care about nodes.
+func (r *ResourceSliceWrapper) NoNodeSelection() *ResourceSliceWrapper {
+	r.clearNodeSelection()
+	return r
+}
+
+func (r *ResourceSliceWrapper) clearNodeSelection() {
+	r.Spec.NodeName = nil
+	r.Spec.NodeSelector = nil
+	r.Spec.AllNodes = nil
+	r.Spec.PerDeviceNodeSelection = nil
+}
+
+// Devices sets the value of ResourceSlice.Spec.Devices.
+func (r *ResourceSliceWrapper) Devices(devices ...resourceapi.Device) *ResourceSliceWrapper {
+	r.Spec.Devices = devices
+	return r
+}
+
+// SharedCounters sets the value of ResourceSlice.Spec.SharedCounters.
+func (r *ResourceSliceWrapper) SharedCounters(counters ...resourceapi.CounterSet) *ResourceSliceWrapper {
+	r.Spec.SharedCounters = counters
+	return r
+}
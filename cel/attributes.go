@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+// attributeWildcard can be used instead of an attribute ID to match
+// any attribute in a domain.
+const attributeWildcard = "*"
+
+// attributeFunctions returns the DRA-specific functions for checking
+// whether a device has certain attributes. The name is a qualified name
+// as in a ResourceSlice, so the domain defaults to the driver name.
+// The ID may be a wildcard which matches any attribute in the domain:
+//
+//	device.hasAttribute("dra.example.com/model") // same as "model" in device.attributes["dra.example.com"]
+//	device.hasAttribute("model")                 // the driver name is the domain
+//	device.hasAttribute("dra.example.com/*")     // true if the device has any attribute in that domain
+//
+// This is easier to get right than the equivalent `in` checks, which
+// need the domain and the ID as separate strings.
+func attributeFunctions(deviceType *cel.Type) []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("hasAttribute",
+			cel.MemberOverload("dra_device_has_attribute_string", []*cel.Type{deviceType, cel.StringType}, cel.BoolType, cel.BinaryBinding(deviceHasAttribute)),
+			// At runtime, the device is a map, not an object of
+			// the declared type.
+			decls.DisableTypeGuards(true),
+			cel.FunctionDocs(`Returns true if the device has the attribute. The name is "<domain>/<ID>" or just "<ID>" for attributes in the domain of the driver. The ID "*" matches any attribute in the domain.`),
+		),
+	}
+}
+
+func deviceHasAttribute(deviceVal, nameVal ref.Val) ref.Val {
+	device, ok := deviceVal.(traits.Mapper)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(deviceVal)
+	}
+	name, ok := nameVal.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(nameVal)
+	}
+
+	var driver string
+	if driverVal, found := device.Find(types.String(driverVar)); found {
+		driver, _ = driverVal.Value().(string)
+	}
+	domain, id := parseQualifiedName(resourceapi.QualifiedName(name), driver)
+	if domain == "" || id == "" || (id != attributeWildcard && strings.Contains(id, attributeWildcard)) || strings.Contains(domain, attributeWildcard) {
+		return types.NewErr("invalid attribute name %q: must be <domain>/<ID> or <ID>, with %q only allowed as the entire ID", string(name), attributeWildcard)
+	}
+
+	attributesVal, found := device.Find(types.String(attributesVar))
+	if !found {
+		return types.False
+	}
+	attributes, ok := attributesVal.(traits.Mapper)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(attributesVal)
+	}
+	// The attributes map returns an empty map for unknown domains.
+	domainVal, found := attributes.Find(types.String(domain))
+	if !found {
+		return types.False
+	}
+	domainAttributes, ok := domainVal.(traits.Mapper)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(domainVal)
+	}
+	if id == attributeWildcard {
+		return types.Bool(domainAttributes.Size().(types.Int) > 0)
+	}
+	return domainAttributes.Contains(types.String(id))
+}
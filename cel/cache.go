@@ -18,29 +18,76 @@ package cel
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"k8s.io/utils/keymutex"
 	"k8s.io/utils/lru"
 )
 
 // Cache is a thread-safe LRU cache for a compiled CEL expression.
+//
+// A single instance may be shared by different users, for example the
+// allocator, the ResourceSlice tracker and validation code, as long
+// as they use the same features. Sharing avoids compiling the same
+// expression more than once and bounds the overall memory usage.
+//
+// Lookups are counted in the metrics registered by [RegisterMetrics]
+// and in [Cache.Stats].
 type Cache struct {
 	compileMutex keymutex.KeyMutex
 	cacheMutex   sync.RWMutex
 	cache        *lru.Cache
+	costs        *lru.Cache
 	compiler     *compiler
+	maxEntries   int
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// CacheStats contains counters for one [Cache] instance.
+type CacheStats struct {
+	// Hits is the number of lookups which were served from the cache.
+	Hits uint64
+	// Misses is the number of lookups which required compiling.
+	Misses uint64
+	// Evictions is the number of entries which were removed because
+	// the cache was full.
+	Evictions uint64
+	// Entries is the current number of cached compilation results.
+	Entries int
+	// MaxEntries is the size limit of the cache.
+	MaxEntries int
 }
 
 // NewCache creates a cache. The maximum number of entries determines
-// how many entries are cached at most before dropping the oldest
-// entry.
+// how many entries are cached at most before dropping the least recently
+// used entry.
 //
 // The features are used to get a suitable compiler.
 func NewCache(maxCacheEntries int, features Features) *Cache {
-	return &Cache{
+	c := &Cache{
 		compileMutex: keymutex.NewHashed(0),
-		cache:        lru.New(maxCacheEntries),
+		costs:        lru.New(maxCacheEntries),
 		compiler:     GetCompiler(features),
+		maxEntries:   maxCacheEntries,
+	}
+	c.cache = lru.NewWithEvictionFunc(maxCacheEntries, func(key lru.Key, value any) {
+		c.evictions.Add(1)
+		cacheEvictions.Inc()
+	})
+	return c
+}
+
+// Stats returns the current counters of the cache.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Evictions:  c.evictions.Load(),
+		Entries:    c.cache.Len(),
+		MaxEntries: c.maxEntries,
 	}
 }
 
@@ -68,6 +115,42 @@ func (c *Cache) GetOrCompile(expression string) CompilationResult {
 	return expr
 }
 
+// EstimateCost is like [compiler.EstimateCost] with default options.
+// Estimated costs of valid expressions are cached separately from the
+// compilation results returned by [Cache.GetOrCompile] because those are
+// compiled without cost estimation.
+func (c *Cache) EstimateCost(expression string) (uint64, error) {
+	if cost, found := c.costs.Get(expression); found {
+		return cost.(uint64), nil
+	}
+	cost, err := c.compiler.EstimateCost(expression, Options{})
+	if err == nil {
+		c.costs.Add(expression, cost)
+	}
+	return cost, err
+}
+
+// AddPrecompiled loads an expression serialized by [compiler.Precompile]
+// with default options into the cache, unless it is already cached.
+// [Cache.GetOrCompile] then returns it without compiling.
+func (c *Cache) AddPrecompiled(data []byte) error {
+	expr := c.compiler.LoadPrecompiled(data, Options{DisableCostEstimation: true})
+	if expr.Error != nil {
+		return expr.Error
+	}
+
+	c.compileMutex.LockKey(expr.Expression)
+	//nolint:errcheck // Only returns an error for unknown keys, which isn't the case here.
+	defer c.compileMutex.UnlockKey(expr.Expression)
+
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+	if _, found := c.cache.Get(expr.Expression); !found {
+		c.cache.Add(expr.Expression, &expr)
+	}
+	return nil
+}
+
 func (c *Cache) add(expression string, expr *CompilationResult) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
@@ -79,8 +162,12 @@ func (c *Cache) get(expression string) *CompilationResult {
 	defer c.cacheMutex.RUnlock()
 	expr, found := c.cache.Get(expression)
 	if !found {
+		c.misses.Add(1)
+		cacheMisses.Inc()
 		return nil
 	}
+	c.hits.Add(1)
+	cacheHits.Inc()
 	return expr.(*CompilationResult)
 }
 
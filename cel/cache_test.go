@@ -24,6 +24,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
 )
 
 func TestCacheSemantic(t *testing.T) {
@@ -102,3 +105,48 @@ func TestCacheConcurrency(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestCacheEstimateCost(t *testing.T) {
+	cache := NewCache(2, Features{})
+	expression := `device.attributes["dra.example.com"].name.startsWith("gpu")`
+
+	cost, err := cache.EstimateCost(expression)
+	require.NoError(t, err)
+	expectedCost := GetCompiler(Features{}).CompileCELExpression(expression, Options{}).MaxCost
+	assert.Equal(t, expectedCost, cost, "estimated cost")
+	assert.Less(t, cost, uint64(math.MaxUint64), "estimated cost")
+
+	costAgain, err := cache.EstimateCost(expression)
+	require.NoError(t, err)
+	assert.Equal(t, cost, costAgain, "cached cost")
+
+	// The compilation result is independent of the estimated cost.
+	result := cache.GetOrCompile(expression)
+	require.Nil(t, result.Error)
+	assert.Equal(t, uint64(math.MaxUint64), result.MaxCost, "cost estimation for cached compilation result")
+
+	cost, err = cache.EstimateCost("no-such-variable")
+	require.ErrorContains(t, err, "compilation failed")
+	assert.Equal(t, uint64(math.MaxUint64), cost, "cost of invalid expression")
+}
+
+func TestCacheStats(t *testing.T) {
+	RegisterMetrics()
+	counter := func(t *testing.T, metric *metrics.Counter) float64 {
+		t.Helper()
+		value, err := testutil.GetCounterMetricValue(metric)
+		require.NoError(t, err)
+		return value
+	}
+	hitsBefore, missesBefore, evictionsBefore := counter(t, cacheHits), counter(t, cacheMisses), counter(t, cacheEvictions)
+
+	cache := NewCache(1, Features{})
+	require.Nil(t, cache.GetOrCompile("true").Error)
+	require.Nil(t, cache.GetOrCompile("true").Error)
+	require.Nil(t, cache.GetOrCompile("false").Error)
+
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Evictions: 1, Entries: 1, MaxEntries: 1}, cache.Stats())
+	assert.Equal(t, 1.0, counter(t, cacheHits)-hitsBefore, "hits metric")
+	assert.Equal(t, 2.0, counter(t, cacheMisses)-missesBefore, "misses metric")
+	assert.Equal(t, 1.0, counter(t, cacheEvictions)-evictionsBefore, "evictions metric")
+}
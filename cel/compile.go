@@ -20,10 +20,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/google/cel-go/cel"
@@ -36,6 +39,7 @@ import (
 	"k8s.io/utils/ptr"
 
 	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/version"
 	celconfig "k8s.io/apiserver/pkg/apis/cel"
 	apiservercel "k8s.io/apiserver/pkg/cel"
@@ -49,6 +53,10 @@ const (
 	multiAllocVar = "allowMultipleAllocations"
 	attributesVar = "attributes"
 	capacityVar   = "capacity"
+	taintsVar     = "taints"
+	taintKeyVar   = "key"
+	taintValueVar = "value"
+	taintEffVar   = "effect"
 )
 
 var (
@@ -77,11 +85,30 @@ var (
 	// Same for capacity.
 	innerCapacityMapType = apiservercel.NewMapType(idType, apiservercel.QuantityDeclType, resourceapi.ResourceSliceMaxAttributesAndCapacitiesPerDevice)
 	outerCapacityMapType = apiservercel.NewMapType(domainType, innerCapacityMapType, resourceapi.ResourceSliceMaxAttributesAndCapacitiesPerDevice)
+
+	// Label keys and values. Taint keys and values have the same limits.
+	labelKeyType   = withMaxElements(apiservercel.StringType, uint64(validation.DNS1123SubdomainMaxLength+1+validation.LabelValueMaxLength))
+	labelValueType = withMaxElements(apiservercel.StringType, uint64(validation.LabelValueMaxLength))
+
+	taintEffectType = withMaxElements(apiservercel.StringType, uint64(len(resourceapi.DeviceTaintEffectNoExecute)))
+	taintType       = apiservercel.NewObjectType("kubernetes.DRADeviceTaint", map[string]*apiservercel.DeclField{
+		taintKeyVar:   apiservercel.NewDeclField(taintKeyVar, labelKeyType, true, nil, nil),
+		taintValueVar: apiservercel.NewDeclField(taintValueVar, labelValueType, true, nil, nil),
+		taintEffVar:   apiservercel.NewDeclField(taintEffVar, taintEffectType, true, nil, nil),
+	})
+	taintsType = apiservercel.NewListType(taintType, resourceapi.DeviceTaintsMaxLength)
 )
 
 // Features contains feature gates supported by the package.
 type Features struct {
 	EnableConsumableCapacity bool
+
+	// EnableNodeVariable adds a "node" variable with the name, labels and
+	// zone of the node for which devices are getting selected, as
+	// provided in [Device.Node]. This is not supported by Kubernetes
+	// and meant for simulation tools and experiments. Expressions
+	// using it are not portable.
+	EnableNodeVariable bool
 }
 
 func GetCompiler(features Features) *compiler {
@@ -121,6 +148,12 @@ type Device struct {
 	AllowMultipleAllocations *bool
 	Attributes               map[resourceapi.QualifiedName]resourceapi.DeviceAttribute
 	Capacity                 map[resourceapi.QualifiedName]resourceapi.DeviceCapacity
+	Taints                   []resourceapi.DeviceTaint
+
+	// Node is only used if [Features.EnableNodeVariable] is set.
+	// Expressions which use the "node" variable fail to evaluate
+	// when it is nil.
+	Node *Node
 }
 
 type compiler struct {
@@ -131,6 +164,16 @@ type compiler struct {
 	// cost estimates version-dependent.
 	deviceType *apiservercel.DeclType
 	envset     *environment.EnvSet
+	features   Features
+
+	// versioned is the configuration which extends the base environment.
+	// It is needed again when building environments for
+	// other compatibility versions.
+	versioned []environment.VersionedOptions
+	// versionedEnvSets caches those environments, keyed by "<major>.<minor>".
+	versionedEnvSets *sync.Map
+	// declarationHashes caches the result of declarationsHash, keyed by *cel.Env.
+	declarationHashes *sync.Map
 }
 
 // Options contains several additional parameters
@@ -138,8 +181,21 @@ type compiler struct {
 // defaults.
 type Options struct {
 	// EnvType allows to override the default environment type [environment.StoredExpressions].
+	// If CompatibilityVersion is set, the default is [environment.NewExpressions].
 	EnvType *environment.Type
 
+	// CompatibilityVersion overrides [environment.DefaultCompatibilityVersion]
+	// as the Kubernetes version which determines which functions and
+	// variables are available in the [environment.NewExpressions]
+	// environment. Only major and minor version matter.
+	//
+	// Validation code can set this to the oldest version of the
+	// control plane which has to accept the expression, for example
+	// during an upgrade or in a driver which supports several Kubernetes
+	// releases. Then an expression is rejected if it uses something that
+	// an older apiserver or scheduler would not understand.
+	CompatibilityVersion *version.Version
+
 	// CostLimit allows overriding the default runtime cost limit [resourceapi.CELSelectorExpressionMaxCost].
 	CostLimit *uint64
 
@@ -152,18 +208,22 @@ type Options struct {
 //
 // TODO (https://github.com/kubernetes/kubernetes/issues/125826): validate AST to detect invalid attribute names.
 func (c compiler) CompileCELExpression(expression string, options Options) CompilationResult {
-	resultError := func(errorString string, errType apiservercel.ErrorType) CompilationResult {
-		return CompilationResult{
-			Error: &apiservercel.Error{
-				Type:   errType,
-				Detail: errorString,
-			},
-			Expression: expression,
-			MaxCost:    math.MaxUint64,
-		}
+	env, ast, errResult := c.check(expression, options)
+	if errResult != nil {
+		return *errResult
 	}
+	return c.newResult(env, ast, expression, options)
+}
 
-	env, err := c.envset.Env(ptr.Deref(options.EnvType, environment.StoredExpressions))
+// check parses and type-checks the expression. It returns a result
+// with the error if that fails.
+func (c compiler) check(expression string, options Options) (*cel.Env, *cel.Ast, *CompilationResult) {
+	resultError := func(errorString string, errType apiservercel.ErrorType) (*cel.Env, *cel.Ast, *CompilationResult) {
+		result := errorResult(expression, errorString, errType)
+		return nil, nil, &result
+	}
+
+	env, err := c.env(options)
 	if err != nil {
 		return resultError(fmt.Sprintf("unexpected error loading CEL environment: %v", err), apiservercel.ErrorTypeInternal)
 	}
@@ -182,15 +242,32 @@ func (c compiler) CompileCELExpression(expression string, options Options) Compi
 		// should be impossible since env.Compile returned no issues
 		return resultError("unexpected compilation error: "+err.Error(), apiservercel.ErrorTypeInternal)
 	}
+	return env, ast, nil
+}
+
+func errorResult(expression, errorString string, errType apiservercel.ErrorType) CompilationResult {
+	return CompilationResult{
+		Error: &apiservercel.Error{
+			Type:   errType,
+			Detail: errorString,
+		},
+		Expression: expression,
+		MaxCost:    math.MaxUint64,
+	}
+}
+
+// newResult creates the program for a type-checked AST.
+func (c compiler) newResult(env *cel.Env, ast *cel.Ast, expression string, options Options) CompilationResult {
 	prog, err := env.Program(ast,
 		// The Kubernetes CEL base environment sets the VAP limit as runtime cost limit.
 		// DRA has its own default cost limit and also allows the caller to change that
 		// limit.
 		cel.CostLimit(ptr.Deref(options.CostLimit, resourceapi.CELSelectorExpressionMaxCost)),
 		cel.InterruptCheckFrequency(celconfig.CheckFrequency),
+		cel.CustomDecorator(quantityOperatorDecorator),
 	)
 	if err != nil {
-		return resultError("program instantiation failed: "+err.Error(), apiservercel.ErrorTypeInternal)
+		return errorResult(expression, "program instantiation failed: "+err.Error(), apiservercel.ErrorTypeInternal)
 	}
 
 	compilationResult := CompilationResult{
@@ -217,6 +294,71 @@ func (c compiler) CompileCELExpression(expression string, options Options) Compi
 	return compilationResult
 }
 
+// EstimateCost returns the worst-case cost of a device selector expression in
+// CEL cost units, i.e. the same value as [CompilationResult.MaxCost] with cost
+// estimation enabled. Admission webhooks and driver tooling can compare it
+// against [resourceapi.CELSelectorExpressionMaxCost] or a stricter limit of
+// their own to reject expressions before they get stored in a DeviceClass or
+// DeviceTaintRule.
+//
+// If the expression is invalid, the compilation error is returned as
+// *apiservercel.Error.
+func (c compiler) EstimateCost(expression string, options Options) (uint64, error) {
+	options.DisableCostEstimation = false
+	result := c.CompileCELExpression(expression, options)
+	if result.Error != nil {
+		return math.MaxUint64, result.Error
+	}
+	return result.MaxCost, nil
+}
+
+// env returns the CEL environment selected by the options.
+func (c compiler) env(options Options) (*cel.Env, error) {
+	if options.CompatibilityVersion == nil {
+		return c.envset.Env(ptr.Deref(options.EnvType, environment.StoredExpressions))
+	}
+	envset, err := c.envSetForVersion(options.CompatibilityVersion)
+	if err != nil {
+		return nil, err
+	}
+	return envset.Env(ptr.Deref(options.EnvType, environment.NewExpressions))
+}
+
+// structFields returns the fields of an object type declared in the
+// environment, nil if there is no such type. The field names are
+// sorted.
+func structFields(env *cel.Env, typeName string) ([]string, map[string]*apiservercel.DeclField) {
+	// The generic types.Provider.FindStructFieldNames is not
+	// implemented by the DeclTypeProvider.
+	provider, ok := env.CELTypeProvider().(*apiservercel.DeclTypeProvider)
+	if !ok {
+		return nil, nil
+	}
+	declType, ok := provider.FindDeclType(typeName)
+	if !ok || declType.Fields == nil {
+		return nil, nil
+	}
+	return slices.Sorted(maps.Keys(declType.Fields)), declType.Fields
+}
+
+// envSetForVersion builds the environments for a certain compatibility
+// version once and then returns the cached result.
+func (c compiler) envSetForVersion(ver *version.Version) (*environment.EnvSet, error) {
+	if len(ver.Components()) < 2 {
+		return nil, fmt.Errorf("compatibility version must have major and minor component, got %q", ver.String())
+	}
+	key := fmt.Sprintf("%d.%d", ver.Major(), ver.Minor())
+	if envset, ok := c.versionedEnvSets.Load(key); ok {
+		return envset.(*environment.EnvSet), nil
+	}
+	envset, err := environment.MustBaseEnvSet(version.MajorMinor(ver.Major(), ver.Minor()), true /* strictCost */).Extend(c.versioned...)
+	if err != nil {
+		return nil, fmt.Errorf("build CEL environment for compatibility version %s: %w", key, err)
+	}
+	actual, _ := c.versionedEnvSets.LoadOrStore(key, envset)
+	return actual.(*environment.EnvSet), nil
+}
+
 func (c *compiler) newCostEstimator() *library.CostEstimator {
 	return &library.CostEstimator{SizeEstimator: &sizeEstimator{compiler: c}}
 }
@@ -245,7 +387,7 @@ func getAttributeValue(attr resourceapi.DeviceAttribute) (any, error) {
 
 var boolType = reflect.TypeOf(true)
 
-func (c CompilationResult) DeviceMatches(ctx context.Context, input Device) (bool, *cel.EvalDetails, error) {
+func (c CompilationResult) deviceMatches(ctx context.Context, input Device) (bool, *cel.EvalDetails, error) {
 	// TODO (future): avoid building these maps and instead use a proxy
 	// which wraps the underlying maps and directly looks up values.
 	attributes := make(map[string]any)
@@ -279,7 +421,23 @@ func (c CompilationResult) DeviceMatches(ctx context.Context, input Device) (boo
 		},
 	}
 
+	taints := make([]any, 0, len(input.Taints))
+	for _, taint := range input.Taints {
+		taints = append(taints, map[string]any{
+			taintKeyVar:   taint.Key,
+			taintValueVar: taint.Value,
+			taintEffVar:   string(taint.Effect),
+		})
+	}
+	variables[deviceVar].(map[string]any)[taintsVar] = taints
+
+	if input.Node != nil {
+		variables[nodeVar] = input.Node.value()
+	}
+
+	start := time.Now()
 	result, details, err := c.Program.ContextEval(ctx, variables)
+	c.recordEvaluation(ctx, time.Since(start), err)
 	if err != nil {
 		// CEL does not wrap the context error. We have to deduce why it failed.
 		// See https://github.com/google/cel-go/issues/1195.
@@ -324,6 +482,15 @@ func newCompiler(features Features) *compiler {
 	fieldsV134ConsumableCapacity = append(fieldsV134ConsumableCapacity, fieldsV131...)
 	deviceTypeV134ConsumableCapacity := apiservercel.NewObjectType("kubernetes.DRADevice", fields(fieldsV134ConsumableCapacity...))
 
+	// Taints were added in 1.35, with and without the feature-gated field.
+	fieldsV135 := append([]*apiservercel.DeclField{field(taintsVar, taintsType, true)}, fieldsV131...)
+	deviceTypeV135 := apiservercel.NewObjectType("kubernetes.DRADevice", fields(fieldsV135...))
+	fieldsV135ConsumableCapacity := append([]*apiservercel.DeclField{field(taintsVar, taintsType, true)}, fieldsV134ConsumableCapacity...)
+	deviceTypeV135ConsumableCapacity := apiservercel.NewObjectType("kubernetes.DRADevice", fields(fieldsV135ConsumableCapacity...))
+	deviceOptions := func(deviceType *apiservercel.DeclType) ([]cel.EnvOption, []*apiservercel.DeclType) {
+		return []cel.EnvOption{cel.VariableWithDoc(deviceVar, deviceType.CelType(), deviceVarDoc)}, []*apiservercel.DeclType{deviceType}
+	}
+
 	versioned := []environment.VersionedOptions{
 		{
 			IntroducedVersion: version.MajorMinor(1, 31),
@@ -345,7 +512,7 @@ func newCompiler(features Features) *compiler {
 				return !features.EnableConsumableCapacity
 			},
 			EnvOptions: []cel.EnvOption{
-				cel.Variable(deviceVar, deviceTypeV131.CelType()),
+				cel.VariableWithDoc(deviceVar, deviceTypeV131.CelType(), deviceVarDoc),
 			},
 			DeclTypes: []*apiservercel.DeclType{
 				deviceTypeV131,
@@ -357,19 +524,69 @@ func newCompiler(features Features) *compiler {
 				return features.EnableConsumableCapacity
 			},
 			EnvOptions: []cel.EnvOption{
-				cel.Variable(deviceVar, deviceTypeV134ConsumableCapacity.CelType()),
+				cel.VariableWithDoc(deviceVar, deviceTypeV134ConsumableCapacity.CelType(), deviceVarDoc),
 			},
 			DeclTypes: []*apiservercel.DeclType{
 				deviceTypeV134ConsumableCapacity,
 			},
 		},
+		{
+			IntroducedVersion: version.MajorMinor(1, 35),
+			EnvOptions:        semverFunctions(),
+		},
+		{
+			IntroducedVersion: version.MajorMinor(1, 35),
+			EnvOptions:        quantityOperators(),
+		},
+		{
+			// All variants of the device type have the same name,
+			// so the functions work for all of them.
+			IntroducedVersion: version.MajorMinor(1, 36),
+			EnvOptions:        attributeFunctions(deviceTypeV131.CelType()),
+		},
+	}
+	// A feature gate takes priority over the version, so the 1.35 types
+	// cannot use FeatureEnabled like the older ones: with the gate enabled,
+	// the taints would also be available for older versions. Instead, the
+	// options depend on the feature at construction time. The last
+	// declaration wins, so StoredExpressions (which ignores FeatureEnabled)
+	// always ends up with all fields.
+	if features.EnableConsumableCapacity {
+		envOptions, declTypes := deviceOptions(deviceTypeV135ConsumableCapacity)
+		versioned = append(versioned, environment.VersionedOptions{
+			IntroducedVersion: version.MajorMinor(1, 35),
+			EnvOptions:        envOptions,
+			DeclTypes:         declTypes,
+		})
+	} else {
+		envOptions, declTypes := deviceOptions(deviceTypeV135)
+		versioned = append(versioned, environment.VersionedOptions{
+			IntroducedVersion: version.MajorMinor(1, 35),
+			EnvOptions:        envOptions,
+			DeclTypes:         declTypes,
+		})
+		envOptions, declTypes = deviceOptions(deviceTypeV135ConsumableCapacity)
+		versioned = append(versioned, environment.VersionedOptions{
+			IntroducedVersion: version.MajorMinor(1, 35),
+			FeatureEnabled:    func() bool { return false },
+			EnvOptions:        envOptions,
+			DeclTypes:         declTypes,
+		})
 	}
+	versioned = append(versioned, nodeVariable(features)...)
 	envset, err := envset.Extend(versioned...)
 	if err != nil {
 		panic(fmt.Errorf("internal error building CEL environment: %w", err))
 	}
 	// return with newest deviceType
-	return &compiler{envset: envset, deviceType: deviceTypeV134ConsumableCapacity}
+	return &compiler{
+		envset:            envset,
+		deviceType:        deviceTypeV135ConsumableCapacity,
+		features:          features,
+		versioned:         versioned,
+		versionedEnvSets:  &sync.Map{},
+		declarationHashes: &sync.Map{},
+	}
 }
 
 func withMaxElements(in *apiservercel.DeclType, maxElements uint64) *apiservercel.DeclType {
@@ -435,6 +652,8 @@ func (s *sizeEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstim
 	switch path[0] {
 	case deviceVar:
 		currentNode = s.compiler.deviceType
+	case nodeVar:
+		currentNode = nodeType
 	default:
 		// Unknown root, shouldn't happen.
 		return nil
@@ -26,6 +26,7 @@ import (
 
 	resourceapi "k8s.io/api/resource/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apiserver/pkg/cel/environment"
 	"k8s.io/klog/v2/ktesting"
 	"k8s.io/utils/ptr"
@@ -35,13 +36,19 @@ var testcases = map[string]struct {
 	// environment.StoredExpressions is the default (= all CEL fields and features from the current version available).
 	// environment.NewExpressions can be used to enforce that only fields and features from the previous version are available.
 	envType *environment.Type
+	// compatibilityVersion selects the environment for that Kubernetes version,
+	// by default [environment.NewExpressions].
+	compatibilityVersion *version.Version
 	// The feature gate only has an effect in combination with environment.NewExpressions.
 	enableConsumableCapacity bool
+	enableNodeVariable       bool
+	node                     *Node
 	expression               string
 	driver                   string
 	allowMultipleAllocations *bool
 	attributes               map[resourceapi.QualifiedName]resourceapi.DeviceAttribute
 	capacity                 map[resourceapi.QualifiedName]resourceapi.DeviceCapacity
+	taints                   []resourceapi.DeviceTaint
 	expectCompileError       string
 	expectMatchError         string
 	expectMatch              bool
@@ -165,6 +172,50 @@ var testcases = map[string]struct {
 		expectMatch: true,
 		expectCost:  7,
 	},
+	"semver-is-at-least": {
+		expression:  `semverIsAtLeast(device.attributes["dra.example.com"].firmware, "1.2.3") && !semverIsAtLeast(device.attributes["dra.example.com"].firmware, "1.10.0")`,
+		attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"firmware": {VersionValue: ptr.To("1.9.0")}},
+		driver:      "dra.example.com",
+		expectMatch: true,
+		expectCost:  11,
+	},
+	"semver-compare": {
+		expression:  `semverCompare(device.attributes["dra.example.com"].firmware, "1.9.0") == 0 && semverCompare("1.10.0", device.attributes["dra.example.com"].firmware) == 1 && semverCompare("1.2.3", "1.2.3-alpha") == 1`,
+		attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"firmware": {VersionValue: ptr.To("1.9.0")}},
+		driver:      "dra.example.com",
+		expectMatch: true,
+		expectCost:  14,
+	},
+	"semver-invalid-string": {
+		expression:       `semverIsAtLeast(device.attributes["dra.example.com"].firmware, "v1.2")`,
+		attributes:       map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"firmware": {VersionValue: ptr.To("1.9.0")}},
+		driver:           "dra.example.com",
+		expectMatchError: `invalid semantic version "v1.2"`,
+		expectCost:       5,
+	},
+	"semver-wrong-attribute-type": {
+		expression:       `semverIsAtLeast(device.attributes["dra.example.com"].firmware, "1.2.3")`,
+		attributes:       map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"firmware": {IntValue: ptr.To(int64(1))}},
+		driver:           "dra.example.com",
+		expectMatchError: "no such overload",
+		expectCost:       5,
+	},
+	"semver-compatibility-version-1.34": {
+		compatibilityVersion: version.MajorMinor(1, 34),
+		expression:           `semverIsAtLeast("1.2.3", "1.2.3")`,
+		expectCompileError:   `undeclared reference to 'semverIsAtLeast'`,
+	},
+	"semver-compatibility-version-1.35": {
+		compatibilityVersion: version.MajorMinor(1, 35),
+		expression:           `semverIsAtLeast("1.2.3", "1.2.3")`,
+		expectMatch:          true,
+		expectCost:           1,
+	},
+	"semver-new-expressions": {
+		envType:            ptr.To(environment.NewExpressions),
+		expression:         `semverIsAtLeast("1.2.3", "1.2.3")`,
+		expectCompileError: `undeclared reference to 'semverIsAtLeast'`,
+	},
 	"quantity": {
 		expression:  `device.capacity["dra.example.com"].name.isGreaterThan(quantity("1Ki"))`,
 		capacity:    map[resourceapi.QualifiedName]resourceapi.DeviceCapacity{"name": {Value: resource.MustParse("1Mi")}},
@@ -172,6 +223,57 @@ var testcases = map[string]struct {
 		expectMatch: true,
 		expectCost:  6,
 	},
+	"quantity-arithmetic": {
+		expression:  `device.capacity["dra.example.com"].memory >= quantity("40Gi") * 2 && device.capacity["dra.example.com"].memory - quantity("1Gi") < 2 * quantity("40Gi") && device.capacity["dra.example.com"].memory + quantity("1Gi") > quantity("80Gi")`,
+		capacity:    map[resourceapi.QualifiedName]resourceapi.DeviceCapacity{"memory": {Value: resource.MustParse("80Gi")}},
+		driver:      "dra.example.com",
+		expectMatch: true,
+		expectCost:  24,
+	},
+	"quantity-comparison": {
+		expression:  `device.capacity["dra.example.com"].memory <= quantity("1Gi") && device.capacity["dra.example.com"].memory > quantity("1Mi") && !(device.capacity["dra.example.com"].memory < quantity("1Gi"))`,
+		capacity:    map[resourceapi.QualifiedName]resourceapi.DeviceCapacity{"memory": {Value: resource.MustParse("1Gi")}},
+		driver:      "dra.example.com",
+		expectMatch: true,
+		expectCost:  19,
+	},
+	"quantity-new-expressions": {
+		envType:            ptr.To(environment.NewExpressions),
+		expression:         `quantity("1Gi") * 2 == quantity("2Gi")`,
+		expectCompileError: `found no matching overload for '_*_'`,
+	},
+	"has-attribute": {
+		expression:  `device.hasAttribute("dra.example.com/model") && device.hasAttribute("model") && device.hasAttribute("other.example.com/serial") && !device.hasAttribute("dra.example.com/serial")`,
+		attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": {StringValue: ptr.To("a100")}, "other.example.com/serial": {StringValue: ptr.To("1234")}},
+		driver:      "dra.example.com",
+		expectMatch: true,
+		expectCost:  9,
+	},
+	"has-attribute-wildcard": {
+		expression:  `device.hasAttribute("other.example.com/*") && !device.hasAttribute("no-such-domain.example.com/*")`,
+		attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"other.example.com/serial": {StringValue: ptr.To("1234")}},
+		driver:      "dra.example.com",
+		expectMatch: true,
+		expectCost:  5,
+	},
+	"has-attribute-invalid-wildcard": {
+		expression:       `device.hasAttribute("dra.example.com/mod*")`,
+		driver:           "dra.example.com",
+		expectMatchError: `invalid attribute name "dra.example.com/mod*"`,
+		expectCost:       2,
+	},
+	"has-attribute-compatibility-version-1.35": {
+		compatibilityVersion: version.MajorMinor(1, 35),
+		expression:           `device.hasAttribute("model")`,
+		expectCompileError:   `undeclared reference to 'hasAttribute'`,
+	},
+	"has-attribute-compatibility-version-1.36": {
+		compatibilityVersion: version.MajorMinor(1, 36),
+		expression:           `device.hasAttribute("model")`,
+		driver:               "dra.example.com",
+		expectMatch:          false,
+		expectCost:           2,
+	},
 	"check-positive": {
 		expression:  `"name" in device.capacity["dra.example.com"] && device.capacity["dra.example.com"].name.isGreaterThan(quantity("1Ki"))`,
 		capacity:    map[resourceapi.QualifiedName]resourceapi.DeviceCapacity{"name": {Value: resource.MustParse("1Mi")}},
@@ -329,6 +431,74 @@ device.attributes["dra.example.com"]["version"].isGreaterThan(semver("0.0.1"))
 		expectMatch:              true,
 		expectCost:               3,
 	},
+	"taints": {
+		expression: `!device.taints.exists(t, t.effect == "NoExecute") && device.taints.exists(t, t.key == "example.com/degraded" && t.value == "true")`,
+		driver:     "dra.example.com",
+		taints: []resourceapi.DeviceTaint{
+			{Key: "example.com/degraded", Value: "true", Effect: resourceapi.DeviceTaintEffectNoSchedule},
+		},
+		expectMatch: true,
+		expectCost:  79,
+	},
+	"taints-no-execute": {
+		expression: `!device.taints.exists(t, t.effect == "NoExecute")`,
+		driver:     "dra.example.com",
+		taints: []resourceapi.DeviceTaint{
+			{Key: "example.com/broken", Effect: resourceapi.DeviceTaintEffectNoExecute},
+		},
+		expectMatch: false,
+		expectCost:  32,
+	},
+	"taints-none": {
+		expression:  `device.taints.size() == 0`,
+		driver:      "dra.example.com",
+		expectMatch: true,
+		expectCost:  4,
+	},
+	"taints-consumable-capacity-1.34": {
+		enableConsumableCapacity: true,
+		compatibilityVersion:     version.MajorMinor(1, 34),
+		expression:               `device.taints.size() == 0 && !device.allowMultipleAllocations`,
+		expectCompileError:       `undefined field 'taints'`,
+	},
+	"taints-consumable-capacity-1.35": {
+		enableConsumableCapacity: true,
+		compatibilityVersion:     version.MajorMinor(1, 35),
+		expression:               `device.taints.size() == 0 && !device.allowMultipleAllocations`,
+		driver:                   "dra.example.com",
+		expectMatch:              true,
+		expectCost:               7,
+	},
+	"taints-new-expressions": {
+		envType:            ptr.To(environment.NewExpressions),
+		expression:         `device.taints.size() == 0`,
+		expectCompileError: `undefined field 'taints'`,
+	},
+	"node-zone": {
+		enableNodeVariable: true,
+		expression:         `node.zone == "zone-a" && node.name == "worker"`,
+		node:               &Node{Name: "worker", Labels: map[string]string{"topology.kubernetes.io/zone": "zone-a"}},
+		expectMatch:        true,
+		expectCost:         6,
+	},
+	"node-labels": {
+		enableNodeVariable: true,
+		expression:         `"rack" in node.labels && node.labels["rack"] == "r1" && node.zone == ""`,
+		node:               &Node{Name: "worker", Labels: map[string]string{"rack": "r1"}},
+		expectMatch:        true,
+		expectCost:         9,
+	},
+	"node-missing-input": {
+		enableNodeVariable: true,
+		expression:         `node.name == "worker"`,
+		expectMatchError:   "no such attribute",
+		expectCost:         3,
+	},
+	"node-disabled": {
+		expression:         `node.name == "worker"`,
+		node:               &Node{Name: "worker"},
+		expectCompileError: `undeclared reference to 'node'`,
+	},
 	"allow_multiple_allocations_disabled": {
 		envType:                  ptr.To(environment.NewExpressions),
 		enableConsumableCapacity: false,
@@ -342,7 +512,7 @@ func TestCEL(t *testing.T) {
 	for name, scenario := range testcases {
 		t.Run(name, func(t *testing.T) {
 			_, ctx := ktesting.NewTestContext(t)
-			result := GetCompiler(Features{EnableConsumableCapacity: scenario.enableConsumableCapacity}).CompileCELExpression(scenario.expression, Options{EnvType: scenario.envType})
+			result := GetCompiler(Features{EnableConsumableCapacity: scenario.enableConsumableCapacity, EnableNodeVariable: scenario.enableNodeVariable}).CompileCELExpression(scenario.expression, Options{EnvType: scenario.envType, CompatibilityVersion: scenario.compatibilityVersion})
 			if scenario.expectCompileError != "" && result.Error == nil {
 				t.Fatalf("FAILURE: expected compile error %q, got none", scenario.expectCompileError)
 			}
@@ -363,7 +533,7 @@ func TestCEL(t *testing.T) {
 			}
 
 			match, details, err := result.DeviceMatches(ctx, Device{
-				AllowMultipleAllocations: scenario.allowMultipleAllocations, Attributes: scenario.attributes, Capacity: scenario.capacity, Driver: scenario.driver,
+				AllowMultipleAllocations: scenario.allowMultipleAllocations, Attributes: scenario.attributes, Capacity: scenario.capacity, Driver: scenario.driver, Taints: scenario.taints, Node: scenario.node,
 			})
 			// details.ActualCost can be called for nil details, no need to check.
 			actualCost := ptr.Deref(details.ActualCost(), 0)
@@ -0,0 +1,93 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/google/cel-go/cel"
+
+	"k8s.io/klog/v2"
+)
+
+// ErrorClass describes why evaluating an expression failed.
+type ErrorClass string
+
+const (
+	// ErrorClassCompile is used when trying to evaluate an expression
+	// which failed to compile.
+	ErrorClassCompile ErrorClass = "Compile"
+	// ErrorClassRuntime is used for errors caused by the expression or
+	// the input, for example a lookup of a missing attribute or
+	// exceeding the cost limit.
+	ErrorClassRuntime ErrorClass = "Runtime"
+	// ErrorClassInternal is used for bugs, most notably panics in the
+	// CEL runtime.
+	ErrorClassInternal ErrorClass = "Internal"
+)
+
+// ExpressionError is returned by [CompilationResult.DeviceMatches] for
+// all failures. The error string is the one of the underlying error,
+// the class and the expression are available after unwrapping with
+// [errors.As].
+type ExpressionError struct {
+	Class      ErrorClass
+	Expression string
+	Err        error
+}
+
+func (e *ExpressionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
+
+// DeviceMatches evaluates the expression for the device. The boolean
+// result is only valid if there is no error. The details are available
+// after trying to evaluate the expression, even if that failed.
+//
+// Panics while evaluating the expression are recovered and returned as
+// error with [ErrorClassInternal], which protects long-running components
+// against crashing because of adversarial expressions that trigger a bug.
+func (c CompilationResult) DeviceMatches(ctx context.Context, input Device) (matches bool, details *cel.EvalDetails, finalErr error) {
+	if c.Error != nil {
+		return false, nil, &ExpressionError{Class: ErrorClassCompile, Expression: c.Expression, Err: c.Error}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			klog.FromContext(ctx).Error(nil, "Recovered from panic while evaluating CEL expression",
+				"expression", truncateExpression(c.Expression),
+				"expressionHash", hashExpression(c.Expression),
+				"panic", r,
+				"stack", string(debug.Stack()),
+			)
+			matches = false
+			finalErr = &ExpressionError{Class: ErrorClassInternal, Expression: c.Expression, Err: fmt.Errorf("internal error: panic during CEL evaluation: %v", r)}
+		}
+	}()
+
+	matches, details, err := c.deviceMatches(ctx, input)
+	if err != nil {
+		return false, details, &ExpressionError{Class: ErrorClassRuntime, Expression: c.Expression, Err: err}
+	}
+	return matches, details, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/klog/v2/ktesting"
+)
+
+// panicProgram simulates a bug in the CEL runtime.
+type panicProgram struct {
+	cel.Program
+}
+
+func (p panicProgram) ContextEval(context.Context, any) (ref.Val, *cel.EvalDetails, error) {
+	panic("fake bug")
+}
+
+func TestDeviceMatchesErrors(t *testing.T) {
+	compiler := GetCompiler(Features{})
+	device := Device{Driver: "dra.example.com"}
+
+	for name, tc := range map[string]struct {
+		result      func(t *testing.T) CompilationResult
+		expectClass ErrorClass
+		expectErr   string
+	}{
+		"compile": {
+			result: func(t *testing.T) CompilationResult {
+				return compiler.CompileCELExpression(`device.driver ==`, Options{})
+			},
+			expectClass: ErrorClassCompile,
+			expectErr:   "compilation failed",
+		},
+		"runtime": {
+			result: func(t *testing.T) CompilationResult {
+				return compiler.CompileCELExpression(`device.attributes["dra.example.com"].missing`, Options{})
+			},
+			expectClass: ErrorClassRuntime,
+			expectErr:   "no such key: missing",
+		},
+		"panic": {
+			result: func(t *testing.T) CompilationResult {
+				result := compiler.CompileCELExpression(`device.driver == "dra.example.com"`, Options{})
+				require.Nil(t, result.Error)
+				result.Program = panicProgram{Program: result.Program}
+				return result
+			},
+			expectClass: ErrorClassInternal,
+			expectErr:   "internal error: panic during CEL evaluation: fake bug",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			result := tc.result(t)
+			matches, _, err := result.DeviceMatches(ctx, device)
+			assert.False(t, matches, "matches")
+			require.ErrorContains(t, err, tc.expectErr)
+			var exprErr *ExpressionError
+			require.True(t, errors.As(err, &exprErr), "ExpressionError")
+			assert.Equal(t, tc.expectClass, exprErr.Class, "error class")
+			assert.Equal(t, result.Expression, exprErr.Expression, "expression")
+		})
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apiserver/pkg/cel/environment"
+	"k8s.io/utils/ptr"
+)
+
+// Severity distinguishes problems which cause an expression to be
+// rejected from those which merely deserve attention.
+type Severity string
+
+const (
+	// SeverityError is used for problems which cause the apiserver
+	// to reject the expression.
+	SeverityError Severity = "Error"
+	// SeverityWarning is used for expressions which are valid, but
+	// might not work as intended, for example because older Kubernetes
+	// releases do not support them.
+	SeverityWarning Severity = "Warning"
+)
+
+// Diagnostic describes one problem found by [compiler.Lint].
+type Diagnostic struct {
+	Severity Severity
+	// Line is the line number in the expression, starting at 1.
+	// Zero if the problem is not tied to a certain position.
+	Line int
+	// Column is the column in the line, starting at 1.
+	// Zero if the problem is not tied to a certain position.
+	Column int
+	// Message explains the problem.
+	Message string
+	// Suggestion is an optional hint how to fix the problem.
+	Suggestion string
+}
+
+// String formats the diagnostic as "<line>:<column>: <severity>: <message> (<suggestion>)",
+// with the optional parts left out when empty.
+func (d Diagnostic) String() string {
+	var buffer strings.Builder
+	if d.Line > 0 {
+		fmt.Fprintf(&buffer, "%d:%d: ", d.Line, d.Column)
+	}
+	fmt.Fprintf(&buffer, "%s: %s", d.Severity, d.Message)
+	if d.Suggestion != "" {
+		fmt.Fprintf(&buffer, " (%s)", d.Suggestion)
+	}
+	return buffer.String()
+}
+
+var (
+	undeclaredReferenceRE = regexp.MustCompile(`undeclared reference to '([^']*)'`)
+	undefinedFieldRE      = regexp.MustCompile(`undefined field '([^']*)'`)
+)
+
+// Lint checks a device selector expression and returns all problems
+// that it finds, sorted by severity. An empty result means that the
+// expression is fine. It is meant for tools like kubectl plugins or
+// the CI of a driver which validate DeviceClass or DeviceTaintRule
+// specs before they get applied.
+//
+// In contrast to [compiler.CompileCELExpression], Lint also warns about
+// expressions which only work in newer Kubernetes releases and about
+// expressions which exceed the cost limit. Options.EnvType is ignored:
+// errors are reported for the [environment.StoredExpressions]
+// environment, warnings for the [environment.NewExpressions]
+// environment of Options.CompatibilityVersion.
+func (c compiler) Lint(expression string, options Options) []Diagnostic {
+	stored := options
+	stored.EnvType = ptr.To(environment.StoredExpressions)
+	stored.DisableCostEstimation = false
+	result := c.CompileCELExpression(expression, stored)
+	if result.Error != nil {
+		return c.compileDiagnostics(expression, stored)
+	}
+
+	var diagnostics []Diagnostic
+	costLimit := ptr.Deref(options.CostLimit, resourceapi.CELSelectorExpressionMaxCost)
+	if result.MaxCost > costLimit {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("estimated worst-case cost %d exceeds the limit of %d", result.MaxCost, costLimit),
+			Suggestion: "avoid macros like all or exists over maps and lists with many entries",
+		})
+	}
+
+	newExpressions := options
+	newExpressions.EnvType = ptr.To(environment.NewExpressions)
+	newExpressions.DisableCostEstimation = true
+	for _, diagnostic := range c.compileDiagnostics(expression, newExpressions) {
+		diagnostic.Severity = SeverityWarning
+		diagnostic.Message = "not supported by all Kubernetes releases which are currently allowed to run the control plane: " + diagnostic.Message
+		diagnostic.Suggestion = "only use this once the cluster no longer needs to be downgraded"
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics
+}
+
+// compileDiagnostics converts the issues found by the CEL compiler.
+func (c compiler) compileDiagnostics(expression string, options Options) []Diagnostic {
+	env, err := c.env(options)
+	if err != nil {
+		return []Diagnostic{{Severity: SeverityError, Message: err.Error()}}
+	}
+	_, issues := env.Compile(expression)
+	if issues == nil || len(issues.Errors()) == 0 {
+		// Problems after parsing and type checking, like the wrong
+		// result type, are handled by CompileCELExpression.
+		options.DisableCostEstimation = true
+		if result := c.CompileCELExpression(expression, options); result.Error != nil {
+			return []Diagnostic{{Severity: SeverityError, Message: result.Error.Detail}}
+		}
+		return nil
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(issues.Errors()))
+	for _, issue := range issues.Errors() {
+		diagnostic := Diagnostic{
+			Severity:   SeverityError,
+			Message:    issue.Message,
+			Suggestion: suggestFix(env, issue.Message),
+		}
+		if issue.Location != nil && issue.Location.Line() > 0 {
+			diagnostic.Line = issue.Location.Line()
+			diagnostic.Column = issue.Location.Column() + 1
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics
+}
+
+// suggestFix recognizes some common mistakes. The hints are based on
+// the variables and their fields in the environment, so for example
+// device.taints and node only get suggested when they are available.
+func suggestFix(env *cel.Env, message string) string {
+	variables, fields := declaredVariables(env)
+	if match := undeclaredReferenceRE.FindStringSubmatch(message); match != nil {
+		name := match[1]
+		for _, variable := range variables {
+			if slices.Contains(fields[variable], name) {
+				return fmt.Sprintf("use %s.%s", variable, name)
+			}
+		}
+		switch len(variables) {
+		case 0:
+			return fmt.Sprintf("%q is neither a variable nor a function", name)
+		case 1:
+			return fmt.Sprintf("%q is neither a variable nor a function, the only variable is %q", name, variables[0])
+		default:
+			return fmt.Sprintf("%q is neither a variable nor a function, the variables are %s", name, quoteAll(variables))
+		}
+	}
+	if match := undefinedFieldRE.FindStringSubmatch(message); match != nil {
+		name := match[1]
+		for _, variable := range variables {
+			for _, field := range fields[variable] {
+				if strings.EqualFold(name, field) {
+					return fmt.Sprintf("use %q, field names are case-sensitive", field)
+				}
+			}
+		}
+		return fmt.Sprintf("attributes and capacities must be looked up in device.%s[<domain>] and device.%s[<domain>]", attributesVar, capacityVar)
+	}
+	return ""
+}
+
+// declaredVariables returns the sorted names of the variables in the
+// environment and the sorted field names of those variables which are
+// objects. Type names, which are also variables in CEL, are skipped.
+func declaredVariables(env *cel.Env) ([]string, map[string][]string) {
+	var variables []string
+	fields := make(map[string][]string)
+	for _, variable := range env.Variables() {
+		if variable.Type().Kind() == types.TypeKind {
+			continue
+		}
+		// A variable may be declared more than once, the last
+		// declaration wins.
+		if !slices.Contains(variables, variable.Name()) {
+			variables = append(variables, variable.Name())
+		}
+		if variable.Type().Kind() == types.StructKind {
+			fields[variable.Name()], _ = structFields(env, variable.Type().TypeName())
+		}
+	}
+	slices.Sort(variables)
+	return variables, fields
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+	return strings.Join(quoted, ", ")
+}
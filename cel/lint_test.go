@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/utils/ptr"
+)
+
+func TestLint(t *testing.T) {
+	for name, tc := range map[string]struct {
+		features   Features
+		expression string
+		options    Options
+		expect     []Diagnostic
+	}{
+		"valid": {
+			expression: `device.driver == "dra.example.com"`,
+		},
+		"syntax-error": {
+			expression: "device.driver ==\n  ",
+			expect: []Diagnostic{{
+				Severity: SeverityError,
+				Line:     2,
+				Column:   3,
+				Message:  "Syntax error: mismatched input '<EOF>' expecting {'[', '{', '(', '.', '-', '!', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER}",
+			}},
+		},
+		"missing-device": {
+			expression: `driver == "dra.example.com"`,
+			expect: []Diagnostic{{
+				Severity:   SeverityError,
+				Line:       1,
+				Column:     1,
+				Message:    "undeclared reference to 'driver' (in container '')",
+				Suggestion: "use device.driver",
+			}},
+		},
+		"wrong-case": {
+			expression: `device.Driver == "dra.example.com"`,
+			expect: []Diagnostic{{
+				Severity:   SeverityError,
+				Line:       1,
+				Column:     7,
+				Message:    "undefined field 'Driver'",
+				Suggestion: `use "driver", field names are case-sensitive`,
+			}},
+		},
+		"wrong-case-taints": {
+			expression: `device.Taints.size() == 0`,
+			expect: []Diagnostic{{
+				Severity:   SeverityError,
+				Line:       1,
+				Column:     7,
+				Message:    "undefined field 'Taints'",
+				Suggestion: `use "taints", field names are case-sensitive`,
+			}},
+		},
+		"missing-node": {
+			features:   Features{EnableNodeVariable: true},
+			expression: `zone == "a"`,
+			expect: []Diagnostic{{
+				Severity:   SeverityError,
+				Line:       1,
+				Column:     1,
+				Message:    "undeclared reference to 'zone' (in container '')",
+				Suggestion: "use node.zone",
+			}},
+		},
+		"unknown-variable": {
+			features:   Features{EnableNodeVariable: true},
+			expression: `host.name == "worker"`,
+			expect: []Diagnostic{{
+				Severity:   SeverityError,
+				Line:       1,
+				Column:     1,
+				Message:    "undeclared reference to 'host' (in container '')",
+				Suggestion: `"host" is neither a variable nor a function, the variables are "device", "node"`,
+			}},
+		},
+		"wrong-type": {
+			expression: `device.driver`,
+			expect: []Diagnostic{{
+				Severity: SeverityError,
+				Message:  "must evaluate to bool or the unknown type, not string",
+			}},
+		},
+		"cost": {
+			expression: `device.attributes.all(domain, device.attributes[domain].all(id, device.capacity.exists(d, d == domain)))`,
+			options:    Options{CostLimit: ptr.To(uint64(100))},
+			expect: []Diagnostic{{
+				Severity:   SeverityError,
+				Message:    "estimated worst-case cost 432387 exceeds the limit of 100",
+				Suggestion: "avoid macros like all or exists over maps and lists with many entries",
+			}},
+		},
+		"new-function": {
+			expression: `semverIsAtLeast("1.2.3", "1.0.0")`,
+			options:    Options{CompatibilityVersion: version.MajorMinor(1, 34)},
+			expect: []Diagnostic{{
+				Severity:   SeverityWarning,
+				Line:       1,
+				Column:     16,
+				Message:    "not supported by all Kubernetes releases which are currently allowed to run the control plane: undeclared reference to 'semverIsAtLeast' (in container '')",
+				Suggestion: "only use this once the cluster no longer needs to be downgraded",
+			}},
+		},
+		"new-function-supported": {
+			expression: `semverIsAtLeast("1.2.3", "1.0.0")`,
+			options:    Options{CompatibilityVersion: version.MajorMinor(1, 35)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			diagnostics := GetCompiler(tc.features).Lint(tc.expression, tc.options)
+			assert.Equal(t, tc.expect, diagnostics)
+		})
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	assert.Equal(t, "1:7: Error: undefined field 'Driver' (use \"driver\")", Diagnostic{Severity: SeverityError, Line: 1, Column: 7, Message: "undefined field 'Driver'", Suggestion: `use "driver"`}.String())
+	assert.Equal(t, "Warning: too slow", Diagnostic{Severity: SeverityWarning, Message: "too slow"}.String())
+}
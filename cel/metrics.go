@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/metrics"
+	drametrics "k8s.io/dynamic-resource-allocation/metrics"
+)
+
+const (
+	metricsNamespace = drametrics.Namespace
+	metricsSubsystem = drametrics.SubsystemCEL
+)
+
+var (
+	cacheHits = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "cache_hits_total",
+			Help:           "Number of CEL expression lookups which were served from a compilation cache.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	cacheMisses = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "cache_misses_total",
+			Help:           "Number of CEL expression lookups which required compiling the expression.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	cacheEvictions = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "cache_evictions_total",
+			Help:           "Number of compiled CEL expressions which were removed from a compilation cache because it was full.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	evaluationDuration = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "evaluation_duration_seconds",
+			Help:           "Duration of evaluating a CEL device selector expression for one device.",
+			Buckets:        metrics.ExponentialBuckets(0.00001, 4, 10),
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	evaluationErrors = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "evaluation_errors_total",
+			Help:           "Number of CEL device selector evaluations which failed with an error.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+)
+
+func init() {
+	drametrics.Add(metricsSubsystem, cacheHits, cacheMisses, cacheEvictions, evaluationDuration, evaluationErrors)
+}
+
+// RegisterMetrics registers the metrics of this package in the legacy
+// registry of k8s.io/component-base/metrics. The metrics cover all
+// instances of [Cache] and all evaluations with
+// [CompilationResult.DeviceMatches]. Calling it more than once is okay.
+//
+// [drametrics.RegisterMetrics] registers the metrics of all packages.
+func RegisterMetrics() {
+	utilruntime.Must(drametrics.RegisterMetrics(drametrics.LegacyRegistry, drametrics.EnableSubsystems(metricsSubsystem)))
+}
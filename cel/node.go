@@ -0,0 +1,94 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"github.com/google/cel-go/cel"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+	apiservercel "k8s.io/apiserver/pkg/cel"
+	"k8s.io/apiserver/pkg/cel/environment"
+)
+
+const (
+	nodeVar       = "node"
+	nodeNameVar   = "name"
+	nodeLabelsVar = "labels"
+	nodeZoneVar   = "zone"
+
+	nodeVarDoc = "The node for which devices are getting selected. Only available if enabled by the caller, not supported by Kubernetes."
+
+	// maxNodeLabels is an assumption for cost estimation. There is no
+	// limit for the number of labels in the Kubernetes API.
+	maxNodeLabels = 256
+)
+
+var (
+	nodeNameType   = withMaxElements(apiservercel.StringType, uint64(validation.DNS1123SubdomainMaxLength))
+	nodeLabelsType = apiservercel.NewMapType(labelKeyType, labelValueType, maxNodeLabels)
+
+	nodeType = apiservercel.NewObjectType("kubernetes.DRANode", map[string]*apiservercel.DeclField{
+		nodeNameVar:   apiservercel.NewDeclField(nodeNameVar, nodeNameType, true, nil, nil),
+		nodeLabelsVar: apiservercel.NewDeclField(nodeLabelsVar, nodeLabelsType, true, nil, nil),
+		nodeZoneVar:   apiservercel.NewDeclField(nodeZoneVar, labelValueType, true, nil, nil),
+	})
+)
+
+// Node defines the node-level input values for a CEL selector expression.
+// They are only available if [Features.EnableNodeVariable] is set.
+type Node struct {
+	Name   string
+	Labels map[string]string
+	// Zone defaults to the value of the topology.kubernetes.io/zone label.
+	Zone string
+}
+
+// nodeVariable returns the optional versioned options for the node variable.
+func nodeVariable(features Features) []environment.VersionedOptions {
+	if !features.EnableNodeVariable {
+		return nil
+	}
+	return []environment.VersionedOptions{{
+		// Not a Kubernetes feature, so it is available in all environments
+		// once enabled.
+		IntroducedVersion: version.MajorMinor(1, 31),
+		EnvOptions: []cel.EnvOption{
+			cel.VariableWithDoc(nodeVar, nodeType.CelType(), nodeVarDoc),
+		},
+		DeclTypes: []*apiservercel.DeclType{
+			nodeType,
+		},
+	}}
+}
+
+func (n *Node) value() map[string]any {
+	zone := n.Zone
+	if zone == "" {
+		zone = n.Labels[v1.LabelTopologyZone]
+	}
+	labels := n.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return map[string]any{
+		nodeNameVar:   n.Name,
+		nodeLabelsVar: labels,
+		nodeZoneVar:   zone,
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/types"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+
+	apiservercel "k8s.io/apiserver/pkg/cel"
+	"k8s.io/apiserver/pkg/cel/environment"
+	"k8s.io/utils/ptr"
+)
+
+// precompiledFormat gets bumped when the content of precompiled
+// changes in an incompatible way.
+const precompiledFormat = 1
+
+// precompiled is the serialized form of a type-checked expression.
+type precompiled struct {
+	Format     int    `json:"format"`
+	Expression string `json:"expression"`
+	// Environment identifies the environment that the expression was
+	// checked against. It must match when loading.
+	Environment string `json:"environment"`
+	// CheckedExpr is the type-checked AST as CheckedExpr protobuf.
+	CheckedExpr []byte `json:"checkedExpr"`
+}
+
+// Precompile parses and type-checks the expression and returns the
+// result in a serialized form. [compiler.LoadPrecompiled] turns that
+// back into a [CompilationResult] without repeating these steps, which
+// are the expensive part of compiling. Components like the scheduler can
+// ship precompiled selectors of well-known DeviceClasses to avoid
+// a spike in CPU usage after a restart.
+//
+// Precompiled data can only be loaded by the same version of this
+// package with the same features and options. Callers must
+// be prepared to fall back to compiling the expression.
+func (c compiler) Precompile(expression string, options Options) ([]byte, error) {
+	_, ast, errResult := c.check(expression, options)
+	if errResult != nil {
+		return nil, errResult.Error
+	}
+	checkedExpr, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return nil, fmt.Errorf("convert AST: %w", err)
+	}
+	checkedExprData, err := proto.Marshal(checkedExpr)
+	if err != nil {
+		return nil, fmt.Errorf("marshal checked expression: %w", err)
+	}
+	envID, err := c.environmentID(options)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(precompiled{
+		Format:      precompiledFormat,
+		Expression:  expression,
+		Environment: envID,
+		CheckedExpr: checkedExprData,
+	})
+}
+
+// LoadPrecompiled is the counterpart of [compiler.Precompile]. The
+// result is the same as for [compiler.CompileCELExpression] with the
+// same expression and options, except that an error is returned if the
+// data is invalid or was created for a different environment.
+func (c compiler) LoadPrecompiled(data []byte, options Options) CompilationResult {
+	var pre precompiled
+	if err := json.Unmarshal(data, &pre); err != nil {
+		return errorResult("", "decode precompiled expression: "+err.Error(), apiservercel.ErrorTypeInvalid)
+	}
+	if pre.Format != precompiledFormat {
+		return errorResult(pre.Expression, fmt.Sprintf("unsupported precompiled format %d, expected %d", pre.Format, precompiledFormat), apiservercel.ErrorTypeInvalid)
+	}
+	expected, err := c.environmentID(options)
+	if err != nil {
+		return errorResult(pre.Expression, fmt.Sprintf("unexpected error loading CEL environment: %v", err), apiservercel.ErrorTypeInternal)
+	}
+	if pre.Environment != expected {
+		return errorResult(pre.Expression, fmt.Sprintf("expression was precompiled for environment %q, need %q", pre.Environment, expected), apiservercel.ErrorTypeInvalid)
+	}
+	var checkedExpr exprpb.CheckedExpr
+	if err := proto.Unmarshal(pre.CheckedExpr, &checkedExpr); err != nil {
+		return errorResult(pre.Expression, "unmarshal checked expression: "+err.Error(), apiservercel.ErrorTypeInvalid)
+	}
+	ast, err := cel.CheckedExprToAstWithSource(&checkedExpr, common.NewTextSource(pre.Expression))
+	if err != nil {
+		return errorResult(pre.Expression, "convert checked expression: "+err.Error(), apiservercel.ErrorTypeInvalid)
+	}
+	env, err := c.env(options)
+	if err != nil {
+		return errorResult(pre.Expression, fmt.Sprintf("unexpected error loading CEL environment: %v", err), apiservercel.ErrorTypeInternal)
+	}
+	return c.newResult(env, ast, pre.Expression, options)
+}
+
+// environmentID describes everything that influences type checking.
+// Besides features and options, it includes a hash of the declarations
+// because those may change between versions of this package without
+// changing anything else.
+func (c compiler) environmentID(options Options) (string, error) {
+	env, err := c.env(options)
+	if err != nil {
+		return "", err
+	}
+	id := fmt.Sprintf("%+v", c.features)
+	if options.CompatibilityVersion != nil {
+		id += fmt.Sprintf("/%d.%d/%s", options.CompatibilityVersion.Major(), options.CompatibilityVersion.Minor(), ptr.Deref(options.EnvType, environment.NewExpressions))
+	} else {
+		id += fmt.Sprintf("/%s", ptr.Deref(options.EnvType, environment.StoredExpressions))
+	}
+	return id + "/" + c.declarationsHash(env), nil
+}
+
+// declarationsHash returns a hash of the variables, the fields of their
+// types and the functions of the environment. It gets computed only once
+// per environment.
+func (c compiler) declarationsHash(env *cel.Env) string {
+	if hash, ok := c.declarationHashes.Load(env); ok {
+		return hash.(string)
+	}
+
+	var decls []string
+	seen := make(map[string]bool)
+	var addFields func(t *types.Type)
+	addFields = func(t *types.Type) {
+		for _, param := range t.Parameters() {
+			addFields(param)
+		}
+		if t.Kind() != types.StructKind || seen[t.TypeName()] {
+			return
+		}
+		seen[t.TypeName()] = true
+		fieldNames, fields := structFields(env, t.TypeName())
+		for _, fieldName := range fieldNames {
+			fieldType := fields[fieldName].Type.CelType()
+			decls = append(decls, fmt.Sprintf("field %s.%s %s", t.TypeName(), fieldName, fieldType))
+			addFields(fieldType)
+		}
+	}
+	for _, variable := range env.Variables() {
+		decls = append(decls, fmt.Sprintf("var %s %s", variable.Name(), variable.Type()))
+		addFields(variable.Type())
+	}
+	for name, function := range env.Functions() {
+		for _, overload := range function.OverloadDecls() {
+			decls = append(decls, fmt.Sprintf("func %s %s %v %s", name, overload.ID(), overload.ArgTypes(), overload.ResultType()))
+		}
+	}
+	slices.Sort(decls)
+	sum := sha256.Sum256([]byte(strings.Join(decls, "\n")))
+	hash := hex.EncodeToString(sum[:8])
+	actual, _ := c.declarationHashes.LoadOrStore(env, hash)
+	return actual.(string)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestPrecompiled(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	compiler := GetCompiler(Features{})
+	expression := `device.attributes["dra.example.com"].model.startsWith("gpu") && semverIsAtLeast(device.attributes["dra.example.com"].firmware, "1.2.0")`
+	device := Device{
+		Driver: "dra.example.com",
+		Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+			"model":    {StringValue: ptr.To("gpu-1")},
+			"firmware": {VersionValue: ptr.To("1.2.3")},
+		},
+	}
+
+	data, err := compiler.Precompile(expression, Options{})
+	require.NoError(t, err, "precompile")
+
+	result := compiler.LoadPrecompiled(data, Options{})
+	require.Nil(t, result.Error, "load")
+	expected := compiler.CompileCELExpression(expression, Options{})
+	require.Nil(t, expected.Error, "compile")
+	assert.Equal(t, expression, result.Expression)
+	assert.Equal(t, expected.MaxCost, result.MaxCost, "cost estimate")
+	matches, _, err := result.DeviceMatches(ctx, device)
+	require.NoError(t, err, "evaluate")
+	assert.True(t, matches, "matches")
+
+	result = GetCompiler(Features{EnableConsumableCapacity: true}).LoadPrecompiled(data, Options{})
+	require.NotNil(t, result.Error, "other features")
+	assert.Contains(t, result.Error.Error(), "expression was precompiled for environment")
+	result = compiler.LoadPrecompiled(data, Options{CompatibilityVersion: version.MajorMinor(1, 35)})
+	require.NotNil(t, result.Error, "other options")
+
+	// The declarations are part of the environment, so the same options
+	// with a different set of variables or functions don't match.
+	oldEnv, err := compiler.env(Options{CompatibilityVersion: version.MajorMinor(1, 31)})
+	require.NoError(t, err, "1.31 environment")
+	newEnv, err := compiler.env(Options{CompatibilityVersion: version.MajorMinor(1, 36)})
+	require.NoError(t, err, "1.36 environment")
+	assert.NotEqual(t, compiler.declarationsHash(oldEnv), compiler.declarationsHash(newEnv), "declarations hash")
+	assert.Equal(t, compiler.declarationsHash(newEnv), compiler.declarationsHash(newEnv), "stable declarations hash")
+
+	result = compiler.LoadPrecompiled([]byte("{"), Options{})
+	require.NotNil(t, result.Error, "invalid data")
+	assert.Contains(t, result.Error.Error(), "decode precompiled expression")
+
+	_, err = compiler.Precompile(`device.driver ==`, Options{})
+	require.ErrorContains(t, err, "compilation failed")
+}
+
+func TestCacheAddPrecompiled(t *testing.T) {
+	cache := NewCache(2, Features{})
+	data, err := cache.compiler.Precompile(`device.driver == "dra.example.com"`, Options{})
+	require.NoError(t, err, "precompile")
+	require.NoError(t, cache.AddPrecompiled(data), "add")
+
+	result := cache.GetOrCompile(`device.driver == "dra.example.com"`)
+	require.Nil(t, result.Error)
+	assert.Equal(t, CacheStats{Hits: 1, Entries: 1, MaxEntries: 2}, cache.Stats())
+
+	require.Error(t, cache.AddPrecompiled([]byte("{}")), "invalid data")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	apiservercel "k8s.io/apiserver/pkg/cel"
+)
+
+// quantityOperators returns overloads of the standard CEL operators for
+// quantities. The Kubernetes quantity library only has methods
+// like isGreaterThan and add. With these overloads, capacity can also be
+// used in arithmetic expressions and comparisons:
+//
+//	device.capacity["dra.example.com"].memory >= quantity("40Gi") * 2
+//	device.capacity["dra.example.com"].memory - quantity("1Gi") > quantity("8Gi")
+//
+// The standard operators are implemented by a single function in cel-go,
+// which cannot be combined with additional bindings. Therefore these are
+// declarations for the type checker, the implementation gets injected by
+// [quantityOperatorDecorator] when creating a program.
+func quantityOperators() []cel.EnvOption {
+	quantityType := apiservercel.QuantityType
+	return []cel.EnvOption{
+		cel.Function(operators.Less, cel.Overload(quantityLessOverload, []*cel.Type{quantityType, quantityType}, cel.BoolType)),
+		cel.Function(operators.LessEquals, cel.Overload(quantityLessEqualsOverload, []*cel.Type{quantityType, quantityType}, cel.BoolType)),
+		cel.Function(operators.Greater, cel.Overload(quantityGreaterOverload, []*cel.Type{quantityType, quantityType}, cel.BoolType)),
+		cel.Function(operators.GreaterEquals, cel.Overload(quantityGreaterEqualsOverload, []*cel.Type{quantityType, quantityType}, cel.BoolType)),
+		cel.Function(operators.Add, cel.Overload(quantityAddOverload, []*cel.Type{quantityType, quantityType}, quantityType)),
+		cel.Function(operators.Subtract, cel.Overload(quantitySubtractOverload, []*cel.Type{quantityType, quantityType}, quantityType)),
+		cel.Function(operators.Multiply,
+			cel.Overload(quantityMultiplyIntOverload, []*cel.Type{quantityType, cel.IntType}, quantityType),
+			cel.Overload(intMultiplyQuantityOverload, []*cel.Type{cel.IntType, quantityType}, quantityType),
+		),
+	}
+}
+
+const (
+	quantityLessOverload          = "dra_less_quantity"
+	quantityLessEqualsOverload    = "dra_less_equals_quantity"
+	quantityGreaterOverload       = "dra_greater_quantity"
+	quantityGreaterEqualsOverload = "dra_greater_equals_quantity"
+	quantityAddOverload           = "dra_add_quantity"
+	quantitySubtractOverload      = "dra_subtract_quantity"
+	quantityMultiplyIntOverload   = "dra_multiply_quantity_int"
+	intMultiplyQuantityOverload   = "dra_multiply_int_quantity"
+)
+
+var quantityOperatorImpls = map[string]func(lhs, rhs ref.Val) ref.Val{
+	quantityLessOverload:          quantityCompare(func(c int) bool { return c < 0 }),
+	quantityLessEqualsOverload:    quantityCompare(func(c int) bool { return c <= 0 }),
+	quantityGreaterOverload:       quantityCompare(func(c int) bool { return c > 0 }),
+	quantityGreaterEqualsOverload: quantityCompare(func(c int) bool { return c >= 0 }),
+	quantityAddOverload:           quantityAdd,
+	quantitySubtractOverload:      quantitySubtract,
+	quantityMultiplyIntOverload:   quantityMultiply,
+	intMultiplyQuantityOverload:   func(lhs, rhs ref.Val) ref.Val { return quantityMultiply(rhs, lhs) },
+}
+
+// quantityOperatorDecorator replaces the implementation of operator calls
+// for which the type checker picked one of the quantity overloads.
+func quantityOperatorDecorator(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+	call, ok := i.(interpreter.InterpretableCall)
+	if !ok {
+		return i, nil
+	}
+	impl, ok := quantityOperatorImpls[call.OverloadID()]
+	if !ok || len(call.Args()) != 2 {
+		return i, nil
+	}
+	return interpreter.NewCall(call.ID(), call.Function(), call.OverloadID(), call.Args(), func(args ...ref.Val) ref.Val {
+		return impl(args[0], args[1])
+	}), nil
+}
+
+func quantityCompare(accept func(c int) bool) func(lhs, rhs ref.Val) ref.Val {
+	return func(lhs, rhs ref.Val) ref.Val {
+		left, ok := lhs.(apiservercel.Quantity)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(lhs)
+		}
+		right, ok := rhs.(apiservercel.Quantity)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(rhs)
+		}
+		return types.Bool(accept(left.Quantity.Cmp(*right.Quantity)))
+	}
+}
+
+func quantityAdd(lhs, rhs ref.Val) ref.Val {
+	left, ok := lhs.(apiservercel.Quantity)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(lhs)
+	}
+	right, ok := rhs.(apiservercel.Quantity)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(rhs)
+	}
+	result := left.Quantity.DeepCopy()
+	result.Add(*right.Quantity)
+	return apiservercel.Quantity{Quantity: &result}
+}
+
+func quantitySubtract(lhs, rhs ref.Val) ref.Val {
+	left, ok := lhs.(apiservercel.Quantity)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(lhs)
+	}
+	right, ok := rhs.(apiservercel.Quantity)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(rhs)
+	}
+	result := left.Quantity.DeepCopy()
+	result.Sub(*right.Quantity)
+	return apiservercel.Quantity{Quantity: &result}
+}
+
+func quantityMultiply(lhs, rhs ref.Val) ref.Val {
+	left, ok := lhs.(apiservercel.Quantity)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(lhs)
+	}
+	factor, ok := rhs.(types.Int)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(rhs)
+	}
+	result := left.Quantity.DeepCopy()
+	if !result.Mul(int64(factor)) {
+		return types.NewErr("quantity multiplication overflow")
+	}
+	return apiservercel.Quantity{Quantity: &result}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+const deviceVarDoc = "The device which is checked by the selector."
+
+// fieldDocs documents the fields of the variables.
+var fieldDocs = map[string]map[string]string{
+	deviceVar: deviceFieldDocs,
+	nodeVar:   nodeFieldDocs,
+}
+
+var deviceFieldDocs = map[string]string{
+	driverVar:     "The name of the DRA driver which provides the device.",
+	multiAllocVar: "True if the device may be allocated for more than one claim at the same time.",
+	attributesVar: `Attributes of the device, grouped by domain: device.attributes["dra.example.com"].model. ` +
+		"Attributes without a domain in the ResourceSlice use the driver name as domain. " +
+		"Looking up a missing domain returns an empty map, looking up a missing attribute is an error.",
+	capacityVar: `Capacity of the device as quantities, grouped by domain like the attributes: device.capacity["dra.example.com"].memory.`,
+	taintsVar:   `Taints of the device as list of objects with key, value and effect: !device.taints.exists(t, t.effect == "NoExecute").`,
+}
+
+var nodeFieldDocs = map[string]string{
+	nodeNameVar:   "The name of the node.",
+	nodeLabelsVar: "The labels of the node.",
+	nodeZoneVar:   "The zone of the node, by default the value of the topology.kubernetes.io/zone label.",
+}
+
+// Schema describes what can be used in device selector expressions.
+type Schema struct {
+	// Variables are sorted by name.
+	Variables []VariableSchema
+	// Functions are sorted by name. Operators are not included.
+	Functions []FunctionSchema
+}
+
+// VariableSchema describes one variable.
+type VariableSchema struct {
+	Name string
+	Type string
+	Doc  string
+	// Fields are sorted by name.
+	Fields []FieldSchema
+}
+
+// FieldSchema describes one field of a variable.
+type FieldSchema struct {
+	Name string
+	Type string
+	Doc  string
+}
+
+// FunctionSchema describes one function and its overloads.
+type FunctionSchema struct {
+	Name string
+	Doc  string
+	// Signatures contains one entry per overload, for example
+	// "semverIsAtLeast(string, string) -> bool" or
+	// "string.startsWith(string) -> bool" for member functions.
+	// They are sorted alphabetically.
+	Signatures []string
+}
+
+// Schema returns the variables and functions available in the
+// environment selected by the options, for example to implement
+// autocompletion for selector expressions. The content depends on the
+// features of the compiler and on Options.EnvType and
+// Options.CompatibilityVersion.
+func (c compiler) Schema(options Options) (Schema, error) {
+	env, err := c.env(options)
+	if err != nil {
+		return Schema{}, fmt.Errorf("load CEL environment: %w", err)
+	}
+
+	var schema Schema
+	variables := make(map[string]VariableSchema)
+	for _, variable := range env.Variables() {
+		// Type names like "int" are also variables, but not
+		// interesting for users.
+		if variable.Type().Kind() == types.TypeKind {
+			continue
+		}
+		variableSchema := VariableSchema{
+			Name: variable.Name(),
+			Type: variable.Type().String(),
+			Doc:  variable.Description(),
+		}
+		// The provider cannot list the fields, so we have to check
+		// which of the known fields are defined.
+		if docs, ok := fieldDocs[variable.Name()]; ok {
+			for _, fieldName := range slices.Sorted(maps.Keys(docs)) {
+				fieldType, ok := env.CELTypeProvider().FindStructFieldType(variable.Type().TypeName(), fieldName)
+				if !ok {
+					continue
+				}
+				variableSchema.Fields = append(variableSchema.Fields, FieldSchema{
+					Name: fieldName,
+					Type: fieldType.Type.String(),
+					Doc:  docs[fieldName],
+				})
+			}
+		}
+		// Redeclaring a variable replaces the previous declaration.
+		variables[variable.Name()] = variableSchema
+	}
+	for _, name := range slices.Sorted(maps.Keys(variables)) {
+		schema.Variables = append(schema.Variables, variables[name])
+	}
+
+	for name, function := range env.Functions() {
+		// Operators and internal functions have names like _+_ or cel.@mapInsert.
+		if !unicode.IsLetter([]rune(name)[0]) || strings.Contains(name, "@") {
+			continue
+		}
+		functionSchema := FunctionSchema{
+			Name: name,
+			Doc:  function.Description(),
+		}
+		for _, overload := range function.OverloadDecls() {
+			functionSchema.Signatures = append(functionSchema.Signatures, signature(name, overload.IsMemberFunction(), overload.ArgTypes(), overload.ResultType()))
+		}
+		slices.Sort(functionSchema.Signatures)
+		functionSchema.Signatures = slices.Compact(functionSchema.Signatures)
+		schema.Functions = append(schema.Functions, functionSchema)
+	}
+	slices.SortFunc(schema.Functions, func(a, b FunctionSchema) int { return strings.Compare(a.Name, b.Name) })
+
+	return schema, nil
+}
+
+func signature(name string, member bool, argTypes []*cel.Type, resultType *cel.Type) string {
+	var buffer strings.Builder
+	if member && len(argTypes) > 0 {
+		buffer.WriteString(argTypes[0].String())
+		buffer.WriteString(".")
+		argTypes = argTypes[1:]
+	}
+	buffer.WriteString(name)
+	buffer.WriteString("(")
+	for i, argType := range argTypes {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(argType.String())
+	}
+	buffer.WriteString(") -> ")
+	buffer.WriteString(resultType.String())
+	return buffer.String()
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apiserver/pkg/cel/environment"
+	"k8s.io/utils/ptr"
+)
+
+func TestSchema(t *testing.T) {
+	schema, err := GetCompiler(Features{EnableConsumableCapacity: true}).Schema(Options{})
+	require.NoError(t, err)
+
+	require.Len(t, schema.Variables, 1, "variables")
+	device := schema.Variables[0]
+	assert.Equal(t, deviceVar, device.Name)
+	assert.Equal(t, deviceVarDoc, device.Doc)
+	var fieldNames []string
+	for _, field := range device.Fields {
+		fieldNames = append(fieldNames, field.Name)
+		assert.NotEmpty(t, field.Doc, "doc of field %s", field.Name)
+	}
+	assert.Equal(t, []string{multiAllocVar, attributesVar, capacityVar, driverVar, taintsVar}, fieldNames)
+	assert.Equal(t, FieldSchema{Name: driverVar, Type: "string", Doc: deviceFieldDocs[driverVar]}, device.Fields[3])
+
+	index := slices.IndexFunc(schema.Functions, func(function FunctionSchema) bool { return function.Name == "semverIsAtLeast" })
+	require.NotEqual(t, -1, index, "semverIsAtLeast")
+	semverIsAtLeast := schema.Functions[index]
+	assert.NotEmpty(t, semverIsAtLeast.Doc)
+	assert.Contains(t, semverIsAtLeast.Signatures, "semverIsAtLeast(kubernetes.Semver, string) -> bool")
+	assert.True(t, slices.IsSortedFunc(schema.Functions, func(a, b FunctionSchema) int {
+		if a.Name < b.Name {
+			return -1
+		}
+		return 1
+	}), "sorted functions")
+	assert.False(t, slices.ContainsFunc(schema.Functions, func(function FunctionSchema) bool { return function.Name == "_+_" }), "operators")
+
+	index = slices.IndexFunc(schema.Functions, func(function FunctionSchema) bool { return function.Name == "startsWith" })
+	require.NotEqual(t, -1, index, "startsWith")
+	assert.Equal(t, []string{"string.startsWith(string) -> bool"}, schema.Functions[index].Signatures)
+}
+
+func TestSchemaCompatibilityVersion(t *testing.T) {
+	compiler := GetCompiler(Features{})
+	for ver, expectSemver := range map[string]bool{"1.34": false, "1.35": true} {
+		t.Run(ver, func(t *testing.T) {
+			schema, err := compiler.Schema(Options{EnvType: ptr.To(environment.NewExpressions), CompatibilityVersion: version.MustParseGeneric(ver)})
+			require.NoError(t, err)
+			assert.Equal(t, expectSemver, slices.ContainsFunc(schema.Functions, func(function FunctionSchema) bool { return function.Name == "semverIsAtLeast" }))
+
+			require.Len(t, schema.Variables, 1, "variables")
+			for _, field := range schema.Variables[0].Fields {
+				assert.NotEqual(t, multiAllocVar, field.Name, "field should be disabled by feature gate")
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"github.com/blang/semver/v4"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	apiservercel "k8s.io/apiserver/pkg/cel"
+)
+
+// semverFunctions returns the DRA-specific functions for comparing semantic
+// versions. Version attributes are available as semver values, but the
+// version they get compared against usually is a string. Both
+// kinds of values are accepted for each parameter:
+//
+//	semverCompare(device.attributes["dra.example.com"].firmware, "1.2.3") // -1, 0, or 1
+//	semverIsAtLeast(device.attributes["dra.example.com"].firmware, "1.2.3") // true if >= 1.2.3
+//
+// Strings must be valid semantic versions, without a "v" prefix. This is
+// the same strict parsing as for version attributes.
+func semverFunctions() []cel.EnvOption {
+	var compareOverloads, isAtLeastOverloads []cel.FunctionOpt
+	for _, argTypes := range []struct {
+		name      string
+		leftType  *cel.Type
+		rightType *cel.Type
+	}{
+		{name: "semver_semver", leftType: apiservercel.SemverType, rightType: apiservercel.SemverType},
+		{name: "semver_string", leftType: apiservercel.SemverType, rightType: cel.StringType},
+		{name: "string_semver", leftType: cel.StringType, rightType: apiservercel.SemverType},
+		{name: "string_string", leftType: cel.StringType, rightType: cel.StringType},
+	} {
+		compareOverloads = append(compareOverloads,
+			cel.Overload("dra_semver_compare_"+argTypes.name, []*cel.Type{argTypes.leftType, argTypes.rightType}, cel.IntType, cel.BinaryBinding(semverCompare)))
+		isAtLeastOverloads = append(isAtLeastOverloads,
+			cel.Overload("dra_semver_is_at_least_"+argTypes.name, []*cel.Type{argTypes.leftType, argTypes.rightType}, cel.BoolType, cel.BinaryBinding(semverIsAtLeast)))
+	}
+	return []cel.EnvOption{
+		cel.Function("semverCompare", append(compareOverloads,
+			cel.FunctionDocs("Compares two semantic versions and returns -1, 0 or 1 if the first one is smaller, equal or larger than the second one. Strings must be valid semantic versions without a \"v\" prefix."))...),
+		cel.Function("semverIsAtLeast", append(isAtLeastOverloads,
+			cel.FunctionDocs("Returns true if the first semantic version is larger than or equal to the second one. Strings must be valid semantic versions without a \"v\" prefix."))...),
+	}
+}
+
+func semverCompare(lhs, rhs ref.Val) ref.Val {
+	left, right, errVal := semverArgs(lhs, rhs)
+	if errVal != nil {
+		return errVal
+	}
+	return types.Int(left.Compare(right))
+}
+
+func semverIsAtLeast(lhs, rhs ref.Val) ref.Val {
+	left, right, errVal := semverArgs(lhs, rhs)
+	if errVal != nil {
+		return errVal
+	}
+	return types.Bool(left.GE(right))
+}
+
+func semverArgs(lhs, rhs ref.Val) (semver.Version, semver.Version, ref.Val) {
+	left, errVal := toSemver(lhs)
+	if errVal != nil {
+		return semver.Version{}, semver.Version{}, errVal
+	}
+	right, errVal := toSemver(rhs)
+	if errVal != nil {
+		return semver.Version{}, semver.Version{}, errVal
+	}
+	return left, right, nil
+}
+
+func toSemver(val ref.Val) (semver.Version, ref.Val) {
+	switch val := val.(type) {
+	case apiservercel.Semver:
+		return val.Version, nil
+	case types.String:
+		v, err := semver.Parse(string(val))
+		if err != nil {
+			return semver.Version{}, types.NewErr("invalid semantic version %q: %v", string(val), err)
+		}
+		return v, nil
+	default:
+		return semver.Version{}, types.MaybeNoSuchOverloadErr(val)
+	}
+}
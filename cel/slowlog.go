@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// maxLoggedExpressionLength is the number of bytes of an expression
+// which get included in the log message about a slow evaluation.
+const maxLoggedExpressionLength = 100
+
+var slowEvaluationThreshold atomic.Int64
+
+// LogSlowEvaluations enables logging of expressions which take longer
+// than the threshold to evaluate for a device. Zero, the default,
+// disables it. Like [RegisterMetrics], this affects all evaluations
+// in the process.
+//
+// The log entry contains the beginning of the expression and
+// a hash of the full expression, which is enough to identify the
+// DeviceClass or ResourceClaim which uses it without making log entries
+// arbitrarily large. The logger is taken from the context passed to
+// [CompilationResult.DeviceMatches].
+func LogSlowEvaluations(threshold time.Duration) {
+	slowEvaluationThreshold.Store(int64(threshold))
+}
+
+// recordEvaluation updates metrics and logs slow evaluations.
+func (c CompilationResult) recordEvaluation(ctx context.Context, duration time.Duration, err error) {
+	evaluationDuration.Observe(duration.Seconds())
+	if err != nil {
+		evaluationErrors.Inc()
+	}
+	threshold := time.Duration(slowEvaluationThreshold.Load())
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+	klog.FromContext(ctx).Info("Slow CEL expression evaluation",
+		"expression", truncateExpression(c.Expression),
+		"expressionHash", hashExpression(c.Expression),
+		"duration", duration,
+		"threshold", threshold,
+	)
+}
+
+func truncateExpression(expression string) string {
+	if len(expression) <= maxLoggedExpressionLength {
+		return expression
+	}
+	return expression[:maxLoggedExpressionLength] + "..."
+}
+
+// hashExpression returns the first 16 hex digits of the SHA256 of the expression.
+func hashExpression(expression string) string {
+	hash := sha256.Sum256([]byte(expression))
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestEvaluationMetrics(t *testing.T) {
+	RegisterMetrics()
+	_, ctx := ktesting.NewTestContext(t)
+	errorsBefore, err := testutil.GetCounterMetricValue(evaluationErrors)
+	require.NoError(t, err)
+	countBefore, err := testutil.GetHistogramMetricCount(evaluationDuration.ObserverMetric)
+	require.NoError(t, err)
+
+	compiler := GetCompiler(Features{})
+	result := compiler.CompileCELExpression(`device.driver == "dra.example.com"`, Options{})
+	require.Nil(t, result.Error)
+	_, _, err = result.DeviceMatches(ctx, Device{Driver: "dra.example.com"})
+	require.NoError(t, err)
+	result = compiler.CompileCELExpression(`device.attributes["dra.example.com"].missing`, Options{})
+	require.Nil(t, result.Error)
+	_, _, err = result.DeviceMatches(ctx, Device{Driver: "dra.example.com"})
+	require.Error(t, err)
+
+	errorsAfter, err := testutil.GetCounterMetricValue(evaluationErrors)
+	require.NoError(t, err)
+	countAfter, err := testutil.GetHistogramMetricCount(evaluationDuration.ObserverMetric)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, errorsAfter-errorsBefore, "evaluation errors")
+	assert.Equal(t, uint64(2), countAfter-countBefore, "evaluations")
+}
+
+func TestLogSlowEvaluations(t *testing.T) {
+	logger := ktesting.NewLogger(t, ktesting.NewConfig(ktesting.BufferLogs(true)))
+	ctx := klog.NewContext(context.Background(), logger)
+	buffer := logger.GetSink().(ktesting.Underlier).GetBuffer()
+	expression := `device.driver == 'dra.example.com'` + strings.Repeat(` || device.driver == 'other.example.com'`, 5)
+	result := GetCompiler(Features{}).CompileCELExpression(expression, Options{})
+	require.Nil(t, result.Error)
+
+	_, _, err := result.DeviceMatches(ctx, Device{Driver: "dra.example.com"})
+	require.NoError(t, err)
+	assert.NotContains(t, buffer.String(), "Slow CEL expression evaluation", "disabled by default")
+
+	LogSlowEvaluations(time.Nanosecond)
+	defer LogSlowEvaluations(0)
+	_, _, err = result.DeviceMatches(ctx, Device{Driver: "dra.example.com"})
+	require.NoError(t, err)
+	output := buffer.String()
+	assert.Contains(t, output, "Slow CEL expression evaluation")
+	assert.Contains(t, output, hashExpression(expression))
+	assert.Contains(t, output, expression[:maxLoggedExpressionLength]+"...")
+	assert.NotContains(t, output, expression)
+}
@@ -59,6 +59,10 @@ func New(clientSet kubernetes.Interface) *Client {
 type Client struct {
 	clientSet kubernetes.Interface
 	useAPI    atomic.Int32
+
+	// Set by NewWithDiscovery.
+	discovered             bool
+	deviceTaintRulesServed bool
 }
 
 var _ cgoresource.ResourceV1Interface = &Client{}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	resourcev1alpha3 "k8s.io/api/resource/v1alpha3"
+	"k8s.io/client-go/kubernetes"
+	cgoresourcev1alpha3 "k8s.io/client-go/kubernetes/typed/resource/v1alpha3"
+)
+
+// ErrNotServed is returned by [NewWithDiscovery] when the apiserver does not
+// serve any of the resource.k8s.io versions supported by this package.
+var ErrNotServed = errors.New("resource.k8s.io API not served by the apiserver")
+
+// NewWithDiscovery is like [New], but asks the apiserver which versions
+// of the resource.k8s.io API it serves instead of finding out through
+// failed calls. The most preferred version which is served gets used for
+// all calls. Should that version go away, for example because of a
+// downgrade, then the client falls back to trying the other versions
+// like a client created by [New].
+func NewWithDiscovery(clientSet kubernetes.Interface) (*Client, error) {
+	groups, err := clientSet.Discovery().ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("discover API groups: %w", err)
+	}
+	served := make(map[string]bool)
+	for _, group := range groups.Groups {
+		if group.Name != resourceapi.GroupName {
+			continue
+		}
+		for _, version := range group.Versions {
+			served[version.Version] = true
+		}
+	}
+
+	c := New(clientSet)
+	c.discovered = true
+	c.deviceTaintRulesServed = served[resourcev1alpha3.SchemeGroupVersion.Version]
+	for api := useLatestAPI; api < numAPIs; api++ {
+		if served[strings.ToLower(apiName(api))] {
+			c.useAPI.Store(api)
+			return c, nil
+		}
+	}
+	return nil, ErrNotServed
+}
+
+// ServesDeviceTaintRules returns true if [Client.DeviceTaintRules] can be
+// used. Without discovery, this is unknown and true is returned.
+func (c *Client) ServesDeviceTaintRules() bool {
+	return !c.discovered || c.deviceTaintRulesServed
+}
+
+// DeviceTaintRules provides access to DeviceTaintRule objects. They only
+// exist in v1alpha3, so there is no conversion and no fallback. The API
+// must be enabled explicitly in the cluster, see [Client.ServesDeviceTaintRules].
+func (c *Client) DeviceTaintRules() cgoresourcev1alpha3.DeviceTaintRuleInterface {
+	return c.clientSet.ResourceV1alpha3().DeviceTaintRules()
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourcev1beta2 "k8s.io/api/resource/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestNewWithDiscovery(t *testing.T) {
+	served := func(versions ...string) []*metav1.APIResourceList {
+		var resources []*metav1.APIResourceList
+		for _, version := range versions {
+			resources = append(resources, &metav1.APIResourceList{GroupVersion: "resource.k8s.io/" + version})
+		}
+		return resources
+	}
+
+	for name, tc := range map[string]struct {
+		served                 []*metav1.APIResourceList
+		expectAPI              string
+		expectDeviceTaintRules bool
+		expectErr              error
+	}{
+		"none": {
+			expectErr: ErrNotServed,
+		},
+		"only-alpha": {
+			served:    served("v1alpha3"),
+			expectErr: ErrNotServed,
+		},
+		"v1beta1": {
+			served:    served("v1beta1"),
+			expectAPI: "V1beta1",
+		},
+		"all": {
+			served:                 served("v1beta1", "v1beta2", "v1", "v1alpha3"),
+			expectAPI:              "V1",
+			expectDeviceTaintRules: true,
+		},
+		"beta": {
+			served:    served("v1beta1", "v1beta2"),
+			expectAPI: "V1beta2",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clientSet := fake.NewClientset()
+			clientSet.Fake.Resources = tc.served
+			c, err := NewWithDiscovery(clientSet)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectAPI, c.CurrentAPI())
+			assert.Equal(t, tc.expectDeviceTaintRules, c.ServesDeviceTaintRules())
+		})
+	}
+
+	assert.True(t, New(fake.NewClientset()).ServesDeviceTaintRules(), "unknown without discovery")
+}
+
+func TestDiscoveredConversion(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	clientSet := fake.NewClientset()
+	clientSet.Fake.Resources = []*metav1.APIResourceList{{GroupVersion: "resource.k8s.io/v1beta2"}}
+	_, err := clientSet.ResourceV1beta2().DeviceClasses().Create(ctx, &resourcev1beta2.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	c, err := NewWithDiscovery(clientSet)
+	require.NoError(t, err)
+	classes, err := c.DeviceClasses().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, classes.Items, 1)
+	assert.Equal(t, "class", classes.Items[0].Name)
+	assert.Equal(t, "V1beta2", c.CurrentAPI())
+}
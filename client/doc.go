@@ -17,7 +17,11 @@ limitations under the License.
 // Package clients provides a wrapper around client-go such that consumers of
 // this package can use the latest resource.k8s.io API. Under the hood those
 // types get converted to and from the most recent API version supported by the
-// apiserver.
+// apiserver. [New] finds that version through trial and error,
+// [NewWithDiscovery] asks the apiserver.
+//
+// DeviceTaintRules are only available in v1alpha3 and therefore get
+// passed through without conversion.
 //
 // Patching and server-side-apply are not supported and return the
 // [ErrNotImplemented] error. It would be necessary to convert the patch or
@@ -30,6 +30,45 @@ const (
 	// referring to a PCIe (Peripheral Component Interconnect Express) Root Complex.
 	// This attribute can be used to identify devices that share the same PCIe Root Complex.
 	StandardDeviceAttributePCIeRoot resourceapi.QualifiedName = StandardDeviceAttributePrefix + "pcieRoot"
+
+	// StandardDeviceAttributePCIeSwitch is a standard device attribute name
+	// which describes the PCIe switch that the PCIe device is connected to.
+	// The value is a string value, the PCI Bus ID of the upstream port of the
+	// switch in BDF format, e.g. `0000:01:00.0`.
+	// This attribute can be used to identify devices that share the same PCIe switch.
+	StandardDeviceAttributePCIeSwitch resourceapi.QualifiedName = StandardDeviceAttributePrefix + "pcieSwitch"
+
+	// StandardDeviceAttributePCIeLinkSpeed is a standard device attribute name
+	// which describes the current speed of the PCIe link of the device.
+	// The value is an integer value in megatransfers per second, e.g. 16000 for 16 GT/s.
+	StandardDeviceAttributePCIeLinkSpeed resourceapi.QualifiedName = StandardDeviceAttributePrefix + "pcieLinkSpeed"
+
+	// StandardDeviceAttributePCIeLinkWidth is a standard device attribute name
+	// which describes the current number of lanes of the PCIe link of the device.
+	// The value is an integer value, e.g. 16 for a x16 link.
+	StandardDeviceAttributePCIeLinkWidth resourceapi.QualifiedName = StandardDeviceAttributePrefix + "pcieLinkWidth"
+
+	// StandardDeviceAttributeNUMANode is a standard device attribute name
+	// which describes the NUMA node of the device.
+	// The value is an integer value, the number of the NUMA node.
+	// The memory of that NUMA node is local to the device, so this attribute
+	// also describes memory locality. It can be used to select devices
+	// which share a NUMA node with each other.
+	StandardDeviceAttributeNUMANode resourceapi.QualifiedName = StandardDeviceAttributePrefix + "numaNode"
+
+	// StandardDeviceAttributeCPUAffinity is a standard device attribute name
+	// which describes the CPUs that are local to the device.
+	// The value is a string value in the Linux CPU list format, e.g. `0-15,32-47`.
+	StandardDeviceAttributeCPUAffinity resourceapi.QualifiedName = StandardDeviceAttributePrefix + "cpuAffinity"
+
+	// StandardDeviceAttributeHealth is a standard device attribute name
+	// which describes the health of the device as reported by its driver.
+	// The value is a string value, either `Degraded` or `Unhealthy`.
+	// Healthy devices don't have the attribute, so selectors must check
+	// for it with `has()` before comparing the value, for example
+	// `!has(device.attributes["resource.kubernetes.io"].health)`.
+	// It can be used to avoid devices which are not fully functional.
+	StandardDeviceAttributeHealth resourceapi.QualifiedName = StandardDeviceAttributePrefix + "health"
 )
 
 // DeviceAttribute represents a device attribute name and its value
@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceattribute
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// attributeType names the type of the value of a DeviceAttribute.
+type attributeType string
+
+const (
+	attributeTypeInvalid attributeType = ""
+	attributeTypeInt     attributeType = "int"
+	attributeTypeBool    attributeType = "bool"
+	attributeTypeString  attributeType = "string"
+	attributeTypeVersion attributeType = "version"
+)
+
+// standardAttributeTypes contains all standard device attributes
+// and the type of their values.
+var standardAttributeTypes = map[resourceapi.QualifiedName]attributeType{
+	StandardDeviceAttributePCIeRoot:      attributeTypeString,
+	StandardDeviceAttributePCIeSwitch:    attributeTypeString,
+	StandardDeviceAttributePCIeLinkSpeed: attributeTypeInt,
+	StandardDeviceAttributePCIeLinkWidth: attributeTypeInt,
+	StandardDeviceAttributeNUMANode:      attributeTypeInt,
+	StandardDeviceAttributeCPUAffinity:   attributeTypeString,
+}
+
+// NormalizeDeviceAttributes checks the attributes of the devices that a
+// driver is about to publish and returns copies of the devices with
+// normalized attribute names. Attribute names which are qualified with
+// the driver name as domain get normalized to the short name without
+// domain, which is what the driver name defaults to.
+//
+// The checks go beyond the validation by the API server, which looks
+// at each device in isolation, so problems are found before publishing:
+//   - names must be valid qualified names,
+//   - values must have exactly one field set and be valid,
+//   - a device must not have the same attribute with and without domain,
+//   - an attribute must have the same type for all devices,
+//   - standard attributes in the "resource.kubernetes.io" domain must be
+//     known and have the standard type,
+//   - a device must not have more attributes and capacities than allowed.
+//
+// The result is nil if there are errors.
+func NormalizeDeviceAttributes(driverName string, devices []resourceapi.Device) ([]resourceapi.Device, field.ErrorList) {
+	var allErrs field.ErrorList
+	type firstUse struct {
+		attrType attributeType
+		device   string
+	}
+	types := make(map[resourceapi.QualifiedName]firstUse)
+	normalized := make([]resourceapi.Device, len(devices))
+	for i := range devices {
+		device := devices[i].DeepCopy()
+		devicePath := field.NewPath("devices").Index(i)
+		attributesPath := devicePath.Child("attributes")
+		if len(device.Attributes) > 0 {
+			attributes := make(map[resourceapi.QualifiedName]resourceapi.DeviceAttribute, len(device.Attributes))
+			// Sorted for deterministic errors.
+			for _, name := range slices.Sorted(maps.Keys(device.Attributes)) {
+				value := device.Attributes[name]
+				attributePath := attributesPath.Key(string(name))
+				allErrs = append(allErrs, validateQualifiedName(name, attributePath)...)
+				attrType, err := typeOfAttribute(value)
+				if err != "" {
+					allErrs = append(allErrs, field.Invalid(attributePath, value, err))
+					continue
+				}
+
+				normalizedName := normalizeAttributeName(driverName, name)
+				if _, ok := attributes[normalizedName]; ok {
+					allErrs = append(allErrs, field.Duplicate(attributePath, fmt.Sprintf("%s and %s/%s are the same attribute", normalizedName, driverName, normalizedName)))
+					continue
+				}
+				attributes[normalizedName] = value
+
+				if strings.HasPrefix(string(normalizedName), StandardDeviceAttributePrefix) {
+					standardType, ok := standardAttributeTypes[normalizedName]
+					switch {
+					case !ok:
+						allErrs = append(allErrs, field.Invalid(attributePath, name, fmt.Sprintf("unknown standard attribute, the domain %q is reserved", strings.TrimSuffix(StandardDeviceAttributePrefix, "/"))))
+						continue
+					case standardType != attrType:
+						allErrs = append(allErrs, field.Invalid(attributePath, value, fmt.Sprintf("standard attribute must have type %s, not %s", standardType, attrType)))
+						continue
+					}
+				}
+
+				if first, ok := types[normalizedName]; !ok {
+					types[normalizedName] = firstUse{attrType: attrType, device: device.Name}
+				} else if first.attrType != attrType {
+					allErrs = append(allErrs, field.Invalid(attributePath, value, fmt.Sprintf("type %s differs from type %s of the same attribute in device %s", attrType, first.attrType, first.device)))
+				}
+			}
+			device.Attributes = attributes
+		}
+		for _, name := range slices.Sorted(maps.Keys(device.Capacity)) {
+			allErrs = append(allErrs, validateQualifiedName(name, devicePath.Child("capacity").Key(string(name)))...)
+		}
+		if count := len(device.Attributes) + len(device.Capacity); count > resourceapi.ResourceSliceMaxAttributesAndCapacitiesPerDevice {
+			allErrs = append(allErrs, field.TooMany(devicePath, count, resourceapi.ResourceSliceMaxAttributesAndCapacitiesPerDevice))
+		}
+		normalized[i] = *device
+	}
+	if len(allErrs) > 0 {
+		return nil, allErrs
+	}
+	return normalized, nil
+}
+
+// normalizeAttributeName removes the domain if it is the driver name.
+func normalizeAttributeName(driverName string, name resourceapi.QualifiedName) resourceapi.QualifiedName {
+	if id, ok := strings.CutPrefix(string(name), driverName+"/"); ok {
+		return resourceapi.QualifiedName(id)
+	}
+	return name
+}
+
+// validateQualifiedName checks a name in the format "[<domain>/]<id>".
+func validateQualifiedName(name resourceapi.QualifiedName, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	domain, id, hasDomain := strings.Cut(string(name), "/")
+	if !hasDomain {
+		id, domain = domain, ""
+	}
+	if hasDomain {
+		if len(domain) > resourceapi.DeviceMaxDomainLength {
+			allErrs = append(allErrs, field.TooLong(path, domain, resourceapi.DeviceMaxDomainLength))
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(domain) {
+			allErrs = append(allErrs, field.Invalid(path, name, msg))
+		}
+	}
+	if len(id) > resourceapi.DeviceMaxIDLength {
+		allErrs = append(allErrs, field.TooLong(path, id, resourceapi.DeviceMaxIDLength))
+	}
+	for _, msg := range validation.IsCIdentifier(id) {
+		allErrs = append(allErrs, field.Invalid(path, name, msg))
+	}
+	return allErrs
+}
+
+// typeOfAttribute returns the type of the attribute or a description
+// of why the attribute is invalid.
+func typeOfAttribute(value resourceapi.DeviceAttribute) (attributeType, string) {
+	attrType := attributeTypeInvalid
+	numFields := 0
+	if value.IntValue != nil {
+		attrType = attributeTypeInt
+		numFields++
+	}
+	if value.BoolValue != nil {
+		attrType = attributeTypeBool
+		numFields++
+	}
+	if value.StringValue != nil {
+		attrType = attributeTypeString
+		numFields++
+		if len(*value.StringValue) > resourceapi.DeviceAttributeMaxValueLength {
+			return attributeTypeInvalid, fmt.Sprintf("string value must not be longer than %d bytes", resourceapi.DeviceAttributeMaxValueLength)
+		}
+	}
+	if value.VersionValue != nil {
+		attrType = attributeTypeVersion
+		numFields++
+		if len(*value.VersionValue) > resourceapi.DeviceAttributeMaxValueLength {
+			return attributeTypeInvalid, fmt.Sprintf("version value must not be longer than %d bytes", resourceapi.DeviceAttributeMaxValueLength)
+		}
+		if _, err := semver.Parse(*value.VersionValue); err != nil {
+			return attributeTypeInvalid, fmt.Sprintf("version value must be a semantic version: %v", err)
+		}
+	}
+	if numFields != 1 {
+		return attributeTypeInvalid, "exactly one value must be set"
+	}
+	return attrType, ""
+}
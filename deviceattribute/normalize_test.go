@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceattribute
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestNormalizeDeviceAttributes(t *testing.T) {
+	driverName := "gpu.example.com"
+	intAttr := resourceapi.DeviceAttribute{IntValue: ptr.To(int64(1))}
+	stringAttr := resourceapi.DeviceAttribute{StringValue: ptr.To("a100")}
+	device := func(name string, attributes map[resourceapi.QualifiedName]resourceapi.DeviceAttribute) resourceapi.Device {
+		return resourceapi.Device{Name: name, Attributes: attributes}
+	}
+	attributePath := func(i int, name string) string {
+		return field.NewPath("devices").Index(i).Child("attributes").Key(name).String()
+	}
+
+	tooMany := map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{}
+	for i := range resourceapi.ResourceSliceMaxAttributesAndCapacitiesPerDevice {
+		tooMany[resourceapi.QualifiedName(fmt.Sprintf("attr%d", i))] = intAttr
+	}
+	tooManyDevice := device("gpu-0", tooMany)
+	tooManyDevice.Capacity = map[resourceapi.QualifiedName]resourceapi.DeviceCapacity{"memory": {Value: resource.MustParse("1Gi")}}
+
+	tests := map[string]struct {
+		devices         []resourceapi.Device
+		expectedDevices []resourceapi.Device
+		expectedPaths   []string
+	}{
+		"normalized": {
+			devices: []resourceapi.Device{
+				device("gpu-0", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					"gpu.example.com/model":         stringAttr,
+					"other.example.com/model":       stringAttr,
+					StandardDeviceAttributeNUMANode: intAttr,
+				}),
+				device("gpu-1", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					"model": stringAttr,
+				}),
+				device("gpu-2", nil),
+			},
+			expectedDevices: []resourceapi.Device{
+				device("gpu-0", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					"model":                         stringAttr,
+					"other.example.com/model":       stringAttr,
+					StandardDeviceAttributeNUMANode: intAttr,
+				}),
+				device("gpu-1", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					"model": stringAttr,
+				}),
+				device("gpu-2", nil),
+			},
+		},
+		"invalid names": {
+			devices: []resourceapi.Device{
+				device("gpu-0", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					"Invalid_Domain/model": stringAttr,
+					"not-a-c-identifier":   stringAttr,
+				}),
+			},
+			expectedPaths: []string{attributePath(0, "Invalid_Domain/model"), attributePath(0, "not-a-c-identifier")},
+		},
+		"invalid values": {
+			devices: []resourceapi.Device{
+				device("gpu-0", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					"empty":   {},
+					"two":     {IntValue: ptr.To(int64(1)), BoolValue: ptr.To(true)},
+					"version": {VersionValue: ptr.To("1.0")},
+				}),
+			},
+			expectedPaths: []string{attributePath(0, "empty"), attributePath(0, "two"), attributePath(0, "version")},
+		},
+		"collision with driver domain": {
+			devices: []resourceapi.Device{
+				device("gpu-0", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					"gpu.example.com/model": stringAttr,
+					"model":                 stringAttr,
+				}),
+			},
+			expectedPaths: []string{attributePath(0, "model")},
+		},
+		"inconsistent types": {
+			devices: []resourceapi.Device{
+				device("gpu-0", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": stringAttr}),
+				device("gpu-1", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"gpu.example.com/model": intAttr}),
+			},
+			expectedPaths: []string{attributePath(1, "gpu.example.com/model")},
+		},
+		"standard attributes": {
+			devices: []resourceapi.Device{
+				device("gpu-0", map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+					StandardDeviceAttributeNUMANode:     stringAttr,
+					StandardDeviceAttributePrefix + "x": intAttr,
+				}),
+			},
+			expectedPaths: []string{attributePath(0, string(StandardDeviceAttributeNUMANode)), attributePath(0, StandardDeviceAttributePrefix+"x")},
+		},
+		"too many": {
+			devices:       []resourceapi.Device{tooManyDevice},
+			expectedPaths: []string{field.NewPath("devices").Index(0).String()},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, errs := NormalizeDeviceAttributes(driverName, test.devices)
+			var paths []string
+			for _, err := range errs {
+				paths = append(paths, err.Field)
+			}
+			if !reflect.DeepEqual(paths, test.expectedPaths) {
+				t.Fatalf("Expected errors for %v, got %v", test.expectedPaths, errs)
+			}
+			if !reflect.DeepEqual(got, test.expectedDevices) {
+				t.Errorf("Expected devices %v, got %v", test.expectedDevices, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceattribute
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+// ErrNUMANodeUnknown is returned when the kernel does not know the NUMA node
+// of a device, for example on systems with only one NUMA node.
+var ErrNUMANodeUnknown = errors.New("NUMA node unknown")
+
+// GetNUMANodeAttributeByPCIBusID retrieves the NUMA node for a given PCI Bus ID
+// in BDF (Bus-Device-Function) format, e.g., "0123:45:1e.7".
+//
+// It returns a DeviceAttribute with the NUMA node as an integer value, an
+// error wrapping ErrNUMANodeUnknown if the kernel does not report a NUMA node
+// for the device, or some other error if the PCI Bus ID is invalid or sysfs
+// cannot be read.
+func GetNUMANodeAttributeByPCIBusID(pciBusID string) (DeviceAttribute, error) {
+	if err := validatePCIBusID(pciBusID); err != nil {
+		return DeviceAttribute{}, err
+	}
+
+	content, err := readPCIDeviceFile(pciBusID, "numa_node")
+	if err != nil {
+		return DeviceAttribute{}, err
+	}
+	numaNode, err := strconv.ParseInt(content, 10, 64)
+	if err != nil {
+		return DeviceAttribute{}, fmt.Errorf("failed to parse NUMA node of PCI Bus ID %s: %w", pciBusID, err)
+	}
+	if numaNode < 0 {
+		return DeviceAttribute{}, fmt.Errorf("PCI Bus ID %s: %w", pciBusID, ErrNUMANodeUnknown)
+	}
+
+	attr := DeviceAttribute{
+		Name:  StandardDeviceAttributeNUMANode,
+		Value: resourceapi.DeviceAttribute{IntValue: &numaNode},
+	}
+
+	return attr, nil
+}
+
+// GetCPUAffinityAttributeByPCIBusID retrieves the CPUs that are local to the
+// device with the given PCI Bus ID in BDF (Bus-Device-Function) format,
+// e.g., "0123:45:1e.7".
+//
+// It returns a DeviceAttribute with the CPU list (e.g. "0-15,32-47") as
+// a string value or an error if the PCI Bus ID is invalid or sysfs cannot be read.
+func GetCPUAffinityAttributeByPCIBusID(pciBusID string) (DeviceAttribute, error) {
+	if err := validatePCIBusID(pciBusID); err != nil {
+		return DeviceAttribute{}, err
+	}
+
+	cpuList, err := readPCIDeviceFile(pciBusID, "local_cpulist")
+	if err != nil {
+		return DeviceAttribute{}, err
+	}
+	if cpuList == "" {
+		return DeviceAttribute{}, fmt.Errorf("empty CPU list for PCI Bus ID %s", pciBusID)
+	}
+
+	attr := DeviceAttribute{
+		Name:  StandardDeviceAttributeCPUAffinity,
+		Value: resourceapi.DeviceAttribute{StringValue: &cpuList},
+	}
+
+	return attr, nil
+}
+
+// GetNUMAAttributesByPCIBusID retrieves all NUMA related attributes for a given
+// PCI Bus ID: the NUMA node and the CPU affinity. The NUMA node is left out
+// if it is unknown.
+func GetNUMAAttributesByPCIBusID(pciBusID string) ([]DeviceAttribute, error) {
+	var attrs []DeviceAttribute
+
+	numaNode, err := GetNUMANodeAttributeByPCIBusID(pciBusID)
+	switch {
+	case errors.Is(err, ErrNUMANodeUnknown):
+	case err != nil:
+		return nil, err
+	default:
+		attrs = append(attrs, numaNode)
+	}
+
+	cpuAffinity, err := GetCPUAffinityAttributeByPCIBusID(pciBusID)
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, cpuAffinity)
+
+	return attrs, nil
+}
+
+// readPCIDeviceFile reads a file in /sys/bus/pci/devices/<address>
+// and returns its content without surrounding whitespace.
+func readPCIDeviceFile(pciBusID, name string) (string, error) {
+	path := sysfs.bus(filepath.Join("pci", "devices", pciBusID, name))
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s for PCI Bus ID %s: %w", name, pciBusID, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
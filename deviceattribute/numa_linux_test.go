@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceattribute
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+func TestGetNUMAAttributesByPCIBusID(t *testing.T) {
+	pciBusID := "0000:01:02.3"
+
+	tests := map[string]struct {
+		numaNode           string
+		cpuList            string
+		address            string
+		expectedAttributes []DeviceAttribute
+		expectedErr        error
+		expectedErrMsg     string
+	}{
+		"valid": {
+			numaNode: "1\n",
+			cpuList:  "16-31,48-63\n",
+			address:  pciBusID,
+			expectedAttributes: []DeviceAttribute{
+				{Name: StandardDeviceAttributeNUMANode, Value: resourceapi.DeviceAttribute{IntValue: ptr.To(int64(1))}},
+				{Name: StandardDeviceAttributeCPUAffinity, Value: resourceapi.DeviceAttribute{StringValue: ptr.To("16-31,48-63")}},
+			},
+		},
+		"unknown NUMA node": {
+			numaNode: "-1\n",
+			cpuList:  "0-7\n",
+			address:  pciBusID,
+			expectedAttributes: []DeviceAttribute{
+				{Name: StandardDeviceAttributeCPUAffinity, Value: resourceapi.DeviceAttribute{StringValue: ptr.To("0-7")}},
+			},
+		},
+		"invalid NUMA node": {
+			numaNode:       "x\n",
+			cpuList:        "0-7\n",
+			address:        pciBusID,
+			expectedErrMsg: "failed to parse NUMA node of PCI Bus ID",
+		},
+		"empty CPU list": {
+			numaNode:       "0\n",
+			cpuList:        "\n",
+			address:        pciBusID,
+			expectedErrMsg: "empty CPU list",
+		},
+		"invalid PCI Bus ID format": {
+			address:        "invalid-pci-id",
+			expectedErrMsg: "invalid PCI Bus ID format: invalid-pci-id",
+		},
+		"no exist PCI Bus ID": {
+			address:        pciBusID,
+			expectedErr:    os.ErrNotExist,
+			expectedErrMsg: "no such file or directory",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSysfs := sysfsPath(t.TempDir())
+			if test.numaNode != "" {
+				writeFile(t, mockSysfs.bus(filepath.Join("pci", "devices", pciBusID, "numa_node")), test.numaNode)
+				writeFile(t, mockSysfs.bus(filepath.Join("pci", "devices", pciBusID, "local_cpulist")), test.cpuList)
+			}
+			sysfs = mockSysfs
+			t.Cleanup(func() {
+				sysfs = sysfsPath(sysfsRoot)
+			})
+
+			got, err := GetNUMAAttributesByPCIBusID(test.address)
+			if test.expectedErrMsg != "" {
+				if err == nil {
+					t.Fatalf("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), test.expectedErrMsg) {
+					t.Errorf("Expected error message to contain %q, got %q", test.expectedErrMsg, err.Error())
+				}
+				if test.expectedErr != nil && !errors.Is(err, test.expectedErr) {
+					t.Errorf("Expected error to wrap %v, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.expectedAttributes) {
+				t.Errorf("Expected attributes %v, got %v", test.expectedAttributes, got)
+			}
+		})
+	}
+
+	t.Run("unknown NUMA node error", func(t *testing.T) {
+		mockSysfs := sysfsPath(t.TempDir())
+		writeFile(t, mockSysfs.bus(filepath.Join("pci", "devices", pciBusID, "numa_node")), "-1\n")
+		sysfs = mockSysfs
+		t.Cleanup(func() {
+			sysfs = sysfsPath(sysfsRoot)
+		})
+
+		_, err := GetNUMANodeAttributeByPCIBusID(pciBusID)
+		if !errors.Is(err, ErrNUMANodeUnknown) {
+			t.Errorf("Expected ErrNUMANodeUnknown, got %v", err)
+		}
+	})
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file %s: %v", path, err)
+	}
+}
@@ -34,13 +34,8 @@ import (
 //
 // ref: https://wiki.xenproject.org/wiki/Bus:Device.Function_(BDF)_Notation
 func GetPCIeRootAttributeByPCIBusID(pciBusID string) (DeviceAttribute, error) {
-	if pciBusID == "" {
-		return DeviceAttribute{}, fmt.Errorf("PCI Bus ID cannot be empty")
-	}
-
-	bdfRegexp := regexp.MustCompile(`^([0-9a-f]{4}):([0-9a-f]{2}):([0-9a-f]{2})\.([0-9a-f]{1})$`)
-	if !bdfRegexp.MatchString(pciBusID) {
-		return DeviceAttribute{}, fmt.Errorf("invalid PCI Bus ID format: %s", pciBusID)
+	if err := validatePCIBusID(pciBusID); err != nil {
+		return DeviceAttribute{}, err
 	}
 
 	pcieRoot, err := resolvePCIeRoot(pciBusID)
@@ -56,8 +51,32 @@ func GetPCIeRootAttributeByPCIBusID(pciBusID string) (DeviceAttribute, error) {
 	return attr, nil
 }
 
+var bdfRegexp = regexp.MustCompile(`^([0-9a-f]{4}):([0-9a-f]{2}):([0-9a-f]{2})\.([0-9a-f]{1})$`)
+
+// validatePCIBusID checks that the PCI Bus ID is in BDF format.
+func validatePCIBusID(pciBusID string) error {
+	if pciBusID == "" {
+		return fmt.Errorf("PCI Bus ID cannot be empty")
+	}
+	if !bdfRegexp.MatchString(pciBusID) {
+		return fmt.Errorf("invalid PCI Bus ID format: %s", pciBusID)
+	}
+	return nil
+}
+
 // resolvePCIeRoot resolves the PCIe Root for a given PCI Bus ID
 // in BDF (Bus-Device-Function) format, e.g., "0123:45:1e.7",
+// by inspecting sysfs(/sys/devices). See resolvePCIePath for details.
+func resolvePCIeRoot(pciBusID string) (string, error) {
+	path, err := resolvePCIePath(pciBusID)
+	if err != nil {
+		return "", err
+	}
+	return path[0], nil
+}
+
+// resolvePCIePath resolves the path in the PCIe hierarchy for a given PCI Bus ID
+// in BDF (Bus-Device-Function) format, e.g., "0123:45:1e.7",
 // by inspecting sysfs(/sys/devices).
 //
 // ref: https://wiki.xenproject.org/wiki/Bus:Device.Function_(BDF)_Notation
@@ -71,14 +90,15 @@ func GetPCIeRootAttributeByPCIBusID(pciBusID string) (DeviceAttribute, error) {
 // For example, if the PCIAddress is "0000:00:1f.0",
 // /sys/bus/pci/devices/0000:00:1f.0 points to
 // /sys/devices/pci0000:01/...<intermediate PCI devices>.../0000:00:1f.0,
-// where "pci0000:01" is the PCIe Root.
-func resolvePCIeRoot(pciBusID string) (string, error) {
+// where "pci0000:01" is the PCIe Root. The result then is
+// ["pci0000:01", <intermediate PCI devices>..., "0000:00:1f.0"].
+func resolvePCIePath(pciBusID string) ([]string, error) {
 	// e.g. /sys/bus/pci/devices/0000:00:1f.0
 	sysBusPath := sysfs.bus(filepath.Join("pci", "devices", pciBusID))
 
 	target, err := os.Readlink(sysBusPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read symlink for PCI Bus ID %s: %w", sysBusPath, err)
+		return nil, fmt.Errorf("failed to read symlink for PCI Bus ID %s: %w", sysBusPath, err)
 	}
 
 	// If the target is a relative path, we need to resolve it relative to the symlink's directory.
@@ -89,14 +109,12 @@ func resolvePCIeRoot(pciBusID string) (string, error) {
 	// targetAbs must be /sys/devices/pci0000:01/...<intermediate PCI devices>.../0000:00:1f.0
 	devicePathPrefix := sysfs.devices("pci")
 	if !strings.HasPrefix(target, devicePathPrefix) {
-		return "", fmt.Errorf("symlink target for PCI Bus ID %s is invalid: it must start with %s: %s", pciBusID, devicePathPrefix, target)
+		return nil, fmt.Errorf("symlink target for PCI Bus ID %s is invalid: it must start with %s: %s", pciBusID, devicePathPrefix, target)
 	}
 	if filepath.Base(target) != pciBusID {
-		return "", fmt.Errorf("symlink target for PCI Bus ID %s is invalid: it must end with %s: %s", pciBusID, pciBusID, target)
+		return nil, fmt.Errorf("symlink target for PCI Bus ID %s is invalid: it must end with %s: %s", pciBusID, pciBusID, target)
 	}
 
-	// We need to extract the PCIe Root part, which is the first part of the path after /sys/devices/.
-	pcieRootPart := strings.Split(strings.TrimPrefix(target, sysfs.devices("")+"/"), "/")[0]
-
-	return pcieRootPart, nil
+	// The PCIe Root is the first part of the path after /sys/devices/.
+	return strings.Split(strings.TrimPrefix(target, sysfs.devices("")+"/"), "/"), nil
 }
@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceattribute
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+// ErrNoPCIeSwitch is returned when a PCIe device is not connected
+// to a PCIe switch.
+var ErrNoPCIeSwitch = errors.New("not connected to a PCIe switch")
+
+// GetPCIeSwitchAttributeByPCIBusID retrieves the PCIe switch for a given PCI Bus ID
+// in BDF (Bus-Device-Function) format, e.g., "0123:45:1e.7".
+//
+// It returns a DeviceAttribute with the PCI Bus ID of the upstream port of the
+// nearest switch above the device as a string value, an error wrapping
+// ErrNoPCIeSwitch if the device is connected directly to a root port, or some
+// other error if the PCI Bus ID is invalid or the hierarchy cannot be determined.
+//
+// A PCIe switch appears in sysfs as an upstream port with downstream ports
+// below it, so a device behind a switch has the path
+// pci<domain>:<bus>/<root port>/<upstream port>/<downstream port>/<device>.
+func GetPCIeSwitchAttributeByPCIBusID(pciBusID string) (DeviceAttribute, error) {
+	if err := validatePCIBusID(pciBusID); err != nil {
+		return DeviceAttribute{}, err
+	}
+
+	path, err := resolvePCIePath(pciBusID)
+	if err != nil {
+		return DeviceAttribute{}, fmt.Errorf("failed to resolve PCIe path for PCI Bus ID %s: %w", pciBusID, err)
+	}
+	// PCIe root, root port, upstream port, downstream port, device.
+	if len(path) < 5 {
+		return DeviceAttribute{}, fmt.Errorf("PCI Bus ID %s: %w", pciBusID, ErrNoPCIeSwitch)
+	}
+	upstreamPort := path[len(path)-3]
+
+	attr := DeviceAttribute{
+		Name:  StandardDeviceAttributePCIeSwitch,
+		Value: resourceapi.DeviceAttribute{StringValue: &upstreamPort},
+	}
+
+	return attr, nil
+}
+
+// GetPCIeLinkAttributesByPCIBusID retrieves the current link speed and width for
+// a given PCI Bus ID in BDF (Bus-Device-Function) format, e.g., "0123:45:1e.7".
+//
+// It returns DeviceAttributes with the link speed in megatransfers per second and
+// the link width as integer values or an error if the PCI Bus ID is invalid or
+// the link information cannot be read.
+func GetPCIeLinkAttributesByPCIBusID(pciBusID string) ([]DeviceAttribute, error) {
+	if err := validatePCIBusID(pciBusID); err != nil {
+		return nil, err
+	}
+
+	speed, err := readPCIDeviceFile(pciBusID, "current_link_speed")
+	if err != nil {
+		return nil, err
+	}
+	speedMTps, err := parseLinkSpeed(speed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse link speed of PCI Bus ID %s: %w", pciBusID, err)
+	}
+
+	width, err := readPCIDeviceFile(pciBusID, "current_link_width")
+	if err != nil {
+		return nil, err
+	}
+	lanes, err := strconv.ParseInt(width, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse link width of PCI Bus ID %s: %w", pciBusID, err)
+	}
+
+	attrs := []DeviceAttribute{
+		{
+			Name:  StandardDeviceAttributePCIeLinkSpeed,
+			Value: resourceapi.DeviceAttribute{IntValue: &speedMTps},
+		},
+		{
+			Name:  StandardDeviceAttributePCIeLinkWidth,
+			Value: resourceapi.DeviceAttribute{IntValue: &lanes},
+		},
+	}
+
+	return attrs, nil
+}
+
+// parseLinkSpeed converts the link speed as reported by the kernel,
+// e.g. "16.0 GT/s PCIe" or "2.5 GT/s", into megatransfers per second.
+func parseLinkSpeed(speed string) (int64, error) {
+	fields := strings.Fields(speed)
+	if len(fields) < 2 || fields[1] != "GT/s" {
+		return 0, fmt.Errorf("unexpected format: %q", speed)
+	}
+	gtps, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(gtps * 1000)), nil
+}
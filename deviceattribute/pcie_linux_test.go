@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceattribute
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+func TestGetPCIeSwitchAttributeByPCIBusID(t *testing.T) {
+	pciBusID := "0000:03:00.0"
+
+	tests := map[string]struct {
+		path              []string
+		expectedAttribute *DeviceAttribute
+		expectedErr       error
+	}{
+		"behind switch": {
+			path: []string{"pci0000:00", "0000:00:01.0", "0000:01:00.0", "0000:02:08.0", pciBusID},
+			expectedAttribute: &DeviceAttribute{
+				Name:  StandardDeviceAttributePCIeSwitch,
+				Value: resourceapi.DeviceAttribute{StringValue: ptr.To("0000:01:00.0")},
+			},
+		},
+		"behind nested switches": {
+			path: []string{"pci0000:00", "0000:00:01.0", "0000:01:00.0", "0000:02:08.0", "0000:05:00.0", "0000:06:01.0", pciBusID},
+			expectedAttribute: &DeviceAttribute{
+				Name:  StandardDeviceAttributePCIeSwitch,
+				Value: resourceapi.DeviceAttribute{StringValue: ptr.To("0000:05:00.0")},
+			},
+		},
+		"root port": {
+			path:        []string{"pci0000:00", "0000:00:01.0", pciBusID},
+			expectedErr: ErrNoPCIeSwitch,
+		},
+		"root complex integrated": {
+			path:        []string{"pci0000:00", pciBusID},
+			expectedErr: ErrNoPCIeSwitch,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSysfs := sysfsPath(t.TempDir())
+			devicePath := mockSysfs.devices(filepath.Join(test.path...))
+			touchFile(t, devicePath)
+			createSymlink(t, devicePath, mockSysfs.bus(filepath.Join("pci", "devices", pciBusID)))
+			sysfs = mockSysfs
+			t.Cleanup(func() {
+				sysfs = sysfsPath(sysfsRoot)
+			})
+
+			got, err := GetPCIeSwitchAttributeByPCIBusID(pciBusID)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Errorf("Expected error %v, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, *test.expectedAttribute) {
+				t.Errorf("Expected attribute %v, got %v", test.expectedAttribute, got)
+			}
+		})
+	}
+}
+
+func TestGetPCIeLinkAttributesByPCIBusID(t *testing.T) {
+	pciBusID := "0000:03:00.0"
+
+	tests := map[string]struct {
+		speed              string
+		width              string
+		expectedAttributes []DeviceAttribute
+		expectedErrMsg     string
+	}{
+		"gen4 x16": {
+			speed: "16.0 GT/s PCIe\n",
+			width: "16\n",
+			expectedAttributes: []DeviceAttribute{
+				{Name: StandardDeviceAttributePCIeLinkSpeed, Value: resourceapi.DeviceAttribute{IntValue: ptr.To(int64(16000))}},
+				{Name: StandardDeviceAttributePCIeLinkWidth, Value: resourceapi.DeviceAttribute{IntValue: ptr.To(int64(16))}},
+			},
+		},
+		"gen1 x1": {
+			speed: "2.5 GT/s\n",
+			width: "1\n",
+			expectedAttributes: []DeviceAttribute{
+				{Name: StandardDeviceAttributePCIeLinkSpeed, Value: resourceapi.DeviceAttribute{IntValue: ptr.To(int64(2500))}},
+				{Name: StandardDeviceAttributePCIeLinkWidth, Value: resourceapi.DeviceAttribute{IntValue: ptr.To(int64(1))}},
+			},
+		},
+		"unknown speed": {
+			speed:          "Unknown\n",
+			width:          "16\n",
+			expectedErrMsg: "failed to parse link speed",
+		},
+		"invalid width": {
+			speed:          "8.0 GT/s PCIe\n",
+			width:          "x16\n",
+			expectedErrMsg: "failed to parse link width",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSysfs := sysfsPath(t.TempDir())
+			writeFile(t, mockSysfs.bus(filepath.Join("pci", "devices", pciBusID, "current_link_speed")), test.speed)
+			writeFile(t, mockSysfs.bus(filepath.Join("pci", "devices", pciBusID, "current_link_width")), test.width)
+			sysfs = mockSysfs
+			t.Cleanup(func() {
+				sysfs = sysfsPath(sysfsRoot)
+			})
+
+			got, err := GetPCIeLinkAttributesByPCIBusID(pciBusID)
+			if test.expectedErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErrMsg) {
+					t.Errorf("Expected error message to contain %q, got %v", test.expectedErrMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.expectedAttributes) {
+				t.Errorf("Expected attributes %v, got %v", test.expectedAttributes, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package featuregates defines which optional DRA features are enabled.
+// The same [Features] type is used by all packages in this module which
+// depend on feature gates: the allocator in structured, the ResourceSlice
+// tracker and controller and the kubelet plugin helper.
+//
+// The names of the features match the Kubernetes feature gates. Plain
+// strings are used because this module must not depend on the feature
+// gate definitions in Kubernetes. [FromGates] can be used to populate
+// [Features] from a Kubernetes feature gate:
+//
+//	features := featuregates.FromGates(func(name string) bool {
+//		return utilfeature.DefaultFeatureGate.Enabled(featuregate.Feature(name))
+//	})
+package featuregates
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/cel"
+)
+
+// Names of the Kubernetes feature gates which correspond to the
+// fields in [Features].
+const (
+	DRAAdminAccess               = "DRAAdminAccess"
+	DRAConsumableCapacity        = "DRAConsumableCapacity"
+	DRADeviceBindingConditions   = "DRADeviceBindingConditions"
+	DRADeviceTaints              = "DRADeviceTaints"
+	DRAPartitionableDevices      = "DRAPartitionableDevices"
+	DRAPrioritizedList           = "DRAPrioritizedList"
+	DRAResourceClaimDeviceStatus = "DRAResourceClaimDeviceStatus"
+)
+
+// Features contains all feature gates that may influence the behavior of
+// the packages in this module.
+//
+// New fields get added when new features are supported. Their zero value
+// always disables the new feature, so code which sets fields by name keeps
+// its current behavior when updating to a newer release of this package.
+type Features struct {
+	// Sorted alphabetically. When adding a new entry, also extend Set,
+	// FromGates, Validate, All and, if applicable, Default.
+
+	AdminAccess          bool // DRAAdminAccess
+	ConsumableCapacity   bool // DRAConsumableCapacity
+	DeviceBinding        bool // DRADeviceBindingConditions, depends on DeviceStatus
+	DeviceStatus         bool // DRAResourceClaimDeviceStatus
+	DeviceTaints         bool // DRADeviceTaints
+	PartitionableDevices bool // DRAPartitionableDevices
+	PrioritizedList      bool // DRAPrioritizedList
+}
+
+// Default returns the features which are enabled by default in Kubernetes.
+func Default() Features {
+	return Features{
+		AdminAccess:     true,
+		DeviceStatus:    true,
+		PrioritizedList: true,
+	}
+}
+
+// All returns a Features instance with all features enabled.
+func All() Features {
+	return Features{
+		AdminAccess:          true,
+		ConsumableCapacity:   true,
+		DeviceBinding:        true,
+		DeviceStatus:         true,
+		DeviceTaints:         true,
+		PartitionableDevices: true,
+		PrioritizedList:      true,
+	}
+}
+
+// FromGates returns the features for which enabled returns true
+// when called with the name of the corresponding feature gate.
+func FromGates(enabled func(name string) bool) Features {
+	return Features{
+		AdminAccess:          enabled(DRAAdminAccess),
+		ConsumableCapacity:   enabled(DRAConsumableCapacity),
+		DeviceBinding:        enabled(DRADeviceBindingConditions),
+		DeviceStatus:         enabled(DRAResourceClaimDeviceStatus),
+		DeviceTaints:         enabled(DRADeviceTaints),
+		PartitionableDevices: enabled(DRAPartitionableDevices),
+		PrioritizedList:      enabled(DRAPrioritizedList),
+	}
+}
+
+// Validate returns an error if some enabled feature depends on
+// another feature which is disabled. Those dependencies are the
+// same as for the Kubernetes feature gates.
+func (f Features) Validate() error {
+	var errs []error
+	if f.DeviceBinding && !f.DeviceStatus {
+		errs = append(errs, fmt.Errorf("%s requires %s", DRADeviceBindingConditions, DRAResourceClaimDeviceStatus))
+	}
+	return errors.Join(errs...)
+}
+
+// Set returns the names of all features which are set to true.
+func (f Features) Set() sets.Set[string] {
+	enabled := sets.New[string]()
+	if f.AdminAccess {
+		enabled.Insert(DRAAdminAccess)
+	}
+	if f.ConsumableCapacity {
+		enabled.Insert(DRAConsumableCapacity)
+	}
+	if f.DeviceTaints {
+		enabled.Insert(DRADeviceTaints)
+	}
+	if f.PartitionableDevices {
+		enabled.Insert(DRAPartitionableDevices)
+	}
+	if f.PrioritizedList {
+		enabled.Insert(DRAPrioritizedList)
+	}
+	if f.DeviceBinding {
+		enabled.Insert(DRADeviceBindingConditions)
+	}
+	if f.DeviceStatus {
+		enabled.Insert(DRAResourceClaimDeviceStatus)
+	}
+	return enabled
+}
+
+// CEL returns the subset of the features which is relevant for
+// compiling CEL expressions.
+func (f Features) CEL() cel.Features {
+	return cel.Features{EnableConsumableCapacity: f.ConsumableCapacity}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuregates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromGates(t *testing.T) {
+	assert.Equal(t, All(), FromGates(func(string) bool { return true }), "all")
+	assert.Equal(t, Features{}, FromGates(func(string) bool { return false }), "none")
+
+	for _, features := range []Features{Default(), All(), {DeviceTaints: true, ConsumableCapacity: true}} {
+		enabled := features.Set()
+		assert.Equal(t, features, FromGates(enabled.Has), "round-trip through Set: %v", enabled)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Default().Validate(), "default")
+	require.NoError(t, All().Validate(), "all")
+	require.EqualError(t, Features{DeviceBinding: true}.Validate(), "DRADeviceBindingConditions requires DRAResourceClaimDeviceStatus")
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fuzzinput turns the random bytes of a fuzz test into decisions
+// for building valid API objects. Fuzz targets use it instead of fuzzing
+// the API types directly, because most randomly generated objects would
+// be rejected by the API server and thus are not interesting.
+package fuzzinput
+
+// Input consumes the fuzz data one byte per decision. Once the data is
+// exhausted, all decisions return their zero value, so every input
+// produces a finite and valid result.
+type Input struct {
+	data []byte
+}
+
+// New wraps the fuzz data.
+func New(data []byte) *Input {
+	return &Input{data: data}
+}
+
+// Byte returns the next byte, or zero if the data is exhausted.
+func (in *Input) Byte() byte {
+	if len(in.data) == 0 {
+		return 0
+	}
+	b := in.data[0]
+	in.data = in.data[1:]
+	return b
+}
+
+// Intn returns a number in the range [0, n). n must be in the
+// range [1, 256].
+func (in *Input) Intn(n int) int {
+	return int(in.Byte()) % n
+}
+
+// Bool returns true for odd bytes.
+func (in *Input) Bool() bool {
+	return in.Byte()%2 == 1
+}
+
+// Pick returns one of the options, which must not be empty.
+func Pick[T any](in *Input, options ...T) T {
+	return options[in.Intn(len(options))]
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fuzzinput
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInput(t *testing.T) {
+	in := New([]byte{7, 3, 5})
+	assert.Equal(t, 1, in.Intn(3))
+	assert.True(t, in.Bool())
+	assert.Equal(t, "b", Pick(in, "a", "b"))
+
+	// Exhausted input.
+	assert.Equal(t, byte(0), in.Byte())
+	assert.Equal(t, 0, in.Intn(10))
+	assert.False(t, in.Bool())
+	assert.Equal(t, "a", Pick(in, "a", "b"))
+}
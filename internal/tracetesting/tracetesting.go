@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracetesting provides an OpenTelemetry tracer provider which
+// records spans for inspection in unit tests.
+package tracetesting
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerProvider records the spans created by its tracers.
+type TracerProvider struct {
+	noop.TracerProvider
+
+	mutex sync.Mutex
+	spans []*Span
+}
+
+var _ trace.TracerProvider = &TracerProvider{}
+
+// Spans returns all spans created so far.
+func (p *TracerProvider) Spans() []*Span {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]*Span(nil), p.spans...)
+}
+
+// SpansByName returns the spans with the given name.
+func (p *TracerProvider) SpansByName(name string) []*Span {
+	var spans []*Span
+	for _, span := range p.Spans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func (p *TracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &tracer{provider: p}
+}
+
+type tracer struct {
+	noop.Tracer
+	provider *TracerProvider
+}
+
+func (t *tracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	span := &Span{provider: t.provider, Name: name, attributes: config.Attributes()}
+	if parent, ok := trace.SpanFromContext(ctx).(*Span); ok {
+		span.Parent = parent
+	}
+	t.provider.mutex.Lock()
+	defer t.provider.mutex.Unlock()
+	t.provider.spans = append(t.provider.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// Span is a recording span. Its methods may be called concurrently.
+type Span struct {
+	noop.Span
+	provider *TracerProvider
+
+	// Name and Parent do not change after creating the span.
+	Name   string
+	Parent *Span
+
+	mutex      sync.Mutex
+	attributes []attribute.KeyValue
+	status     otelcodes.Code
+	errors     []error
+	ended      bool
+}
+
+var _ trace.Span = &Span{}
+
+// Attributes returns the attributes that were set so far.
+func (s *Span) Attributes() []attribute.KeyValue {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]attribute.KeyValue(nil), s.attributes...)
+}
+
+// Attribute returns the most recent value of the attribute.
+func (s *Span) Attribute(key attribute.Key) (attribute.Value, bool) {
+	attributes := s.Attributes()
+	for i := len(attributes) - 1; i >= 0; i-- {
+		if attributes[i].Key == key {
+			return attributes[i].Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+// Status returns the status code.
+func (s *Span) Status() otelcodes.Code {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.status
+}
+
+// Errors returns the errors passed to RecordError.
+func (s *Span) Errors() []error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]error(nil), s.errors...)
+}
+
+// Ended returns true if End was called.
+func (s *Span) Ended() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ended
+}
+
+func (s *Span) IsRecording() bool {
+	return true
+}
+
+func (s *Span) TracerProvider() trace.TracerProvider {
+	return s.provider
+}
+
+func (s *Span) SetAttributes(kv ...attribute.KeyValue) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.attributes = append(s.attributes, kv...)
+}
+
+func (s *Span) SetStatus(code otelcodes.Code, description string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status = code
+}
+
+func (s *Span) RecordError(err error, options ...trace.EventOption) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.errors = append(s.errors, err)
+}
+
+func (s *Span) End(options ...trace.SpanEndOption) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ended = true
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// ErrPrepareDenied is wrapped by the per-claim error of a claim which
+// was rejected by a [PrepareAdmissionFunc]. Use with:
+//
+//	errors.Is(err, ErrPrepareDenied)
+var ErrPrepareDenied = errors.New("prepare denied")
+
+// PrepareAdmissionFunc implements a node-local policy for preparing claims.
+// It gets called for each claim before the claim is passed to
+// [DRAPlugin.PrepareResourceClaims], with the devices which were allocated
+// for the claim by the driver. A non-nil error denies preparing the claim.
+//
+// The function gets called while holding the lock that serializes
+// gRPC calls (see [Serialize]).
+type PrepareAdmissionFunc func(ctx context.Context, claim *resourceapi.ResourceClaim, devices []resourceapi.DeviceRequestAllocationResult) error
+
+// PrepareAdmission adds a policy check which runs before the DRA driver
+// prepares a claim, for example to deny using a device which is pinned by
+// some system daemon. This option may be used more than once, then all
+// policies must admit the claim.
+//
+// A denied claim is not passed to the DRA driver. Instead, the
+// kubelet gets an error for it which wraps [ErrPrepareDenied] and will
+// try again later.
+func PrepareAdmission(admit PrepareAdmissionFunc) Option {
+	return func(o *options) error {
+		o.prepareAdmission = append(o.prepareAdmission, admit)
+		return nil
+	}
+}
+
+// admitClaims checks all claims. It returns the admitted claims
+// and errors for the denied claims.
+func (d *Helper) admitClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) ([]*resourceapi.ResourceClaim, map[types.UID]error) {
+	if len(d.prepareAdmission) == 0 {
+		return claims, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	admitted := make([]*resourceapi.ResourceClaim, 0, len(claims))
+	denied := make(map[types.UID]error)
+	for _, claim := range claims {
+		var devices []resourceapi.DeviceRequestAllocationResult
+		for _, result := range claim.Status.Allocation.Devices.Results {
+			if result.Driver == d.driverName {
+				devices = append(devices, result)
+			}
+		}
+		if err := d.admitClaim(ctx, claim, devices); err != nil {
+			logger.V(3).Info("Denied preparing claim", "claim", klog.KObj(claim), "err", err)
+			denied[claim.UID] = err
+			continue
+		}
+		admitted = append(admitted, claim)
+	}
+	return admitted, denied
+}
+
+func (d *Helper) admitClaim(ctx context.Context, claim *resourceapi.ResourceClaim, devices []resourceapi.DeviceRequestAllocationResult) error {
+	for _, admit := range d.prepareAdmission {
+		if err := admit(ctx, claim, devices); err != nil {
+			return fmt.Errorf("%w: %w", ErrPrepareDenied, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin/fakekubelet"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestPrepareAdmission(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	driverName := "driver.example.com"
+	newClaim := func(name, device string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+			Status: resourceapi.ResourceClaimStatus{
+				Allocation: &resourceapi.AllocationResult{
+					Devices: resourceapi.DeviceAllocationResult{
+						Results: []resourceapi.DeviceRequestAllocationResult{
+							{Request: "req", Driver: driverName, Pool: "pool", Device: device},
+							{Request: "other", Driver: "other.example.com", Pool: "pool", Device: "pinned"},
+						},
+					},
+				},
+			},
+		}
+	}
+	allowedClaim := newClaim("allowed", "dev-0")
+	deniedClaim := newClaim("denied", "pinned")
+	plugin := &testPlugin{t: t}
+
+	var admitted []string
+	helper, err := Start(ctx, plugin,
+		DriverName(driverName),
+		KubeClient(fake.NewClientset(allowedClaim, deniedClaim)),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		PrepareAdmission(func(ctx context.Context, claim *resourceapi.ResourceClaim, devices []resourceapi.DeviceRequestAllocationResult) error {
+			for _, device := range devices {
+				admitted = append(admitted, device.Device)
+				if device.Device == "pinned" {
+					return errors.New("device is pinned by system daemon")
+				}
+			}
+			return nil
+		}),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	kubelet, err := fakekubelet.Register(ctx, path.Join(tempDir, driverName+"-reg.sock"))
+	require.NoError(t, err, "register")
+	defer kubelet.Close()
+
+	resp, err := kubelet.NodePrepareResources(ctx, allowedClaim, deniedClaim)
+	require.NoError(t, err, "prepare")
+	assert.Empty(t, resp.Claims[string(allowedClaim.UID)].Error, "allowed claim")
+	assert.Equal(t, "prepare denied: device is pinned by system daemon", resp.Claims[string(deniedClaim.UID)].Error, "denied claim")
+	assert.Equal(t, []types.UID{allowedClaim.UID}, plugin.getPrepared(), "claims passed to plugin")
+	assert.Equal(t, []string{"dev-0", "pinned"}, admitted, "devices passed to admission")
+
+	resp, err = kubelet.NodePrepareResources(ctx, deniedClaim)
+	require.NoError(t, err, "prepare")
+	assert.Equal(t, "prepare denied: device is pinned by system daemon", resp.Claims[string(deniedClaim.UID)].Error, "denied claim")
+	assert.Equal(t, []types.UID{allowedClaim.UID}, plugin.getPrepared(), "plugin must not be called when all claims are denied")
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// DefaultCDISpecDirs are the directories where container runtimes
+// look for CDI spec files by default.
+var DefaultCDISpecDirs = []string{"/etc/cdi", "/var/run/cdi"}
+
+// VerifyPreparedCDIDevices controls whether the helper checks the CDI
+// devices of each successfully prepared claim with [VerifyCDIDevices]
+// before responding to the kubelet. If no directories are given,
+// [DefaultCDISpecDirs] are used. Off by default.
+//
+// Without this, a CDI spec which does not match what the DRA driver
+// returned only gets noticed by the container runtime when starting
+// the containers of the pod, with less obvious errors. With it, the
+// claim fails to prepare and the kubelet tries again later. Failed
+// claims get rolled back if [RollbackFailedPrepare] is enabled.
+//
+// The spec directories must be the ones used by the container runtime,
+// mounted into the driver container under the same paths. Device nodes
+// and the host paths of bind mounts get checked inside the driver
+// container, so /dev and those host paths must be visible there, too.
+//
+// Spec files which cannot be read or decoded get logged and skipped.
+// They only cause a failure for claims with a CDI device which is not
+// defined by any of the other spec files.
+func VerifyPreparedCDIDevices(specDirs ...string) Option {
+	return func(o *options) error {
+		o.verifyCDIDevices = true
+		o.cdiSpecDirs = specDirs
+		return nil
+	}
+}
+
+// cdiSpec contains the parts of a CDI spec which are relevant for
+// verifying it. See
+// https://github.com/cncf-tags/container-device-interface/blob/main/SPEC.md.
+type cdiSpec struct {
+	Kind           string            `json:"kind"`
+	Devices        []cdiDevice       `json:"devices"`
+	ContainerEdits cdiContainerEdits `json:"containerEdits"`
+}
+
+type cdiDevice struct {
+	Name           string            `json:"name"`
+	ContainerEdits cdiContainerEdits `json:"containerEdits"`
+}
+
+type cdiContainerEdits struct {
+	DeviceNodes []cdiDeviceNode `json:"deviceNodes"`
+	Mounts      []cdiMount      `json:"mounts"`
+}
+
+type cdiDeviceNode struct {
+	Path     string `json:"path"`
+	HostPath string `json:"hostPath"`
+}
+
+type cdiMount struct {
+	HostPath      string   `json:"hostPath"`
+	ContainerPath string   `json:"containerPath"`
+	Type          string   `json:"type"`
+	Options       []string `json:"options"`
+}
+
+// VerifyCDIDevices checks that each CDI device ID is defined exactly once
+// by the spec files in the directories and that the edits of the device
+// can be applied: device nodes must exist on the host and be character or
+// block devices, bind mounts must have an existing host path and an
+// absolute container path. The spec-wide edits get checked the same way.
+//
+// Spec files are the *.json and *.yaml files directly inside the
+// directories. Directories which do not exist are skipped. Spec files
+// which cannot be read or decoded are skipped unless a device is not
+// found elsewhere, because the device might be defined in one of them.
+func VerifyCDIDevices(specDirs []string, cdiDeviceIDs ...string) error {
+	if len(cdiDeviceIDs) == 0 {
+		return nil
+	}
+	specs, err := readCDISpecs(specDirs)
+	if err != nil {
+		return err
+	}
+	return specs.verify(cdiDeviceIDs)
+}
+
+type cdiSpecFile struct {
+	path string
+	spec cdiSpec
+}
+
+// cdiSpecFiles contains all spec files that could be read and the
+// errors for those that could not.
+type cdiSpecFiles struct {
+	files  []cdiSpecFile
+	broken []error
+}
+
+func (specs *cdiSpecFiles) verify(cdiDeviceIDs []string) error {
+	var errs []error
+	for _, id := range cdiDeviceIDs {
+		if err := specs.verifyDevice(id); err != nil {
+			errs = append(errs, fmt.Errorf("CDI device %q: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func readCDISpecs(specDirs []string) (*cdiSpecFiles, error) {
+	specs := &cdiSpecFiles{}
+	for _, dir := range specDirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CDI spec directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !slices.Contains([]string{".json", ".yaml"}, path.Ext(entry.Name())) {
+				continue
+			}
+			filePath := path.Join(dir, entry.Name())
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				specs.broken = append(specs.broken, fmt.Errorf("read CDI spec: %w", err))
+				continue
+			}
+			var spec cdiSpec
+			if err := yaml.Unmarshal(data, &spec); err != nil {
+				specs.broken = append(specs.broken, fmt.Errorf("decode CDI spec %s: %w", filePath, err))
+				continue
+			}
+			specs.files = append(specs.files, cdiSpecFile{path: filePath, spec: spec})
+		}
+	}
+	return specs, nil
+}
+
+func (specs *cdiSpecFiles) verifyDevice(id string) error {
+	if err := ValidateCDIDeviceID(id); err != nil {
+		return err
+	}
+	kind, name, _ := strings.Cut(id, "=")
+
+	var found []string
+	var errs []error
+	for _, file := range specs.files {
+		if file.spec.Kind != kind {
+			continue
+		}
+		for _, device := range file.spec.Devices {
+			if device.Name != name {
+				continue
+			}
+			found = append(found, file.path)
+			errs = append(errs, verifyCDIContainerEdits(device.ContainerEdits)...)
+			errs = append(errs, verifyCDIContainerEdits(file.spec.ContainerEdits)...)
+		}
+	}
+	switch len(found) {
+	case 0:
+		if len(specs.broken) > 0 {
+			return fmt.Errorf("not defined by any valid CDI spec, perhaps because of: %w", errors.Join(specs.broken...))
+		}
+		return errors.New("not defined by any CDI spec")
+	case 1:
+		return errors.Join(errs...)
+	default:
+		return fmt.Errorf("defined more than once, in %s", strings.Join(found, ", "))
+	}
+}
+
+func verifyCDIContainerEdits(edits cdiContainerEdits) []error {
+	var errs []error
+	for _, node := range edits.DeviceNodes {
+		hostPath := node.HostPath
+		if hostPath == "" {
+			hostPath = node.Path
+		}
+		info, err := os.Stat(hostPath)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("device node: %w", err))
+		case info.Mode()&os.ModeDevice == 0:
+			errs = append(errs, fmt.Errorf("device node %s: not a character or block device", hostPath))
+		}
+	}
+	for _, mount := range edits.Mounts {
+		if !path.IsAbs(mount.ContainerPath) {
+			errs = append(errs, fmt.Errorf("mount of %s: container path %q must be absolute", mount.HostPath, mount.ContainerPath))
+		}
+		if mount.Type != "" && mount.Type != "bind" && !slices.Contains(mount.Options, "bind") && !slices.Contains(mount.Options, "rbind") {
+			// Not a bind mount, the host path is the source of
+			// some other file system (tmpfs, ...).
+			continue
+		}
+		if _, err := os.Stat(mount.HostPath); err != nil {
+			errs = append(errs, fmt.Errorf("mount: %w", err))
+		}
+	}
+	return errs
+}
+
+// verifyPreparedClaims turns the result of claims with CDI devices
+// which cannot be verified into an error.
+func (d *Helper) verifyPreparedClaims(ctx context.Context, claims []*resourceapi.ResourceClaim, result map[types.UID]PrepareResult) {
+	if !d.verifyCDIDevices {
+		return
+	}
+	logger := klog.FromContext(ctx)
+	// The spec files get read only once and only if needed.
+	var specs *cdiSpecFiles
+	var readErr error
+	for _, claim := range claims {
+		claimResult, ok := result[claim.UID]
+		if !ok || claimResult.Err != nil {
+			continue
+		}
+		var ids []string
+		for _, device := range claimResult.Devices {
+			ids = append(ids, device.CDIDeviceIDs...)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if specs == nil && readErr == nil {
+			specs, readErr = readCDISpecs(d.cdiSpecDirs)
+			if specs != nil {
+				for _, err := range specs.broken {
+					logger.Error(err, "Skipping CDI spec")
+				}
+			}
+		}
+		err := readErr
+		if err == nil {
+			err = specs.verify(ids)
+		}
+		if err != nil {
+			logger.V(3).Info("Verifying CDI devices failed", "claim", klog.KObj(claim), "err", err)
+			result[claim.UID] = PrepareResult{Err: fmt.Errorf("verify CDI devices: %w", err)}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestVerifyCDIDevices(t *testing.T) {
+	tempDir := t.TempDir()
+	specDir := path.Join(tempDir, "cdi")
+	require.NoError(t, os.Mkdir(specDir, 0700))
+	regularFile := path.Join(tempDir, "file")
+	require.NoError(t, os.WriteFile(regularFile, nil, 0600))
+	writeSpec := func(name, content string) {
+		require.NoError(t, os.WriteFile(path.Join(specDir, name), []byte(content), 0600))
+	}
+	writeSpec("vendor.yaml", fmt.Sprintf(`
+cdiVersion: "0.6.0"
+kind: vendor.example.com/gpu
+devices:
+- name: good
+  containerEdits:
+    deviceNodes:
+    - path: /dev/gpu0
+      hostPath: /dev/null
+    mounts:
+    - hostPath: %[1]s
+      containerPath: /data
+    - hostPath: tmpfs
+      containerPath: /tmp
+      type: tmpfs
+- name: not-a-device
+  containerEdits:
+    deviceNodes:
+    - path: %[2]s
+- name: bad-mounts
+  containerEdits:
+    mounts:
+    - hostPath: %[1]s/missing
+      containerPath: /data
+    - hostPath: %[1]s
+      containerPath: data
+- name: duplicate
+`, tempDir, regularFile))
+	writeSpec("other.json", `{"cdiVersion": "0.6.0", "kind": "vendor.example.com/gpu", "devices": [{"name": "duplicate"}]}`)
+	writeSpec("ignored.txt", "not a spec")
+	specDirs := []string{specDir, path.Join(tempDir, "does-not-exist")}
+
+	testCases := map[string]struct {
+		id          string
+		expectError string
+	}{
+		"good": {
+			id: "vendor.example.com/gpu=good",
+		},
+		"invalid-id": {
+			id:          "gpu",
+			expectError: `CDI device "gpu": CDI device ID "gpu": must have the form <vendor>/<class>=<name>`,
+		},
+		"unknown": {
+			id:          "vendor.example.com/gpu=unknown",
+			expectError: `CDI device "vendor.example.com/gpu=unknown": not defined by any CDI spec`,
+		},
+		"other-class": {
+			id:          "vendor.example.com/nic=good",
+			expectError: `CDI device "vendor.example.com/nic=good": not defined by any CDI spec`,
+		},
+		"not-a-device": {
+			id:          "vendor.example.com/gpu=not-a-device",
+			expectError: fmt.Sprintf(`CDI device "vendor.example.com/gpu=not-a-device": device node %s: not a character or block device`, regularFile),
+		},
+		"bad-mounts": {
+			id:          "vendor.example.com/gpu=bad-mounts",
+			expectError: fmt.Sprintf("CDI device \"vendor.example.com/gpu=bad-mounts\": mount: stat %[1]s/missing: no such file or directory\nmount of %[1]s: container path \"data\" must be absolute", tempDir),
+		},
+		"duplicate": {
+			id:          "vendor.example.com/gpu=duplicate",
+			expectError: fmt.Sprintf(`CDI device "vendor.example.com/gpu=duplicate": defined more than once, in %s, %s`, path.Join(specDir, "other.json"), path.Join(specDir, "vendor.yaml")),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := VerifyCDIDevices(specDirs, tc.id)
+			if tc.expectError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectError)
+		})
+	}
+
+	t.Run("broken-spec", func(t *testing.T) {
+		brokenDir := path.Join(tempDir, "broken")
+		require.NoError(t, os.Mkdir(brokenDir, 0700))
+		brokenFile := path.Join(brokenDir, "broken.yaml")
+		require.NoError(t, os.WriteFile(brokenFile, []byte("devices: {"), 0600))
+		specDirs := []string{specDir, brokenDir}
+
+		require.NoError(t, VerifyCDIDevices(specDirs, "vendor.example.com/gpu=good"), "device in valid spec")
+		err := VerifyCDIDevices(specDirs, "vendor.example.com/gpu=unknown")
+		require.ErrorContains(t, err, `CDI device "vendor.example.com/gpu=unknown": not defined by any valid CDI spec, perhaps because of: decode CDI spec `+brokenFile, "device not in valid spec")
+	})
+
+	t.Run("prepared-claims", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		d := &Helper{verifyCDIDevices: true, cdiSpecDirs: specDirs}
+		good := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "good", UID: "good-uid"}}
+		bad := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bad", UID: "bad-uid"}}
+		result := map[types.UID]PrepareResult{
+			good.UID: {Devices: []Device{{PoolName: "pool", DeviceName: "dev-0", CDIDeviceIDs: []string{"vendor.example.com/gpu=good"}}}},
+			bad.UID:  {Devices: []Device{{PoolName: "pool", DeviceName: "dev-1", CDIDeviceIDs: []string{"vendor.example.com/gpu=unknown"}}}},
+		}
+		d.verifyPreparedClaims(ctx, []*resourceapi.ResourceClaim{good, bad}, result)
+		require.NoError(t, result[good.UID].Err, "good claim")
+		require.EqualError(t, result[bad.UID].Err, `verify CDI devices: CDI device "vendor.example.com/gpu=unknown": not defined by any CDI spec`, "bad claim")
+		assert.Empty(t, result[bad.UID].Devices, "devices of bad claim")
+	})
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkpointFilename is the name of the file in the plugin data directory
+// where the helper stores its own state.
+const checkpointFilename = "helper-checkpoint.json"
+
+// checkpointVersion must be bumped when making incompatible changes.
+const checkpointVersion = "v1"
+
+// checkpoint is the state of the helper which has to survive restarts.
+// It is stored as JSON.
+type checkpoint struct {
+	Version string `json:"version"`
+
+	// Rollbacks contains claims for which preparing failed and
+	// the helper is undoing what the DRA driver might have done
+	// already. An entry gets removed once unpreparing or preparing
+	// the claim succeeds.
+	Rollbacks map[types.UID]rollbackEntry `json:"rollbacks,omitempty"`
+
+	// PendingUnprepares contains claims which were removed while
+	// unpreparing them failed. Nothing will ask the DRA driver
+	// to unprepare them again, so the helper keeps retrying.
+	// An entry gets removed once unpreparing succeeds.
+	PendingUnprepares map[types.UID]pendingUnprepareEntry `json:"pendingUnprepares,omitempty"`
+
+	// DeviceUsage contains the devices of all claims which were
+	// prepared successfully and the pods using those claims.
+	// An entry gets removed once unpreparing succeeds.
+	DeviceUsage map[types.UID]deviceUsageEntry `json:"deviceUsage,omitempty"`
+}
+
+type rollbackEntry struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Time      metav1.Time `json:"time"`
+
+	// PrepareError is the error which triggered the rollback.
+	PrepareError string `json:"prepareError"`
+
+	// UnprepareError is the error of the most recent attempt to
+	// unprepare the claim, empty if none was made yet.
+	UnprepareError string `json:"unprepareError,omitempty"`
+}
+
+type pendingUnprepareEntry struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Time      metav1.Time `json:"time"`
+
+	// Attempts is the number of failed attempts, including the
+	// initial one.
+	Attempts int `json:"attempts"`
+
+	// LastAttempt is the time of the most recent failed attempt.
+	LastAttempt metav1.Time `json:"lastAttempt"`
+
+	// UnprepareError is the error of the most recent attempt.
+	UnprepareError string `json:"unprepareError"`
+}
+
+// checkpointStore reads and writes the checkpoint file. Updates are
+// serialized inside the process. Across processes, the lock for
+// serializing gRPC calls must be held.
+//
+// A nil store is valid and does nothing.
+type checkpointStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func newCheckpointStore(dir string) *checkpointStore {
+	return &checkpointStore{path: path.Join(dir, checkpointFilename)}
+}
+
+// get returns the current content of the checkpoint.
+func (s *checkpointStore) get() (*checkpoint, error) {
+	if s == nil {
+		return &checkpoint{Version: checkpointVersion}, nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.read()
+}
+
+// update reads the checkpoint, calls the function to modify it, then writes it.
+func (s *checkpointStore) update(modify func(c *checkpoint)) error {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, err := s.read()
+	if err != nil {
+		return err
+	}
+	modify(c)
+	return s.write(c)
+}
+
+func (s *checkpointStore) read() (*checkpoint, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &checkpoint{Version: checkpointVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("decode checkpoint %s: %w", s.path, err)
+	}
+	if c.Version != checkpointVersion {
+		return nil, fmt.Errorf("checkpoint %s: unsupported version %q", s.path, c.Version)
+	}
+	return &c, nil
+}
+
+func (s *checkpointStore) write(c *checkpoint) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+	// Write to a temporary file first, then rename. This way
+	// the checkpoint is never left incomplete.
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// SlowPrepareReason is the reason of the Warning event which gets
+// emitted when preparing claims takes longer than the
+// threshold configured with [SlowPrepareThreshold].
+const SlowPrepareReason = "SlowPrepare"
+
+// SlowPrepareThreshold enables Warning events for NodePrepareResources
+// calls which take longer than the given duration. Zero, the default,
+// disables them.
+//
+// The event gets emitted once per call while the call is still running, for
+// each claim and each pod which has reserved one of the claims. This way users
+// can see why their pod is stuck in ContainerCreating without having to
+// look at the driver logs. The event has [SlowPrepareReason] as reason.
+func SlowPrepareThreshold(threshold time.Duration) Option {
+	return func(o *options) error {
+		o.slowPrepareThreshold = threshold
+		return nil
+	}
+}
+
+// RemainingTime returns the time left until the deadline of the context.
+// Inside [DRAPlugin.PrepareResourceClaims] and
+// [DRAPlugin.UnprepareResourceClaims], that deadline is the one set
+// by the kubelet for the gRPC call. Drivers can use this to decide whether
+// a lengthy operation is worth starting. False is returned if the context
+// has no deadline.
+func RemainingTime(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// startEventRecorder sets up the event recorder. The returned function
+// must be called to shut it down.
+func (d *Helper) startEventRecorder(ctx context.Context) func() {
+	broadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	broadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: d.kubeClient.CoreV1().Events("")})
+	d.recorder = broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: d.driverName, Host: d.nodeName})
+	return broadcaster.Shutdown
+}
+
+// watchSlowPrepare emits events for the claims if the returned function
+// does not get called before the threshold is reached.
+func (d *Helper) watchSlowPrepare(claims []*resourceapi.ResourceClaim) func() {
+	if d.slowPrepareThreshold <= 0 || d.recorder == nil || len(claims) == 0 {
+		return func() {}
+	}
+	start := time.Now()
+	timer := time.AfterFunc(d.slowPrepareThreshold, func() {
+		duration := time.Since(start).Round(time.Millisecond)
+		for _, claim := range claims {
+			d.recorder.Eventf(claim, v1.EventTypeWarning, SlowPrepareReason,
+				"Preparing devices by DRA driver %s has been running for %s, longer than the expected %s", d.driverName, duration, d.slowPrepareThreshold)
+			for _, consumer := range claim.Status.ReservedFor {
+				if consumer.APIGroup != "" || consumer.Resource != "pods" {
+					continue
+				}
+				pod := &v1.ObjectReference{
+					APIVersion: "v1",
+					Kind:       "Pod",
+					Namespace:  claim.Namespace,
+					Name:       consumer.Name,
+					UID:        consumer.UID,
+				}
+				d.recorder.Eventf(pod, v1.EventTypeWarning, SlowPrepareReason,
+					"Preparing devices for ResourceClaim %s by DRA driver %s has been running for %s, longer than the expected %s", claim.Name, d.driverName, duration, d.slowPrepareThreshold)
+			}
+		}
+	})
+	return func() { timer.Stop() }
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin/fakekubelet"
+	"k8s.io/klog/v2/ktesting"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1"
+)
+
+func TestSlowPrepareEvents(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	driverName := "driver.example.com"
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status: resourceapi.ResourceClaimStatus{
+			Allocation: &resourceapi.AllocationResult{
+				Devices: resourceapi.DeviceAllocationResult{
+					Results: []resourceapi.DeviceRequestAllocationResult{
+						{Request: "req", Driver: driverName, Pool: "pool", Device: "dev-0"},
+					},
+				},
+			},
+			ReservedFor: []resourceapi.ResourceClaimConsumerReference{
+				{Resource: "pods", Name: "pod", UID: "pod-uid"},
+			},
+		},
+	}
+	kubeClient := fake.NewClientset(claim)
+
+	helper, err := Start(ctx, &testPlugin{t: t, prepareDelay: 500 * time.Millisecond},
+		DriverName(driverName),
+		KubeClient(kubeClient),
+		NodeName("worker"),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		SlowPrepareThreshold(10*time.Millisecond),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	kubelet, err := fakekubelet.Register(ctx, path.Join(tempDir, driverName+"-reg.sock"))
+	require.NoError(t, err, "register")
+	defer kubelet.Close()
+
+	resp, err := kubelet.NodePrepareResources(ctx, claim)
+	require.NoError(t, err, "prepare")
+	assert.Empty(t, resp.Claims[string(claim.UID)].Error, "claim error")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		events, err := kubeClient.CoreV1().Events("default").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err, "list events")
+		var objects []string
+		for _, event := range events.Items {
+			assert.Equal(t, SlowPrepareReason, event.Reason, "reason")
+			assert.Equal(t, "Warning", event.Type, "type")
+			objects = append(objects, event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name)
+		}
+		assert.ElementsMatch(t, []string{"ResourceClaim/claim", "Pod/pod"}, objects, "involved objects")
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestPrepareDeadlineExceeded(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+	plugin := &testPlugin{t: t}
+
+	helper, err := Start(ctx, plugin,
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset(claim)),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		// The methods get called directly.
+		RegistrationService(false),
+		DRAService(false),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	expiredCtx, cancel := context.WithDeadline(ctx, time.Now())
+	defer cancel()
+	_, err = (&nodePluginImplementation{Helper: helper}).NodePrepareResources(expiredCtx, &drapbv1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1.Claim{{Namespace: claim.Namespace, Name: claim.Name, UID: string(claim.UID)}},
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), "status code for %v", err)
+	assert.Empty(t, plugin.getPrepared(), "claims passed to plugin")
+
+	_, err = (&nodePluginImplementation{Helper: helper}).NodeUnprepareResources(expiredCtx, &drapbv1.NodeUnprepareResourcesRequest{
+		Claims: []*drapbv1.Claim{{Namespace: claim.Namespace, Name: claim.Name, UID: string(claim.UID)}},
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), "status code for %v", err)
+	assert.Empty(t, plugin.getUnprepared(), "claims passed to plugin")
+}
+
+func TestRemainingTime(t *testing.T) {
+	_, ok := RemainingTime(context.Background())
+	assert.False(t, ok, "no deadline")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	remaining, ok := RemainingTime(ctx)
+	assert.True(t, ok, "deadline")
+	assert.InDelta(t, time.Hour, remaining, float64(time.Minute), "remaining time")
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"slices"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// DebugStatePath is the path at which the debug server started by
+// [DebugSocket] serves a textual dump of the helper state.
+const DebugStatePath = "/debug/state"
+
+// DebugSocket enables an HTTP server on a Unix domain socket at the given
+// path. It serves the Go runtime profiling data under /debug/pprof/ (see
+// [net/http/pprof]) and a dump of the helper state under [DebugStatePath]:
+// registered gRPC services, claims prepared by this instance, the
+// device usage (see [TrackDeviceUsage]) and the checkpoint contents.
+// Off by default.
+//
+// The socket is only accessible to the user of the driver process.
+// [PeerAuthorization] also applies to it. The directory must exist.
+//
+// Usage example inside the driver container:
+//
+//	curl --unix-socket /run/driver/debug.sock http://localhost/debug/state
+func DebugSocket(path string) Option {
+	return func(o *options) error {
+		o.debugSocketPath = path
+		return nil
+	}
+}
+
+// trackPrepared remembers which claims were prepared successfully.
+// Only needed for the debug socket.
+func (d *Helper) trackPrepared(claims []*resourceapi.ResourceClaim, result map[types.UID]PrepareResult) {
+	if d.preparedClaims == nil {
+		return
+	}
+	d.preparedMutex.Lock()
+	defer d.preparedMutex.Unlock()
+	for _, claim := range claims {
+		if claimResult, ok := result[claim.UID]; ok && claimResult.Err == nil {
+			d.preparedClaims[claim.UID] = NamespacedObject{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}
+		}
+	}
+}
+
+// trackUnprepared forgets about claims which were unprepared successfully.
+func (d *Helper) trackUnprepared(result map[types.UID]error) {
+	d.preparedMutex.Lock()
+	defer d.preparedMutex.Unlock()
+	for uid, err := range result {
+		if err == nil {
+			delete(d.preparedClaims, uid)
+		}
+	}
+}
+
+// startDebugServer runs an HTTP server with the debug handlers
+// until the context is canceled.
+func (d *Helper) startDebugServer(ctx context.Context, e endpoint) error {
+	logger := klog.FromContext(ctx)
+	listener, err := e.listen(ctx)
+	if err != nil {
+		return fmt.Errorf("listen on %q: %w", e.path(), err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc(DebugStatePath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		d.writeState(w)
+	})
+	server := &http.Server{
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	d.wg.Add(2)
+	go func() {
+		defer d.wg.Done()
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			d.plugin.HandleError(ctx, err, "debug HTTP server failed")
+		}
+	}()
+	go func() {
+		defer d.wg.Done()
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	logger.V(3).Info("Debug HTTP server started", "endpoint", e.path())
+	return nil
+}
+
+// writeState dumps information about the helper in a format that is
+// meant to be read by humans. It may change at any time.
+func (d *Helper) writeState(w io.Writer) {
+	fmt.Fprintf(w, "Driver: %s\n", d.driverName)
+	fmt.Fprintf(w, "Node: %s\n", d.nodeName)
+	fmt.Fprintf(w, "Healthy: %s\n", errorOrOK(d.Healthy()))
+	fmt.Fprintf(w, "Ready: %s\n", errorOrOK(d.Ready()))
+
+	fmt.Fprintf(w, "\nServices:\n")
+	for _, service := range d.supportedServices {
+		fmt.Fprintf(w, "  %s\n", service)
+	}
+	fmt.Fprintf(w, "DRA endpoint: %s\n", d.draEndpointPath)
+	if d.registrar != nil {
+		fmt.Fprintf(w, "Registration endpoint: %s\n", d.registrar.server.endpoint.path())
+		if status := d.registrar.status.Load(); status != nil {
+			fmt.Fprintf(w, "Registration status: registered=%t error=%q\n", status.PluginRegistered, status.Error)
+		} else {
+			fmt.Fprintf(w, "Registration status: unknown\n")
+		}
+	}
+
+	d.preparedMutex.Lock()
+	prepared := slices.SortedFunc(maps.Values(d.preparedClaims), func(a, b NamespacedObject) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	d.preparedMutex.Unlock()
+	fmt.Fprintf(w, "\nPrepared claims (%d):\n", len(prepared))
+	for _, claim := range prepared {
+		fmt.Fprintf(w, "  %s\n", claim)
+	}
+
+	d.writeDeviceUsage(w)
+
+	if d.checkpoints != nil {
+		fmt.Fprintf(w, "\nCheckpoint:\n")
+		c, err := d.checkpoints.get()
+		if err != nil {
+			fmt.Fprintf(w, "  error: %v\n", err)
+			return
+		}
+		data, err := json.MarshalIndent(c, "  ", "  ")
+		if err != nil {
+			fmt.Fprintf(w, "  error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(w, "  %s\n", data)
+	}
+}
+
+func errorOrOK(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestDebugSocket(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	debugSocket := path.Join(tempDir, "debug.sock")
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+
+	helper, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		NodeName("worker"),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		RollbackFailedPrepare(true),
+		DebugSocket(debugSocket),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	info, err := os.Stat(debugSocket)
+	require.NoError(t, err, "stat debug socket")
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "permissions")
+
+	_, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err, "prepare")
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", debugSocket)
+			},
+		},
+	}
+	get := func(t *testing.T, path string) string {
+		t.Helper()
+		resp, err := client.Get("http://localhost" + path)
+		require.NoError(t, err, "GET %s", path)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "read body")
+		require.Equal(t, http.StatusOK, resp.StatusCode, "status code, body:\n%s", body)
+		return string(body)
+	}
+
+	state := get(t, DebugStatePath)
+	assert.Contains(t, state, "Driver: driver.example.com\n")
+	assert.Contains(t, state, "Prepared claims (1):\n  default/claim:claim-uid\n")
+	assert.Contains(t, state, "Checkpoint:\n")
+
+	_, err = helper.UnprepareClaims(ctx, []NamespacedObject{{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}})
+	require.NoError(t, err, "unprepare")
+	state = get(t, DebugStatePath)
+	assert.Contains(t, state, "Prepared claims (0):\n")
+
+	assert.Contains(t, get(t, "/debug/pprof/"), "goroutine")
+}
+
+func TestDebugSocketDisabled(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+	helper, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	_, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err, "prepare")
+	assert.Nil(t, helper.preparedClaims, "prepared claims are not tracked without debug socket")
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// DecommissionMode defines what [Helper.Decommission] does with
+// the ResourceSlices of the node.
+type DecommissionMode int
+
+const (
+	// DecommissionDeleteSlices removes all ResourceSlices of the driver
+	// for the node. The scheduler then no longer considers the devices.
+	DecommissionDeleteSlices DecommissionMode = iota
+
+	// DecommissionTaintDevices keeps the ResourceSlices, but adds a
+	// NoSchedule taint with the key returned by [DecommissionTaintKey]
+	// to all devices. Pods which are already using the devices keep
+	// running, no new pods get scheduled. Depends on the
+	// DRADeviceTaints feature.
+	DecommissionTaintDevices
+)
+
+// DecommissionTaintKey returns the key of the taint added by
+// [DecommissionTaintDevices].
+func DecommissionTaintKey(driverName string) string {
+	return driverName + "/decommissioned"
+}
+
+// Decommission stops the helper like [Helper.Stop] and then removes
+// or taints the ResourceSlices published for the node. This is meant
+// for drivers which shut down permanently, for example because they get
+// uninstalled from the node. It must not be used for normal restarts
+// because then the devices would disappear temporarily.
+//
+// Decommission blocks until the ResourceSlices were updated or the
+// context gets canceled.
+func (d *Helper) Decommission(ctx context.Context, mode DecommissionMode) error {
+	if d.nodeName == "" {
+		return errors.New("no NodeName was set, cannot decommission ResourceSlices")
+	}
+	if mode == DecommissionTaintDevices && d.features != nil && !d.features.DeviceTaints {
+		return fmt.Errorf("cannot taint devices, the %s feature is disabled", featuregates.DRADeviceTaints)
+	}
+
+	// The ResourceSlice controller must not restore what we are about to change.
+	d.Stop()
+
+	logger := klog.FromContext(ctx)
+	// Slices with a node selector or per-device node selection don't have
+	// the node name, so all slices of the driver need to be checked.
+	selector := fields.Set{
+		resourceapi.ResourceSliceSelectorDriver: d.driverName,
+	}
+	sliceList, err := d.resourceClient.ResourceSlices().List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("list ResourceSlices: %w", err)
+	}
+
+	var errs []error
+	for i := range sliceList.Items {
+		slice := &sliceList.Items[i]
+		if !d.publishedForNode(slice) {
+			continue
+		}
+		switch mode {
+		case DecommissionDeleteSlices:
+			err := d.resourceClient.ResourceSlices().Delete(ctx, slice.Name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &slice.UID}})
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("delete ResourceSlice %s: %w", slice.Name, err))
+				continue
+			}
+			logger.V(3).Info("Deleted ResourceSlice", "slice", klog.KObj(slice))
+		case DecommissionTaintDevices:
+			if err := d.taintDevices(ctx, slice); err != nil {
+				errs = append(errs, fmt.Errorf("taint devices in ResourceSlice %s: %w", slice.Name, err))
+				continue
+			}
+			logger.V(3).Info("Tainted devices in ResourceSlice", "slice", klog.KObj(slice))
+		default:
+			return fmt.Errorf("unknown decommission mode %d", mode)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publishedForNode returns true for slices of the driver which are
+// either for the node or owned by it, which is the case for all
+// slices published by the helper.
+func (d *Helper) publishedForNode(slice *resourceapi.ResourceSlice) bool {
+	if slice.Spec.Driver != d.driverName {
+		return false
+	}
+	if ptr.Deref(slice.Spec.NodeName, "") == d.nodeName {
+		return true
+	}
+	return slices.ContainsFunc(slice.OwnerReferences, func(owner metav1.OwnerReference) bool {
+		return owner.APIVersion == "v1" && owner.Kind == "Node" && owner.Name == d.nodeName &&
+			(d.nodeUID == "" || owner.UID == d.nodeUID)
+	})
+}
+
+func (d *Helper) taintDevices(ctx context.Context, slice *resourceapi.ResourceSlice) error {
+	key := DecommissionTaintKey(d.driverName)
+	now := metav1.Now()
+	slice = slice.DeepCopy()
+	for i := range slice.Spec.Devices {
+		device := &slice.Spec.Devices[i]
+		if slices.ContainsFunc(device.Taints, func(taint resourceapi.DeviceTaint) bool { return taint.Key == key }) {
+			continue
+		}
+		device.Taints = append(device.Taints, resourceapi.DeviceTaint{
+			Key:       key,
+			Effect:    resourceapi.DeviceTaintEffectNoSchedule,
+			TimeAdded: &now,
+		})
+	}
+	actualSlice, err := d.resourceClient.ResourceSlices().Update(ctx, slice, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	for _, device := range actualSlice.Spec.Devices {
+		if !slices.ContainsFunc(device.Taints, func(taint resourceapi.DeviceTaint) bool { return taint.Key == key }) {
+			return fmt.Errorf("taint was dropped by the apiserver, probably because the %s feature is disabled", featuregates.DRADeviceTaints)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestDecommission(t *testing.T) {
+	driverName := "driver.example.com"
+	resources := resourceslice.DriverResources{
+		Pools: map[string]resourceslice.Pool{
+			"pool": {
+				Slices: []resourceslice.Slice{{Devices: []resourceapi.Device{{Name: "dev-0"}, {Name: "dev-1"}}}},
+			},
+		},
+	}
+
+	for name, mode := range map[string]DecommissionMode{
+		"delete": DecommissionDeleteSlices,
+		"taint":  DecommissionTaintDevices,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			kubeClient := fake.NewClientset()
+			tempDir := t.TempDir()
+			helper, err := Start(ctx, &testPlugin{t: t},
+				DriverName(driverName),
+				KubeClient(kubeClient),
+				NodeName("worker"),
+				NodeUID("worker-uid"),
+				PluginDataDirectoryPath(tempDir),
+				RegistrarDirectoryPath(tempDir),
+				Resources(resources),
+			)
+			require.NoError(t, err, "start")
+			defer helper.Stop()
+
+			require.EventuallyWithT(t, func(t *assert.CollectT) {
+				slices, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+				require.NoError(t, err, "list slices")
+				require.Len(t, slices.Items, 1)
+			}, 10*time.Second, 10*time.Millisecond)
+
+			require.NoError(t, helper.Decommission(ctx, mode), "decommission")
+
+			slices, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+			require.NoError(t, err, "list slices")
+			switch mode {
+			case DecommissionDeleteSlices:
+				assert.Empty(t, slices.Items, "slices after decommissioning")
+			case DecommissionTaintDevices:
+				require.Len(t, slices.Items, 1, "slices after decommissioning")
+				for _, device := range slices.Items[0].Spec.Devices {
+					require.Len(t, device.Taints, 1, "taints of device %s", device.Name)
+					assert.Equal(t, DecommissionTaintKey(driverName), device.Taints[0].Key)
+					assert.Equal(t, resourceapi.DeviceTaintEffectNoSchedule, device.Taints[0].Effect)
+				}
+			}
+		})
+	}
+}
+
+func TestDecommissionOwnedSlices(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	driverName := "driver.example.com"
+	nodeOwner := func(nodeName string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "v1", Kind: "Node", Name: nodeName, UID: types.UID(nodeName + "-uid")}}
+	}
+	newSlice := func(name, driver string, owners []metav1.OwnerReference, spec resourceapi.ResourceSliceSpec) *resourceapi.ResourceSlice {
+		spec.Driver = driver
+		return &resourceapi.ResourceSlice{ObjectMeta: metav1.ObjectMeta{Name: name, OwnerReferences: owners}, Spec: spec}
+	}
+	nodeSelector := &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+		MatchExpressions: []v1.NodeSelectorRequirement{{Key: "example.com/group", Operator: v1.NodeSelectorOpExists}},
+	}}}
+	kubeClient := fake.NewClientset(
+		newSlice("node-name", driverName, nodeOwner("worker"), resourceapi.ResourceSliceSpec{NodeName: ptr.To("worker")}),
+		newSlice("node-selector", driverName, nodeOwner("worker"), resourceapi.ResourceSliceSpec{NodeSelector: nodeSelector}),
+		newSlice("per-device", driverName, nodeOwner("worker"), resourceapi.ResourceSliceSpec{PerDeviceNodeSelection: ptr.To(true)}),
+		newSlice("other-node", driverName, nodeOwner("other"), resourceapi.ResourceSliceSpec{NodeSelector: nodeSelector}),
+		newSlice("other-driver", "other.example.com", nodeOwner("worker"), resourceapi.ResourceSliceSpec{NodeName: ptr.To("worker")}),
+	)
+	tempDir := t.TempDir()
+	helper, err := Start(ctx, &testPlugin{t: t},
+		DriverName(driverName),
+		KubeClient(kubeClient),
+		NodeName("worker"),
+		NodeUID("worker-uid"),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	require.NoError(t, helper.Decommission(ctx, DecommissionDeleteSlices), "decommission")
+
+	sliceList, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "list slices")
+	var remaining []string
+	for _, slice := range sliceList.Items {
+		remaining = append(remaining, slice.Name)
+	}
+	assert.ElementsMatch(t, []string{"other-node", "other-driver"}, remaining, "remaining slices")
+}
+
+func TestDecommissionDisabledTaints(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	features := featuregates.Default()
+	helper := &Helper{driverName: "driver.example.com", nodeName: "worker", features: &features}
+	require.EqualError(t, helper.Decommission(ctx, DecommissionTaintDevices), "cannot taint devices, the DRADeviceTaints feature is disabled")
+}
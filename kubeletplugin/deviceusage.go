@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TrackDeviceUsage controls whether the helper keeps track of which
+// claims and pods use the devices that were prepared successfully.
+// Off by default.
+//
+// When enabled, [Helper.DeviceUsers] answers which claims and pods
+// use a certain device, for example during incident response.
+// The information is also included in the [DebugSocket] state dump.
+// It gets stored in a checkpoint file in the plugin data directory
+// (see [PluginDataDirectoryPath]) and thus survives restarts.
+//
+// The pods are the ones listed in the ReservedFor field of the claim
+// at the time when it was prepared.
+func TrackDeviceUsage(enabled bool) Option {
+	return func(o *options) error {
+		o.trackDeviceUsage = enabled
+		return nil
+	}
+}
+
+// DeviceUser is a claim which uses a device.
+type DeviceUser struct {
+	// Claim identifies the ResourceClaim.
+	Claim NamespacedObject
+	// PodUIDs contains the pods which had reserved the claim.
+	PodUIDs []types.UID
+}
+
+type deviceUsageEntry struct {
+	Namespace string              `json:"namespace"`
+	Name      string              `json:"name"`
+	Devices   []deviceUsageDevice `json:"devices"`
+	PodUIDs   []types.UID         `json:"podUIDs,omitempty"`
+}
+
+type deviceUsageDevice struct {
+	PoolName   string `json:"poolName"`
+	DeviceName string `json:"deviceName"`
+}
+
+// deviceUsage is the in-memory copy of the device usage in the checkpoint.
+//
+// A nil usage is valid and tracks nothing.
+type deviceUsage struct {
+	mutex  sync.Mutex
+	claims map[types.UID]deviceUsageEntry
+}
+
+// loadDeviceUsage initializes the device usage from the checkpoint.
+func loadDeviceUsage(checkpoints *checkpointStore) (*deviceUsage, error) {
+	c, err := checkpoints.get()
+	if err != nil {
+		return nil, err
+	}
+	usage := &deviceUsage{claims: c.DeviceUsage}
+	if usage.claims == nil {
+		usage.claims = make(map[types.UID]deviceUsageEntry)
+	}
+	return usage, nil
+}
+
+// DeviceUsers returns the claims which use the device, sorted by
+// namespace and name. Without [TrackDeviceUsage], the result is
+// always empty.
+func (d *Helper) DeviceUsers(poolName, deviceName string) []DeviceUser {
+	if d.deviceUsage == nil {
+		return nil
+	}
+	d.deviceUsage.mutex.Lock()
+	defer d.deviceUsage.mutex.Unlock()
+
+	var users []DeviceUser
+	for uid, entry := range d.deviceUsage.claims {
+		if slices.Contains(entry.Devices, deviceUsageDevice{PoolName: poolName, DeviceName: deviceName}) {
+			users = append(users, DeviceUser{
+				Claim:   NamespacedObject{UID: uid, NamespacedName: types.NamespacedName{Namespace: entry.Namespace, Name: entry.Name}},
+				PodUIDs: slices.Clone(entry.PodUIDs),
+			})
+		}
+	}
+	slices.SortFunc(users, func(a, b DeviceUser) int {
+		return strings.Compare(a.Claim.String(), b.Claim.String())
+	})
+	return users
+}
+
+// recordDeviceUsage adds or updates the entries of all claims which
+// were prepared successfully.
+func (d *Helper) recordDeviceUsage(ctx context.Context, claims []*resourceapi.ResourceClaim, result map[types.UID]PrepareResult) {
+	if d.deviceUsage == nil {
+		return
+	}
+	d.deviceUsage.mutex.Lock()
+	defer d.deviceUsage.mutex.Unlock()
+
+	entries := make(map[types.UID]deviceUsageEntry)
+	for _, claim := range claims {
+		claimResult, ok := result[claim.UID]
+		if !ok || claimResult.Err != nil {
+			continue
+		}
+		entry := deviceUsageEntry{Namespace: claim.Namespace, Name: claim.Name}
+		for _, device := range claimResult.Devices {
+			usedDevice := deviceUsageDevice{PoolName: device.PoolName, DeviceName: device.DeviceName}
+			if !slices.Contains(entry.Devices, usedDevice) {
+				entry.Devices = append(entry.Devices, usedDevice)
+			}
+		}
+		for _, consumer := range claim.Status.ReservedFor {
+			if consumer.APIGroup == "" && consumer.Resource == "pods" {
+				entry.PodUIDs = append(entry.PodUIDs, consumer.UID)
+			}
+		}
+		entries[claim.UID] = entry
+	}
+	if len(entries) == 0 {
+		return
+	}
+	maps.Copy(d.deviceUsage.claims, entries)
+	d.writeDeviceUsageLocked(ctx)
+}
+
+// forgetDeviceUsage removes the entries of all claims which were
+// unprepared successfully.
+func (d *Helper) forgetDeviceUsage(ctx context.Context, result map[types.UID]error) {
+	if d.deviceUsage == nil {
+		return
+	}
+	d.deviceUsage.mutex.Lock()
+	defer d.deviceUsage.mutex.Unlock()
+
+	changed := false
+	for uid, err := range result {
+		if _, ok := d.deviceUsage.claims[uid]; ok && err == nil {
+			delete(d.deviceUsage.claims, uid)
+			changed = true
+		}
+	}
+	if changed {
+		d.writeDeviceUsageLocked(ctx)
+	}
+}
+
+func (d *Helper) writeDeviceUsageLocked(ctx context.Context) {
+	usage := maps.Clone(d.deviceUsage.claims)
+	if err := d.checkpoints.update(func(c *checkpoint) {
+		c.DeviceUsage = usage
+	}); err != nil {
+		d.plugin.HandleError(ctx, recoverableError{error: err}, "recording device usage")
+	}
+}
+
+// writeDeviceUsage adds the device usage to the debug state dump.
+func (d *Helper) writeDeviceUsage(w io.Writer) {
+	if d.deviceUsage == nil {
+		return
+	}
+	d.deviceUsage.mutex.Lock()
+	users := make(map[deviceUsageDevice][]string)
+	for uid, entry := range d.deviceUsage.claims {
+		claim := NamespacedObject{UID: uid, NamespacedName: types.NamespacedName{Namespace: entry.Namespace, Name: entry.Name}}
+		for _, device := range entry.Devices {
+			users[device] = append(users[device], fmt.Sprintf("%s pods=%v", claim, entry.PodUIDs))
+		}
+	}
+	d.deviceUsage.mutex.Unlock()
+
+	devices := slices.SortedFunc(maps.Keys(users), func(a, b deviceUsageDevice) int {
+		return strings.Compare(a.PoolName+"/"+a.DeviceName, b.PoolName+"/"+b.DeviceName)
+	})
+	fmt.Fprintf(w, "\nDevice usage (%d):\n", len(devices))
+	for _, device := range devices {
+		slices.Sort(users[device])
+		fmt.Fprintf(w, "  %s/%s:\n", device.PoolName, device.DeviceName)
+		for _, user := range users[device] {
+			fmt.Fprintf(w, "    %s\n", user)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+// usagePlugin prepares one device per claim, named after the claim.
+type usagePlugin struct {
+	*testPlugin
+}
+
+func (p usagePlugin) PrepareResourceClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) (map[types.UID]PrepareResult, error) {
+	result, err := p.testPlugin.PrepareResourceClaims(ctx, claims)
+	for _, claim := range claims {
+		result[claim.UID] = PrepareResult{Devices: []Device{{PoolName: "pool", DeviceName: "dev-" + claim.Name}}}
+	}
+	return result, err
+}
+
+func TestTrackDeviceUsage(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", UID: "claim-uid"},
+		Status: resourceapi.ResourceClaimStatus{
+			Allocation: &resourceapi.AllocationResult{},
+			ReservedFor: []resourceapi.ResourceClaimConsumerReference{
+				{Resource: "pods", Name: "pod", UID: "pod-uid"},
+				{APIGroup: "example.com", Resource: "other", Name: "other", UID: "other-uid"},
+			},
+		},
+	}
+	claimRef := NamespacedObject{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}
+	start := func() *Helper {
+		helper, err := Start(ctx, usagePlugin{&testPlugin{t: t}},
+			DriverName("driver.example.com"),
+			KubeClient(fake.NewClientset()),
+			PluginDataDirectoryPath(tempDir),
+			RegistrarDirectoryPath(tempDir),
+			Standalone(),
+			TrackDeviceUsage(true),
+		)
+		require.NoError(t, err, "start")
+		return helper
+	}
+
+	helper := start()
+	defer func() { helper.Stop() }()
+	assert.Empty(t, helper.DeviceUsers("pool", "dev-a"), "before prepare")
+
+	_, err := helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err, "prepare")
+	expectUsers := []DeviceUser{{Claim: claimRef, PodUIDs: []types.UID{"pod-uid"}}}
+	assert.Equal(t, expectUsers, helper.DeviceUsers("pool", "dev-a"), "after prepare")
+	assert.Empty(t, helper.DeviceUsers("pool", "dev-b"), "other device")
+
+	var state strings.Builder
+	helper.writeState(&state)
+	assert.Contains(t, state.String(), "Device usage (1):\n  pool/dev-a:\n    default/a:claim-uid pods=[pod-uid]\n")
+
+	// The usage survives a restart.
+	helper.Stop()
+	helper = start()
+	assert.Equal(t, expectUsers, helper.DeviceUsers("pool", "dev-a"), "after restart")
+
+	_, err = helper.UnprepareClaims(ctx, []NamespacedObject{claimRef})
+	require.NoError(t, err, "unprepare")
+	assert.Empty(t, helper.DeviceUsers("pool", "dev-a"), "after unprepare")
+	c, err := helper.checkpoints.get()
+	require.NoError(t, err, "get checkpoint")
+	assert.Empty(t, c.DeviceUsage, "checkpoint after unprepare")
+}
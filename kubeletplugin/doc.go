@@ -65,4 +65,11 @@ limitations under the License.
 // provide two variants of its DaemonSet deployment. In the variant with
 // support for rolling updates, `maxSurge` can be set to a non-zero
 // value. Administrators have to be careful about running the right variant.
+//
+// For unit testing of a DRA driver without a real node,
+// [k8s.io/dynamic-resource-allocation/kubeletplugin/fakekubelet]
+// can take the role of the kubelet.
+// [k8s.io/dynamic-resource-allocation/kubeletplugin/drivertesting]
+// combines it with a complete fake driver for tests of components
+// which interact with DRA drivers.
 package kubeletplugin
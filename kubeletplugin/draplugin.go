@@ -18,13 +18,16 @@ package kubeletplugin
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path"
 	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"k8s.io/klog/v2"
 
@@ -34,7 +37,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	cgoresource "k8s.io/client-go/kubernetes/typed/resource/v1"
+	"k8s.io/client-go/tools/record"
 	draclient "k8s.io/dynamic-resource-allocation/client"
+	"k8s.io/dynamic-resource-allocation/featuregates"
 	"k8s.io/dynamic-resource-allocation/resourceclaim"
 	"k8s.io/dynamic-resource-allocation/resourceslice"
 	drahealthv1alpha1 "k8s.io/kubelet/pkg/apis/dra-health/v1alpha1"
@@ -84,7 +89,12 @@ type DRAPlugin interface {
 	// must have exactly one entry for each claim, identified by the UID of
 	// the corresponding ResourceClaim. For each claim, preparation
 	// can be either successful (no error set in the per-ResourceClaim PrepareResult)
-	// or can be reported as failed.
+	// or can be reported as failed. [PrepareResultBuilder] can be used to
+	// construct a PrepareResult which is known to be valid.
+	//
+	// The returned error gets mapped to a gRPC status code. Errors
+	// which already have a gRPC status (see [google.golang.org/grpc/status])
+	// keep their code.
 	//
 	// It is possible to create the CDI spec files which define the CDI devices
 	// on-the-fly in PrepareResourceClaims. UnprepareResourceClaims then can
@@ -468,6 +478,138 @@ func DRAService(enabled bool) Option {
 	}
 }
 
+// SingleSocket controls whether the DRA gRPC service is served on the
+// registration socket instead of a separate socket in the plugin data
+// directory. Off by default.
+//
+// When enabled, the endpoint reported to the kubelet during registration
+// is the registration socket and [PluginSocket] and [PluginListener]
+// have no effect. The plugin data directory is still used for
+// other files, like the lock file for [RollingUpdate]. This reduces the
+// number of sockets that need to be accessible to the driver and the
+// kubelet, which simplifies security policies.
+//
+// This option requires that both [RegistrationService] and [DRAService]
+// are enabled.
+func SingleSocket(enabled bool) Option {
+	return func(o *options) error {
+		o.singleSocket = enabled
+		return nil
+	}
+}
+
+// Resources defines the initial set of resources which get published in
+// ResourceSlice objects. Publishing starts as part of [Start], with the
+// same Kubernetes client, logger and lifecycle as the gRPC services.
+// The ResourceSlice controller gets stopped together with the helper.
+//
+// This is equivalent to calling [Helper.PublishResources] after Start
+// returned, except that configuration problems (like a missing [NodeName])
+// are reported by Start. PublishResources can still be called later to
+// replace the initial resources.
+func Resources(resources resourceslice.DriverResources) Option {
+	return func(o *options) error {
+		o.resources = &resources
+		return nil
+	}
+}
+
+// SocketPermissions sets the file permissions of the Unix domain sockets
+// created for the registration and DRA services. By default, the
+// permissions are determined by the umask of the process.
+//
+// Restricting access to the owner (0600) prevents other local processes
+// from connecting, as long as the kubelet runs as the same user or as root.
+// This has no effect for sockets created by [RegistrarListener] or
+// [PluginListener].
+func SocketPermissions(mode os.FileMode) Option {
+	return func(o *options) error {
+		o.socketPermissions = mode
+		return nil
+	}
+}
+
+// Features describes which optional DRA features are enabled in the
+// cluster. They get passed on to the ResourceSlice controller, which then
+// removes fields of disabled features before publishing (see
+// [resourceslice.Options.Features]), and [Helper.Decommission] fails right
+// away for [DecommissionTaintDevices] when device taints are disabled.
+// By default, the helper relies on the apiserver to drop such fields.
+func Features(features featuregates.Features) Option {
+	return func(o *options) error {
+		if err := features.Validate(); err != nil {
+			return fmt.Errorf("invalid features: %w", err)
+		}
+		o.features = &features
+		return nil
+	}
+}
+
+// TracerProvider enables OpenTelemetry tracing of the NodePrepareResources
+// and NodeUnprepareResources calls like [TracingInterceptor], with the
+// driver name added to the spans and devices, and of ResourceSlice
+// publishing (see [resourceslice.Options.TracerProvider]).
+func TracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(o *options) error {
+		o.tracerProvider = tracerProvider
+		return nil
+	}
+}
+
+// PeerAuthorization enables checking the credentials of each process which
+// connects to the registration or DRA service. Connections are rejected if
+// the authorizer returns an error. [AllowUIDs] and [AllowGIDs] cover typical
+// policies.
+//
+// Peer credentials are only available for Unix domain sockets on Linux.
+// Start fails on other platforms when this option is used.
+func PeerAuthorization(authorize PeerAuthorizer) Option {
+	return func(o *options) error {
+		o.peerAuthorizer = authorize
+		return nil
+	}
+}
+
+// newOptions applies the options to the defaults.
+func newOptions(opts ...Option) (options, error) {
+	o := options{
+		logger:        klog.Background(),
+		grpcVerbosity: 6, // Logs requests and responses, which can be large.
+		serialize:     true,
+		nodeV1beta1:   true,
+		nodeV1:        true,
+		pluginRegistrationEndpoint: endpoint{
+			dir: KubeletRegistryDir,
+		},
+		draService:          true,
+		registrationService: true,
+	}
+	for _, option := range opts {
+		if err := option(&o); err != nil {
+			return o, err
+		}
+	}
+	return o, nil
+}
+
+// completeEndpoints fills in the default file and directory names
+// which depend on other options.
+func (o *options) completeEndpoints() {
+	uidPart := ""
+	if o.rollingUpdateUID != "" {
+		uidPart = "-" + string(o.rollingUpdateUID)
+	}
+	if o.pluginRegistrationEndpoint.file == "" {
+		o.pluginRegistrationEndpoint.file = o.driverName + uidPart + "-reg.sock"
+	}
+	if o.pluginDataDirectoryPath == "" {
+		o.pluginDataDirectoryPath = path.Join(KubeletPluginsDir, o.driverName)
+	}
+	if o.pluginSocket == "" {
+		o.pluginSocket = "dra" + uidPart + ".sock" // "dra" is hard-coded. The directory is unique, so we get a unique full path also without the UID.
+	}
+}
+
 type options struct {
 	logger                     klog.Logger
 	grpcVerbosity              int
@@ -488,7 +630,27 @@ type options struct {
 	nodeV1                     bool
 	registrationService        bool
 	draService                 bool
+	singleSocket               bool
+	slowPrepareThreshold       time.Duration
+	retryFailedUnprepare       bool
+	nodeHandler                NodeHandler
+	nodeTaintKeys              []string
+	debugSocketPath            string
 	healthService              *bool
+	resources                  *resourceslice.DriverResources
+	prepareAdmission           []PrepareAdmissionFunc
+	rollbackFailedPrepare      bool
+	cachePrepareResults        bool
+	trackDeviceUsage           bool
+	verifyCDIDevices           bool
+	cdiSpecDirs                []string
+	faultInjector              injectFaultFunc
+	socketPermissions          os.FileMode
+	peerAuthorizer             PeerAuthorizer
+	healthAddress              string
+	healthTLSConfig            *tls.Config
+	tracerProvider             trace.TracerProvider
+	features                   *featuregates.Features
 }
 
 // Helper combines the kubelet registration service and the DRA node plugin
@@ -503,14 +665,40 @@ type Helper struct {
 	pluginServer     *grpcServer
 	plugin           DRAPlugin
 	driverName       string
+	tracerProvider   trace.TracerProvider
+	features         *featuregates.Features
 	nodeName         string
 	nodeUID          types.UID
 	kubeClient       kubernetes.Interface
 	resourceClient   cgoresource.ResourceV1Interface
+	prepareAdmission []PrepareAdmissionFunc
 	serialize        bool
 	grpcMutex        sync.Mutex
 	grpcLockFilePath string
 
+	rollbacks   *rollbacks       // nil if disabled.
+	checkpoints *checkpointStore // nil if nothing needs to be stored.
+
+	prepareCache *prepareCache // nil if disabled.
+	deviceUsage  *deviceUsage  // nil if disabled.
+
+	verifyCDIDevices bool
+	cdiSpecDirs      []string
+
+	faultInjector injectFaultFunc // nil unless built for fault injection.
+
+	retryFailedUnprepare  bool
+	unprepareRetryTrigger chan struct{}
+
+	slowPrepareThreshold time.Duration
+	recorder             record.EventRecorder // nil if no events are emitted.
+
+	// Information for the debug socket.
+	supportedServices []string
+	draEndpointPath   string
+	preparedMutex     sync.Mutex
+	preparedClaims    map[types.UID]NamespacedObject // nil if the debug socket is disabled.
+
 	// Information about resource publishing changes concurrently and thus
 	// must be protected by the mutex. The controller gets started only
 	// if needed.
@@ -519,8 +707,8 @@ type Helper struct {
 }
 
 // Start sets up all enabled gRPC servers (by default, one for registration,
-// one for the DRA node client) and implements them by calling a [DRAPlugin]
-// implementation.
+// one for the DRA node client, see [SingleSocket] for combining them) and
+// implements them by calling a [DRAPlugin] implementation.
 //
 // The context and/or DRAPlugin.Stop can be used to stop all background activity.
 // Stop also blocks. A logger can be stored in the context to add values or
@@ -528,25 +716,13 @@ type Helper struct {
 //
 // [KubeClient] and [DriverName] options are mandatory.
 // If the plugin will be used to publish resources, [NodeName]
-// is also mandatory.
+// is also mandatory. Those resources can be passed to Start
+// via [Resources] or published later with [Helper.PublishResources].
 func Start(ctx context.Context, plugin DRAPlugin, opts ...Option) (result *Helper, finalErr error) {
 	logger := klog.FromContext(ctx)
-	o := options{
-		logger:        klog.Background(),
-		grpcVerbosity: 6, // Logs requests and responses, which can be large.
-		serialize:     true,
-		nodeV1beta1:   true,
-		nodeV1:        true,
-		pluginRegistrationEndpoint: endpoint{
-			dir: KubeletRegistryDir,
-		},
-		draService:          true,
-		registrationService: true,
-	}
-	for _, option := range opts {
-		if err := option(&o); err != nil {
-			return nil, err
-		}
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
 	}
 
 	if o.driverName == "" {
@@ -558,28 +734,74 @@ func Start(ctx context.Context, plugin DRAPlugin, opts ...Option) (result *Helpe
 	if o.rollingUpdateUID != "" && o.pluginRegistrationEndpoint.file != "" {
 		return nil, errors.New("rolling updates and explicit registration socket filename are mutually exclusive")
 	}
-	uidPart := ""
-	if o.rollingUpdateUID != "" {
-		uidPart = "-" + string(o.rollingUpdateUID)
+	if o.resources != nil && o.nodeName == "" {
+		return nil, errors.New("no NodeName was set to publish resources")
 	}
-	if o.pluginRegistrationEndpoint.file == "" {
-		o.pluginRegistrationEndpoint.file = o.driverName + uidPart + "-reg.sock"
+	if o.nodeHandler != nil && o.nodeName == "" {
+		return nil, errors.New("no NodeName was set to watch the node")
 	}
-	if o.pluginDataDirectoryPath == "" {
-		o.pluginDataDirectoryPath = path.Join(KubeletPluginsDir, o.driverName)
+	if o.singleSocket && (!o.registrationService || !o.draService) {
+		return nil, errors.New("single socket mode requires both the registration and the DRA service")
 	}
-	if o.pluginSocket == "" {
-		o.pluginSocket = "dra" + uidPart + ".sock" // "dra" is hard-coded. The directory is unique, so we get a unique full path also without the UID.
+	if o.peerAuthorizer != nil && !peerCredentialsSupported {
+		return nil, errPeerCredentialsUnsupported
+	}
+	if o.tracerProvider != nil {
+		o.unaryInterceptors = append([]grpc.UnaryServerInterceptor{tracingInterceptor(o.tracerProvider, o.driverName)}, o.unaryInterceptors...)
 	}
+	if o.faultInjector != nil {
+		o.unaryInterceptors = append(o.unaryInterceptors, faultInterceptor(o.faultInjector))
+	}
+	o.pluginRegistrationEndpoint.permissions = o.socketPermissions
+	o.pluginRegistrationEndpoint.authorizePeer = o.peerAuthorizer
+	o.completeEndpoints()
 
 	d := &Helper{
-		driverName:     o.driverName,
-		nodeName:       o.nodeName,
-		nodeUID:        o.nodeUID,
-		kubeClient:     o.kubeClient,
-		resourceClient: draclient.New(o.kubeClient),
-		serialize:      o.serialize,
-		plugin:         plugin,
+		driverName:       o.driverName,
+		tracerProvider:   o.tracerProvider,
+		features:         o.features,
+		nodeName:         o.nodeName,
+		nodeUID:          o.nodeUID,
+		kubeClient:       o.kubeClient,
+		resourceClient:   draclient.New(o.kubeClient),
+		prepareAdmission: o.prepareAdmission,
+		serialize:        o.serialize,
+		plugin:           plugin,
+		faultInjector:    o.faultInjector,
+
+		slowPrepareThreshold: o.slowPrepareThreshold,
+	}
+	if o.verifyCDIDevices {
+		d.verifyCDIDevices = true
+		d.cdiSpecDirs = o.cdiSpecDirs
+		if len(d.cdiSpecDirs) == 0 {
+			d.cdiSpecDirs = DefaultCDISpecDirs
+		}
+	}
+	if o.rollbackFailedPrepare || o.retryFailedUnprepare || o.trackDeviceUsage {
+		d.retryFailedUnprepare = o.retryFailedUnprepare
+		d.unprepareRetryTrigger = make(chan struct{}, 1)
+		d.checkpoints = newCheckpointStore(o.pluginDataDirectoryPath)
+	}
+	if o.trackDeviceUsage {
+		usage, err := loadDeviceUsage(d.checkpoints)
+		if err != nil {
+			return nil, fmt.Errorf("load device usage: %w", err)
+		}
+		d.deviceUsage = usage
+	}
+	if o.rollbackFailedPrepare {
+		rollbacks, err := loadRollbacks(d.checkpoints)
+		if err != nil {
+			return nil, fmt.Errorf("load rollbacks: %w", err)
+		}
+		d.rollbacks = rollbacks
+	}
+	if o.debugSocketPath != "" {
+		d.preparedClaims = make(map[types.UID]NamespacedObject)
+	}
+	if o.cachePrepareResults {
+		d.prepareCache = &prepareCache{results: make(map[types.UID]PrepareResult)}
 	}
 	if o.rollingUpdateUID != "" {
 		dir := o.pluginDataDirectoryPath
@@ -613,6 +835,22 @@ func Start(ctx context.Context, plugin DRAPlugin, opts ...Option) (result *Helpe
 		}
 	}()
 
+	// The recorder is used by the gRPC handlers and therefore
+	// must be ready before the servers start.
+	if o.slowPrepareThreshold > 0 {
+		stopEventRecorder := d.startEventRecorder(ctx)
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			<-ctx.Done()
+			stopEventRecorder()
+		}()
+	}
+
+	// Finish rollbacks of a previous instance before
+	// the kubelet can call us again.
+	d.replayRollbacks(ctx)
+
 	var supportedServices []string
 	if o.nodeV1 {
 		supportedServices = append(supportedServices, drapbv1.DRAPluginService)
@@ -630,12 +868,39 @@ func Start(ctx context.Context, plugin DRAPlugin, opts ...Option) (result *Helpe
 		return nil, errors.New("no supported DRA gRPC API is implemented and enabled")
 	}
 	draEndpoint := endpoint{
-		dir:        o.pluginDataDirectoryPath,
-		file:       o.pluginSocket,
-		listenFunc: o.draEndpointListen,
+		dir:           o.pluginDataDirectoryPath,
+		file:          o.pluginSocket,
+		listenFunc:    o.draEndpointListen,
+		permissions:   o.socketPermissions,
+		authorizePeer: o.peerAuthorizer,
 	}
 
-	if o.draService {
+	registerDRAServices := func(grpcServer *grpc.Server) {
+		if o.nodeV1 {
+			logger.V(5).Info("registering v1.DRAPlugin gRPC service")
+			drapbv1.RegisterDRAPluginServer(grpcServer, &nodePluginImplementation{Helper: d})
+		}
+
+		if o.nodeV1beta1 {
+			logger.V(5).Info("registering v1beta1.DRAPlugin gRPC service")
+			drapbv1beta1.RegisterDRAPluginServer(grpcServer, drapbv1beta1.V1ServerWrapper{DRAPluginServer: &nodePluginImplementation{Helper: d}})
+		}
+
+		if heatlhServer, ok := d.plugin.(drahealthv1alpha1.DRAResourceHealthServer); ok {
+			if o.healthService == nil || *o.healthService {
+				logger.V(5).Info("registering v1alpha1.DRAResourceHealth gRPC service")
+				drahealthv1alpha1.RegisterDRAResourceHealthServer(grpcServer, heatlhServer)
+			}
+		}
+	}
+	var registrarServices []registerService
+	if o.singleSocket {
+		// The registrar serves everything, the kubelet gets pointed towards it.
+		draEndpoint = o.pluginRegistrationEndpoint
+		registrarServices = append(registrarServices, registerDRAServices)
+	}
+
+	if o.draService && !o.singleSocket {
 		// Run the node plugin gRPC server first to ensure that it is ready.
 		pluginServer, err := startGRPCServer(
 			klog.LoggerWithName(logger, "dra"),
@@ -646,24 +911,7 @@ func Start(ctx context.Context, plugin DRAPlugin, opts ...Option) (result *Helpe
 			func(ctx context.Context, err error) { // This error handler is REQUIRED
 				plugin.HandleError(ctx, err, "DRA gRPC server failed")
 			},
-			func(grpcServer *grpc.Server) {
-				if o.nodeV1 {
-					logger.V(5).Info("registering v1.DRAPlugin gRPC service")
-					drapbv1.RegisterDRAPluginServer(grpcServer, &nodePluginImplementation{Helper: d})
-				}
-
-				if o.nodeV1beta1 {
-					logger.V(5).Info("registering v1beta1.DRAPlugin gRPC service")
-					drapbv1beta1.RegisterDRAPluginServer(grpcServer, drapbv1beta1.V1ServerWrapper{DRAPluginServer: &nodePluginImplementation{Helper: d}})
-				}
-
-				if heatlhServer, ok := d.plugin.(drahealthv1alpha1.DRAResourceHealthServer); ok {
-					if o.healthService == nil || *o.healthService {
-						logger.V(5).Info("registering v1alpha1.DRAResourceHealth gRPC service")
-						drahealthv1alpha1.RegisterDRAResourceHealthServer(grpcServer, heatlhServer)
-					}
-				}
-			},
+			registerDRAServices,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("start DRA service: %w", err)
@@ -685,12 +933,15 @@ func Start(ctx context.Context, plugin DRAPlugin, opts ...Option) (result *Helpe
 			func(ctx context.Context, err error) {
 				plugin.HandleError(ctx, err, "registrar gRPC server failed")
 			},
+			registrarServices...,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("start registrar: %w", err)
 		}
 		d.registrar = registrar
 	}
+	d.supportedServices = supportedServices
+	d.draEndpointPath = draEndpoint.path()
 
 	// startGRPCServer and startRegistrar don't implement cancellation
 	// themselves, we add that for both here.
@@ -709,6 +960,45 @@ func Start(ctx context.Context, plugin DRAPlugin, opts ...Option) (result *Helpe
 		d.mutex.Unlock()
 	}()
 
+	if o.retryFailedUnprepare {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.retryPendingUnprepares(ctx)
+		}()
+	}
+
+	if o.nodeHandler != nil {
+		if err := d.startNodeWatch(ctx, o.nodeHandler, o.nodeTaintKeys); err != nil {
+			return nil, fmt.Errorf("watch node: %w", err)
+		}
+	}
+
+	if o.debugSocketPath != "" {
+		if err := d.startDebugServer(ctx, endpoint{
+			dir:           path.Dir(o.debugSocketPath),
+			file:          path.Base(o.debugSocketPath),
+			permissions:   0600,
+			authorizePeer: o.peerAuthorizer,
+		}); err != nil {
+			return nil, fmt.Errorf("start debug server: %w", err)
+		}
+	}
+
+	if o.healthAddress != "" {
+		if err := d.startHealthServer(ctx, o.healthAddress, o.healthTLSConfig); err != nil {
+			return nil, fmt.Errorf("start health server: %w", err)
+		}
+	}
+
+	if o.resources != nil {
+		// The goroutine above takes care of stopping the controller,
+		// so this has to come after it.
+		if err := d.PublishResources(ctx, *o.resources); err != nil {
+			return nil, fmt.Errorf("publish resources: %w", err)
+		}
+	}
+
 	return d, nil
 }
 
@@ -731,6 +1021,13 @@ func (d *Helper) Stop() {
 // after it returns before all information is actually written
 // to the API server.
 //
+// Each call replaces the previously published resources. This can be used
+// to react to changes at runtime, like hot-plugging devices or
+// reconfiguring partitions. Only pools which differ from the previous call
+// get synced again and prepared claims are not affected. It is the
+// responsibility of the DRA driver to not remove devices which are
+// still in use.
+//
 // It is the responsibility of the caller to ensure that the pools and
 // slices described in the driver resources parameters are valid
 // according to the restrictions defined in the resource.k8s.io API.
@@ -772,10 +1069,12 @@ func (d *Helper) PublishResources(_ context.Context, resources resourceslice.Dri
 		var err error
 		if d.resourceSliceController, err = resourceslice.StartController(controllerCtx,
 			resourceslice.Options{
-				DriverName: d.driverName,
-				KubeClient: d.kubeClient,
-				Owner:      &owner,
-				Resources:  driverResources,
+				DriverName:     d.driverName,
+				KubeClient:     d.kubeClient,
+				Owner:          &owner,
+				Resources:      driverResources,
+				TracerProvider: d.tracerProvider,
+				Features:       d.features,
 				ErrorHandler: func(ctx context.Context, err error, msg string) {
 					// ResourceSlice publishing errors like dropped fields or
 					// invalid spec are not going to get resolved by retrying,
@@ -799,8 +1098,7 @@ func (d *Helper) RegistrationStatus() *registerapi.RegistrationStatus {
 	if d.registrar == nil {
 		return nil
 	}
-	// TODO: protect against concurrency issues.
-	return d.registrar.status
+	return d.registrar.status.Load()
 }
 
 // SetGetInfoError configures the registration server to make
@@ -845,18 +1143,12 @@ func (d *nodePluginImplementation) NodePrepareResources(ctx context.Context, req
 	// Do slow API calls before serializing.
 	claims, err := d.getResourceClaims(ctx, req.Claims)
 	if err != nil {
-		return nil, fmt.Errorf("get resource claims: %w", err)
+		return nil, grpcError(err, "get resource claims")
 	}
 
-	unlock, err := d.serializeGRPCIfEnabled()
+	result, err := d.PrepareClaims(ctx, claims)
 	if err != nil {
-		return nil, fmt.Errorf("serialize gRPC: %w", err)
-	}
-	defer unlock()
-
-	result, err := d.plugin.PrepareResourceClaims(ctx, claims)
-	if err != nil {
-		return nil, fmt.Errorf("prepare resource claims: %w", err)
+		return nil, grpcError(err, "prepare resource claims")
 	}
 
 	resp := &drapbv1.NodePrepareResourcesResponse{Claims: map[string]*drapbv1.NodePrepareResourceResponse{}}
@@ -914,19 +1206,13 @@ func (d *nodePluginImplementation) getResourceClaims(ctx context.Context, claims
 
 // NodeUnprepareResources implements [draapi.NodeUnprepareResources].
 func (d *nodePluginImplementation) NodeUnprepareResources(ctx context.Context, req *drapbv1.NodeUnprepareResourcesRequest) (*drapbv1.NodeUnprepareResourcesResponse, error) {
-	unlock, err := d.serializeGRPCIfEnabled()
-	if err != nil {
-		return nil, fmt.Errorf("serialize gRPC: %w", err)
-	}
-	defer unlock()
-
 	claims := make([]NamespacedObject, 0, len(req.Claims))
 	for _, claim := range req.Claims {
 		claims = append(claims, NamespacedObject{UID: types.UID(claim.UID), NamespacedName: types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}})
 	}
-	result, err := d.plugin.UnprepareResourceClaims(ctx, claims)
+	result, err := d.UnprepareClaims(ctx, claims)
 	if err != nil {
-		return nil, fmt.Errorf("unprepare resource claims: %w", err)
+		return nil, grpcError(err, "unprepare resource claims")
 	}
 
 	resp := &drapbv1.NodeUnprepareResourcesResponse{Claims: map[string]*drapbv1.NodeUnprepareResourceResponse{}}
@@ -0,0 +1,201 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"os"
+	"path"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin/fakekubelet"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	"k8s.io/klog/v2/ktesting"
+)
+
+// testPlugin is a minimal DRAPlugin which records the calls that it receives.
+type testPlugin struct {
+	t *testing.T
+
+	mutex        sync.Mutex
+	prepareCalls int
+	prepared     []types.UID
+	unprepared   []types.UID
+	prepareErr   map[types.UID]error
+	unprepareErr map[types.UID]error
+	prepareDelay time.Duration
+}
+
+func (p *testPlugin) PrepareResourceClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) (map[types.UID]PrepareResult, error) {
+	time.Sleep(p.prepareDelay)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.prepareCalls++
+	result := make(map[types.UID]PrepareResult, len(claims))
+	for _, claim := range claims {
+		p.prepared = append(p.prepared, claim.UID)
+		result[claim.UID] = PrepareResult{Err: p.prepareErr[claim.UID]}
+	}
+	return result, nil
+}
+
+func (p *testPlugin) getPrepared() []types.UID {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return slices.Clone(p.prepared)
+}
+
+func (p *testPlugin) getUnprepared() []types.UID {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return slices.Clone(p.unprepared)
+}
+
+func (p *testPlugin) UnprepareResourceClaims(ctx context.Context, claims []NamespacedObject) (map[types.UID]error, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	result := make(map[types.UID]error, len(claims))
+	for _, claim := range claims {
+		p.unprepared = append(p.unprepared, claim.UID)
+		result[claim.UID] = p.unprepareErr[claim.UID]
+	}
+	return result, nil
+}
+
+func (p *testPlugin) HandleError(ctx context.Context, err error, msg string) {
+	p.t.Errorf("unexpected background error: %s: %v", msg, err)
+}
+
+func TestStartWithResources(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := fake.NewClientset()
+	tempDir := t.TempDir()
+	resources := resourceslice.DriverResources{
+		Pools: map[string]resourceslice.Pool{
+			"pool": {
+				Slices: []resourceslice.Slice{{Devices: []resourceapi.Device{{Name: "dev-0"}}}},
+			},
+		},
+	}
+
+	helper, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(kubeClient),
+		NodeName("worker"),
+		NodeUID("worker-uid"),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Resources(resources),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		slices, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+		require.NoError(t, err, "list slices")
+		require.Len(t, slices.Items, 1)
+		slice := slices.Items[0]
+		assert.Equal(t, "driver.example.com", slice.Spec.Driver)
+		assert.Equal(t, "worker", *slice.Spec.NodeName)
+		assert.Equal(t, "pool", slice.Spec.Pool.Name)
+		assert.Len(t, slice.Spec.Devices, 1)
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestStartWithResourcesNoNodeName(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+
+	_, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Resources(resourceslice.DriverResources{}),
+	)
+	require.ErrorContains(t, err, "no NodeName was set to publish resources")
+}
+
+func TestSingleSocket(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	registrarDir := t.TempDir()
+	dataDir := t.TempDir()
+	driverName := "driver.example.com"
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status: resourceapi.ResourceClaimStatus{
+			Allocation: &resourceapi.AllocationResult{
+				Devices: resourceapi.DeviceAllocationResult{
+					Results: []resourceapi.DeviceRequestAllocationResult{
+						{Request: "req", Driver: driverName, Pool: "pool", Device: "dev-0"},
+					},
+				},
+			},
+		},
+	}
+	plugin := &testPlugin{t: t}
+
+	helper, err := Start(ctx, plugin,
+		DriverName(driverName),
+		KubeClient(fake.NewClientset(claim)),
+		PluginDataDirectoryPath(dataDir),
+		RegistrarDirectoryPath(registrarDir),
+		SingleSocket(true),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	registrarSocket := path.Join(registrarDir, driverName+"-reg.sock")
+	kubelet, err := fakekubelet.Register(ctx, registrarSocket)
+	require.NoError(t, err, "register")
+	defer kubelet.Close()
+	assert.Equal(t, registrarSocket, kubelet.PluginInfo().Endpoint, "DRA endpoint")
+
+	resp, err := kubelet.NodePrepareResources(ctx, claim)
+	require.NoError(t, err, "prepare")
+	assert.Empty(t, resp.Claims[string(claim.UID)].Error, "claim error")
+	assert.Equal(t, []types.UID{claim.UID}, plugin.getPrepared(), "prepared claims")
+
+	entries, err := os.ReadDir(dataDir)
+	require.NoError(t, err, "read plugin data directory")
+	assert.Empty(t, entries, "plugin data directory")
+}
+
+func TestSingleSocketWithoutDRAService(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+
+	_, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		DRAService(false),
+		SingleSocket(true),
+	)
+	require.ErrorContains(t, err, "single socket mode requires both the registration and the DRA service")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivertesting
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/dynamic-resource-allocation/resourceclaim"
+	"k8s.io/klog/v2"
+)
+
+// CDIClass is the CDI class used by [Driver] for the CDI device IDs
+// of prepared devices. The names are generated with
+// [resourceclaim.CDIDeviceName] with the driver name as vendor.
+const CDIClass = "device"
+
+// Driver implements [kubeletplugin.DRAPlugin] on top of [Hardware].
+// It prepares the devices allocated for a claim by marking them as used
+// in the hardware and remembers them for unpreparing. Errors reported
+// by the kubeletplugin helper in the background are recorded.
+type Driver struct {
+	driverName string
+	hardware   *Hardware
+
+	mutex    sync.Mutex
+	prepared map[types.UID][]DeviceRef
+	errors   []error
+}
+
+var _ kubeletplugin.DRAPlugin = &Driver{}
+
+// NewDriver creates a driver for the hardware.
+func NewDriver(driverName string, hardware *Hardware) *Driver {
+	return &Driver{
+		driverName: driverName,
+		hardware:   hardware,
+		prepared:   make(map[types.UID][]DeviceRef),
+	}
+}
+
+// PrepareResourceClaims implements [kubeletplugin.DRAPlugin.PrepareResourceClaims].
+// Preparing a claim fails if any of its devices cannot be prepared. The
+// devices which were prepared before the failure remain in use until the
+// claim gets unprepared, like they would with real hardware.
+func (d *Driver) PrepareResourceClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) (map[types.UID]kubeletplugin.PrepareResult, error) {
+	logger := klog.FromContext(ctx)
+	result := make(map[types.UID]kubeletplugin.PrepareResult, len(claims))
+	for _, claim := range claims {
+		b := kubeletplugin.NewPrepareResultBuilder(d.driverName, claim)
+		var errs []error
+		for _, device := range claim.Status.Allocation.Devices.Results {
+			if device.Driver != d.driverName {
+				continue
+			}
+			ref := DeviceRef{Pool: device.Pool, Device: device.Device}
+			if err := d.hardware.prepare(claim.UID, ref); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			d.addPrepared(claim.UID, ref)
+			b.AddDevice(device, resourceclaim.CDIDeviceName(CDIClass, claim.UID, device))
+		}
+		if len(errs) > 0 {
+			result[claim.UID] = kubeletplugin.PrepareResult{Err: errors.Join(errs...)}
+			continue
+		}
+		result[claim.UID] = b.Build()
+		logger.V(3).Info("Prepared ResourceClaim", "claim", klog.KObj(claim))
+	}
+	return result, nil
+}
+
+func (d *Driver) addPrepared(claimUID types.UID, ref DeviceRef) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if !slices.Contains(d.prepared[claimUID], ref) {
+		d.prepared[claimUID] = append(d.prepared[claimUID], ref)
+	}
+}
+
+// UnprepareResourceClaims implements [kubeletplugin.DRAPlugin.UnprepareResourceClaims].
+// Devices which cannot be released stay in use and get released when
+// unpreparing the claim is tried again.
+func (d *Driver) UnprepareResourceClaims(ctx context.Context, claims []kubeletplugin.NamespacedObject) (map[types.UID]error, error) {
+	logger := klog.FromContext(ctx)
+	result := make(map[types.UID]error, len(claims))
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, claim := range claims {
+		var errs []error
+		var remaining []DeviceRef
+		for _, ref := range d.prepared[claim.UID] {
+			if err := d.hardware.release(claim.UID, ref); err != nil {
+				errs = append(errs, err)
+				remaining = append(remaining, ref)
+			}
+		}
+		if len(remaining) > 0 {
+			d.prepared[claim.UID] = remaining
+		} else {
+			delete(d.prepared, claim.UID)
+		}
+		result[claim.UID] = errors.Join(errs...)
+		if result[claim.UID] == nil {
+			logger.V(3).Info("Unprepared ResourceClaim", "claim", claim)
+		}
+	}
+	return result, nil
+}
+
+// HandleError implements [kubeletplugin.DRAPlugin.HandleError] by recording
+// the error. All errors are treated as recoverable.
+func (d *Driver) HandleError(ctx context.Context, err error, msg string) {
+	klog.FromContext(ctx).Error(err, msg)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.errors = append(d.errors, err)
+}
+
+// Errors returns all errors passed to HandleError so far.
+func (d *Driver) Errors() []error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return slices.Clone(d.errors)
+}
+
+// Prepared returns the devices which are currently prepared for the claim.
+func (d *Driver) Prepared(claimUID types.UID) []DeviceRef {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return slices.Clone(d.prepared[claimUID])
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivertesting
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	"k8s.io/utils/ptr"
+)
+
+// DeviceRef identifies a device of the fake hardware.
+type DeviceRef struct {
+	Pool   string
+	Device string
+}
+
+func (r DeviceRef) String() string {
+	return r.Pool + "/" + r.Device
+}
+
+// Hardware is a scripted hardware backend. Tests add and remove devices
+// and inject failures while the driver is running. It keeps track of
+// which claims use which devices, so tests can check that devices are
+// released again.
+//
+// Hardware is safe for concurrent use. The zero value is not usable,
+// use [NewHardware].
+type Hardware struct {
+	mutex          sync.Mutex
+	pools          map[string][]resourceapi.Device
+	prepareErrs    map[DeviceRef]error
+	unprepareErrs  map[DeviceRef]error
+	users          map[DeviceRef]map[types.UID]bool
+	changeHandlers []func()
+}
+
+// NewHardware creates a backend without devices.
+func NewHardware() *Hardware {
+	return &Hardware{
+		pools:         make(map[string][]resourceapi.Device),
+		prepareErrs:   make(map[DeviceRef]error),
+		unprepareErrs: make(map[DeviceRef]error),
+		users:         make(map[DeviceRef]map[types.UID]bool),
+	}
+}
+
+// AddDevices adds devices to the pool, creating the pool if needed.
+// A device with the same name as an existing one replaces that device.
+func (h *Hardware) AddDevices(pool string, devices ...resourceapi.Device) {
+	h.mutex.Lock()
+	defer h.changed()
+	defer h.mutex.Unlock()
+	for _, device := range devices {
+		index := slices.IndexFunc(h.pools[pool], func(existing resourceapi.Device) bool { return existing.Name == device.Name })
+		if index >= 0 {
+			h.pools[pool][index] = *device.DeepCopy()
+		} else {
+			h.pools[pool] = append(h.pools[pool], *device.DeepCopy())
+		}
+	}
+}
+
+// RemoveDevices removes devices from the pool. The pool is removed
+// together with its last device. Devices which are in use can be removed,
+// which simulates a device failing or getting unplugged.
+func (h *Hardware) RemoveDevices(pool string, names ...string) {
+	h.mutex.Lock()
+	defer h.changed()
+	defer h.mutex.Unlock()
+	h.pools[pool] = slices.DeleteFunc(h.pools[pool], func(device resourceapi.Device) bool { return slices.Contains(names, device.Name) })
+	if len(h.pools[pool]) == 0 {
+		delete(h.pools, pool)
+	}
+}
+
+// SetPrepareError makes preparing the device fail with the error until
+// it gets cleared again by passing nil.
+func (h *Hardware) SetPrepareError(ref DeviceRef, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	setOrDelete(h.prepareErrs, ref, err)
+}
+
+// SetUnprepareError makes releasing the device fail with the error until
+// it gets cleared again by passing nil. The device remains in use while
+// releasing it fails.
+func (h *Hardware) SetUnprepareError(ref DeviceRef, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	setOrDelete(h.unprepareErrs, ref, err)
+}
+
+func setOrDelete(errs map[DeviceRef]error, ref DeviceRef, err error) {
+	if err == nil {
+		delete(errs, ref)
+		return
+	}
+	errs[ref] = err
+}
+
+// Users returns the UIDs of all claims for which the device is prepared,
+// in sorted order.
+func (h *Hardware) Users(ref DeviceRef) []types.UID {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return slices.Sorted(maps.Keys(h.users[ref]))
+}
+
+// InUse returns all devices which are prepared for at least one claim.
+func (h *Hardware) InUse() []DeviceRef {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return slices.SortedFunc(maps.Keys(h.users), func(a, b DeviceRef) int {
+		return cmp.Or(strings.Compare(a.Pool, b.Pool), strings.Compare(a.Device, b.Device))
+	})
+}
+
+// Resources describes the current devices in the format expected by
+// [resourceslice.Controller], with one slice per pool.
+func (h *Hardware) Resources() resourceslice.DriverResources {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	resources := resourceslice.DriverResources{
+		Pools: make(map[string]resourceslice.Pool, len(h.pools)),
+	}
+	for name, devices := range h.pools {
+		slice := resourceslice.Slice{}
+		for _, device := range devices {
+			slice.Devices = append(slice.Devices, *device.DeepCopy())
+		}
+		resources.Pools[name] = resourceslice.Pool{
+			Slices: []resourceslice.Slice{slice},
+		}
+	}
+	return resources
+}
+
+// onChange registers a callback which gets invoked after each
+// modification of the devices.
+func (h *Hardware) onChange(handler func()) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.changeHandlers = append(h.changeHandlers, handler)
+}
+
+// changed must be called without holding the mutex, because
+// handlers typically call back into the Hardware.
+func (h *Hardware) changed() {
+	h.mutex.Lock()
+	handlers := slices.Clone(h.changeHandlers)
+	h.mutex.Unlock()
+	for _, handler := range handlers {
+		handler()
+	}
+}
+
+// prepare marks the device as used by the claim. Preparing the same
+// device again for the same claim is not an error.
+func (h *Hardware) prepare(claimUID types.UID, ref DeviceRef) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if err := h.prepareErrs[ref]; err != nil {
+		return fmt.Errorf("prepare device %s: %w", ref, err)
+	}
+	index := slices.IndexFunc(h.pools[ref.Pool], func(device resourceapi.Device) bool { return device.Name == ref.Device })
+	if index < 0 {
+		return fmt.Errorf("prepare device %s: device not found", ref)
+	}
+	users := h.users[ref]
+	if users[claimUID] {
+		return nil
+	}
+	if len(users) > 0 && !ptr.Deref(h.pools[ref.Pool][index].AllowMultipleAllocations, false) {
+		return fmt.Errorf("prepare device %s: already in use by ResourceClaim with UID %s", ref, slices.Sorted(maps.Keys(users))[0])
+	}
+	if users == nil {
+		users = make(map[types.UID]bool)
+		h.users[ref] = users
+	}
+	users[claimUID] = true
+	return nil
+}
+
+// release marks the device as no longer used by the claim. Releasing
+// a device which is not used by the claim is not an error.
+func (h *Hardware) release(claimUID types.UID, ref DeviceRef) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if err := h.unprepareErrs[ref]; err != nil {
+		return fmt.Errorf("release device %s: %w", ref, err)
+	}
+	delete(h.users[ref], claimUID)
+	if len(h.users[ref]) == 0 {
+		delete(h.users, ref)
+	}
+	return nil
+}
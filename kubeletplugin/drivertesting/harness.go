@@ -0,0 +1,329 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drivertesting runs a complete DRA driver against a scripted
+// fake hardware backend, for conformance-style tests of drivers and
+// control plane components which must run in CI without real devices.
+//
+// A [Harness] combines:
+//   - a [Hardware] backend where tests add and remove devices
+//     and inject failures,
+//   - a [Driver] which implements [kubeletplugin.DRAPlugin] on top of it,
+//   - the [kubeletplugin.Helper], which publishes the devices as
+//     ResourceSlices through the [resourceslice.Controller] whenever
+//     the hardware changes,
+//   - a [fakekubelet.Kubelet] which registers the driver and
+//     prepares and unprepares claims through the gRPC API.
+//
+// The harness works with the fake clientset from
+// [k8s.io/client-go/kubernetes/fake] and with a client for a real API
+// server, for example one started by envtest.
+package drivertesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin/fakekubelet"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// Options configure a [Harness]. Only Directory is required.
+type Options struct {
+	// DriverName is the name of the fake driver.
+	// The default is "driver.example.com".
+	DriverName string
+
+	// NodeName is the node on which the driver runs.
+	// The Node object gets created if it does not exist.
+	// The default is "worker".
+	NodeName string
+
+	// KubeClient is used by the driver and by the harness.
+	// The default is [NewFakeClient]. A fake clientset created
+	// differently must support GenerateName for ResourceSlices.
+	KubeClient kubernetes.Interface
+
+	// Hardware is the backend of the driver. The default is
+	// a new backend without devices.
+	Hardware *Hardware
+
+	// Directory is used for the registration and DRA service sockets.
+	// The path must be short enough for Unix domain sockets,
+	// which is usually the case for [testing.T.TempDir].
+	Directory string
+}
+
+// Harness runs one driver instance. The fields must not be modified.
+type Harness struct {
+	DriverName string
+	NodeName   string
+	Client     kubernetes.Interface
+	Hardware   *Hardware
+	Driver     *Driver
+	Helper     *kubeletplugin.Helper
+	Kubelet    *fakekubelet.Kubelet
+}
+
+// Start creates the Node object if needed, starts the driver, publishes
+// the current devices of the hardware and registers the driver with the
+// fake kubelet. The caller must call [Harness.Stop] to free resources.
+func Start(ctx context.Context, options Options) (finalH *Harness, finalErr error) {
+	if options.Directory == "" {
+		return nil, errors.New("a directory for the sockets is required")
+	}
+	h := &Harness{
+		DriverName: options.DriverName,
+		NodeName:   options.NodeName,
+		Client:     options.KubeClient,
+		Hardware:   options.Hardware,
+	}
+	if h.DriverName == "" {
+		h.DriverName = "driver.example.com"
+	}
+	if h.NodeName == "" {
+		h.NodeName = "worker"
+	}
+	if h.Client == nil {
+		h.Client = NewFakeClient()
+	}
+	if h.Hardware == nil {
+		h.Hardware = NewHardware()
+	}
+	h.Driver = NewDriver(h.DriverName, h.Hardware)
+	defer func() {
+		if finalErr != nil {
+			h.Stop()
+		}
+	}()
+
+	node, err := h.ensureNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Helper, err = kubeletplugin.Start(ctx, h.Driver,
+		kubeletplugin.DriverName(h.DriverName),
+		kubeletplugin.KubeClient(h.Client),
+		kubeletplugin.NodeName(h.NodeName),
+		kubeletplugin.NodeUID(node.UID),
+		kubeletplugin.PluginDataDirectoryPath(options.Directory),
+		kubeletplugin.RegistrarDirectoryPath(options.Directory),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("start driver: %w", err)
+	}
+
+	// Publishing is asynchronous, PublishResources only fails when
+	// the helper is misconfigured.
+	publish := func() {
+		if err := h.Helper.PublishResources(ctx, h.Hardware.Resources()); err != nil {
+			h.Driver.HandleError(ctx, err, "Publishing resources failed")
+		}
+	}
+	h.Hardware.onChange(publish)
+	publish()
+
+	h.Kubelet, err = fakekubelet.Register(ctx, path.Join(options.Directory, h.DriverName+"-reg.sock"))
+	if err != nil {
+		return nil, fmt.Errorf("register driver: %w", err)
+	}
+	klog.FromContext(ctx).V(3).Info("Started fake driver", "driverName", h.DriverName, "node", h.NodeName)
+	return h, nil
+}
+
+// NewFakeClient returns a fake clientset with the given objects. Unlike
+// [fake.NewClientset], it generates names for objects which only have
+// GenerateName set. The ResourceSlice controller depends on that.
+func NewFakeClient(objects ...runtime.Object) *fake.Clientset {
+	client := fake.NewClientset(objects...)
+	var mutex sync.Mutex
+	var counter int
+	client.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := meta.Accessor(action.(k8stesting.CreateAction).GetObject())
+		if err != nil {
+			return false, nil, err
+		}
+		if obj.GetName() == "" && obj.GetGenerateName() != "" {
+			mutex.Lock()
+			defer mutex.Unlock()
+			counter++
+			obj.SetName(fmt.Sprintf("%s%d", obj.GetGenerateName(), counter))
+		}
+		return false, nil, nil
+	})
+	return client
+}
+
+func (h *Harness) ensureNode(ctx context.Context) (*v1.Node, error) {
+	node, err := h.Client.CoreV1().Nodes().Get(ctx, h.NodeName, metav1.GetOptions{})
+	if err == nil {
+		return node, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("get Node %s: %w", h.NodeName, err)
+	}
+	// The fake clientset does not set a UID, a real API server replaces it.
+	node = &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: h.NodeName, UID: uuid.NewUUID()}}
+	node, err = h.Client.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("create Node %s: %w", h.NodeName, err)
+	}
+	return node, nil
+}
+
+// Stop disconnects the fake kubelet and stops the driver. The
+// ResourceSlices and the Node object are left in place.
+func (h *Harness) Stop() {
+	if h.Kubelet != nil {
+		h.Kubelet.Close()
+	}
+	if h.Helper != nil {
+		h.Helper.Stop()
+	}
+}
+
+// ResourceSlices returns the ResourceSlices which are currently
+// published for the driver on the node.
+func (h *Harness) ResourceSlices(ctx context.Context) ([]resourceapi.ResourceSlice, error) {
+	list, err := h.Client.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list ResourceSlices: %w", err)
+	}
+	return slices.DeleteFunc(list.Items, func(slice resourceapi.ResourceSlice) bool {
+		return slice.Spec.Driver != h.DriverName || ptr.Deref(slice.Spec.NodeName, "") != h.NodeName
+	}), nil
+}
+
+// WaitForResourceSlices waits until the published ResourceSlices
+// contain exactly the devices of the hardware and returns them.
+// It gives up when the context gets canceled.
+func (h *Harness) WaitForResourceSlices(ctx context.Context) ([]resourceapi.ResourceSlice, error) {
+	var result []resourceapi.ResourceSlice
+	err := wait.PollUntilContextCancel(ctx, 10*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+		var err error
+		result, err = h.ResourceSlices(ctx)
+		if err != nil {
+			return false, err
+		}
+		return publishedDevices(result).Equal(hardwareDevices(h.Hardware.Resources())), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wait for ResourceSlices of driver %s: %w", h.DriverName, err)
+	}
+	return result, nil
+}
+
+func publishedDevices(slices []resourceapi.ResourceSlice) sets.Set[DeviceRef] {
+	generations := make(map[string]int64)
+	for _, slice := range slices {
+		generations[slice.Spec.Pool.Name] = max(generations[slice.Spec.Pool.Name], slice.Spec.Pool.Generation)
+	}
+	devices := sets.New[DeviceRef]()
+	for _, slice := range slices {
+		if slice.Spec.Pool.Generation < generations[slice.Spec.Pool.Name] {
+			continue
+		}
+		for _, device := range slice.Spec.Devices {
+			devices.Insert(DeviceRef{Pool: slice.Spec.Pool.Name, Device: device.Name})
+		}
+	}
+	return devices
+}
+
+func hardwareDevices(resources resourceslice.DriverResources) sets.Set[DeviceRef] {
+	devices := sets.New[DeviceRef]()
+	for poolName, pool := range resources.Pools {
+		for _, slice := range pool.Slices {
+			for _, device := range slice.Devices {
+				devices.Insert(DeviceRef{Pool: poolName, Device: device.Name})
+			}
+		}
+	}
+	return devices
+}
+
+// CreateAllocatedClaim creates a ResourceClaim with one request for the
+// given devices of the driver and marks it as allocated on the node, the
+// same way as the scheduler would. The device class is not checked.
+func (h *Harness) CreateAllocatedClaim(ctx context.Context, namespace, name string, devices ...DeviceRef) (*resourceapi.ResourceClaim, error) {
+	const requestName = "req"
+	claim := &resourceapi.ResourceClaim{
+		// The fake clientset does not set a UID, a real API server replaces it.
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: uuid.NewUUID()},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: requestName,
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: h.DriverName,
+						AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+						Count:           int64(len(devices)),
+					},
+				}},
+			},
+		},
+	}
+	claim, err := h.Client.ResourceV1().ResourceClaims(namespace).Create(ctx, claim, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("create ResourceClaim %s/%s: %w", namespace, name, err)
+	}
+	allocation := &resourceapi.AllocationResult{
+		NodeSelector: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchFields: []v1.NodeSelectorRequirement{{
+					Key:      "metadata.name",
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{h.NodeName},
+				}},
+			}},
+		},
+	}
+	for _, device := range devices {
+		allocation.Devices.Results = append(allocation.Devices.Results, resourceapi.DeviceRequestAllocationResult{
+			Request: requestName,
+			Driver:  h.DriverName,
+			Pool:    device.Pool,
+			Device:  device.Device,
+		})
+	}
+	claim.Status.Allocation = allocation
+	claim, err = h.Client.ResourceV1().ResourceClaims(namespace).UpdateStatus(ctx, claim, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("allocate ResourceClaim %s/%s: %w", namespace, name, err)
+	}
+	return claim, nil
+}
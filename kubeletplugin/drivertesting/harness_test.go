@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivertesting
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/resourceclaim"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func startHarness(t *testing.T, ctx context.Context, hardware *Hardware) *Harness {
+	t.Helper()
+	h, err := Start(ctx, Options{Hardware: hardware, Directory: t.TempDir()})
+	require.NoError(t, err, "start harness")
+	t.Cleanup(h.Stop)
+	return h
+}
+
+func waitForSlices(t *testing.T, ctx context.Context, h *Harness) []resourceapi.ResourceSlice {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	published, err := h.WaitForResourceSlices(ctx)
+	require.NoError(t, err, "wait for ResourceSlices")
+	return published
+}
+
+func TestPublish(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	hardware := NewHardware()
+	hardware.AddDevices("pool-a", resourceapi.Device{Name: "dev-0"}, resourceapi.Device{Name: "dev-1"})
+	h := startHarness(t, ctx, hardware)
+
+	published := waitForSlices(t, ctx, h)
+	require.Len(t, published, 1)
+	assert.Equal(t, "pool-a", published[0].Spec.Pool.Name)
+	assert.Equal(t, "worker", ptr.Deref(published[0].Spec.NodeName, ""))
+	assert.Len(t, published[0].Spec.Devices, 2)
+
+	hardware.AddDevices("pool-b", resourceapi.Device{Name: "dev-0"})
+	hardware.RemoveDevices("pool-a", "dev-1")
+	published = waitForSlices(t, ctx, h)
+	assert.Equal(t, []DeviceRef{{Pool: "pool-a", Device: "dev-0"}, {Pool: "pool-b", Device: "dev-0"}}, sortedDevices(published))
+	assert.Empty(t, h.Driver.Errors())
+}
+
+func sortedDevices(published []resourceapi.ResourceSlice) []DeviceRef {
+	devices := publishedDevices(published).UnsortedList()
+	slices.SortFunc(devices, func(a, b DeviceRef) int {
+		return cmp.Or(strings.Compare(a.Pool, b.Pool), strings.Compare(a.Device, b.Device))
+	})
+	return devices
+}
+
+func TestPrepareUnprepare(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	hardware := NewHardware()
+	hardware.AddDevices("pool", resourceapi.Device{Name: "dev-0"}, resourceapi.Device{Name: "dev-1"})
+	h := startHarness(t, ctx, hardware)
+	dev0 := DeviceRef{Pool: "pool", Device: "dev-0"}
+	dev1 := DeviceRef{Pool: "pool", Device: "dev-1"}
+
+	claim, err := h.CreateAllocatedClaim(ctx, "default", "claim", dev0, dev1)
+	require.NoError(t, err, "create claim")
+	resp, err := h.Kubelet.NodePrepareResources(ctx, claim)
+	require.NoError(t, err, "prepare")
+	assert.Empty(t, resp.Claims[string(claim.UID)].Error)
+	assert.Equal(t, []DeviceRef{dev0, dev1}, hardware.InUse())
+	assert.Equal(t, []types.UID{claim.UID}, hardware.Users(dev0))
+	devices := h.Kubelet.Checkpoint()[claim.UID].Devices
+	require.Len(t, devices, 2)
+	assert.Equal(t, []string{resourceclaim.CDIDeviceName(CDIClass, claim.UID, claim.Status.Allocation.Devices.Results[0])}, devices[0].CDIDeviceIDs)
+
+	// Another claim cannot use the same device.
+	other, err := h.CreateAllocatedClaim(ctx, "default", "other", dev1)
+	require.NoError(t, err, "create other claim")
+	resp, err = h.Kubelet.NodePrepareResources(ctx, other)
+	require.NoError(t, err, "prepare other")
+	assert.Contains(t, resp.Claims[string(other.UID)].Error, "already in use")
+	assert.False(t, h.Kubelet.IsPrepared(other.UID), "other claim prepared")
+
+	hardware.SetUnprepareError(dev1, errors.New("fake release error"))
+	unprepareResp, err := h.Kubelet.NodeUnprepareResources(ctx, claim)
+	require.NoError(t, err, "unprepare")
+	assert.Contains(t, unprepareResp.Claims[string(claim.UID)].Error, "fake release error")
+	assert.Equal(t, []DeviceRef{dev1}, hardware.InUse())
+	assert.Equal(t, []DeviceRef{dev1}, h.Driver.Prepared(claim.UID))
+
+	hardware.SetUnprepareError(dev1, nil)
+	unprepareResp, err = h.Kubelet.NodeUnprepareResources(ctx, claim)
+	require.NoError(t, err, "unprepare again")
+	assert.Empty(t, unprepareResp.Claims[string(claim.UID)].Error)
+	assert.Empty(t, hardware.InUse())
+	assert.Empty(t, h.Kubelet.Checkpoint())
+}
+
+func TestPrepareError(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	hardware := NewHardware()
+	hardware.AddDevices("pool", resourceapi.Device{Name: "dev-0"})
+	h := startHarness(t, ctx, hardware)
+	dev0 := DeviceRef{Pool: "pool", Device: "dev-0"}
+
+	claim, err := h.CreateAllocatedClaim(ctx, "default", "claim", dev0)
+	require.NoError(t, err, "create claim")
+	hardware.SetPrepareError(dev0, errors.New("fake prepare error"))
+	resp, err := h.Kubelet.NodePrepareResources(ctx, claim)
+	require.NoError(t, err, "prepare")
+	assert.Contains(t, resp.Claims[string(claim.UID)].Error, "fake prepare error")
+	assert.Empty(t, hardware.InUse())
+
+	hardware.SetPrepareError(dev0, nil)
+	resp, err = h.Kubelet.NodePrepareResources(ctx, claim)
+	require.NoError(t, err, "prepare again")
+	assert.Empty(t, resp.Claims[string(claim.UID)].Error)
+	assert.True(t, h.Kubelet.IsPrepared(claim.UID), "claim prepared")
+
+	// A device which disappears cannot be prepared for new claims.
+	hardware.RemoveDevices("pool", "dev-0")
+	waitForSlices(t, ctx, h)
+	other, err := h.CreateAllocatedClaim(ctx, "default", "other", dev0)
+	require.NoError(t, err, "create other claim")
+	resp, err = h.Kubelet.NodePrepareResources(ctx, other)
+	require.NoError(t, err, "prepare other")
+	assert.Contains(t, resp.Claims[string(other.UID)].Error, "device not found")
+}
+
+func TestSharedDevice(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	hardware := NewHardware()
+	hardware.AddDevices("pool", resourceapi.Device{Name: "dev-0", AllowMultipleAllocations: ptr.To(true)})
+	h := startHarness(t, ctx, hardware)
+	dev0 := DeviceRef{Pool: "pool", Device: "dev-0"}
+
+	var uids []types.UID
+	for _, name := range []string{"claim-a", "claim-b"} {
+		claim, err := h.CreateAllocatedClaim(ctx, "default", name, dev0)
+		require.NoError(t, err, "create claim")
+		resp, err := h.Kubelet.NodePrepareResources(ctx, claim)
+		require.NoError(t, err, "prepare")
+		assert.Empty(t, resp.Claims[string(claim.UID)].Error)
+		uids = append(uids, claim.UID)
+	}
+	assert.ElementsMatch(t, uids, hardware.Users(dev0))
+}
@@ -23,6 +23,8 @@ import (
 	"net"
 	"os"
 	"path"
+
+	"k8s.io/klog/v2"
 )
 
 // endpoint defines where and how to listen for incoming connections.
@@ -30,9 +32,14 @@ import (
 //
 // If the listen function is not set, a new listener for a Unix domain socket gets
 // created at the path.
+//
+// Permissions are only applied to sockets created by the endpoint itself.
+// The peer authorizer, if set, is used for all listeners.
 type endpoint struct {
-	dir, file  string
-	listenFunc func(ctx context.Context, socketpath string) (net.Listener, error)
+	dir, file     string
+	listenFunc    func(ctx context.Context, socketpath string) (net.Listener, error)
+	permissions   os.FileMode
+	authorizePeer PeerAuthorizer
 }
 
 func (e endpoint) path() string {
@@ -40,6 +47,14 @@ func (e endpoint) path() string {
 }
 
 func (e endpoint) listen(ctx context.Context) (net.Listener, error) {
+	listener, err := e.listenSocket(ctx)
+	if err != nil || listener == nil || e.authorizePeer == nil {
+		return listener, err
+	}
+	return &authorizingListener{Listener: listener, logger: klog.FromContext(ctx), authorize: e.authorizePeer}, nil
+}
+
+func (e endpoint) listenSocket(ctx context.Context) (net.Listener, error) {
 	socketpath := e.path()
 
 	if e.listenFunc != nil {
@@ -58,6 +73,12 @@ func (e endpoint) listen(ctx context.Context) (net.Listener, error) {
 		}
 		return nil, err
 	}
+	if e.permissions != 0 {
+		if err := os.Chmod(socketpath, e.permissions); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("change permissions of Unix domain socket: %w", errors.Join(err, e.removeSocket()))
+		}
+	}
 	return &unixListener{Listener: listener, endpoint: e}, nil
 }
 
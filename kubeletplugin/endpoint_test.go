@@ -18,7 +18,9 @@ package kubeletplugin
 
 import (
 	"context"
+	"io"
 	"net"
+	"os"
 	"path"
 	"testing"
 
@@ -53,3 +55,72 @@ func TestEndpointListener(t *testing.T) {
 	assert.NoFileExists(t, path.Join(tempDir, socketname))
 	assert.Nil(t, listener)
 }
+
+func TestEndpointPermissions(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	socketname := "test.sock"
+	e := endpoint{dir: tempDir, file: socketname, permissions: 0600}
+	listener, err := e.listen(ctx)
+	require.NoError(t, err, "listen")
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	info, err := os.Stat(path.Join(tempDir, socketname))
+	require.NoError(t, err, "stat socket")
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestEndpointPeerAuthorization(t *testing.T) {
+	if !peerCredentialsSupported {
+		t.Skip("peer credentials not supported")
+	}
+	uid := uint32(os.Getuid())
+
+	t.Run("allowed", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		e := endpoint{dir: t.TempDir(), file: "test.sock", authorizePeer: AllowUIDs(uid)}
+		listener, err := e.listen(ctx)
+		require.NoError(t, err, "listen")
+		defer func() {
+			_ = listener.Close()
+		}()
+
+		clientConn, err := net.Dial("unix", e.path())
+		require.NoError(t, err, "dial")
+		defer func() {
+			_ = clientConn.Close()
+		}()
+		serverConn, err := listener.Accept()
+		require.NoError(t, err, "accept")
+		_ = serverConn.Close()
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		e := endpoint{dir: t.TempDir(), file: "test.sock", authorizePeer: AllowUIDs(uid + 1)}
+		listener, err := e.listen(ctx)
+		require.NoError(t, err, "listen")
+
+		acceptErr := make(chan error)
+		go func() {
+			conn, err := listener.Accept()
+			if conn != nil {
+				_ = conn.Close()
+			}
+			acceptErr <- err
+		}()
+
+		clientConn, err := net.Dial("unix", e.path())
+		require.NoError(t, err, "dial")
+		defer func() {
+			_ = clientConn.Close()
+		}()
+		_, err = clientConn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, io.EOF, "connection should have been closed by server")
+
+		require.NoError(t, listener.Close(), "close")
+		require.Error(t, <-acceptErr, "accept after close")
+	})
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakekubelet impersonates the kubelet for unit tests of DRA drivers
+// which use the kubeletplugin helper package.
+//
+// It performs the plugin registration handshake through the registration
+// socket, connects to the DRA service of the plugin and then issues
+// NodePrepareResources and NodeUnprepareResources calls. Like the real
+// kubelet, it keeps a checkpoint of all claims which were prepared
+// successfully. Tests can inspect that checkpoint.
+//
+// Nothing in this package depends on a real node, so a driver can be
+// tested end-to-end inside a normal "go test" invocation.
+package fakekubelet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1"
+	registerapi "k8s.io/kubelet/pkg/apis/pluginregistration/v1"
+)
+
+// Kubelet is connected to one DRA driver. It is safe for concurrent use.
+type Kubelet struct {
+	info           *registerapi.PluginInfo
+	registrarConn  *grpc.ClientConn
+	pluginConn     *grpc.ClientConn
+	pluginClient   drapbv1.DRAPluginClient
+	registerClient registerapi.RegistrationClient
+
+	mutex      sync.Mutex
+	checkpoint map[types.UID]PreparedClaim
+}
+
+// PreparedClaim is the checkpoint entry for one claim which
+// was prepared successfully.
+type PreparedClaim struct {
+	// Claim identifies the ResourceClaim.
+	Claim *drapbv1.Claim
+
+	// Devices is the response of the driver for the claim.
+	Devices []*drapbv1.Device
+}
+
+// Register connects to the registration socket, retrieves the plugin
+// information and confirms a successful registration, the same way
+// as the kubelet does it. Then it connects to the DRA service
+// advertised by the plugin.
+//
+// The plugin must support the v1 DRA gRPC API.
+//
+// The caller must call [Kubelet.Close] to free resources.
+func Register(ctx context.Context, registrarSocketPath string) (finalK *Kubelet, finalErr error) {
+	logger := klog.FromContext(ctx)
+	k := &Kubelet{
+		checkpoint: make(map[types.UID]PreparedClaim),
+	}
+	defer func() {
+		if finalErr != nil {
+			k.Close()
+		}
+	}()
+
+	var err error
+	k.registrarConn, err = dial(registrarSocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("connect to registrar: %w", err)
+	}
+	k.registerClient = registerapi.NewRegistrationClient(k.registrarConn)
+	k.info, err = k.registerClient.GetInfo(ctx, &registerapi.InfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("get plugin info: %w", err)
+	}
+	logger.V(3).Info("Received plugin info", "info", k.info)
+
+	var validationErr error
+	switch {
+	case k.info.Type != registerapi.DRAPlugin:
+		validationErr = fmt.Errorf("unexpected plugin type %q", k.info.Type)
+	case k.info.Name == "":
+		validationErr = errors.New("empty driver name")
+	case k.info.Endpoint == "":
+		validationErr = errors.New("empty DRA service endpoint")
+	case !slices.Contains(k.info.SupportedVersions, drapbv1.DRAPluginService):
+		validationErr = fmt.Errorf("%s not in supported services %q", drapbv1.DRAPluginService, k.info.SupportedVersions)
+	}
+	if validationErr != nil {
+		_, _ = k.registerClient.NotifyRegistrationStatus(ctx, &registerapi.RegistrationStatus{PluginRegistered: false, Error: validationErr.Error()})
+		return nil, fmt.Errorf("invalid plugin info: %w", validationErr)
+	}
+
+	k.pluginConn, err = dial(k.info.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("connect to DRA service: %w", err)
+	}
+	k.pluginClient = drapbv1.NewDRAPluginClient(k.pluginConn)
+
+	if _, err := k.registerClient.NotifyRegistrationStatus(ctx, &registerapi.RegistrationStatus{PluginRegistered: true}); err != nil {
+		return nil, fmt.Errorf("notify registration status: %w", err)
+	}
+	logger.V(3).Info("Registered plugin", "driverName", k.info.Name)
+
+	return k, nil
+}
+
+func dial(socketPath string) (*grpc.ClientConn, error) {
+	return grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// Close disconnects from the plugin. It is idempotent.
+func (k *Kubelet) Close() {
+	if k.pluginConn != nil {
+		_ = k.pluginConn.Close()
+		k.pluginConn = nil
+	}
+	if k.registrarConn != nil {
+		_ = k.registrarConn.Close()
+		k.registrarConn = nil
+	}
+}
+
+// PluginInfo returns the information provided by the plugin during registration.
+func (k *Kubelet) PluginInfo() *registerapi.PluginInfo {
+	return k.info
+}
+
+// DriverName returns the name of the DRA driver.
+func (k *Kubelet) DriverName() string {
+	return k.info.Name
+}
+
+// NodePrepareResources asks the driver to prepare the claims. It returns
+// the raw response. Claims which got prepared successfully are
+// added to the checkpoint.
+func (k *Kubelet) NodePrepareResources(ctx context.Context, claims ...*resourceapi.ResourceClaim) (*drapbv1.NodePrepareResourcesResponse, error) {
+	req := &drapbv1.NodePrepareResourcesRequest{}
+	for _, claim := range claims {
+		req.Claims = append(req.Claims, toClaim(claim))
+	}
+	resp, err := k.pluginClient.NodePrepareResources(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	for _, claim := range req.Claims {
+		claimResp, ok := resp.Claims[claim.UID]
+		if !ok || claimResp.Error != "" {
+			continue
+		}
+		k.checkpoint[types.UID(claim.UID)] = PreparedClaim{Claim: claim, Devices: claimResp.Devices}
+	}
+	return resp, nil
+}
+
+// NodeUnprepareResources asks the driver to unprepare the claims.
+// It returns the raw response. Claims which got unprepared
+// successfully are removed from the checkpoint.
+func (k *Kubelet) NodeUnprepareResources(ctx context.Context, claims ...*resourceapi.ResourceClaim) (*drapbv1.NodeUnprepareResourcesResponse, error) {
+	req := &drapbv1.NodeUnprepareResourcesRequest{}
+	for _, claim := range claims {
+		req.Claims = append(req.Claims, toClaim(claim))
+	}
+	resp, err := k.pluginClient.NodeUnprepareResources(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	for _, claim := range req.Claims {
+		claimResp, ok := resp.Claims[claim.UID]
+		if !ok || claimResp.Error != "" {
+			continue
+		}
+		delete(k.checkpoint, types.UID(claim.UID))
+	}
+	return resp, nil
+}
+
+// Checkpoint returns a copy of all claims which are currently prepared,
+// indexed by claim UID.
+func (k *Kubelet) Checkpoint() map[types.UID]PreparedClaim {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return maps.Clone(k.checkpoint)
+}
+
+// IsPrepared checks whether the claim is currently in the checkpoint.
+func (k *Kubelet) IsPrepared(uid types.UID) bool {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	_, ok := k.checkpoint[uid]
+	return ok
+}
+
+func toClaim(claim *resourceapi.ResourceClaim) *drapbv1.Claim {
+	return &drapbv1.Claim{
+		Namespace: claim.Namespace,
+		Name:      claim.Name,
+		UID:       string(claim.UID),
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakekubelet
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/klog/v2/ktesting"
+)
+
+const driverName = "driver.example.com"
+
+type testDriver struct {
+	failUnprepare bool
+}
+
+func (d *testDriver) PrepareResourceClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) (map[types.UID]kubeletplugin.PrepareResult, error) {
+	result := make(map[types.UID]kubeletplugin.PrepareResult, len(claims))
+	for _, claim := range claims {
+		b := kubeletplugin.NewPrepareResultBuilder(driverName, claim)
+		for _, device := range claim.Status.Allocation.Devices.Results {
+			b.AddDevice(device, "vendor.com/class="+string(claim.UID)+"-"+device.Device)
+		}
+		result[claim.UID] = b.Build()
+	}
+	return result, nil
+}
+
+func (d *testDriver) UnprepareResourceClaims(ctx context.Context, claims []kubeletplugin.NamespacedObject) (map[types.UID]error, error) {
+	result := make(map[types.UID]error, len(claims))
+	for _, claim := range claims {
+		if d.failUnprepare {
+			result[claim.UID] = errors.New("fake unprepare error")
+		} else {
+			result[claim.UID] = nil
+		}
+	}
+	return result, nil
+}
+
+func (d *testDriver) HandleError(ctx context.Context, err error, msg string) {}
+
+func TestFakeKubelet(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status: resourceapi.ResourceClaimStatus{
+			Allocation: &resourceapi.AllocationResult{
+				Devices: resourceapi.DeviceAllocationResult{
+					Results: []resourceapi.DeviceRequestAllocationResult{{
+						Request: "req", Driver: driverName, Pool: "pool", Device: "dev",
+					}},
+				},
+			},
+		},
+	}
+	kubeClient := fake.NewClientset(claim)
+	tempDir := t.TempDir()
+	driver := &testDriver{}
+
+	helper, err := kubeletplugin.Start(ctx, driver,
+		kubeletplugin.DriverName(driverName),
+		kubeletplugin.KubeClient(kubeClient),
+		kubeletplugin.PluginDataDirectoryPath(tempDir),
+		kubeletplugin.RegistrarDirectoryPath(tempDir),
+	)
+	require.NoError(t, err, "start plugin")
+	defer helper.Stop()
+
+	kubelet, err := Register(ctx, path.Join(tempDir, driverName+"-reg.sock"))
+	require.NoError(t, err, "register plugin")
+	defer kubelet.Close()
+	assert.Equal(t, driverName, kubelet.DriverName())
+	require.NotNil(t, helper.RegistrationStatus(), "registration status")
+	assert.True(t, helper.RegistrationStatus().PluginRegistered, "plugin registered")
+
+	resp, err := kubelet.NodePrepareResources(ctx, claim)
+	require.NoError(t, err, "prepare")
+	require.Contains(t, resp.Claims, string(claim.UID))
+	assert.Empty(t, resp.Claims[string(claim.UID)].Error)
+	require.True(t, kubelet.IsPrepared(claim.UID), "claim prepared")
+	checkpoint := kubelet.Checkpoint()
+	require.Len(t, checkpoint[claim.UID].Devices, 1)
+	assert.Equal(t, []string{"vendor.com/class=claim-uid-dev"}, checkpoint[claim.UID].Devices[0].CDIDeviceIDs)
+
+	driver.failUnprepare = true
+	resp2, err := kubelet.NodeUnprepareResources(ctx, claim)
+	require.NoError(t, err, "unprepare")
+	assert.Equal(t, "fake unprepare error", resp2.Claims[string(claim.UID)].Error)
+	assert.True(t, kubelet.IsPrepared(claim.UID), "claim still prepared after failed unprepare")
+
+	driver.failUnprepare = false
+	_, err = kubelet.NodeUnprepareResources(ctx, claim)
+	require.NoError(t, err, "unprepare")
+	assert.Empty(t, kubelet.Checkpoint(), "checkpoint after unprepare")
+}
+
+func TestFakeKubeletNoClaim(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+
+	helper, err := kubeletplugin.Start(ctx, &testDriver{},
+		kubeletplugin.DriverName(driverName),
+		kubeletplugin.KubeClient(fake.NewClientset()),
+		kubeletplugin.PluginDataDirectoryPath(tempDir),
+		kubeletplugin.RegistrarDirectoryPath(tempDir),
+	)
+	require.NoError(t, err, "start plugin")
+	defer helper.Stop()
+
+	kubelet, err := Register(ctx, path.Join(tempDir, driverName+"-reg.sock"))
+	require.NoError(t, err, "register plugin")
+	defer kubelet.Close()
+
+	claim := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"}}
+	_, err = kubelet.NodePrepareResources(ctx, claim)
+	require.ErrorContains(t, err, "NotFound")
+	assert.Empty(t, kubelet.Checkpoint())
+}
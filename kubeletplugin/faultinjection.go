@@ -0,0 +1,191 @@
+//go:build dra_fault_injection
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// FaultPoint identifies an operation into which faults can be injected.
+type FaultPoint string
+
+const (
+	// FaultPointRegistration is the GetInfo call of the kubelet
+	// during plugin registration.
+	FaultPointRegistration FaultPoint = faultPointRegistration
+	// FaultPointPrepare is [Helper.PrepareClaims], which also
+	// implements NodePrepareResources.
+	FaultPointPrepare FaultPoint = faultPointPrepare
+	// FaultPointUnprepare is [Helper.UnprepareClaims], which also
+	// implements NodeUnprepareResources.
+	FaultPointUnprepare FaultPoint = faultPointUnprepare
+)
+
+// Fault describes what happens at a fault point. The zero value
+// lets the operation proceed normally.
+type Fault struct {
+	// Delay is the time to wait before proceeding or failing.
+	// The wait ends early when the context of the operation
+	// gets canceled, which then fails.
+	Delay time.Duration
+
+	// Err, if non-nil, is returned instead of calling the DRAPlugin.
+	Err error
+}
+
+// FaultInjector decides about the fault for each call at a fault point.
+// It gets called concurrently.
+type FaultInjector interface {
+	Fault(ctx context.Context, point FaultPoint) Fault
+}
+
+// FaultInjectorFunc implements [FaultInjector] with a function.
+type FaultInjectorFunc func(ctx context.Context, point FaultPoint) Fault
+
+// Fault implements [FaultInjector.Fault].
+func (f FaultInjectorFunc) Fault(ctx context.Context, point FaultPoint) Fault {
+	return f(ctx, point)
+}
+
+// FaultInjection enables injecting failures and latency into plugin
+// registration, preparing and unpreparing of claims. This is meant for
+// tests which exercise the retry and error handling of the kubelet, for
+// example E2E tests or the CI of a DRA driver.
+//
+// Failed prepare and unprepare calls fail as a whole, without calling
+// the DRAPlugin. Only available when building with the
+// dra_fault_injection tag.
+func FaultInjection(injector FaultInjector) Option {
+	return func(o *options) error {
+		if injector == nil {
+			o.faultInjector = nil
+			return nil
+		}
+		o.faultInjector = func(ctx context.Context, point string) error {
+			fault := injector.Fault(ctx, FaultPoint(point))
+			if fault.Delay == 0 && fault.Err == nil {
+				return nil
+			}
+			klog.FromContext(ctx).V(2).Info("Injecting fault", "point", point, "delay", fault.Delay, "err", fault.Err)
+			if fault.Delay > 0 {
+				timer := time.NewTimer(fault.Delay)
+				defer timer.Stop()
+				select {
+				case <-ctx.Done():
+					return context.Cause(ctx)
+				case <-timer.C:
+				}
+			}
+			return fault.Err
+		}
+		return nil
+	}
+}
+
+// RandomFaults injects the same fault into a certain percentage
+// of the calls.
+type RandomFaults struct {
+	// Points are the fault points where faults get injected.
+	// All of them if empty.
+	Points []FaultPoint
+
+	// Probability is the chance for each call to get the fault,
+	// between 0 (never) and 1 (always).
+	Probability float64
+
+	// Inject is the fault that gets injected.
+	Inject Fault
+
+	// Rand is the source of randomness. A fixed seed makes
+	// the sequence of faults reproducible. The global
+	// random number generator is used if nil.
+	Rand *rand.Rand
+
+	mutex sync.Mutex
+}
+
+var _ FaultInjector = &RandomFaults{}
+
+// Fault implements [FaultInjector.Fault].
+func (r *RandomFaults) Fault(ctx context.Context, point FaultPoint) Fault {
+	if len(r.Points) > 0 && !slices.Contains(r.Points, point) {
+		return Fault{}
+	}
+	var value float64
+	if r.Rand != nil {
+		// rand.Rand is not safe for concurrent use.
+		r.mutex.Lock()
+		value = r.Rand.Float64()
+		r.mutex.Unlock()
+	} else {
+		value = rand.Float64()
+	}
+	if value >= r.Probability {
+		return Fault{}
+	}
+	return r.Inject
+}
+
+// ScriptedFaults injects faults in a pre-defined order. Each call at a
+// fault point consumes the next fault scripted for that point. Once
+// all of them are consumed, calls proceed normally.
+type ScriptedFaults struct {
+	mutex  sync.Mutex
+	script map[FaultPoint][]Fault
+}
+
+var _ FaultInjector = &ScriptedFaults{}
+
+// NewScriptedFaults returns an injector without any scripted faults.
+func NewScriptedFaults() *ScriptedFaults {
+	return &ScriptedFaults{script: make(map[FaultPoint][]Fault)}
+}
+
+// Add appends faults for the point. A zero Fault can be used to let
+// a call succeed before failing later ones.
+func (s *ScriptedFaults) Add(point FaultPoint, faults ...Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.script[point] = append(s.script[point], faults...)
+}
+
+// Pending returns the number of faults which have not been consumed yet.
+func (s *ScriptedFaults) Pending(point FaultPoint) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.script[point])
+}
+
+// Fault implements [FaultInjector.Fault].
+func (s *ScriptedFaults) Fault(ctx context.Context, point FaultPoint) Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	faults := s.script[point]
+	if len(faults) == 0 {
+		return Fault{}
+	}
+	s.script[point] = faults[1:]
+	return faults[0]
+}
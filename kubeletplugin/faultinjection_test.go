@@ -0,0 +1,127 @@
+//go:build dra_fault_injection
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+	registerapi "k8s.io/kubelet/pkg/apis/pluginregistration/v1"
+)
+
+func TestFaultInjection(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+	claimRef := NamespacedObject{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}
+	plugin := &testPlugin{t: t}
+	faults := NewScriptedFaults()
+	faults.Add(FaultPointPrepare, Fault{Err: errors.New("injected prepare error")}, Fault{})
+	faults.Add(FaultPointUnprepare, Fault{Delay: time.Hour})
+
+	helper, err := Start(ctx, plugin,
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		FaultInjection(faults),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	_, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.EqualError(t, err, "injected prepare error", "first prepare")
+	assert.Empty(t, plugin.getPrepared(), "prepared claims after injected error")
+
+	result, err := helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err, "second prepare")
+	assert.Equal(t, map[types.UID]PrepareResult{claim.UID: {}}, result, "second prepare result")
+	assert.Equal(t, 0, faults.Pending(FaultPointPrepare), "pending prepare faults")
+
+	// The delay ends when the caller gives up.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = helper.UnprepareClaims(timeoutCtx, []NamespacedObject{claimRef})
+	require.ErrorIs(t, err, context.DeadlineExceeded, "first unprepare")
+
+	// The script is used up.
+	_, err = helper.UnprepareClaims(ctx, []NamespacedObject{claimRef})
+	require.NoError(t, err, "second unprepare")
+}
+
+func TestFaultInjectionRegistration(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	o, err := newOptions(FaultInjection(FaultInjectorFunc(func(ctx context.Context, point FaultPoint) Fault {
+		return Fault{Err: errors.New("injected " + string(point) + " error")}
+	})))
+	require.NoError(t, err, "options")
+	interceptor := faultInterceptor(o.faultInjector)
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "response", nil
+	}
+
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: registerapi.Registration_GetInfo_FullMethodName}, handler)
+	require.EqualError(t, err, "injected Registration error", "GetInfo")
+
+	response, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: registerapi.Registration_NotifyRegistrationStatus_FullMethodName}, handler)
+	require.NoError(t, err, "NotifyRegistrationStatus")
+	assert.Equal(t, "response", response, "NotifyRegistrationStatus response")
+}
+
+func TestRandomFaults(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	fault := Fault{Err: errors.New("injected error")}
+
+	always := &RandomFaults{Points: []FaultPoint{FaultPointPrepare}, Probability: 1, Inject: fault}
+	assert.Equal(t, fault, always.Fault(ctx, FaultPointPrepare), "probability 1")
+	assert.Equal(t, Fault{}, always.Fault(ctx, FaultPointUnprepare), "other point")
+
+	never := &RandomFaults{Probability: 0, Inject: fault}
+	assert.Equal(t, Fault{}, never.Fault(ctx, FaultPointPrepare), "probability 0")
+
+	// The same seed produces the same sequence.
+	sequence := func() []bool {
+		faults := &RandomFaults{Probability: 0.5, Inject: fault, Rand: rand.New(rand.NewPCG(1, 2))}
+		var injected []bool
+		for range 100 {
+			injected = append(injected, faults.Fault(ctx, FaultPointPrepare).Err != nil)
+		}
+		return injected
+	}
+	first := sequence()
+	assert.Equal(t, first, sequence(), "seeded sequence")
+	assert.Contains(t, first, true, "some faults injected")
+	assert.Contains(t, first, false, "some calls succeed")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	registerapi "k8s.io/kubelet/pkg/apis/pluginregistration/v1"
+)
+
+// Points where faults can be injected. The exported names are
+// defined in faultinjection.go.
+const (
+	faultPointRegistration = "Registration"
+	faultPointPrepare      = "Prepare"
+	faultPointUnprepare    = "Unprepare"
+)
+
+// injectFaultFunc is called at each fault point. It may block and
+// returns the error that is to be injected, if any.
+//
+// It can only be set with the FaultInjection option, which is only
+// available when building with the dra_fault_injection tag. In normal
+// builds, it is always nil.
+type injectFaultFunc func(ctx context.Context, point string) error
+
+// injectFault returns the error that the operation at the point should
+// fail with, nil if it should proceed normally.
+func (d *Helper) injectFault(ctx context.Context, point string) error {
+	if d.faultInjector == nil {
+		return nil
+	}
+	return d.faultInjector(ctx, point)
+}
+
+// faultInterceptor injects faults into the GetInfo call of the kubelet
+// during plugin registration. Other calls are passed through.
+func faultInterceptor(injectFault injectFaultFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod == registerapi.Registration_GetInfo_FullMethodName {
+			if err := injectFault(ctx, faultPointRegistration); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
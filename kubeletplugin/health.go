@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// HealthzPath is the path at which [Helper.RegisterHealthChecks]
+	// serves the liveness check.
+	HealthzPath = "/healthz"
+
+	// ReadyzPath is the path at which [Helper.RegisterHealthChecks]
+	// serves the readiness check.
+	ReadyzPath = "/readyz"
+)
+
+// HTTPServer represents any type that could serve HTTP requests for the
+// health check endpoints, for example [http.ServeMux].
+type HTTPServer interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// HealthAddress enables an HTTP server which listens on the address
+// (for example, ":8080") and serves [HealthzPath] and [ReadyzPath].
+// These can be used for liveness and readiness probes of the driver's
+// DaemonSet. The server is stopped together with the helper.
+//
+// Drivers which already have an HTTP server can use
+// [Helper.RegisterHealthChecks] instead.
+func HealthAddress(address string) Option {
+	return func(o *options) error {
+		o.healthAddress = address
+		return nil
+	}
+}
+
+// HealthTLSConfig enables HTTPS for the server started by [HealthAddress].
+// The config must contain a certificate.
+func HealthTLSConfig(config *tls.Config) Option {
+	return func(o *options) error {
+		o.healthTLSConfig = config
+		return nil
+	}
+}
+
+// RegisterHealthChecks adds handlers for [HealthzPath] and [ReadyzPath]
+// which report the result of [Helper.Healthy] and [Helper.Ready].
+func (d *Helper) RegisterHealthChecks(s HTTPServer) {
+	s.Handle(HealthzPath, adaptCheckToHandler(d.Healthy))
+	s.Handle(ReadyzPath, adaptCheckToHandler(d.Ready))
+}
+
+// Healthy returns an error if the helper has been stopped or
+// one of the gRPC servers is not serving requests anymore.
+// Restarting the process is the recommended reaction.
+func (d *Helper) Healthy() error {
+	if err := context.Cause(d.backgroundCtx); err != nil {
+		return fmt.Errorf("stopped: %w", err)
+	}
+	if err := d.pluginServer.healthy(); err != nil {
+		return err
+	}
+	if d.registrar != nil {
+		if err := d.registrar.server.healthy(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ready returns an error if the plugin is not healthy, has not
+// been registered successfully with the kubelet (when the registration
+// service is enabled) or if publishing ResourceSlices (when enabled)
+// currently fails.
+func (d *Helper) Ready() error {
+	if err := d.Healthy(); err != nil {
+		return err
+	}
+	if d.registrar != nil {
+		status := d.registrar.status.Load()
+		switch {
+		case status == nil:
+			return errors.New("not registered with kubelet yet")
+		case !status.PluginRegistered:
+			return fmt.Errorf("registration with kubelet failed: %s", status.Error)
+		}
+	}
+
+	d.mutex.Lock()
+	controller := d.resourceSliceController
+	d.mutex.Unlock()
+	if controller != nil {
+		if err := controller.SyncError(); err != nil {
+			return fmt.Errorf("publishing ResourceSlices: %w", err)
+		}
+	}
+	return nil
+}
+
+// startHealthServer runs an HTTP server with the health checks
+// until the context is canceled.
+func (d *Helper) startHealthServer(ctx context.Context, address string, tlsConfig *tls.Config) error {
+	logger := klog.FromContext(ctx)
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("listen on %q: %w", address, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	mux := http.NewServeMux()
+	d.RegisterHealthChecks(mux)
+	server := &http.Server{
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	d.wg.Add(2)
+	go func() {
+		defer d.wg.Done()
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			d.plugin.HandleError(ctx, err, "health HTTP server failed")
+		}
+	}()
+	go func() {
+		defer d.wg.Done()
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	logger.V(3).Info("Health HTTP server started", "address", listener.Addr())
+	return nil
+}
+
+// adaptCheckToHandler returns an http.HandlerFunc that serves the provided checks.
+func adaptCheckToHandler(c func() error) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := c()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("internal server error: %v", err), http.StatusInternalServerError)
+		} else {
+			fmt.Fprint(w, "ok")
+		}
+	})
+}
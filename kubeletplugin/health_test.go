@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin/fakekubelet"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestHealthChecks(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+
+	// Find a free port.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "listen")
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close(), "close listener")
+
+	helper, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		HealthAddress(address),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get("http://" + address + path)
+		require.NoError(t, err, "GET %s", path)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "read body")
+		return resp.StatusCode, string(body)
+	}
+
+	code, _ := get(HealthzPath)
+	assert.Equal(t, http.StatusOK, code, "healthz before registration")
+	code, body := get(ReadyzPath)
+	assert.Equal(t, http.StatusInternalServerError, code, "readyz before registration")
+	assert.Contains(t, body, "not registered with kubelet yet")
+
+	kubelet, err := fakekubelet.Register(ctx, path.Join(tempDir, "driver.example.com-reg.sock"))
+	require.NoError(t, err, "register")
+	defer kubelet.Close()
+
+	code, body = get(ReadyzPath)
+	assert.Equal(t, http.StatusOK, code, "readyz after registration")
+	assert.Equal(t, "ok", body)
+
+	helper.Stop()
+	require.ErrorContains(t, helper.Healthy(), "stopped: DRA plugin was stopped")
+	_, err = http.Get("http://" + address + HealthzPath)
+	require.Error(t, err, "health server should be stopped")
+}
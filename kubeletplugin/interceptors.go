@@ -0,0 +1,239 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/tracing"
+	"k8s.io/klog/v2"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1"
+	drapbv1beta1 "k8s.io/kubelet/pkg/apis/dra/v1beta1"
+)
+
+// instrumentationName is used for the OpenTelemetry tracer.
+const instrumentationName = "k8s.io/dynamic-resource-allocation/kubeletplugin"
+
+// LoggingInterceptor returns a gRPC interceptor which logs one line
+// for each NodePrepareResources and NodeUnprepareResources call,
+// with the claims, the prepared devices, the duration and the outcome.
+// Other calls are not logged. Add it with [GRPCInterceptor].
+//
+// In contrast to the logging controlled by [GRPCVerbosity], this is
+// meant to be enabled in production as an audit trail: successful calls
+// get logged with the given verbosity, failures always get logged
+// as errors. The logger in the context has the request ID of
+// the call.
+func LoggingInterceptor(verbosity int) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		call, ok := newDRACall(req)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		call.setResponse(resp, "")
+
+		logger := klog.FromContext(ctx)
+		values := []any{"claims", call.claims, "duration", time.Since(start)}
+		if call.devices != nil {
+			values = append(values, "devices", call.devices)
+		}
+		switch {
+		case err != nil:
+			logger.Error(err, "DRA call failed", values...)
+		case len(call.claimErrors) > 0:
+			logger.Error(nil, "DRA call failed for some claims", append(values, "claimErrors", call.claimErrors)...)
+		default:
+			logger.V(verbosity).Info("DRA call succeeded", values...)
+		}
+		return resp, err
+	}
+}
+
+// TracingInterceptor returns a gRPC interceptor which creates an
+// OpenTelemetry span for each NodePrepareResources and
+// NodeUnprepareResources call. The span has the claim UIDs and
+// prepared devices as attributes and records failures. Add it
+// with [GRPCInterceptor].
+//
+// The interceptor does not know the driver name, so devices are recorded
+// as "<pool>/<device>". The [TracerProvider] option records them in the
+// "<driver>/<pool>/<device>" format used elsewhere, see [tracing.DevicesKey].
+func TracingInterceptor(tracerProvider trace.TracerProvider) grpc.UnaryServerInterceptor {
+	return tracingInterceptor(tracerProvider, "")
+}
+
+func tracingInterceptor(tracerProvider trace.TracerProvider, driverName string) grpc.UnaryServerInterceptor {
+	tracer := tracerProvider.Tracer(instrumentationName)
+	var driverAttributes []attribute.KeyValue
+	if driverName != "" {
+		driverAttributes = append(driverAttributes, tracing.DriverKey.String(driverName))
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		call, ok := newDRACall(req)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		uids := make([]string, 0, len(call.claims))
+		for _, claim := range call.claims {
+			uids = append(uids, string(claim.UID))
+		}
+		ctx, span := tracer.Start(ctx, info.FullMethod,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(tracing.ClaimUIDsKey.StringSlice(uids)),
+			trace.WithAttributes(driverAttributes...),
+		)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		call.setResponse(resp, driverName)
+
+		var devices []string
+		for _, uid := range slices.Sorted(maps.Keys(call.devices)) {
+			devices = append(devices, call.devices[uid]...)
+		}
+		if len(devices) > 0 {
+			span.SetAttributes(tracing.DevicesKey.StringSlice(devices))
+		}
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+		case len(call.claimErrors) > 0:
+			span.SetAttributes(tracing.ClaimFailuresKey.Int(len(call.claimErrors)))
+			span.SetStatus(otelcodes.Error, "DRA call failed for some claims")
+		default:
+			span.SetStatus(otelcodes.Ok, "")
+		}
+		return resp, err
+	}
+}
+
+// draCall summarizes one NodePrepareResources or NodeUnprepareResources call.
+type draCall struct {
+	claims []NamespacedObject
+	// devices maps claim UID to "<pool>/<device>" or, if the driver
+	// name is known, "<driver>/<pool>/<device>". Nil for unprepare.
+	devices map[string][]string
+	// claimErrors maps claim UID to the error for the claim.
+	claimErrors map[string]string
+}
+
+// newDRACall returns false if the request is not for a DRA method.
+// v1beta1 requests are converted to v1.
+func newDRACall(req any) (*draCall, bool) {
+	var claims []*drapbv1.Claim
+	switch req := req.(type) {
+	case *drapbv1.NodePrepareResourcesRequest:
+		claims = req.Claims
+	case *drapbv1.NodeUnprepareResourcesRequest:
+		claims = req.Claims
+	case *drapbv1beta1.NodePrepareResourcesRequest:
+		var converted drapbv1.NodePrepareResourcesRequest
+		if err := drapbv1beta1.Convert_v1beta1_NodePrepareResourcesRequest_To_v1_NodePrepareResourcesRequest(req, &converted, nil); err != nil {
+			return nil, false
+		}
+		claims = converted.Claims
+	case *drapbv1beta1.NodeUnprepareResourcesRequest:
+		var converted drapbv1.NodeUnprepareResourcesRequest
+		if err := drapbv1beta1.Convert_v1beta1_NodeUnprepareResourcesRequest_To_v1_NodeUnprepareResourcesRequest(req, &converted, nil); err != nil {
+			return nil, false
+		}
+		claims = converted.Claims
+	default:
+		return nil, false
+	}
+
+	call := &draCall{claims: make([]NamespacedObject, 0, len(claims))}
+	for _, claim := range claims {
+		call.claims = append(call.claims, NamespacedObject{UID: types.UID(claim.UID), NamespacedName: types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}})
+	}
+	return call, true
+}
+
+// setResponse records the result of the call. The driver name is
+// optional, see draCall.devices.
+func (c *draCall) setResponse(resp any, driverName string) {
+	switch resp := resp.(type) {
+	case *drapbv1.NodePrepareResourcesResponse:
+		c.setPrepareResponse(resp, driverName)
+	case *drapbv1.NodeUnprepareResourcesResponse:
+		c.setUnprepareResponse(resp)
+	case *drapbv1beta1.NodePrepareResourcesResponse:
+		var converted drapbv1.NodePrepareResourcesResponse
+		if err := drapbv1beta1.Convert_v1beta1_NodePrepareResourcesResponse_To_v1_NodePrepareResourcesResponse(resp, &converted, nil); err == nil {
+			c.setPrepareResponse(&converted, driverName)
+		}
+	case *drapbv1beta1.NodeUnprepareResourcesResponse:
+		var converted drapbv1.NodeUnprepareResourcesResponse
+		if err := drapbv1beta1.Convert_v1beta1_NodeUnprepareResourcesResponse_To_v1_NodeUnprepareResourcesResponse(resp, &converted, nil); err == nil {
+			c.setUnprepareResponse(&converted)
+		}
+	}
+}
+
+func (c *draCall) setPrepareResponse(resp *drapbv1.NodePrepareResourcesResponse, driverName string) {
+	if resp == nil {
+		return
+	}
+	c.devices = make(map[string][]string, len(resp.Claims))
+	for uid, claimResp := range resp.Claims {
+		if claimResp.Error != "" {
+			c.addClaimError(uid, claimResp.Error)
+			continue
+		}
+		devices := make([]string, 0, len(claimResp.Devices))
+		for _, device := range claimResp.Devices {
+			if driverName != "" {
+				devices = append(devices, tracing.DeviceID(driverName, device.PoolName, device.DeviceName))
+			} else {
+				devices = append(devices, device.PoolName+"/"+device.DeviceName)
+			}
+		}
+		c.devices[uid] = devices
+	}
+}
+
+func (c *draCall) setUnprepareResponse(resp *drapbv1.NodeUnprepareResourcesResponse) {
+	if resp == nil {
+		return
+	}
+	for uid, claimResp := range resp.Claims {
+		if claimResp.Error != "" {
+			c.addClaimError(uid, claimResp.Error)
+		}
+	}
+}
+
+func (c *draCall) addClaimError(uid, err string) {
+	if c.claimErrors == nil {
+		c.claimErrors = make(map[string]string)
+	}
+	c.claimErrors[uid] = err
+}
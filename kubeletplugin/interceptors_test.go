@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+
+	"k8s.io/dynamic-resource-allocation/internal/tracetesting"
+	"k8s.io/dynamic-resource-allocation/tracing"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/ktesting"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1"
+	drapbv1beta1 "k8s.io/kubelet/pkg/apis/dra/v1beta1"
+)
+
+var (
+	testPrepareRequest = &drapbv1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1.Claim{
+			{Namespace: "default", Name: "good", UID: "good-uid"},
+			{Namespace: "default", Name: "bad", UID: "bad-uid"},
+		},
+	}
+	testPrepareResponse = &drapbv1.NodePrepareResourcesResponse{
+		Claims: map[string]*drapbv1.NodePrepareResourceResponse{
+			"good-uid": {Devices: []*drapbv1.Device{{PoolName: "pool", DeviceName: "dev-0"}}},
+			"bad-uid":  {Error: "broken device"},
+		},
+	}
+	testPrepareInfo = &grpc.UnaryServerInfo{FullMethod: "/k8s.io.kubelet.pkg.apis.dra.v1.DRAPlugin/NodePrepareResources"}
+)
+
+func TestLoggingInterceptor(t *testing.T) {
+	logger := ktesting.NewLogger(t, ktesting.NewConfig(ktesting.BufferLogs(true)))
+	ctx := klog.NewContext(context.Background(), logger)
+	interceptor := LoggingInterceptor(2)
+	buffer := logger.GetSink().(ktesting.Underlier).GetBuffer()
+
+	resp, err := interceptor(ctx, testPrepareRequest, testPrepareInfo, func(ctx context.Context, req any) (any, error) {
+		return testPrepareResponse, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testPrepareResponse, resp, "response")
+	output := buffer.String()
+	assert.Contains(t, output, "DRA call failed for some claims")
+	assert.Contains(t, output, "pool/dev-0")
+	assert.Contains(t, output, "broken device")
+	offset := len(output)
+
+	_, err = interceptor(ctx, &drapbv1beta1.NodeUnprepareResourcesRequest{Claims: []*drapbv1beta1.Claim{{Namespace: "default", Name: "good", UID: "good-uid"}}}, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		return nil, errors.New("fake error")
+	})
+	require.Error(t, err)
+	output = buffer.String()[offset:]
+	assert.Contains(t, output, "DRA call failed")
+	assert.Contains(t, output, "fake error")
+	assert.Contains(t, output, "good-uid")
+	offset += len(output)
+
+	_, err = interceptor(ctx, "other request", &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, buffer.String()[offset:], "other calls must not be logged")
+}
+
+func TestTracingInterceptor(t *testing.T) {
+	for name, tc := range map[string]struct {
+		driverName    string
+		expectDevices []string
+	}{
+		"without-driver": {
+			expectDevices: []string{"pool/dev-0"},
+		},
+		"with-driver": {
+			driverName:    "driver.example.com",
+			expectDevices: []string{"driver.example.com/pool/dev-0"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			tracerProvider := &tracetesting.TracerProvider{}
+			interceptor := tracingInterceptor(tracerProvider, tc.driverName)
+
+			_, err := interceptor(ctx, testPrepareRequest, testPrepareInfo, func(ctx context.Context, req any) (any, error) {
+				return testPrepareResponse, nil
+			})
+			require.NoError(t, err)
+			spans := tracerProvider.Spans()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, "/k8s.io.kubelet.pkg.apis.dra.v1.DRAPlugin/NodePrepareResources", span.Name, "name")
+			assert.True(t, span.Ended(), "ended")
+			assert.Equal(t, otelcodes.Error, span.Status(), "status")
+			assert.Contains(t, span.Attributes(), attribute.StringSlice("dra.devices", tc.expectDevices))
+			assert.Contains(t, span.Attributes(), attribute.Int("dra.claim.failures", 1))
+			driver, ok := span.Attribute(tracing.DriverKey)
+			assert.Equal(t, tc.driverName != "", ok, "has driver attribute")
+			assert.Equal(t, tc.driverName, driver.AsString(), "driver")
+		})
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/metrics"
+	drametrics "k8s.io/dynamic-resource-allocation/metrics"
+)
+
+const (
+	metricsNamespace = drametrics.Namespace
+	metricsSubsystem = drametrics.SubsystemKubeletPlugin
+)
+
+var (
+	pendingUnprepareClaims = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "pending_unprepare_claims",
+			Help:           "Number of claims for which unpreparing failed after the claim was removed and which are still getting retried.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name"},
+	)
+)
+
+func init() {
+	drametrics.Add(metricsSubsystem, pendingUnprepareClaims)
+}
+
+// registerMetrics registers the metrics of this package in the
+// legacy registry of k8s.io/component-base/metrics.
+func registerMetrics() {
+	utilruntime.Must(drametrics.RegisterMetrics(drametrics.LegacyRegistry, drametrics.EnableSubsystems(metricsSubsystem)))
+}
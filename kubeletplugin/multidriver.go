@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Driver is one of several DRA drivers managed by [StartDrivers].
+type Driver struct {
+	// Plugin implements the driver.
+	Plugin DRAPlugin
+
+	// Options are specific to this driver. They must include
+	// [DriverName] and may override the common options,
+	// for example with [Resources].
+	Options []Option
+}
+
+// Drivers is the result of [StartDrivers].
+type Drivers struct {
+	helpers map[string]*Helper
+}
+
+// StartDrivers calls [Start] for each driver, with the common options
+// followed by the options of the driver. This is meant for a single
+// binary which manages several device families on a node under
+// different driver names.
+//
+// Each driver gets its own sockets, resources and DRAPlugin. By default,
+// the socket paths are derived from the driver name and thus are unique.
+// StartDrivers checks that explicitly configured paths do not conflict.
+// This includes the plugin data directory (see [PluginDataDirectoryPath]),
+// also when the DRA service does not listen on a socket in it.
+// If starting one driver fails, all drivers which were started
+// already get stopped again.
+func StartDrivers(ctx context.Context, drivers []Driver, commonOpts ...Option) (result *Drivers, finalErr error) {
+	names := sets.New[string]()
+	sockets := make(map[string]string)
+	dataDirs := make(map[string]string)
+	for i, driver := range drivers {
+		o, err := newOptions(append(slices.Clone(commonOpts), driver.Options...)...)
+		if err != nil {
+			return nil, fmt.Errorf("driver #%d: %w", i, err)
+		}
+		if o.driverName == "" {
+			return nil, fmt.Errorf("driver #%d: driver name must be set", i)
+		}
+		if names.Has(o.driverName) {
+			return nil, fmt.Errorf("driver %s: driver name used more than once", o.driverName)
+		}
+		names.Insert(o.driverName)
+		o.completeEndpoints()
+		for _, socket := range o.socketPaths() {
+			if other, ok := sockets[socket]; ok {
+				return nil, fmt.Errorf("driver %s: socket %s is also used by driver %s", o.driverName, socket, other)
+			}
+			sockets[socket] = o.driverName
+		}
+		// The directory also holds checkpoints and lock files,
+		// which are not shared between drivers.
+		if other, ok := dataDirs[o.pluginDataDirectoryPath]; ok {
+			return nil, fmt.Errorf("driver %s: plugin data directory %s is also used by driver %s", o.driverName, o.pluginDataDirectoryPath, other)
+		}
+		dataDirs[o.pluginDataDirectoryPath] = o.driverName
+	}
+
+	d := &Drivers{helpers: make(map[string]*Helper, len(drivers))}
+	defer func() {
+		if finalErr != nil {
+			d.Stop()
+		}
+	}()
+	for _, driver := range drivers {
+		helper, err := Start(ctx, driver.Plugin, append(slices.Clone(commonOpts), driver.Options...)...)
+		if err != nil {
+			return nil, fmt.Errorf("start driver: %w", err)
+		}
+		d.helpers[helper.driverName] = helper
+	}
+	return d, nil
+}
+
+// socketPaths returns all Unix domain sockets which the helper
+// will create. completeEndpoints must have been called.
+func (o *options) socketPaths() []string {
+	var sockets []string
+	if o.registrationService {
+		sockets = append(sockets, o.pluginRegistrationEndpoint.path())
+	}
+	if o.draService && !o.singleSocket && o.draEndpointListen == nil {
+		sockets = append(sockets, endpoint{dir: o.pluginDataDirectoryPath, file: o.pluginSocket}.path())
+	}
+	if o.debugSocketPath != "" {
+		sockets = append(sockets, o.debugSocketPath)
+	}
+	return sockets
+}
+
+// Helper returns the helper for the driver, nil if there is none.
+func (d *Drivers) Helper(driverName string) *Helper {
+	return d.helpers[driverName]
+}
+
+// DriverNames returns the names of all drivers, sorted alphabetically.
+func (d *Drivers) DriverNames() []string {
+	return slices.Sorted(maps.Keys(d.helpers))
+}
+
+// Stop stops all drivers.
+func (d *Drivers) Stop() {
+	if d == nil {
+		return
+	}
+	for _, helper := range d.helpers {
+		helper.Stop()
+	}
+}
+
+// Healthy returns nil if all drivers are healthy, otherwise
+// the errors of those which are not.
+func (d *Drivers) Healthy() error {
+	var errs []error
+	for _, driverName := range d.DriverNames() {
+		if err := d.helpers[driverName].Healthy(); err != nil {
+			errs = append(errs, fmt.Errorf("driver %s: %w", driverName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestStartDrivers(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	gpuPlugin, nicPlugin := &testPlugin{t: t}, &testPlugin{t: t}
+	gpuDir, nicDir := path.Join(tempDir, "gpu"), path.Join(tempDir, "nic")
+	require.NoError(t, os.Mkdir(gpuDir, 0700))
+	require.NoError(t, os.Mkdir(nicDir, 0700))
+	common := []Option{
+		KubeClient(fake.NewClientset()),
+		RegistrarDirectoryPath(tempDir),
+	}
+
+	_, err := StartDrivers(ctx, []Driver{
+		{Plugin: gpuPlugin, Options: []Option{DriverName("gpu.example.com"), PluginDataDirectoryPath(gpuDir)}},
+		{Plugin: nicPlugin, Options: []Option{DriverName("nic.example.com"), PluginDataDirectoryPath(gpuDir)}},
+	}, common...)
+	require.EqualError(t, err, "driver nic.example.com: socket "+path.Join(gpuDir, "dra.sock")+" is also used by driver gpu.example.com", "conflicting sockets")
+
+	_, err = StartDrivers(ctx, []Driver{
+		{Plugin: gpuPlugin, Options: []Option{DriverName("gpu.example.com"), PluginDataDirectoryPath(gpuDir)}},
+		{Plugin: nicPlugin, Options: []Option{DriverName("nic.example.com"), PluginDataDirectoryPath(gpuDir)}},
+	}, append(common, SingleSocket(true))...)
+	require.EqualError(t, err, "driver nic.example.com: plugin data directory "+gpuDir+" is also used by driver gpu.example.com", "conflicting data directories")
+
+	_, err = StartDrivers(ctx, []Driver{
+		{Plugin: gpuPlugin, Options: []Option{DriverName("gpu.example.com"), Standalone()}},
+		{Plugin: nicPlugin, Options: []Option{DriverName("gpu.example.com"), Standalone()}},
+	}, common...)
+	require.EqualError(t, err, "driver gpu.example.com: driver name used more than once", "duplicate driver name")
+
+	_, err = StartDrivers(ctx, []Driver{{Plugin: gpuPlugin}}, common...)
+	require.EqualError(t, err, "driver #0: driver name must be set", "missing driver name")
+
+	drivers, err := StartDrivers(ctx, []Driver{
+		{Plugin: gpuPlugin, Options: []Option{DriverName("gpu.example.com"), PluginDataDirectoryPath(gpuDir)}},
+		{Plugin: nicPlugin, Options: []Option{DriverName("nic.example.com"), PluginDataDirectoryPath(nicDir)}},
+	}, common...)
+	require.NoError(t, err, "start")
+	defer drivers.Stop()
+	assert.Equal(t, []string{"gpu.example.com", "nic.example.com"}, drivers.DriverNames(), "driver names")
+	assert.Nil(t, drivers.Helper("other.example.com"), "unknown driver")
+	for _, socket := range []string{
+		path.Join(tempDir, "gpu.example.com-reg.sock"),
+		path.Join(tempDir, "nic.example.com-reg.sock"),
+		path.Join(gpuDir, "dra.sock"),
+		path.Join(nicDir, "dra.sock"),
+	} {
+		assert.FileExists(t, socket)
+	}
+
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+	_, err = drivers.Helper("nic.example.com").PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err, "prepare")
+	assert.Empty(t, gpuPlugin.getPrepared(), "prepared by GPU driver")
+	assert.Equal(t, []types.UID{claim.UID}, nicPlugin.getPrepared(), "prepared by NIC driver")
+}
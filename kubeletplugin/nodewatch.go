@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// NodeHandler gets notified about changes of the Node object, see [WatchNode].
+// The methods are called sequentially in the background and should not
+// block for long.
+type NodeHandler interface {
+	// NodeDeleting is called once when the node gets marked for
+	// deletion or, if that was not observed, when it got removed.
+	// This is the last chance to clean up before the kubelet
+	// goes away.
+	NodeDeleting(ctx context.Context, node *v1.Node)
+
+	// NodeTainted is called when the node gets one or more of the
+	// watched taints. It is called again only for taints which get
+	// removed and added again. Taints which the node already has when
+	// the watch starts also get reported.
+	NodeTainted(ctx context.Context, node *v1.Node, taints []v1.Taint)
+}
+
+// WatchNode enables watching the Node object of the node the driver is
+// running on. The handler gets called when the node is being deleted or
+// gets any of the taints with the given keys. The default is the
+// [v1.TaintNodeUnschedulable] key, which is added when cordoning a node.
+//
+// Drivers can use this to flush prepared state or flag devices as
+// unhealthy proactively. [NodeName] must be set.
+func WatchNode(handler NodeHandler, taintKeys ...string) Option {
+	return func(o *options) error {
+		if len(taintKeys) == 0 {
+			taintKeys = []string{v1.TaintNodeUnschedulable}
+		}
+		o.nodeHandler = handler
+		o.nodeTaintKeys = taintKeys
+		return nil
+	}
+}
+
+// startNodeWatch starts an informer for the node. It runs until the context
+// gets canceled.
+func (d *Helper) startNodeWatch(ctx context.Context, handler NodeHandler, taintKeys []string) error {
+	logger := klog.LoggerWithName(klog.FromContext(ctx), "nodewatch")
+	ctx = klog.NewContext(ctx, logger)
+
+	tweakListOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector(metav1.ObjectNameField, d.nodeName).String()
+	}
+	informer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+				tweakListOptions(&options)
+				return d.kubeClient.CoreV1().Nodes().List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+				tweakListOptions(&options)
+				return d.kubeClient.CoreV1().Nodes().Watch(ctx, options)
+			},
+		},
+		&v1.Node{},
+		0,
+		cache.Indexers{},
+	)
+
+	watchedTaints := func(node *v1.Node) []v1.Taint {
+		var taints []v1.Taint
+		for _, taint := range node.Spec.Taints {
+			if slices.Contains(taintKeys, taint.Key) {
+				taints = append(taints, taint)
+			}
+		}
+		return taints
+	}
+	nodeChanged := func(oldNode, newNode *v1.Node) {
+		if newNode.DeletionTimestamp != nil && (oldNode == nil || oldNode.DeletionTimestamp == nil) {
+			logger.V(3).Info("Node is being deleted", "node", klog.KObj(newNode))
+			handler.NodeDeleting(ctx, newNode)
+		}
+		var oldTaints []v1.Taint
+		if oldNode != nil {
+			oldTaints = watchedTaints(oldNode)
+		}
+		var addedTaints []v1.Taint
+		for _, taint := range watchedTaints(newNode) {
+			if !slices.ContainsFunc(oldTaints, func(oldTaint v1.Taint) bool { return oldTaint.MatchTaint(&taint) }) {
+				addedTaints = append(addedTaints, taint)
+			}
+		}
+		if len(addedTaints) > 0 {
+			logger.V(3).Info("Node got tainted", "node", klog.KObj(newNode), "taints", addedTaints)
+			handler.NodeTainted(ctx, newNode, addedTaints)
+		}
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			node, ok := obj.(*v1.Node)
+			if !ok {
+				return
+			}
+			nodeChanged(nil, node)
+		},
+		UpdateFunc: func(old, new any) {
+			oldNode, ok := old.(*v1.Node)
+			if !ok {
+				return
+			}
+			newNode, ok := new.(*v1.Node)
+			if !ok {
+				return
+			}
+			nodeChanged(oldNode, newNode)
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			node, ok := obj.(*v1.Node)
+			if !ok || node.DeletionTimestamp != nil {
+				// Already reported.
+				return
+			}
+			logger.V(3).Info("Node was deleted", "node", klog.KObj(node))
+			handler.NodeDeleting(ctx, node)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("registering event handler on the Node informer: %w", err)
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer logger.V(3).Info("Node informer has stopped")
+		informer.Run(ctx.Done())
+	}()
+	return nil
+}
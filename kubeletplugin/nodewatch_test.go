@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+// testNodeHandler records the calls that it receives as strings.
+type testNodeHandler struct {
+	mutex sync.Mutex
+	calls []string
+}
+
+func (h *testNodeHandler) NodeDeleting(ctx context.Context, node *v1.Node) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.calls = append(h.calls, "deleting "+node.Name)
+}
+
+func (h *testNodeHandler) NodeTainted(ctx context.Context, node *v1.Node, taints []v1.Taint) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, taint := range taints {
+		h.calls = append(h.calls, "tainted "+node.Name+" "+taint.Key)
+	}
+}
+
+func (h *testNodeHandler) getCalls() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return slices.Clone(h.calls)
+}
+
+func TestWatchNode(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}}
+	kubeClient := fake.NewClientset(node)
+	handler := &testNodeHandler{}
+
+	helper, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(kubeClient),
+		NodeName(node.Name),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		WatchNode(handler, v1.TaintNodeUnschedulable, "example.com/maintenance"),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	expectCalls := func(t *testing.T, expected ...string) {
+		t.Helper()
+		require.EventuallyWithT(t, func(t *assert.CollectT) {
+			assert.Equal(t, expected, handler.getCalls())
+		}, 10*time.Second, 10*time.Millisecond)
+	}
+	update := func(t *testing.T, modify func(node *v1.Node)) {
+		t.Helper()
+		modify(node)
+		var err error
+		node, err = kubeClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		require.NoError(t, err, "update node")
+	}
+
+	// Give the informer a chance to start before making changes.
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, handler.getCalls(), "initial calls")
+
+	update(t, func(node *v1.Node) {
+		node.Spec.Taints = append(node.Spec.Taints,
+			v1.Taint{Key: "example.com/other", Effect: v1.TaintEffectNoSchedule},
+			v1.Taint{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule},
+		)
+	})
+	expectCalls(t, "tainted worker "+v1.TaintNodeUnschedulable)
+
+	update(t, func(node *v1.Node) {
+		node.Spec.Taints = append(node.Spec.Taints, v1.Taint{Key: "example.com/maintenance", Effect: v1.TaintEffectNoExecute})
+	})
+	expectCalls(t, "tainted worker "+v1.TaintNodeUnschedulable, "tainted worker example.com/maintenance")
+
+	now := metav1.Now()
+	update(t, func(node *v1.Node) {
+		node.DeletionTimestamp = &now
+	})
+	expectCalls(t, "tainted worker "+v1.TaintNodeUnschedulable, "tainted worker example.com/maintenance", "deleting worker")
+
+	require.NoError(t, kubeClient.CoreV1().Nodes().Delete(ctx, node.Name, metav1.DeleteOptions{}), "delete node")
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, handler.getCalls(), 3, "deleting must only be reported once")
+}
+
+func TestWatchNodeNoNodeName(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+
+	_, err := Start(ctx, &testPlugin{t: t},
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		WatchNode(&testNodeHandler{}),
+	)
+	require.ErrorContains(t, err, "no NodeName was set to watch the node")
+}
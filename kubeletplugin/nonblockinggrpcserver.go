@@ -18,6 +18,7 @@ package kubeletplugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -33,6 +34,7 @@ type grpcServer struct {
 	wg            sync.WaitGroup
 	endpoint      endpoint
 	server        *grpc.Server
+	terminated    atomic.Bool
 }
 
 type registerService func(s *grpc.Server)
@@ -79,8 +81,10 @@ func startGRPCServer(logger klog.Logger, grpcVerbosity int, unaryInterceptors []
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
+		defer s.terminated.Store(true)
 		err := s.server.Serve(listener)
-		if err != nil {
+		// Stopping before Serve got called is not an error.
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
 			errHandler(ctx, err)
 		} else {
 			logger.V(3).Info("GRPC server terminated gracefully", "endpoint", endpoint.path())
@@ -207,6 +211,18 @@ func (l logStream) SendMsg(msg interface{}) error {
 	return err
 }
 
+// healthy returns an error if the server is not serving requests anymore.
+// A nil server is considered healthy.
+func (s *grpcServer) healthy() error {
+	if s == nil {
+		return nil
+	}
+	if s.terminated.Load() {
+		return fmt.Errorf("gRPC server for %s has terminated", s.endpoint.path())
+	}
+	return nil
+}
+
 // stop ensures that the server is not running anymore and cleans up all resources.
 // It is idempotent and may be called with a nil pointer.
 func (s *grpcServer) stop() {
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+
+	"k8s.io/klog/v2"
+)
+
+// PeerCredentials describes the process on the other side of a
+// Unix domain socket connection, as reported by the operating
+// system (SO_PEERCRED on Linux).
+type PeerCredentials struct {
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// PeerAuthorizer decides whether a process may use the gRPC services
+// of the plugin. It returns nil if the connection is allowed and
+// an error describing the reason otherwise.
+type PeerAuthorizer func(creds PeerCredentials) error
+
+// AllowUIDs returns a PeerAuthorizer which accepts connections
+// from processes running as one of the given user IDs.
+// The kubelet typically runs as root (UID 0).
+func AllowUIDs(uids ...uint32) PeerAuthorizer {
+	return func(creds PeerCredentials) error {
+		if !slices.Contains(uids, creds.UID) {
+			return fmt.Errorf("UID %d not allowed", creds.UID)
+		}
+		return nil
+	}
+}
+
+// AllowGIDs returns a PeerAuthorizer which accepts connections
+// from processes running with one of the given group IDs.
+func AllowGIDs(gids ...uint32) PeerAuthorizer {
+	return func(creds PeerCredentials) error {
+		if !slices.Contains(gids, creds.GID) {
+			return fmt.Errorf("GID %d not allowed", creds.GID)
+		}
+		return nil
+	}
+}
+
+// errPeerCredentialsUnsupported is returned by getPeerCredentials
+// on platforms where retrieving peer credentials is not implemented.
+var errPeerCredentialsUnsupported = errors.New("retrieving peer credentials is not supported on this platform")
+
+// authorizingListener checks each new connection with the authorizer.
+// Rejected connections get closed immediately.
+type authorizingListener struct {
+	net.Listener
+	logger    klog.Logger
+	authorize PeerAuthorizer
+}
+
+func (l *authorizingListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if err := l.authorizeConn(conn); err != nil {
+			l.logger.Error(err, "Rejected connection", "remoteAddr", conn.RemoteAddr())
+			_ = conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
+func (l *authorizingListener) authorizeConn(conn net.Conn) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return fmt.Errorf("connection of type %T is not a Unix domain socket connection", conn)
+	}
+	creds, err := getPeerCredentials(unixConn)
+	if err != nil {
+		return fmt.Errorf("get peer credentials: %w", err)
+	}
+	if err := l.authorize(creds); err != nil {
+		return fmt.Errorf("peer with PID %d, UID %d, GID %d: %w", creds.PID, creds.UID, creds.GID, err)
+	}
+	return nil
+}
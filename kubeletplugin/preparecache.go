@@ -0,0 +1,116 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"slices"
+	"sync"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CachePrepareResults controls whether the helper remembers the result
+// of successfully preparing a claim. Off by default.
+//
+// When enabled, asking again to prepare a claim with the same UID
+// returns the remembered result without calling
+// [DRAPlugin.PrepareResourceClaims] and without [PrepareAdmission].
+// The kubelet does that when retrying after a failure of some other
+// claim of the same pod or after restarting. Unpreparing a claim
+// forgets about it, regardless of the outcome.
+//
+// The cache is kept in memory. After a restart of the driver,
+// the driver gets called again and still must be idempotent.
+func CachePrepareResults(enabled bool) Option {
+	return func(o *options) error {
+		o.cachePrepareResults = enabled
+		return nil
+	}
+}
+
+// prepareCache holds the results of successfully prepared claims.
+//
+// A nil cache is valid and never has any entries.
+type prepareCache struct {
+	mutex   sync.Mutex
+	results map[types.UID]PrepareResult
+}
+
+// lookup splits the claims into those which have a cached result and
+// those which need to be prepared. The returned results are copies.
+func (c *prepareCache) lookup(claims []*resourceapi.ResourceClaim) ([]*resourceapi.ResourceClaim, map[types.UID]PrepareResult) {
+	if c == nil {
+		return claims, nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var cached map[types.UID]PrepareResult
+	var uncached []*resourceapi.ResourceClaim
+	for _, claim := range claims {
+		result, ok := c.results[claim.UID]
+		if !ok {
+			uncached = append(uncached, claim)
+			continue
+		}
+		if cached == nil {
+			cached = make(map[types.UID]PrepareResult)
+		}
+		cached[claim.UID] = copyPrepareResult(result)
+	}
+	return uncached, cached
+}
+
+// store remembers all successful results for the given claims.
+func (c *prepareCache) store(claims []*resourceapi.ResourceClaim, result map[types.UID]PrepareResult) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, claim := range claims {
+		if claimResult, ok := result[claim.UID]; ok && claimResult.Err == nil {
+			c.results[claim.UID] = copyPrepareResult(claimResult)
+		}
+	}
+}
+
+// forget removes the claims from the cache.
+func (c *prepareCache) forget(claims []NamespacedObject) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, claim := range claims {
+		delete(c.results, claim.UID)
+	}
+}
+
+// copyPrepareResult ensures that callers cannot modify the cached result.
+func copyPrepareResult(result PrepareResult) PrepareResult {
+	result.Devices = slices.Clone(result.Devices)
+	for i := range result.Devices {
+		device := &result.Devices[i]
+		device.Requests = slices.Clone(device.Requests)
+		device.CDIDeviceIDs = slices.Clone(device.CDIDeviceIDs)
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestCachePrepareResults(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	newClaim := func(name string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+			Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+		}
+	}
+	claimA, claimB := newClaim("a"), newClaim("b")
+	plugin := &testPlugin{t: t, prepareErr: map[types.UID]error{claimB.UID: errors.New("fake error")}}
+
+	helper, err := Start(ctx, plugin,
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		CachePrepareResults(true),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	result, err := helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claimA, claimB})
+	require.NoError(t, err, "first prepare")
+	assert.Equal(t, map[types.UID]PrepareResult{claimA.UID: {}, claimB.UID: {Err: errors.New("fake error")}}, result, "first prepare result")
+	assert.Equal(t, []types.UID{claimA.UID, claimB.UID}, plugin.getPrepared(), "prepared claims after first prepare")
+
+	// Only the failed claim gets prepared again.
+	plugin.mutex.Lock()
+	plugin.prepareErr = nil
+	plugin.mutex.Unlock()
+	result, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claimA, claimB})
+	require.NoError(t, err, "second prepare")
+	assert.Equal(t, map[types.UID]PrepareResult{claimA.UID: {}, claimB.UID: {}}, result, "second prepare result")
+	assert.Equal(t, []types.UID{claimA.UID, claimB.UID, claimB.UID}, plugin.getPrepared(), "prepared claims after second prepare")
+
+	// Now everything is cached.
+	result, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claimA, claimB})
+	require.NoError(t, err, "third prepare")
+	assert.Equal(t, map[types.UID]PrepareResult{claimA.UID: {}, claimB.UID: {}}, result, "third prepare result")
+	assert.Equal(t, []types.UID{claimA.UID, claimB.UID, claimB.UID}, plugin.getPrepared(), "prepared claims after third prepare")
+
+	// Unpreparing invalidates the cache.
+	_, err = helper.UnprepareClaims(ctx, []NamespacedObject{{UID: claimA.UID, NamespacedName: types.NamespacedName{Namespace: claimA.Namespace, Name: claimA.Name}}})
+	require.NoError(t, err, "unprepare")
+	_, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claimA})
+	require.NoError(t, err, "prepare after unprepare")
+	assert.Equal(t, []types.UID{claimA.UID, claimB.UID, claimB.UID, claimA.UID}, plugin.getPrepared(), "prepared claims after unprepare")
+}
+
+func TestCopyPrepareResult(t *testing.T) {
+	original := PrepareResult{Devices: []Device{{Requests: []string{"req"}, PoolName: "pool", DeviceName: "dev", CDIDeviceIDs: []string{"vendor.com/class=dev"}}}}
+	cache := &prepareCache{results: make(map[types.UID]PrepareResult)}
+	claim := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{UID: "uid"}}
+	cache.store([]*resourceapi.ResourceClaim{claim}, map[types.UID]PrepareResult{claim.UID: original})
+	_, cached := cache.lookup([]*resourceapi.ResourceClaim{claim})
+	cached[claim.UID].Devices[0].CDIDeviceIDs[0] = "modified"
+	_, cached = cache.lookup([]*resourceapi.ResourceClaim{claim})
+	assert.Equal(t, original, cached[claim.UID], "cached result must not change")
+}
+
+// nilResultPlugin returns no result at all for claims which it prepared.
+type nilResultPlugin struct {
+	*testPlugin
+	nilResult bool
+}
+
+func (p *nilResultPlugin) PrepareResourceClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) (map[types.UID]PrepareResult, error) {
+	result, err := p.testPlugin.PrepareResourceClaims(ctx, claims)
+	if p.nilResult {
+		return nil, err
+	}
+	return result, err
+}
+
+func TestCachePrepareResultsMissingResult(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	newClaim := func(name string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+			Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+		}
+	}
+	claimA, claimB := newClaim("a"), newClaim("b")
+	plugin := &nilResultPlugin{testPlugin: &testPlugin{t: t}}
+
+	helper, err := Start(ctx, plugin,
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		CachePrepareResults(true),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	_, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claimA})
+	require.NoError(t, err, "prepare first claim")
+
+	// Claim A is cached, the driver returns nothing for claim B.
+	plugin.nilResult = true
+	result, err := helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claimA, claimB})
+	require.NoError(t, err, "prepare both claims")
+	assert.Equal(t, PrepareResult{}, result[claimA.UID], "cached claim")
+	assert.EqualError(t, result[claimB.UID].Err, "claim default/b: no result from DRA driver", "claim without result")
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PrepareResultBuilder constructs the [PrepareResult] for one ResourceClaim.
+// It checks the result while it gets built, so that malformed results are
+// detected by the DRA driver instead of getting rejected by the kubelet.
+//
+// For each device allocated by the driver in the claim, [PrepareResultBuilder.AddDevice]
+// must be called exactly once. [PrepareResultBuilder.Build] then returns
+// a PrepareResult with Err set if anything was wrong.
+//
+// A PrepareResultBuilder is not safe for concurrent use.
+type PrepareResultBuilder struct {
+	driverName string
+	claim      *resourceapi.ResourceClaim
+	devices    []Device
+	added      map[deviceKey]bool
+	errs       []error
+}
+
+type deviceKey struct {
+	request, pool, device string
+}
+
+// NewPrepareResultBuilder starts building the result for the claim.
+// Only devices allocated by the given driver are relevant,
+// all other devices in the claim are ignored.
+func NewPrepareResultBuilder(driverName string, claim *resourceapi.ResourceClaim) *PrepareResultBuilder {
+	return &PrepareResultBuilder{
+		driverName: driverName,
+		claim:      claim,
+		added:      make(map[deviceKey]bool),
+	}
+}
+
+// AddDevice records that the allocated device was prepared and is
+// made available to containers through the given CDI devices.
+// The device must be part of the claim's allocation result.
+func (b *PrepareResultBuilder) AddDevice(result resourceapi.DeviceRequestAllocationResult, cdiDeviceIDs ...string) *PrepareResultBuilder {
+	key := deviceKey{request: result.Request, pool: result.Pool, device: result.Device}
+	switch {
+	case result.Driver != b.driverName:
+		b.errs = append(b.errs, fmt.Errorf("device %s/%s for request %q: allocated by driver %q, not %q", result.Pool, result.Device, result.Request, result.Driver, b.driverName))
+		return b
+	case !b.isAllocated(key):
+		b.errs = append(b.errs, fmt.Errorf("device %s/%s for request %q: not allocated for the claim", result.Pool, result.Device, result.Request))
+		return b
+	case b.added[key]:
+		b.errs = append(b.errs, fmt.Errorf("device %s/%s for request %q: added more than once", result.Pool, result.Device, result.Request))
+		return b
+	}
+	for _, id := range cdiDeviceIDs {
+		if err := ValidateCDIDeviceID(id); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("device %s/%s for request %q: %w", result.Pool, result.Device, result.Request, err))
+			return b
+		}
+	}
+	b.added[key] = true
+	b.devices = append(b.devices, Device{
+		Requests:     []string{result.Request},
+		PoolName:     result.Pool,
+		DeviceName:   result.Device,
+		CDIDeviceIDs: cdiDeviceIDs,
+	})
+	return b
+}
+
+// Build returns the final result. If some device was not added or
+// an earlier AddDevice call failed, then PrepareResult.Err
+// describes all of those problems and the devices are omitted.
+func (b *PrepareResultBuilder) Build() PrepareResult {
+	errs := b.errs
+	if b.claim.Status.Allocation == nil {
+		errs = append(errs, errors.New("claim not allocated"))
+	} else {
+		for _, result := range b.claim.Status.Allocation.Devices.Results {
+			if result.Driver != b.driverName {
+				continue
+			}
+			if !b.added[deviceKey{request: result.Request, pool: result.Pool, device: result.Device}] {
+				errs = append(errs, fmt.Errorf("device %s/%s for request %q: not added", result.Pool, result.Device, result.Request))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return PrepareResult{Err: fmt.Errorf("invalid prepare result for claim %s/%s: %w", b.claim.Namespace, b.claim.Name, errors.Join(errs...))}
+	}
+	return PrepareResult{Devices: b.devices}
+}
+
+func (b *PrepareResultBuilder) isAllocated(key deviceKey) bool {
+	if b.claim.Status.Allocation == nil {
+		return false
+	}
+	for _, result := range b.claim.Status.Allocation.Devices.Results {
+		if result.Driver == b.driverName &&
+			result.Request == key.request &&
+			result.Pool == key.pool &&
+			result.Device == key.device {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCDIDeviceID checks that the string is a fully-qualified
+// CDI device name of the form "<vendor>/<class>=<name>", as defined in
+// https://github.com/cncf-tags/container-device-interface/blob/main/SPEC.md.
+func ValidateCDIDeviceID(id string) error {
+	kind, name, ok := strings.Cut(id, "=")
+	if !ok {
+		return fmt.Errorf("CDI device ID %q: must have the form <vendor>/<class>=<name>", id)
+	}
+	vendor, class, ok := strings.Cut(kind, "/")
+	if !ok {
+		return fmt.Errorf("CDI device ID %q: must have the form <vendor>/<class>=<name>", id)
+	}
+	if err := validateCDIName(vendor, isCDIVendorChar); err != nil {
+		return fmt.Errorf("CDI device ID %q: vendor: %w", id, err)
+	}
+	if err := validateCDIName(class, isCDIClassChar); err != nil {
+		return fmt.Errorf("CDI device ID %q: class: %w", id, err)
+	}
+	if err := validateCDIName(name, isCDIDeviceChar); err != nil {
+		return fmt.Errorf("CDI device ID %q: name: %w", id, err)
+	}
+	return nil
+}
+
+func validateCDIName(name string, isValidChar func(c rune) bool) error {
+	if name == "" {
+		return errors.New("must not be empty")
+	}
+	if !isAlphaNumeric(rune(name[0])) {
+		return fmt.Errorf("%q must start with a letter or digit", name)
+	}
+	if !isAlphaNumeric(rune(name[len(name)-1])) {
+		return fmt.Errorf("%q must end with a letter or digit", name)
+	}
+	for _, c := range name {
+		if !isValidChar(c) {
+			return fmt.Errorf("%q contains invalid character %q", name, c)
+		}
+	}
+	return nil
+}
+
+func isAlphaNumeric(c rune) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isCDIVendorChar(c rune) bool {
+	return isAlphaNumeric(c) || c == '-' || c == '_' || c == '.'
+}
+
+func isCDIClassChar(c rune) bool {
+	return isAlphaNumeric(c) || c == '-' || c == '_'
+}
+
+func isCDIDeviceChar(c rune) bool {
+	return isAlphaNumeric(c) || c == '-' || c == '_' || c == '.' || c == ':'
+}
+
+// grpcError turns an error returned by the DRA driver or the helper into
+// an error with a suitable gRPC status code. If the error already has
+// a gRPC status, that status is used as-is.
+func grpcError(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	var withStatus interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &withStatus) {
+		return status.Errorf(withStatus.GRPCStatus().Code(), "%s: %v", msg, err)
+	}
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case apierrors.IsNotFound(err):
+		code = codes.NotFound
+	case apierrors.IsForbidden(err):
+		code = codes.PermissionDenied
+	case apierrors.IsUnauthorized(err):
+		code = codes.Unauthenticated
+	case apierrors.IsTooManyRequests(err), apierrors.IsServerTimeout(err), apierrors.IsServiceUnavailable(err):
+		code = codes.Unavailable
+	}
+	return status.Errorf(code, "%s: %v", msg, err)
+}
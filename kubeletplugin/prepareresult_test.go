@@ -0,0 +1,189 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/resourceclaim"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateCDIDeviceID(t *testing.T) {
+	for id, expectErr := range map[string]string{
+		"vendor.com/class=name":        "",
+		"nvidia.com/gpu=all":           "",
+		"vendor.com/class_1=gpu-0:1.2": "",
+		"vendor.com/class":             "must have the form",
+		"vendor.com=name":              "must have the form",
+		"/class=name":                  "vendor: must not be empty",
+		"vendor.com/=name":             "class: must not be empty",
+		"vendor.com/class=":            "name: must not be empty",
+		"-vendor/class=name":           "must start with a letter or digit",
+		"vendor/class=name-":           "must end with a letter or digit",
+		"vendor/cl.ass=name":           "contains invalid character '.'",
+		"vendor/class=na/me":           "contains invalid character '/'",
+	} {
+		t.Run(id, func(t *testing.T) {
+			err := ValidateCDIDeviceID(id)
+			if expectErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, expectErr)
+			}
+		})
+	}
+}
+
+func TestCDIDeviceNameIsValid(t *testing.T) {
+	id := resourceclaim.CDIDeviceName("gpu", "5c5b8b26-1a4b-4d8e-9f3c-6b0f1e2d3c4a", resourceapi.DeviceRequestAllocationResult{
+		Driver:  "gpu.example.com",
+		Pool:    "node-1.example.com/part-a",
+		Device:  "gpu-0",
+		ShareID: ptr.To(types.UID("0f1e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b")),
+	})
+	require.NoError(t, ValidateCDIDeviceID(id))
+}
+
+func TestPrepareResultBuilder(t *testing.T) {
+	driverName := "driver.example.com"
+	device0 := resourceapi.DeviceRequestAllocationResult{Request: "req-0", Driver: driverName, Pool: "pool", Device: "dev-0"}
+	device1 := resourceapi.DeviceRequestAllocationResult{Request: "req-1/sub", Driver: driverName, Pool: "pool", Device: "dev-1"}
+	otherDevice := resourceapi.DeviceRequestAllocationResult{Request: "req-2", Driver: "other.example.com", Pool: "pool", Device: "dev-2"}
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim"},
+		Status: resourceapi.ResourceClaimStatus{
+			Allocation: &resourceapi.AllocationResult{
+				Devices: resourceapi.DeviceAllocationResult{
+					Results: []resourceapi.DeviceRequestAllocationResult{device0, device1, otherDevice},
+				},
+			},
+		},
+	}
+
+	testcases := map[string]struct {
+		claim           *resourceapi.ResourceClaim
+		build           func(b *PrepareResultBuilder)
+		expectDevices   []Device
+		expectErrString string
+	}{
+		"complete": {
+			build: func(b *PrepareResultBuilder) {
+				b.AddDevice(device0, "vendor.com/class=dev-0").AddDevice(device1)
+			},
+			expectDevices: []Device{
+				{Requests: []string{"req-0"}, PoolName: "pool", DeviceName: "dev-0", CDIDeviceIDs: []string{"vendor.com/class=dev-0"}},
+				{Requests: []string{"req-1/sub"}, PoolName: "pool", DeviceName: "dev-1"},
+			},
+		},
+		"missing": {
+			build: func(b *PrepareResultBuilder) {
+				b.AddDevice(device0)
+			},
+			expectErrString: `invalid prepare result for claim default/claim: device pool/dev-1 for request "req-1/sub": not added`,
+		},
+		"duplicate": {
+			build: func(b *PrepareResultBuilder) {
+				b.AddDevice(device0).AddDevice(device0).AddDevice(device1)
+			},
+			expectErrString: `invalid prepare result for claim default/claim: device pool/dev-0 for request "req-0": added more than once`,
+		},
+		"other-driver": {
+			build: func(b *PrepareResultBuilder) {
+				b.AddDevice(device0).AddDevice(device1).AddDevice(otherDevice)
+			},
+			expectErrString: `invalid prepare result for claim default/claim: device pool/dev-2 for request "req-2": allocated by driver "other.example.com", not "driver.example.com"`,
+		},
+		"not-allocated": {
+			build: func(b *PrepareResultBuilder) {
+				device := device0
+				device.Device = "dev-3"
+				b.AddDevice(device0).AddDevice(device1).AddDevice(device)
+			},
+			expectErrString: `invalid prepare result for claim default/claim: device pool/dev-3 for request "req-0": not allocated for the claim`,
+		},
+		"bad-cdi-id": {
+			build: func(b *PrepareResultBuilder) {
+				b.AddDevice(device0, "dev-0").AddDevice(device1)
+			},
+			expectErrString: `invalid prepare result for claim default/claim: device pool/dev-0 for request "req-0": CDI device ID "dev-0": must have the form <vendor>/<class>=<name>
+device pool/dev-0 for request "req-0": not added`,
+		},
+		"no-allocation": {
+			claim:           &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim"}},
+			build:           func(b *PrepareResultBuilder) {},
+			expectErrString: `invalid prepare result for claim default/claim: claim not allocated`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			c := claim
+			if tc.claim != nil {
+				c = tc.claim
+			}
+			b := NewPrepareResultBuilder(driverName, c)
+			tc.build(b)
+			result := b.Build()
+			if tc.expectErrString != "" {
+				require.EqualError(t, result.Err, tc.expectErrString)
+				assert.Empty(t, result.Devices)
+				return
+			}
+			require.NoError(t, result.Err)
+			assert.Equal(t, tc.expectDevices, result.Devices)
+		})
+	}
+}
+
+func TestGRPCError(t *testing.T) {
+	gr := schema.GroupResource{Group: "resource.k8s.io", Resource: "resourceclaims"}
+	for name, tc := range map[string]struct {
+		err        error
+		expectCode codes.Code
+	}{
+		"plain":        {err: errors.New("fake error"), expectCode: codes.Unknown},
+		"canceled":     {err: fmt.Errorf("wrapped: %w", context.Canceled), expectCode: codes.Canceled},
+		"deadline":     {err: context.DeadlineExceeded, expectCode: codes.DeadlineExceeded},
+		"not-found":    {err: apierrors.NewNotFound(gr, "claim"), expectCode: codes.NotFound},
+		"forbidden":    {err: apierrors.NewForbidden(gr, "claim", errors.New("fake error")), expectCode: codes.PermissionDenied},
+		"unauthorized": {err: apierrors.NewUnauthorized("fake error"), expectCode: codes.Unauthenticated},
+		"throttled":    {err: apierrors.NewTooManyRequests("slow down", 1), expectCode: codes.Unavailable},
+		"status":       {err: fmt.Errorf("wrapped: %w", status.Error(codes.ResourceExhausted, "no more devices")), expectCode: codes.ResourceExhausted},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := grpcError(tc.err, "prepare")
+			assert.Equal(t, tc.expectCode, status.Code(err))
+			assert.Contains(t, err.Error(), "prepare: ")
+		})
+	}
+
+	assert.NoError(t, grpcError(nil, "prepare"))
+}
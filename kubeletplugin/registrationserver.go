@@ -29,7 +29,7 @@ type registrationServer struct {
 	driverName        string
 	draEndpointPath   string
 	supportedVersions []string
-	status            *registerapi.RegistrationStatus
+	status            atomic.Pointer[registerapi.RegistrationStatus]
 
 	getInfoError atomic.Pointer[error]
 
@@ -53,7 +53,7 @@ func (e *registrationServer) GetInfo(ctx context.Context, req *registerapi.InfoR
 
 // NotifyRegistrationStatus is the RPC invoked by plugin watcher.
 func (e *registrationServer) NotifyRegistrationStatus(ctx context.Context, status *registerapi.RegistrationStatus) (*registerapi.RegistrationStatusResponse, error) {
-	e.status = status
+	e.status.Store(status)
 	if !status.PluginRegistered {
 		return nil, fmt.Errorf("failed registration process: %+v", status.Error)
 	}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/dynamic-resource-allocation/resourceclaim"
+)
+
+// ResolvedClaim is a ResourceClaim which was checked by [ResolveClaim]
+// together with the parts of its allocation which are relevant
+// for one DRA driver.
+type ResolvedClaim struct {
+	// Claim is the complete ResourceClaim as retrieved from the apiserver.
+	Claim *resourceapi.ResourceClaim
+
+	// Devices are the allocated devices of the driver, in the order
+	// of the allocation result. There is at least one.
+	Devices []resourceapi.DeviceRequestAllocationResult
+
+	// Config contains the opaque configurations of the driver, in the
+	// order of the allocation result. Configurations from the class
+	// come before those from the claim, so later entries take
+	// precedence. Use [ResolvedClaim.ConfigForDevice] to get the
+	// configurations of a specific device.
+	Config []resourceapi.DeviceAllocationConfiguration
+}
+
+// ConfigForDevice returns those entries from Config which apply to the
+// request of the device.
+func (c *ResolvedClaim) ConfigForDevice(device resourceapi.DeviceRequestAllocationResult) []resourceapi.DeviceAllocationConfiguration {
+	return resourceclaim.ConfigForResult(c.Config, device)
+}
+
+// ResolveClaim checks that the claim is allocated, that the allocation is
+// usable on the node and that it contains devices of the driver. The node
+// is only checked if the allocation has a node selector and the node is
+// not nil.
+func ResolveClaim(claim *resourceapi.ResourceClaim, driverName string, node *v1.Node) (*ResolvedClaim, error) {
+	allocation := claim.Status.Allocation
+	if allocation == nil {
+		return nil, fmt.Errorf("claim %s/%s not allocated", claim.Namespace, claim.Name)
+	}
+	if allocation.NodeSelector != nil && node != nil {
+		selector, err := nodeaffinity.NewNodeSelector(allocation.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("claim %s/%s: parse node selector of allocation: %w", claim.Namespace, claim.Name, err)
+		}
+		if !selector.Match(node) {
+			return nil, fmt.Errorf("claim %s/%s: allocated for some other node than %s", claim.Namespace, claim.Name, node.Name)
+		}
+	}
+
+	resolved := &ResolvedClaim{Claim: claim}
+	for _, device := range allocation.Devices.Results {
+		if device.Driver == driverName {
+			resolved.Devices = append(resolved.Devices, device)
+		}
+	}
+	if len(resolved.Devices) == 0 {
+		return nil, fmt.Errorf("claim %s/%s: no devices allocated by driver %s", claim.Namespace, claim.Name, driverName)
+	}
+	for _, config := range allocation.Devices.Config {
+		if config.Opaque != nil && config.Opaque.Driver == driverName {
+			resolved.Config = append(resolved.Config, config)
+		}
+	}
+	slices.SortStableFunc(resolved.Config, func(a, b resourceapi.DeviceAllocationConfiguration) int {
+		return configSourceOrder(a.Source) - configSourceOrder(b.Source)
+	})
+	return resolved, nil
+}
+
+func configSourceOrder(source resourceapi.AllocationConfigSource) int {
+	if source == resourceapi.AllocationConfigSourceClass {
+		return 0
+	}
+	return 1
+}
+
+// ResolveClaims retrieves the claims, for example those of a
+// NodePrepareResources request, and checks them with [ResolveClaim].
+// The UID of each retrieved claim must match the UID in the reference,
+// if one is set. The node is retrieved if [NodeName] was set.
+//
+// The result has one entry per reference. Errors are reported per claim,
+// using the same index. The overall error is only set when retrieving
+// the node failed.
+func (d *Helper) ResolveClaims(ctx context.Context, claims []NamespacedObject) ([]*ResolvedClaim, []error, error) {
+	var node *v1.Node
+	if d.nodeName != "" {
+		var err error
+		node, err = d.kubeClient.CoreV1().Nodes().Get(ctx, d.nodeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("retrieve node %s: %w", d.nodeName, err)
+		}
+	}
+
+	resolved := make([]*ResolvedClaim, len(claims))
+	errs := make([]error, len(claims))
+	for i, ref := range claims {
+		claim, err := d.resourceClient.ResourceClaims(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			errs[i] = fmt.Errorf("retrieve claim %s/%s: %w", ref.Namespace, ref.Name, err)
+			continue
+		}
+		if ref.UID != "" && claim.UID != ref.UID {
+			errs[i] = fmt.Errorf("claim %s/%s got replaced", ref.Namespace, ref.Name)
+			continue
+		}
+		resolved[i], errs[i] = ResolveClaim(claim, d.driverName, node)
+	}
+	if errors.Join(errs...) == nil {
+		errs = nil
+	}
+	return resolved, errs, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestResolveClaims(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	const driverName = "driver.example.com"
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker", Labels: map[string]string{"rack": "a"}}}
+	nodeSelector := func(rack string) *v1.NodeSelector {
+		return &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+			MatchExpressions: []v1.NodeSelectorRequirement{{Key: "rack", Operator: v1.NodeSelectorOpIn, Values: []string{rack}}},
+		}}}
+	}
+	ownDevice := resourceapi.DeviceRequestAllocationResult{Request: "req/sub", Driver: driverName, Pool: "pool", Device: "dev-0"}
+	otherDevice := resourceapi.DeviceRequestAllocationResult{Request: "req", Driver: "other.example.com", Pool: "pool", Device: "dev-0"}
+	classConfig := resourceapi.DeviceAllocationConfiguration{
+		Source:              resourceapi.AllocationConfigSourceClass,
+		DeviceConfiguration: resourceapi.DeviceConfiguration{Opaque: &resourceapi.OpaqueDeviceConfiguration{Driver: driverName}},
+	}
+	claimConfig := resourceapi.DeviceAllocationConfiguration{
+		Source:              resourceapi.AllocationConfigSourceClaim,
+		Requests:            []string{"req"},
+		DeviceConfiguration: resourceapi.DeviceConfiguration{Opaque: &resourceapi.OpaqueDeviceConfiguration{Driver: driverName}},
+	}
+	otherConfig := resourceapi.DeviceAllocationConfiguration{
+		Source:              resourceapi.AllocationConfigSourceClaim,
+		Requests:            []string{"other"},
+		DeviceConfiguration: resourceapi.DeviceConfiguration{Opaque: &resourceapi.OpaqueDeviceConfiguration{Driver: driverName}},
+	}
+	newClaim := func(name string, allocation *resourceapi.AllocationResult) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+			Status:     resourceapi.ResourceClaimStatus{Allocation: allocation},
+		}
+	}
+	good := newClaim("good", &resourceapi.AllocationResult{
+		NodeSelector: nodeSelector("a"),
+		Devices: resourceapi.DeviceAllocationResult{
+			Results: []resourceapi.DeviceRequestAllocationResult{otherDevice, ownDevice},
+			Config:  []resourceapi.DeviceAllocationConfiguration{claimConfig, otherConfig, classConfig},
+		},
+	})
+	otherNode := newClaim("other-node", &resourceapi.AllocationResult{
+		NodeSelector: nodeSelector("b"),
+		Devices:      resourceapi.DeviceAllocationResult{Results: []resourceapi.DeviceRequestAllocationResult{ownDevice}},
+	})
+	otherDriver := newClaim("other-driver", &resourceapi.AllocationResult{
+		Devices: resourceapi.DeviceAllocationResult{Results: []resourceapi.DeviceRequestAllocationResult{otherDevice}},
+	})
+	unallocated := newClaim("unallocated", nil)
+
+	helper, err := Start(ctx, &testPlugin{t: t},
+		DriverName(driverName),
+		KubeClient(fake.NewClientset([]runtime.Object{node, good, otherNode, otherDriver, unallocated}...)),
+		NodeName(node.Name),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	ref := func(claim *resourceapi.ResourceClaim) NamespacedObject {
+		return NamespacedObject{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}
+	}
+	replaced := ref(good)
+	replaced.UID = "old-uid"
+	missing := NamespacedObject{NamespacedName: types.NamespacedName{Namespace: "default", Name: "missing"}}
+
+	resolved, errs, err := helper.ResolveClaims(ctx, []NamespacedObject{ref(good), ref(otherNode), ref(otherDriver), ref(unallocated), replaced, missing})
+	require.NoError(t, err, "resolve")
+	require.Len(t, resolved, 6)
+	require.Len(t, errs, 6)
+
+	require.NoError(t, errs[0], "good claim")
+	assert.Equal(t, &ResolvedClaim{
+		Claim:   good,
+		Devices: []resourceapi.DeviceRequestAllocationResult{ownDevice},
+		Config:  []resourceapi.DeviceAllocationConfiguration{classConfig, claimConfig, otherConfig},
+	}, resolved[0], "good claim")
+	assert.Equal(t, []resourceapi.DeviceAllocationConfiguration{classConfig, claimConfig}, resolved[0].ConfigForDevice(ownDevice), "config for device")
+
+	assert.EqualError(t, errs[1], "claim default/other-node: allocated for some other node than worker")
+	assert.EqualError(t, errs[2], "claim default/other-driver: no devices allocated by driver driver.example.com")
+	assert.EqualError(t, errs[3], "claim default/unallocated not allocated")
+	assert.EqualError(t, errs[4], "claim default/good got replaced")
+	assert.EqualError(t, errs[5], `retrieve claim default/missing: resourceclaims.resource.k8s.io "missing" not found`)
+	for i := 1; i < len(resolved); i++ {
+		assert.Nil(t, resolved[i], "resolved claim #%d", i)
+	}
+
+	resolved, errs, err = helper.ResolveClaims(ctx, []NamespacedObject{ref(good)})
+	require.NoError(t, err, "resolve good claim")
+	assert.Nil(t, errs, "no errors")
+	assert.Len(t, resolved, 1)
+}
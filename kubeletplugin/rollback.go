@@ -0,0 +1,242 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// RollbackFailedPrepare controls whether the helper calls
+// [DRAPlugin.UnprepareResourceClaims] for claims where
+// [DRAPlugin.PrepareResourceClaims] reported a failure. Off by default.
+//
+// A DRA driver might have prepared some devices of a claim before
+// running into a problem with another device. With rollback
+// enabled, the driver doesn't need to undo that itself: the helper
+// unprepares the claim before responding to the kubelet, so the
+// next attempt starts from a consistent state.
+//
+// The attempt is recorded in a checkpoint file in the plugin data
+// directory (see [PluginDataDirectoryPath]) until unpreparing
+// succeeds, either as part of the rollback, in a later
+// NodeUnprepareResources call, or when [Start] tries again for
+// rollbacks which had failed before a restart. Preparing the claim
+// successfully also removes the record.
+func RollbackFailedPrepare(enabled bool) Option {
+	return func(o *options) error {
+		o.rollbackFailedPrepare = enabled
+		return nil
+	}
+}
+
+// rollbacks is the in-memory copy of the rollbacks in the checkpoint.
+type rollbacks struct {
+	mutex  sync.Mutex
+	claims map[types.UID]rollbackEntry
+}
+
+// loadRollbacks initializes the rollbacks from the checkpoint.
+func loadRollbacks(checkpoints *checkpointStore) (*rollbacks, error) {
+	c, err := checkpoints.get()
+	if err != nil {
+		return nil, err
+	}
+	r := &rollbacks{claims: c.Rollbacks}
+	if r.claims == nil {
+		r.claims = make(map[types.UID]rollbackEntry)
+	}
+	return r, nil
+}
+
+// rollbackFailedClaims unprepares all claims which failed to prepare and
+// updates their error in the result accordingly. Claims which were
+// prepared successfully no longer need an earlier rollback.
+func (d *Helper) rollbackFailedClaims(ctx context.Context, claims []*resourceapi.ResourceClaim, result map[types.UID]PrepareResult) {
+	if d.rollbacks == nil {
+		return
+	}
+	logger := klog.FromContext(ctx)
+
+	var failed []NamespacedObject
+	var prepared []types.UID
+	for _, claim := range claims {
+		claimResult, ok := result[claim.UID]
+		switch {
+		case !ok:
+			// Not possible after completePrepareResult.
+		case claimResult.Err != nil:
+			failed = append(failed, NamespacedObject{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}})
+		default:
+			prepared = append(prepared, claim.UID)
+		}
+	}
+	d.forgetRollbacks(ctx, prepared)
+	if len(failed) == 0 {
+		return
+	}
+
+	d.rollbacks.mutex.Lock()
+	defer d.rollbacks.mutex.Unlock()
+
+	// Record before doing anything, in case that we get killed in the middle of it.
+	now := metav1.Now()
+	for _, claim := range failed {
+		d.rollbacks.claims[claim.UID] = rollbackEntry{
+			Namespace:    claim.Namespace,
+			Name:         claim.Name,
+			Time:         now,
+			PrepareError: result[claim.UID].Err.Error(),
+		}
+	}
+	d.writeRollbacksLocked(ctx)
+
+	logger.V(3).Info("Rolling back failed claims", "claims", failed)
+	start := time.Now()
+	unprepareResult, err := d.plugin.UnprepareResourceClaims(ctx, failed)
+	logger.V(3).Info("Rolled back failed claims", "claims", failed, "duration", time.Since(start), "err", err)
+
+	rollbackErrs := d.updateRollbacksLocked(failed, unprepareResult, err)
+	d.writeRollbacksLocked(ctx)
+	for _, claim := range failed {
+		prepareErr := result[claim.UID].Err
+		if rollbackErr := rollbackErrs[claim.UID]; rollbackErr != nil {
+			result[claim.UID] = PrepareResult{Err: fmt.Errorf("%w; rolling back also failed: %w", prepareErr, rollbackErr)}
+		} else {
+			result[claim.UID] = PrepareResult{Err: fmt.Errorf("%w (rolled back)", prepareErr)}
+		}
+	}
+}
+
+// replayRollbacks tries again to unprepare the claims for which
+// rolling back had failed before a restart. It must be called before
+// the kubelet can call the helper.
+func (d *Helper) replayRollbacks(ctx context.Context) {
+	if d.rollbacks == nil {
+		return
+	}
+	logger := klog.FromContext(ctx)
+	unlock, err := d.serializeGRPCIfEnabled()
+	if err != nil {
+		d.plugin.HandleError(ctx, recoverableError{error: err}, "serialize gRPC for replaying rollbacks")
+		return
+	}
+	defer unlock()
+
+	d.rollbacks.mutex.Lock()
+	defer d.rollbacks.mutex.Unlock()
+
+	var claims []NamespacedObject
+	for uid, entry := range d.rollbacks.claims {
+		claims = append(claims, NamespacedObject{UID: uid, NamespacedName: types.NamespacedName{Namespace: entry.Namespace, Name: entry.Name}})
+	}
+	if len(claims) == 0 {
+		return
+	}
+
+	logger.V(3).Info("Replaying rollbacks", "claims", claims)
+	start := time.Now()
+	result, err := d.plugin.UnprepareResourceClaims(ctx, claims)
+	logger.V(3).Info("Replayed rollbacks", "claims", claims, "duration", time.Since(start), "err", err)
+
+	for uid, rollbackErr := range d.updateRollbacksLocked(claims, result, err) {
+		if rollbackErr != nil {
+			d.plugin.HandleError(ctx, recoverableError{error: rollbackErr}, fmt.Sprintf("replaying rollback of claim %s", uid))
+		}
+	}
+	d.writeRollbacksLocked(ctx)
+}
+
+// updateRollbacksLocked removes the entries of all claims which were
+// unprepared successfully and stores the error for the others.
+// It returns the error for each claim, nil if successful.
+func (d *Helper) updateRollbacksLocked(claims []NamespacedObject, result map[types.UID]error, err error) map[types.UID]error {
+	claimErrs := make(map[types.UID]error, len(claims))
+	for _, claim := range claims {
+		claimErr := err
+		if claimErr == nil {
+			var ok bool
+			claimErr, ok = result[claim.UID]
+			if !ok {
+				claimErr = errors.New("no result from driver")
+			}
+		}
+		claimErrs[claim.UID] = claimErr
+		entry, ok := d.rollbacks.claims[claim.UID]
+		switch {
+		case !ok:
+			// Nothing to do.
+		case claimErr == nil:
+			delete(d.rollbacks.claims, claim.UID)
+		default:
+			entry.UnprepareError = claimErr.Error()
+			d.rollbacks.claims[claim.UID] = entry
+		}
+	}
+	return claimErrs
+}
+
+// forgetRollbacks removes the entries of claims which don't need to be
+// rolled back anymore because they were prepared or unprepared
+// successfully since then.
+func (d *Helper) forgetRollbacks(ctx context.Context, uids []types.UID) {
+	if d.rollbacks == nil {
+		return
+	}
+	d.rollbacks.mutex.Lock()
+	defer d.rollbacks.mutex.Unlock()
+
+	changed := false
+	for _, uid := range uids {
+		if _, ok := d.rollbacks.claims[uid]; ok {
+			delete(d.rollbacks.claims, uid)
+			changed = true
+		}
+	}
+	if changed {
+		d.writeRollbacksLocked(ctx)
+	}
+}
+
+// unpreparedUIDs returns the claims which were unprepared successfully.
+func unpreparedUIDs(result map[types.UID]error) []types.UID {
+	var uids []types.UID
+	for uid, err := range result {
+		if err == nil {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+func (d *Helper) writeRollbacksLocked(ctx context.Context) {
+	claims := maps.Clone(d.rollbacks.claims)
+	if err := d.checkpoints.update(func(c *checkpoint) {
+		c.Rollbacks = claims
+	}); err != nil {
+		d.plugin.HandleError(ctx, recoverableError{error: err}, "recording rollback")
+	}
+}
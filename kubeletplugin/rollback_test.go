@@ -0,0 +1,220 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin/fakekubelet"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestRollbackFailedPrepare(t *testing.T) {
+	driverName := "driver.example.com"
+	newClaim := func(name string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+			Status: resourceapi.ResourceClaimStatus{
+				Allocation: &resourceapi.AllocationResult{},
+			},
+		}
+	}
+	goodClaim := newClaim("good")
+	badClaim := newClaim("bad")
+
+	testcases := map[string]struct {
+		disabled          bool
+		unprepareErr      error
+		expectError       string
+		expectUnprepared  []types.UID
+		expectCheckpoints map[types.UID]rollbackEntry
+	}{
+		"disabled": {
+			disabled:    true,
+			expectError: "fake prepare error",
+		},
+		"rolled-back": {
+			expectError:      "fake prepare error (rolled back)",
+			expectUnprepared: []types.UID{badClaim.UID},
+		},
+		"rollback-failed": {
+			unprepareErr:     errors.New("fake unprepare error"),
+			expectError:      "fake prepare error; rolling back also failed: fake unprepare error",
+			expectUnprepared: []types.UID{badClaim.UID},
+			expectCheckpoints: map[types.UID]rollbackEntry{
+				badClaim.UID: {
+					Namespace:      badClaim.Namespace,
+					Name:           badClaim.Name,
+					PrepareError:   "fake prepare error",
+					UnprepareError: "fake unprepare error",
+				},
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			tempDir := t.TempDir()
+			plugin := &testPlugin{
+				t:            t,
+				prepareErr:   map[types.UID]error{badClaim.UID: errors.New("fake prepare error")},
+				unprepareErr: map[types.UID]error{badClaim.UID: tc.unprepareErr},
+			}
+			helper, err := Start(ctx, plugin,
+				DriverName(driverName),
+				KubeClient(fake.NewClientset(goodClaim, badClaim)),
+				PluginDataDirectoryPath(tempDir),
+				RegistrarDirectoryPath(tempDir),
+				RollbackFailedPrepare(!tc.disabled),
+			)
+			require.NoError(t, err, "start")
+			defer helper.Stop()
+
+			kubelet, err := fakekubelet.Register(ctx, path.Join(tempDir, driverName+"-reg.sock"))
+			require.NoError(t, err, "register")
+			defer kubelet.Close()
+
+			resp, err := kubelet.NodePrepareResources(ctx, goodClaim, badClaim)
+			require.NoError(t, err, "prepare")
+			assert.Empty(t, resp.Claims[string(goodClaim.UID)].Error, "good claim")
+			assert.Equal(t, tc.expectError, resp.Claims[string(badClaim.UID)].Error, "bad claim")
+			assert.Equal(t, tc.expectUnprepared, plugin.getUnprepared(), "unprepared claims")
+
+			c, err := helper.checkpoints.get()
+			require.NoError(t, err, "get checkpoint")
+			for uid, entry := range c.Rollbacks {
+				assert.False(t, entry.Time.IsZero(), "time of rollback")
+				entry.Time = metav1.Time{}
+				c.Rollbacks[uid] = entry
+			}
+			assert.Equal(t, tc.expectCheckpoints, c.Rollbacks, "rollbacks in checkpoint")
+		})
+	}
+}
+
+func TestRollbackAfterRestart(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+	start := func(plugin *testPlugin) *Helper {
+		helper, err := Start(ctx, plugin,
+			DriverName("driver.example.com"),
+			KubeClient(fake.NewClientset()),
+			PluginDataDirectoryPath(tempDir),
+			RegistrarDirectoryPath(tempDir),
+			Standalone(),
+			RollbackFailedPrepare(true),
+		)
+		require.NoError(t, err, "start")
+		return helper
+	}
+	getRollbacks := func(helper *Helper) map[types.UID]rollbackEntry {
+		c, err := helper.checkpoints.get()
+		require.NoError(t, err, "get checkpoint")
+		return c.Rollbacks
+	}
+
+	// Rolling back fails, so the entry remains.
+	plugin := &testPlugin{
+		t:            t,
+		prepareErr:   map[types.UID]error{claim.UID: errors.New("fake prepare error")},
+		unprepareErr: map[types.UID]error{claim.UID: errors.New("fake unprepare error")},
+	}
+	helper := start(plugin)
+	result, err := helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err, "prepare")
+	require.EqualError(t, result[claim.UID].Err, "fake prepare error; rolling back also failed: fake unprepare error", "prepare result")
+	assert.Contains(t, getRollbacks(helper), claim.UID, "rollbacks before restart")
+	helper.Stop()
+
+	// The new instance unprepares again while starting.
+	plugin = &testPlugin{t: t}
+	helper = start(plugin)
+	defer helper.Stop()
+	assert.Equal(t, []types.UID{claim.UID}, plugin.getUnprepared(), "unprepared claims after restart")
+	assert.Empty(t, getRollbacks(helper), "rollbacks after restart")
+}
+
+func TestRollbackForgotten(t *testing.T) {
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+	claimRef := NamespacedObject{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}
+
+	testcases := map[string]func(ctx context.Context, t *testing.T, helper *Helper){
+		"unprepared": func(ctx context.Context, t *testing.T, helper *Helper) {
+			result, err := helper.UnprepareClaims(ctx, []NamespacedObject{claimRef})
+			require.NoError(t, err, "unprepare")
+			require.NoError(t, result[claim.UID], "unprepare result")
+		},
+		"prepared": func(ctx context.Context, t *testing.T, helper *Helper) {
+			result, err := helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err, "prepare")
+			require.NoError(t, result[claim.UID].Err, "prepare result")
+		},
+	}
+
+	for name, succeed := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			plugin := &testPlugin{
+				t:            t,
+				prepareErr:   map[types.UID]error{claim.UID: errors.New("fake prepare error")},
+				unprepareErr: map[types.UID]error{claim.UID: errors.New("fake unprepare error")},
+			}
+			helper, err := Start(ctx, plugin,
+				DriverName("driver.example.com"),
+				KubeClient(fake.NewClientset()),
+				PluginDataDirectoryPath(t.TempDir()),
+				RegistrarDirectoryPath(t.TempDir()),
+				Standalone(),
+				RollbackFailedPrepare(true),
+			)
+			require.NoError(t, err, "start")
+			defer helper.Stop()
+
+			_, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err, "failed prepare")
+
+			// The driver recovers.
+			plugin.mutex.Lock()
+			plugin.prepareErr = nil
+			plugin.unprepareErr = nil
+			plugin.mutex.Unlock()
+			succeed(ctx, t, helper)
+
+			c, err := helper.checkpoints.get()
+			require.NoError(t, err, "get checkpoint")
+			assert.Empty(t, c.Rollbacks, "rollbacks in checkpoint")
+		})
+	}
+}
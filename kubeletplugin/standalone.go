@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Standalone disables the registration and DRA gRPC services. It is a
+// shorthand for [RegistrationService] and [DRAService] with false.
+//
+// This is meant for environments without a real kubelet, for example
+// virtual-kubelet providers. Such a caller invokes [Helper.PrepareClaims]
+// and [Helper.UnprepareClaims] directly instead of going through gRPC and
+// gets the same processing of the calls as the kubelet, including
+// serialization, admission and rollback. Publishing ResourceSlices works
+// as usual.
+func Standalone() Option {
+	return func(o *options) error {
+		o.registrationService = false
+		o.draService = false
+		return nil
+	}
+}
+
+// PrepareClaims is what the helper does for a NodePrepareResources gRPC call
+// after retrieving the claims. It may be called directly, typically in
+// combination with [Standalone].
+//
+// All claims must be allocated. The result has the same semantic as
+// the one from [DRAPlugin.PrepareResourceClaims], except that it also has
+// entries for claims which got denied by [PrepareAdmission] and for
+// claims which were prepared before (see [CachePrepareResults]).
+// [DRAPlugin.PrepareResourceClaims] is not called when no claims
+// are left to prepare.
+func (d *Helper) PrepareClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) (map[types.UID]PrepareResult, error) {
+	for _, claim := range claims {
+		if claim.Status.Allocation == nil {
+			return nil, fmt.Errorf("claim %s/%s not allocated", claim.Namespace, claim.Name)
+		}
+	}
+
+	unlock, err := d.serializeGRPCIfEnabled()
+	if err != nil {
+		return nil, fmt.Errorf("serialize gRPC: %w", err)
+	}
+	defer unlock()
+
+	// Waiting for the lock may have used up the time that the caller
+	// is willing to wait. Don't start preparing in that case.
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	if err := d.injectFault(ctx, faultPointPrepare); err != nil {
+		return nil, err
+	}
+
+	allClaims := claims
+	claims, cached := d.prepareCache.lookup(claims)
+	if len(claims) == 0 && len(cached) > 0 {
+		d.recordDeviceUsage(ctx, allClaims, cached)
+		return cached, nil
+	}
+
+	claims, denied := d.admitClaims(ctx, claims)
+	if len(claims) == 0 && len(denied) == 0 {
+		// Nothing to do, don't bother the driver with an empty batch.
+		return map[types.UID]PrepareResult{}, nil
+	}
+	var result map[types.UID]PrepareResult
+	if len(claims) > 0 {
+		stopWatching := d.watchSlowPrepare(claims)
+		result, err = d.plugin.PrepareResourceClaims(ctx, claims)
+		stopWatching()
+		if err != nil {
+			return nil, err
+		}
+		result = completePrepareResult(claims, result)
+		d.verifyPreparedClaims(ctx, claims, result)
+		d.rollbackFailedClaims(ctx, claims, result)
+		d.prepareCache.store(claims, result)
+	}
+	if result == nil {
+		result = make(map[types.UID]PrepareResult, len(denied)+len(cached))
+	}
+	for uid, err := range denied {
+		result[uid] = PrepareResult{Err: err}
+	}
+	for uid, claimResult := range cached {
+		result[uid] = claimResult
+	}
+	d.trackPrepared(claims, result)
+	d.recordDeviceUsage(ctx, allClaims, result)
+	return result, nil
+}
+
+// completePrepareResult turns a missing result for a claim into an error
+// for that claim. A DRA driver is supposed to return a result for each
+// claim, but if it doesn't, the kubelet should retry instead of assuming
+// that the claim was prepared.
+func completePrepareResult(claims []*resourceapi.ResourceClaim, result map[types.UID]PrepareResult) map[types.UID]PrepareResult {
+	if result == nil {
+		result = make(map[types.UID]PrepareResult, len(claims))
+	}
+	for _, claim := range claims {
+		if _, ok := result[claim.UID]; !ok {
+			result[claim.UID] = PrepareResult{Err: fmt.Errorf("claim %s/%s: no result from DRA driver", claim.Namespace, claim.Name)}
+		}
+	}
+	return result
+}
+
+// UnprepareClaims is what the helper does for a NodeUnprepareResources gRPC
+// call. It may be called directly, typically in combination with [Standalone].
+func (d *Helper) UnprepareClaims(ctx context.Context, claims []NamespacedObject) (map[types.UID]error, error) {
+	unlock, err := d.serializeGRPCIfEnabled()
+	if err != nil {
+		return nil, fmt.Errorf("serialize gRPC: %w", err)
+	}
+	defer unlock()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	if err := d.injectFault(ctx, faultPointUnprepare); err != nil {
+		return nil, err
+	}
+
+	// Whatever the outcome, the claims may no longer be prepared.
+	d.prepareCache.forget(claims)
+	result, err := d.plugin.UnprepareResourceClaims(ctx, claims)
+	if err == nil {
+		d.trackUnprepared(result)
+		d.forgetDeviceUsage(ctx, result)
+		d.forgetRollbacks(ctx, unpreparedUIDs(result))
+	}
+	d.recordFailedUnprepares(ctx, claims, result, err)
+	return result, err
+}
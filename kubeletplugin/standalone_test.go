@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestStandalone(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim", UID: "claim-uid"},
+		Status:     resourceapi.ResourceClaimStatus{Allocation: &resourceapi.AllocationResult{}},
+	}
+	unallocatedClaim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unallocated", UID: "unallocated-uid"},
+	}
+	plugin := &testPlugin{t: t, unprepareErr: map[types.UID]error{claim.UID: errors.New("fake error")}}
+
+	helper, err := Start(ctx, plugin,
+		DriverName("driver.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	entries, err := os.ReadDir(tempDir)
+	require.NoError(t, err, "read directory")
+	assert.Empty(t, entries, "no sockets")
+
+	_, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim, unallocatedClaim})
+	require.ErrorContains(t, err, "claim default/unallocated not allocated")
+	assert.Empty(t, plugin.getPrepared(), "prepared claims")
+
+	// The driver doesn't get called without claims.
+	result, err := helper.PrepareClaims(ctx, nil)
+	require.NoError(t, err, "prepare nothing")
+	assert.Empty(t, result, "prepare nothing result")
+	assert.Zero(t, plugin.prepareCalls, "driver calls")
+
+	result, err = helper.PrepareClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err, "prepare")
+	assert.Equal(t, map[types.UID]PrepareResult{claim.UID: {}}, result, "prepare result")
+	assert.Equal(t, []types.UID{claim.UID}, plugin.getPrepared(), "prepared claims")
+
+	unprepareResult, err := helper.UnprepareClaims(ctx, []NamespacedObject{{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}})
+	require.NoError(t, err, "unprepare")
+	assert.Equal(t, map[types.UID]error{claim.UID: errors.New("fake error")}, unprepareResult, "unprepare result")
+	assert.Equal(t, []types.UID{claim.UID}, plugin.getUnprepared(), "unprepared claims")
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// unprepareRetryInitialDelay is the delay after the first failure.
+	// It doubles after each failed attempt, up to unprepareRetryMaxDelay.
+	unprepareRetryInitialDelay = time.Second
+	unprepareRetryMaxDelay     = 5 * time.Minute
+)
+
+// RetryFailedUnprepare controls whether the helper keeps calling
+// [DRAPlugin.UnprepareResourceClaims] for claims where unpreparing failed
+// and which have been removed already. Off by default.
+//
+// The kubelet does not retry unpreparing for such claims, so without this
+// the devices remain prepared until the DRA driver cleans up by itself.
+// The pending claims are stored in a checkpoint file in the plugin data
+// directory (see [PluginDataDirectoryPath]), so retrying with exponential
+// backoff continues after a restart of the driver.
+//
+// The number of pending claims is exposed as the
+// dra_kubeletplugin_pending_unprepare_claims metric in the legacy
+// registry of k8s.io/component-base/metrics.
+func RetryFailedUnprepare(enabled bool) Option {
+	return func(o *options) error {
+		o.retryFailedUnprepare = enabled
+		return nil
+	}
+}
+
+// recordFailedUnprepares checks the outcome of unpreparing and updates
+// the pending entries accordingly. The lock for serializing gRPC calls
+// must be held.
+func (d *Helper) recordFailedUnprepares(ctx context.Context, claims []NamespacedObject, result map[types.UID]error, err error) {
+	if !d.retryFailedUnprepare {
+		return
+	}
+	logger := klog.FromContext(ctx)
+
+	now := metav1.Now()
+	failed := make(map[types.UID]pendingUnprepareEntry)
+	var succeeded []types.UID
+	for _, claim := range claims {
+		claimErr := err
+		if claimErr == nil {
+			var ok bool
+			claimErr, ok = result[claim.UID]
+			if !ok {
+				claimErr = errors.New("no result from driver")
+			}
+		}
+		if claimErr == nil {
+			succeeded = append(succeeded, claim.UID)
+			continue
+		}
+		gone, err := d.claimIsGone(ctx, claim)
+		if err != nil {
+			// The kubelet will retry if the claim still exists.
+			logger.Error(err, "Checking for removed claim failed, not retrying unprepare", "claim", claim)
+			continue
+		}
+		if !gone {
+			continue
+		}
+		failed[claim.UID] = pendingUnprepareEntry{
+			Namespace:      claim.Namespace,
+			Name:           claim.Name,
+			Time:           now,
+			Attempts:       1,
+			LastAttempt:    now,
+			UnprepareError: claimErr.Error(),
+		}
+	}
+
+	if err := d.checkpoints.update(func(c *checkpoint) {
+		for _, uid := range succeeded {
+			delete(c.PendingUnprepares, uid)
+		}
+		if len(failed) == 0 {
+			return
+		}
+		if c.PendingUnprepares == nil {
+			c.PendingUnprepares = make(map[types.UID]pendingUnprepareEntry)
+		}
+		for uid, entry := range failed {
+			if oldEntry, ok := c.PendingUnprepares[uid]; ok {
+				entry.Time = oldEntry.Time
+				entry.Attempts += oldEntry.Attempts
+			}
+			c.PendingUnprepares[uid] = entry
+		}
+	}); err != nil {
+		d.plugin.HandleError(ctx, recoverableError{error: err}, "recording failed unprepare")
+		return
+	}
+	if len(failed) > 0 {
+		logger.V(3).Info("Unpreparing removed claims failed, will retry", "claims", failed)
+		d.triggerUnprepareRetry()
+	}
+}
+
+// claimIsGone returns true if the claim does not exist anymore or got replaced.
+func (d *Helper) claimIsGone(ctx context.Context, claim NamespacedObject) (bool, error) {
+	actualClaim, err := d.resourceClient.ResourceClaims(claim.Namespace).Get(ctx, claim.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return true, nil
+	case err != nil:
+		return false, err
+	default:
+		return actualClaim.UID != claim.UID, nil
+	}
+}
+
+func (d *Helper) triggerUnprepareRetry() {
+	select {
+	case d.unprepareRetryTrigger <- struct{}{}:
+	default:
+	}
+}
+
+// retryPendingUnprepares runs until the context is canceled.
+func (d *Helper) retryPendingUnprepares(ctx context.Context) {
+	registerMetrics()
+	pendingClaims := pendingUnprepareClaims.WithLabelValues(d.driverName)
+	defer pendingUnprepareClaims.DeleteLabelValues(d.driverName)
+
+	for {
+		c, err := d.checkpoints.get()
+		if err != nil {
+			d.plugin.HandleError(ctx, recoverableError{error: err}, "reading pending unprepares")
+			c = &checkpoint{}
+		}
+		pendingClaims.Set(float64(len(c.PendingUnprepares)))
+
+		var next <-chan time.Time
+		switch {
+		case err != nil:
+			next = time.After(unprepareRetryMaxDelay)
+		case len(c.PendingUnprepares) > 0:
+			now := time.Now()
+			delay := unprepareRetryMaxDelay
+			for _, entry := range c.PendingUnprepares {
+				delay = min(delay, entry.LastAttempt.Add(unprepareRetryDelay(entry.Attempts)).Sub(now))
+			}
+			next = time.After(delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.unprepareRetryTrigger:
+			continue
+		case <-next:
+			d.retryDueUnprepares(ctx)
+		}
+	}
+}
+
+// unprepareRetryDelay returns the delay after the given number of failed attempts.
+func unprepareRetryDelay(attempts int) time.Duration {
+	delay := unprepareRetryInitialDelay
+	for i := 1; i < attempts && delay < unprepareRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, unprepareRetryMaxDelay)
+}
+
+func (d *Helper) retryDueUnprepares(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	unlock, err := d.serializeGRPCIfEnabled()
+	if err != nil {
+		d.plugin.HandleError(ctx, recoverableError{error: err}, "serialize gRPC for retrying unprepare")
+		return
+	}
+	defer unlock()
+
+	c, err := d.checkpoints.get()
+	if err != nil {
+		d.plugin.HandleError(ctx, recoverableError{error: err}, "reading pending unprepares")
+		return
+	}
+	now := time.Now()
+	var claims []NamespacedObject
+	for uid, entry := range c.PendingUnprepares {
+		if entry.LastAttempt.Add(unprepareRetryDelay(entry.Attempts)).After(now) {
+			continue
+		}
+		claims = append(claims, NamespacedObject{UID: uid, NamespacedName: types.NamespacedName{Namespace: entry.Namespace, Name: entry.Name}})
+	}
+	if len(claims) == 0 {
+		return
+	}
+
+	logger.V(3).Info("Retrying unprepare of removed claims", "claims", claims)
+	start := time.Now()
+	result, err := d.plugin.UnprepareResourceClaims(ctx, claims)
+	logger.V(3).Info("Retried unprepare of removed claims", "claims", claims, "duration", time.Since(start), "err", err)
+	if err == nil {
+		d.forgetDeviceUsage(ctx, result)
+		d.forgetRollbacks(ctx, unpreparedUIDs(result))
+	}
+
+	lastAttempt := metav1.Now()
+	if err := d.checkpoints.update(func(c *checkpoint) {
+		for _, claim := range claims {
+			entry, ok := c.PendingUnprepares[claim.UID]
+			if !ok {
+				continue
+			}
+			claimErr := err
+			if claimErr == nil {
+				claimErr, ok = result[claim.UID]
+				if !ok {
+					claimErr = errors.New("no result from driver")
+				}
+			}
+			if claimErr == nil {
+				delete(c.PendingUnprepares, claim.UID)
+				continue
+			}
+			entry.Attempts++
+			entry.LastAttempt = lastAttempt
+			entry.UnprepareError = claimErr.Error()
+			c.PendingUnprepares[claim.UID] = entry
+		}
+	}); err != nil {
+		d.plugin.HandleError(ctx, recoverableError{error: err}, "recording pending unprepares")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeletplugin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestRetryFailedUnprepare(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	driverName := "retry.example.com"
+	existingClaim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "existing", UID: "existing-uid"},
+	}
+	removedClaim := NamespacedObject{UID: "removed-uid", NamespacedName: types.NamespacedName{Namespace: "default", Name: "removed"}}
+	plugin := &testPlugin{t: t, unprepareErr: map[types.UID]error{
+		existingClaim.UID: errors.New("fake error"),
+		removedClaim.UID:  errors.New("fake error"),
+	}}
+
+	helper, err := Start(ctx, plugin,
+		DriverName(driverName),
+		KubeClient(fake.NewClientset(existingClaim)),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		RetryFailedUnprepare(true),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	result, err := helper.UnprepareClaims(ctx, []NamespacedObject{
+		{UID: existingClaim.UID, NamespacedName: types.NamespacedName{Namespace: existingClaim.Namespace, Name: existingClaim.Name}},
+		removedClaim,
+	})
+	require.NoError(t, err, "unprepare")
+	assert.Len(t, result, 2, "unprepare result")
+
+	c, err := helper.checkpoints.get()
+	require.NoError(t, err, "get checkpoint")
+	require.Len(t, c.PendingUnprepares, 1, "only the removed claim is pending")
+	entry := c.PendingUnprepares[removedClaim.UID]
+	assert.Equal(t, removedClaim.Name, entry.Name, "name")
+	assert.Equal(t, "fake error", entry.UnprepareError, "error")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		value, err := testutil.GetGaugeMetricValue(pendingUnprepareClaims.WithLabelValues(driverName))
+		require.NoError(t, err, "get metric")
+		assert.Equal(t, 1.0, value, "pending claims")
+	}, 10*time.Second, 10*time.Millisecond)
+
+	plugin.mutex.Lock()
+	plugin.unprepareErr = nil
+	plugin.mutex.Unlock()
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		c, err := helper.checkpoints.get()
+		require.NoError(t, err, "get checkpoint")
+		assert.Empty(t, c.PendingUnprepares, "pending unprepares")
+		value, err := testutil.GetGaugeMetricValue(pendingUnprepareClaims.WithLabelValues(driverName))
+		require.NoError(t, err, "get metric")
+		assert.Equal(t, 0.0, value, "pending claims")
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []types.UID{existingClaim.UID, removedClaim.UID, removedClaim.UID}, plugin.getUnprepared(), "unprepared claims")
+}
+
+func TestRetryFailedUnprepareAfterRestart(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	tempDir := t.TempDir()
+	longAgo := metav1.NewTime(time.Now().Add(-time.Hour))
+	require.NoError(t, newCheckpointStore(tempDir).update(func(c *checkpoint) {
+		c.PendingUnprepares = map[types.UID]pendingUnprepareEntry{
+			"claim-uid": {Namespace: "default", Name: "claim", Time: longAgo, Attempts: 3, LastAttempt: longAgo, UnprepareError: "fake error"},
+		}
+	}), "write checkpoint")
+	plugin := &testPlugin{t: t}
+
+	helper, err := Start(ctx, plugin,
+		DriverName("restart.example.com"),
+		KubeClient(fake.NewClientset()),
+		PluginDataDirectoryPath(tempDir),
+		RegistrarDirectoryPath(tempDir),
+		Standalone(),
+		RetryFailedUnprepare(true),
+	)
+	require.NoError(t, err, "start")
+	defer helper.Stop()
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		c, err := helper.checkpoints.get()
+		require.NoError(t, err, "get checkpoint")
+		assert.Empty(t, c.PendingUnprepares, "pending unprepares")
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []types.UID{"claim-uid"}, plugin.getUnprepared(), "unprepared claims")
+}
+
+func TestUnprepareRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, unprepareRetryDelay(1))
+	assert.Equal(t, 2*time.Second, unprepareRetryDelay(2))
+	assert.Equal(t, 8*time.Second, unprepareRetryDelay(4))
+	assert.Equal(t, unprepareRetryMaxDelay, unprepareRetryDelay(100))
+}
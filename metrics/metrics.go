@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics registers the metrics of all packages in this module
+// with one call. All metrics are named "dra_<subsystem>_<name>", with one
+// subsystem per package:
+//
+//	allocator      k8s.io/dynamic-resource-allocation/structured
+//	cel            k8s.io/dynamic-resource-allocation/cel
+//	kubeletplugin  k8s.io/dynamic-resource-allocation/kubeletplugin
+//	resourceslice  k8s.io/dynamic-resource-allocation/resourceslice
+//	tracker        k8s.io/dynamic-resource-allocation/resourceslice/tracker
+//
+// Packages add their metrics in an init function, so only the metrics
+// of packages which are linked into a binary get registered. The metrics
+// only record values after they have been registered.
+package metrics
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// Namespace is the common prefix of all metrics.
+const Namespace = "dra"
+
+// Subsystems of the packages in this module.
+const (
+	SubsystemAllocator     = "allocator"
+	SubsystemCEL           = "cel"
+	SubsystemKubeletPlugin = "kubeletplugin"
+	SubsystemResourceSlice = "resourceslice"
+	SubsystemTracker       = "tracker"
+)
+
+// Registerer is the part of [metrics.KubeRegistry] which is needed by
+// [RegisterMetrics]. Implementations must be comparable because
+// registrations are tracked per Registerer.
+type Registerer interface {
+	Register(metrics.Registerable) error
+}
+
+// LegacyRegistry is the global legacy registry of
+// k8s.io/component-base/metrics, which is what Kubernetes
+// components expose by default.
+var LegacyRegistry Registerer = legacyRegistry{}
+
+type legacyRegistry struct{}
+
+func (legacyRegistry) Register(c metrics.Registerable) error {
+	return legacyregistry.Register(c)
+}
+
+var (
+	mutex sync.Mutex
+	// collectors contains the metrics of each subsystem.
+	collectors = make(map[string][]metrics.Registerable)
+	// registered tracks which subsystems were registered where.
+	registered = make(map[Registerer]map[string]bool)
+)
+
+// Add adds metrics for a subsystem. It gets called by the packages
+// of this module during their initialization.
+func Add(subsystem string, cs ...metrics.Registerable) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	collectors[subsystem] = append(collectors[subsystem], cs...)
+}
+
+// Option changes which metrics get registered by [RegisterMetrics].
+type Option func(*options)
+
+type options struct {
+	enabled  []string
+	disabled []string
+}
+
+// EnableSubsystems limits registration to the given subsystems.
+func EnableSubsystems(subsystems ...string) Option {
+	return func(o *options) {
+		o.enabled = append(o.enabled, subsystems...)
+	}
+}
+
+// DisableSubsystems prevents registration of the given subsystems.
+func DisableSubsystems(subsystems ...string) Option {
+	return func(o *options) {
+		o.disabled = append(o.disabled, subsystems...)
+	}
+}
+
+// RegisterMetrics registers the metrics of all subsystems, unless changed
+// through options. Calling it more than once for the same registerer is
+// okay, metrics which are already registered are skipped.
+func RegisterMetrics(registerer Registerer, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	done := registered[registerer]
+	if done == nil {
+		done = make(map[string]bool)
+		registered[registerer] = done
+	}
+	for _, subsystem := range slices.Sorted(maps.Keys(collectors)) {
+		if done[subsystem] ||
+			o.enabled != nil && !slices.Contains(o.enabled, subsystem) ||
+			slices.Contains(o.disabled, subsystem) {
+			continue
+		}
+		for _, c := range collectors[subsystem] {
+			if err := registerer.Register(c); err != nil {
+				return fmt.Errorf("register metrics of subsystem %s: %w", subsystem, err)
+			}
+		}
+		done[subsystem] = true
+	}
+	return nil
+}
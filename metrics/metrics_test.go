@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/component-base/metrics"
+)
+
+func newCounter(subsystem string) *metrics.Counter {
+	return metrics.NewCounter(&metrics.CounterOpts{
+		Namespace:      Namespace,
+		Subsystem:      subsystem,
+		Name:           "test_total",
+		Help:           "Test counter.",
+		StabilityLevel: metrics.ALPHA,
+	})
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	Add("test_a", newCounter("test_a"))
+	Add("test_b", newCounter("test_b"))
+	Add("test_c", newCounter("test_c"))
+
+	gathered := func(t *testing.T, registry metrics.KubeRegistry) []string {
+		t.Helper()
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		var names []string
+		for _, family := range families {
+			names = append(names, family.GetName())
+		}
+		return names
+	}
+	// Counters only get gathered once they have a value.
+	inc := func() {
+		for _, subsystem := range []string{"test_a", "test_b", "test_c"} {
+			for _, c := range collectors[subsystem] {
+				c.(*metrics.Counter).Inc()
+			}
+		}
+	}
+
+	registry := metrics.NewKubeRegistry()
+	require.NoError(t, RegisterMetrics(registry, EnableSubsystems("test_a", "test_b"), DisableSubsystems("test_b")))
+	inc()
+	assert.Equal(t, []string{"dra_test_a_test_total"}, gathered(t, registry))
+
+	require.NoError(t, RegisterMetrics(registry), "repeated registration")
+	inc()
+	assert.Equal(t, []string{"dra_test_a_test_total", "dra_test_b_test_total", "dra_test_c_test_total"}, gathered(t, registry))
+
+	other := metrics.NewKubeRegistry()
+	require.NoError(t, RegisterMetrics(other, DisableSubsystems("test_a")), "other registry")
+	assert.Equal(t, []string{"dra_test_b_test_total", "dra_test_c_test_total"}, gathered(t, other))
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CDIDeviceName returns the fully-qualified CDI device name for a device
+// which was allocated for a claim, in the form
+// "<driver>/<class>=<claim UID>-<pool>-<device>[-<share ID>]". The class
+// is chosen by the driver, for example "gpu".
+//
+// The name is unique for each claim and device, which is what the
+// kubelet plugin helper recommends to avoid stale entries in the CDI
+// cache of container runtimes. The share ID is included for devices
+// which are allocated more than once. Slashes in the pool name are
+// replaced with underscores because they are not valid in CDI names.
+//
+// Drivers which use this for the names in their CDI spec files and in
+// the result of NodePrepareResources, and tests which check that result,
+// thus agree on the names without having to exchange them.
+func CDIDeviceName(class string, claimUID types.UID, result resourceapi.DeviceRequestAllocationResult) string {
+	var name strings.Builder
+	name.WriteString(result.Driver)
+	name.WriteString("/")
+	name.WriteString(class)
+	name.WriteString("=")
+	name.WriteString(string(claimUID))
+	name.WriteString("-")
+	name.WriteString(strings.ReplaceAll(result.Pool, "/", "_"))
+	name.WriteString("-")
+	name.WriteString(result.Device)
+	if result.ShareID != nil {
+		name.WriteString("-")
+		name.WriteString(string(*result.ShareID))
+	}
+	return name.String()
+}
+
+// CDIDeviceNames returns the CDI device names of all devices in the
+// allocation of the claim which belong to the driver, in the same order
+// as the allocation results. The result is nil for unallocated claims.
+func CDIDeviceNames(class string, driverName string, claim *resourceapi.ResourceClaim) []string {
+	if claim.Status.Allocation == nil {
+		return nil
+	}
+	var names []string
+	for _, result := range claim.Status.Allocation.Devices.Results {
+		if result.Driver != driverName {
+			continue
+		}
+		names = append(names, CDIDeviceName(class, claim.UID, result))
+	}
+	return names
+}
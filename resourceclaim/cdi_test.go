@@ -0,0 +1,61 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func TestCDIDeviceName(t *testing.T) {
+	result := resourceapi.DeviceRequestAllocationResult{
+		Request: "req",
+		Driver:  "gpu.example.com",
+		Pool:    "node-1",
+		Device:  "gpu-0",
+	}
+	assert.Equal(t, "gpu.example.com/gpu=claim-uid-node-1-gpu-0", CDIDeviceName("gpu", "claim-uid", result))
+
+	result.Pool = "node-1/part-a"
+	result.ShareID = ptr.To(types.UID("share-uid"))
+	assert.Equal(t, "gpu.example.com/gpu=claim-uid-node-1_part-a-gpu-0-share-uid", CDIDeviceName("gpu", "claim-uid", result))
+}
+
+func TestCDIDeviceNames(t *testing.T) {
+	claim := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{UID: "claim-uid"}}
+	assert.Nil(t, CDIDeviceNames("gpu", "gpu.example.com", claim))
+
+	claim.Status.Allocation = &resourceapi.AllocationResult{
+		Devices: resourceapi.DeviceAllocationResult{
+			Results: []resourceapi.DeviceRequestAllocationResult{
+				{Request: "a", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-1"},
+				{Request: "b", Driver: "net.example.com", Pool: "node-1", Device: "eth0"},
+				{Request: "c", Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0"},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"gpu.example.com/gpu=claim-uid-node-1-gpu-1",
+		"gpu.example.com/gpu=claim-uid-node-1-gpu-0",
+	}, CDIDeviceNames("gpu", "gpu.example.com", claim))
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// Condition types which drivers may report for allocated devices
+// in the claim status.
+const (
+	// DeviceConditionReady is true when the device can be used by the consumers.
+	DeviceConditionReady = "Ready"
+	// DeviceConditionNetworkAttached is true when a network device
+	// was attached to the network of the pod.
+	DeviceConditionNetworkAttached = "NetworkAttached"
+	// DeviceConditionError is true when preparing or using the device
+	// failed. The reason and message describe the problem.
+	DeviceConditionError = "Error"
+)
+
+// DeviceStatusKey identifies the entry of an allocated device in
+// the Devices list of a claim status. ShareID is empty unless the
+// device is allocated multiple times.
+type DeviceStatusKey struct {
+	Driver  string
+	Pool    string
+	Device  string
+	ShareID string
+}
+
+// DeviceStatusKeyForResult returns the key for the device of an
+// allocation result.
+func DeviceStatusKeyForResult(result resourceapi.DeviceRequestAllocationResult) DeviceStatusKey {
+	return DeviceStatusKey{
+		Driver:  result.Driver,
+		Pool:    result.Pool,
+		Device:  result.Device,
+		ShareID: string(ptr.Deref(result.ShareID, "")),
+	}
+}
+
+func (k DeviceStatusKey) matches(status *resourceapi.AllocatedDeviceStatus) bool {
+	return status.Driver == k.Driver &&
+		status.Pool == k.Pool &&
+		status.Device == k.Device &&
+		ptr.Deref(status.ShareID, "") == k.ShareID
+}
+
+// FindDeviceStatus returns the status entry of the device, or nil if
+// there is none. The result points into the claim.
+func FindDeviceStatus(claim *resourceapi.ResourceClaim, key DeviceStatusKey) *resourceapi.AllocatedDeviceStatus {
+	for i := range claim.Status.Devices {
+		if key.matches(&claim.Status.Devices[i]) {
+			return &claim.Status.Devices[i]
+		}
+	}
+	return nil
+}
+
+// DeviceCondition returns the condition of the device, or nil if the
+// driver has not reported it.
+func DeviceCondition(claim *resourceapi.ResourceClaim, key DeviceStatusKey, conditionType string) *metav1.Condition {
+	status := FindDeviceStatus(claim, key)
+	if status == nil {
+		return nil
+	}
+	return meta.FindStatusCondition(status.Conditions, conditionType)
+}
+
+// IsDeviceConditionTrue checks whether the condition of the device is true
+// and was reported for the current generation of the claim.
+func IsDeviceConditionTrue(claim *resourceapi.ResourceClaim, key DeviceStatusKey, conditionType string) bool {
+	condition := DeviceCondition(claim, key, conditionType)
+	return condition != nil &&
+		condition.Status == metav1.ConditionTrue &&
+		condition.ObservedGeneration == claim.Generation
+}
+
+// SetDeviceCondition sets the condition of the device in the claim,
+// adding a status entry for the device if needed. Like
+// [meta.SetStatusCondition], LastTransitionTime only changes when the
+// status changes and defaults to the current time. ObservedGeneration is
+// always set to the generation of the claim. It returns true if the
+// claim was modified.
+//
+// The claim is modified in place, so callers which got it from an informer
+// must pass a copy.
+func SetDeviceCondition(claim *resourceapi.ResourceClaim, key DeviceStatusKey, condition metav1.Condition) bool {
+	status := FindDeviceStatus(claim, key)
+	if status == nil {
+		entry := resourceapi.AllocatedDeviceStatus{
+			Driver: key.Driver,
+			Pool:   key.Pool,
+			Device: key.Device,
+		}
+		if key.ShareID != "" {
+			entry.ShareID = ptr.To(key.ShareID)
+		}
+		claim.Status.Devices = append(claim.Status.Devices, entry)
+		status = &claim.Status.Devices[len(claim.Status.Devices)-1]
+	}
+	condition.ObservedGeneration = claim.Generation
+	return meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// RemoveDeviceCondition removes the condition of the device. It returns
+// true if the claim was modified. The status entry of the device is kept
+// because it may contain additional data.
+func RemoveDeviceCondition(claim *resourceapi.ResourceClaim, key DeviceStatusKey, conditionType string) bool {
+	status := FindDeviceStatus(claim, key)
+	if status == nil {
+		return false
+	}
+	return meta.RemoveStatusCondition(&status.Conditions, conditionType)
+}
+
+// SetDeviceReady sets the Ready condition of the device. A device which is
+// ready has no Error condition, so that condition gets removed.
+func SetDeviceReady(claim *resourceapi.ResourceClaim, key DeviceStatusKey, reason, message string) bool {
+	changed := SetDeviceCondition(claim, key, metav1.Condition{
+		Type:    DeviceConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	if RemoveDeviceCondition(claim, key, DeviceConditionError) {
+		changed = true
+	}
+	return changed
+}
+
+// SetDeviceError sets the Error condition of the device with the reason
+// and message. The device is not ready anymore.
+func SetDeviceError(claim *resourceapi.ResourceClaim, key DeviceStatusKey, reason, message string) bool {
+	changed := SetDeviceCondition(claim, key, metav1.Condition{
+		Type:    DeviceConditionError,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	if SetDeviceCondition(claim, key, metav1.Condition{
+		Type:    DeviceConditionReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}) {
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeviceConditions(t *testing.T) {
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default", Generation: 1},
+	}
+	key := DeviceStatusKeyForResult(resourceapi.DeviceRequestAllocationResult{Driver: "driver", Pool: "pool", Device: "dev", ShareID: ptr.To(types.UID("share"))})
+	otherKey := DeviceStatusKey{Driver: "driver", Pool: "pool", Device: "dev"}
+
+	assert.Nil(t, DeviceCondition(claim, key, DeviceConditionReady))
+	assert.False(t, RemoveDeviceCondition(claim, key, DeviceConditionReady))
+
+	assert.True(t, SetDeviceReady(claim, key, "Prepared", ""))
+	require.Len(t, claim.Status.Devices, 1)
+	assert.Equal(t, ptr.To("share"), claim.Status.Devices[0].ShareID)
+	assert.True(t, IsDeviceConditionTrue(claim, key, DeviceConditionReady))
+	assert.False(t, IsDeviceConditionTrue(claim, otherKey, DeviceConditionReady), "other share")
+	transitionTime := DeviceCondition(claim, key, DeviceConditionReady).LastTransitionTime
+	assert.False(t, transitionTime.IsZero())
+	assert.False(t, SetDeviceReady(claim, key, "Prepared", ""), "no change")
+
+	// A new generation makes the condition stale until it is reported again.
+	claim.Generation = 2
+	assert.False(t, IsDeviceConditionTrue(claim, key, DeviceConditionReady))
+	assert.True(t, SetDeviceReady(claim, key, "Prepared", ""))
+	assert.True(t, IsDeviceConditionTrue(claim, key, DeviceConditionReady))
+	assert.Equal(t, transitionTime, DeviceCondition(claim, key, DeviceConditionReady).LastTransitionTime, "status did not change")
+
+	assert.True(t, SetDeviceError(claim, key, "AttachFailed", "no such interface"))
+	assert.True(t, IsDeviceConditionTrue(claim, key, DeviceConditionError))
+	assert.False(t, IsDeviceConditionTrue(claim, key, DeviceConditionReady))
+	assert.Equal(t, "AttachFailed", DeviceCondition(claim, key, DeviceConditionReady).Reason)
+
+	assert.True(t, SetDeviceCondition(claim, key, metav1.Condition{Type: DeviceConditionNetworkAttached, Status: metav1.ConditionTrue, Reason: "Attached"}))
+	assert.True(t, SetDeviceReady(claim, key, "Prepared", ""))
+	assert.Nil(t, DeviceCondition(claim, key, DeviceConditionError), "error cleared")
+	assert.True(t, IsDeviceConditionTrue(claim, key, DeviceConditionNetworkAttached))
+
+	assert.True(t, RemoveDeviceCondition(claim, key, DeviceConditionNetworkAttached))
+	assert.Len(t, claim.Status.Devices, 1, "status entry is kept")
+}
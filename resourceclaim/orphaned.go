@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"context"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	resourceclient "k8s.io/client-go/kubernetes/typed/resource/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// IsOrphaned checks whether the claim was generated for a pod which no
+// longer exists. Only claims which are controlled by a pod, like those
+// created from a ResourceClaimTemplate, can be orphaned. A pod with the
+// same name but a different UID is a different pod.
+func IsOrphaned(claim *resourceapi.ResourceClaim, pods corelisters.PodLister) (bool, error) {
+	owner := metav1.GetControllerOf(claim)
+	if owner == nil || owner.APIVersion != "v1" || owner.Kind != "Pod" {
+		return false, nil
+	}
+	pod, err := pods.Pods(claim.Namespace).Get(owner.Name)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get owner of ResourceClaim %s: %w", klog.KObj(claim), err)
+	}
+	return pod.UID != owner.UID, nil
+}
+
+// OrphanedClaims returns those claims for which [IsOrphaned] is true.
+func OrphanedClaims(claims []*resourceapi.ResourceClaim, pods corelisters.PodLister) ([]*resourceapi.ResourceClaim, error) {
+	var orphaned []*resourceapi.ResourceClaim
+	for _, claim := range claims {
+		isOrphaned, err := IsOrphaned(claim, pods)
+		if err != nil {
+			return nil, err
+		}
+		if isOrphaned {
+			orphaned = append(orphaned, claim)
+		}
+	}
+	return orphaned, nil
+}
+
+// CleanupOrphanedClaim removes the reservation of an orphaned claim for
+// its owning pod and then deletes the claim if it is not reserved for any
+// other consumer. A claim which is still in use is left alone and needs to
+// be cleaned up again once its other consumers are gone.
+//
+// The deletion has the UID and ResourceVersion of the claim as
+// preconditions, so a claim which was replaced or reserved in the meantime
+// does not get deleted. A claim which is already gone is not an error.
+func CleanupOrphanedClaim(ctx context.Context, client resourceclient.ResourceClaimsGetter, claim *resourceapi.ResourceClaim) (deleted bool, finalErr error) {
+	logger := klog.FromContext(ctx)
+	owner := metav1.GetControllerOf(claim)
+	if owner != nil && owner.Kind == "Pod" {
+		updated, err := UpdateReservedFor(ctx, client, claim.Namespace, claim.Name, claim, ReservedForChange{Remove: []types.UID{owner.UID}})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		claim = updated
+	}
+	if len(claim.Status.ReservedFor) > 0 {
+		logger.V(5).Info("Orphaned ResourceClaim is still in use", "claim", klog.KObj(claim), "consumers", len(claim.Status.ReservedFor))
+		return false, nil
+	}
+	err := client.ResourceClaims(claim.Namespace).Delete(ctx, claim.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID:             &claim.UID,
+			ResourceVersion: &claim.ResourceVersion,
+		},
+	})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("delete orphaned ResourceClaim %s: %w", klog.KObj(claim), err)
+	}
+	logger.V(3).Info("Deleted orphaned ResourceClaim", "claim", klog.KObj(claim))
+	return true, nil
+}
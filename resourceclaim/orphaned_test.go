@@ -0,0 +1,94 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func generatedClaim(name, podName string, podUID types.UID, consumers ...resourceapi.ResourceClaimConsumerReference) *resourceapi.ResourceClaim {
+	return &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(name + "-uid"),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Name:       podName,
+				UID:        podUID,
+				Controller: ptr.To(true),
+			}},
+		},
+		Status: resourceapi.ResourceClaimStatus{ReservedFor: consumers},
+	}
+}
+
+func TestOrphanedClaims(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod-uid"}}))
+	pods := corelisters.NewPodLister(indexer)
+
+	owned := generatedClaim("owned", "pod", "pod-uid")
+	recreated := generatedClaim("recreated", "pod", "old-pod-uid")
+	gone := generatedClaim("gone", "other-pod", "other-pod-uid")
+	unowned := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: "default"}}
+
+	orphaned, err := OrphanedClaims([]*resourceapi.ResourceClaim{owned, recreated, gone, unowned}, pods)
+	require.NoError(t, err)
+	assert.Equal(t, []*resourceapi.ResourceClaim{recreated, gone}, orphaned)
+}
+
+func TestCleanupOrphanedClaim(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	ownerRef := resourceapi.ResourceClaimConsumerReference{Resource: "pods", Name: "pod", UID: "pod-uid"}
+	otherRef := resourceapi.ResourceClaimConsumerReference{Resource: "pods", Name: "other-pod", UID: "other-pod-uid"}
+	reserved := generatedClaim("reserved", "pod", "pod-uid", ownerRef)
+	shared := generatedClaim("shared", "pod", "pod-uid", ownerRef, otherRef)
+	client := fake.NewClientset(reserved, shared)
+
+	deleted, err := CleanupOrphanedClaim(ctx, client.ResourceV1(), reserved)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	_, err = client.ResourceV1().ResourceClaims("default").Get(ctx, "reserved", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "claim should have been deleted, got %v", err)
+
+	deleted, err = CleanupOrphanedClaim(ctx, client.ResourceV1(), shared)
+	require.NoError(t, err)
+	assert.False(t, deleted, "still in use by other pod")
+	shared, err = client.ResourceV1().ResourceClaims("default").Get(ctx, "shared", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []resourceapi.ResourceClaimConsumerReference{otherRef}, shared.Status.ReservedFor)
+
+	deleted, err = CleanupOrphanedClaim(ctx, client.ResourceV1(), reserved)
+	require.NoError(t, err, "already gone")
+	assert.False(t, deleted)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	resourceclient "k8s.io/client-go/kubernetes/typed/resource/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// ErrReservedForFull is wrapped by the errors returned when adding
+// consumers to a claim would exceed
+// [resourceapi.ResourceClaimReservedForMaxSize].
+var ErrReservedForFull = errors.New("maximum number of consumers reached")
+
+// PodReference returns the consumer reference for the pod.
+func PodReference(pod *v1.Pod) resourceapi.ResourceClaimConsumerReference {
+	return resourceapi.ResourceClaimConsumerReference{
+		Resource: "pods",
+		Name:     pod.Name,
+		UID:      pod.UID,
+	}
+}
+
+// ReservedForChange describes how the ReservedFor list of a claim needs
+// to be modified. Consumers which are already listed don't get added
+// again. Removing consumers which are not listed is not an error.
+type ReservedForChange struct {
+	Add    []resourceapi.ResourceClaimConsumerReference
+	Remove []types.UID
+}
+
+// Apply returns a copy of the claim with the modified ReservedFor list
+// and whether anything changed. The original claim is not modified.
+// Removals are applied before additions. If the resulting list would be
+// too long, the error wraps [ErrReservedForFull] and the claim is nil.
+func (c ReservedForChange) Apply(claim *resourceapi.ResourceClaim) (*resourceapi.ResourceClaim, bool, error) {
+	reservedFor := slices.DeleteFunc(slices.Clone(claim.Status.ReservedFor), func(reserved resourceapi.ResourceClaimConsumerReference) bool {
+		return slices.Contains(c.Remove, reserved.UID)
+	})
+	changed := len(reservedFor) != len(claim.Status.ReservedFor)
+	for _, consumer := range c.Add {
+		if slices.ContainsFunc(reservedFor, func(reserved resourceapi.ResourceClaimConsumerReference) bool {
+			return reserved.UID == consumer.UID
+		}) {
+			continue
+		}
+		reservedFor = append(reservedFor, consumer)
+		changed = true
+	}
+	if !changed {
+		return claim, false, nil
+	}
+	if len(reservedFor) > resourceapi.ResourceClaimReservedForMaxSize {
+		return nil, false, fmt.Errorf("ResourceClaim %s: %d consumers, at most %d allowed: %w", klog.KObj(claim), len(reservedFor), resourceapi.ResourceClaimReservedForMaxSize, ErrReservedForFull)
+	}
+	claim = claim.DeepCopy()
+	if len(reservedFor) == 0 {
+		reservedFor = nil
+	}
+	claim.Status.ReservedFor = reservedFor
+	return claim, true, nil
+}
+
+// UpdateReservedFor applies the change to the claim in the API server.
+// The claim is optional. If given, it is used for the first attempt
+// instead of retrieving the claim. On conflicts, the claim gets
+// retrieved again and the change gets applied anew.
+//
+// The result is the updated claim, or the current claim if nothing
+// needed to be changed.
+func UpdateReservedFor(ctx context.Context, client resourceclient.ResourceClaimsGetter, namespace, name string, claim *resourceapi.ResourceClaim, change ReservedForChange) (*resourceapi.ResourceClaim, error) {
+	var result *resourceapi.ResourceClaim
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if claim == nil {
+			current, err := client.ResourceClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			claim = current
+		}
+		modified, changed, err := change.Apply(claim)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			result = claim
+			return nil
+		}
+		updated, err := client.ResourceClaims(namespace).UpdateStatus(ctx, modified, metav1.UpdateOptions{})
+		if err != nil {
+			// Must be retrieved again for the next attempt.
+			claim = nil
+			return err
+		}
+		result = updated
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update ReservedFor of ResourceClaim %s/%s: %w", namespace, name, err)
+	}
+	return result, nil
+}
+
+// ReservedForBatcher collects changes for the ReservedFor lists of claims
+// and applies them with one update per claim. This is useful when many
+// pods share the same claim and get reserved or released at the same
+// time.
+//
+// A ReservedForBatcher is thread-safe. The zero value is not usable,
+// use [NewReservedForBatcher].
+type ReservedForBatcher struct {
+	client resourceclient.ResourceClaimsGetter
+
+	mutex   sync.Mutex
+	changes map[types.NamespacedName]*ReservedForChange
+}
+
+// NewReservedForBatcher creates a batcher which updates claims through
+// the client.
+func NewReservedForBatcher(client resourceclient.ResourceClaimsGetter) *ReservedForBatcher {
+	return &ReservedForBatcher{
+		client:  client,
+		changes: make(map[types.NamespacedName]*ReservedForChange),
+	}
+}
+
+// Add queues adding the consumer to the claim. It cancels a pending
+// removal of the same consumer.
+func (b *ReservedForBatcher) Add(namespace, name string, consumer resourceapi.ResourceClaimConsumerReference) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	change := b.change(namespace, name)
+	change.Remove = slices.DeleteFunc(change.Remove, func(uid types.UID) bool { return uid == consumer.UID })
+	change.Add = append(change.Add, consumer)
+}
+
+// Remove queues removing the consumer from the claim. It cancels a pending
+// addition of the same consumer.
+func (b *ReservedForBatcher) Remove(namespace, name string, uid types.UID) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	change := b.change(namespace, name)
+	change.Add = slices.DeleteFunc(change.Add, func(consumer resourceapi.ResourceClaimConsumerReference) bool { return consumer.UID == uid })
+	change.Remove = append(change.Remove, uid)
+}
+
+func (b *ReservedForBatcher) change(namespace, name string) *ReservedForChange {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	change := b.changes[key]
+	if change == nil {
+		change = &ReservedForChange{}
+		b.changes[key] = change
+	}
+	return change
+}
+
+// Flush applies all queued changes with [UpdateReservedFor]. Claims which
+// could not be updated are not retried by a later Flush, the caller has
+// to queue their changes again. The error contains all failures, each
+// wrapping the original error.
+func (b *ReservedForBatcher) Flush(ctx context.Context) error {
+	b.mutex.Lock()
+	changes := b.changes
+	b.changes = make(map[types.NamespacedName]*ReservedForChange)
+	b.mutex.Unlock()
+
+	logger := klog.FromContext(ctx)
+	var errs []error
+	for key, change := range changes {
+		if _, err := UpdateReservedFor(ctx, b.client, key.Namespace, key.Name, nil, *change); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.V(5).Info("Updated ReservedFor", "claim", key, "added", len(change.Add), "removed", len(change.Remove))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func consumer(i int) resourceapi.ResourceClaimConsumerReference {
+	return resourceapi.ResourceClaimConsumerReference{Resource: "pods", Name: fmt.Sprintf("pod-%d", i), UID: types.UID(fmt.Sprintf("uid-%d", i))}
+}
+
+func TestReservedForChangeApply(t *testing.T) {
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Status: resourceapi.ResourceClaimStatus{
+			ReservedFor: []resourceapi.ResourceClaimConsumerReference{consumer(0), consumer(1)},
+		},
+	}
+
+	modified, changed, err := ReservedForChange{Add: []resourceapi.ResourceClaimConsumerReference{consumer(1), consumer(2)}, Remove: []types.UID{"uid-0"}}.Apply(claim)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []resourceapi.ResourceClaimConsumerReference{consumer(1), consumer(2)}, modified.Status.ReservedFor)
+	assert.Len(t, claim.Status.ReservedFor, 2, "original claim should not be modified")
+
+	modified, changed, err = ReservedForChange{Add: []resourceapi.ResourceClaimConsumerReference{consumer(0)}, Remove: []types.UID{"uid-3"}}.Apply(claim)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Same(t, claim, modified)
+
+	modified, _, err = ReservedForChange{Remove: []types.UID{"uid-0", "uid-1"}}.Apply(claim)
+	require.NoError(t, err)
+	assert.Nil(t, modified.Status.ReservedFor)
+
+	var many ReservedForChange
+	for i := range resourceapi.ResourceClaimReservedForMaxSize {
+		many.Add = append(many.Add, consumer(i))
+	}
+	_, _, err = many.Apply(claim)
+	require.NoError(t, err, "existing consumers are not counted twice")
+	many.Add = append(many.Add, consumer(resourceapi.ResourceClaimReservedForMaxSize))
+	_, _, err = many.Apply(claim)
+	require.ErrorIs(t, err, ErrReservedForFull)
+}
+
+func TestUpdateReservedFor(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+	}
+	client := fake.NewClientset(claim)
+	conflicts := 2
+	client.PrependReactor("update", "resourceclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "status" && conflicts > 0 {
+			conflicts--
+			return true, nil, apierrors.NewConflict(resourceapi.Resource("resourceclaims"), "claim", fmt.Errorf("fake conflict"))
+		}
+		return false, nil, nil
+	})
+
+	updated, err := UpdateReservedFor(ctx, client.ResourceV1(), "default", "claim", claim, ReservedForChange{Add: []resourceapi.ResourceClaimConsumerReference{consumer(0)}})
+	require.NoError(t, err)
+	assert.Equal(t, []resourceapi.ResourceClaimConsumerReference{consumer(0)}, updated.Status.ReservedFor)
+	assert.Equal(t, 0, conflicts, "should have retried after conflicts")
+
+	_, err = UpdateReservedFor(ctx, client.ResourceV1(), "default", "no-such-claim", nil, ReservedForChange{Add: []resourceapi.ResourceClaimConsumerReference{consumer(0)}})
+	require.True(t, apierrors.IsNotFound(err), "expected NotFound, got %v", err)
+}
+
+func TestReservedForBatcher(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Status: resourceapi.ResourceClaimStatus{
+			ReservedFor: []resourceapi.ResourceClaimConsumerReference{consumer(0)},
+		},
+	}
+	client := fake.NewClientset(claim)
+	updates := 0
+	client.PrependReactor("update", "resourceclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		updates++
+		return false, nil, nil
+	})
+
+	batcher := NewReservedForBatcher(client.ResourceV1())
+	for i := 1; i <= 10; i++ {
+		batcher.Add("default", "claim", consumer(i))
+	}
+	batcher.Remove("default", "claim", "uid-0")
+	batcher.Remove("default", "claim", "uid-10")
+	batcher.Add("default", "no-such-claim", consumer(0))
+	err := batcher.Flush(ctx)
+	require.True(t, apierrors.IsNotFound(err), "expected NotFound, got %v", err)
+	assert.Equal(t, 1, updates, "one update for all consumers of the claim")
+
+	claim, err = client.ResourceV1().ResourceClaims("default").Get(ctx, "claim", metav1.GetOptions{})
+	require.NoError(t, err)
+	var expected []resourceapi.ResourceClaimConsumerReference
+	for i := 1; i < 10; i++ {
+		expected = append(expected, consumer(i))
+	}
+	assert.Equal(t, expected, claim.Status.ReservedFor)
+
+	require.NoError(t, batcher.Flush(ctx), "nothing queued")
+	assert.Equal(t, 1, updates)
+}
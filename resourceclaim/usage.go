@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+// UsageKey identifies the devices of one driver on one node. NodeName is
+// empty for devices in slices which are not local to a single node.
+type UsageKey struct {
+	NodeName string
+	Driver   string
+}
+
+// DeviceUsage summarizes how many devices are allocated.
+type DeviceUsage struct {
+	// TotalDevices is the number of devices in the ResourceSlices.
+	TotalDevices int
+	// AllocatedDevices is the number of those devices which are allocated
+	// by at least one claim. Allocations with admin access are not
+	// counted because they do not prevent allocating the device.
+	AllocatedDevices int
+	// Counters contains the usage for each counter in the counter sets
+	// which are defined by the slices. The key is "<pool>/<counter set>".
+	Counters map[string]map[string]CounterUsage
+}
+
+// FreeDevices returns the number of devices which are not allocated. Some
+// of them may still be unusable because their counters are exhausted.
+func (u DeviceUsage) FreeDevices() int {
+	return u.TotalDevices - u.AllocatedDevices
+}
+
+// CounterUsage compares the value of a counter with the sum of the
+// consumption by allocated devices.
+type CounterUsage struct {
+	Total    resource.Quantity
+	Consumed resource.Quantity
+}
+
+type usagePoolID struct {
+	driver, pool string
+}
+
+type usageDeviceID struct {
+	driver, pool, device string
+}
+
+type usageCounterSetID struct {
+	driver, pool, counterSet string
+}
+
+// SummarizeUsage determines per node and driver how many devices are
+// allocated by the claims and how much of the counters they consume. Only
+// the slices of the most recent generation of each pool are considered.
+// Allocated devices which are not in any of those slices are ignored.
+func SummarizeUsage(claims []*resourceapi.ResourceClaim, slices []*resourceapi.ResourceSlice) map[UsageKey]*DeviceUsage {
+	generations := make(map[usagePoolID]int64)
+	for _, slice := range slices {
+		pool := usagePoolID{driver: slice.Spec.Driver, pool: slice.Spec.Pool.Name}
+		generations[pool] = max(generations[pool], slice.Spec.Pool.Generation)
+	}
+
+	summary := make(map[UsageKey]*DeviceUsage)
+	type deviceInfo struct {
+		key    UsageKey
+		device *resourceapi.Device
+	}
+	devices := make(map[usageDeviceID]deviceInfo)
+	type counterSetInfo struct {
+		usage *DeviceUsage
+		name  string
+	}
+	counterSets := make(map[usageCounterSetID]counterSetInfo)
+	for _, slice := range slices {
+		if slice.Spec.Pool.Generation < generations[usagePoolID{driver: slice.Spec.Driver, pool: slice.Spec.Pool.Name}] {
+			continue
+		}
+		key := UsageKey{NodeName: ptr.Deref(slice.Spec.NodeName, ""), Driver: slice.Spec.Driver}
+		usage := summary[key]
+		if usage == nil {
+			usage = &DeviceUsage{}
+			summary[key] = usage
+		}
+		usage.TotalDevices += len(slice.Spec.Devices)
+		for i := range slice.Spec.Devices {
+			device := &slice.Spec.Devices[i]
+			devices[usageDeviceID{driver: slice.Spec.Driver, pool: slice.Spec.Pool.Name, device: device.Name}] = deviceInfo{key: key, device: device}
+		}
+		for _, counterSet := range slice.Spec.SharedCounters {
+			name := slice.Spec.Pool.Name + "/" + counterSet.Name
+			if usage.Counters == nil {
+				usage.Counters = make(map[string]map[string]CounterUsage)
+			}
+			counters := make(map[string]CounterUsage, len(counterSet.Counters))
+			for counterName, counter := range counterSet.Counters {
+				counters[counterName] = CounterUsage{Total: counter.Value.DeepCopy()}
+			}
+			usage.Counters[name] = counters
+			counterSets[usageCounterSetID{driver: slice.Spec.Driver, pool: slice.Spec.Pool.Name, counterSet: counterSet.Name}] = counterSetInfo{usage: usage, name: name}
+		}
+	}
+
+	// A device which is shared by several claims or allocated multiple
+	// times through consumable capacity only counts once.
+	allocated := make(map[usageDeviceID]bool)
+	for _, claim := range claims {
+		if claim.Status.Allocation == nil {
+			continue
+		}
+		for _, result := range claim.Status.Allocation.Devices.Results {
+			if ptr.Deref(result.AdminAccess, false) {
+				continue
+			}
+			id := usageDeviceID{driver: result.Driver, pool: result.Pool, device: result.Device}
+			info, ok := devices[id]
+			if !ok || allocated[id] {
+				continue
+			}
+			allocated[id] = true
+			summary[info.key].AllocatedDevices++
+			for _, consumption := range info.device.ConsumesCounters {
+				counterSet, ok := counterSets[usageCounterSetID{driver: result.Driver, pool: result.Pool, counterSet: consumption.CounterSet}]
+				if !ok {
+					continue
+				}
+				counters := counterSet.usage.Counters[counterSet.name]
+				for counterName, counter := range consumption.Counters {
+					counterUsage, ok := counters[counterName]
+					if !ok {
+						continue
+					}
+					counterUsage.Consumed.Add(counter.Value)
+					counters[counterName] = counterUsage
+				}
+			}
+		}
+	}
+	return summary
+}
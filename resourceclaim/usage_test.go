@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSummarizeUsage(t *testing.T) {
+	memory := func(value string) map[string]resourceapi.Counter {
+		return map[string]resourceapi.Counter{"memory": {Value: resource.MustParse(value)}}
+	}
+	slices := []*resourceapi.ResourceSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1-gpu"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   "gpu.example.com",
+				Pool:     resourceapi.ResourcePool{Name: "node-1", Generation: 2},
+				NodeName: ptr.To("node-1"),
+				SharedCounters: []resourceapi.CounterSet{{
+					Name:     "gpu-0",
+					Counters: memory("40Gi"),
+				}},
+				Devices: []resourceapi.Device{
+					{Name: "gpu-0-part-0", ConsumesCounters: []resourceapi.DeviceCounterConsumption{{CounterSet: "gpu-0", Counters: memory("10Gi")}}},
+					{Name: "gpu-0-part-1", ConsumesCounters: []resourceapi.DeviceCounterConsumption{{CounterSet: "gpu-0", Counters: memory("20Gi")}}},
+					{Name: "gpu-0-part-2", ConsumesCounters: []resourceapi.DeviceCounterConsumption{{CounterSet: "gpu-0", Counters: memory("20Gi")}}},
+				},
+			},
+		},
+		{
+			// Outdated, must be ignored.
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1-gpu-old"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   "gpu.example.com",
+				Pool:     resourceapi.ResourcePool{Name: "node-1", Generation: 1},
+				NodeName: ptr.To("node-1"),
+				Devices:  []resourceapi.Device{{Name: "old"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "network"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   "net.example.com",
+				Pool:     resourceapi.ResourcePool{Name: "network"},
+				AllNodes: ptr.To(true),
+				Devices:  []resourceapi.Device{{Name: "vlan-1"}, {Name: "vlan-2"}},
+			},
+		},
+	}
+	allocated := func(name string, results ...resourceapi.DeviceRequestAllocationResult) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: resourceapi.ResourceClaimStatus{
+				Allocation: &resourceapi.AllocationResult{
+					Devices: resourceapi.DeviceAllocationResult{Results: results},
+				},
+			},
+		}
+	}
+	claims := []*resourceapi.ResourceClaim{
+		allocated("a",
+			resourceapi.DeviceRequestAllocationResult{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0-part-0"},
+			resourceapi.DeviceRequestAllocationResult{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0-part-1"},
+		),
+		// Shared with "a", counted once.
+		allocated("b", resourceapi.DeviceRequestAllocationResult{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0-part-0"}),
+		allocated("admin", resourceapi.DeviceRequestAllocationResult{Driver: "gpu.example.com", Pool: "node-1", Device: "gpu-0-part-2", AdminAccess: ptr.To(true)}),
+		allocated("net", resourceapi.DeviceRequestAllocationResult{Driver: "net.example.com", Pool: "network", Device: "vlan-1"}),
+		allocated("unknown", resourceapi.DeviceRequestAllocationResult{Driver: "net.example.com", Pool: "network", Device: "vlan-3"}),
+		{ObjectMeta: metav1.ObjectMeta{Name: "pending"}},
+	}
+
+	summary := SummarizeUsage(claims, slices)
+	require.Len(t, summary, 2)
+
+	gpu := summary[UsageKey{NodeName: "node-1", Driver: "gpu.example.com"}]
+	require.NotNil(t, gpu)
+	assert.Equal(t, 3, gpu.TotalDevices)
+	assert.Equal(t, 2, gpu.AllocatedDevices)
+	assert.Equal(t, 1, gpu.FreeDevices())
+	counter := gpu.Counters["node-1/gpu-0"]["memory"]
+	assert.Equal(t, "40Gi", counter.Total.String())
+	assert.Equal(t, "30Gi", counter.Consumed.String())
+
+	network := summary[UsageKey{Driver: "net.example.com"}]
+	require.NotNil(t, network)
+	assert.Equal(t, 2, network.TotalDevices)
+	assert.Equal(t, 1, network.AllocatedDevices)
+	assert.Nil(t, network.Counters)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/dynamic-resource-allocation/cel"
+)
+
+// ValidationOptions provides the cluster state which a claim gets
+// validated against.
+type ValidationOptions struct {
+	// Classes are all DeviceClasses in the cluster.
+	Classes []*resourceapi.DeviceClass
+
+	// Slices are optional. If set, requests for more devices than
+	// published in total by all slices are invalid. The slices are
+	// not used for anything else.
+	Slices []*resourceapi.ResourceSlice
+
+	// CELCache is used to compile CEL expressions. A new cache
+	// with default features is used if nil.
+	CELCache *cel.Cache
+}
+
+// ValidateClaim checks whether the claim can possibly be allocated in
+// the cluster described by the options, for use in admission webhooks and
+// preflight checks. It complements the validation by the API server, which
+// only checks the claim itself: referenced classes must exist, the CEL
+// expressions of the claim and its classes must compile within the cost
+// limit, and the number of requested devices must be allocatable.
+//
+// A valid claim may still fail to get allocated, for example because
+// matching devices are in use.
+func ValidateClaim(claim *resourceapi.ResourceClaim, options ValidationOptions) field.ErrorList {
+	celCache := options.CELCache
+	if celCache == nil {
+		celCache = cel.NewCache(10, cel.Features{})
+	}
+	classes := make(map[string]*resourceapi.DeviceClass, len(options.Classes))
+	for _, class := range options.Classes {
+		classes[class.Name] = class
+	}
+	maxDevices := -1
+	if options.Slices != nil {
+		maxDevices = 0
+		for _, slice := range options.Slices {
+			maxDevices += len(slice.Spec.Devices)
+		}
+	}
+
+	var allErrs field.ErrorList
+	requestsPath := field.NewPath("spec", "devices", "requests")
+	var totalCount int64
+	for i, request := range claim.Spec.Devices.Requests {
+		requestPath := requestsPath.Index(i)
+		switch {
+		case request.Exactly != nil:
+			allErrs = append(allErrs, validateExactRequest(request.Exactly, requestPath.Child("exactly"), classes, celCache, maxDevices)...)
+			totalCount += minDeviceCount(request.Exactly.AllocationMode, request.Exactly.Count)
+		case len(request.FirstAvailable) > 0:
+			var minCount int64 = -1
+			for e, subRequest := range request.FirstAvailable {
+				exact := resourceapi.ExactDeviceRequest{
+					DeviceClassName: subRequest.DeviceClassName,
+					Selectors:       subRequest.Selectors,
+					AllocationMode:  subRequest.AllocationMode,
+					Count:           subRequest.Count,
+				}
+				allErrs = append(allErrs, validateExactRequest(&exact, requestPath.Child("firstAvailable").Index(e), classes, celCache, maxDevices)...)
+				count := minDeviceCount(subRequest.AllocationMode, subRequest.Count)
+				if minCount < 0 || count < minCount {
+					minCount = count
+				}
+			}
+			totalCount += minCount
+		}
+	}
+	if totalCount > resourceapi.AllocationResultsMaxSize {
+		allErrs = append(allErrs, field.Invalid(requestsPath, totalCount, fmt.Sprintf("the requests need at least %d devices, at most %d can be allocated for a claim", totalCount, resourceapi.AllocationResultsMaxSize)))
+	}
+	return allErrs
+}
+
+func validateExactRequest(request *resourceapi.ExactDeviceRequest, path *field.Path, classes map[string]*resourceapi.DeviceClass, celCache *cel.Cache, maxDevices int) field.ErrorList {
+	var allErrs field.ErrorList
+	classPath := path.Child("deviceClassName")
+	if class := classes[request.DeviceClassName]; class == nil {
+		allErrs = append(allErrs, field.NotFound(classPath, request.DeviceClassName))
+	} else {
+		for _, selector := range class.Spec.Selectors {
+			if err := validateSelector(selector, celCache); err != "" {
+				allErrs = append(allErrs, field.Invalid(classPath, request.DeviceClassName, "DeviceClass has an invalid selector: "+err))
+			}
+		}
+	}
+	for i, selector := range request.Selectors {
+		if err := validateSelector(selector, celCache); err != "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("selectors").Index(i).Child("cel", "expression"), selector.CEL.Expression, err))
+		}
+	}
+	count := minDeviceCount(request.AllocationMode, request.Count)
+	if maxDevices >= 0 && count > int64(maxDevices) {
+		allErrs = append(allErrs, field.Invalid(path.Child("count"), count, fmt.Sprintf("only %d devices are available in the cluster", maxDevices)))
+	}
+	return allErrs
+}
+
+// validateSelector returns a description of the problem, or the empty
+// string if the selector is okay.
+func validateSelector(selector resourceapi.DeviceSelector, celCache *cel.Cache) string {
+	if selector.CEL == nil {
+		return ""
+	}
+	result := celCache.GetOrCompile(selector.CEL.Expression)
+	if result.Error != nil {
+		return result.Error.Error()
+	}
+	cost, err := celCache.EstimateCost(selector.CEL.Expression)
+	if err != nil {
+		return err.Error()
+	}
+	if cost > resourceapi.CELSelectorExpressionMaxCost {
+		return fmt.Sprintf("estimated cost %d exceeds the limit of %d", cost, resourceapi.CELSelectorExpressionMaxCost)
+	}
+	return ""
+}
+
+// minDeviceCount returns the minimum number of devices that get
+// allocated for a request.
+func minDeviceCount(mode resourceapi.DeviceAllocationMode, count int64) int64 {
+	if mode == resourceapi.DeviceAllocationModeAll {
+		return 1
+	}
+	// An unset count defaults to one.
+	return max(count, 1)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceclaim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateClaim(t *testing.T) {
+	celSelector := func(expression string) []resourceapi.DeviceSelector {
+		return []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: expression}}}
+	}
+	classes := []*resourceapi.DeviceClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}, Spec: resourceapi.DeviceClassSpec{Selectors: celSelector(`device.driver == "gpu.example.com"`)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "broken"}, Spec: resourceapi.DeviceClassSpec{Selectors: celSelector(`device.no_such_field`)}},
+	}
+	slices := []*resourceapi.ResourceSlice{{
+		Spec: resourceapi.ResourceSliceSpec{Devices: []resourceapi.Device{{Name: "gpu-0"}, {Name: "gpu-1"}}},
+	}}
+	exactly := func(className string, count int64, selectors ...resourceapi.DeviceSelector) resourceapi.DeviceRequest {
+		return resourceapi.DeviceRequest{
+			Name: "req",
+			Exactly: &resourceapi.ExactDeviceRequest{
+				DeviceClassName: className,
+				AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+				Count:           count,
+				Selectors:       selectors,
+			},
+		}
+	}
+	claim := func(requests ...resourceapi.DeviceRequest) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{Spec: resourceapi.ResourceClaimSpec{Devices: resourceapi.DeviceClaim{Requests: requests}}}
+	}
+	requestsPath := field.NewPath("spec", "devices", "requests")
+
+	testcases := map[string]struct {
+		claim       *resourceapi.ResourceClaim
+		slices      []*resourceapi.ResourceSlice
+		expectPaths []string
+	}{
+		"valid": {
+			claim:  claim(exactly("gpu", 2, celSelector(`device.attributes["gpu.example.com"].model == "a100"`)...)),
+			slices: slices,
+		},
+		"unknown-class": {
+			claim:       claim(exactly("tpu", 1)),
+			expectPaths: []string{requestsPath.Index(0).Child("exactly", "deviceClassName").String()},
+		},
+		"invalid-class": {
+			claim:       claim(exactly("broken", 1)),
+			expectPaths: []string{requestsPath.Index(0).Child("exactly", "deviceClassName").String()},
+		},
+		"invalid-selector": {
+			claim:       claim(exactly("gpu", 1, celSelector(`device.driver ==`)...)),
+			expectPaths: []string{requestsPath.Index(0).Child("exactly", "selectors").Index(0).Child("cel", "expression").String()},
+		},
+		"not-enough-devices": {
+			claim:       claim(exactly("gpu", 3)),
+			slices:      slices,
+			expectPaths: []string{requestsPath.Index(0).Child("exactly", "count").String()},
+		},
+		"slices-not-checked": {
+			claim: claim(exactly("gpu", 3)),
+		},
+		"too-many-devices": {
+			claim:       claim(exactly("gpu", resourceapi.AllocationResultsMaxSize+1)),
+			expectPaths: []string{requestsPath.String()},
+		},
+		"first-available": {
+			claim: claim(resourceapi.DeviceRequest{
+				Name: "req",
+				FirstAvailable: []resourceapi.DeviceSubRequest{
+					{Name: "a", DeviceClassName: "gpu", AllocationMode: resourceapi.DeviceAllocationModeExactCount, Count: 5},
+					{Name: "b", DeviceClassName: "tpu", AllocationMode: resourceapi.DeviceAllocationModeAll},
+				},
+			}),
+			slices: slices,
+			expectPaths: []string{
+				requestsPath.Index(0).Child("firstAvailable").Index(0).Child("count").String(),
+				requestsPath.Index(0).Child("firstAvailable").Index(1).Child("deviceClassName").String(),
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			errs := ValidateClaim(tc.claim, ValidationOptions{Classes: classes, Slices: tc.slices})
+			var paths []string
+			for _, err := range errs {
+				paths = append(paths, err.Field)
+			}
+			assert.Equal(t, tc.expectPaths, paths, "errors: %v", errs)
+		})
+	}
+}
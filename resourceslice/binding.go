@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// InvalidDeviceError is returned for a pool which cannot be published
+// because one of its devices is invalid. The ResourceSlices of the pool
+// remain unchanged until the driver provides a valid device.
+type InvalidDeviceError struct {
+	PoolName   string
+	SliceIndex int
+	DeviceName string
+	Err        error
+}
+
+func (err *InvalidDeviceError) Error() string {
+	return fmt.Sprintf("pool %q, slice #%d, device %q: %v", err.PoolName, err.SliceIndex, err.DeviceName, err.Err)
+}
+
+func (err *InvalidDeviceError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = &InvalidDeviceError{}
+
+// validateBinding checks the fields of the devices in a pool which
+// control binding of network-attached devices (BindsToNode,
+// BindingConditions, BindingFailureConditions). The apiserver would
+// reject slices with invalid values, but checking them locally leads
+// to more specific errors.
+//
+// How long the scheduler waits for binding conditions is not part
+// of the device. It is configured for the scheduler (the bindingTimeout
+// in the DynamicResources plugin arguments).
+func validateBinding(poolName string, pool Pool) error {
+	for i, slice := range pool.Slices {
+		for _, device := range slice.Devices {
+			if err := validateDeviceBinding(device); err != nil {
+				return &InvalidDeviceError{PoolName: poolName, SliceIndex: i, DeviceName: device.Name, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func validateDeviceBinding(device resourceapi.Device) error {
+	var errs []error
+	if len(device.BindingConditions) > 0 && len(device.BindingFailureConditions) == 0 {
+		errs = append(errs, errors.New("bindingFailureConditions must be set together with bindingConditions"))
+	}
+	if len(device.BindingFailureConditions) > 0 && len(device.BindingConditions) == 0 {
+		errs = append(errs, errors.New("bindingConditions must be set together with bindingFailureConditions"))
+	}
+	errs = append(errs, validateConditions("bindingConditions", device.BindingConditions, resourceapi.BindingConditionsMaxSize)...)
+	errs = append(errs, validateConditions("bindingFailureConditions", device.BindingFailureConditions, resourceapi.BindingFailureConditionsMaxSize)...)
+	if both := sets.New(device.BindingConditions...).Intersection(sets.New(device.BindingFailureConditions...)); both.Len() > 0 {
+		errs = append(errs, fmt.Errorf("conditions must not be both binding and binding failure conditions: %s", strings.Join(sets.List(both), ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+func validateConditions(field string, conditions []string, maxSize int) []error {
+	var errs []error
+	if len(conditions) > maxSize {
+		errs = append(errs, fmt.Errorf("%s: must have at most %d entries, got %d", field, maxSize, len(conditions)))
+	}
+	seen := sets.New[string]()
+	for i, condition := range conditions {
+		for _, msg := range validation.IsQualifiedName(condition) {
+			errs = append(errs, fmt.Errorf("%s[%d]: %q: %s", field, i, condition, msg))
+		}
+		if seen.Has(condition) {
+			errs = append(errs, fmt.Errorf("%s[%d]: duplicate condition %q", field, i, condition))
+		}
+		seen.Insert(condition)
+	}
+	return errs
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllertesting runs a [resourceslice.Controller] against
+// a fake clientset with a fake clock and lets tests decide when the
+// controller syncs pools. This makes it possible to unit-test the
+// [resourceslice.DriverResources] produced by a driver deterministically:
+//
+//	h, err := controllertesting.New(ctx, resourceslice.Options{DriverName: "gpu.example.com"})
+//	...
+//	defer h.Stop()
+//	h.Controller.Update(driver.Resources())
+//	h.Step()
+//	slices, err := h.ResourceSlices(ctx)
+//
+// The controller receives changes of ResourceSlices through an informer,
+// which runs asynchronously. Syncs triggered by informer events get
+// scheduled with the controller's SyncDelay and thus only happen when
+// a test advances the clock past that delay.
+package controllertesting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+// Harness controls a running controller.
+type Harness struct {
+	// Client is the client used by the controller.
+	Client kubernetes.Interface
+	// Clock is the clock used by the controller.
+	Clock *testingclock.FakeClock
+	// Controller is the controller under test.
+	Controller *resourceslice.Controller
+
+	queue *queue
+
+	mutex  sync.Mutex
+	errors []error
+}
+
+// New starts a controller. The Queue, Clock and ErrorHandler in the
+// options get replaced. Errors are recorded for [Harness.Errors] and
+// also passed to the original ErrorHandler, if there was one.
+//
+// The default KubeClient is [NewFakeClient]. The clock starts at the
+// current time.
+func New(ctx context.Context, options resourceslice.Options) (*Harness, error) {
+	if options.KubeClient == nil {
+		options.KubeClient = NewFakeClient()
+	}
+	h := &Harness{
+		Client: options.KubeClient,
+		Clock:  testingclock.NewFakeClock(time.Now()),
+	}
+	h.queue = newQueue(h.Clock)
+	options.Queue = h.queue
+	options.Clock = h.Clock
+	errorHandler := options.ErrorHandler
+	options.ErrorHandler = func(ctx context.Context, err error, msg string) {
+		h.mutex.Lock()
+		h.errors = append(h.errors, fmt.Errorf("%s: %w", msg, err))
+		h.mutex.Unlock()
+		if errorHandler != nil {
+			errorHandler(ctx, err, msg)
+		}
+	}
+	controller, err := resourceslice.StartController(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	h.Controller = controller
+	return h, nil
+}
+
+// Stop stops the controller.
+func (h *Harness) Stop() {
+	h.Controller.Stop()
+}
+
+// Step syncs all pools which need to be synced at the current time
+// of the clock, including those which become due while doing so,
+// and returns the number of syncs. It returns once the controller is idle.
+func (h *Harness) Step() int {
+	return h.queue.step()
+}
+
+// Advance moves the clock forward and then calls [Harness.Step].
+func (h *Harness) Advance(duration time.Duration) int {
+	h.Clock.Step(duration)
+	return h.Step()
+}
+
+// Scheduled returns the pools for which a sync is scheduled
+// in the future and when that will be.
+func (h *Harness) Scheduled() map[string]time.Time {
+	return h.queue.scheduled()
+}
+
+// Errors returns all errors reported by the controller since the
+// previous call.
+func (h *Harness) Errors() []error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	errs := h.errors
+	h.errors = nil
+	return errs
+}
+
+// ResourceSlices returns all ResourceSlices in the cluster, sorted by name.
+func (h *Harness) ResourceSlices(ctx context.Context) ([]resourceapi.ResourceSlice, error) {
+	list, err := h.Client.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list ResourceSlices: %w", err)
+	}
+	items := list.Items
+	slices.SortFunc(items, func(a, b resourceapi.ResourceSlice) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return items, nil
+}
+
+// NewFakeClient creates a fake clientset with the given objects. Unlike
+// a plain fake clientset, it supports GenerateName and increments the
+// ResourceVersion of ResourceSlices, both of which the controller
+// depends on.
+func NewFakeClient(objects ...runtime.Object) *fake.Clientset {
+	client := fake.NewSimpleClientset(objects...)
+	var mutex sync.Mutex
+	var counter int
+	client.PrependReactor("create", "resourceslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		slice := action.(k8stesting.CreateAction).GetObject().(*resourceapi.ResourceSlice)
+		if slice.Name == "" && slice.GenerateName != "" {
+			slice.Name = fmt.Sprintf("%s%d", slice.GenerateName, counter)
+			counter++
+		}
+		slice.ResourceVersion = "1"
+		return false, nil, nil
+	})
+	client.PrependReactor("update", "resourceslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		slice := action.(k8stesting.UpdateAction).GetObject().(*resourceapi.ResourceSlice)
+		rev := 0
+		if slice.ResourceVersion != "" {
+			oldRev, err := strconv.Atoi(slice.ResourceVersion)
+			if err != nil {
+				return true, nil, fmt.Errorf("ResourceVersion %q should have been an int: %w", slice.ResourceVersion, err)
+			}
+			rev = oldRev
+		}
+		slice.ResourceVersion = strconv.Itoa(rev + 1)
+		return false, nil, nil
+	})
+	return client
+}
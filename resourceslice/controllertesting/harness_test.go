@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllertesting
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestHarness(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	client := NewFakeClient()
+	failCreate := true
+	client.PrependReactor("create", "resourceslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failCreate {
+			return true, nil, errors.New("fake create error")
+		}
+		return false, nil, nil
+	})
+	h, err := New(ctx, resourceslice.Options{
+		DriverName: "driver.example.com",
+		KubeClient: client,
+		Resources: &resourceslice.DriverResources{
+			Pools: map[string]resourceslice.Pool{
+				"pool": {Slices: []resourceslice.Slice{{Devices: []resourceapi.Device{{Name: "dev-0"}}}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer h.Stop()
+
+	// Nothing happens without stepping.
+	slices, err := h.ResourceSlices(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, slices, "before first step")
+
+	assert.Equal(t, 1, h.Step(), "first sync")
+	assert.Len(t, h.Errors(), 1, "errors of first sync")
+	assert.Equal(t, map[string]time.Time{"pool": h.Clock.Now().Add(5 * time.Millisecond)}, h.Scheduled(), "retry")
+	assert.Equal(t, 0, h.Step(), "retry not due yet")
+
+	failCreate = false
+	assert.Equal(t, 1, h.Advance(5*time.Millisecond), "retry")
+	assert.Empty(t, h.Errors(), "errors of retry")
+	slices, err = h.ResourceSlices(ctx)
+	require.NoError(t, err)
+	require.Len(t, slices, 1, "after retry")
+	assert.Equal(t, []resourceapi.Device{{Name: "dev-0"}}, slices[0].Spec.Devices)
+
+	h.Controller.Update(nil)
+	assert.Equal(t, 1, h.Step(), "sync after update")
+	slices, err = h.ResourceSlices(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, slices, "after removing the pool")
+}
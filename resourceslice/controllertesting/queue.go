@@ -0,0 +1,226 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllertesting
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+const (
+	// Same as in workqueue.DefaultTypedControllerRateLimiter.
+	baseRetryDelay = 5 * time.Millisecond
+	maxRetryDelay  = 1000 * time.Second
+)
+
+// queue is a work queue where the controller's worker only gets
+// items after step allowed it. Delays are measured with the fake clock.
+type queue struct {
+	clock *testingclock.FakeClock
+
+	mutex sync.Mutex
+	cond  *sync.Cond
+	// pending contains items which are ready, but not released yet.
+	pending []string
+	// released contains items which Get may hand out.
+	released []string
+	inFlight []string
+	later    map[string]time.Time
+	failures map[string]int
+	shutdown bool
+	numSyncs int
+}
+
+var _ workqueue.TypedRateLimitingInterface[string] = &queue{}
+
+func newQueue(clock *testingclock.FakeClock) *queue {
+	q := &queue{
+		clock:    clock,
+		later:    make(map[string]time.Time),
+		failures: make(map[string]int),
+	}
+	q.cond = sync.NewCond(&q.mutex)
+	return q
+}
+
+// step releases all items which are due, waits for the worker to process
+// them, and repeats until no item is due anymore. It returns the number
+// of processed items.
+func (q *queue) step() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	start := q.numSyncs
+	for !q.shutdown {
+		now := q.clock.Now()
+		for _, item := range slices.Sorted(maps.Keys(q.later)) {
+			if !q.later[item].After(now) {
+				delete(q.later, item)
+				q.addLocked(item)
+			}
+		}
+		if len(q.pending) == 0 {
+			break
+		}
+		q.released = append(q.released, q.pending...)
+		q.pending = nil
+		q.cond.Broadcast()
+		for !q.shutdown && (len(q.released) > 0 || len(q.inFlight) > 0) {
+			q.cond.Wait()
+		}
+	}
+	return q.numSyncs - start
+}
+
+// scheduled returns the items which will become due in the future.
+func (q *queue) scheduled() map[string]time.Time {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return maps.Clone(q.later)
+}
+
+func (q *queue) addLocked(item string) {
+	if slices.Contains(q.pending, item) || slices.Contains(q.released, item) {
+		return
+	}
+	q.pending = append(q.pending, item)
+}
+
+// Add implements [workqueue.TypedInterface].
+func (q *queue) Add(item string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.addLocked(item)
+}
+
+// Len implements [workqueue.TypedInterface].
+func (q *queue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.pending) + len(q.released)
+}
+
+// Get implements [workqueue.TypedInterface]. It blocks until
+// step releases an item or the queue gets shut down.
+func (q *queue) Get() (string, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for len(q.released) == 0 && !q.shutdown {
+		q.cond.Wait()
+	}
+	if len(q.released) == 0 {
+		return "", true
+	}
+	item := q.released[0]
+	q.released = q.released[1:]
+	q.inFlight = append(q.inFlight, item)
+	return item, false
+}
+
+// Done implements [workqueue.TypedInterface].
+func (q *queue) Done(item string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if index := slices.Index(q.inFlight, item); index >= 0 {
+		q.inFlight = slices.Delete(q.inFlight, index, index+1)
+		q.numSyncs++
+	}
+	q.cond.Broadcast()
+}
+
+// ShutDown implements [workqueue.TypedInterface].
+func (q *queue) ShutDown() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.shutdown = true
+	q.cond.Broadcast()
+}
+
+// ShutDownWithDrain implements [workqueue.TypedInterface].
+func (q *queue) ShutDownWithDrain() {
+	q.ShutDown()
+}
+
+// ShuttingDown implements [workqueue.TypedInterface].
+func (q *queue) ShuttingDown() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.shutdown
+}
+
+// AddAfter implements [workqueue.TypedDelayingInterface].
+// Like the real queue, it only ever shortens the delay of an item.
+func (q *queue) AddAfter(item string, duration time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.addAfterLocked(item, duration)
+}
+
+func (q *queue) addAfterLocked(item string, duration time.Duration) {
+	if duration <= 0 {
+		q.addLocked(item)
+		return
+	}
+	when := q.clock.Now().Add(duration)
+	if existing, ok := q.later[item]; ok && existing.Before(when) {
+		return
+	}
+	q.later[item] = when
+}
+
+// AddRateLimited implements [workqueue.TypedRateLimitingInterface] with
+// the same exponential backoff as the default controller rate limiter.
+func (q *queue) AddRateLimited(item string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delay := baseRetryDelay << q.failures[item]
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+	q.failures[item]++
+	q.addAfterLocked(item, delay)
+}
+
+// Forget implements [workqueue.TypedRateLimitingInterface].
+func (q *queue) Forget(item string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delete(q.failures, item)
+}
+
+// NumRequeues implements [workqueue.TypedRateLimitingInterface].
+func (q *queue) NumRequeues(item string) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.failures[item]
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultDiscoveryInterval is used by [PollingDiscoverer] when
+	// the interval is not positive.
+	DefaultDiscoveryInterval = time.Minute
+
+	// DefaultDiscoveryDebounce is the default for [Discovery.Debounce].
+	DefaultDiscoveryDebounce = time.Second
+
+	// DefaultDiscoveryMaxDebounceFactor determines the default for
+	// [Discovery.MaxDebounce] as a multiple of [Discovery.Debounce].
+	DefaultDiscoveryMaxDebounceFactor = 10
+)
+
+// DefaultDiscoveryBackoff is the default for [Discovery.Backoff].
+var DefaultDiscoveryBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    10,
+	Cap:      5 * time.Minute,
+}
+
+// DeviceDiscoverer finds the devices of a driver, for example by querying
+// a cloud inventory. The controller drives it when configured with
+// [Options.Discovery], so a driver does not need to call [Controller.Update]
+// itself.
+type DeviceDiscoverer interface {
+	// Run calls update with the complete desired resources whenever
+	// they change, until the context gets canceled. Update may also
+	// be called with unchanged resources, the controller then does nothing.
+	//
+	// When Run returns before the context is canceled, the error
+	// is passed to the ErrorHandler and Run gets called again after
+	// a delay determined by [Discovery.Backoff].
+	Run(ctx context.Context, update func(resources *DriverResources)) error
+}
+
+// DeviceDiscovererFunc implements a streaming [DeviceDiscoverer] with a function.
+type DeviceDiscovererFunc func(ctx context.Context, update func(resources *DriverResources)) error
+
+// Run implements [DeviceDiscoverer].
+func (f DeviceDiscovererFunc) Run(ctx context.Context, update func(resources *DriverResources)) error {
+	return f(ctx, update)
+}
+
+// PollingDiscoverer returns a [DeviceDiscoverer] which calls discover
+// immediately and then repeatedly with the given interval. An error
+// returned by discover ends the Run call.
+func PollingDiscoverer(discover func(ctx context.Context) (*DriverResources, error), interval time.Duration) DeviceDiscoverer {
+	if interval <= 0 {
+		interval = DefaultDiscoveryInterval
+	}
+	return DeviceDiscovererFunc(func(ctx context.Context, update func(resources *DriverResources)) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			resources, err := discover(ctx)
+			if err != nil {
+				return err
+			}
+			update(resources)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Discovery configures how the controller drives a [DeviceDiscoverer].
+type Discovery struct {
+	// Discoverer is required.
+	Discoverer DeviceDiscoverer
+
+	// Debounce is how long the controller waits after an update from
+	// the discoverer for further updates before it uses the most recent
+	// one. This avoids syncing intermediate states. The default is
+	// [DefaultDiscoveryDebounce], zero disables debouncing.
+	Debounce *time.Duration
+
+	// MaxDebounce is the longest time that the controller waits after
+	// the first of several updates which arrive in quick succession.
+	// Without it, a discoverer which keeps sending updates would never
+	// get its state published. The default is Debounce multiplied with
+	// [DefaultDiscoveryMaxDebounceFactor].
+	MaxDebounce *time.Duration
+
+	// Backoff determines how long to wait before calling Run again
+	// after it failed. The delay gets reset once Run delivers an update.
+	// The default is [DefaultDiscoveryBackoff].
+	Backoff *wait.Backoff
+}
+
+// runDiscovery runs until the context is canceled.
+func (c *Controller) runDiscovery(ctx context.Context, discovery *Discovery) {
+	logger := klog.FromContext(ctx)
+	debounce := DefaultDiscoveryDebounce
+	if discovery.Debounce != nil {
+		debounce = *discovery.Debounce
+	}
+	maxDebounce := debounce * DefaultDiscoveryMaxDebounceFactor
+	if discovery.MaxDebounce != nil {
+		maxDebounce = *discovery.MaxDebounce
+	}
+	initialBackoff := DefaultDiscoveryBackoff
+	if discovery.Backoff != nil {
+		initialBackoff = *discovery.Backoff
+	}
+
+	var mutex sync.Mutex
+	var timer *time.Timer
+	var latest *DriverResources
+	var pendingSince time.Time
+	backoff := initialBackoff
+	update := func(resources *DriverResources) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		backoff = initialBackoff
+		if debounce <= 0 {
+			c.Update(resources)
+			return
+		}
+		now := time.Now()
+		if latest == nil {
+			pendingSince = now
+		}
+		// Must copy because the discoverer may modify
+		// the resources once update returns.
+		latest = resources.DeepCopy()
+		if timer != nil {
+			timer.Stop()
+		}
+		delay := min(debounce, max(pendingSince.Add(maxDebounce).Sub(now), 0))
+		timer = time.AfterFunc(delay, func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if ctx.Err() != nil || latest == nil {
+				return
+			}
+			c.Update(latest)
+			latest = nil
+		})
+	}
+	defer func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		err := discovery.Discoverer.Run(ctx, update)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("device discovery stopped unexpectedly")
+		}
+		c.errorHandler(ctx, err, "discover devices")
+		mutex.Lock()
+		delay := backoff.Step()
+		mutex.Unlock()
+		logger.V(3).Info("Restarting device discovery", "delay", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
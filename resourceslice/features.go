@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"context"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+)
+
+// dropDisabledFields removes fields which depend on disabled features from
+// the desired slices of the pool, like the apiserver would. The slices are
+// modified in place, so each change only gets reported once, by calling
+// the error handler.
+func (c *Controller) dropDisabledFields(ctx context.Context, poolName string, pool Pool) {
+	for i := range pool.Slices {
+		slice := &pool.Slices[i]
+		if !disabledSliceFields(*c.features, slice, false) {
+			continue
+		}
+		desired := slice.DeepCopy()
+		disabledSliceFields(*c.features, slice, true)
+		err := &DroppedFieldsError{
+			PoolName:     poolName,
+			SliceIndex:   i,
+			DesiredSlice: &resourceapi.ResourceSlice{Spec: sliceSpec(desired)},
+			ActualSlice:  &resourceapi.ResourceSlice{Spec: sliceSpec(slice.DeepCopy())},
+			Local:        true,
+		}
+		c.errorHandler(ctx, err, "publish ResourceSlice")
+	}
+}
+
+func sliceSpec(slice *Slice) resourceapi.ResourceSliceSpec {
+	return resourceapi.ResourceSliceSpec{
+		Devices:                slice.Devices,
+		SharedCounters:         slice.SharedCounters,
+		PerDeviceNodeSelection: slice.PerDeviceNodeSelection,
+	}
+}
+
+// disabledSliceFields returns true if the slice has fields which depend
+// on disabled features. Those fields get cleared if drop is true.
+func disabledSliceFields(features featuregates.Features, slice *Slice, drop bool) bool {
+	found := false
+	if !features.PartitionableDevices && (slice.SharedCounters != nil || slice.PerDeviceNodeSelection != nil) {
+		found = true
+		if drop {
+			slice.SharedCounters = nil
+			slice.PerDeviceNodeSelection = nil
+		}
+	}
+	for i := range slice.Devices {
+		device := &slice.Devices[i]
+		if !features.DeviceTaints && device.Taints != nil {
+			found = true
+			if drop {
+				device.Taints = nil
+			}
+		}
+		if !features.PartitionableDevices && (device.ConsumesCounters != nil || device.NodeName != nil || device.NodeSelector != nil || device.AllNodes != nil) {
+			found = true
+			if drop {
+				device.ConsumesCounters = nil
+				device.NodeName = nil
+				device.NodeSelector = nil
+				device.AllNodes = nil
+			}
+		}
+		if !features.DeviceBinding && (device.BindsToNode != nil || device.BindingConditions != nil || device.BindingFailureConditions != nil) {
+			found = true
+			if drop {
+				device.BindsToNode = nil
+				device.BindingConditions = nil
+				device.BindingFailureConditions = nil
+			}
+		}
+		if !features.ConsumableCapacity {
+			if device.AllowMultipleAllocations != nil {
+				found = true
+				if drop {
+					device.AllowMultipleAllocations = nil
+				}
+			}
+			for name, capacity := range device.Capacity {
+				if capacity.RequestPolicy != nil {
+					found = true
+					if drop {
+						capacity.RequestPolicy = nil
+						device.Capacity[name] = capacity
+					}
+				}
+			}
+		}
+		if found && !drop {
+			return true
+		}
+	}
+	return found
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	cgocoordination "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+)
+
+// DefaultFencingLeaseDuration is the default for [Fencing.LeaseDuration].
+const DefaultFencingLeaseDuration = 15 * time.Second
+
+// Fencing configures a Lease which the controller must hold before
+// it creates, updates or deletes ResourceSlices. When accidentally
+// running two instances of the same driver for the same owner
+// (for example, a stale DaemonSet pod plus a new one), only one
+// of them publishes while the other reports a [FencingError].
+//
+// The lease gets renewed in the background while the controller runs.
+// An instance which does not hold the lease takes it over once the
+// holder stopped renewing it for the lease duration.
+type Fencing struct {
+	// Namespace is the required namespace of the Lease.
+	Namespace string
+
+	// Identity is the required, unique identity of the controller
+	// instance, for example the name of the pod in which it runs.
+	Identity string
+
+	// LeaseName can be used to override the default name of the Lease,
+	// which is "<owner name>-<driver name>" or just the driver name
+	// if there is no owner.
+	LeaseName string
+
+	// LeaseDuration is how long the lease remains valid after
+	// the most recent renewal. The default is [DefaultFencingLeaseDuration].
+	LeaseDuration *time.Duration
+}
+
+// FencingError is returned by the controller when it refuses to publish
+// because some other instance holds the Lease configured via [Options.Fencing].
+// It gets passed to the ErrorHandler and is also returned by [Controller.SyncError].
+type FencingError struct {
+	// LeaseName is the "<namespace>/<name>" of the Lease.
+	LeaseName string
+	// Holder is the identity of the current holder of the Lease.
+	Holder string
+	// Expires is when the Lease will expire unless renewed by the holder.
+	Expires time.Time
+}
+
+func (err *FencingError) Error() string {
+	return fmt.Sprintf("lease %s is held by %q until %s, refusing to publish ResourceSlices", err.LeaseName, err.Holder, err.Expires.Format(time.RFC3339))
+}
+
+var _ error = &FencingError{}
+
+// fencer acquires and renews the Lease. In contrast to the
+// LeaderElector from client-go, it checks synchronously whether the
+// lease is held, which is what the controller needs before each
+// change. Reading and writing the Lease is done by the same
+// [resourcelock.LeaseLock] that the LeaderElector uses.
+type fencer struct {
+	lock          *resourcelock.LeaseLock
+	leaseDuration time.Duration
+	clock         clock.PassiveClock
+
+	mutex sync.Mutex
+	// heldUntil is the time until which the lease is considered held
+	// without checking again. It includes a safety margin.
+	heldUntil time.Time
+}
+
+func newFencer(client cgocoordination.CoordinationV1Interface, fencing *Fencing, driverName string, owner *Owner, clk clock.PassiveClock) (*fencer, error) {
+	if fencing.Namespace == "" {
+		return nil, errors.New("lease namespace is empty")
+	}
+	if fencing.Identity == "" {
+		return nil, errors.New("lease identity is empty")
+	}
+	name := fencing.LeaseName
+	if name == "" {
+		name = driverName
+		if owner != nil {
+			name = owner.Name + "-" + driverName
+		}
+	}
+	f := &fencer{
+		lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: fencing.Namespace, Name: name},
+			Client:     client,
+			LockConfig: resourcelock.ResourceLockConfig{Identity: fencing.Identity},
+		},
+		leaseDuration: ptr.Deref(fencing.LeaseDuration, DefaultFencingLeaseDuration),
+		clock:         clk,
+	}
+	if f.clock == nil {
+		f.clock = clock.RealClock{}
+	}
+	if f.leaseDuration < time.Second {
+		return nil, fmt.Errorf("lease duration %s is less than one second", f.leaseDuration)
+	}
+	return f, nil
+}
+
+// check returns nil if the lease is held, acquiring or renewing it if necessary.
+func (f *fencer) check(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.clock.Now().Before(f.heldUntil) {
+		return nil
+	}
+	return f.acquireLocked(ctx)
+}
+
+// run renews the lease periodically until the context is canceled.
+// Problems only get logged. They are reported through the ErrorHandler
+// when syncing a pool fails because of them.
+func (f *fencer) run(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		var fencingErr *FencingError
+		err := f.acquireLocked(ctx)
+		switch {
+		case err == nil, ctx.Err() != nil:
+		case errors.As(err, &fencingErr):
+			logger.V(5).Info("Lease is held by someone else", "lease", fencingErr.LeaseName, "holder", fencingErr.Holder, "expires", fencingErr.Expires)
+		default:
+			logger.Error(err, "Renewing lease failed")
+		}
+	}, f.leaseDuration/3)
+}
+
+func (f *fencer) acquireLocked(ctx context.Context) error {
+	logger := klog.FromContext(ctx)
+	now := metav1.NewTime(f.clock.Now())
+	identity := f.lock.Identity()
+	record := resourcelock.LeaderElectionRecord{
+		HolderIdentity:       identity,
+		LeaseDurationSeconds: int(f.leaseDuration.Seconds()),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	oldRecord, _, err := f.lock.Get(ctx)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := f.lock.Create(ctx, record); err != nil {
+			return fmt.Errorf("create lease %s: %w", f.lock.Describe(), err)
+		}
+		logger.V(3).Info("Acquired new lease", "lease", f.lock.Describe(), "identity", identity)
+	case err != nil:
+		return fmt.Errorf("get lease %s: %w", f.lock.Describe(), err)
+	default:
+		holder := oldRecord.HolderIdentity
+		if holder != "" && holder != identity && !oldRecord.RenewTime.IsZero() {
+			duration := time.Duration(oldRecord.LeaseDurationSeconds) * time.Second
+			if expires := oldRecord.RenewTime.Add(duration); expires.After(now.Time) {
+				f.heldUntil = time.Time{}
+				return &FencingError{LeaseName: f.lock.Describe(), Holder: holder, Expires: expires}
+			}
+		}
+		record.LeaderTransitions = oldRecord.LeaderTransitions
+		record.Strategy = oldRecord.Strategy
+		record.PreferredHolder = oldRecord.PreferredHolder
+		if holder == identity {
+			record.AcquireTime = oldRecord.AcquireTime
+		} else {
+			record.LeaderTransitions++
+		}
+		// The lock updates the Lease that it got above. The resource
+		// version precondition ensures that only one instance succeeds
+		// when several try to take over at once.
+		if err := f.lock.Update(ctx, record); err != nil {
+			f.heldUntil = time.Time{}
+			return fmt.Errorf("update lease %s: %w", f.lock.Describe(), err)
+		}
+		if holder != identity {
+			logger.V(3).Info("Took over lease", "lease", f.lock.Describe(), "identity", identity, "previousHolder", holder)
+		}
+	}
+
+	// Renew well before the lease expires to leave time for the
+	// API calls which depend on holding it.
+	f.heldUntil = now.Add(f.leaseDuration / 2)
+	return nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/dynamic-resource-allocation/deviceattribute"
+	"k8s.io/utils/ptr"
+)
+
+// DeviceHealth is the health of a device as determined by the driver.
+type DeviceHealth string
+
+const (
+	// DeviceHealthy is the default for all devices.
+	DeviceHealthy DeviceHealth = "Healthy"
+	// DeviceDegraded is for devices which work, but not as well as they should.
+	DeviceDegraded DeviceHealth = "Degraded"
+	// DeviceUnhealthy is for devices which cannot be used.
+	DeviceUnhealthy DeviceHealth = "Unhealthy"
+)
+
+// HealthAction determines how devices with a certain health
+// get published.
+type HealthAction string
+
+const (
+	// HealthActionNone publishes the device unchanged.
+	HealthActionNone HealthAction = ""
+	// HealthActionTaint adds a taint with [HealthPolicy.TaintKey]
+	// and the health as value.
+	HealthActionTaint HealthAction = "Taint"
+	// HealthActionDrop removes the device from its slice.
+	HealthActionDrop HealthAction = "Drop"
+	// HealthActionAttribute sets the [HealthPolicy.AttributeName]
+	// attribute to the health. Healthy devices are published without
+	// the attribute.
+	HealthActionAttribute HealthAction = "Attribute"
+)
+
+// DefaultHealthTaintKey is the default for [HealthPolicy.TaintKey].
+const DefaultHealthTaintKey = deviceattribute.StandardDeviceAttributePrefix + "health"
+
+// HealthPolicy configures how the controller publishes devices
+// which were marked as not healthy with [Controller.SetDeviceHealth].
+type HealthPolicy struct {
+	// Degraded is the action for devices marked as [DeviceDegraded].
+	Degraded HealthAction
+	// DegradedTaintEffect is used for taints of degraded devices.
+	// The default is NoSchedule.
+	DegradedTaintEffect resourceapi.DeviceTaintEffect
+
+	// Unhealthy is the action for devices marked as [DeviceUnhealthy].
+	Unhealthy HealthAction
+	// UnhealthyTaintEffect is used for taints of unhealthy devices.
+	// The default is NoExecute.
+	UnhealthyTaintEffect resourceapi.DeviceTaintEffect
+
+	// TaintKey is used for taints. The default is [DefaultHealthTaintKey].
+	TaintKey string
+	// AttributeName is used for attributes. The default is
+	// [deviceattribute.StandardDeviceAttributeHealth].
+	AttributeName resourceapi.QualifiedName
+}
+
+func (p *HealthPolicy) validate() error {
+	for health, action := range map[DeviceHealth]HealthAction{DeviceDegraded: p.Degraded, DeviceUnhealthy: p.Unhealthy} {
+		switch action {
+		case HealthActionNone, HealthActionTaint, HealthActionDrop, HealthActionAttribute:
+		default:
+			return fmt.Errorf("unsupported action %q for %s devices", action, health)
+		}
+	}
+	return nil
+}
+
+func (p *HealthPolicy) action(health DeviceHealth) (HealthAction, resourceapi.DeviceTaintEffect) {
+	switch health {
+	case DeviceDegraded:
+		return p.Degraded, cmp.Or(p.DegradedTaintEffect, resourceapi.DeviceTaintEffectNoSchedule)
+	case DeviceUnhealthy:
+		return p.Unhealthy, cmp.Or(p.UnhealthyTaintEffect, resourceapi.DeviceTaintEffectNoExecute)
+	default:
+		return HealthActionNone, ""
+	}
+}
+
+// SetDeviceHealth changes the health of a device in a pool. The device
+// then gets published according to the [Options.HealthPolicy]. The health
+// is remembered across [Controller.Update] calls, even if the device
+// is temporarily not part of the desired resources. Setting
+// [DeviceHealthy] forgets about the device.
+//
+// Without a HealthPolicy, the controller only remembers the health.
+func (c *Controller) SetDeviceHealth(poolName, deviceName string, health DeviceHealth) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.deviceHealth[poolName][deviceName] == health ||
+		health == DeviceHealthy && c.deviceHealth[poolName][deviceName] == "" {
+		return
+	}
+	if health == DeviceHealthy {
+		delete(c.deviceHealth[poolName], deviceName)
+		if len(c.deviceHealth[poolName]) == 0 {
+			delete(c.deviceHealth, poolName)
+		}
+	} else {
+		if c.deviceHealth == nil {
+			c.deviceHealth = make(map[string]map[string]DeviceHealth)
+		}
+		if c.deviceHealth[poolName] == nil {
+			c.deviceHealth[poolName] = make(map[string]DeviceHealth)
+		}
+		c.deviceHealth[poolName][deviceName] = health
+	}
+	if c.lastUpdate == nil {
+		// Not initialized yet, Update will take care of applying the health.
+		return
+	}
+	if pool, ok := c.lastUpdate.Pools[poolName]; ok {
+		// syncPool reads c.resources without holding the mutex,
+		// so the map must be replaced instead of modified.
+		pool = *pool.DeepCopy()
+		c.applyHealthLocked(poolName, &pool)
+		c.resources = &DriverResources{Pools: maps.Clone(c.resources.Pools)}
+		c.resources.Pools[poolName] = pool
+		c.queue.Add(poolName)
+	}
+}
+
+// DeviceHealth returns the health of a device as set by [Controller.SetDeviceHealth].
+func (c *Controller) DeviceHealth(poolName, deviceName string) DeviceHealth {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if health, ok := c.deviceHealth[poolName][deviceName]; ok {
+		return health
+	}
+	return DeviceHealthy
+}
+
+// applyHealthLocked modifies the pool in place according to the policy.
+// The pool must not be shared.
+func (c *Controller) applyHealthLocked(poolName string, pool *Pool) {
+	if c.healthPolicy == nil || len(c.deviceHealth[poolName]) == 0 {
+		return
+	}
+	taintKey := cmp.Or(c.healthPolicy.TaintKey, DefaultHealthTaintKey)
+	attributeName := cmp.Or(c.healthPolicy.AttributeName, deviceattribute.StandardDeviceAttributeHealth)
+	for i := range pool.Slices {
+		slice := &pool.Slices[i]
+		// Health is tracked per device, so generated devices must
+		// exist individually. Invalid templates are left alone
+		// for syncPool to report.
+		_ = slice.expandDeviceTemplates()
+		slice.Devices = slices.DeleteFunc(slice.Devices, func(device resourceapi.Device) bool {
+			action, _ := c.healthPolicy.action(c.deviceHealth[poolName][device.Name])
+			return action == HealthActionDrop
+		})
+		for j := range slice.Devices {
+			device := &slice.Devices[j]
+			health := c.deviceHealth[poolName][device.Name]
+			switch action, effect := c.healthPolicy.action(health); action {
+			case HealthActionTaint:
+				device.Taints = append(device.Taints, resourceapi.DeviceTaint{
+					Key:    taintKey,
+					Value:  string(health),
+					Effect: effect,
+				})
+			case HealthActionAttribute:
+				if device.Attributes == nil {
+					device.Attributes = make(map[resourceapi.QualifiedName]resourceapi.DeviceAttribute)
+				}
+				device.Attributes[attributeName] = resourceapi.DeviceAttribute{StringValue: ptr.To(string(health))}
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"k8s.io/component-base/metrics"
+	drametrics "k8s.io/dynamic-resource-allocation/metrics"
+)
+
+const (
+	metricsNamespace = drametrics.Namespace
+	metricsSubsystem = drametrics.SubsystemResourceSlice
+)
+
+// Values of the "result" label.
+const (
+	syncResultSuccess = "success"
+	syncResultError   = "error"
+)
+
+var (
+	poolSyncDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "pool_sync_duration_seconds",
+			Help:           "Duration of syncing the ResourceSlices of one pool with the desired state, by driver and result (success, error).",
+			Buckets:        metrics.ExponentialBuckets(0.001, 4, 10),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "result"},
+	)
+	sliceOperations = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "slice_operations_total",
+			Help:           "Number of ResourceSlices which were created, updated or deleted, by driver and operation.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "operation"},
+	)
+	publishLatencySeconds = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "publish_latency_seconds",
+			Help:           "Time from an Update call which changed a pool until the resulting ResourceSlices were observed in the informer cache, by driver.",
+			Buckets:        metrics.ExponentialBuckets(0.01, 2, 14),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name"},
+	)
+)
+
+func init() {
+	drametrics.Add(metricsSubsystem, poolSyncDuration, sliceOperations, publishLatencySeconds)
+}
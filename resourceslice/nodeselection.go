@@ -0,0 +1,71 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// validateNodeSelection checks that each slice of the pool ends up with
+// exactly one way of selecting nodes: the node name of a node owner,
+// a node selector, per-device node selection, or all nodes.
+func validateNodeSelection(poolName string, pool Pool, nodeName string) error {
+	if pool.NodeSelector != nil {
+		if nodeName != "" {
+			return fmt.Errorf("pool %q: node selector must not be set for the node-local resources of node %q", poolName, nodeName)
+		}
+		if len(pool.NodeSelector.NodeSelectorTerms) == 0 {
+			return fmt.Errorf("pool %q: node selector must have at least one term", poolName)
+		}
+	}
+	for i, slice := range pool.Slices {
+		if slice.NodeSelector == nil {
+			continue
+		}
+		var err error
+		switch {
+		case nodeName != "":
+			err = fmt.Errorf("must not be set for the node-local resources of node %q", nodeName)
+		case pool.NodeSelector != nil:
+			err = errors.New("must not be set together with a node selector for the pool")
+		case ptr.Deref(slice.PerDeviceNodeSelection, false):
+			err = errors.New("must not be set together with per-device node selection")
+		case len(slice.NodeSelector.NodeSelectorTerms) == 0:
+			err = errors.New("must have at least one term")
+		}
+		if err != nil {
+			return fmt.Errorf("pool %q, slice #%d: node selector %w", poolName, i, err)
+		}
+	}
+	return nil
+}
+
+// desiredNodeSelection returns the NodeSelector and AllNodes
+// fields for a slice of the pool.
+func desiredNodeSelection(pool Pool, sliceIndex int, nodeName string) (*v1.NodeSelector, bool) {
+	slice := pool.Slices[sliceIndex]
+	nodeSelector := pool.NodeSelector
+	if slice.NodeSelector != nil {
+		nodeSelector = slice.NodeSelector
+	}
+	allNodes := nodeName == "" && nodeSelector == nil && !ptr.Deref(slice.PerDeviceNodeSelection, false)
+	return nodeSelector, allNodes
+}
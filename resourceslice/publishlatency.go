@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// publishLatency measures the time from an Update call which changed a pool
+// until the informer has observed the ResourceSlices written for that change.
+type publishLatency struct {
+	mutex sync.Mutex
+	// start contains the time of the oldest Update call
+	// which has not been observed yet, by pool.
+	start map[string]time.Time
+	// pending contains the writes of the most recent successful sync,
+	// by pool, for those pools which have a start time.
+	pending map[string]*poolWrites
+}
+
+// poolWrites describes the ResourceSlice changes made by one sync.
+type poolWrites struct {
+	// stored has the resource version of each created or updated slice.
+	stored  map[string]string
+	deleted sets.Set[string]
+}
+
+func (w *poolWrites) sliceStored(slice *resourceapi.ResourceSlice) {
+	if w.stored == nil {
+		w.stored = make(map[string]string)
+	}
+	w.stored[slice.Name] = slice.ResourceVersion
+}
+
+func (w *poolWrites) sliceDeleted(slice *resourceapi.ResourceSlice) {
+	if w.deleted == nil {
+		w.deleted = sets.New[string]()
+	}
+	w.deleted.Insert(slice.Name)
+}
+
+// publishRequested gets called by Update for each pool which needs to be synced.
+func (c *Controller) publishRequested(poolName string) {
+	c.publishLatency.mutex.Lock()
+	defer c.publishLatency.mutex.Unlock()
+
+	if c.publishLatency.start == nil {
+		c.publishLatency.start = make(map[string]time.Time)
+		c.publishLatency.pending = make(map[string]*poolWrites)
+	}
+	if _, ok := c.publishLatency.start[poolName]; !ok {
+		c.publishLatency.start[poolName] = c.clock.Now()
+	}
+}
+
+// publishSynced gets called after a successful sync of the pool.
+func (c *Controller) publishSynced(poolName string, writes *poolWrites) {
+	c.publishLatency.mutex.Lock()
+	if _, ok := c.publishLatency.start[poolName]; !ok {
+		c.publishLatency.mutex.Unlock()
+		return
+	}
+	c.publishLatency.pending[poolName] = writes
+	c.publishLatency.mutex.Unlock()
+
+	c.checkPublished(poolName)
+}
+
+// checkPublished observes the publish latency of the pool if the informer
+// cache contains the result of the most recent sync.
+func (c *Controller) checkPublished(poolName string) {
+	c.publishLatency.mutex.Lock()
+	defer c.publishLatency.mutex.Unlock()
+
+	start, ok := c.publishLatency.start[poolName]
+	if !ok {
+		return
+	}
+	writes, ok := c.publishLatency.pending[poolName]
+	if !ok {
+		return
+	}
+	for name, resourceVersion := range writes.stored {
+		obj, exists, err := c.informerStore.GetByKey(name)
+		if err != nil || !exists {
+			return
+		}
+		slice, ok := obj.(*resourceapi.ResourceSlice)
+		if !ok || !resourceVersionAtLeast(slice.ResourceVersion, resourceVersion) {
+			return
+		}
+	}
+	for name := range writes.deleted {
+		if _, exists, err := c.informerStore.GetByKey(name); err != nil || exists {
+			return
+		}
+	}
+	publishLatencySeconds.WithLabelValues(c.driverName).Observe(c.clock.Since(start).Seconds())
+	delete(c.publishLatency.start, poolName)
+	delete(c.publishLatency.pending, poolName)
+}
+
+// resourceVersionAtLeast treats resource versions as integers, like the
+// mutation cache does. Other resource versions must be equal.
+func resourceVersionAtLeast(actual, expected string) bool {
+	if actual == expected {
+		return true
+	}
+	a, errA := strconv.ParseUint(actual, 10, 64)
+	e, errE := strconv.ParseUint(expected, 10, 64)
+	return errA == nil && errE == nil && a >= e
+}
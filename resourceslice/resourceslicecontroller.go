@@ -20,12 +20,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
 	v1 "k8s.io/api/core/v1"
 	resourceapi "k8s.io/api/resource/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -44,11 +50,17 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	draclient "k8s.io/dynamic-resource-allocation/client"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/dynamic-resource-allocation/tracing"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/ptr"
 )
 
 const (
+	// instrumentationName is used for the OpenTelemetry tracer.
+	instrumentationName = "k8s.io/dynamic-resource-allocation/resourceslice"
+
 	// poolNameIndex is the name for the ResourceSlice store's index function,
 	// which is to index by ResourceSlice.Spec.Pool.Name
 	poolNameIndex = "poolName"
@@ -82,9 +94,13 @@ type Controller struct {
 	// The queue is keyed with the pool name that needs work.
 	queue            workqueue.TypedRateLimitingInterface[string]
 	sliceStore       cache.MutationCache
+	informerStore    cache.Store
 	mutationCacheTTL time.Duration
 	syncDelay        time.Duration
 	errorHandler     func(ctx context.Context, err error, msg string)
+	features         *featuregates.Features
+	tracer           trace.Tracer
+	clock            clock.PassiveClock
 
 	// Last time that a ResourceSlice of a pool was created.
 	// At that time + cache mutation TTL do we have to sync again
@@ -101,6 +117,12 @@ type Controller struct {
 	// unique for syncPool calls for the pool.
 	lastAddByPool map[string]time.Time
 
+	// syncErrors contains the most recent error for each pool
+	// which has not been synced successfully since then.
+	// Protected by syncErrorsMutex.
+	syncErrors      map[string]error
+	syncErrorsMutex sync.Mutex
+
 	// Must use atomic access...
 	numCreates int64
 	numUpdates int64
@@ -112,6 +134,25 @@ type Controller struct {
 	// so it is okay to not do a deep copy of it when reading it. Only reading
 	// the pointer itself must be protected by a read lock.
 	resources *DriverResources
+
+	// lastUpdate is an unmodified copy of the resources from the
+	// most recent Update call. Protected by the mutex.
+	lastUpdate *DriverResources
+
+	// deviceHealth contains the health of all devices which are
+	// not healthy, by pool and device name. Protected by the mutex.
+	deviceHealth map[string]map[string]DeviceHealth
+	healthPolicy *HealthPolicy
+	// attributeSchema is nil if devices are published unchecked.
+	attributeSchema *AttributeSchema
+
+	// fencer is non-nil if publishing depends on holding a lease.
+	fencer *fencer
+
+	publishLatency publishLatency
+	// writes collects the changes made by the current sync.
+	// Only used by the single worker.
+	writes *poolWrites
 }
 
 // +k8s:deepcopy-gen=true
@@ -129,6 +170,10 @@ type Pool struct {
 	// NodeSelector may be different for each pool. Must not get set together
 	// with Resources.NodeName. If nil and Resources.NodeName is not set,
 	// then devices are available on all nodes.
+	//
+	// A pool of network-attached devices which spans several groups
+	// of nodes, for example one per rack, can leave this nil and
+	// set a NodeSelector in each slice instead.
 	NodeSelector *v1.NodeSelector
 
 	// Generation can be left at zero. It gets bumped up automatically
@@ -160,6 +205,16 @@ type Slice struct {
 	Devices                []resourceapi.Device
 	SharedCounters         []resourceapi.CounterSet
 	PerDeviceNodeSelection *bool
+
+	// NodeSelector defines the nodes on which the devices of this
+	// slice are available. It must not get set together with
+	// Pool.NodeSelector, a node owner or PerDeviceNodeSelection.
+	// If nil, the Pool.NodeSelector applies.
+	NodeSelector *v1.NodeSelector
+
+	// DeviceTemplates generate additional devices which get published
+	// after the ones in Devices.
+	DeviceTemplates []DeviceTemplate
 }
 
 // +k8s:deepcopy-gen=true
@@ -247,6 +302,45 @@ type Options struct {
 	// The default is [utilruntime.HandleErrorWithContext] which just logs
 	// the problem.
 	ErrorHandler func(ctx context.Context, err error, msg string)
+
+	// Features, if set, describes which features are enabled in the
+	// cluster. Fields of slices and devices which depend on disabled
+	// features then get removed before publishing and the ErrorHandler
+	// is called once with a [DroppedFieldsError]. Without it, the
+	// apiserver drops those fields and the controller only notices
+	// after storing a slice.
+	Features *featuregates.Features
+
+	// TracerProvider enables OpenTelemetry tracing. Each
+	// synchronization of a pool then creates a "PublishPool" span with
+	// the driver, pool and devices as attributes, using the keys from
+	// the tracing package. The default is to not trace.
+	TracerProvider trace.TracerProvider
+
+	// HealthPolicy determines how devices get published after the driver
+	// marked them as degraded or unhealthy with [Controller.SetDeviceHealth].
+	// The default is to publish them unchanged.
+	HealthPolicy *HealthPolicy
+
+	// AttributeSchema, if set, is used to check the attributes
+	// of all devices before publishing them.
+	AttributeSchema *AttributeSchema
+
+	// Clock can be used to replace the real time in tests. It determines
+	// when pools get synced again and when the fencing lease expires.
+	// The mutation cache and device discovery always use the real time.
+	Clock clock.PassiveClock
+
+	// Fencing, if set, makes the controller acquire and renew a Lease
+	// and refuse to publish while some other instance holds it.
+	// The KubeClient must have permission to get, create and update
+	// that Lease.
+	Fencing *Fencing
+
+	// Discovery, if set, lets the controller call [Controller.Update]
+	// with the resources found by a [DeviceDiscoverer]. Resources
+	// are then usually left unset.
+	Discovery *Discovery
 }
 
 // DroppedFieldsError is reported through the ErrorHandler in [Options] if
@@ -255,6 +349,10 @@ type DroppedFieldsError struct {
 	PoolName                  string
 	SliceIndex                int
 	DesiredSlice, ActualSlice *resourceapi.ResourceSlice
+	// Local is true if the controller itself removed the fields because
+	// of the Features in [Options]. ActualSlice then only has the spec
+	// of the slice that gets published.
+	Local bool
 }
 
 func (err *DroppedFieldsError) Error() string {
@@ -265,6 +363,9 @@ func (err *DroppedFieldsError) Error() string {
 		// If we get here, DisabledFeatures needs to be updated.
 		disabled = []string{"unknown"}
 	}
+	if err.Local {
+		return fmt.Sprintf("pool %q, slice #%d: some fields were dropped because these features are disabled: %s", err.PoolName, err.SliceIndex, strings.Join(disabled, " "))
+	}
 	return fmt.Sprintf("pool %q, slice #%d: some fields were dropped by the apiserver, probably because these features are disabled: %s", err.PoolName, err.SliceIndex, strings.Join(disabled, " "))
 }
 
@@ -274,7 +375,7 @@ func (err *DroppedFieldsError) DisabledFeatures() []string {
 	// Both slices should have the same number of devices, but better check it.
 	for i := 0; i < len(err.DesiredSlice.Spec.Devices) && i < len(err.ActualSlice.Spec.Devices); i++ {
 		if len(err.DesiredSlice.Spec.Devices[i].Taints) > len(err.ActualSlice.Spec.Devices[i].Taints) {
-			disabled = append(disabled, "DRADeviceTaints")
+			disabled = append(disabled, featuregates.DRADeviceTaints)
 			break
 		}
 	}
@@ -282,7 +383,7 @@ func (err *DroppedFieldsError) DisabledFeatures() []string {
 	// Dropped fields for partitionable devices can be detected without looking at the devices themselves.
 	if ptr.Deref(err.DesiredSlice.Spec.PerDeviceNodeSelection, false) && !ptr.Deref(err.ActualSlice.Spec.PerDeviceNodeSelection, false) ||
 		len(err.DesiredSlice.Spec.SharedCounters) > len(err.ActualSlice.Spec.SharedCounters) {
-		disabled = append(disabled, "DRAPartitionableDevices")
+		disabled = append(disabled, featuregates.DRAPartitionableDevices)
 	}
 
 	// The number of binding conditions for both slices should be the same. If they differ,
@@ -290,7 +391,7 @@ func (err *DroppedFieldsError) DisabledFeatures() []string {
 	for i := 0; i < len(err.DesiredSlice.Spec.Devices) && i < len(err.ActualSlice.Spec.Devices); i++ {
 		if len(err.DesiredSlice.Spec.Devices[i].BindingConditions) != len(err.ActualSlice.Spec.Devices[i].BindingConditions) ||
 			len(err.DesiredSlice.Spec.Devices[i].BindingFailureConditions) != len(err.ActualSlice.Spec.Devices[i].BindingFailureConditions) {
-			disabled = append(disabled, "DRADeviceBindingConditions")
+			disabled = append(disabled, featuregates.DRADeviceBindingConditions)
 			break
 		}
 	}
@@ -298,7 +399,7 @@ func (err *DroppedFieldsError) DisabledFeatures() []string {
 	// Dropped fields for consumable capacity can be detected with allowMultipleAllocations flag without looking at individual device capacity.
 	for i := 0; i < len(err.DesiredSlice.Spec.Devices) && i < len(err.ActualSlice.Spec.Devices); i++ {
 		if err.DesiredSlice.Spec.Devices[i].AllowMultipleAllocations != nil && err.ActualSlice.Spec.Devices[i].AllowMultipleAllocations == nil {
-			disabled = append(disabled, "DRAConsumableCapacity")
+			disabled = append(disabled, featuregates.DRAConsumableCapacity)
 			break
 		}
 	}
@@ -322,26 +423,85 @@ func (c *Controller) Stop() {
 // The controller is doing a deep copy, so the caller may update
 // the instance once Update returns. Nil is valid and the same
 // as an empty resources struct.
+//
+// Update may be called at any time, for example after hot-plugging
+// devices. Only pools which were added, removed or modified compared
+// to the previous call get synced again, so unchanged pools
+// cause no API calls. In particular, calling Update with the same
+// content does not repair ResourceSlices which were modified by
+// someone else. Use [Controller.Resync] for that.
 func (c *Controller) Update(resources *DriverResources) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// Sync all old pools..
-	if c.resources != nil {
-		for poolName := range c.resources.Pools {
-			c.queue.Add(poolName)
+	// c.resources may get modified by syncPool, so we have to compare
+	// against a separate copy of what was passed in last time.
+	oldResources := c.lastUpdate
+	if resources == nil {
+		c.lastUpdate = &DriverResources{}
+	} else {
+		c.lastUpdate = resources.DeepCopy()
+		roundTaintTimeAdded(c.lastUpdate)
+	}
+	c.resources = c.lastUpdate.DeepCopy()
+	for poolName, pool := range c.resources.Pools {
+		if len(c.deviceHealth[poolName]) > 0 {
+			c.applyHealthLocked(poolName, &pool)
+			c.resources.Pools[poolName] = pool
 		}
 	}
 
-	if resources == nil {
-		c.resources = &DriverResources{}
-	} else {
-		c.resources = resources.DeepCopy()
-		roundTaintTimeAdded(c.resources)
+	// Sync all old pools which were removed or changed...
+	if oldResources != nil {
+		for poolName, oldPool := range oldResources.Pools {
+			if newPool, ok := c.lastUpdate.Pools[poolName]; !ok || !apiequality.Semantic.DeepEqual(oldPool, newPool) {
+				c.publishRequested(poolName)
+				c.queue.Add(poolName)
+			}
+		}
 	}
 
-	// ... and the new ones (might be the same).
+	// ... and the new ones.
 	for poolName := range c.resources.Pools {
+		if oldResources == nil {
+			c.publishRequested(poolName)
+			c.queue.Add(poolName)
+			continue
+		}
+		if _, ok := oldResources.Pools[poolName]; !ok {
+			c.publishRequested(poolName)
+			c.queue.Add(poolName)
+		}
+	}
+}
+
+// Resync syncs all pools again, regardless of whether they have changed.
+// This includes pools for which only ResourceSlices exist. Syncing
+// repairs ResourceSlices which were modified or deleted by someone else.
+//
+// The controller notices such modifications through its informer and
+// repairs them automatically, so calling Resync is only necessary
+// if that is not sufficient, for example after restoring the cluster
+// state from a backup.
+func (c *Controller) Resync() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	poolNames := sets.New[string]()
+	if c.resources != nil {
+		for poolName := range c.resources.Pools {
+			poolNames.Insert(poolName)
+		}
+	}
+	if c.informerStore != nil {
+		for _, obj := range c.informerStore.List() {
+			if slice, ok := obj.(*resourceapi.ResourceSlice); ok {
+				poolNames.Insert(slice.Spec.Pool.Name)
+			}
+		}
+	}
+	for poolName := range poolNames {
+		c.publishRequested(poolName)
 		c.queue.Add(poolName)
 	}
 }
@@ -373,6 +533,37 @@ func (c *Controller) GetStats() Stats {
 	return s
 }
 
+// SyncError returns an error if syncing of some pool failed and that pool
+// has not been synced successfully since then. It returns nil if all
+// pools were synced successfully or are pending their first sync.
+//
+// This can be used to implement health checks.
+func (c *Controller) SyncError() error {
+	c.syncErrorsMutex.Lock()
+	defer c.syncErrorsMutex.Unlock()
+
+	poolNames := slices.Sorted(maps.Keys(c.syncErrors))
+	errs := make([]error, 0, len(poolNames))
+	for _, poolName := range poolNames {
+		errs = append(errs, fmt.Errorf("pool %q: %w", poolName, c.syncErrors[poolName]))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Controller) setSyncError(poolName string, err error) {
+	c.syncErrorsMutex.Lock()
+	defer c.syncErrorsMutex.Unlock()
+
+	if err == nil {
+		delete(c.syncErrors, poolName)
+		return
+	}
+	if c.syncErrors == nil {
+		c.syncErrors = make(map[string]error)
+	}
+	c.syncErrors[poolName] = err
+}
+
 type Stats struct {
 	// NumCreates counts the number of ResourceSlices that got created.
 	NumCreates int64
@@ -390,6 +581,35 @@ func newController(ctx context.Context, options Options) (*Controller, error) {
 	if options.DriverName == "" {
 		return nil, errors.New("DRA driver name is empty")
 	}
+	if options.Features != nil {
+		if err := options.Features.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid features: %w", err)
+		}
+	}
+
+	if options.HealthPolicy != nil {
+		if err := options.HealthPolicy.validate(); err != nil {
+			return nil, fmt.Errorf("invalid health policy: %w", err)
+		}
+	}
+
+	if options.AttributeSchema != nil {
+		if err := options.AttributeSchema.validate(); err != nil {
+			return nil, fmt.Errorf("invalid attribute schema: %w", err)
+		}
+	}
+
+	if options.Discovery != nil && options.Discovery.Discoverer == nil {
+		return nil, errors.New("device discoverer is nil")
+	}
+	var fencer *fencer
+	if options.Fencing != nil {
+		var err error
+		fencer, err = newFencer(options.KubeClient.CoordinationV1(), options.Fencing, options.DriverName, options.Owner, options.Clock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fencing: %w", err)
+		}
+	}
 
 	ctx, cancel := context.WithCancelCause(ctx)
 
@@ -403,7 +623,12 @@ func newController(ctx context.Context, options Options) (*Controller, error) {
 		mutationCacheTTL: ptr.Deref(options.MutationCacheTTL, DefaultMutationCacheTTL),
 		syncDelay:        ptr.Deref(options.SyncDelay, DefaultSyncDelay),
 		errorHandler:     options.ErrorHandler,
+		features:         options.Features,
 		lastAddByPool:    make(map[string]time.Time),
+		healthPolicy:     options.HealthPolicy,
+		attributeSchema:  options.AttributeSchema,
+		clock:            options.Clock,
+		fencer:           fencer,
 	}
 	if c.queue == nil {
 		c.queue = workqueue.NewTypedRateLimitingQueueWithConfig(
@@ -411,16 +636,40 @@ func newController(ctx context.Context, options Options) (*Controller, error) {
 			workqueue.TypedRateLimitingQueueConfig[string]{Name: "node_resource_slices"},
 		)
 	}
+	if c.clock == nil {
+		c.clock = clock.RealClock{}
+	}
 	if c.errorHandler == nil {
 		c.errorHandler = func(ctx context.Context, err error, msg string) {
 			utilruntime.HandleErrorWithContext(ctx, err, msg)
 		}
 	}
+	tracerProvider := options.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = noop.NewTracerProvider()
+	}
+	c.tracer = tracerProvider.Tracer(instrumentationName)
 	if err := c.initInformer(ctx); err != nil {
 		return nil, err
 	}
+	if c.fencer != nil {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.fencer.run(ctx)
+		}()
+	}
 
 	c.Update(options.Resources)
+	// Must start after the initial Update, otherwise that
+	// might overwrite the first discovered resources.
+	if options.Discovery != nil {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.runDiscovery(ctx, options.Discovery)
+		}()
+	}
 
 	return c, nil
 }
@@ -476,6 +725,7 @@ func (c *Controller) initInformer(ctx context.Context) error {
 		0,
 		indexers,
 	)
+	c.informerStore = informer.GetStore()
 	c.sliceStore = cache.NewIntegerResourceVersionMutationCache(logger, informer.GetStore(), informer.GetIndexer(), c.mutationCacheTTL, true /* includeAdds */)
 	handler, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
@@ -484,8 +734,9 @@ func (c *Controller) initInformer(ctx context.Context) error {
 				return
 			}
 			logger.V(5).Info("ResourceSlice add", "slice", klog.KObj(slice))
+			c.checkPublished(slice.Spec.Pool.Name)
 			c.queue.AddAfter(slice.Spec.Pool.Name, c.syncDelay)
-			logger.V(5).Info("Scheduled sync", "poolName", slice.Spec.Pool.Name, "at", time.Now().Add(c.syncDelay))
+			logger.V(5).Info("Scheduled sync", "poolName", slice.Spec.Pool.Name, "at", c.clock.Now().Add(c.syncDelay))
 		},
 		UpdateFunc: func(old, new any) {
 			oldSlice, ok := old.(*resourceapi.ResourceSlice)
@@ -501,11 +752,12 @@ func (c *Controller) initInformer(ctx context.Context) error {
 			} else {
 				logger.V(5).Info("ResourceSlice update", "slice", klog.KObj(newSlice))
 			}
+			c.checkPublished(newSlice.Spec.Pool.Name)
 			c.queue.AddAfter(oldSlice.Spec.Pool.Name, c.syncDelay)
-			logger.V(5).Info("Scheduled sync", "pool", oldSlice.Spec.Pool.Name, "at", time.Now().Add(c.syncDelay))
+			logger.V(5).Info("Scheduled sync", "pool", oldSlice.Spec.Pool.Name, "at", c.clock.Now().Add(c.syncDelay))
 			if oldSlice.Spec.Pool.Name != newSlice.Spec.Pool.Name {
 				c.queue.AddAfter(newSlice.Spec.Pool.Name, c.syncDelay)
-				logger.V(5).Info("Scheduled sync", "poolName", newSlice.Spec.Pool.Name, "at", time.Now().Add(c.syncDelay))
+				logger.V(5).Info("Scheduled sync", "poolName", newSlice.Spec.Pool.Name, "at", c.clock.Now().Add(c.syncDelay))
 			}
 		},
 		DeleteFunc: func(obj any) {
@@ -517,8 +769,9 @@ func (c *Controller) initInformer(ctx context.Context) error {
 				return
 			}
 			logger.V(5).Info("ResourceSlice delete", "slice", klog.KObj(slice))
+			c.checkPublished(slice.Spec.Pool.Name)
 			c.queue.AddAfter(slice.Spec.Pool.Name, c.syncDelay)
-			logger.V(5).Info("Scheduled sync", "poolName", slice.Spec.Pool.Name, "at", time.Now().Add(c.syncDelay))
+			logger.V(5).Info("Scheduled sync", "poolName", slice.Spec.Pool.Name, "at", c.clock.Now().Add(c.syncDelay))
 		},
 	})
 	if err != nil {
@@ -558,7 +811,29 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	defer c.queue.Done(poolName)
 	logger := klog.FromContext(ctx)
 
-	err := c.syncPool(klog.NewContext(ctx, klog.LoggerWithValues(logger, "poolName", poolName)), poolName)
+	start := c.clock.Now()
+	syncCtx, span := c.tracer.Start(ctx, "PublishPool", trace.WithAttributes(
+		tracing.DriverKey.String(c.driverName),
+		tracing.PoolKey.String(poolName),
+	))
+	numCreates, numUpdates, numDeletes := atomic.LoadInt64(&c.numCreates), atomic.LoadInt64(&c.numUpdates), atomic.LoadInt64(&c.numDeletes)
+	c.writes = &poolWrites{}
+	err := c.syncPool(klog.NewContext(syncCtx, klog.LoggerWithValues(logger, "poolName", poolName)), poolName)
+	// There is only one worker, so the counters were only changed by this sync.
+	span.SetAttributes(
+		attribute.Int64("dra.resourceslices.created", atomic.LoadInt64(&c.numCreates)-numCreates),
+		attribute.Int64("dra.resourceslices.updated", atomic.LoadInt64(&c.numUpdates)-numUpdates),
+		attribute.Int64("dra.resourceslices.deleted", atomic.LoadInt64(&c.numDeletes)-numDeletes),
+	)
+	result := syncResultSuccess
+	if err != nil {
+		result = syncResultError
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+	poolSyncDuration.WithLabelValues(c.driverName, result).Observe(c.clock.Since(start).Seconds())
+	c.setSyncError(poolName, err)
 	if err != nil {
 		c.errorHandler(ctx, err, "processing ResourceSlice objects")
 		c.queue.AddRateLimited(poolName)
@@ -569,6 +844,7 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	}
 
 	c.queue.Forget(poolName)
+	c.publishSynced(poolName, c.writes)
 	return true
 }
 
@@ -577,7 +853,13 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 // be updated at any time by the user of the controller.
 func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 	logger := klog.FromContext(ctx)
-	start := time.Now()
+	start := c.clock.Now()
+
+	if c.fencer != nil {
+		if err := c.fencer.check(ctx); err != nil {
+			return err
+		}
+	}
 
 	// Gather information about the actual and desired state.
 	var slices []*resourceapi.ResourceSlice
@@ -596,6 +878,36 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 	c.mutex.RUnlock()
 
 	pool, ok := resources.Pools[poolName]
+	if ok {
+		pool, err = expandDeviceTemplates(poolName, pool)
+		if err != nil {
+			return err
+		}
+	}
+	if span := trace.SpanFromContext(ctx); span.IsRecording() && ok {
+		var devices []string
+		for _, slice := range pool.Slices {
+			for _, device := range slice.Devices {
+				devices = append(devices, tracing.DeviceID(c.driverName, poolName, device.Name))
+			}
+		}
+		span.SetAttributes(tracing.DevicesKey.StringSlice(devices))
+	}
+	if ok && c.features != nil {
+		c.dropDisabledFields(ctx, poolName, pool)
+	}
+	if ok {
+		if err := validateBinding(poolName, pool); err != nil {
+			return err
+		}
+		if c.attributeSchema != nil {
+			var err error
+			pool, err = c.attributeSchema.apply(c.driverName, poolName, pool)
+			if err != nil {
+				return err
+			}
+		}
+	}
 	if !ok {
 		if len(slices) > 0 {
 			// All are obsolete, pool does not exist anymore.
@@ -624,6 +936,10 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 		}
 	}
 
+	if err := validateNodeSelection(poolName, pool, nodeName); err != nil {
+		return err
+	}
+
 	// Slices that don't match any driver slice need to be deleted.
 	obsoleteSlices := make([]*resourceapi.ResourceSlice, 0, len(slices))
 
@@ -707,16 +1023,16 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 		Generation:         generation, // May get updated later.
 		ResourceSliceCount: int64(resourceSliceCount),
 	}
-	desiredAllNodes := pool.NodeSelector == nil && nodeName == ""
 
 	// Now for each desired slice, figure out which of them are changed.
 	changedDesiredSlices := sets.New[int]()
 	for i, currentSlice := range currentSliceForDesiredSlice {
 		// Reordering entries is a difference and causes an update even if the
 		// entries are the same.
+		nodeSelector, allNodes := desiredNodeSelection(pool, i, nodeName)
 		if !apiequality.Semantic.DeepEqual(&currentSlice.Spec.Pool, &desiredPool) ||
-			!apiequality.Semantic.DeepEqual(currentSlice.Spec.NodeSelector, pool.NodeSelector) ||
-			ptr.Deref(currentSlice.Spec.AllNodes, false) != desiredAllNodes ||
+			!apiequality.Semantic.DeepEqual(currentSlice.Spec.NodeSelector, nodeSelector) ||
+			ptr.Deref(currentSlice.Spec.AllNodes, false) != allNodes ||
 			!DevicesDeepEqual(currentSlice.Spec.Devices, pool.Slices[i].Devices) ||
 			!apiequality.Semantic.DeepEqual(currentSlice.Spec.SharedCounters, pool.Slices[i].SharedCounters) ||
 			!apiequality.Semantic.DeepEqual(currentSlice.Spec.PerDeviceNodeSelection, pool.Slices[i].PerDeviceNodeSelection) {
@@ -765,8 +1081,9 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 		// have listed the existing slice.
 		//
 		// When adding new fields here, then also extend sliceStored.
-		slice.Spec.NodeSelector = pool.NodeSelector
-		slice.Spec.AllNodes = refIfNotZero(desiredAllNodes)
+		nodeSelector, allNodes := desiredNodeSelection(pool, i, nodeName)
+		slice.Spec.NodeSelector = nodeSelector
+		slice.Spec.AllNodes = refIfNotZero(allNodes)
 		slice.Spec.SharedCounters = pool.Slices[i].SharedCounters
 		slice.Spec.PerDeviceNodeSelection = pool.Slices[i].PerDeviceNodeSelection
 		// Preserve TimeAdded from existing device, if there is a matching device and taint.
@@ -778,6 +1095,7 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 		}
 		logger.V(5).Info("Updated existing resource slice", "slice", klog.KObj(slice))
 		atomic.AddInt64(&c.numUpdates, 1)
+		sliceOperations.WithLabelValues(c.driverName, "update").Inc()
 		c.sliceStored(ctx, "update ResourceSlice", poolName, pool, i, slice, actualSlice)
 	}
 
@@ -800,6 +1118,7 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 				},
 			)
 		}
+		nodeSelector, allNodes := desiredNodeSelection(pool, i, nodeName)
 		generateName := c.driverName + "-"
 		if c.owner != nil {
 			generateName = c.owner.Name + "-" + generateName
@@ -813,8 +1132,8 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 				Driver:                 c.driverName,
 				Pool:                   desiredPool,
 				NodeName:               refIfNotZero(nodeName),
-				NodeSelector:           pool.NodeSelector,
-				AllNodes:               refIfNotZero(desiredAllNodes),
+				NodeSelector:           nodeSelector,
+				AllNodes:               refIfNotZero(allNodes),
 				Devices:                pool.Slices[i].Devices,
 				SharedCounters:         pool.Slices[i].SharedCounters,
 				PerDeviceNodeSelection: pool.Slices[i].PerDeviceNodeSelection,
@@ -836,11 +1155,12 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 		}
 		logger.V(5).Info("Created new resource slice", "slice", klog.KObj(actualSlice))
 		atomic.AddInt64(&c.numCreates, 1)
+		sliceOperations.WithLabelValues(c.driverName, "create").Inc()
 		added = true
 		c.sliceStored(ctx, "create ResourceSlice", poolName, pool, i, slice, actualSlice)
 	}
 
-	now := time.Now()
+	now := c.clock.Now()
 	if added {
 		c.lastAddByPool[poolName] = now
 		logger.V(5).Info("Added slices")
@@ -856,7 +1176,7 @@ func (c *Controller) syncPool(ctx context.Context, poolName string) error {
 		// Scheduling the resync races with scheduling them in informer events, but that's okay:
 		// what matters is that we sync at all at some point.
 		//
-		// lastAdd was taked by time.Now() above and thus is slightly higher or equal
+		// lastAdd was taken by c.clock.Now() above and thus is slightly higher or equal
 		// to the time taken by the mutation cache when the slice was added, so we
 		// can be sure that any sync running at this time will not see the added
 		// slice because it will be expired.
@@ -891,6 +1211,10 @@ func (c *Controller) removeSlices(ctx context.Context, slices []*resourceapi.Res
 		case err == nil:
 			logger.V(5).Info("Deleted obsolete resource slice", "slice", klog.KObj(slice), "deleteOptions", options)
 			atomic.AddInt64(&c.numDeletes, 1)
+			if c.writes != nil {
+				c.writes.sliceDeleted(slice)
+			}
+			sliceOperations.WithLabelValues(c.driverName, "delete").Inc()
 		case apierrors.IsNotFound(err):
 			logger.V(5).Info("Resource slice was already deleted earlier", "slice", klog.KObj(slice))
 		default:
@@ -906,6 +1230,9 @@ func (c *Controller) removeSlices(ctx context.Context, slices []*resourceapi.Res
 // through the apiserver.
 func (c *Controller) sliceStored(ctx context.Context, msg string, poolName string, pool Pool, sliceIndex int, desiredSlice, actualSlice *resourceapi.ResourceSlice) {
 	c.sliceStore.Mutation(actualSlice)
+	if c.writes != nil {
+		c.writes.sliceStored(actualSlice)
+	}
 
 	// One difference is normal: the apiserver may have added TimeAdded to taints.
 	// This mutates desiredSlice for the DeepEqual below.
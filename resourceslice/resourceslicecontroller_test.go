@@ -30,18 +30,28 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
 	resourceapi "k8s.io/api/resource/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/dynamic-resource-allocation/internal/tracetesting"
 	"k8s.io/dynamic-resource-allocation/internal/workqueue"
+	drametrics "k8s.io/dynamic-resource-allocation/metrics"
+	"k8s.io/dynamic-resource-allocation/tracing"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/ptr"
 )
 
@@ -858,6 +868,84 @@ func TestControllerSyncPool(t *testing.T) {
 					Pool(resourceapi.ResourcePool{Name: poolName, Generation: 1, ResourceSliceCount: 1}).Obj(),
 			},
 		},
+		"create-per-slice-node-selectors": {
+			initialObjects: []runtime.Object{},
+			inputDriverResources: &DriverResources{
+				Pools: map[string]Pool{
+					poolName: {
+						Slices: []Slice{
+							{NodeSelector: nodeSelector, Devices: []resourceapi.Device{newDevice(deviceName1)}},
+							{NodeSelector: otherNodeSelector, Devices: []resourceapi.Device{newDevice(deviceName2)}},
+						},
+					},
+				},
+			},
+			expectedStats: Stats{
+				NumCreates: 2,
+			},
+			expectedResourceSlices: []resourceapi.ResourceSlice{
+				*MakeResourceSlice().Name(generatedName1).GenerateName(generateName).
+					AppOwnerReferences(ownerName).NodeSelector(nodeSelector).
+					Driver(driverName).Devices([]resourceapi.Device{newDevice(deviceName1)}).
+					Pool(resourceapi.ResourcePool{Name: poolName, Generation: 1, ResourceSliceCount: 2}).Obj(),
+				*MakeResourceSlice().Name(generateName + "1").GenerateName(generateName).
+					AppOwnerReferences(ownerName).NodeSelector(otherNodeSelector).
+					Driver(driverName).Devices([]resourceapi.Device{newDevice(deviceName2)}).
+					Pool(resourceapi.ResourcePool{Name: poolName, Generation: 1, ResourceSliceCount: 2}).Obj(),
+			},
+		},
+		"update-per-slice-node-selector": {
+			initialObjects: []runtime.Object{
+				MakeResourceSlice().Name(resourceSlice1).UID(resourceSlice1).
+					AppOwnerReferences(ownerName).AllNodes(true).
+					Driver(driverName).Devices([]resourceapi.Device{newDevice(deviceName)}).
+					Pool(resourceapi.ResourcePool{Name: poolName, Generation: 1, ResourceSliceCount: 1}).Obj(),
+			},
+			inputDriverResources: &DriverResources{
+				Pools: map[string]Pool{
+					poolName: {
+						Slices: []Slice{{NodeSelector: nodeSelector, Devices: []resourceapi.Device{newDevice(deviceName)}}},
+					},
+				},
+			},
+			expectedStats: Stats{
+				NumUpdates: 1,
+			},
+			expectedResourceSlices: []resourceapi.ResourceSlice{
+				*MakeResourceSlice().Name(resourceSlice1).UID(resourceSlice1).ResourceVersion("1").
+					AppOwnerReferences(ownerName).NodeSelector(nodeSelector).
+					Driver(driverName).Devices([]resourceapi.Device{newDevice(deviceName)}).
+					Pool(resourceapi.ResourcePool{Name: poolName, Generation: 1, ResourceSliceCount: 1}).Obj(),
+			},
+		},
+		"create-from-device-templates": {
+			nodeUID: nodeUID,
+			inputDriverResources: &DriverResources{
+				Pools: map[string]Pool{
+					poolName: {
+						Slices: []Slice{{
+							Devices: []resourceapi.Device{newDevice(deviceName)},
+							DeviceTemplates: []DeviceTemplate{{
+								NamePattern: "device-%d",
+								Start:       1,
+								Count:       2,
+								Device:      newDevice("", attrs),
+							}},
+						}},
+					},
+				},
+			},
+			expectedStats: Stats{
+				NumCreates: 1,
+			},
+			expectedResourceSlices: []resourceapi.ResourceSlice{
+				*MakeResourceSlice().Name(generatedName1).GenerateName(generateName).
+					NodeOwnerReferences(ownerName, string(nodeUID)).NodeName(ownerName).
+					Driver(driverName).
+					Devices([]resourceapi.Device{newDevice(deviceName), newDevice(deviceName1, attrs), newDevice(deviceName2, attrs)}).
+					Pool(resourceapi.ResourcePool{Name: poolName, Generation: 1, ResourceSliceCount: 1}).Obj(),
+			},
+		},
 		"create-partitionable-device": {
 			nodeUID: nodeUID,
 			inputDriverResources: &DriverResources{
@@ -1130,6 +1218,174 @@ func TestControllerSyncPool(t *testing.T) {
 	}
 }
 
+// TestControllerUpdate verifies that Update only schedules syncing of
+// pools which were added, removed or modified.
+func TestControllerUpdate(t *testing.T) {
+	pool := func(deviceNames ...string) Pool {
+		var devices []resourceapi.Device
+		for _, name := range deviceNames {
+			devices = append(devices, resourceapi.Device{Name: name})
+		}
+		return Pool{Slices: []Slice{{Devices: devices}}}
+	}
+	initial := &DriverResources{
+		Pools: map[string]Pool{
+			"unchanged": pool("dev-a"),
+			"modified":  pool("dev-b"),
+			"removed":   pool("dev-c"),
+		},
+	}
+
+	testCases := map[string]struct {
+		update      *DriverResources
+		resync      bool
+		expectReady []string
+	}{
+		"same": {
+			update: initial,
+		},
+		"same-with-resync": {
+			update:      initial,
+			resync:      true,
+			expectReady: []string{"modified", "removed", "unchanged"},
+		},
+		"changes": {
+			update: &DriverResources{
+				Pools: map[string]Pool{
+					"unchanged": pool("dev-a"),
+					"modified":  pool("dev-b", "dev-b2"),
+					"added":     pool("dev-d"),
+				},
+			},
+			expectReady: []string{"added", "modified", "removed"},
+		},
+		"nil": {
+			expectReady: []string{"modified", "removed", "unchanged"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var queue workqueue.Mock[string]
+			c := &Controller{queue: &queue, clock: clock.RealClock{}}
+			c.Update(initial)
+			ready := queue.State().Ready
+			sort.Strings(ready)
+			assert.Equal(t, []string{"modified", "removed", "unchanged"}, ready, "initial update")
+			for range ready {
+				item, _ := queue.Get()
+				queue.Done(item)
+			}
+
+			c.Update(tc.update)
+			if tc.resync {
+				c.Resync()
+			}
+			ready = queue.State().Ready
+			sort.Strings(ready)
+			assert.Equal(t, tc.expectReady, ready, "second update")
+		})
+	}
+}
+
+func TestControllerTracing(t *testing.T) {
+	const (
+		driverName = "driver"
+		poolName   = "pool"
+	)
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := createTestClient(features{}, metav1.Now())
+	var queue workqueue.Mock[string]
+	tracerProvider := &tracetesting.TracerProvider{}
+	ctrl, err := newController(ctx, Options{
+		DriverName: driverName,
+		KubeClient: kubeClient,
+		Owner:      &Owner{APIVersion: "v1", Kind: "Node", Name: "node", UID: "node-uid"},
+		Resources: &DriverResources{
+			Pools: map[string]Pool{
+				poolName: {Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev-0"}, {Name: "dev-1"}}}}},
+			},
+		},
+		Queue:          &queue,
+		TracerProvider: tracerProvider,
+	})
+	require.NoError(t, err, "unexpected controller creation error")
+	defer ctrl.Stop()
+	ctrl.run(ctx)
+
+	spans := tracerProvider.SpansByName("PublishPool")
+	require.NotEmpty(t, spans)
+	span := spans[0]
+	assert.True(t, span.Ended(), "ended")
+	assert.Equal(t, otelcodes.Unset, span.Status(), "status")
+	assert.Contains(t, span.Attributes(), tracing.DriverKey.String(driverName))
+	assert.Contains(t, span.Attributes(), tracing.PoolKey.String(poolName))
+	assert.Contains(t, span.Attributes(), tracing.DevicesKey.StringSlice([]string{
+		tracing.DeviceID(driverName, poolName, "dev-0"),
+		tracing.DeviceID(driverName, poolName, "dev-1"),
+	}))
+	assert.Contains(t, span.Attributes(), attribute.Int64("dra.resourceslices.created", 1))
+}
+
+func TestControllerFeatures(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := createTestClient(features{}, metav1.Now())
+	var queue workqueue.Mock[string]
+	var controllerErrors []error
+	ctrl, err := newController(ctx, Options{
+		DriverName: "driver",
+		KubeClient: kubeClient,
+		Owner:      &Owner{APIVersion: "v1", Kind: "Node", Name: "node", UID: "node-uid"},
+		Resources: &DriverResources{
+			Pools: map[string]Pool{
+				"pool": {Slices: []Slice{{Devices: []resourceapi.Device{{
+					Name:                     "dev",
+					Taints:                   []resourceapi.DeviceTaint{{Key: "example.com/taint", Effect: resourceapi.DeviceTaintEffectNoSchedule}},
+					AllowMultipleAllocations: ptr.To(true),
+				}}}}},
+			},
+		},
+		Queue:    &queue,
+		Features: &featuregates.Features{},
+		ErrorHandler: func(ctx context.Context, err error, msg string) {
+			controllerErrors = append(controllerErrors, err)
+		},
+	})
+	require.NoError(t, err, "unexpected controller creation error")
+	defer ctrl.Stop()
+	ctrl.run(ctx)
+	queue.Add("pool")
+	ctrl.run(ctx)
+
+	require.Len(t, controllerErrors, 1, "errors")
+	var droppedFields *DroppedFieldsError
+	require.ErrorAs(t, controllerErrors[0], &droppedFields)
+	assert.True(t, droppedFields.Local, "local")
+	assert.Equal(t, []string{featuregates.DRADeviceTaints, featuregates.DRAConsumableCapacity}, droppedFields.DisabledFeatures())
+	assert.EqualError(t, droppedFields, `pool "pool", slice #0: some fields were dropped because these features are disabled: DRADeviceTaints DRAConsumableCapacity`)
+
+	resourceSlices, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "list resource slices")
+	require.Len(t, resourceSlices.Items, 1)
+	assert.Equal(t, []resourceapi.Device{{Name: "dev"}}, resourceSlices.Items[0].Spec.Devices)
+	assert.Equal(t, Stats{NumCreates: 1}, ctrl.GetStats(), "no updates")
+}
+
+func TestControllerSyncError(t *testing.T) {
+	c := &Controller{}
+	require.NoError(t, c.SyncError(), "initial state")
+
+	c.setSyncError("pool-b", errors.New("fake error b"))
+	c.setSyncError("pool-a", errors.New("fake error a"))
+	require.EqualError(t, c.SyncError(), "pool \"pool-a\": fake error a\npool \"pool-b\": fake error b")
+
+	c.setSyncError("pool-a", nil)
+	require.EqualError(t, c.SyncError(), "pool \"pool-b\": fake error b")
+
+	c.setSyncError("pool-b", nil)
+	require.NoError(t, c.SyncError(), "after successful syncs")
+}
+
 func formatErrors(errs []error) []string {
 	var errMsgs []string
 	for _, err := range errs {
@@ -1405,3 +1661,494 @@ func newDevice(name string, fields ...any) resourceapi.Device {
 	}
 	return device
 }
+
+func TestControllerDeviceHealth(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := createTestClient(features{}, metav1.Now())
+	var queue workqueue.Mock[string]
+	ctrl, err := newController(ctx, Options{
+		DriverName: "driver",
+		KubeClient: kubeClient,
+		Owner:      &Owner{APIVersion: "v1", Kind: "Node", Name: "node", UID: "node-uid"},
+		Resources: &DriverResources{
+			Pools: map[string]Pool{
+				"pool": {Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev-0"}, {Name: "dev-1"}, {Name: "dev-2"}}}}},
+			},
+		},
+		Queue: &queue,
+		HealthPolicy: &HealthPolicy{
+			Degraded:  HealthActionAttribute,
+			Unhealthy: HealthActionDrop,
+		},
+	})
+	require.NoError(t, err, "unexpected controller creation error")
+	defer ctrl.Stop()
+	ctrl.run(ctx)
+
+	ctrl.SetDeviceHealth("pool", "dev-0", DeviceDegraded)
+	ctrl.SetDeviceHealth("pool", "dev-1", DeviceUnhealthy)
+	assert.Equal(t, DeviceUnhealthy, ctrl.DeviceHealth("pool", "dev-1"))
+	assert.Equal(t, DeviceHealthy, ctrl.DeviceHealth("pool", "dev-2"))
+	assert.Equal(t, []string{"pool"}, queue.State().Ready, "pool queued")
+	ctrl.run(ctx)
+
+	resourceSlices, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "list resource slices")
+	require.Len(t, resourceSlices.Items, 1)
+	assert.Equal(t, []resourceapi.Device{
+		{Name: "dev-0", Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"resource.kubernetes.io/health": {StringValue: ptr.To("Degraded")}}},
+		{Name: "dev-2"},
+	}, resourceSlices.Items[0].Spec.Devices)
+
+	// The health survives an update of the desired state.
+	ctrl.Update(&DriverResources{
+		Pools: map[string]Pool{
+			"pool": {Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev-0"}, {Name: "dev-1"}}}}},
+		},
+	})
+	ctrl.SetDeviceHealth("pool", "dev-0", DeviceHealthy)
+	ctrl.run(ctx)
+	resourceSlices, err = kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "list resource slices")
+	require.Len(t, resourceSlices.Items, 1)
+	assert.Equal(t, []resourceapi.Device{{Name: "dev-0"}}, resourceSlices.Items[0].Spec.Devices)
+}
+
+func TestHealthPolicyValidate(t *testing.T) {
+	assert.NoError(t, (&HealthPolicy{Degraded: HealthActionTaint}).validate())
+	assert.EqualError(t, (&HealthPolicy{Unhealthy: "Explode"}).validate(), `unsupported action "Explode" for Unhealthy devices`)
+}
+
+func TestControllerFencing(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-driver", Namespace: "kube-system"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To("other"),
+			LeaseDurationSeconds: ptr.To(int32(3600)),
+			RenewTime:            &metav1.MicroTime{Time: time.Now()},
+		},
+	}
+	kubeClient := createTestClient(features{}, metav1.Now(), lease)
+	var queue workqueue.Mock[string]
+	var controllerErrors []error
+	ctrl, err := newController(ctx, Options{
+		DriverName: "driver",
+		KubeClient: kubeClient,
+		Owner:      &Owner{APIVersion: "v1", Kind: "Node", Name: "node", UID: "node-uid"},
+		Resources: &DriverResources{
+			Pools: map[string]Pool{
+				"pool": {Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev"}}}}},
+			},
+		},
+		Queue: &queue,
+		ErrorHandler: func(ctx context.Context, err error, msg string) {
+			controllerErrors = append(controllerErrors, err)
+		},
+		Fencing: &Fencing{Namespace: "kube-system", Identity: "me"},
+	})
+	require.NoError(t, err, "unexpected controller creation error")
+	defer ctrl.Stop()
+	ctrl.run(ctx)
+
+	var fencingErr *FencingError
+	require.ErrorAs(t, ctrl.SyncError(), &fencingErr)
+	assert.Equal(t, "kube-system/node-driver", fencingErr.LeaseName)
+	assert.Equal(t, "other", fencingErr.Holder)
+	resourceSlices, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "list resource slices")
+	assert.Empty(t, resourceSlices.Items, "no slices while lease is held by other")
+
+	// Expire the lease, then the controller takes over.
+	lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now().Add(-2 * time.Hour)}
+	_, err = kubeClient.CoordinationV1().Leases(lease.Namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	require.NoError(t, err, "update lease")
+	queue.Add("pool")
+	ctrl.run(ctx)
+
+	require.NoError(t, ctrl.SyncError())
+	resourceSlices, err = kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "list resource slices")
+	assert.Len(t, resourceSlices.Items, 1)
+	lease, err = kubeClient.CoordinationV1().Leases(lease.Namespace).Get(ctx, lease.Name, metav1.GetOptions{})
+	require.NoError(t, err, "get lease")
+	assert.Equal(t, "me", ptr.Deref(lease.Spec.HolderIdentity, ""))
+	assert.Equal(t, int32(1), ptr.Deref(lease.Spec.LeaseTransitions, 0))
+}
+
+func TestControllerDiscovery(t *testing.T) {
+	for name, debounce := range map[string]time.Duration{
+		"no-debounce": 0,
+		"debounce":    10 * time.Millisecond,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			kubeClient := createTestClient(features{}, metav1.Now())
+			var queue workqueue.Mock[string]
+			var mutex sync.Mutex
+			var controllerErrors []error
+			numCalls := 0
+			discover := func(ctx context.Context) (*DriverResources, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				numCalls++
+				if numCalls == 1 {
+					return nil, errors.New("fake discovery error")
+				}
+				return &DriverResources{
+					Pools: map[string]Pool{
+						"pool": {Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev"}}}}},
+					},
+				}, nil
+			}
+			ctrl, err := newController(ctx, Options{
+				DriverName: "driver",
+				KubeClient: kubeClient,
+				Owner:      &Owner{APIVersion: "v1", Kind: "Node", Name: "node", UID: "node-uid"},
+				Queue:      &queue,
+				ErrorHandler: func(ctx context.Context, err error, msg string) {
+					mutex.Lock()
+					defer mutex.Unlock()
+					controllerErrors = append(controllerErrors, err)
+				},
+				Discovery: &Discovery{
+					Discoverer: PollingDiscoverer(discover, time.Hour),
+					Debounce:   &debounce,
+					Backoff:    &wait.Backoff{Duration: time.Millisecond},
+				},
+			})
+			require.NoError(t, err, "unexpected controller creation error")
+			defer ctrl.Stop()
+
+			assert.EventuallyWithT(t, func(t *assert.CollectT) {
+				assert.Equal(t, []string{"pool"}, queue.State().Ready)
+			}, 10*time.Second, time.Millisecond, "pool queued")
+			ctrl.run(ctx)
+
+			resourceSlices, err := kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+			require.NoError(t, err, "list resource slices")
+			assert.Len(t, resourceSlices.Items, 1)
+			mutex.Lock()
+			defer mutex.Unlock()
+			assert.Equal(t, []string{"fake discovery error"}, formatErrors(controllerErrors))
+		})
+	}
+}
+
+func TestControllerDiscoveryMaxDebounce(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := createTestClient(features{}, metav1.Now())
+	var queue workqueue.Mock[string]
+	debounce, maxDebounce := time.Hour, 10*time.Millisecond
+	// Each update restarts the debounce period, so only the
+	// maximum delay gets the state published.
+	discoverer := DeviceDiscovererFunc(func(ctx context.Context, update func(resources *DriverResources)) error {
+		for {
+			update(&DriverResources{
+				Pools: map[string]Pool{
+					"pool": {Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev"}}}}},
+				},
+			})
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Millisecond):
+			}
+		}
+	})
+	ctrl, err := newController(ctx, Options{
+		DriverName: "driver",
+		KubeClient: kubeClient,
+		Owner:      &Owner{APIVersion: "v1", Kind: "Node", Name: "node", UID: "node-uid"},
+		Queue:      &queue,
+		Discovery: &Discovery{
+			Discoverer:  discoverer,
+			Debounce:    &debounce,
+			MaxDebounce: &maxDebounce,
+		},
+	})
+	require.NoError(t, err, "unexpected controller creation error")
+	defer ctrl.Stop()
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Equal(t, []string{"pool"}, queue.State().Ready)
+	}, 10*time.Second, time.Millisecond, "pool queued")
+}
+
+func TestNewControllerInvalidDiscovery(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	_, err := newController(ctx, Options{
+		DriverName: "driver",
+		KubeClient: createTestClient(features{}, metav1.Now()),
+		Discovery:  &Discovery{},
+	})
+	require.EqualError(t, err, "device discoverer is nil")
+}
+
+func TestValidateBinding(t *testing.T) {
+	tooMany := []string{"a", "b", "c", "d", "e"}
+	testCases := map[string]struct {
+		device      resourceapi.Device
+		expectError string
+	}{
+		"none": {
+			device: resourceapi.Device{Name: "dev"},
+		},
+		"valid": {
+			device: resourceapi.Device{
+				Name:                     "dev",
+				BindsToNode:              ptr.To(true),
+				BindingConditions:        []string{"example.com/attached"},
+				BindingFailureConditions: []string{"example.com/failed"},
+			},
+		},
+		"missing-failure-conditions": {
+			device:      resourceapi.Device{Name: "dev", BindingConditions: []string{"attached"}},
+			expectError: `pool "pool", slice #0, device "dev": bindingFailureConditions must be set together with bindingConditions`,
+		},
+		"too-many": {
+			device:      resourceapi.Device{Name: "dev", BindingConditions: tooMany, BindingFailureConditions: []string{"failed"}},
+			expectError: `pool "pool", slice #0, device "dev": bindingConditions: must have at most 4 entries, got 5`,
+		},
+		"invalid-and-duplicate": {
+			device:      resourceapi.Device{Name: "dev", BindingConditions: []string{"attached"}, BindingFailureConditions: []string{"x y", "attached", "attached"}},
+			expectError: "pool \"pool\", slice #0, device \"dev\": bindingFailureConditions[0]: \"x y\": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')\nbindingFailureConditions[2]: duplicate condition \"attached\"\nconditions must not be both binding and binding failure conditions: attached",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := validateBinding("pool", Pool{Slices: []Slice{{Devices: []resourceapi.Device{tc.device}}}})
+			if tc.expectError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectError)
+			var invalidDevice *InvalidDeviceError
+			require.ErrorAs(t, err, &invalidDevice)
+		})
+	}
+}
+
+func TestValidateNodeSelection(t *testing.T) {
+	nodeSelector := &v1.NodeSelector{
+		NodeSelectorTerms: []v1.NodeSelectorTerm{{
+			MatchFields: []v1.NodeSelectorRequirement{{Key: "name", Values: []string{"node-a"}}},
+		}},
+	}
+	testCases := map[string]struct {
+		pool        Pool
+		nodeName    string
+		expectError string
+	}{
+		"all-nodes": {
+			pool: Pool{Slices: []Slice{{}}},
+		},
+		"pool-selector": {
+			pool: Pool{NodeSelector: nodeSelector, Slices: []Slice{{}}},
+		},
+		"slice-selectors": {
+			pool: Pool{Slices: []Slice{{NodeSelector: nodeSelector}, {}}},
+		},
+		"pool-selector-with-node": {
+			pool:        Pool{NodeSelector: nodeSelector, Slices: []Slice{{}}},
+			nodeName:    "node-a",
+			expectError: `pool "pool": node selector must not be set for the node-local resources of node "node-a"`,
+		},
+		"empty-pool-selector": {
+			pool:        Pool{NodeSelector: &v1.NodeSelector{}, Slices: []Slice{{}}},
+			expectError: `pool "pool": node selector must have at least one term`,
+		},
+		"slice-selector-with-node": {
+			pool:        Pool{Slices: []Slice{{NodeSelector: nodeSelector}}},
+			nodeName:    "node-a",
+			expectError: `pool "pool", slice #0: node selector must not be set for the node-local resources of node "node-a"`,
+		},
+		"slice-and-pool-selector": {
+			pool:        Pool{NodeSelector: nodeSelector, Slices: []Slice{{}, {NodeSelector: nodeSelector}}},
+			expectError: `pool "pool", slice #1: node selector must not be set together with a node selector for the pool`,
+		},
+		"slice-selector-per-device": {
+			pool:        Pool{Slices: []Slice{{NodeSelector: nodeSelector, PerDeviceNodeSelection: ptr.To(true)}}},
+			expectError: `pool "pool", slice #0: node selector must not be set together with per-device node selection`,
+		},
+		"empty-slice-selector": {
+			pool:        Pool{Slices: []Slice{{NodeSelector: &v1.NodeSelector{}}}},
+			expectError: `pool "pool", slice #0: node selector must have at least one term`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := validateNodeSelection("pool", tc.pool, tc.nodeName)
+			if tc.expectError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectError)
+		})
+	}
+}
+
+func TestControllerPublishLatency(t *testing.T) {
+	require.NoError(t, drametrics.RegisterMetrics(drametrics.LegacyRegistry, drametrics.EnableSubsystems(drametrics.SubsystemResourceSlice)))
+	const driverName = "publish-latency-driver"
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := createTestClient(features{}, metav1.Now())
+	var queue workqueue.Mock[string]
+	ctrl, err := newController(ctx, Options{
+		DriverName: driverName,
+		KubeClient: kubeClient,
+		Owner:      &Owner{APIVersion: "v1", Kind: "Node", Name: "node", UID: "node-uid"},
+		Resources: &DriverResources{
+			Pools: map[string]Pool{
+				"pool": {Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev"}}}}},
+			},
+		},
+		Queue: &queue,
+	})
+	require.NoError(t, err, "unexpected controller creation error")
+	defer ctrl.Stop()
+	ctrl.run(ctx)
+
+	expectCount := func(what string, expected uint64) {
+		t.Helper()
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			count, err := testutil.GetHistogramMetricCount(publishLatencySeconds.WithLabelValues(driverName))
+			require.NoError(t, err)
+			assert.Equal(t, expected, count)
+		}, 10*time.Second, time.Millisecond, what)
+	}
+	expectCount("created slice observed", 1)
+
+	// Removing the pool is observed once the informer has seen the delete.
+	ctrl.Update(nil)
+	ctrl.run(ctx)
+	expectCount("deleted slice observed", 2)
+}
+
+func TestAttributeSchema(t *testing.T) {
+	schema := AttributeSchema{
+		Attributes: map[resourceapi.QualifiedName]AttributeSpec{
+			"model":                  {Type: AttributeTypeString, Required: true},
+			"driver.example.com/mem": {Type: AttributeTypeInt},
+			"other.example.com/ver":  {Type: AttributeTypeVersion},
+		},
+	}
+	testCases := map[string]struct {
+		coerce       bool
+		attributes   map[resourceapi.QualifiedName]resourceapi.DeviceAttribute
+		expectError  string
+		expectCoerce map[resourceapi.QualifiedName]resourceapi.DeviceAttribute
+	}{
+		"valid": {
+			attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+				"driver.example.com/model": {StringValue: ptr.To("a100")},
+				"mem":                      {IntValue: ptr.To[int64](1)},
+				"extra":                    {BoolValue: ptr.To(true)},
+			},
+		},
+		"missing": {
+			attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{},
+			expectError: `pool "pool", slice #0, device "dev": attribute "model": required, but not set`,
+		},
+		"wrong-type": {
+			attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+				"model": {StringValue: ptr.To("a100")},
+				"mem":   {StringValue: ptr.To("1")},
+			},
+			expectError: `pool "pool", slice #0, device "dev": attribute "driver.example.com/mem": must be of type int, got string`,
+		},
+		"coerce": {
+			coerce: true,
+			attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+				"model":                 {IntValue: ptr.To[int64](100)},
+				"mem":                   {StringValue: ptr.To("1")},
+				"other.example.com/ver": {StringValue: ptr.To("1.2.3")},
+			},
+			expectCoerce: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+				"model":                 {StringValue: ptr.To("100")},
+				"mem":                   {IntValue: ptr.To[int64](1)},
+				"other.example.com/ver": {VersionValue: ptr.To("1.2.3")},
+			},
+		},
+		"coerce-failed": {
+			coerce: true,
+			attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+				"model": {StringValue: ptr.To("a100")},
+				"mem":   {StringValue: ptr.To("lots")},
+			},
+			expectError: `pool "pool", slice #0, device "dev": attribute "driver.example.com/mem": cannot convert string to int: strconv.ParseInt: parsing "lots": invalid syntax`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			schema := schema
+			schema.Coerce = tc.coerce
+			pool := Pool{Slices: []Slice{{Devices: []resourceapi.Device{{Name: "dev", Attributes: tc.attributes}}}}}
+			original := pool.DeepCopy()
+			actual, err := schema.apply("driver.example.com", "pool", pool)
+			assert.Equal(t, original, &pool, "original pool must not be modified")
+			if tc.expectError != "" {
+				require.EqualError(t, err, tc.expectError)
+				var invalidDevice *InvalidDeviceError
+				require.ErrorAs(t, err, &invalidDevice)
+				return
+			}
+			require.NoError(t, err)
+			expect := tc.attributes
+			if tc.expectCoerce != nil {
+				expect = tc.expectCoerce
+			}
+			assert.Equal(t, expect, actual.Slices[0].Devices[0].Attributes)
+		})
+	}
+
+	invalid := AttributeSchema{Attributes: map[resourceapi.QualifiedName]AttributeSpec{"x": {Type: "float"}}}
+	require.EqualError(t, invalid.validate(), `attribute "x": unsupported type "float"`)
+}
+
+func TestExpandDeviceTemplates(t *testing.T) {
+	testCases := map[string]struct {
+		template      DeviceTemplate
+		expectDevices []string
+		expectError   string
+	}{
+		"none": {
+			template: DeviceTemplate{NamePattern: "gpu-%d"},
+		},
+		"names": {
+			template:      DeviceTemplate{NamePattern: "gpu-%02d", Start: 9, Count: 2},
+			expectDevices: []string{"gpu-09", "gpu-10"},
+		},
+		"no-verb": {
+			template:    DeviceTemplate{NamePattern: "gpu", Count: 1},
+			expectError: `pool "pool", slice #0: device template #0: name pattern "gpu" must contain exactly one %d verb`,
+		},
+		"two-verbs": {
+			template:    DeviceTemplate{NamePattern: "gpu-%d-%d", Count: 1},
+			expectError: `pool "pool", slice #0: device template #0: name pattern "gpu-%d-%d" must contain exactly one %d verb`,
+		},
+		"invalid": {
+			template:    DeviceTemplate{NamePattern: "gpu-%d", Count: -1, Device: resourceapi.Device{Name: "gpu"}},
+			expectError: "pool \"pool\", slice #0: device template #0: count must not be negative, got -1\ndevice name must be empty, got \"gpu\"",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pool := Pool{Slices: []Slice{{DeviceTemplates: []DeviceTemplate{tc.template}}}}
+			original := pool.DeepCopy()
+			actual, err := expandDeviceTemplates("pool", pool)
+			assert.Equal(t, original, &pool, "original pool must not be modified")
+			if tc.expectError != "" {
+				require.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, device := range actual.Slices[0].Devices {
+				names = append(names, device.Name)
+			}
+			assert.Equal(t, tc.expectDevices, names)
+			assert.Empty(t, actual.Slices[0].DeviceTemplates)
+		})
+	}
+}
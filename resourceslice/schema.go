@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/ptr"
+)
+
+// AttributeType is the type of the value of a device attribute.
+type AttributeType string
+
+// The supported types correspond to the fields of [resourceapi.DeviceAttribute].
+const (
+	AttributeTypeBool    AttributeType = "bool"
+	AttributeTypeInt     AttributeType = "int"
+	AttributeTypeString  AttributeType = "string"
+	AttributeTypeVersion AttributeType = "version"
+)
+
+// AttributeSchema describes the attributes which devices of a driver
+// are expected to have. CEL selectors which compare an attribute
+// with a value of a different type fail at scheduling time, so
+// checking this before publishing catches such mistakes early.
+type AttributeSchema struct {
+	// Attributes maps attribute names to their specification.
+	// Names without a domain are in the domain of the driver,
+	// the same as in [resourceapi.Device.Attributes].
+	Attributes map[resourceapi.QualifiedName]AttributeSpec
+
+	// Coerce enables converting values which have the wrong type
+	// into the type from the schema, if possible. For example,
+	// a string "1" becomes an int. Without it, or if the conversion
+	// is not possible, the pool of the device does not get published
+	// and the ErrorHandler gets called with an [InvalidDeviceError].
+	Coerce bool
+}
+
+// AttributeSpec is the specification of one attribute in an [AttributeSchema].
+type AttributeSpec struct {
+	// Type is the type of the attribute value.
+	Type AttributeType
+	// Required is true if all devices must have the attribute.
+	Required bool
+}
+
+func (s *AttributeSchema) validate() error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(s.Attributes)) {
+		switch s.Attributes[name].Type {
+		case AttributeTypeBool, AttributeTypeInt, AttributeTypeString, AttributeTypeVersion:
+		default:
+			errs = append(errs, fmt.Errorf("attribute %q: unsupported type %q", name, s.Attributes[name].Type))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply checks all devices in the pool against the schema.
+// If devices need to be coerced, a modified copy of the pool is returned.
+// The original pool is never modified.
+func (s *AttributeSchema) apply(driverName, poolName string, pool Pool) (Pool, error) {
+	copied := false
+	for i, slice := range pool.Slices {
+		for e, device := range slice.Devices {
+			attributes, err := s.applyToDevice(driverName, device.Attributes)
+			if err != nil {
+				return pool, &InvalidDeviceError{PoolName: poolName, SliceIndex: i, DeviceName: device.Name, Err: err}
+			}
+			if attributes == nil {
+				continue
+			}
+			if !copied {
+				pool = *pool.DeepCopy()
+				copied = true
+			}
+			pool.Slices[i].Devices[e].Attributes = attributes
+		}
+	}
+	return pool, nil
+}
+
+// applyToDevice returns nil if the attributes conform to the schema,
+// coerced attributes if they were converted, or an error.
+func (s *AttributeSchema) applyToDevice(driverName string, attributes map[resourceapi.QualifiedName]resourceapi.DeviceAttribute) (map[resourceapi.QualifiedName]resourceapi.DeviceAttribute, error) {
+	values := make(map[resourceapi.QualifiedName]resourceapi.QualifiedName, len(attributes))
+	for name := range attributes {
+		values[qualifyAttributeName(driverName, name)] = name
+	}
+
+	var errs []error
+	var coerced map[resourceapi.QualifiedName]resourceapi.DeviceAttribute
+	for _, name := range slices.Sorted(maps.Keys(s.Attributes)) {
+		spec := s.Attributes[name]
+		deviceName, ok := values[qualifyAttributeName(driverName, name)]
+		if !ok {
+			if spec.Required {
+				errs = append(errs, fmt.Errorf("attribute %q: required, but not set", name))
+			}
+			continue
+		}
+		value := attributes[deviceName]
+		actual := attributeType(value)
+		if actual == spec.Type {
+			continue
+		}
+		if !s.Coerce {
+			errs = append(errs, fmt.Errorf("attribute %q: must be of type %s, got %s", name, spec.Type, actual))
+			continue
+		}
+		converted, err := coerceAttribute(value, spec.Type)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("attribute %q: cannot convert %s to %s: %w", name, actual, spec.Type, err))
+			continue
+		}
+		if coerced == nil {
+			coerced = maps.Clone(attributes)
+		}
+		coerced[deviceName] = converted
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return coerced, nil
+}
+
+// qualifyAttributeName adds the driver name as domain if the name has none.
+func qualifyAttributeName(driverName string, name resourceapi.QualifiedName) resourceapi.QualifiedName {
+	if strings.Contains(string(name), "/") {
+		return name
+	}
+	return resourceapi.QualifiedName(driverName + "/" + string(name))
+}
+
+func attributeType(value resourceapi.DeviceAttribute) AttributeType {
+	switch {
+	case value.BoolValue != nil:
+		return AttributeTypeBool
+	case value.IntValue != nil:
+		return AttributeTypeInt
+	case value.StringValue != nil:
+		return AttributeTypeString
+	case value.VersionValue != nil:
+		return AttributeTypeVersion
+	default:
+		return ""
+	}
+}
+
+// coerceAttribute converts the value via its string representation.
+func coerceAttribute(value resourceapi.DeviceAttribute, to AttributeType) (resourceapi.DeviceAttribute, error) {
+	var str string
+	switch {
+	case value.BoolValue != nil:
+		str = strconv.FormatBool(*value.BoolValue)
+	case value.IntValue != nil:
+		str = strconv.FormatInt(*value.IntValue, 10)
+	case value.StringValue != nil:
+		str = *value.StringValue
+	case value.VersionValue != nil:
+		str = *value.VersionValue
+	default:
+		return resourceapi.DeviceAttribute{}, errors.New("no value")
+	}
+
+	switch to {
+	case AttributeTypeBool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return resourceapi.DeviceAttribute{}, err
+		}
+		return resourceapi.DeviceAttribute{BoolValue: &b}, nil
+	case AttributeTypeInt:
+		i, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return resourceapi.DeviceAttribute{}, err
+		}
+		return resourceapi.DeviceAttribute{IntValue: &i}, nil
+	case AttributeTypeString:
+		return resourceapi.DeviceAttribute{StringValue: &str}, nil
+	case AttributeTypeVersion:
+		v, err := semver.Parse(str)
+		if err != nil {
+			return resourceapi.DeviceAttribute{}, err
+		}
+		return resourceapi.DeviceAttribute{VersionValue: ptr.To(v.String())}, nil
+	default:
+		return resourceapi.DeviceAttribute{}, fmt.Errorf("unsupported type %q", to)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceslice
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+// +k8s:deepcopy-gen=true
+
+// DeviceTemplate describes devices which are identical except for
+// an index in their name. The controller only generates the devices
+// when publishing them, so a driver with many such devices does not
+// need to keep all of them in memory.
+type DeviceTemplate struct {
+	// NamePattern is a format string with exactly one %d verb
+	// for the index, for example "gpu-%d".
+	NamePattern string
+
+	// Start is the index of the first device.
+	Start int
+
+	// Count is the number of devices.
+	Count int
+
+	// Device provides all fields of the generated devices
+	// except for the name, which must be empty.
+	Device resourceapi.Device
+}
+
+func (t *DeviceTemplate) validate() error {
+	var errs []error
+	if t.Count < 0 {
+		errs = append(errs, fmt.Errorf("count must not be negative, got %d", t.Count))
+	}
+	if t.Start < 0 {
+		errs = append(errs, fmt.Errorf("start must not be negative, got %d", t.Start))
+	}
+	if t.Device.Name != "" {
+		errs = append(errs, fmt.Errorf("device name must be empty, got %q", t.Device.Name))
+	}
+	// The pattern must produce valid, distinct names. Trying it
+	// out catches missing, additional or unsupported verbs.
+	first, second := fmt.Sprintf(t.NamePattern, 0), fmt.Sprintf(t.NamePattern, 1)
+	if strings.Contains(first, "%!") || first == second {
+		errs = append(errs, fmt.Errorf("name pattern %q must contain exactly one %%d verb", t.NamePattern))
+	}
+	return errors.Join(errs...)
+}
+
+func (t *DeviceTemplate) deviceName(index int) string {
+	return fmt.Sprintf(t.NamePattern, t.Start+index)
+}
+
+// expandDeviceTemplates appends the devices generated from the
+// templates of the slice to its devices and removes the templates.
+// Devices is always replaced, so the original slice may be shared.
+func (s *Slice) expandDeviceTemplates() error {
+	if len(s.DeviceTemplates) == 0 {
+		return nil
+	}
+	numDevices := len(s.Devices)
+	for i := range s.DeviceTemplates {
+		template := &s.DeviceTemplates[i]
+		if err := template.validate(); err != nil {
+			return fmt.Errorf("device template #%d: %w", i, err)
+		}
+		numDevices += template.Count
+	}
+	devices := slices.Grow(slices.Clone(s.Devices), numDevices-len(s.Devices))
+	for i := range s.DeviceTemplates {
+		template := &s.DeviceTemplates[i]
+		for index := range template.Count {
+			device := template.Device.DeepCopy()
+			device.Name = template.deviceName(index)
+			devices = append(devices, *device)
+		}
+	}
+	s.Devices = devices
+	s.DeviceTemplates = nil
+	return nil
+}
+
+// expandDeviceTemplates returns the pool with all device templates
+// replaced by the generated devices. The original pool is not modified.
+func expandDeviceTemplates(poolName string, pool Pool) (Pool, error) {
+	if !slices.ContainsFunc(pool.Slices, func(slice Slice) bool { return len(slice.DeviceTemplates) > 0 }) {
+		return pool, nil
+	}
+	pool.Slices = slices.Clone(pool.Slices)
+	for i := range pool.Slices {
+		if err := pool.Slices[i].expandDeviceTemplates(); err != nil {
+			return pool, fmt.Errorf("pool %q, slice #%d: %w", poolName, i, err)
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"slices"
+	"sync"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// changeLog remembers for each ResourceSlice the revision in which its
+// patched form changed last. The revision increases by one for each
+// change.
+type changeLog struct {
+	mutex    sync.Mutex
+	revision uint64
+	changed  map[string]uint64
+}
+
+func (c *changeLog) record(objs ...any) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, obj := range objs {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		slice, ok := obj.(*resourceapi.ResourceSlice)
+		if !ok || slice == nil {
+			continue
+		}
+		if c.changed == nil {
+			c.changed = make(map[string]uint64)
+		}
+		c.revision++
+		c.changed[slice.Name] = c.revision
+		return
+	}
+}
+
+// handler records changes reported by an informer.
+func (c *changeLog) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { c.record(obj) },
+		UpdateFunc: func(_, newObj any) { c.record(newObj) },
+		DeleteFunc: func(obj any) { c.record(obj) },
+	}
+}
+
+// Changes returns the names of all ResourceSlices whose patched form
+// was added, updated or removed after the given revision, sorted by name,
+// and the current revision. Passing that revision into the next call returns
+// the changes since this call, so a consumer can periodically reconcile
+// just the modified slices by looking them up in the result of
+// [Tracker.ListPatchedResourceSlices] without registering an event handler.
+//
+// The initial revision is zero. Revisions are local to a Tracker instance.
+func (t *Tracker) Changes(sinceRevision uint64) ([]string, uint64) {
+	t.changes.mutex.Lock()
+	defer t.changes.mutex.Unlock()
+
+	var names []string
+	for name, revision := range t.changes.changed {
+		if revision > sinceRevision {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, t.changes.revision
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// deviceLimiter counts how many devices each DeviceTaintRule matches
+// across all ResourceSlices and determines which rules match more devices
+// than allowed by [Options.MaxDevicesPerRule].
+type deviceLimiter struct {
+	max intstr.IntOrString
+
+	mutex sync.Mutex
+	// sliceDevices contains the number of devices per slice.
+	sliceDevices map[string]int
+	totalDevices int
+	// matches contains the number of matched devices per rule and slice.
+	matches map[string]map[string]int
+	// exceeded contains all rules for which the tracker was last told
+	// that they exceed the limit.
+	exceeded sets.Set[string]
+}
+
+func newDeviceLimiter(max intstr.IntOrString) (*deviceLimiter, error) {
+	if _, err := intstr.GetScaledValueFromIntOrPercent(&max, 100, true); err != nil {
+		return nil, fmt.Errorf("invalid maximum number of devices per rule: %w", err)
+	}
+	return &deviceLimiter{
+		max:          max,
+		sliceDevices: make(map[string]int),
+		matches:      make(map[string]map[string]int),
+		exceeded:     sets.New[string](),
+	}, nil
+}
+
+// resetSlice starts counting the devices of a slice again.
+// numDevices is zero when the slice was removed.
+func (l *deviceLimiter) resetSlice(sliceName string, numDevices int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.totalDevices += numDevices - l.sliceDevices[sliceName]
+	if numDevices > 0 {
+		l.sliceDevices[sliceName] = numDevices
+	} else {
+		delete(l.sliceDevices, sliceName)
+	}
+	for ruleName, slices := range l.matches {
+		delete(slices, sliceName)
+		if len(slices) == 0 {
+			delete(l.matches, ruleName)
+		}
+	}
+}
+
+// setMatches stores the number of devices that a rule matches in a slice
+// and returns the number of devices matched by the rule overall and the
+// limit for it.
+func (l *deviceLimiter) setMatches(ruleName, sliceName string, numDevices int) (int, int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if numDevices > 0 {
+		if l.matches[ruleName] == nil {
+			l.matches[ruleName] = make(map[string]int)
+		}
+		l.matches[ruleName][sliceName] = numDevices
+	}
+	return l.numMatchesLocked(ruleName), l.limitLocked()
+}
+
+// deleteRule forgets about a rule.
+func (l *deviceLimiter) deleteRule(ruleName string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.matches, ruleName)
+	l.exceeded.Delete(ruleName)
+}
+
+// changes returns the rules which started or stopped exceeding the limit
+// since the previous call.
+func (l *deviceLimiter) changes() (sets.Set[string], sets.Set[string]) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limit := l.limitLocked()
+	exceeded := sets.New[string]()
+	for ruleName := range l.matches {
+		if l.numMatchesLocked(ruleName) > limit {
+			exceeded.Insert(ruleName)
+		}
+	}
+	started, stopped := exceeded.Difference(l.exceeded), l.exceeded.Difference(exceeded)
+	l.exceeded = exceeded
+	return started, stopped
+}
+
+// numMatches returns the number of devices matched by the rule overall.
+func (l *deviceLimiter) numMatches(ruleName string) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.numMatchesLocked(ruleName)
+}
+
+func (l *deviceLimiter) numMatchesLocked(ruleName string) int {
+	numMatches := 0
+	for _, numDevices := range l.matches[ruleName] {
+		numMatches += numDevices
+	}
+	return numMatches
+}
+
+func (l *deviceLimiter) limitLocked() int {
+	// Rounding up ensures that a percentage allows at least one device.
+	// The error was checked when creating the limiter.
+	limit, _ := intstr.GetScaledValueFromIntOrPercent(&l.max, l.totalDevices, true)
+	return limit
+}
+
+// checkDeviceLimits resyncs the slices of all rules which started or stopped
+// exceeding the limit and emits events about them.
+func (t *Tracker) checkDeviceLimits(ctx context.Context) {
+	if t.deviceLimiter == nil {
+		return
+	}
+	started, stopped := t.deviceLimiter.changes()
+	logger := klog.FromContext(ctx)
+	for ruleName := range started.Union(stopped) {
+		obj, exists, err := t.deviceTaints.GetIndexer().GetByKey(ruleName)
+		if err != nil {
+			t.handleError(ctx, err, "failed to lookup DeviceTaintRule", "deviceTaintRule", ruleName)
+			continue
+		}
+		if !exists {
+			continue
+		}
+		rule, ok := obj.(*resourcealphaapi.DeviceTaintRule)
+		if !ok {
+			continue
+		}
+		if started.Has(ruleName) {
+			logger.Info("DeviceTaintRule matches too many devices, not applying it", "deviceTaintRule", klog.KObj(rule), "maxDevicesPerRule", t.deviceLimiter.max.String())
+			t.recordRuleEvent(rule, ruleEvent{reason: ReasonTooManyDevices, affectedDevices: ptr.To(t.deviceLimiter.numMatches(ruleName))}, "rule matches more than %s devices, not applying it", t.deviceLimiter.max.String())
+		} else {
+			logger.Info("DeviceTaintRule no longer matches too many devices, applying it", "deviceTaintRule", klog.KObj(rule))
+		}
+		for _, sliceName := range t.sliceNamesForPatch(ctx, rule) {
+			t.syncSlice(ctx, sliceName, false)
+		}
+	}
+}
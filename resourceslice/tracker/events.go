@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+)
+
+// EventReason is the machine-readable reason of an Event which the tracker
+// emits for a DeviceTaintRule. The values are stable and can be used
+// by alerting pipelines instead of parsing the message.
+type EventReason string
+
+const (
+	// ReasonInvalidSliceSelector is used when the ResourceSlice selector
+	// of a rule cannot be parsed. The rule does not get applied.
+	ReasonInvalidSliceSelector EventReason = "InvalidSliceSelector"
+	// ReasonInvalidTaintExpiry is used when the expiry of a rule cannot
+	// be parsed. The rule does not get applied.
+	ReasonInvalidTaintExpiry EventReason = "InvalidTaintExpiry"
+	// ReasonCELRuntimeError is used when evaluating a CEL selector of a
+	// rule fails for a device. The rule does not get applied to that device.
+	// The [EventAnnotationExpressionIndex] annotation identifies the selector.
+	ReasonCELRuntimeError EventReason = "CELRuntimeError"
+	// ReasonTooManyDevices is used when a rule matches more devices than
+	// allowed by [Options.MaxDevicesPerRule]. The rule does not get applied.
+	// The [EventAnnotationAffectedDevices] annotation contains the number
+	// of matched devices.
+	ReasonTooManyDevices EventReason = "TooManyDevices"
+)
+
+// Annotations which are set for Events emitted by the tracker.
+// Annotations which do not apply to a certain reason are not set.
+const (
+	// EventAnnotationRule contains the name of the DeviceTaintRule.
+	EventAnnotationRule = "tracker.resource.k8s.io/rule"
+	// EventAnnotationExpressionIndex contains the index of the CEL
+	// selector in the DeviceTaintRule, in decimal.
+	EventAnnotationExpressionIndex = "tracker.resource.k8s.io/expression-index"
+	// EventAnnotationAffectedDevices contains the number of devices
+	// affected by the problem, in decimal.
+	EventAnnotationAffectedDevices = "tracker.resource.k8s.io/affected-devices"
+)
+
+// ruleEvent describes an Event about a DeviceTaintRule.
+type ruleEvent struct {
+	reason          EventReason
+	expressionIndex *int
+	affectedDevices *int
+}
+
+// recordRuleEvent emits a Warning event for the rule, if events are enabled.
+func (t *Tracker) recordRuleEvent(rule *resourcealphaapi.DeviceTaintRule, event ruleEvent, messageFmt string, args ...any) {
+	if t.recorder == nil {
+		return
+	}
+	annotations := map[string]string{
+		EventAnnotationRule: rule.Name,
+	}
+	if event.expressionIndex != nil {
+		annotations[EventAnnotationExpressionIndex] = strconv.Itoa(*event.expressionIndex)
+	}
+	if event.affectedDevices != nil {
+		annotations[EventAnnotationAffectedDevices] = strconv.Itoa(*event.affectedDevices)
+	}
+	t.recorder.AnnotatedEventf(rule, annotations, v1.EventTypeWarning, string(event.reason), messageFmt, args...)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	"k8s.io/klog/v2"
+)
+
+// TaintExpiresAnnotation sets a point in time after which a DeviceTaintRule
+// no longer applies. The value is a timestamp in RFC 3339 format, for
+// example:
+//
+//	2025-06-01T08:00:00Z
+//
+// This is useful for temporary maintenance windows: once the rule expires,
+// the tracker removes its taint from the patched ResourceSlices and emits
+// updates for them without requiring that the rule gets deleted. A rule
+// with an invalid expiry does not apply to any slice.
+const TaintExpiresAnnotation = "resource.kubernetes.io/taint-expires"
+
+// TaintExpiry returns the time from the [TaintExpiresAnnotation] of the rule.
+// The time is zero if the rule does not expire.
+func TaintExpiry(rule *resourcealphaapi.DeviceTaintRule) (time.Time, error) {
+	value, ok := rule.Annotations[TaintExpiresAnnotation]
+	if !ok {
+		return time.Time{}, nil
+	}
+	expires, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse %s annotation: %w", TaintExpiresAnnotation, err)
+	}
+	return expires, nil
+}
+
+// scheduleExpiry ensures that the slices matched by the rule get synced
+// again once the rule expires. Any previously scheduled resync of the
+// rule is replaced. A nil rule only cancels it.
+func (t *Tracker) scheduleExpiry(ctx context.Context, name string, rule *resourcealphaapi.DeviceTaintRule) {
+	t.expiryMutex.Lock()
+	defer t.expiryMutex.Unlock()
+
+	if timer := t.expiryTimers[name]; timer != nil {
+		timer.Stop()
+		delete(t.expiryTimers, name)
+	}
+	if rule == nil || t.expiryTimers == nil {
+		// Deleted or tracker stopped.
+		return
+	}
+	expires, err := TaintExpiry(rule)
+	if err != nil || expires.IsZero() {
+		return
+	}
+	delay := time.Until(expires)
+	if delay <= 0 {
+		// Already expired when it was synced.
+		return
+	}
+	logger := klog.FromContext(ctx)
+	logger.V(5).Info("DeviceTaintRule expiry scheduled", "patch", klog.KObj(rule), "expires", expires)
+	t.expiryTimers[name] = time.AfterFunc(delay, func() {
+		t.expireRule(ctx, name)
+	})
+}
+
+// expireRule syncs the slices matched by the current revision of the rule.
+func (t *Tracker) expireRule(ctx context.Context, name string) {
+	logger := klog.FromContext(ctx)
+	obj, exists, err := t.deviceTaints.GetIndexer().GetByKey(name)
+	if err != nil {
+		t.handleError(ctx, err, "failed to lookup DeviceTaintRule", "deviceTaintRule", name)
+		return
+	}
+	if !exists {
+		return
+	}
+	rule, ok := obj.(*resourcealphaapi.DeviceTaintRule)
+	if !ok {
+		return
+	}
+	logger.V(5).Info("DeviceTaintRule expired", "patch", klog.KObj(rule))
+	for _, sliceName := range t.sliceNamesForPatch(ctx, rule) {
+		t.syncSlice(ctx, sliceName, false)
+	}
+}
+
+// stopExpiry cancels all pending resyncs.
+func (t *Tracker) stopExpiry() {
+	t.expiryMutex.Lock()
+	defer t.expiryMutex.Unlock()
+
+	for _, timer := range t.expiryTimers {
+		timer.Stop()
+	}
+	t.expiryTimers = nil
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	stdcmp "cmp"
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/builders"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/dynamic-resource-allocation/internal/fuzzinput"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/ktesting"
+)
+
+// FuzzListPatchedResourceSlices feeds random but valid slices and taint
+// rules through the tracker. It checks that:
+//   - nothing panics,
+//   - the result does not depend on the order of the events,
+//   - patching is idempotent: repeating the same events changes nothing,
+//   - patching only adds taints from the rules to existing devices,
+//   - limiting the devices per rule also does not depend on the order.
+//
+// This is a native Go fuzz test, which OSS-Fuzz can build with
+// compile_native_go_fuzzer.
+func FuzzListPatchedResourceSlices(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{2, 0, 0, 3, 0, 1, 0, 1, 1, 2, 1, 0, 1})
+	f.Add([]byte{3, 1, 1, 2, 1, 0, 0, 0, 2, 3, 3, 1, 1, 1, 0, 1, 1, 1, 1, 5, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		in := fuzzinput.New(data)
+		resourceSlices, rules := fuzzTrackerObjects(in)
+		var events []any
+		for _, slice := range resourceSlices {
+			events = append(events, add(slice))
+		}
+		for _, rule := range rules {
+			events = append(events, add(rule))
+		}
+		events = append(events, add(deviceClass1))
+		// Fisher-Yates shuffle driven by the fuzz input.
+		shuffled := slices.Clone(events)
+		for i := len(shuffled) - 1; i > 0; i-- {
+			j := in.Intn(i + 1)
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		}
+
+		expected := fuzzPatchedSlices(t, events)
+		actual := fuzzPatchedSlices(t, shuffled)
+		assert.Equal(t, expected, actual, "result depends on order of events")
+
+		var repeated []any
+		for _, event := range shuffled {
+			switch pair := event.(type) {
+			case [2]*resourceapi.ResourceSlice:
+				repeated = append(repeated, update(pair[1], pair[1]))
+			case [2]*resourcealphaapi.DeviceTaintRule:
+				repeated = append(repeated, update(pair[1], pair[1]))
+			case [2]*resourceapi.DeviceClass:
+				repeated = append(repeated, update(pair[1], pair[1]))
+			}
+		}
+		actual = fuzzPatchedSlices(t, append(shuffled, repeated...))
+		assert.Equal(t, expected, actual, "patching is not idempotent")
+
+		checkPatchedSlices(t, resourceSlices, rules, expected)
+
+		maxDevices := intstr.FromInt32(int32(in.Intn(4)))
+		limit := func(opts *Options) {
+			opts.MaxDevicesPerRule = &maxDevices
+		}
+		expected = fuzzPatchedSlices(t, events, limit)
+		actual = fuzzPatchedSlices(t, shuffled, limit)
+		assert.Equal(t, expected, actual, "result with at most %s devices per rule depends on order of events", maxDevices.String())
+	})
+}
+
+// fuzzTrackerObjects builds up to three slices with up to three devices each
+// and up to three taint rules with random selectors.
+func fuzzTrackerObjects(in *fuzzinput.Input) ([]*resourceapi.ResourceSlice, []*resourcealphaapi.DeviceTaintRule) {
+	var resourceSlices []*resourceapi.ResourceSlice
+	for i := range in.Intn(4) {
+		var devices []resourceapi.Device
+		for j := range in.Intn(4) {
+			device := builders.MakeDevice(fmt.Sprintf("device-%d", j)).IntAttribute("index", int64(j))
+			if in.Bool() {
+				device.Taints(deviceTaint2)
+			}
+			devices = append(devices, device.Obj())
+		}
+		resourceSlices = append(resourceSlices, builders.MakeResourceSlice(fmt.Sprintf("slice-%d", i)).
+			Driver(fuzzinput.Pick(in, driver1, driver2)).
+			Pool(fuzzinput.Pick(in, pool1, pool2)).
+			Devices(devices...).
+			Obj())
+	}
+
+	var rules []*resourcealphaapi.DeviceTaintRule
+	for i := range in.Intn(4) {
+		taint := builders.MakeDeviceTaint(fuzzinput.Pick(in, "example.com/a", "example.com/b")).
+			Effect(fuzzinput.Pick(in, resourceapi.DeviceTaintEffectNoSchedule, resourceapi.DeviceTaintEffectNoExecute)).
+			Obj()
+		rule := builders.MakeDeviceTaintRule(fmt.Sprintf("rule-%d", i), taint)
+		if in.Bool() {
+			rule.Driver(fuzzinput.Pick(in, driver1, driver2))
+		}
+		if in.Bool() {
+			rule.Pool(fuzzinput.Pick(in, pool1, pool2))
+		}
+		if in.Bool() {
+			rule.Device(fuzzinput.Pick(in, device0Name, device1Name, device2Name))
+		}
+		if in.Bool() {
+			rule.DeviceClassName(fuzzinput.Pick(in, deviceClass1.Name, "no-such-class"))
+		}
+		if in.Bool() {
+			rule.Selectors(fuzzinput.Pick(in,
+				`true`,
+				`false`,
+				`device.attributes["`+driver1+`"].index > 0`,
+				`device.attributes["test.example.com"].deviceAttr`,
+				`device.attributes["`+driver1+`"].index == 1`,
+			))
+		}
+		rules = append(rules, rule.Obj())
+	}
+	return resourceSlices, rules
+}
+
+// fuzzPatchedSlices runs the events through a new tracker and returns
+// the patched slices, sorted by name. The tracker options can be modified
+// before creating the tracker.
+func fuzzPatchedSlices(t *testing.T, events []any, modifyOptions ...func(*Options)) []*resourceapi.ResourceSlice {
+	logger, ctx := ktesting.NewTestContext(t)
+	// CEL runtime errors are expected, don't
+	// log them at the default verbosity.
+	ctx = klog.NewContext(ctx, logger.V(5))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	kubeClient := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+	opts := Options{
+		Features:      featuregates.Features{DeviceTaints: true},
+		SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+		TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+		ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+		KubeClient:    kubeClient,
+	}
+	for _, modify := range modifyOptions {
+		modify(&opts)
+	}
+	tracker, err := newTracker(ctx, opts)
+	require.NoError(t, err)
+	defer tracker.Stop()
+	tracker.handleError = func(_ context.Context, err error, msg string, _ ...any) {
+		t.Errorf("unexpected unhandled error: %s: %v", msg, err)
+	}
+
+	runInputEvents(&testContext{T: t, Context: ctx, Tracker: tracker, Clientset: kubeClient}, events)
+	patchedSlices, err := tracker.ListPatchedResourceSlices()
+	require.NoError(t, err, "list patched resource slices")
+	slices.SortFunc(patchedSlices, func(a, b *resourceapi.ResourceSlice) int {
+		return stdcmp.Compare(a.Name, b.Name)
+	})
+	return patchedSlices
+}
+
+// checkPatchedSlices verifies that the patched slices are the input slices
+// with only additional taints from the rules.
+func checkPatchedSlices(t *testing.T, resourceSlices []*resourceapi.ResourceSlice, rules []*resourcealphaapi.DeviceTaintRule, patchedSlices []*resourceapi.ResourceSlice) {
+	type taintID struct {
+		key    string
+		effect string
+	}
+	ruleTaints := sets.New[taintID]()
+	for _, rule := range rules {
+		ruleTaints.Insert(taintID{key: rule.Spec.Taint.Key, effect: string(rule.Spec.Taint.Effect)})
+	}
+	require.Len(t, patchedSlices, len(resourceSlices), "number of patched slices")
+	for _, patched := range patchedSlices {
+		index := slices.IndexFunc(resourceSlices, func(slice *resourceapi.ResourceSlice) bool { return slice.Name == patched.Name })
+		require.GreaterOrEqual(t, index, 0, "unknown slice %s", patched.Name)
+		original := resourceSlices[index]
+		require.Len(t, patched.Spec.Devices, len(original.Spec.Devices), "devices in slice %s", patched.Name)
+		for i, device := range patched.Spec.Devices {
+			originalDevice := original.Spec.Devices[i]
+			assert.Equal(t, originalDevice.Name, device.Name)
+			require.GreaterOrEqual(t, len(device.Taints), len(originalDevice.Taints), "taints of device %s", device.Name)
+			if len(originalDevice.Taints) > 0 {
+				assert.Equal(t, originalDevice.Taints, device.Taints[:len(originalDevice.Taints)], "original taints of device %s", device.Name)
+			}
+			for _, taint := range device.Taints[len(originalDevice.Taints):] {
+				assert.True(t, ruleTaints.Has(taintID{key: taint.Key, effect: string(taint.Effect)}), "taint %s of device %s not from a rule", taint.Key, device.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+// TaintMergeStrategy determines what happens when a DeviceTaintRule adds
+// a taint to a device which already has a taint with the same key and
+// effect, either because the driver published it or because some other
+// rule added it before. Rules are applied in the order of their names.
+type TaintMergeStrategy string
+
+const (
+	// TaintMergeAppend adds the taint, regardless of existing taints.
+	// This is the default.
+	TaintMergeAppend TaintMergeStrategy = "Append"
+
+	// TaintMergeReplace replaces the value and TimeAdded of the existing
+	// taint with those of the new taint.
+	TaintMergeReplace TaintMergeStrategy = "Replace"
+
+	// TaintMergeKeepOldest keeps whichever of the two taints was added
+	// first according to TimeAdded. A taint without TimeAdded is considered
+	// older than a taint which has it. If that does not distinguish them,
+	// the existing taint is kept.
+	TaintMergeKeepOldest TaintMergeStrategy = "KeepOldest"
+
+	// TaintMergeDedupe skips the taint if there is an existing taint
+	// with the same value. Otherwise the taint gets added.
+	TaintMergeDedupe TaintMergeStrategy = "Dedupe"
+)
+
+func (s TaintMergeStrategy) validate() error {
+	switch s {
+	case "", TaintMergeAppend, TaintMergeReplace, TaintMergeKeepOldest, TaintMergeDedupe:
+		return nil
+	default:
+		return fmt.Errorf("unknown taint merge strategy %q", s)
+	}
+}
+
+// mergeTaint adds the taint to the existing taints of a device according
+// to the strategy. The existing taints may get modified in place.
+func (s TaintMergeStrategy) mergeTaint(taints []resourceapi.DeviceTaint, taint resourceapi.DeviceTaint) []resourceapi.DeviceTaint {
+	for i := range taints {
+		existing := &taints[i]
+		if existing.Key != taint.Key || existing.Effect != taint.Effect {
+			continue
+		}
+		switch s {
+		case TaintMergeReplace:
+			existing.Value = taint.Value
+			existing.TimeAdded = taint.TimeAdded
+			return taints
+		case TaintMergeKeepOldest:
+			if existing.TimeAdded != nil && (taint.TimeAdded == nil || taint.TimeAdded.Before(existing.TimeAdded)) {
+				*existing = taint
+			}
+			return taints
+		case TaintMergeDedupe:
+			if existing.Value == taint.Value {
+				return taints
+			}
+		}
+	}
+	return append(taints, taint)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/builders"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestMergeTaint(t *testing.T) {
+	older := &metav1.Time{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newer := &metav1.Time{Time: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}
+	taint := func(key, value string, effect resourceapi.DeviceTaintEffect, timeAdded *metav1.Time) resourceapi.DeviceTaint {
+		return builders.MakeDeviceTaint(key).Value(value).Effect(effect).TimeAdded(timeAdded).Obj()
+	}
+	// Published by the driver, without TimeAdded.
+	driverTaint := taint("example.com/a", "driver", resourceapi.DeviceTaintEffectNoSchedule, nil)
+	// Added by rules.
+	oldTaint := taint("example.com/a", "old", resourceapi.DeviceTaintEffectNoSchedule, older)
+	newTaint := taint("example.com/a", "new", resourceapi.DeviceTaintEffectNoSchedule, newer)
+	newTaintSameValue := taint("example.com/a", "old", resourceapi.DeviceTaintEffectNoSchedule, newer)
+	otherEffect := taint("example.com/a", "new", resourceapi.DeviceTaintEffectNoExecute, newer)
+	otherKey := taint("example.com/b", "new", resourceapi.DeviceTaintEffectNoSchedule, newer)
+
+	testcases := map[string]struct {
+		strategy TaintMergeStrategy
+		existing []resourceapi.DeviceTaint
+		add      []resourceapi.DeviceTaint
+		expected []resourceapi.DeviceTaint
+	}{
+		"default-appends": {
+			existing: []resourceapi.DeviceTaint{oldTaint},
+			add:      []resourceapi.DeviceTaint{newTaint},
+			expected: []resourceapi.DeviceTaint{oldTaint, newTaint},
+		},
+		"append": {
+			strategy: TaintMergeAppend,
+			existing: []resourceapi.DeviceTaint{driverTaint},
+			add:      []resourceapi.DeviceTaint{oldTaint, oldTaint},
+			expected: []resourceapi.DeviceTaint{driverTaint, oldTaint, oldTaint},
+		},
+		"replace-driver-taint": {
+			strategy: TaintMergeReplace,
+			existing: []resourceapi.DeviceTaint{driverTaint},
+			add:      []resourceapi.DeviceTaint{newTaint},
+			expected: []resourceapi.DeviceTaint{newTaint},
+		},
+		"replace-conflicting-rules": {
+			strategy: TaintMergeReplace,
+			add:      []resourceapi.DeviceTaint{newTaint, oldTaint},
+			expected: []resourceapi.DeviceTaint{oldTaint},
+		},
+		"replace-other-effect-and-key": {
+			strategy: TaintMergeReplace,
+			existing: []resourceapi.DeviceTaint{oldTaint},
+			add:      []resourceapi.DeviceTaint{otherEffect, otherKey},
+			expected: []resourceapi.DeviceTaint{oldTaint, otherEffect, otherKey},
+		},
+		"keep-oldest-driver-taint": {
+			strategy: TaintMergeKeepOldest,
+			existing: []resourceapi.DeviceTaint{driverTaint},
+			add:      []resourceapi.DeviceTaint{oldTaint},
+			expected: []resourceapi.DeviceTaint{driverTaint},
+		},
+		"keep-oldest-conflicting-rules": {
+			strategy: TaintMergeKeepOldest,
+			add:      []resourceapi.DeviceTaint{newTaint, oldTaint, newTaint},
+			expected: []resourceapi.DeviceTaint{oldTaint},
+		},
+		"dedupe-same-value": {
+			strategy: TaintMergeDedupe,
+			existing: []resourceapi.DeviceTaint{oldTaint},
+			add:      []resourceapi.DeviceTaint{newTaintSameValue},
+			expected: []resourceapi.DeviceTaint{oldTaint},
+		},
+		"dedupe-different-value": {
+			strategy: TaintMergeDedupe,
+			existing: []resourceapi.DeviceTaint{driverTaint},
+			add:      []resourceapi.DeviceTaint{oldTaint, oldTaint},
+			expected: []resourceapi.DeviceTaint{driverTaint, oldTaint},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			taints := append([]resourceapi.DeviceTaint(nil), tc.existing...)
+			for _, taint := range tc.add {
+				taints = tc.strategy.mergeTaint(taints, taint)
+			}
+			assert.Equal(t, tc.expected, taints)
+		})
+	}
+}
+
+func TestTaintMergeStrategy(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+	opts := Options{
+		Features:           featuregates.Features{DeviceTaints: true},
+		SliceInformer:      informerFactory.Resource().V1().ResourceSlices(),
+		TaintInformer:      informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+		ClassInformer:      informerFactory.Resource().V1().DeviceClasses(),
+		TaintMergeStrategy: TaintMergeReplace,
+	}
+	tracker, err := newTracker(ctx, opts)
+	require.NoError(t, err)
+	defer tracker.Stop()
+	tCtx := &testContext{T: t, Context: ctx, Tracker: tracker, Clientset: kubeClient}
+
+	// The taint from the driver gets replaced by both rules.
+	// The one with the later name wins.
+	driverTaint := builders.MakeDeviceTaint(deviceTaint1.Key).Value("driver").Effect(deviceTaint1.Effect).Obj()
+	slice := sliceWithDevices(slice1, []resourceapi.Device{builders.MakeDevice(device1Name).Taints(driverTaint).Obj()})
+	taint2 := builders.MakeDeviceTaint(deviceTaint1.Key).Value("rule-2").Effect(deviceTaint1.Effect).Obj()
+	rule2 := builders.MakeDeviceTaintRule("rule-2", taint2).Obj()
+	runInputEvents(tCtx, []any{add(rule2), add(taintAllDevicesRule), add(slice)})
+
+	patchedSlices, err := tracker.ListPatchedResourceSlices()
+	require.NoError(t, err)
+	assert.Equal(t, []*resourceapi.ResourceSlice{sliceWithDevices(slice1, []resourceapi.Device{builders.MakeDevice(device1Name).Taints(taint2).Obj()})}, patchedSlices)
+
+	opts.TaintMergeStrategy = "Merge"
+	_, err = newTracker(ctx, opts)
+	require.EqualError(t, err, `unknown taint merge strategy "Merge"`)
+}
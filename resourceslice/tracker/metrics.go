@@ -0,0 +1,72 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"k8s.io/component-base/metrics"
+	drametrics "k8s.io/dynamic-resource-allocation/metrics"
+)
+
+const (
+	metricsNamespace = drametrics.Namespace
+	metricsSubsystem = drametrics.SubsystemTracker
+)
+
+var (
+	patchDuration = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "patch_duration_seconds",
+			Help:           "Duration of applying all DeviceTaintRules to one ResourceSlice.",
+			Buckets:        metrics.ExponentialBuckets(0.00001, 4, 10),
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	patchErrors = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "patch_errors_total",
+			Help:           "Number of times that DeviceTaintRules could not be applied to a ResourceSlice.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	celRuntimeErrors = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "cel_runtime_errors_total",
+			Help:           "Number of DeviceTaintRule selector evaluations for a device which failed with a runtime error.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	celEvaluationDuration = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "cel_evaluation_seconds_total",
+			Help:           "Cumulative time spent evaluating CEL selectors for devices, by DeviceTaintRule.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"device_taint_rule"},
+	)
+)
+
+func init() {
+	drametrics.Add(metricsSubsystem, patchDuration, patchErrors, celRuntimeErrors, celEvaluationDuration)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RuleStats describes the cost of evaluating the CEL selectors of one
+// DeviceTaintRule, accumulated since the tracker was started.
+type RuleStats struct {
+	// Name is the name of the DeviceTaintRule.
+	Name string `json:"name"`
+	// Evaluations is the number of times that a selector of the rule
+	// or of the DeviceClass referenced by it was evaluated for a device.
+	Evaluations int64 `json:"evaluations"`
+	// Duration is the total time spent in those evaluations.
+	Duration time.Duration `json:"duration"`
+}
+
+// ruleStats collects RuleStats for all rules.
+type ruleStats struct {
+	mutex sync.Mutex
+	stats map[string]*RuleStats
+}
+
+func (s *ruleStats) add(name string, evaluations int64, duration time.Duration) {
+	celEvaluationDuration.WithLabelValues(name).Add(duration.Seconds())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stats == nil {
+		s.stats = make(map[string]*RuleStats)
+	}
+	stats := s.stats[name]
+	if stats == nil {
+		stats = &RuleStats{Name: name}
+		s.stats[name] = stats
+	}
+	stats.Evaluations += evaluations
+	stats.Duration += duration
+}
+
+func (s *ruleStats) delete(name string) {
+	celEvaluationDuration.DeleteLabelValues(name)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.stats, name)
+}
+
+// RuleStats returns the cost of all DeviceTaintRules which have CEL
+// selectors, most expensive rule first. The same information is available
+// through the cel_evaluation_seconds_total metric of the tracker.
+func (t *Tracker) RuleStats() []RuleStats {
+	t.ruleStats.mutex.Lock()
+	defer t.ruleStats.mutex.Unlock()
+
+	result := make([]RuleStats, 0, len(t.ruleStats.stats))
+	for _, stats := range t.ruleStats.stats {
+		result = append(result, *stats)
+	}
+	slices.SortFunc(result, func(a, b RuleStats) int {
+		return cmp.Or(
+			cmp.Compare(b.Duration, a.Duration),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return result
+}
+
+// debugState is the content of the dump written by [Tracker.WriteDebugState].
+type debugState struct {
+	Synced         bool        `json:"synced"`
+	Revision       uint64      `json:"revision"`
+	ResourceSlices int         `json:"resourceSlices"`
+	Rules          []RuleStats `json:"rules"`
+}
+
+// WriteDebugState writes a JSON dump of the tracker state for debugging,
+// including the [Tracker.RuleStats]. The format may change.
+func (t *Tracker) WriteDebugState(w io.Writer) error {
+	resourceSlices, err := t.ListPatchedResourceSlices()
+	if err != nil {
+		return err
+	}
+	_, revision := t.Changes(0)
+	state := debugState{
+		Synced:         t.HasSynced(),
+		Revision:       revision,
+		ResourceSlices: len(resourceSlices),
+		Rules:          t.RuleStats(),
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(state); err != nil {
+		return fmt.Errorf("encode debug state: %w", err)
+	}
+	return nil
+}
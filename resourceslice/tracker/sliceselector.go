@@ -0,0 +1,50 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"fmt"
+
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SliceSelectorAnnotation limits a DeviceTaintRule to ResourceSlices with
+// matching labels, in addition to the criteria in the DeviceSelector of
+// the rule. The value is a label selector in the format accepted by
+// [labels.Parse], for example:
+//
+//	example.com/rack=a1,example.com/firmware in (1.2,1.3)
+//
+// This enables targeting slices which drivers label by rack, firmware
+// batch or maintenance window. A rule with an invalid selector does not
+// apply to any slice.
+const SliceSelectorAnnotation = "resource.kubernetes.io/slice-selector"
+
+// SliceSelector returns the selector from the [SliceSelectorAnnotation]
+// of the rule. Without that annotation, all slices are selected.
+func SliceSelector(rule *resourcealphaapi.DeviceTaintRule) (labels.Selector, error) {
+	value, ok := rule.Annotations[SliceSelectorAnnotation]
+	if !ok {
+		return labels.Everything(), nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s annotation: %w", SliceSelectorAnnotation, err)
+	}
+	return selector, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+// snapshotVersion gets bumped when the content of a snapshot changes
+// in an incompatible way.
+const snapshotVersion = 1
+
+// snapshot is the serialized form of the patched ResourceSlices.
+type snapshot struct {
+	Version        int                          `json:"version"`
+	ResourceSlices []*resourceapi.ResourceSlice `json:"resourceSlices"`
+}
+
+// WriteSnapshot serializes the patched ResourceSlices. The result can be
+// passed to [Options.Snapshot] when starting the tracker again, for example
+// after a restart of the component.
+//
+// Only a synced tracker has a complete view of the cluster, so writing
+// a snapshot before that fails.
+func (t *Tracker) WriteSnapshot(w io.Writer) error {
+	if !t.HasSynced() {
+		return errors.New("tracker has not synced yet")
+	}
+	resourceSlices, err := t.ListPatchedResourceSlices()
+	if err != nil {
+		return err
+	}
+	// Sorting makes the output deterministic.
+	slices.SortFunc(resourceSlices, func(a, b *resourceapi.ResourceSlice) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(snapshot{Version: snapshotVersion, ResourceSlices: resourceSlices}); err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	return nil
+}
+
+// readSnapshot is the counterpart of [Tracker.WriteSnapshot].
+func readSnapshot(r io.Reader) ([]*resourceapi.ResourceSlice, error) {
+	var s snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, expected %d", s.Version, snapshotVersion)
+	}
+	return s.ResourceSlices, nil
+}
+
+// listSnapshot returns the ResourceSlices from the snapshot until the
+// tracker has synced. After that, the snapshot is no longer needed.
+func (t *Tracker) listSnapshot() ([]*resourceapi.ResourceSlice, bool) {
+	t.snapshotMutex.Lock()
+	defer t.snapshotMutex.Unlock()
+
+	if t.snapshot == nil {
+		return nil, false
+	}
+	if t.HasSynced() {
+		t.snapshot = nil
+		return nil, false
+	}
+	return slices.Clone(t.snapshot), true
+}
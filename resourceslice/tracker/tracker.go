@@ -20,14 +20,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"slices"
+	"strings"
 	"sync"
+	"time"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	v1 "k8s.io/api/core/v1"
 	resourceapi "k8s.io/api/resource/v1"
 	resourcealphaapi "k8s.io/api/resource/v1alpha3"
 	labels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	resourceinformers "k8s.io/client-go/informers/resource/v1"
@@ -38,13 +46,19 @@ import (
 	resourcelisters "k8s.io/client-go/listers/resource/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/dynamic-resource-allocation/api/convert"
 	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/dynamic-resource-allocation/tracing"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/buffer"
 	"k8s.io/utils/ptr"
 )
 
 const (
+	// instrumentationName is used for the OpenTelemetry tracer.
+	instrumentationName = "k8s.io/dynamic-resource-allocation/resourceslice/tracker"
+
 	driverPoolDeviceIndexName = "driverPoolDevice"
 
 	anyDriver = "*"
@@ -52,12 +66,37 @@ const (
 	anyDevice = "*"
 )
 
+// Interface is the part of [Tracker] which is needed by consumers of
+// the patched ResourceSlices. It is also implemented by the fake tracker in
+// [k8s.io/dynamic-resource-allocation/resourceslice/tracker/trackertesting].
+type Interface interface {
+	// HasSynced is the same as [Tracker.HasSynced].
+	HasSynced() bool
+	// ListPatchedResourceSlices is the same as [Tracker.ListPatchedResourceSlices].
+	ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error)
+	// AddEventHandler is the same as [Tracker.AddEventHandler].
+	AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error)
+}
+
+var _ Interface = &Tracker{}
+
 // Tracker maintains a view of ResourceSlice objects with matching
 // DeviceTaintRules applied. It is backed by informers to process
 // potential changes to resolved ResourceSlices asynchronously.
 type Tracker struct {
 	enableDeviceTaints bool
 
+	// snapshotMutex protects snapshot, which is only set
+	// until the tracker has synced.
+	snapshotMutex sync.Mutex
+	snapshot      []*resourceapi.ResourceSlice
+
+	// changes is updated for each event. For the minimal wrapper,
+	// changesHandle is an additional handler for the ResourceSlice
+	// informer which does that.
+	changes       changeLog
+	changesHandle cache.ResourceEventHandlerRegistration
+
 	resourceSliceLister   resourcelisters.ResourceSliceLister
 	resourceSlices        cache.SharedIndexInformer
 	resourceSlicesHandle  cache.ResourceEventHandlerRegistration
@@ -66,6 +105,10 @@ type Tracker struct {
 	deviceClasses         cache.SharedIndexInformer
 	deviceClassesHandle   cache.ResourceEventHandlerRegistration
 	celCache              *cel.Cache
+	deviceLimiter         *deviceLimiter
+	ruleStats             ruleStats
+	taintMergeStrategy    TaintMergeStrategy
+	tracer                trace.Tracer
 	patchedResourceSlices cache.Store
 	broadcaster           record.EventBroadcaster
 	recorder              record.EventRecorder
@@ -73,6 +116,12 @@ type Tracker struct {
 	// may be overridden in tests.
 	handleError func(context.Context, error, string, ...any)
 
+	// expiryMutex protects expiryTimers, which contains one timer for
+	// each DeviceTaintRule with a pending [TaintExpiresAnnotation]. It
+	// is nil after Stop.
+	expiryMutex  sync.Mutex
+	expiryTimers map[string]*time.Timer
+
 	// Synchronizes updates to these fields related to event handlers.
 	rwMutex sync.RWMutex
 	// All registered event handlers.
@@ -101,16 +150,18 @@ type Tracker struct {
 
 // Options configure a [Tracker].
 type Options struct {
-	// EnableDeviceTaints controls whether DeviceTaintRules
-	// will be reflected in ResourceSlices reported by the tracker.
+	// Features controls which optional features are supported.
 	//
+	// DeviceTaints controls whether DeviceTaintRules
+	// will be reflected in ResourceSlices reported by the tracker.
 	// If false, then TaintInformer and ClassInformer
 	// are not needed. The tracker turns into
 	// a thin wrapper around the underlying
 	// SliceInformer, with no processing of its own.
-	EnableDeviceTaints bool
-	// EnableConsumableCapacity defines whether the CEL compiler supports the DRAConsumableCapacity feature.
-	EnableConsumableCapacity bool
+	//
+	// ConsumableCapacity controls whether CEL expressions in
+	// DeviceTaintRules may use consumable capacity.
+	Features featuregates.Features
 
 	SliceInformer resourceinformers.ResourceSliceInformer
 	TaintInformer resourcealphainformers.DeviceTaintRuleInformer
@@ -119,22 +170,71 @@ type Options struct {
 	// KubeClient is used to generate Events when CEL expressions
 	// encounter runtime errors.
 	KubeClient kubernetes.Interface
+
+	// TaintMergeStrategy determines how taints from DeviceTaintRules get
+	// combined with existing taints of a device that have the same key and
+	// effect. The default is [TaintMergeAppend].
+	TaintMergeStrategy TaintMergeStrategy
+
+	// MaxDevicesPerRule limits how many devices a single DeviceTaintRule
+	// may taint, either as an absolute number or as a percentage of all
+	// devices in all ResourceSlices (rounded up). A rule which matches
+	// more devices does not get applied at all and a [ReasonTooManyDevices]
+	// Warning event gets emitted for it. This protects against a rule with
+	// a typo in its selector which taints the entire cluster.
+	//
+	// The default is to not limit rules.
+	MaxDevicesPerRule *intstr.IntOrString
+
+	// Snapshot optionally provides the output of [Tracker.WriteSnapshot]
+	// from a previous instance. Until the tracker has synced,
+	// [Tracker.ListPatchedResourceSlices] then returns the ResourceSlices
+	// from the snapshot instead of an incomplete view of the cluster.
+	// This enables components with strict startup requirements to make
+	// decisions based on slightly stale data.
+	//
+	// Event handlers are not affected. They only get informed about
+	// the current ResourceSlices.
+	Snapshot io.Reader
+
+	// TracerProvider enables OpenTelemetry tracing. Each slice that
+	// gets patched then creates a "PatchResourceSlice" span with the
+	// slice, driver, pool and tainted devices as attributes, using the
+	// keys from the tracing package. The default is to not trace.
+	TracerProvider trace.TracerProvider
 }
 
 // StartTracker creates and initializes informers for a new [Tracker].
 func StartTracker(ctx context.Context, opts Options) (finalT *Tracker, finalErr error) {
-	if !opts.EnableDeviceTaints {
+	var snapshot []*resourceapi.ResourceSlice
+	if opts.Snapshot != nil {
+		var err error
+		snapshot, err = readSnapshot(opts.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !opts.Features.DeviceTaints {
 		// Minimal wrapper. All public methods shortcut by calling the underlying informer.
-		return &Tracker{
+		t := &Tracker{
 			resourceSliceLister: opts.SliceInformer.Lister(),
 			resourceSlices:      opts.SliceInformer.Informer(),
-		}, nil
+			snapshot:            snapshot,
+		}
+		var err error
+		t.changesHandle, err = t.resourceSlices.AddEventHandler(t.changes.handler())
+		if err != nil {
+			return nil, fmt.Errorf("add event handler for ResourceSlices: %w", err)
+		}
+		return t, nil
 	}
 
 	t, err := newTracker(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
+	t.snapshot = snapshot
 	defer func() {
 		// If we don't return the tracker, stop the partially initialized instance.
 		if finalErr != nil {
@@ -150,14 +250,15 @@ func StartTracker(ctx context.Context, opts Options) (finalT *Tracker, finalErr
 // newTracker is used in testing to construct a tracker without informer event handlers.
 func newTracker(ctx context.Context, opts Options) (finalT *Tracker, finalErr error) {
 	t := &Tracker{
-		enableDeviceTaints:    opts.EnableDeviceTaints,
+		enableDeviceTaints:    opts.Features.DeviceTaints,
 		resourceSliceLister:   opts.SliceInformer.Lister(),
 		resourceSlices:        opts.SliceInformer.Informer(),
 		deviceTaints:          opts.TaintInformer.Informer(),
 		deviceClasses:         opts.ClassInformer.Informer(),
-		celCache:              cel.NewCache(10, cel.Features{EnableConsumableCapacity: opts.EnableConsumableCapacity}),
+		celCache:              cel.NewCache(10, opts.Features.CEL()),
 		patchedResourceSlices: cache.NewStore(cache.MetaNamespaceKeyFunc),
 		handleError:           utilruntime.HandleErrorWithContext,
+		expiryTimers:          make(map[string]*time.Timer),
 		eventQueue:            *buffer.NewRing[func()](buffer.RingOptions{InitialSize: 0, NormalSize: 4}),
 	}
 	defer func() {
@@ -166,6 +267,22 @@ func newTracker(ctx context.Context, opts Options) (finalT *Tracker, finalErr er
 			t.Stop()
 		}
 	}()
+	tracerProvider := opts.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = noop.NewTracerProvider()
+	}
+	t.tracer = tracerProvider.Tracer(instrumentationName)
+	if err := opts.TaintMergeStrategy.validate(); err != nil {
+		return nil, err
+	}
+	t.taintMergeStrategy = opts.TaintMergeStrategy
+	if opts.MaxDevicesPerRule != nil {
+		limiter, err := newDeviceLimiter(*opts.MaxDevicesPerRule)
+		if err != nil {
+			return nil, err
+		}
+		t.deviceLimiter = limiter
+	}
 	err := t.resourceSlices.AddIndexers(cache.Indexers{driverPoolDeviceIndexName: sliceDriverPoolDeviceIndexFunc})
 	if err != nil {
 		return nil, fmt.Errorf("failed to add %s index to ResourceSlice informer: %w", driverPoolDeviceIndexName, err)
@@ -243,6 +360,9 @@ func (t *Tracker) HasSynced() bool {
 // Stop ends all background activity and blocks until that shutdown is complete.
 func (t *Tracker) Stop() {
 	if !t.enableDeviceTaints {
+		if t.changesHandle != nil {
+			_ = t.resourceSlices.RemoveEventHandler(t.changesHandle)
+		}
 		return
 	}
 
@@ -252,11 +372,17 @@ func (t *Tracker) Stop() {
 	_ = t.resourceSlices.RemoveEventHandler(t.resourceSlicesHandle)
 	_ = t.deviceTaints.RemoveEventHandler(t.deviceTaintsHandle)
 	_ = t.deviceClasses.RemoveEventHandler(t.deviceClassesHandle)
+	t.stopExpiry()
 }
 
 // ListPatchedResourceSlices returns all ResourceSlices in the cluster with
-// modifications from DeviceTaints applied.
+// modifications from DeviceTaints applied. If the tracker was started with
+// a snapshot, then the content of that snapshot is returned until the
+// tracker has synced.
 func (t *Tracker) ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error) {
+	if resourceSlices, ok := t.listSnapshot(); ok {
+		return resourceSlices, nil
+	}
 	if !t.enableDeviceTaints {
 		return t.resourceSliceLister.List(labels.Everything())
 	}
@@ -282,7 +408,7 @@ func (t *Tracker) AddEventHandler(handler cache.ResourceEventHandler) (cache.Res
 	defer t.rwMutex.Unlock()
 
 	t.eventHandlers = append(t.eventHandlers, handler)
-	allObjs, _ := t.ListPatchedResourceSlices()
+	allObjs := typedSlice[*resourceapi.ResourceSlice](t.patchedResourceSlices.List())
 	for _, obj := range allObjs {
 		t.eventQueue.WriteOne(func() {
 			handler.OnAdd(obj, true)
@@ -322,6 +448,7 @@ func (t *Tracker) emitEvents() {
 func (t *Tracker) pushEvent(oldObj, newObj any) {
 	t.rwMutex.Lock()
 	defer t.rwMutex.Unlock()
+	t.changes.record(newObj, oldObj)
 	for _, handler := range t.eventHandlers {
 		handler := handler
 		if oldObj == nil {
@@ -437,6 +564,7 @@ func (t *Tracker) deviceTaintAdd(ctx context.Context) func(obj any) {
 			return
 		}
 		logger.V(5).Info("DeviceTaintRule add", "patch", klog.KObj(patch))
+		t.scheduleExpiry(ctx, patch.Name, patch)
 		for _, sliceName := range t.sliceNamesForPatch(ctx, patch) {
 			t.syncSlice(ctx, sliceName, false)
 		}
@@ -459,6 +587,7 @@ func (t *Tracker) deviceTaintUpdate(ctx context.Context) func(oldObj, newObj any
 		} else {
 			logger.V(5).Info("DeviceTaintRule update", "patch", klog.KObj(newPatch))
 		}
+		t.scheduleExpiry(ctx, newPatch.Name, newPatch)
 
 		// Slices that matched the old patch may need to be updated, in
 		// case they no longer match the new patch and need to have the
@@ -483,6 +612,11 @@ func (t *Tracker) deviceTaintDelete(ctx context.Context) func(obj any) {
 			return
 		}
 		logger.V(5).Info("DeviceTaintRule delete", "patch", klog.KObj(patch))
+		t.scheduleExpiry(ctx, patch.Name, nil)
+		if t.deviceLimiter != nil {
+			t.deviceLimiter.deleteRule(patch.Name)
+		}
+		t.ruleStats.delete(patch.Name)
 		for _, sliceName := range t.sliceNamesForPatch(ctx, patch) {
 			t.syncSlice(ctx, sliceName, false)
 		}
@@ -549,6 +683,8 @@ func (t *Tracker) deviceClassDelete(ctx context.Context) func(obj any) {
 // doing costly DeepEqual comparisons where possible.
 func (t *Tracker) syncSlice(ctx context.Context, name string, sendEvent bool) {
 	defer t.emitEvents()
+	// Runs first, after the patched slice is stored.
+	defer t.checkDeviceLimits(ctx)
 
 	logger := klog.FromContext(ctx)
 	logger = klog.LoggerWithValues(logger, "resourceslice", name)
@@ -566,6 +702,9 @@ func (t *Tracker) syncSlice(ctx context.Context, name string, sendEvent bool) {
 		return
 	}
 	if !sliceExists {
+		if t.deviceLimiter != nil {
+			t.deviceLimiter.resetSlice(name, 0)
+		}
 		err := t.patchedResourceSlices.Delete(oldPatchedObj)
 		if err != nil {
 			t.handleError(ctx, err, "failed to delete cached patched resource slice", "resourceslice", name)
@@ -591,16 +730,46 @@ func (t *Tracker) syncSlice(ctx context.Context, name string, sendEvent bool) {
 	}
 
 	patches := typedSlice[*resourcealphaapi.DeviceTaintRule](t.deviceTaints.GetIndexer().List())
-	patchedSlice, err := t.applyPatches(ctx, slice, patches)
+	// The order of the cache is random. Sorting makes the order of the
+	// added taints deterministic, otherwise the patched slice would
+	// change each time that it gets synced.
+	slices.SortFunc(patches, func(a, b *resourcealphaapi.DeviceTaintRule) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	start := time.Now()
+	patchCtx, span := t.tracer.Start(ctx, "PatchResourceSlice", trace.WithAttributes(
+		tracing.ResourceSliceKey.String(slice.Name),
+		tracing.DriverKey.String(slice.Spec.Driver),
+		tracing.PoolKey.String(slice.Spec.Pool.Name),
+	))
+	patchedSlice, err := t.applyPatches(patchCtx, slice, patches)
+	patchDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		span.End()
+		patchErrors.Inc()
 		t.handleError(ctx, err, "failed to apply patches to ResourceSlice", "resourceslice", klog.KObj(slice))
 		return
 	}
+	if span.IsRecording() {
+		var tainted []string
+		for i := range patchedSlice.Spec.Devices {
+			if len(patchedSlice.Spec.Devices[i].Taints) > len(slice.Spec.Devices[i].Taints) {
+				tainted = append(tainted, tracing.DeviceID(slice.Spec.Driver, slice.Spec.Pool.Name, slice.Spec.Devices[i].Name))
+			}
+		}
+		span.SetAttributes(tracing.DevicesKey.StringSlice(tainted))
+	}
+	span.End()
 
 	// When syncSlice is triggered by something other than a ResourceSlice
 	// event, only the device attributes and capacity might change. We
 	// deliberately avoid any costly DeepEqual-style comparisons here.
 	if !sendEvent && oldPatchedSlice != nil {
+		if patchedSlice != slice {
+			keepTimeAdded(oldPatchedSlice, patchedSlice)
+		}
 		for i := range patchedSlice.Spec.Devices {
 			oldDevice := oldPatchedSlice.Spec.Devices[i]
 			newDevice := patchedSlice.Spec.Devices[i]
@@ -631,10 +800,35 @@ func (t *Tracker) applyPatches(ctx context.Context, slice *resourceapi.ResourceS
 	// slice will be DeepCopied just-in-time, only when necessary.
 	patchedSlice := slice
 
+	if t.deviceLimiter != nil {
+		t.deviceLimiter.resetSlice(slice.Name, len(slice.Spec.Devices))
+	}
+
 	for _, taintRule := range taintRules {
 		logger := klog.LoggerWithValues(logger, "deviceTaintRule", klog.KObj(taintRule))
 		logger.V(6).Info("processing DeviceTaintRule")
 
+		sliceSelector, err := SliceSelector(taintRule)
+		if err != nil {
+			logger.V(7).Info("DeviceTaintRule does not apply, invalid slice selector", "err", err)
+			t.recordRuleEvent(taintRule, ruleEvent{reason: ReasonInvalidSliceSelector}, "%v", err)
+			continue
+		}
+		if !sliceSelector.Matches(labels.Set(slice.Labels)) {
+			logger.V(7).Info("DeviceTaintRule does not apply, mismatched slice labels", "sliceLabels", slice.Labels, "sliceSelector", sliceSelector)
+			continue
+		}
+		expires, err := TaintExpiry(taintRule)
+		if err != nil {
+			logger.V(7).Info("DeviceTaintRule does not apply, invalid expiry", "err", err)
+			t.recordRuleEvent(taintRule, ruleEvent{reason: ReasonInvalidTaintExpiry}, "%v", err)
+			continue
+		}
+		if !expires.IsZero() && !expires.After(time.Now()) {
+			logger.V(7).Info("DeviceTaintRule does not apply, expired", "expires", expires)
+			continue
+		}
+
 		deviceSelector := taintRule.Spec.DeviceSelector
 		var deviceClassExprs []cel.CompilationResult
 		var selectorExprs []cel.CompilationResult
@@ -674,6 +868,12 @@ func (t *Tracker) applyPatches(ctx context.Context, slice *resourceapi.ResourceS
 				}
 			}
 		}
+		var matchedDevices []int
+		var evaluations int64
+		var evalDuration time.Duration
+		// Selectors see the taints published by the driver, not those
+		// added by other DeviceTaintRules, so the outcome does not depend
+		// on the order in which rules get applied.
 	devices:
 		for dIndex, device := range slice.Spec.Devices {
 			deviceID := deviceID(slice.Spec.Driver, slice.Spec.Pool.Name, device.Name)
@@ -693,7 +893,10 @@ func (t *Tracker) applyPatches(ctx context.Context, slice *resourceapi.ResourceS
 					// than the cluster it runs in.
 					return nil, fmt.Errorf("DeviceTaintRule %s: class %s: selector #%d: CEL compile error: %w", taintRule.Name, *deviceSelector.DeviceClassName, i, expr.Error)
 				}
-				matches, details, err := expr.DeviceMatches(ctx, cel.Device{Driver: slice.Spec.Driver, Attributes: device.Attributes, Capacity: device.Capacity})
+				evalStart := time.Now()
+				matches, details, err := expr.DeviceMatches(ctx, cel.Device{Driver: slice.Spec.Driver, Attributes: device.Attributes, Capacity: device.Capacity, Taints: device.Taints})
+				evaluations++
+				evalDuration += time.Since(evalStart)
 				logger.V(7).Info("CEL result", "class", *deviceSelector.DeviceClassName, "selector", i, "expression", expr.Expression, "matches", matches, "actualCost", ptr.Deref(details.ActualCost(), 0), "err", err)
 				if err != nil {
 					continue devices
@@ -712,12 +915,14 @@ func (t *Tracker) applyPatches(ctx context.Context, slice *resourceapi.ResourceS
 					// than the cluster it runs in.
 					return nil, fmt.Errorf("DeviceTaintRule %s: selector #%d: CEL compile error: %w", taintRule.Name, i, expr.Error)
 				}
-				matches, details, err := expr.DeviceMatches(ctx, cel.Device{Driver: slice.Spec.Driver, Attributes: device.Attributes, Capacity: device.Capacity})
+				evalStart := time.Now()
+				matches, details, err := expr.DeviceMatches(ctx, cel.Device{Driver: slice.Spec.Driver, Attributes: device.Attributes, Capacity: device.Capacity, Taints: device.Taints})
+				evaluations++
+				evalDuration += time.Since(evalStart)
 				logger.V(7).Info("CEL result", "selector", i, "expression", expr.Expression, "matches", matches, "actualCost", ptr.Deref(details.ActualCost(), 0), "err", err)
 				if err != nil {
-					if t.recorder != nil {
-						t.recorder.Eventf(taintRule, v1.EventTypeWarning, "CELRuntimeError", "selector #%d: runtime error: %v", i, err)
-					}
+					celRuntimeErrors.Inc()
+					t.recordRuleEvent(taintRule, ruleEvent{reason: ReasonCELRuntimeError, expressionIndex: ptr.To(i)}, "selector #%d: runtime error: %v", i, err)
 					continue devices
 				}
 				if !matches {
@@ -725,27 +930,59 @@ func (t *Tracker) applyPatches(ctx context.Context, slice *resourceapi.ResourceS
 				}
 			}
 
-			logger.V(6).Info("applying matching DeviceTaintRule")
+			matchedDevices = append(matchedDevices, dIndex)
+		}
+		if evaluations > 0 {
+			t.ruleStats.add(taintRule.Name, evaluations, evalDuration)
+		}
 
-			// TODO: remove conversion once taint is already in the right API package.
-			ta := resourceapi.DeviceTaint{
-				Key:       taintRule.Spec.Taint.Key,
-				Value:     taintRule.Spec.Taint.Value,
-				Effect:    resourceapi.DeviceTaintEffect(taintRule.Spec.Taint.Effect),
-				TimeAdded: taintRule.Spec.Taint.TimeAdded,
+		if t.deviceLimiter != nil && len(matchedDevices) > 0 {
+			numMatches, limit := t.deviceLimiter.setMatches(taintRule.Name, slice.Name, len(matchedDevices))
+			if numMatches > limit {
+				logger.V(6).Info("DeviceTaintRule does not apply, too many matching devices", "numMatches", numMatches, "limit", limit)
+				continue
 			}
+		}
+
+		for _, dIndex := range matchedDevices {
+			logger.V(6).Info("applying matching DeviceTaintRule", "device", deviceID(slice.Spec.Driver, slice.Spec.Pool.Name, slice.Spec.Devices[dIndex].Name))
+
+			ta := convert.DeviceTaintFromV1Alpha3(taintRule.Spec.Taint)
 
 			if patchedSlice == slice {
 				patchedSlice = slice.DeepCopy()
 			}
 
-			patchedSlice.Spec.Devices[dIndex].Taints = append(patchedSlice.Spec.Devices[dIndex].Taints, ta)
+			patchedSlice.Spec.Devices[dIndex].Taints = t.taintMergeStrategy.mergeTaint(patchedSlice.Spec.Devices[dIndex].Taints, ta)
 		}
 	}
 
 	return patchedSlice, nil
 }
 
+// keepTimeAdded copies TimeAdded from the old patched slice into the
+// new one for NoSchedule taints which are otherwise the same. Without
+// that, defaulting or updating TimeAdded in a DeviceTaintRule would cause
+// updates of the patched slice although nothing changed for consumers:
+// TimeAdded only matters for evicting pods because of a NoExecute taint.
+//
+// The new slice must be a copy that may be modified.
+func keepTimeAdded(oldSlice, newSlice *resourceapi.ResourceSlice) {
+	for i := range min(len(oldSlice.Spec.Devices), len(newSlice.Spec.Devices)) {
+		oldTaints := oldSlice.Spec.Devices[i].Taints
+		newTaints := newSlice.Spec.Devices[i].Taints
+		for j := range min(len(oldTaints), len(newTaints)) {
+			oldTaint, newTaint := &oldTaints[j], &newTaints[j]
+			if newTaint.Effect == resourceapi.DeviceTaintEffectNoSchedule &&
+				newTaint.Key == oldTaint.Key &&
+				newTaint.Effect == oldTaint.Effect &&
+				newTaint.Value == oldTaint.Value {
+				newTaint.TimeAdded = oldTaint.TimeAdded
+			}
+		}
+	}
+}
+
 func taintsEqual(a, b resourceapi.DeviceTaint) bool {
 	return a.Key == b.Key &&
 		a.Effect == b.Effect &&
@@ -17,28 +17,37 @@ limitations under the License.
 package tracker
 
 import (
+	"bytes"
 	stdcmp "cmp"
 	"context"
 	"fmt"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	resourceapi "k8s.io/api/resource/v1"
 	resourcealphaapi "k8s.io/api/resource/v1alpha3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/dynamic-resource-allocation/builders"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/dynamic-resource-allocation/internal/tracetesting"
+	drametrics "k8s.io/dynamic-resource-allocation/metrics"
+	"k8s.io/dynamic-resource-allocation/tracing"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/ktesting"
 	_ "k8s.io/klog/v2/ktesting/init"
-	"k8s.io/utils/ptr"
 )
 
 type handlerEventType string
@@ -141,7 +150,6 @@ type testContext struct {
 }
 
 var (
-	now, _      = time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
 	driver1     = "driver1.example.com"
 	driver2     = "driver2.example.com"
 	pool1       = "pool-1"
@@ -150,76 +158,25 @@ var (
 	device1Name = "device-1"
 	device2Name = "device-2"
 
-	deviceClass1 = &resourceapi.DeviceClass{
-		ObjectMeta: metav1.ObjectMeta{Name: "device-class-1"},
-		Spec: resourceapi.DeviceClassSpec{
-			Selectors: []resourceapi.DeviceSelector{
-				{
-					CEL: &resourceapi.CELDeviceSelector{
-						Expression: `device.driver == "` + driver1 + `"`,
-					},
-				},
-			},
-		},
-	}
+	deviceClass1 = builders.MakeDeviceClass("device-class-1").DriverSelector(driver1).Obj()
 
 	sliceWithDevices = func(slice *resourceapi.ResourceSlice, devices []resourceapi.Device) *resourceapi.ResourceSlice {
 		slice = slice.DeepCopy()
 		slice.Spec.Devices = devices
 		return slice
 	}
-	slice1NoDevices = &resourceapi.ResourceSlice{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "s1",
-		},
-		Spec: resourceapi.ResourceSliceSpec{
-			Driver: driver1,
-			Pool: resourceapi.ResourcePool{
-				Name: pool1,
-			},
-		},
-	}
-	slice2NoDevices = &resourceapi.ResourceSlice{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "s2",
-		},
-		Spec: resourceapi.ResourceSliceSpec{
-			Driver: driver2,
-			Pool: resourceapi.ResourcePool{
-				Name: pool2,
-			},
-		},
-	}
-	unchangedSlice = &resourceapi.ResourceSlice{ObjectMeta: metav1.ObjectMeta{Name: "no-change"}}
+	slice1NoDevices = builders.MakeResourceSlice("s1").Driver(driver1).Pool(pool1).Obj()
+	slice2NoDevices = builders.MakeResourceSlice("s2").Driver(driver2).Pool(pool2).Obj()
+	unchangedSlice  = builders.MakeResourceSlice("no-change").Obj()
 
-	deviceWithName = func(device resourceapi.Device, name string) resourceapi.Device {
-		device.Name = name
-		return device
-	}
-	deviceWithTaints = func(device resourceapi.Device, taints []resourceapi.DeviceTaint) resourceapi.Device {
-		device.Taints = taints
-		return device
-	}
-	emptyDevice = resourceapi.Device{}
-	device0     = deviceWithName(emptyDevice, device0Name)
-	device1     = deviceWithName(emptyDevice, device1Name)
-	device2     = deviceWithName(emptyDevice, device2Name)
-
-	deviceTaint1 = resourceapi.DeviceTaint{
-		Key:       "example.com/taint",
-		Value:     "tainted",
-		Effect:    resourceapi.DeviceTaintEffectNoExecute,
-		TimeAdded: &metav1.Time{Time: now},
-	}
-	deviceTaint2 = resourceapi.DeviceTaint{
-		Key:       "example.com/taint2",
-		Value:     "tainted2",
-		Effect:    resourceapi.DeviceTaintEffectNoExecute,
-		TimeAdded: &metav1.Time{Time: now},
-	}
-	deviceTaints   = []resourceapi.DeviceTaint{deviceTaint1}
-	device1Tainted = deviceWithTaints(device1, deviceTaints)
-	device2Tainted = deviceWithTaints(device2, deviceTaints)
+	deviceTaint1 = builders.MakeDeviceTaint("example.com/taint").Value("tainted").Effect(resourceapi.DeviceTaintEffectNoExecute).Obj()
+	deviceTaint2 = builders.MakeDeviceTaint("example.com/taint2").Value("tainted2").Effect(resourceapi.DeviceTaintEffectNoExecute).Obj()
+
+	device0        = builders.MakeDevice(device0Name).Obj()
+	device1        = builders.MakeDevice(device1Name).Obj()
+	device2        = builders.MakeDevice(device2Name).Obj()
+	device1Tainted = builders.MakeDevice(device1Name).Taints(deviceTaint1).Obj()
+	device2Tainted = builders.MakeDevice(device2Name).Taints(deviceTaint1).Obj()
 	devices        = []resourceapi.Device{device1}
 	threeDevices   = []resourceapi.Device{
 		device0,
@@ -235,12 +192,8 @@ var (
 	taintedDevices  = []resourceapi.Device{device1Tainted}
 	taintedDevices2 = []resourceapi.Device{device2Tainted}
 
-	existingDeviceTaints   = []resourceapi.DeviceTaint{deviceTaint2}
-	existingDevice1Tainted = deviceWithTaints(device1, existingDeviceTaints)
-	existingTaintedDevices = []resourceapi.Device{existingDevice1Tainted}
-	mergedDeviceTaints     = []resourceapi.DeviceTaint{deviceTaint2, deviceTaint1}
-	mergedDevice1Tainted   = deviceWithTaints(device1, mergedDeviceTaints)
-	mergedTaintedDevices   = []resourceapi.Device{mergedDevice1Tainted}
+	existingTaintedDevices = []resourceapi.Device{builders.MakeDevice(device1Name).Taints(deviceTaint2).Obj()}
+	mergedTaintedDevices   = []resourceapi.Device{builders.MakeDevice(device1Name).Taints(deviceTaint2, deviceTaint1).Obj()}
 
 	slice1               = sliceWithDevices(slice1NoDevices, devices)
 	slice1Tainted        = sliceWithDevices(slice1, taintedDevices)
@@ -249,74 +202,41 @@ var (
 	slice2               = sliceWithDevices(slice2NoDevices, devices2)
 	slice2Tainted        = sliceWithDevices(slice2, taintedDevices2)
 
-	alphaDeviceTaint = func(taint resourceapi.DeviceTaint) resourcealphaapi.DeviceTaint {
-		return resourcealphaapi.DeviceTaint{
-			Key:       taint.Key,
-			Value:     taint.Value,
-			Effect:    resourcealphaapi.DeviceTaintEffect(taint.Effect),
-			TimeAdded: taint.TimeAdded,
-		}
-	}
-	taintAllDevicesRule = &resourcealphaapi.DeviceTaintRule{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "rule",
-		},
-		Spec: resourcealphaapi.DeviceTaintRuleSpec{
-			Taint: alphaDeviceTaint(deviceTaint1),
-		},
+	// taintRule starts a new rule which applies deviceTaint1.
+	// Without further selectors it matches all devices.
+	taintRule = func() *builders.DeviceTaintRuleWrapper {
+		return builders.MakeDeviceTaintRule("rule", deviceTaint1)
 	}
-	taintPoolDevicesRule = func(rule *resourcealphaapi.DeviceTaintRule, pool string) *resourcealphaapi.DeviceTaintRule {
-		rule = rule.DeepCopy()
-		rule.Spec.DeviceSelector = &resourcealphaapi.DeviceTaintSelector{
-			Pool: &pool,
-		}
-		return rule
-	}
-	taintDriverDevicesRule = func(rule *resourcealphaapi.DeviceTaintRule, driver string) *resourcealphaapi.DeviceTaintRule {
-		rule = rule.DeepCopy()
-		rule.Spec.DeviceSelector = &resourcealphaapi.DeviceTaintSelector{
-			Driver: &driver,
-		}
-		return rule
-	}
-	taintNamedDevicesRule = func(rule *resourcealphaapi.DeviceTaintRule, name string) *resourcealphaapi.DeviceTaintRule {
-		rule = rule.DeepCopy()
-		rule.Spec.DeviceSelector = &resourcealphaapi.DeviceTaintSelector{
-			Device: &name,
-		}
-		return rule
-	}
-	taintCELSelectedDevicesRule = func(rule *resourcealphaapi.DeviceTaintRule, exprs ...string) *resourcealphaapi.DeviceTaintRule {
-		rule = rule.DeepCopy()
-		var selectors []resourcealphaapi.DeviceSelector
-		for _, expr := range exprs {
-			selectors = append(selectors, resourcealphaapi.DeviceSelector{
-				CEL: &resourcealphaapi.CELDeviceSelector{
-					Expression: expr,
-				},
-			})
-		}
-		rule.Spec.DeviceSelector = &resourcealphaapi.DeviceTaintSelector{
-			Selectors: selectors,
-		}
-		return rule
-	}
-	taintDeviceClassRule = func(rule *resourcealphaapi.DeviceTaintRule, deviceClassName string) *resourcealphaapi.DeviceTaintRule {
-		rule = rule.DeepCopy()
-		rule.Spec.DeviceSelector = &resourcealphaapi.DeviceTaintSelector{
-			DeviceClassName: &deviceClassName,
-		}
-		return rule
-	}
-	taintPool1DevicesRule             = taintPoolDevicesRule(taintAllDevicesRule, pool1)
-	taintPool2DevicesRule             = taintPoolDevicesRule(taintAllDevicesRule, pool2)
-	taintDriver1DevicesRule           = taintDriverDevicesRule(taintAllDevicesRule, driver1)
-	taintDevice1Rule                  = taintNamedDevicesRule(taintAllDevicesRule, device1Name)
-	taintDriver1DevicesCELRule        = taintCELSelectedDevicesRule(taintAllDevicesRule, `device.driver == "`+driver1+`"`)
-	taintNoDevicesCELRule             = taintCELSelectedDevicesRule(taintAllDevicesRule, `true`, `false`, `true`)
-	taintNoDevicesCELRuntimeErrorRule = taintCELSelectedDevicesRule(taintAllDevicesRule, `device.attributes["test.example.com"].deviceAttr`)
-	taintNoDevicesInvalidCELRule      = taintCELSelectedDevicesRule(taintAllDevicesRule, `invalid`)
-	taintDeviceClass1Rule             = taintDeviceClassRule(taintAllDevicesRule, deviceClass1.Name)
+	taintAllDevicesRule               = taintRule().Obj()
+	taintPool1DevicesRule             = taintRule().Pool(pool1).Obj()
+	taintPool2DevicesRule             = taintRule().Pool(pool2).Obj()
+	taintDriver1DevicesRule           = taintRule().Driver(driver1).Obj()
+	taintDevice1Rule                  = taintRule().Device(device1Name).Obj()
+	taintDriver1DevicesCELRule        = taintRule().Selectors(`device.driver == "` + driver1 + `"`).Obj()
+	taintNoDevicesCELRule             = taintRule().Selectors(`true`, `false`, `true`).Obj()
+	taintTaint2DevicesCELRule         = taintRule().Selectors(`device.taints.exists(t, t.key == "example.com/taint2")`).Obj()
+	taintNoDevicesCELRuntimeErrorRule = taintRule().Selectors(`device.attributes["test.example.com"].deviceAttr`).Obj()
+	taintNoDevicesInvalidCELRule      = taintRule().Selectors(`invalid`).Obj()
+	taintDeviceClass1Rule             = taintRule().DeviceClassName(deviceClass1.Name).Obj()
+	taintRackA1Rule                   = taintRule().Annotation(SliceSelectorAnnotation, "example.com/rack=a1").Obj()
+	taintInvalidSliceSelectorRule     = taintRule().Annotation(SliceSelectorAnnotation, "example.com/rack in a1").Obj()
+	noScheduleTaint                   = builders.MakeDeviceTaint("example.com/taint").Value("tainted").Obj()
+	noScheduleTaintWithTime           = builders.MakeDeviceTaint("example.com/taint").Value("tainted").TimeAdded(&metav1.Time{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}).Obj()
+	deviceTaint1WithTime              = builders.MakeDeviceTaint("example.com/taint").Value("tainted").Effect(resourceapi.DeviceTaintEffectNoExecute).TimeAdded(&metav1.Time{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}).Obj()
+	taintNoScheduleRule               = builders.MakeDeviceTaintRule("rule", noScheduleTaint).Obj()
+	taintNoScheduleWithTimeRule       = builders.MakeDeviceTaintRule("rule", noScheduleTaintWithTime).Obj()
+	taintNoExecuteWithTimeRule        = builders.MakeDeviceTaintRule("rule", deviceTaint1WithTime).Obj()
+	taintExpiredRule                  = taintRule().Annotation(TaintExpiresAnnotation, "2000-01-01T00:00:00Z").Obj()
+	taintUnexpiredRule                = taintRule().Annotation(TaintExpiresAnnotation, "2999-01-01T00:00:00Z").Obj()
+	taintInvalidExpiryRule            = taintRule().Annotation(TaintExpiresAnnotation, "tomorrow").Obj()
+
+	slice1NoScheduleTainted = sliceWithDevices(slice1, []resourceapi.Device{builders.MakeDevice(device1Name).Taints(noScheduleTaint).Obj()})
+	slice1TaintedWithTime   = sliceWithDevices(slice1, []resourceapi.Device{builders.MakeDevice(device1Name).Taints(deviceTaint1WithTime).Obj()})
+
+	slice1RackA1        = builders.MakeResourceSlice("s1").Label("example.com/rack", "a1").Driver(driver1).Pool(pool1).Devices(devices...).Obj()
+	slice1RackA1Tainted = sliceWithDevices(slice1RackA1, taintedDevices)
+	slice2RackB2        = builders.MakeResourceSlice("s2").Label("example.com/rack", "b2").Driver(driver2).Pool(pool2).Devices(devices2...).Obj()
+	slice2RackA1        = builders.MakeResourceSlice("s2").Label("example.com/rack", "a1").Driver(driver2).Pool(pool2).Devices(devices2...).Obj()
 )
 
 func TestListPatchedResourceSlices(t *testing.T) {
@@ -499,6 +419,21 @@ func TestListPatchedResourceSlices(t *testing.T) {
 				{event: handlerEventAdd, newObj: slice2},
 			},
 		},
+		"selector-on-published-taints": {
+			events: []any{
+				add(taintTaint2DevicesCELRule),
+				add(slice1AlreadyTainted),
+				add(slice2),
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1MergedTaints,
+				slice2,
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1MergedTaints},
+				{event: handlerEventAdd, newObj: slice2},
+			},
+		},
 		"selector-does-not-match": {
 			events: []any{
 				add(taintNoDevicesCELRule),
@@ -524,7 +459,139 @@ func TestListPatchedResourceSlices(t *testing.T) {
 					return
 				}
 				assert.Equal(t, taintNoDevicesCELRuntimeErrorRule.Name, events.Items[0].InvolvedObject.Name)
-				assert.Equal(t, "CELRuntimeError", events.Items[0].Reason)
+				assert.Equal(t, string(ReasonCELRuntimeError), events.Items[0].Reason)
+				assert.Equal(t, map[string]string{
+					EventAnnotationRule:            taintNoDevicesCELRuntimeErrorRule.Name,
+					EventAnnotationExpressionIndex: "0",
+				}, events.Items[0].Annotations)
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1},
+			},
+		},
+		"slice-selector": {
+			events: []any{
+				add(taintRackA1Rule),
+				add(slice1RackA1),
+				add(slice2RackB2),
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1RackA1Tainted,
+				slice2RackB2,
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1RackA1Tainted},
+				{event: handlerEventAdd, newObj: slice2RackB2},
+			},
+		},
+		"slice-selector-label-change": {
+			events: []any{
+				add(taintRackA1Rule),
+				[]any{
+					add(slice2RackB2),
+					update(slice2RackB2, slice2RackA1),
+				},
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				sliceWithDevices(slice2RackA1, taintedDevices2),
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice2RackB2},
+				{event: handlerEventUpdate, oldObj: slice2RackB2, newObj: sliceWithDevices(slice2RackA1, taintedDevices2)},
+			},
+		},
+		"invalid-slice-selector": {
+			events: []any{
+				add(taintInvalidSliceSelectorRule),
+				add(slice1),
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1,
+			},
+			expectEvents: func(t *assert.CollectT, events *v1.EventList) {
+				if !assert.Len(t, events.Items, 1) {
+					return
+				}
+				assert.Equal(t, taintInvalidSliceSelectorRule.Name, events.Items[0].InvolvedObject.Name)
+				assert.Equal(t, string(ReasonInvalidSliceSelector), events.Items[0].Reason)
+				assert.Equal(t, map[string]string{EventAnnotationRule: taintInvalidSliceSelectorRule.Name}, events.Items[0].Annotations)
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1},
+			},
+		},
+		"no-schedule-time-added-update": {
+			events: []any{
+				[]any{
+					add(slice1),
+					add(taintNoScheduleRule),
+					update(taintNoScheduleRule, taintNoScheduleWithTimeRule),
+				},
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1NoScheduleTainted,
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1},
+				{event: handlerEventUpdate, oldObj: slice1, newObj: slice1NoScheduleTainted},
+			},
+		},
+		"no-execute-time-added-update": {
+			events: []any{
+				[]any{
+					add(slice1),
+					add(taintDevice1Rule),
+					update(taintDevice1Rule, taintNoExecuteWithTimeRule),
+				},
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1TaintedWithTime,
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1},
+				{event: handlerEventUpdate, oldObj: slice1, newObj: slice1Tainted},
+				{event: handlerEventUpdate, oldObj: slice1Tainted, newObj: slice1TaintedWithTime},
+			},
+		},
+		"expired-taint": {
+			events: []any{
+				add(taintExpiredRule),
+				add(slice1),
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1,
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1},
+			},
+		},
+		"unexpired-taint": {
+			events: []any{
+				add(taintUnexpiredRule),
+				add(slice1),
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1Tainted,
+			},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1Tainted},
+			},
+		},
+		"invalid-taint-expiry": {
+			events: []any{
+				add(taintInvalidExpiryRule),
+				add(slice1),
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{
+				slice1,
+			},
+			expectEvents: func(t *assert.CollectT, events *v1.EventList) {
+				if !assert.Len(t, events.Items, 1) {
+					return
+				}
+				assert.Equal(t, taintInvalidExpiryRule.Name, events.Items[0].InvolvedObject.Name)
+				assert.Equal(t, string(ReasonInvalidTaintExpiry), events.Items[0].Reason)
+				assert.Equal(t, map[string]string{EventAnnotationRule: taintInvalidExpiryRule.Name}, events.Items[0].Annotations)
 			},
 			expectedHandlerEvents: []handlerEvent{
 				{event: handlerEventAdd, newObj: slice1},
@@ -565,22 +632,13 @@ func TestListPatchedResourceSlices(t *testing.T) {
 			events: []any{
 				add(deviceClass1),
 				add(
-					taintDeviceClassRule(
-						taintDriverDevicesRule(
-							taintPoolDevicesRule(
-								taintNamedDevicesRule(
-									taintCELSelectedDevicesRule(
-										taintAllDevicesRule,
-										`true`,
-									),
-									device1Name,
-								),
-								pool1,
-							),
-							driver1,
-						),
-						deviceClass1.Name,
-					),
+					taintRule().
+						Selectors(`true`).
+						Device(device1Name).
+						Pool(pool1).
+						Driver(driver1).
+						DeviceClassName(deviceClass1.Name).
+						Obj(),
 				),
 				add(slice1),
 				add(slice2),
@@ -626,11 +684,11 @@ func TestListPatchedResourceSlices(t *testing.T) {
 		informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
 
 		opts := Options{
-			EnableDeviceTaints: true,
-			SliceInformer:      informerFactory.Resource().V1().ResourceSlices(),
-			TaintInformer:      informerFactory.Resource().V1alpha3().DeviceTaintRules(),
-			ClassInformer:      informerFactory.Resource().V1().DeviceClasses(),
-			KubeClient:         kubeClient,
+			Features:      featuregates.Features{DeviceTaints: true},
+			SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+			TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+			ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+			KubeClient:    kubeClient,
 		}
 		tracker, err := newTracker(ctx, opts)
 		require.NoError(t, err)
@@ -793,8 +851,313 @@ func TestListPatchedResourceSlices(t *testing.T) {
 	}
 }
 
+func TestMetrics(t *testing.T) {
+	require.NoError(t, drametrics.RegisterMetrics(drametrics.LegacyRegistry, drametrics.EnableSubsystems(drametrics.SubsystemTracker)))
+	before, err := testutil.GetCounterMetricValue(celRuntimeErrors)
+	require.NoError(t, err)
+
+	fuzzPatchedSlices(t, []any{add(taintNoDevicesCELRuntimeErrorRule), add(slice1)})
+
+	after, err := testutil.GetCounterMetricValue(celRuntimeErrors)
+	require.NoError(t, err)
+	assert.Positive(t, after-before, "CEL runtime errors")
+}
+
+func TestRuleStats(t *testing.T) {
+	require.NoError(t, drametrics.RegisterMetrics(drametrics.LegacyRegistry, drametrics.EnableSubsystems(drametrics.SubsystemTracker)))
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+	tracker, err := newTracker(ctx, Options{
+		Features:      featuregates.Features{DeviceTaints: true},
+		SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+		TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+		ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+	})
+	require.NoError(t, err)
+	defer tracker.Stop()
+	tCtx := &testContext{T: t, Context: ctx, Tracker: tracker, Clientset: kubeClient}
+
+	celRule := builders.MakeDeviceTaintRule("cel-rule", deviceTaint1).Selectors(`true`, `true`).Obj()
+	runInputEvents(tCtx, []any{add(taintDevice1Rule), add(celRule), add(slice1), add(slice2)})
+
+	stats := tracker.RuleStats()
+	require.Len(t, stats, 1, "only rules with CEL selectors")
+	assert.Equal(t, celRule.Name, stats[0].Name)
+	// Two selectors evaluated for two devices.
+	assert.Equal(t, int64(4), stats[0].Evaluations, "evaluations")
+	assert.Positive(t, stats[0].Duration, "duration")
+
+	metricValue, err := testutil.GetCounterMetricValue(celEvaluationDuration.WithLabelValues(celRule.Name))
+	require.NoError(t, err)
+	assert.InDelta(t, stats[0].Duration.Seconds(), metricValue, 1e-9, "metric")
+
+	var buffer bytes.Buffer
+	require.NoError(t, tracker.WriteDebugState(&buffer))
+	assert.Contains(t, buffer.String(), `"name": "cel-rule"`)
+
+	runInputEvents(tCtx, []any{remove(celRule)})
+	assert.Empty(t, tracker.RuleStats(), "stats after removing the rule")
+}
+
+func TestTracing(t *testing.T) {
+	tracerProvider := &tracetesting.TracerProvider{}
+	fuzzPatchedSlices(t, []any{add(taintDevice1Rule), add(slice1), add(slice2)}, func(opts *Options) {
+		opts.TracerProvider = tracerProvider
+	})
+
+	devices := make(map[string][]string)
+	for _, span := range tracerProvider.SpansByName("PatchResourceSlice") {
+		assert.True(t, span.Ended(), "ended")
+		name, _ := span.Attribute(tracing.ResourceSliceKey)
+		tainted, _ := span.Attribute(tracing.DevicesKey)
+		devices[name.AsString()] = tainted.AsStringSlice()
+	}
+	assert.Equal(t, map[string][]string{
+		slice1.Name: {tracing.DeviceID(driver1, pool1, device1Name)},
+		slice2.Name: {},
+	}, devices)
+}
+
+func TestTaintExpiry(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	kubeClient := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+	tracker, err := newTracker(ctx, Options{
+		Features:      featuregates.Features{DeviceTaints: true},
+		SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+		TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+		ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+	})
+	require.NoError(t, err)
+	defer tracker.Stop()
+
+	var mutex sync.Mutex
+	var handlerEvents []handlerEvent
+	_, err = tracker.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			handlerEvents = append(handlerEvents, handlerEvent{event: handlerEventAdd, newObj: obj.(*resourceapi.ResourceSlice)})
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			handlerEvents = append(handlerEvents, handlerEvent{event: handlerEventUpdate, oldObj: oldObj.(*resourceapi.ResourceSlice), newObj: newObj.(*resourceapi.ResourceSlice)})
+		},
+	})
+	require.NoError(t, err)
+
+	// RFC 3339 supports fractional seconds, so the rule can expire soon.
+	rule := taintRule().Annotation(TaintExpiresAnnotation, time.Now().Add(time.Second).Format(time.RFC3339Nano)).Obj()
+	require.NoError(t, tracker.deviceTaints.GetIndexer().Add(rule))
+	tracker.deviceTaintAdd(ctx)(rule)
+	require.NoError(t, tracker.resourceSlices.GetIndexer().Add(slice1))
+	tracker.resourceSliceAdd(ctx)(slice1)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.Equal(t, []handlerEvent{
+			{event: handlerEventAdd, newObj: slice1Tainted},
+			{event: handlerEventUpdate, oldObj: slice1Tainted, newObj: slice1},
+		}, handlerEvents)
+	}, 10*time.Second, 10*time.Millisecond, "taint should get removed once the rule expires")
+}
+
+func TestSnapshot(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	newOptions := func() (Options, informers.SharedInformerFactory) {
+		kubeClient := fake.NewSimpleClientset()
+		informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+		return Options{
+			Features:      featuregates.Features{DeviceTaints: true},
+			SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+			TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+			ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+		}, informerFactory
+	}
+
+	opts, _ := newOptions()
+	tracker, err := newTracker(ctx, opts)
+	require.NoError(t, err)
+	defer tracker.Stop()
+	require.NoError(t, tracker.deviceTaints.GetIndexer().Add(taintDevice1Rule))
+	tracker.deviceTaintAdd(ctx)(taintDevice1Rule)
+	for _, slice := range []*resourceapi.ResourceSlice{slice1, slice2} {
+		require.NoError(t, tracker.resourceSlices.GetIndexer().Add(slice))
+		tracker.resourceSliceAdd(ctx)(slice)
+	}
+	var buffer bytes.Buffer
+	require.NoError(t, tracker.WriteSnapshot(&buffer))
+
+	for name, deviceTaints := range map[string]bool{"with-taints": true, "without-taints": false} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			opts, informerFactory := newOptions()
+			opts.Features.DeviceTaints = deviceTaints
+			opts.Snapshot = bytes.NewReader(buffer.Bytes())
+			tracker, err := StartTracker(ctx, opts)
+			require.NoError(t, err)
+			defer tracker.Stop()
+
+			resourceSlices, err := tracker.ListPatchedResourceSlices()
+			require.NoError(t, err)
+			// Timestamps are compared semantically because JSON decoding uses the local time zone.
+			assert.Empty(t, cmp.Diff([]*resourceapi.ResourceSlice{slice1Tainted, slice2}, resourceSlices), "slices from snapshot before sync (- expected, + actual)")
+
+			informerFactory.Start(ctx.Done())
+			defer func() {
+				cancel()
+				informerFactory.Shutdown()
+			}()
+			require.Eventually(t, tracker.HasSynced, 10*time.Second, 10*time.Millisecond)
+			resourceSlices, err = tracker.ListPatchedResourceSlices()
+			require.NoError(t, err)
+			assert.Empty(t, resourceSlices, "slices after sync")
+		})
+	}
+
+	opts, _ = newOptions()
+	opts.Snapshot = strings.NewReader(`{"version":0}`)
+	_, err = StartTracker(ctx, opts)
+	require.EqualError(t, err, "unsupported snapshot version 0, expected 1")
+}
+
+func TestChanges(t *testing.T) {
+	t.Run("with-taints", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		kubeClient := fake.NewSimpleClientset()
+		informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+		tracker, err := newTracker(ctx, Options{
+			Features:      featuregates.Features{DeviceTaints: true},
+			SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+			TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+			ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+		})
+		require.NoError(t, err)
+		defer tracker.Stop()
+
+		names, revision := tracker.Changes(0)
+		assert.Empty(t, names, "initial changes")
+		assert.Equal(t, uint64(0), revision, "initial revision")
+
+		require.NoError(t, tracker.deviceTaints.GetIndexer().Add(taintDevice1Rule))
+		tracker.deviceTaintAdd(ctx)(taintDevice1Rule)
+		for _, slice := range []*resourceapi.ResourceSlice{slice2, slice1} {
+			require.NoError(t, tracker.resourceSlices.GetIndexer().Add(slice))
+			tracker.resourceSliceAdd(ctx)(slice)
+		}
+		names, revision = tracker.Changes(revision)
+		assert.Equal(t, []string{slice1.Name, slice2.Name}, names, "added slices")
+
+		// Only the tainted slice changes when removing the rule.
+		require.NoError(t, tracker.deviceTaints.GetIndexer().Delete(taintDevice1Rule))
+		tracker.deviceTaintDelete(ctx)(taintDevice1Rule)
+		names, revision = tracker.Changes(revision)
+		assert.Equal(t, []string{slice1.Name}, names, "untainted slice")
+
+		require.NoError(t, tracker.resourceSlices.GetIndexer().Delete(slice2))
+		tracker.resourceSliceDelete(ctx)(slice2)
+		names, revision = tracker.Changes(revision)
+		assert.Equal(t, []string{slice2.Name}, names, "deleted slice")
+
+		names, _ = tracker.Changes(revision)
+		assert.Empty(t, names, "no further changes")
+	})
+
+	t.Run("without-taints", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		ctx, cancel := context.WithCancel(ctx)
+		kubeClient := fake.NewSimpleClientset()
+		informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+		tracker, err := StartTracker(ctx, Options{
+			SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+		})
+		require.NoError(t, err)
+		defer tracker.Stop()
+		informerFactory.Start(ctx.Done())
+		defer func() {
+			cancel()
+			informerFactory.Shutdown()
+		}()
+
+		_, err = kubeClient.ResourceV1().ResourceSlices().Create(ctx, slice1, metav1.CreateOptions{})
+		require.NoError(t, err)
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			names, revision := tracker.Changes(0)
+			assert.Equal(t, []string{slice1.Name}, names, "added slice")
+			assert.Equal(t, uint64(1), revision, "revision")
+		}, 10*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestMaxDevicesPerRule(t *testing.T) {
+	for name, maxDevices := range map[string]intstr.IntOrString{
+		"absolute":   intstr.FromInt32(1),
+		"percentage": intstr.FromString("50%"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			kubeClient := fake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+			tracker, err := newTracker(ctx, Options{
+				Features:          featuregates.Features{DeviceTaints: true},
+				SliceInformer:     informerFactory.Resource().V1().ResourceSlices(),
+				TaintInformer:     informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+				ClassInformer:     informerFactory.Resource().V1().DeviceClasses(),
+				KubeClient:        kubeClient,
+				MaxDevicesPerRule: &maxDevices,
+			})
+			require.NoError(t, err)
+			defer tracker.Stop()
+			tCtx := &testContext{T: t, Context: ctx, Tracker: tracker, Clientset: kubeClient}
+
+			expectSlices := func(what string, expected ...*resourceapi.ResourceSlice) {
+				t.Helper()
+				actual, err := tracker.ListPatchedResourceSlices()
+				require.NoError(t, err)
+				slices.SortFunc(actual, func(a, b *resourceapi.ResourceSlice) int { return stdcmp.Compare(a.Name, b.Name) })
+				assert.Equal(t, expected, actual, what)
+			}
+
+			runInputEvents(tCtx, []any{add(taintAllDevicesRule), add(slice1), add(slice2)})
+			expectSlices("two devices match", slice1, slice2)
+			assert.EventuallyWithT(t, func(t *assert.CollectT) {
+				events, err := kubeClient.CoreV1().Events("").List(ctx, metav1.ListOptions{})
+				require.NoError(t, err, "list events")
+				if assert.Len(t, events.Items, 1) {
+					assert.Equal(t, taintAllDevicesRule.Name, events.Items[0].InvolvedObject.Name)
+					assert.Equal(t, string(ReasonTooManyDevices), events.Items[0].Reason)
+					assert.Equal(t, "2", events.Items[0].Annotations[EventAnnotationAffectedDevices])
+				}
+			}, 10*time.Second, 10*time.Millisecond)
+
+			// Without slice1, the rule matches only one device. The percentage
+			// then refers to fewer devices, but still allows one.
+			runInputEvents(tCtx, []any{remove(slice1)})
+			expectSlices("one device matches", slice2Tainted)
+		})
+	}
+
+	_, ctx := ktesting.NewTestContext(t)
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(fake.NewSimpleClientset(), 10*time.Minute)
+	maxDevices := intstr.FromString("all")
+	_, err := newTracker(ctx, Options{
+		Features:          featuregates.Features{DeviceTaints: true},
+		SliceInformer:     informerFactory.Resource().V1().ResourceSlices(),
+		TaintInformer:     informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+		ClassInformer:     informerFactory.Resource().V1().DeviceClasses(),
+		MaxDevicesPerRule: &maxDevices,
+	})
+	require.ErrorContains(t, err, "invalid maximum number of devices per rule")
+}
+
 func BenchmarkEventHandlers(b *testing.B) {
 	now := time.Now()
+	taint := builders.MakeDeviceTaint("example.com/taint").Value("tainted").Effect(resourceapi.DeviceTaintEffectNoExecute).TimeAdded(&metav1.Time{Time: now}).Obj()
 	benchmarks := map[string]struct {
 		resourceSlices []*resourceapi.ResourceSlice
 		taintRules     []*resourcealphaapi.DeviceTaintRule
@@ -804,14 +1167,7 @@ func BenchmarkEventHandlers(b *testing.B) {
 			resourceSlices: func() []*resourceapi.ResourceSlice {
 				resourceSlices := make([]*resourceapi.ResourceSlice, 1000)
 				for i := range resourceSlices {
-					resourceSlices[i] = &resourceapi.ResourceSlice{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "slice-" + strconv.Itoa(i),
-						},
-						Spec: resourceapi.ResourceSliceSpec{
-							Devices: slices.Repeat([]resourceapi.Device{}, 64),
-						},
-					}
+					resourceSlices[i] = builders.MakeResourceSlice("slice-" + strconv.Itoa(i)).Obj()
 				}
 				return resourceSlices
 			}(),
@@ -823,32 +1179,12 @@ func BenchmarkEventHandlers(b *testing.B) {
 			resourceSlices: func() []*resourceapi.ResourceSlice {
 				resourceSlices := make([]*resourceapi.ResourceSlice, 500)
 				for i := range resourceSlices {
-					resourceSlices[i] = &resourceapi.ResourceSlice{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "slice-" + strconv.Itoa(i),
-						},
-						Spec: resourceapi.ResourceSliceSpec{
-							Devices: slices.Repeat([]resourceapi.Device{{}}, 64),
-						},
-					}
+					resourceSlices[i] = builders.MakeResourceSlice("slice-" + strconv.Itoa(i)).Devices(slices.Repeat([]resourceapi.Device{{}}, 64)...).Obj()
 				}
 				return resourceSlices
 			}(),
 			taintRules: []*resourcealphaapi.DeviceTaintRule{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "taintRule",
-					},
-					Spec: resourcealphaapi.DeviceTaintRuleSpec{
-						DeviceSelector: nil, // all slices
-						Taint: resourcealphaapi.DeviceTaint{
-							Key:       "example.com/taint",
-							Value:     "tainted",
-							Effect:    resourcealphaapi.DeviceTaintEffectNoExecute,
-							TimeAdded: &metav1.Time{Time: now},
-						},
-					},
-				},
+				builders.MakeDeviceTaintRule("taintRule", taint).Obj(), // all slices
 			},
 			loop: func(ctx context.Context, b *testing.B, tracker *Tracker, resourceSlices []*resourceapi.ResourceSlice, taintRules []*resourcealphaapi.DeviceTaintRule, i int) {
 				tracker.deviceTaintAdd(ctx)(taintRules[i%len(taintRules)])
@@ -858,32 +1194,12 @@ func BenchmarkEventHandlers(b *testing.B) {
 			resourceSlices: func() []*resourceapi.ResourceSlice {
 				resourceSlices := make([]*resourceapi.ResourceSlice, 500)
 				for i := range resourceSlices {
-					resourceSlices[i] = &resourceapi.ResourceSlice{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "slice-" + strconv.Itoa(i),
-						},
-						Spec: resourceapi.ResourceSliceSpec{
-							Devices: slices.Repeat([]resourceapi.Device{{}}, 64),
-						},
-					}
+					resourceSlices[i] = builders.MakeResourceSlice("slice-" + strconv.Itoa(i)).Devices(slices.Repeat([]resourceapi.Device{{}}, 64)...).Obj()
 				}
 				return resourceSlices
 			}(),
 			taintRules: []*resourcealphaapi.DeviceTaintRule{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "taintRule",
-					},
-					Spec: resourcealphaapi.DeviceTaintRuleSpec{
-						DeviceSelector: nil, // all slices
-						Taint: resourcealphaapi.DeviceTaint{
-							Key:       "example.com/taint",
-							Value:     "tainted",
-							Effect:    resourcealphaapi.DeviceTaintEffectNoExecute,
-							TimeAdded: &metav1.Time{Time: now},
-						},
-					},
-				},
+				builders.MakeDeviceTaintRule("taintRule", taint).Obj(), // all slices
 			},
 			loop: func(ctx context.Context, b *testing.B, tracker *Tracker, resourceSlices []*resourceapi.ResourceSlice, _ []*resourcealphaapi.DeviceTaintRule, i int) {
 				tracker.resourceSliceAdd(ctx)(resourceSlices[i%len(resourceSlices)])
@@ -895,46 +1211,17 @@ func BenchmarkEventHandlers(b *testing.B) {
 				nDevices := 64
 				resourceSlices := make([]*resourceapi.ResourceSlice, nSlices)
 				for i := range resourceSlices {
-					resourceSlices[i] = &resourceapi.ResourceSlice{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "slice-" + strconv.Itoa(i),
-						},
-						Spec: resourceapi.ResourceSliceSpec{
-							Pool: resourceapi.ResourcePool{
-								Name: "pool-" + strconv.Itoa(i),
-							},
-							Devices: func() []resourceapi.Device {
-								devices := make([]resourceapi.Device, nDevices)
-								for j := range devices {
-									devices[j] = resourceapi.Device{
-										Name: "device-" + strconv.Itoa(j),
-									}
-								}
-								return devices
-							}(),
-						},
+					devices := make([]resourceapi.Device, nDevices)
+					for j := range devices {
+						devices[j] = builders.MakeDevice("device-" + strconv.Itoa(j)).Obj()
 					}
+					resourceSlices[i] = builders.MakeResourceSlice("slice-" + strconv.Itoa(i)).Pool("pool-" + strconv.Itoa(i)).Devices(devices...).Obj()
 				}
 				resourceSlices[nSlices/2].Spec.Devices[nDevices/2].Name = "patchme"
 				return resourceSlices
 			}(),
 			taintRules: []*resourcealphaapi.DeviceTaintRule{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "taintRule",
-					},
-					Spec: resourcealphaapi.DeviceTaintRuleSpec{
-						DeviceSelector: &resourcealphaapi.DeviceTaintSelector{
-							Device: ptr.To("patchme"),
-						},
-						Taint: resourcealphaapi.DeviceTaint{
-							Key:       "example.com/taint",
-							Value:     "tainted",
-							Effect:    resourcealphaapi.DeviceTaintEffectNoExecute,
-							TimeAdded: &metav1.Time{Time: now},
-						},
-					},
-				},
+				builders.MakeDeviceTaintRule("taintRule", taint).Device("patchme").Obj(),
 			},
 			loop: func(ctx context.Context, b *testing.B, tracker *Tracker, resourceSlices []*resourceapi.ResourceSlice, taintRules []*resourcealphaapi.DeviceTaintRule, i int) {
 				tracker.deviceTaintAdd(ctx)(taintRules[i%len(taintRules)])
@@ -944,43 +1231,15 @@ func BenchmarkEventHandlers(b *testing.B) {
 			resourceSlices: func() []*resourceapi.ResourceSlice {
 				resourceSlices := make([]*resourceapi.ResourceSlice, 500)
 				for i := range resourceSlices {
-					resourceSlices[i] = &resourceapi.ResourceSlice{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "slice-" + strconv.Itoa(i),
-						},
-						Spec: resourceapi.ResourceSliceSpec{
-							Pool: resourceapi.ResourcePool{
-								Name: "pool-" + strconv.Itoa(i),
-							},
-							Devices: func() []resourceapi.Device {
-								nDevices := 64
-								devices := slices.Repeat([]resourceapi.Device{{}}, nDevices)
-								devices[nDevices/2].Name = "patchme"
-								return devices
-							}(),
-						},
-					}
+					nDevices := 64
+					devices := slices.Repeat([]resourceapi.Device{{}}, nDevices)
+					devices[nDevices/2].Name = "patchme"
+					resourceSlices[i] = builders.MakeResourceSlice("slice-" + strconv.Itoa(i)).Pool("pool-" + strconv.Itoa(i)).Devices(devices...).Obj()
 				}
 				return resourceSlices
 			}(),
 			taintRules: []*resourcealphaapi.DeviceTaintRule{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "patch",
-					},
-					Spec: resourcealphaapi.DeviceTaintRuleSpec{
-						DeviceSelector: &resourcealphaapi.DeviceTaintSelector{
-							Pool:   ptr.To("pool-250"),
-							Device: ptr.To("patchme"),
-						},
-						Taint: resourcealphaapi.DeviceTaint{
-							Key:       "example.com/taint",
-							Value:     "tainted",
-							Effect:    resourcealphaapi.DeviceTaintEffectNoExecute,
-							TimeAdded: &metav1.Time{Time: now},
-						},
-					},
-				},
+				builders.MakeDeviceTaintRule("patch", taint).Pool("pool-250").Device("patchme").Obj(),
 			},
 			loop: func(ctx context.Context, b *testing.B, tracker *Tracker, resourceSlices []*resourceapi.ResourceSlice, patches []*resourcealphaapi.DeviceTaintRule, i int) {
 				tracker.resourceSliceAdd(ctx)(resourceSlices[250]) // the slice affected by the patch
@@ -990,39 +1249,14 @@ func BenchmarkEventHandlers(b *testing.B) {
 			resourceSlices: func() []*resourceapi.ResourceSlice {
 				resourceSlices := make([]*resourceapi.ResourceSlice, 500)
 				for i := range resourceSlices {
-					resourceSlices[i] = &resourceapi.ResourceSlice{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "slice-" + strconv.Itoa(i),
-						},
-						Spec: resourceapi.ResourceSliceSpec{
-							Pool: resourceapi.ResourcePool{
-								Name: "pool-" + strconv.Itoa(i),
-							},
-							Devices: slices.Repeat([]resourceapi.Device{{}}, 64),
-						},
-					}
+					resourceSlices[i] = builders.MakeResourceSlice("slice-" + strconv.Itoa(i)).Pool("pool-" + strconv.Itoa(i)).Devices(slices.Repeat([]resourceapi.Device{{}}, 64)...).Obj()
 				}
 				return resourceSlices
 			}(),
 			taintRules: func() []*resourcealphaapi.DeviceTaintRule {
 				patches := make([]*resourcealphaapi.DeviceTaintRule, 500)
 				for i := range patches {
-					patches[i] = &resourcealphaapi.DeviceTaintRule{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "taint-rule-" + strconv.Itoa(i),
-						},
-						Spec: resourcealphaapi.DeviceTaintRuleSpec{
-							DeviceSelector: &resourcealphaapi.DeviceTaintSelector{
-								Pool: ptr.To("pool-" + strconv.Itoa(i)),
-							},
-							Taint: resourcealphaapi.DeviceTaint{
-								Key:       "example.com/taint",
-								Value:     "tainted",
-								Effect:    resourcealphaapi.DeviceTaintEffectNoExecute,
-								TimeAdded: &metav1.Time{Time: now},
-							},
-						},
-					}
+					patches[i] = builders.MakeDeviceTaintRule("taint-rule-"+strconv.Itoa(i), taint).Pool("pool-" + strconv.Itoa(i)).Obj()
 				}
 				return patches
 			}(),
@@ -1036,11 +1270,11 @@ func BenchmarkEventHandlers(b *testing.B) {
 		kubeClient := fake.NewSimpleClientset()
 		informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
 		opts := Options{
-			EnableDeviceTaints: true,
-			SliceInformer:      informerFactory.Resource().V1().ResourceSlices(),
-			TaintInformer:      informerFactory.Resource().V1alpha3().DeviceTaintRules(),
-			ClassInformer:      informerFactory.Resource().V1().DeviceClasses(),
-			KubeClient:         kubeClient,
+			Features:      featuregates.Features{DeviceTaints: true},
+			SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+			TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+			ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+			KubeClient:    kubeClient,
 		}
 		tracker, err := newTracker(ctx, opts)
 		require.NoError(b, err)
@@ -0,0 +1,269 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trackertesting provides a fake implementation of
+// [tracker.Interface] for unit tests. It gets populated directly with
+// ResourceSlices, DeviceTaintRules and DeviceClasses instead of through
+// informers.
+package trackertesting
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/dynamic-resource-allocation/api/convert"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/resourceslice/tracker"
+)
+
+// Tracker is a fake [tracker.Interface]. It applies DeviceTaintRules like
+// the real tracker with device taints enabled, including their CEL
+// selectors and the selectors of the referenced DeviceClass. A selector
+// which cannot be evaluated does not match. Expired rules are ignored,
+// but unlike the real tracker, the fake does not emit updates when a
+// rule expires.
+//
+// Events are delivered synchronously to all event handlers before
+// the method which caused them returns. A Tracker is thread-safe, but
+// event handlers must not modify it.
+type Tracker struct {
+	celCache *cel.Cache
+
+	// deliverMutex ensures that events get delivered in order.
+	deliverMutex sync.Mutex
+
+	mutex      sync.Mutex
+	slices     map[string]*resourceapi.ResourceSlice
+	taintRules map[string]*resourcealphaapi.DeviceTaintRule
+	classes    map[string]*resourceapi.DeviceClass
+	patched    map[string]*resourceapi.ResourceSlice
+	handlers   []cache.ResourceEventHandler
+}
+
+var _ tracker.Interface = &Tracker{}
+
+// New creates a fake tracker with the given slices.
+func New(slices ...*resourceapi.ResourceSlice) *Tracker {
+	t := &Tracker{
+		celCache:   cel.NewCache(10, cel.Features{EnableConsumableCapacity: true}),
+		slices:     make(map[string]*resourceapi.ResourceSlice),
+		taintRules: make(map[string]*resourcealphaapi.DeviceTaintRule),
+		classes:    make(map[string]*resourceapi.DeviceClass),
+		patched:    make(map[string]*resourceapi.ResourceSlice),
+	}
+	for _, slice := range slices {
+		t.slices[slice.Name] = slice
+	}
+	t.repatch()
+	return t
+}
+
+// HasSynced always returns true.
+func (t *Tracker) HasSynced() bool {
+	return true
+}
+
+// ListPatchedResourceSlices returns the slices with taints from
+// matching DeviceTaintRules, sorted by name.
+func (t *Tracker) ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	result := make([]*resourceapi.ResourceSlice, 0, len(t.patched))
+	for _, name := range sortedKeys(t.patched) {
+		result = append(result, t.patched[name])
+	}
+	return result, nil
+}
+
+// AddEventHandler delivers Add events for all current slices and then
+// future changes.
+func (t *Tracker) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	t.deliverMutex.Lock()
+	defer t.deliverMutex.Unlock()
+	t.mutex.Lock()
+	t.handlers = append(t.handlers, handler)
+	var initial []*resourceapi.ResourceSlice
+	for _, name := range sortedKeys(t.patched) {
+		initial = append(initial, t.patched[name])
+	}
+	t.mutex.Unlock()
+	for _, slice := range initial {
+		handler.OnAdd(slice, true)
+	}
+	return t, nil
+}
+
+// AddSlice adds or replaces a ResourceSlice.
+func (t *Tracker) AddSlice(slice *resourceapi.ResourceSlice) {
+	t.modify(func() { t.slices[slice.Name] = slice })
+}
+
+// DeleteSlice removes a ResourceSlice.
+func (t *Tracker) DeleteSlice(name string) {
+	t.modify(func() { delete(t.slices, name) })
+}
+
+// AddTaintRule adds or replaces a DeviceTaintRule.
+func (t *Tracker) AddTaintRule(rule *resourcealphaapi.DeviceTaintRule) {
+	t.modify(func() { t.taintRules[rule.Name] = rule })
+}
+
+// DeleteTaintRule removes a DeviceTaintRule.
+func (t *Tracker) DeleteTaintRule(name string) {
+	t.modify(func() { delete(t.taintRules, name) })
+}
+
+// AddClass adds or replaces a DeviceClass. Classes are only
+// used for DeviceTaintRules which reference them.
+func (t *Tracker) AddClass(class *resourceapi.DeviceClass) {
+	t.modify(func() { t.classes[class.Name] = class })
+}
+
+// DeleteClass removes a DeviceClass.
+func (t *Tracker) DeleteClass(name string) {
+	t.modify(func() { delete(t.classes, name) })
+}
+
+type event struct {
+	oldObj, newObj *resourceapi.ResourceSlice
+}
+
+// modify applies the change and delivers events for all patched
+// slices which changed as a result.
+func (t *Tracker) modify(change func()) {
+	t.deliverMutex.Lock()
+	defer t.deliverMutex.Unlock()
+
+	t.mutex.Lock()
+	old := t.patched
+	change()
+	t.repatch()
+	var events []event
+	for _, name := range sortedKeys(old) {
+		if _, ok := t.patched[name]; !ok {
+			events = append(events, event{oldObj: old[name]})
+		}
+	}
+	for _, name := range sortedKeys(t.patched) {
+		oldObj := old[name]
+		newObj := t.patched[name]
+		if oldObj != nil && apiequality.Semantic.DeepEqual(oldObj, newObj) {
+			// Keep the old object, nothing changed.
+			t.patched[name] = oldObj
+			continue
+		}
+		events = append(events, event{oldObj: oldObj, newObj: newObj})
+	}
+	handlers := slices.Clone(t.handlers)
+	t.mutex.Unlock()
+
+	for _, e := range events {
+		for _, handler := range handlers {
+			switch {
+			case e.oldObj == nil:
+				handler.OnAdd(e.newObj, false)
+			case e.newObj == nil:
+				handler.OnDelete(e.oldObj)
+			default:
+				handler.OnUpdate(e.oldObj, e.newObj)
+			}
+		}
+	}
+}
+
+// repatch recomputes all patched slices. The mutex must be locked.
+func (t *Tracker) repatch() {
+	t.patched = make(map[string]*resourceapi.ResourceSlice, len(t.slices))
+	rules := make([]*resourcealphaapi.DeviceTaintRule, 0, len(t.taintRules))
+	for _, name := range sortedKeys(t.taintRules) {
+		rules = append(rules, t.taintRules[name])
+	}
+	for name, slice := range t.slices {
+		patched := slice
+		for i := range slice.Spec.Devices {
+			for _, rule := range rules {
+				if !t.matches(rule, slice, &slice.Spec.Devices[i]) {
+					continue
+				}
+				if patched == slice {
+					patched = slice.DeepCopy()
+				}
+				patched.Spec.Devices[i].Taints = append(patched.Spec.Devices[i].Taints, convert.DeviceTaintFromV1Alpha3(rule.Spec.Taint))
+			}
+		}
+		t.patched[name] = patched
+	}
+}
+
+func (t *Tracker) matches(rule *resourcealphaapi.DeviceTaintRule, slice *resourceapi.ResourceSlice, device *resourceapi.Device) bool {
+	sliceSelector, err := tracker.SliceSelector(rule)
+	if err != nil || !sliceSelector.Matches(labels.Set(slice.Labels)) {
+		return false
+	}
+	expires, err := tracker.TaintExpiry(rule)
+	if err != nil || !expires.IsZero() && !expires.After(time.Now()) {
+		return false
+	}
+	selector := rule.Spec.DeviceSelector
+	if selector == nil {
+		return true
+	}
+	if selector.Driver != nil && *selector.Driver != slice.Spec.Driver ||
+		selector.Pool != nil && *selector.Pool != slice.Spec.Pool.Name ||
+		selector.Device != nil && *selector.Device != device.Name {
+		return false
+	}
+	var expressions []string
+	if selector.DeviceClassName != nil {
+		class := t.classes[*selector.DeviceClassName]
+		if class == nil {
+			return false
+		}
+		for _, s := range class.Spec.Selectors {
+			if s.CEL != nil {
+				expressions = append(expressions, s.CEL.Expression)
+			}
+		}
+	}
+	for _, s := range selector.Selectors {
+		if s.CEL != nil {
+			expressions = append(expressions, s.CEL.Expression)
+		}
+	}
+	for _, expression := range expressions {
+		expr := t.celCache.GetOrCompile(expression)
+		if expr.Error != nil {
+			return false
+		}
+		matches, _, err := expr.DeviceMatches(context.Background(), cel.Device{Driver: slice.Spec.Driver, Attributes: device.Attributes, Capacity: device.Capacity, Taints: device.Taints})
+		if err != nil || !matches {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	return slices.Sorted(maps.Keys(m))
+}
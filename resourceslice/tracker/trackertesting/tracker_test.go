@@ -0,0 +1,133 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trackertesting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { r.events = append(r.events, "add "+obj.(*resourceapi.ResourceSlice).Name) },
+		UpdateFunc: func(_, obj any) { r.events = append(r.events, "update "+obj.(*resourceapi.ResourceSlice).Name) },
+		DeleteFunc: func(obj any) { r.events = append(r.events, "delete "+obj.(*resourceapi.ResourceSlice).Name) },
+	}
+}
+
+func (r *recorder) take() []string {
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func TestTracker(t *testing.T) {
+	slice := func(name, driver string, devices ...resourceapi.Device) *resourceapi.ResourceSlice {
+		return &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   driver,
+				Pool:     resourceapi.ResourcePool{Name: "pool"},
+				AllNodes: ptr.To(true),
+				Devices:  devices,
+			},
+		}
+	}
+	gpu := func(name, model string) resourceapi.Device {
+		return resourceapi.Device{
+			Name:       name,
+			Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": {StringValue: ptr.To(model)}},
+		}
+	}
+	taint := resourcealphaapi.DeviceTaint{Key: "example.com/broken", Effect: resourcealphaapi.DeviceTaintEffectNoSchedule}
+	taintsOf := func(t *testing.T, tracker *Tracker, sliceName string) [][]resourceapi.DeviceTaint {
+		slices, err := tracker.ListPatchedResourceSlices()
+		require.NoError(t, err)
+		for _, slice := range slices {
+			if slice.Name == sliceName {
+				var taints [][]resourceapi.DeviceTaint
+				for _, device := range slice.Spec.Devices {
+					taints = append(taints, device.Taints)
+				}
+				return taints
+			}
+		}
+		t.Fatalf("slice %s not found", sliceName)
+		return nil
+	}
+	expectedTaint := []resourceapi.DeviceTaint{{Key: "example.com/broken", Effect: resourceapi.DeviceTaintEffectNoSchedule}}
+
+	tracker := New(slice("gpus", "gpu.example.com", gpu("gpu-0", "a100"), gpu("gpu-1", "h100")))
+	assert.True(t, tracker.HasSynced())
+
+	var r recorder
+	registration, err := tracker.AddEventHandler(r.handler())
+	require.NoError(t, err)
+	assert.True(t, registration.HasSynced())
+	assert.Equal(t, []string{"add gpus"}, r.take())
+
+	tracker.AddSlice(slice("nics", "nic.example.com", resourceapi.Device{Name: "eth0"}))
+	assert.Equal(t, []string{"add nics"}, r.take())
+
+	// Only affects the GPUs, so only that slice gets updated.
+	tracker.AddTaintRule(&resourcealphaapi.DeviceTaintRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "driver"},
+		Spec: resourcealphaapi.DeviceTaintRuleSpec{
+			DeviceSelector: &resourcealphaapi.DeviceTaintSelector{Driver: ptr.To("gpu.example.com"), Device: ptr.To("gpu-0")},
+			Taint:          taint,
+		},
+	})
+	assert.Equal(t, []string{"update gpus"}, r.take())
+	assert.Equal(t, [][]resourceapi.DeviceTaint{expectedTaint, nil}, taintsOf(t, tracker, "gpus"))
+
+	tracker.AddClass(&resourceapi.DeviceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "h100"},
+		Spec: resourceapi.DeviceClassSpec{
+			Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `device.attributes["gpu.example.com"].model == "h100"`}}},
+		},
+	})
+	assert.Empty(t, r.take(), "class alone changes nothing")
+	tracker.AddTaintRule(&resourcealphaapi.DeviceTaintRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "class"},
+		Spec: resourcealphaapi.DeviceTaintRuleSpec{
+			DeviceSelector: &resourcealphaapi.DeviceTaintSelector{DeviceClassName: ptr.To("h100")},
+			Taint:          taint,
+		},
+	})
+	assert.Equal(t, []string{"update gpus"}, r.take())
+	assert.Equal(t, [][]resourceapi.DeviceTaint{expectedTaint, expectedTaint}, taintsOf(t, tracker, "gpus"))
+
+	tracker.DeleteTaintRule("driver")
+	tracker.DeleteClass("h100")
+	assert.Equal(t, []string{"update gpus", "update gpus"}, r.take())
+	assert.Equal(t, [][]resourceapi.DeviceTaint{nil, nil}, taintsOf(t, tracker, "gpus"))
+
+	tracker.DeleteSlice("nics")
+	assert.Equal(t, []string{"delete nics"}, r.take())
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"slices"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ViewOptions select the ResourceSlices which are visible in a [View].
+// A slice must match all criteria which are set.
+type ViewOptions struct {
+	// Drivers limits the view to slices of these drivers.
+	Drivers []string
+
+	// Selector limits the view to slices with matching labels.
+	Selector labels.Selector
+
+	// Filter is called for each patched slice. It must return true
+	// for slices which are part of the view.
+	Filter func(slice *resourceapi.ResourceSlice) bool
+}
+
+// View is a subset of the patched ResourceSlices of a [Tracker].
+// Different consumers, like scheduler profiles or tenants, can each have
+// their own view with its own event handlers while sharing the
+// informers and patching of one tracker.
+type View struct {
+	tracker *Tracker
+	opts    ViewOptions
+}
+
+var _ Interface = &View{}
+
+// NewView creates a view. It is cheap and does not need to be stopped.
+func (t *Tracker) NewView(opts ViewOptions) *View {
+	return &View{tracker: t, opts: opts}
+}
+
+func (v *View) matches(obj any) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	slice, ok := obj.(*resourceapi.ResourceSlice)
+	if !ok {
+		return false
+	}
+	if v.opts.Drivers != nil && !slices.Contains(v.opts.Drivers, slice.Spec.Driver) {
+		return false
+	}
+	if v.opts.Selector != nil && !v.opts.Selector.Matches(labels.Set(slice.Labels)) {
+		return false
+	}
+	if v.opts.Filter != nil && !v.opts.Filter(slice) {
+		return false
+	}
+	return true
+}
+
+// HasSynced is the same as [Tracker.HasSynced].
+func (v *View) HasSynced() bool {
+	return v.tracker.HasSynced()
+}
+
+// ListPatchedResourceSlices returns those slices from
+// [Tracker.ListPatchedResourceSlices] which are part of the view.
+func (v *View) ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error) {
+	resourceSlices, err := v.tracker.ListPatchedResourceSlices()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(resourceSlices, func(slice *resourceapi.ResourceSlice) bool {
+		return !v.matches(slice)
+	}), nil
+}
+
+// AddEventHandler adds an event handler which only gets called for
+// slices that are part of the view. When a slice enters or leaves the
+// view because of an update, the handler gets an add or delete event.
+// Otherwise it is the same as [Tracker.AddEventHandler].
+func (v *View) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	return v.tracker.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: v.matches,
+		Handler:    handler,
+	})
+}
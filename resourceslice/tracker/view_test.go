@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	stdcmp "cmp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestView(t *testing.T) {
+	testcases := map[string]struct {
+		opts                  ViewOptions
+		expectedPatchedSlices []*resourceapi.ResourceSlice
+		expectedHandlerEvents []handlerEvent
+	}{
+		"everything": {
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{slice1RackA1Tainted, slice2RackA1},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1RackA1Tainted},
+				{event: handlerEventAdd, newObj: slice2RackB2},
+				{event: handlerEventUpdate, oldObj: slice2RackB2, newObj: slice2RackA1},
+			},
+		},
+		"driver": {
+			opts:                  ViewOptions{Drivers: []string{driver2}},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{slice2RackA1},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice2RackB2},
+				{event: handlerEventUpdate, oldObj: slice2RackB2, newObj: slice2RackA1},
+			},
+		},
+		"selector": {
+			opts:                  ViewOptions{Selector: labels.SelectorFromSet(labels.Set{"example.com/rack": "a1"})},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{slice1RackA1Tainted, slice2RackA1},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1RackA1Tainted},
+				// Enters the view.
+				{event: handlerEventAdd, newObj: slice2RackA1},
+			},
+		},
+		"filter": {
+			opts: ViewOptions{Filter: func(slice *resourceapi.ResourceSlice) bool {
+				return slice.Labels["example.com/rack"] == "b2"
+			}},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice2RackB2},
+				// Leaves the view.
+				{event: handlerEventDelete, oldObj: slice2RackB2},
+			},
+		},
+		"all-criteria": {
+			opts: ViewOptions{
+				Drivers:  []string{driver1, driver2},
+				Selector: labels.SelectorFromSet(labels.Set{"example.com/rack": "a1"}),
+				Filter: func(slice *resourceapi.ResourceSlice) bool {
+					return slice.Spec.Driver == driver1
+				},
+			},
+			expectedPatchedSlices: []*resourceapi.ResourceSlice{slice1RackA1Tainted},
+			expectedHandlerEvents: []handlerEvent{
+				{event: handlerEventAdd, newObj: slice1RackA1Tainted},
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			kubeClient := fake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 10*time.Minute)
+			tracker, err := newTracker(ctx, Options{
+				Features:      featuregates.Features{DeviceTaints: true},
+				SliceInformer: informerFactory.Resource().V1().ResourceSlices(),
+				TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+				ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+			})
+			require.NoError(t, err)
+			defer tracker.Stop()
+			tCtx := &testContext{T: t, Context: ctx, Tracker: tracker, Clientset: kubeClient}
+
+			view := tracker.NewView(tc.opts)
+			var handlerEvents []handlerEvent
+			_, err = view.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj any) {
+					handlerEvents = append(handlerEvents, handlerEvent{event: handlerEventAdd, newObj: obj.(*resourceapi.ResourceSlice)})
+				},
+				UpdateFunc: func(oldObj, newObj any) {
+					handlerEvents = append(handlerEvents, handlerEvent{event: handlerEventUpdate, oldObj: oldObj.(*resourceapi.ResourceSlice), newObj: newObj.(*resourceapi.ResourceSlice)})
+				},
+				DeleteFunc: func(obj any) {
+					handlerEvents = append(handlerEvents, handlerEvent{event: handlerEventDelete, oldObj: obj.(*resourceapi.ResourceSlice)})
+				},
+			})
+			require.NoError(t, err)
+
+			runInputEvents(tCtx, []any{add(taintDevice1Rule), add(slice1RackA1), add(slice2RackB2), update(slice2RackB2, slice2RackA1)})
+
+			assert.Equal(t, tc.expectedHandlerEvents, handlerEvents, "handler events")
+			patchedSlices, err := view.ListPatchedResourceSlices()
+			require.NoError(t, err)
+			slices.SortFunc(patchedSlices, func(a, b *resourceapi.ResourceSlice) int { return stdcmp.Compare(a.Name, b.Name) })
+			assert.Equal(t, tc.expectedPatchedSlices, patchedSlices, "patched slices")
+		})
+	}
+}
@@ -26,6 +26,23 @@ import (
 	resourcev1 "k8s.io/api/resource/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceTemplate) DeepCopyInto(out *DeviceTemplate) {
+	*out = *in
+	in.Device.DeepCopyInto(&out.Device)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceTemplate.
+func (in *DeviceTemplate) DeepCopy() *DeviceTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriverResources) DeepCopyInto(out *DriverResources) {
 	*out = *in
@@ -115,6 +132,18 @@ func (in *Slice) DeepCopyInto(out *Slice) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeviceTemplates != nil {
+		in, out := &in.DeviceTemplates, &out.DeviceTemplates
+		*out = make([]DeviceTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
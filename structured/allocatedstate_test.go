@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestGatherAllocatedStateConsumableCapacity(t *testing.T) {
+	const (
+		driver = "dra.example.com"
+		pool   = "pool"
+	)
+	shareID := types.UID("share-1")
+	existing := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"},
+		Status: resourceapi.ResourceClaimStatus{
+			Allocation: &resourceapi.AllocationResult{
+				Devices: resourceapi.DeviceAllocationResult{
+					Results: []resourceapi.DeviceRequestAllocationResult{
+						{Request: "req", Driver: driver, Pool: pool, Device: "vgpu", ShareID: &shareID, ConsumedCapacity: map[resourceapi.QualifiedName]resource.Quantity{"memory": resource.MustParse("10Gi")}},
+						{Request: "req", Driver: driver, Pool: pool, Device: "gpu"},
+						// Admin access does not make the device unavailable.
+						{Request: "admin", Driver: driver, Pool: pool, Device: "other-gpu", AdminAccess: ptr.To(true)},
+					},
+				},
+			},
+		},
+	}
+	unallocated := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Name: "unallocated", Namespace: "default"}}
+
+	state := GatherAllocatedState([]*resourceapi.ResourceClaim{existing, unallocated})
+	vgpu := MakeDeviceID(driver, pool, "vgpu")
+	assert.ElementsMatch(t, []DeviceID{MakeDeviceID(driver, pool, "gpu")}, state.AllocatedDevices.UnsortedList())
+	assert.ElementsMatch(t, []SharedDeviceID{MakeSharedDeviceID(vgpu, &shareID)}, state.AllocatedSharedDeviceIDs.UnsortedList())
+	require.Contains(t, state.AggregatedCapacity, vgpu)
+	assert.Equal(t, "10Gi", state.AggregatedCapacity[vgpu]["memory"].String())
+
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driver,
+			Pool:     resourceapi.ResourcePool{Name: pool, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			Devices: []resourceapi.Device{
+				{Name: "gpu"},
+				{
+					Name:                     "vgpu",
+					AllowMultipleAllocations: ptr.To(true),
+					Capacity:                 map[resourceapi.QualifiedName]resourceapi.DeviceCapacity{"memory": {Value: resource.MustParse("16Gi")}},
+				},
+			},
+		},
+	}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	claim := func(memory string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           1,
+							Capacity: &resourceapi.CapacityRequirements{
+								Requests: map[resourceapi.QualifiedName]resource.Quantity{"memory": resource.MustParse(memory)},
+							},
+						},
+					}},
+				},
+			},
+		}
+	}
+	_, ctx := ktesting.NewTestContext(t)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	allocator, err := NewAllocator(ctx, internal.FeaturesAll, state, deviceClasses{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{EnableConsumableCapacity: true}))
+	require.NoError(t, err)
+
+	results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim("8Gi")})
+	require.NoError(t, err)
+	assert.Nil(t, results, "only 6Gi remaining")
+
+	results, err = allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim("6Gi")})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Devices.Results, 1)
+	result := results[0].Devices.Results[0]
+	assert.Equal(t, "vgpu", result.Device)
+	assert.NotNil(t, result.ShareID)
+	assert.Equal(t, "6Gi", ptr.To(result.ConsumedCapacity["memory"]).String())
+}
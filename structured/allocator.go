@@ -37,7 +37,18 @@ import (
 // for this package are more useful.
 
 type DeviceClassLister = internal.DeviceClassLister
+
+// Features is the same as [k8s.io/dynamic-resource-allocation/featuregates.Features].
 type Features = internal.Features
+
+// DefaultFeatures returns the features which are enabled by default in
+// Kubernetes. Callers which do not track Kubernetes feature gates
+// themselves can use this as a starting point.
+func DefaultFeatures() Features {
+	return internal.FeaturesDefault
+}
+
+// DeviceID is the same as [k8s.io/dynamic-resource-allocation/api.DeviceID].
 type DeviceID = internal.DeviceID
 
 func MakeDeviceID(driver, pool, device string) DeviceID {
@@ -55,6 +66,14 @@ func MakeSharedDeviceID(deviceID DeviceID, shareID *types.UID) SharedDeviceID {
 	return internal.MakeSharedDeviceID(deviceID, shareID)
 }
 
+// GatherAllocatedState determines the AllocatedState from the status of
+// all allocated claims. Devices with AllowMultipleAllocations are tracked
+// per share, together with the capacity consumed by each share, so that
+// new requests only get admitted against the remaining capacity.
+func GatherAllocatedState(claims []*resourceapi.ResourceClaim) AllocatedState {
+	return internal.GatherAllocatedState(claims)
+}
+
 func NewConsumedCapacityCollection() ConsumedCapacityCollection {
 	return internal.NewConsumedCapacityCollection()
 }
@@ -101,9 +120,102 @@ type Allocator interface {
 	Allocate(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim) (finalResult []resourceapi.AllocationResult, finalErr error)
 }
 
+// The Allocator* interfaces below are optional. They are implemented by
+// some of the allocators returned by NewAllocator. At the moment, that is only the
+// case when experimental features are enabled. Callers must use a type
+// assertion to check for them.
+
+// AllocatorScoring is for allocators which support a scoring phase.
+type AllocatorScoring = internal.AllocatorScoring
+
+// AllocatorStreaming is for allocators which can report allocations as they are found and stop early.
+type AllocatorStreaming = internal.AllocatorStreaming
+
+// ResultCallback is used by [AllocatorStreaming] to receive allocations.
+type ResultCallback = internal.ResultCallback
+
+// AllocatorIncremental is for allocators which can allocate claims for several pods with one instance.
+type AllocatorIncremental = internal.AllocatorIncremental
+
+// AllocatorExplaining is for allocators which can explain why claims cannot be allocated.
+type AllocatorExplaining = internal.AllocatorExplaining
+
+// Explanation describes why claims could not be allocated on a node.
+// Its String method returns a summary for Pod events.
+type Explanation = internal.Explanation
+
+// RequestExplanation is the part of an [Explanation] for one request.
+type RequestExplanation = internal.RequestExplanation
+
+// DeviceScorer is used by [AllocatorScoring] to determine which of the
+// devices that are suitable for a request are preferred.
+type DeviceScorer = internal.DeviceScorer
+
+// AllocatorPod is for allocators which can allocate all claims of a pod as one transaction.
+type AllocatorPod = internal.AllocatorPod
+
+// AllocatorConstraints is for allocators which support additional constraints.
+type AllocatorConstraints = internal.AllocatorConstraints
+
+// ConstraintProvider creates additional [Constraint]s for a claim.
+type ConstraintProvider = internal.ConstraintProvider
+
+// ConstraintProviderFunc implements [ConstraintProvider] with a function.
+type ConstraintProviderFunc = internal.ConstraintProviderFunc
+
+// Constraint restricts which devices can be allocated together for a claim,
+// in addition to the constraints in the claim. [NewMatchAttribute] is an
+// implementation with the same semantic as a matchAttribute constraint.
+type Constraint = internal.Constraint
+
+// AllocatorSelectorCaching is for allocators which can cache CEL selector results in a [SelectorCache].
+type AllocatorSelectorCaching = internal.AllocatorSelectorCaching
+
+// SelectorCache stores the outcome of evaluating CEL selectors for devices
+// across scheduling attempts. Results for a ResourceSlice are invalidated
+// when its ResourceVersion changes. Results for a device are also
+// invalidated when its taints change.
+type SelectorCache = internal.SelectorCache
+
+// NewSelectorCache returns an empty cache.
+func NewSelectorCache() *SelectorCache {
+	return internal.NewSelectorCache()
+}
+
+// AllocatorDeterministic is for allocators which can produce reproducible results for a given seed.
+type AllocatorDeterministic = internal.AllocatorDeterministic
+
+// AllocatorLimited is for allocators which support limiting the search for a solution.
+type AllocatorLimited = internal.AllocatorLimited
+
+// Limits bound the amount of work done by one Allocate call of an
+// [AllocatorLimited].
+type Limits = internal.Limits
+
+// ErrLimitExceeded gets wrapped by the error returned by Allocate when
+// one of the [Limits] was reached before a solution was found.
+var ErrLimitExceeded = internal.ErrLimitExceeded
+
+// RegisterMetrics registers the allocator metrics in the legacy registry
+// of k8s.io/component-base/metrics. At the moment, only the allocator
+// which is used when experimental features are enabled records metrics.
+// Calling it more than once is okay.
+//
+// The same allocator also creates an OpenTelemetry "Allocate" span when
+// the context passed to Allocate contains a span. It has the claim UIDs
+// and allocated devices as attributes, using the keys from
+// k8s.io/dynamic-resource-allocation/tracing.
+func RegisterMetrics() {
+	internal.RegisterMetrics()
+}
+
 // NewAllocator returns an allocator for a certain set of claims or an error if
 // some problem was detected which makes it impossible to allocate claims.
 //
+// The features are not validated. Allocators handle features whose
+// dependencies are disabled by not allocating devices which need them.
+// Callers which want to reject such combinations can use [Features.Validate].
+//
 // The returned Allocator can be used multiple times and is thread-safe.
 func NewAllocator(ctx context.Context,
 	features Features,
@@ -145,6 +257,33 @@ func NewAllocator(ctx context.Context,
 	return nil, fmt.Errorf("internal error: no allocator available for feature set %v", features)
 }
 
+// PatchedResourceSliceLister provides ResourceSlices with the taints from
+// DeviceTaintRules applied. It is implemented by
+// [k8s.io/dynamic-resource-allocation/resourceslice/tracker.Tracker].
+type PatchedResourceSliceLister interface {
+	ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error)
+}
+
+// NewAllocatorForPatchedSlices is like NewAllocator, except that it gets
+// the ResourceSlices from the lister. Using the same source of patched
+// slices in the scheduler and the Cluster Autoscaler ensures that devices
+// with NoSchedule or NoExecute taints are only allocated for claims which
+// tolerate them, regardless of whether the taint is set in the slice or
+// by a DeviceTaintRule.
+func NewAllocatorForPatchedSlices(ctx context.Context,
+	features Features,
+	allocatedState AllocatedState,
+	classLister DeviceClassLister,
+	sliceLister PatchedResourceSliceLister,
+	celCache *cel.Cache,
+) (Allocator, error) {
+	slices, err := sliceLister.ListPatchedResourceSlices()
+	if err != nil {
+		return nil, fmt.Errorf("list patched ResourceSlices: %w", err)
+	}
+	return NewAllocator(ctx, features, allocatedState, classLister, slices, celCache)
+}
+
 var availableAllocators = []struct {
 	supportedFeatures Features
 	newAllocator      func(ctx context.Context,
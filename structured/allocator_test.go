@@ -20,10 +20,14 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	resourceapi "k8s.io/api/resource/v1"
 	"k8s.io/dynamic-resource-allocation/cel"
 	"k8s.io/dynamic-resource-allocation/structured/internal"
 	"k8s.io/dynamic-resource-allocation/structured/internal/allocatortesting"
+	"k8s.io/klog/v2/ktesting"
 )
 
 func TestAllocator(t *testing.T) {
@@ -40,3 +44,35 @@ func TestAllocator(t *testing.T) {
 			return NewAllocator(ctx, features, allocatedState, classLister, slices, celCache)
 		})
 }
+
+func TestFeatures(t *testing.T) {
+	for name, tc := range map[string]struct {
+		features    Features
+		expectError string
+	}{
+		"none":    {},
+		"default": {features: DefaultFeatures()},
+		"all":     {features: internal.FeaturesAll},
+		"device-binding": {
+			features:    Features{DeviceBinding: true},
+			expectError: "DRADeviceBindingConditions requires DRAResourceClaimDeviceStatus",
+		},
+		"device-binding-with-status": {
+			features: Features{DeviceBinding: true, DeviceStatus: true},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			err := tc.features.Validate()
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectError)
+			}
+
+			// Not validated by NewAllocator.
+			_, err = NewAllocator(ctx, tc.features, AllocatedState{}, deviceClasses{}, nil, cel.NewCache(1, cel.Features{}))
+			require.NoError(t, err)
+		})
+	}
+}
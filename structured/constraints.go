@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// NewMatchAttribute returns a [Constraint] which requires that all devices
+// allocated for the requests have the same value for the attribute, like
+// a matchAttribute constraint in a ResourceClaim. An empty list of requests
+// means "all requests". A parent request also covers its subrequests.
+//
+// It can be used as starting point for additional constraints, for example
+// one which checks that devices are connected to the same PCIe switch.
+func NewMatchAttribute(requests []string, attributeName resourceapi.FullyQualifiedName) Constraint {
+	return &matchAttribute{
+		requestNames:  sets.New(requests...),
+		attributeName: attributeName,
+	}
+}
+
+type matchAttribute struct {
+	requestNames  sets.Set[string]
+	attributeName resourceapi.FullyQualifiedName
+
+	value      resourceapi.DeviceAttribute
+	numDevices int
+}
+
+var _ Constraint = &matchAttribute{}
+
+func (m *matchAttribute) Add(requestName string, deviceID DeviceID, device *resourceapi.Device) bool {
+	if !m.applies(requestName) {
+		return true
+	}
+	value, ok := lookupAttribute(device, deviceID, m.attributeName)
+	if !ok {
+		return false
+	}
+	if m.numDevices > 0 && !apiequality.Semantic.DeepEqual(value, m.value) {
+		return false
+	}
+	m.value = value
+	m.numDevices++
+	return true
+}
+
+func (m *matchAttribute) Remove(requestName string, deviceID DeviceID, device *resourceapi.Device) {
+	if !m.applies(requestName) {
+		return
+	}
+	m.numDevices--
+}
+
+func (m *matchAttribute) applies(requestName string) bool {
+	if m.requestNames.Len() == 0 {
+		return true
+	}
+	parentRequestName, _, _ := strings.Cut(requestName, "/")
+	return m.requestNames.Has(requestName) || m.requestNames.Has(parentRequestName)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestConstraintProviders(t *testing.T) {
+	const driver = "dra.example.com"
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	pcieSwitch := func(name string) map[resourceapi.QualifiedName]resourceapi.DeviceAttribute {
+		return map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"pcieSwitch": {StringValue: ptr.To(name)}}
+	}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driver,
+			Pool:     resourceapi.ResourcePool{Name: "pool", ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			Devices: []resourceapi.Device{
+				{Name: "gpu-0", Attributes: pcieSwitch("a")},
+				{Name: "gpu-1", Attributes: pcieSwitch("b")},
+				{Name: "gpu-2", Attributes: pcieSwitch("b")},
+			},
+		},
+	}
+	request := func(name string) resourceapi.DeviceRequest {
+		return resourceapi.DeviceRequest{
+			Name: name,
+			Exactly: &resourceapi.ExactDeviceRequest{
+				DeviceClassName: class.Name,
+				AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+				Count:           1,
+			},
+		}
+	}
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{request("gpu"), request("nic"), request("other")},
+			},
+		},
+	}
+	sameSwitch := ConstraintProviderFunc(func(ctx context.Context, claim *resourceapi.ResourceClaim) ([]Constraint, error) {
+		return []Constraint{NewMatchAttribute([]string{"gpu", "nic"}, driver+"/pcieSwitch")}, nil
+	})
+
+	for name, tc := range map[string]struct {
+		providers     []ConstraintProvider
+		expectDevices []string
+		expectError   string
+	}{
+		"none": {
+			expectDevices: []string{"gpu-0", "gpu-1", "gpu-2"},
+		},
+		"same-switch": {
+			providers:     []ConstraintProvider{sameSwitch},
+			expectDevices: []string{"gpu-1", "gpu-2", "gpu-0"},
+		},
+		"error": {
+			providers: []ConstraintProvider{ConstraintProviderFunc(func(ctx context.Context, claim *resourceapi.ResourceClaim) ([]Constraint, error) {
+				return nil, errors.New("fake error")
+			})},
+			expectError: "claim default/claim: additional constraints: fake error",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			allocator, err := NewAllocator(ctx, internal.FeaturesAll, AllocatedState{}, deviceClasses{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+			require.NoError(t, err)
+			allocatorConstraints, ok := allocator.(AllocatorConstraints)
+			require.True(t, ok, "allocator supports constraints")
+			allocatorConstraints.SetConstraintProviders(tc.providers...)
+
+			results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+			if tc.expectError != "" {
+				require.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			var devices []string
+			for _, result := range results[0].Devices.Results {
+				devices = append(devices, result.Device)
+			}
+			assert.Equal(t, tc.expectDevices, devices)
+		})
+	}
+}
+
+func TestMatchAttribute(t *testing.T) {
+	const driver = "dra.example.com"
+	deviceID := MakeDeviceID(driver, "pool", "device")
+	device := func(value string) *resourceapi.Device {
+		return &resourceapi.Device{Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": {StringValue: ptr.To(value)}}}
+	}
+
+	constraint := NewMatchAttribute([]string{"req"}, driver+"/model")
+	assert.True(t, constraint.Add("req/sub", deviceID, device("a")), "first device")
+	assert.False(t, constraint.Add("req", deviceID, device("b")), "different value")
+	assert.True(t, constraint.Add("other", deviceID, device("b")), "other request")
+	assert.False(t, constraint.Add("req", deviceID, &resourceapi.Device{}), "no attribute")
+	constraint.Remove("req/sub", deviceID, device("a"))
+	assert.True(t, constraint.Add("req", deviceID, device("b")), "empty set")
+}
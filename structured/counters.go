@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// CounterSetID identifies one of the SharedCounters of a ResourceSlice.
+type CounterSetID struct {
+	// Slice is the name of the ResourceSlice.
+	Slice string
+	// CounterSet is the name of the counter set in that slice.
+	CounterSet string
+}
+
+// RemainingCounters returns how much of each shared counter is still
+// available after subtracting what the allocated devices consume.
+// The values may be negative if the devices consume more than
+// available, which is treated like zero by the allocator.
+//
+// Simulations can use AllocatedState.Clone and AddAllocation to
+// determine what would be left after allocating some claims.
+func RemainingCounters(slices []*resourceapi.ResourceSlice, allocatedState AllocatedState) map[CounterSetID]map[string]resource.Quantity {
+	remaining := make(map[CounterSetID]map[string]resource.Quantity)
+	for _, slice := range slices {
+		for _, counterSet := range slice.Spec.SharedCounters {
+			counters := make(map[string]resource.Quantity, len(counterSet.Counters))
+			for name, counter := range counterSet.Counters {
+				counters[name] = counter.Value.DeepCopy()
+			}
+			remaining[CounterSetID{Slice: slice.Name, CounterSet: counterSet.Name}] = counters
+		}
+	}
+	for _, slice := range slices {
+		for _, device := range slice.Spec.Devices {
+			if !allocatedState.AllocatedDevices.Has(MakeDeviceID(slice.Spec.Driver, slice.Spec.Pool.Name, device.Name)) {
+				continue
+			}
+			for _, consumption := range device.ConsumesCounters {
+				counters := remaining[CounterSetID{Slice: slice.Name, CounterSet: consumption.CounterSet}]
+				for name, counter := range consumption.Counters {
+					value, ok := counters[name]
+					if !ok {
+						// Not possible in valid slices.
+						continue
+					}
+					value.Sub(counter.Value)
+					counters[name] = value
+				}
+			}
+		}
+	}
+	return remaining
+}
+
+// LeastFragmentedCounters is a [DeviceScorer] for partitionable devices.
+// It prefers those devices which use up most of what remains in their
+// counter sets, so that partitions get packed tightly and large
+// partitions remain available for later claims.
+//
+// The score is based on the counters at the time when the scorer was
+// created, so it should be created anew for each allocation.
+type LeastFragmentedCounters struct {
+	remaining map[CounterSetID]map[string]resource.Quantity
+	slices    map[DeviceID]string
+}
+
+var _ DeviceScorer = &LeastFragmentedCounters{}
+
+// NewLeastFragmentedCounters determines the remaining counters with [RemainingCounters].
+func NewLeastFragmentedCounters(slices []*resourceapi.ResourceSlice, allocatedState AllocatedState) *LeastFragmentedCounters {
+	s := &LeastFragmentedCounters{
+		remaining: RemainingCounters(slices, allocatedState),
+		slices:    make(map[DeviceID]string),
+	}
+	for _, slice := range slices {
+		if len(slice.Spec.SharedCounters) == 0 {
+			continue
+		}
+		for _, device := range slice.Spec.Devices {
+			s.slices[MakeDeviceID(slice.Spec.Driver, slice.Spec.Pool.Name, device.Name)] = slice.Name
+		}
+	}
+	return s
+}
+
+// ScoreDevice returns, for each consumed counter, the percentage of the
+// remaining value which the device would use, summed up. Devices which
+// do not consume counters have a zero score.
+func (s *LeastFragmentedCounters) ScoreDevice(ctx context.Context, claim *resourceapi.ResourceClaim, requestName string, deviceID DeviceID, device *resourceapi.Device) (int64, error) {
+	sliceName, ok := s.slices[deviceID]
+	if !ok {
+		return 0, nil
+	}
+	var score int64
+	for _, consumption := range device.ConsumesCounters {
+		counters := s.remaining[CounterSetID{Slice: sliceName, CounterSet: consumption.CounterSet}]
+		for name, counter := range consumption.Counters {
+			remaining, ok := counters[name]
+			if !ok || remaining.Sign() <= 0 {
+				continue
+			}
+			percentage := int64(100 * counter.Value.AsApproximateFloat64() / remaining.AsApproximateFloat64())
+			score += min(percentage, 100)
+		}
+	}
+	return score, nil
+}
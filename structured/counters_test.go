@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestLeastFragmentedCounters(t *testing.T) {
+	const driver = "dra.example.com"
+	partition := func(name, memory string) resourceapi.Device {
+		return resourceapi.Device{
+			Name: name,
+			ConsumesCounters: []resourceapi.DeviceCounterConsumption{{
+				CounterSet: "gpu-0",
+				Counters:   map[string]resourceapi.Counter{"memory": {Value: resource.MustParse(memory)}},
+			}},
+		}
+	}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driver,
+			Pool:     resourceapi.ResourcePool{Name: "pool", ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			SharedCounters: []resourceapi.CounterSet{{
+				Name:     "gpu-0",
+				Counters: map[string]resourceapi.Counter{"memory": {Value: resource.MustParse("80Gi")}},
+			}},
+			Devices: []resourceapi.Device{
+				partition("full", "80Gi"),
+				partition("half-a", "40Gi"),
+				partition("half-b", "40Gi"),
+				partition("quarter", "20Gi"),
+				{Name: "other"},
+			},
+		},
+	}
+	slices := []*resourceapi.ResourceSlice{slice}
+	allocatedState := AllocatedState{AllocatedDevices: sets.New(MakeDeviceID(driver, "pool", "half-a"))}
+
+	remaining := RemainingCounters(slices, allocatedState)
+	assert.Equal(t, map[CounterSetID]map[string]resource.Quantity{
+		{Slice: "slice", CounterSet: "gpu-0"}: {"memory": resource.MustParse("40Gi")},
+	}, remaining)
+
+	_, ctx := ktesting.NewTestContext(t)
+	scorer := NewLeastFragmentedCounters(slices, allocatedState)
+	for device, expectScore := range map[string]int64{
+		"full":    100,
+		"half-b":  100,
+		"quarter": 50,
+		"other":   0,
+	} {
+		index := 0
+		for index < len(slice.Spec.Devices) && slice.Spec.Devices[index].Name != device {
+			index++
+		}
+		score, err := scorer.ScoreDevice(ctx, nil, "req", MakeDeviceID(driver, "pool", device), &slice.Spec.Devices[index])
+		require.NoError(t, err, device)
+		assert.Equal(t, expectScore, score, device)
+	}
+
+	// "full" is preferred, but does not fit anymore, so "half-b" gets picked
+	// instead of "quarter", which would leave 20Gi unused.
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	allocator, err := NewAllocator(ctx, internal.FeaturesAll, allocatedState, deviceClasses{class}, slices, cel.NewCache(1, cel.Features{}))
+	require.NoError(t, err)
+	scoring, ok := allocator.(AllocatorScoring)
+	require.True(t, ok, "allocator should support scoring")
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: class.Name,
+						AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+						Count:           1,
+						Selectors:       []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `device.driver == "dra.example.com"`}}},
+					},
+				}},
+			},
+		},
+	}
+	results, score, err := scoring.AllocateWithScore(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}, []*resourceapi.ResourceClaim{claim}, scorer)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Devices.Results, 1)
+	assert.Equal(t, "half-b", results[0].Devices.Results[0].Device)
+	assert.Equal(t, int64(100), score)
+}
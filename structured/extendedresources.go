@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"fmt"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// ExtendedResourceMapping maps extended resource names (for example,
+// "vendor.com/gpu") to the name of the DeviceClass whose devices provide
+// that resource. It enables scheduling pods which still use extended
+// resource requests with the structured allocator, for example while
+// migrating from device plugins to DRA drivers.
+//
+// The mapping may be configured directly or be determined from the
+// DeviceClasses with [NewExtendedResourceMapping].
+type ExtendedResourceMapping map[v1.ResourceName]string
+
+// NewExtendedResourceMapping determines the mapping from the
+// ExtendedResourceName field of the classes. In addition, each class
+// provides the implicit "deviceclass.resource.kubernetes.io/<class name>"
+// resource.
+//
+// When several classes have the same ExtendedResourceName, the class
+// created last is used. If they were created at the same time, then the
+// one whose name sorts first is used.
+func NewExtendedResourceMapping(classes []*resourceapi.DeviceClass) ExtendedResourceMapping {
+	mapping := make(ExtendedResourceMapping)
+	winners := make(map[v1.ResourceName]*resourceapi.DeviceClass)
+	for _, class := range classes {
+		mapping[v1.ResourceName(resourceapi.ResourceDeviceClassPrefix+class.Name)] = class.Name
+		if class.Spec.ExtendedResourceName == nil {
+			continue
+		}
+		name := v1.ResourceName(*class.Spec.ExtendedResourceName)
+		if other := winners[name]; other != nil && !preferClass(class, other) {
+			continue
+		}
+		winners[name] = class
+		mapping[name] = class.Name
+	}
+	return mapping
+}
+
+// preferClass returns true if a is preferred over b for the same extended resource.
+func preferClass(a, b *resourceapi.DeviceClass) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// ExtendedResourceClaim synthesizes a ResourceClaim with one request per
+// extended resource and container of the pod which is backed by a
+// DeviceClass. The second return value describes which request was
+// created for which container and resource, in the format used by
+// [v1.PodExtendedResourceClaimStatus]. Nil is returned if the pod has
+// no such extended resource requests.
+//
+// The node is optional. If given, resources which are in its allocatable
+// resources are skipped because a device plugin provides them on that node.
+//
+// The claim has the [resourceapi.ExtendedResourceClaimAnnotation] and
+// the pod as owner. It has no name, only a GenerateName. It can be
+// allocated together with the other claims of the pod.
+func (m ExtendedResourceMapping) ExtendedResourceClaim(pod *v1.Pod, node *v1.Node) (*resourceapi.ResourceClaim, []v1.ContainerExtendedResourceRequest) {
+	var requests []resourceapi.DeviceRequest
+	var mappings []v1.ContainerExtendedResourceRequest
+	containers := slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers)
+	for containerIndex, container := range containers {
+		// Sorted by name for stable request names.
+		names := make([]v1.ResourceName, 0, len(container.Resources.Requests)+len(container.Resources.Limits))
+		for name := range container.Resources.Requests {
+			names = append(names, name)
+		}
+		for name := range container.Resources.Limits {
+			if _, ok := container.Resources.Requests[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+
+		requestIndex := 0
+		for _, name := range names {
+			className, ok := m[name]
+			if !ok {
+				continue
+			}
+			if node != nil {
+				if _, ok := node.Status.Allocatable[name]; ok {
+					continue
+				}
+			}
+			// Extended resources cannot be overcommitted, so a limit
+			// without a request is the same as a request.
+			quantity, ok := container.Resources.Requests[name]
+			if !ok {
+				quantity = container.Resources.Limits[name]
+			}
+			if quantity.Value() <= 0 {
+				continue
+			}
+			requestName := fmt.Sprintf("container-%d-request-%d", containerIndex, requestIndex)
+			requestIndex++
+			requests = append(requests, resourceapi.DeviceRequest{
+				Name: requestName,
+				Exactly: &resourceapi.ExactDeviceRequest{
+					DeviceClassName: className,
+					AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+					Count:           quantity.Value(),
+				},
+			})
+			mappings = append(mappings, v1.ContainerExtendedResourceRequest{
+				ContainerName: container.Name,
+				ResourceName:  name.String(),
+				RequestName:   requestName,
+			})
+		}
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: pod.Name + "-extended-resources-",
+			Namespace:    pod.Namespace,
+			Annotations: map[string]string{
+				resourceapi.ExtendedResourceClaimAnnotation: "true",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "v1",
+				Kind:               "Pod",
+				Name:               pod.Name,
+				UID:                pod.UID,
+				Controller:         ptr.To(true),
+				BlockOwnerDeletion: ptr.To(true),
+			}},
+		},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: requests,
+			},
+		},
+	}
+	return claim, mappings
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestNewExtendedResourceMapping(t *testing.T) {
+	now := metav1.Now()
+	later := metav1.NewTime(now.Add(time.Minute))
+	class := func(name, extendedResourceName string, created metav1.Time) *resourceapi.DeviceClass {
+		class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: created}}
+		if extendedResourceName != "" {
+			class.Spec.ExtendedResourceName = ptr.To(extendedResourceName)
+		}
+		return class
+	}
+
+	mapping := NewExtendedResourceMapping([]*resourceapi.DeviceClass{
+		class("old-gpu", "vendor.com/gpu", now),
+		class("new-gpu", "vendor.com/gpu", later),
+		class("b-nic", "vendor.com/nic", now),
+		class("a-nic", "vendor.com/nic", now),
+		class("fpga", "", now),
+	})
+	assert.Equal(t, ExtendedResourceMapping{
+		"vendor.com/gpu": "new-gpu",
+		"vendor.com/nic": "a-nic",
+		"deviceclass.resource.kubernetes.io/old-gpu": "old-gpu",
+		"deviceclass.resource.kubernetes.io/new-gpu": "new-gpu",
+		"deviceclass.resource.kubernetes.io/b-nic":   "b-nic",
+		"deviceclass.resource.kubernetes.io/a-nic":   "a-nic",
+		"deviceclass.resource.kubernetes.io/fpga":    "fpga",
+	}, mapping)
+}
+
+func TestExtendedResourceClaim(t *testing.T) {
+	const driver = "dra.example.com"
+	mapping := ExtendedResourceMapping{"vendor.com/gpu": "gpu", "vendor.com/nic": "nic"}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "1234"},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{
+				Name: "init",
+				Resources: v1.ResourceRequirements{
+					Limits: v1.ResourceList{"vendor.com/nic": resource.MustParse("1")},
+				},
+			}},
+			Containers: []v1.Container{{
+				Name: "ctr",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceCPU:   resource.MustParse("1"),
+						"vendor.com/gpu": resource.MustParse("2"),
+						"vendor.com/nic": resource.MustParse("1"),
+						"other.com/dev":  resource.MustParse("1"),
+					},
+				},
+			}},
+		},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		claim, mappings := mapping.ExtendedResourceClaim(pod, nil)
+		require.NotNil(t, claim)
+		assert.Equal(t, "pod-extended-resources-", claim.GenerateName)
+		assert.Equal(t, "true", claim.Annotations[resourceapi.ExtendedResourceClaimAnnotation])
+		assert.Equal(t, pod.UID, claim.OwnerReferences[0].UID)
+		assert.Equal(t, []resourceapi.DeviceRequest{
+			{Name: "container-0-request-0", Exactly: &resourceapi.ExactDeviceRequest{DeviceClassName: "nic", AllocationMode: resourceapi.DeviceAllocationModeExactCount, Count: 1}},
+			{Name: "container-1-request-0", Exactly: &resourceapi.ExactDeviceRequest{DeviceClassName: "gpu", AllocationMode: resourceapi.DeviceAllocationModeExactCount, Count: 2}},
+			{Name: "container-1-request-1", Exactly: &resourceapi.ExactDeviceRequest{DeviceClassName: "nic", AllocationMode: resourceapi.DeviceAllocationModeExactCount, Count: 1}},
+		}, claim.Spec.Devices.Requests)
+		assert.Equal(t, []v1.ContainerExtendedResourceRequest{
+			{ContainerName: "init", ResourceName: "vendor.com/nic", RequestName: "container-0-request-0"},
+			{ContainerName: "ctr", ResourceName: "vendor.com/gpu", RequestName: "container-1-request-0"},
+			{ContainerName: "ctr", ResourceName: "vendor.com/nic", RequestName: "container-1-request-1"},
+		}, mappings)
+	})
+
+	t.Run("device-plugin-node", func(t *testing.T) {
+		node := &v1.Node{Status: v1.NodeStatus{Allocatable: v1.ResourceList{"vendor.com/nic": resource.MustParse("4")}}}
+		claim, mappings := mapping.ExtendedResourceClaim(pod, node)
+		require.NotNil(t, claim)
+		require.Len(t, claim.Spec.Devices.Requests, 1)
+		assert.Equal(t, "gpu", claim.Spec.Devices.Requests[0].Exactly.DeviceClassName)
+		assert.Equal(t, []v1.ContainerExtendedResourceRequest{{ContainerName: "ctr", ResourceName: "vendor.com/gpu", RequestName: "container-1-request-0"}}, mappings)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		claim, mappings := ExtendedResourceMapping{}.ExtendedResourceClaim(pod, nil)
+		assert.Nil(t, claim)
+		assert.Nil(t, mappings)
+	})
+
+	t.Run("allocate", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+		slice := &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   driver,
+				Pool:     resourceapi.ResourcePool{Name: node.Name, ResourceSliceCount: 1},
+				NodeName: ptr.To(node.Name),
+				Devices: []resourceapi.Device{
+					{Name: "gpu-0", Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"type": {StringValue: ptr.To("gpu")}}},
+					{Name: "gpu-1", Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"type": {StringValue: ptr.To("gpu")}}},
+					{Name: "nic-0", Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"type": {StringValue: ptr.To("nic")}}},
+				},
+			},
+		}
+		classes := []*resourceapi.DeviceClass{
+			{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}, Spec: resourceapi.DeviceClassSpec{Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `device.attributes["dra.example.com"].type == "gpu"`}}}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "nic"}, Spec: resourceapi.DeviceClassSpec{Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `device.attributes["dra.example.com"].type == "nic"`}}}}},
+		}
+		pod := pod.DeepCopy()
+		pod.Spec.InitContainers = nil
+		claim, _ := mapping.ExtendedResourceClaim(pod, node)
+		results, err := Simulate(ctx, []*resourceapi.ResourceClaim{claim}, []*resourceapi.ResourceSlice{slice}, node, SimulateOptions{Classes: classes})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		var devices []string
+		for _, result := range results[0].Devices.Results {
+			devices = append(devices, result.Device)
+		}
+		assert.Equal(t, []string{"gpu-0", "gpu-1", "nic-0"}, devices)
+	})
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/builders"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/internal/fuzzinput"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+// FuzzAllocate feeds random but valid slices and claims through the
+// allocator. It checks that nothing panics and that allocation results:
+//   - exist for all claims or for none,
+//   - only reference existing devices on the node which are not tainted
+//     (when device taints are enabled),
+//   - never use the same device twice or a device which was already allocated,
+//   - have the requested number of devices.
+//
+// This is a native Go fuzz test, which OSS-Fuzz can build with
+// compile_native_go_fuzzer.
+func FuzzAllocate(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{1, 2, 0, 0, 3, 0, 0, 0, 1, 1, 0, 2, 0, 0})
+	f.Add([]byte{0, 3, 1, 1, 2, 1, 1, 0, 3, 0, 0, 1, 2, 2, 1, 1, 0, 1, 1, 3, 1, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		in := fuzzinput.New(data)
+		features := fuzzinput.Pick(in, DefaultFeatures(), internal.FeaturesAll)
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}}
+		class := builders.MakeDeviceClass("class").Selectors(fuzzinput.Pick(in, `true`, `device.driver == "`+builders.DefaultDriver+`"`)).Obj()
+
+		var slices []*resourceapi.ResourceSlice
+		type deviceInfo struct {
+			onNode  bool
+			tainted bool
+		}
+		devices := make(map[DeviceID]deviceInfo)
+		for i := range in.Intn(3) + 1 {
+			pool := fmt.Sprintf("pool-%d", i)
+			nodeName := fuzzinput.Pick(in, "", node.Name, "other")
+			slice := builders.MakeResourceSlice(fmt.Sprintf("slice-%d", i)).Pool(pool)
+			if nodeName != "" {
+				slice.NodeName(nodeName)
+			}
+			var sliceDevices []resourceapi.Device
+			for j := range in.Intn(4) {
+				name := fmt.Sprintf("device-%d", j)
+				device := builders.MakeDevice(name).IntAttribute("index", int64(j))
+				tainted := in.Intn(4) == 0
+				if tainted {
+					device.Taints(builders.MakeDeviceTaint("example.com/unhealthy").Obj())
+				}
+				sliceDevices = append(sliceDevices, device.Obj())
+				devices[MakeDeviceID(builders.DefaultDriver, pool, name)] = deviceInfo{onNode: nodeName != "other", tainted: tainted}
+			}
+			slices = append(slices, slice.Devices(sliceDevices...).Obj())
+		}
+
+		allocated := sets.New[DeviceID]()
+		for id := range devices {
+			if in.Intn(4) == 0 {
+				allocated.Insert(id)
+			}
+		}
+
+		var claims []*resourceapi.ResourceClaim
+		for i := range in.Intn(3) + 1 {
+			claim := builders.MakeResourceClaim(fmt.Sprintf("claim-%d", i))
+			for j := range in.Intn(2) + 1 {
+				request := fmt.Sprintf("req-%d", j)
+				if in.Intn(4) == 0 {
+					claim.RequestAll(request, class.Name)
+				} else {
+					claim.Request(request, class.Name, int64(in.Intn(3)+1))
+				}
+				if in.Bool() {
+					claim.Selectors(fuzzinput.Pick(in, `true`, `device.attributes["`+builders.DefaultDriver+`"].index > 0`))
+				}
+			}
+			claims = append(claims, claim.Obj())
+		}
+
+		logger, ctx := ktesting.NewTestContext(t)
+		ctx = klog.NewContext(ctx, logger.V(5))
+		allocator, err := NewAllocator(ctx, features, AllocatedState{AllocatedDevices: allocated}, deviceClasses{class}, slices, cel.NewCache(10, cel.Features{}))
+		require.NoError(t, err, "create allocator")
+		results, err := allocator.Allocate(ctx, node, claims)
+		require.NoError(t, err, "allocate")
+		if results == nil {
+			return
+		}
+
+		require.Len(t, results, len(claims), "one result per claim")
+		used := sets.New[DeviceID]()
+		for i, result := range results {
+			counts := make(map[string]int64)
+			for _, device := range result.Devices.Results {
+				id := MakeDeviceID(device.Driver, device.Pool, device.Device)
+				info, ok := devices[id]
+				if !assert.True(t, ok, "allocated device %s does not exist", id) {
+					continue
+				}
+				assert.True(t, info.onNode, "allocated device %s is not available on the node", id)
+				if features.DeviceTaints {
+					assert.False(t, info.tainted, "allocated device %s is tainted", id)
+				}
+				assert.False(t, allocated.Has(id), "allocated device %s was already allocated", id)
+				assert.False(t, used.Has(id), "device %s allocated twice", id)
+				assert.False(t, ptr.Deref(device.AdminAccess, false), "admin access for device %s", id)
+				used.Insert(id)
+				counts[device.Request]++
+			}
+			for _, request := range claims[i].Spec.Devices.Requests {
+				switch request.Exactly.AllocationMode {
+				case resourceapi.DeviceAllocationModeExactCount:
+					assert.Equal(t, request.Exactly.Count, counts[request.Name], "number of devices for request %s of claim %s", request.Name, claims[i].Name)
+				case resourceapi.DeviceAllocationModeAll:
+					assert.Positive(t, counts[request.Name], "number of devices for request %s of claim %s", request.Name, claims[i].Name)
+				}
+			}
+		}
+	})
+}
@@ -26,22 +26,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	draapi "k8s.io/dynamic-resource-allocation/api"
+	"k8s.io/utils/ptr"
 )
 
-type DeviceID struct {
-	Driver, Pool, Device draapi.UniqueString
-}
-
-func (d DeviceID) String() string {
-	return d.Driver.String() + "/" + d.Pool.String() + "/" + d.Device.String()
-}
+type DeviceID = draapi.DeviceID
 
 func MakeDeviceID(driver, pool, device string) DeviceID {
-	return DeviceID{
-		Driver: draapi.MakeUniqueString(driver),
-		Pool:   draapi.MakeUniqueString(pool),
-		Device: draapi.MakeUniqueString(device),
-	}
+	return draapi.MakeDeviceID(driver, pool, device)
 }
 
 type SharedDeviceID struct {
@@ -84,6 +75,68 @@ type AllocatedState struct {
 	AggregatedCapacity       ConsumedCapacityCollection
 }
 
+// GatherAllocatedState determines which devices and how much of their
+// consumable capacity are in use by the allocated claims. Claims without
+// an allocation are skipped.
+func GatherAllocatedState(claims []*resourceapi.ResourceClaim) AllocatedState {
+	state := AllocatedState{
+		AllocatedDevices:         sets.New[DeviceID](),
+		AllocatedSharedDeviceIDs: sets.New[SharedDeviceID](),
+		AggregatedCapacity:       NewConsumedCapacityCollection(),
+	}
+	for _, claim := range claims {
+		if claim.Status.Allocation != nil {
+			state.AddAllocation(claim.Status.Allocation)
+		}
+	}
+	return state
+}
+
+// Clone makes a copy which can be modified without affecting the original.
+func (s AllocatedState) Clone() AllocatedState {
+	return AllocatedState{
+		AllocatedDevices:         s.AllocatedDevices.Clone(),
+		AllocatedSharedDeviceIDs: s.AllocatedSharedDeviceIDs.Clone(),
+		AggregatedCapacity:       s.AggregatedCapacity.Clone(),
+	}
+}
+
+// AddAllocation marks all devices in the allocation as in use, except
+// for those allocated with admin access. Those remain available for
+// other claims and do not consume capacity.
+// The state must have been created with non-nil fields, for example
+// by Clone.
+func (s AllocatedState) AddAllocation(allocation *resourceapi.AllocationResult) {
+	for _, result := range allocation.Devices.Results {
+		if ptr.Deref(result.AdminAccess, false) {
+			continue
+		}
+		deviceID := MakeDeviceID(result.Driver, result.Pool, result.Device)
+		if result.ShareID == nil {
+			s.AllocatedDevices.Insert(deviceID)
+			continue
+		}
+		s.AllocatedSharedDeviceIDs.Insert(MakeSharedDeviceID(deviceID, result.ShareID))
+		s.AggregatedCapacity.Insert(NewDeviceConsumedCapacity(deviceID, result.ConsumedCapacity))
+	}
+}
+
+// RemoveAllocation reverts AddAllocation.
+func (s AllocatedState) RemoveAllocation(allocation *resourceapi.AllocationResult) {
+	for _, result := range allocation.Devices.Results {
+		if ptr.Deref(result.AdminAccess, false) {
+			continue
+		}
+		deviceID := MakeDeviceID(result.Driver, result.Pool, result.Device)
+		if result.ShareID == nil {
+			s.AllocatedDevices.Delete(deviceID)
+			continue
+		}
+		s.AllocatedSharedDeviceIDs.Delete(MakeSharedDeviceID(deviceID, result.ShareID))
+		s.AggregatedCapacity.Remove(NewDeviceConsumedCapacity(deviceID, result.ConsumedCapacity))
+	}
+}
+
 // ConsumedCapacity defines consumable capacity values
 type ConsumedCapacity map[resourceapi.QualifiedName]*resource.Quantity
 
@@ -17,6 +17,7 @@ limitations under the License.
 package experimental
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -43,6 +44,20 @@ type DeviceClassLister = internal.DeviceClassLister
 type Features = internal.Features
 type DeviceID = internal.DeviceID
 type Stats = internal.Stats
+type DeviceScorer = internal.DeviceScorer
+type Limits = internal.Limits
+type Constraint = internal.Constraint
+type SelectorCache = internal.SelectorCache
+type ConstraintProvider = internal.ConstraintProvider
+type ResultCallback = internal.ResultCallback
+
+const (
+	AllocationResultSuccess       = internal.AllocationResultSuccess
+	AllocationResultUnschedulable = internal.AllocationResultUnschedulable
+	AllocationResultError         = internal.AllocationResultError
+)
+
+var ErrLimitExceeded = internal.ErrLimitExceeded
 
 func MakeDeviceID(driver, pool, device string) DeviceID {
 	return internal.MakeDeviceID(driver, pool, device)
@@ -102,15 +117,36 @@ type Allocator struct {
 	// The allocator might be accessed by different goroutines, so
 	// access to this map must be synchronized.
 	availableCounters map[string]counterSets
-	mutex             sync.RWMutex
+	// pools caches the result of GatherPools per node name.
+	// Protected by the mutex.
+	pools map[string][]*Pool
+	mutex sync.RWMutex
 	// numAllocateOneInvocations counts the number of times the allocateOne
 	// function is called for the allocator. This is a measurement of the
 	// amount of work the allocator had to do to allocate devices
 	// for the claims.
 	numAllocateOneInvocations atomic.Int64
+	// limits get set by SetLimits. Protected by the mutex.
+	limits Limits
+	// constraintProviders get set by SetConstraintProviders.
+	// Protected by the mutex.
+	constraintProviders []ConstraintProvider
+	// selectorCache gets set by SetSelectorCache. Protected by the mutex.
+	selectorCache *SelectorCache
+	// orderSeed gets set by SetDeterministicOrder. Protected by the mutex.
+	orderSeed *uint64
 }
 
 var _ internal.AllocatorExtended = &Allocator{}
+var _ internal.AllocatorScoring = &Allocator{}
+var _ internal.AllocatorIncremental = &Allocator{}
+var _ internal.AllocatorExplaining = &Allocator{}
+var _ internal.AllocatorLimited = &Allocator{}
+var _ internal.AllocatorPod = &Allocator{}
+var _ internal.AllocatorConstraints = &Allocator{}
+var _ internal.AllocatorSelectorCaching = &Allocator{}
+var _ internal.AllocatorDeterministic = &Allocator{}
+var _ internal.AllocatorStreaming = &Allocator{}
 
 // NewAllocator returns an allocator for a certain set of claims or an error if
 // some problem was detected which makes it impossible to allocate claims.
@@ -130,31 +166,220 @@ func NewAllocator(ctx context.Context,
 		slices:            slices,
 		celCache:          celCache,
 		availableCounters: make(map[string]counterSets),
+		pools:             make(map[string][]*Pool),
 	}, nil
 }
 
+// Assume treats the devices in the allocation results as allocated in all
+// following Allocate calls. The returned function reverts that. This is
+// meant for allocating claims of several pods one after the other with the
+// same Allocator, for example during one scheduling cycle.
+//
+// The AllocatedState passed to NewAllocator is not modified. Assume must not
+// be called while Allocate runs.
+func (a *Allocator) Assume(results []resourceapi.AllocationResult) (rollback func()) {
+	// Callers may modify their results later.
+	assumed := make([]resourceapi.AllocationResult, len(results))
+	for i := range results {
+		results[i].DeepCopyInto(&assumed[i])
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.updateAllocatedState(func(allocatedState AllocatedState) {
+		for i := range assumed {
+			allocatedState.AddAllocation(&assumed[i])
+		}
+	})
+
+	return func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		a.updateAllocatedState(func(allocatedState AllocatedState) {
+			for i := range assumed {
+				allocatedState.RemoveAllocation(&assumed[i])
+			}
+		})
+	}
+}
+
+// SetLimits bounds the amount of work done by following Allocate calls.
+// Without limits, pathological claims (for example, many requests with
+// overlapping constraints) can keep the exhaustive search busy for a
+// long time.
+func (a *Allocator) SetLimits(limits Limits) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.limits = limits
+}
+
+// SetConstraintProviders replaces the providers of additional constraints
+// for following Allocate calls.
+func (a *Allocator) SetConstraintProviders(providers ...ConstraintProvider) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.constraintProviders = providers
+}
+
+// SetSelectorCache enables caching of CEL selector results across
+// Allocate calls. The cache may be shared between allocators.
+func (a *Allocator) SetSelectorCache(cache *SelectorCache) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.selectorCache = cache
+}
+
+// SetDeterministicOrder makes the order in which devices are tried, and
+// thus the choice between devices which are equally suitable, depend only
+// on the ResourceSlices and the seed. Without it, the order depends on the
+// order of the ResourceSlices passed to NewAllocator and on map iteration.
+func (a *Allocator) SetDeterministicOrder(seed uint64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.orderSeed = &seed
+	a.pools = make(map[string][]*Pool)
+}
+
+// updateAllocatedState must be called while holding the mutex. It invalidates
+// the available counters, which depend on the allocated devices.
+func (a *Allocator) updateAllocatedState(update func(allocatedState AllocatedState)) {
+	allocatedState := a.allocatedState.Clone()
+	update(allocatedState)
+	a.allocatedState = allocatedState
+	a.availableCounters = make(map[string]counterSets)
+}
+
+// gatherPools returns the pools for the node. The result only depends
+// on the slices and the node, so it gets computed once per node.
+func (a *Allocator) gatherPools(ctx context.Context, node *v1.Node) ([]*Pool, error) {
+	if node == nil {
+		return a.gatherOrderedPools(ctx, node)
+	}
+	a.mutex.RLock()
+	pools, found := a.pools[node.Name]
+	a.mutex.RUnlock()
+	if found {
+		return pools, nil
+	}
+	pools, err := a.gatherOrderedPools(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	a.mutex.Lock()
+	a.pools[node.Name] = pools
+	a.mutex.Unlock()
+	return pools, nil
+}
+
 func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim) (finalResult []resourceapi.AllocationResult, finalErr error) {
+	result, _, err := a.AllocateWithScore(ctx, node, claims, nil)
+	return result, err
+}
+
+// AllocateWithScore is like Allocate, except that devices which are suitable
+// for a request are tried in order of decreasing score. Devices with the same
+// score are tried in the same order as in Allocate.
+//
+// This is a preference, not an optimization: the first solution that is found
+// is used, so a device with a lower score still gets picked when trying the
+// better ones does not lead to a solution. The returned score is the sum of
+// the scores of all allocated devices. Schedulers can combine it with their
+// own node score.
+//
+// Scores do not change the order of subrequests in a request with
+// FirstAvailable: the first subrequest which can be satisfied is used, even
+// if a later one would have a higher score. The scorer gets called with
+// "<request>/<subrequest>" as request name for the devices of a subrequest.
+func (a *Allocator) AllocateWithScore(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim, scorer DeviceScorer) (finalResult []resourceapi.AllocationResult, finalScore int64, finalErr error) {
+	return a.allocate(ctx, node, claims, nil, scorer, nil, nil)
+}
+
+// AllocateWithExplanation is like Allocate. When the claims cannot be allocated,
+// it also returns an explanation why.
+func (a *Allocator) AllocateWithExplanation(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim) (finalResult []resourceapi.AllocationResult, explanation *Explanation, finalErr error) {
+	explainer := newExplainer()
+	result, _, err := a.allocate(ctx, node, claims, nil, nil, explainer, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result == nil {
+		explanation = explainer.explanation
+	}
+	return result, explanation, nil
+}
+
+// AllocateStream is like AllocateWithScore, except that it does not stop at
+// the first solution. Instead, each solution gets passed to the callback as
+// soon as it is found. The search continues with the next solution while
+// the callback returns true and stops when it returns false.
+//
+// The search is exhaustive, so solutions which are equivalent in practice
+// (for example, the same devices allocated for two identical requests in
+// a different order) may get reported more than once. Limits apply to
+// the entire search.
+func (a *Allocator) AllocateStream(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim, scorer DeviceScorer, onResult ResultCallback) error {
+	_, _, err := a.allocate(ctx, node, claims, nil, scorer, nil, onResult)
+	return err
+}
+
+func (a *Allocator) allocate(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim, podConstraints []resourceapi.DeviceConstraint, scorer DeviceScorer, explainer *explainer, onResult ResultCallback) (finalResult []resourceapi.AllocationResult, finalScore int64, finalErr error) {
+	a.mutex.RLock()
+	limits := a.limits
+	constraintProviders := a.constraintProviders
+	selectorCache := a.selectorCache
+	a.mutex.RUnlock()
+	ctx, span := startAllocateSpan(ctx, node, claims)
+	if limits.Timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeoutCause(ctx, limits.Timeout, fmt.Errorf("%w: timeout after %s", ErrLimitExceeded, limits.Timeout))
+		defer cancel()
+	}
+
 	alloc := &allocator{
-		Allocator:            a,
-		ctx:                  ctx, // all methods share the same a and thus ctx
-		logger:               klog.FromContext(ctx),
-		node:                 node,
-		deviceMatchesRequest: make(map[matchKey]bool),
-		constraints:          make([][]constraint, len(claims)),
-		consumedCounters:     make(map[string]counterSets),
-		requestData:          make(map[requestIndices]requestData),
-		result:               make([]internalAllocationResult, len(claims)),
-		allocatingCapacity:   NewConsumedCapacityCollection(),
+		Allocator:     a,
+		limits:        limits,
+		selectorCache: selectorCache,
+		ctx:           ctx, // all methods share the same a and thus ctx
+		logger:        klog.FromContext(ctx),
+		node:          node,
+		scorer:        scorer,
+		explainer:     explainer,
+		onResult:      onResult,
+		scratch:       getScratch(),
+		constraints:   make([][]constraint, len(claims)),
+		result:        make([]internalAllocationResult, len(claims)),
+		metrics:       newAllocationMetrics(),
 	}
 	alloc.claimsToAllocate = claims
+	// Must be the first defer so that it runs last,
+	// after everything else which uses the scratch maps.
+	defer alloc.scratch.release()
+	defer func() {
+		result := AllocationResultSuccess
+		switch {
+		case finalErr != nil:
+			result = AllocationResultError
+		case finalResult == nil && alloc.numResults == 0:
+			result = AllocationResultUnschedulable
+		}
+		alloc.metrics.record(result, alloc.classNames())
+		endAllocateSpan(span, result, finalResult, finalErr)
+	}()
 	alloc.logger.V(5).Info("Starting allocation", "numClaims", len(alloc.claimsToAllocate))
+	if explainer != nil {
+		defer func() {
+			if finalResult == nil && finalErr == nil {
+				explainer.explanation = explainer.explain(alloc)
+			}
+		}()
+	}
 	defer alloc.logger.V(5).Info("Done with allocation", "success", len(finalResult) == len(alloc.claimsToAllocate), "err", finalErr)
 
 	alloc.logger.V(5).Info("Gathering pools", "slices", alloc.slices)
 	// First determine all eligible pools.
-	pools, err := GatherPools(ctx, alloc.slices, node, a.features)
+	pools, err := a.gatherPools(ctx, node)
 	if err != nil {
-		return nil, fmt.Errorf("gather pool information: %w", err)
+		return nil, 0, fmt.Errorf("gather pool information: %w", err)
 	}
 	alloc.pools = pools
 	if loggerV := alloc.logger.V(7); loggerV.Enabled() {
@@ -201,7 +426,7 @@ func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resou
 			// Error out if the prioritizedList feature is not enabled and the request
 			// has subrequests. This is to avoid surprising behavior for users.
 			if !a.features.PrioritizedList && hasSubRequests {
-				return nil, fmt.Errorf("claim %s, request %s: has subrequests, but the DRAPrioritizedList feature is disabled", klog.KObj(claim), request.Name)
+				return nil, 0, fmt.Errorf("claim %s, request %s: has subrequests, but the DRAPrioritizedList feature is disabled", klog.KObj(claim), request.Name)
 			}
 
 			// Error out if the consumableCapacity feature is not enabled
@@ -218,7 +443,7 @@ func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resou
 					}
 				}
 				if containsCapacityRequest {
-					return nil, fmt.Errorf("claim %s, request %s: has capacity requests, but the DRAConsumableCapacity feature is disabled",
+					return nil, 0, fmt.Errorf("claim %s, request %s: has capacity requests, but the DRAConsumableCapacity feature is disabled",
 						klog.KObj(claim), request.Name)
 				}
 			}
@@ -236,13 +461,13 @@ func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resou
 					// Error out if the consumableCapacity feature is not enabled
 					// and the subrequest contains capacity requests.
 					if !a.features.ConsumableCapacity && subReq.Capacity != nil {
-						return nil, fmt.Errorf("claim %s, subrequest %s: has capacity requests, but the DRAConsumableCapacity feature is disabled",
+						return nil, 0, fmt.Errorf("claim %s, subrequest %s: has capacity requests, but the DRAConsumableCapacity feature is disabled",
 							klog.KObj(claim), subReq.Name)
 					}
 					reqData, err := alloc.validateDeviceRequest(&deviceSubRequestAccessor{subRequest: &subReq},
 						&exactDeviceRequestAccessor{request: request}, requestKey, pools)
 					if err != nil {
-						return nil, err
+						return nil, 0, err
 					}
 					requestKey.subRequestIndex = i
 					alloc.requestData[requestKey] = reqData
@@ -254,7 +479,7 @@ func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resou
 			} else {
 				reqData, err := alloc.validateDeviceRequest(&exactDeviceRequestAccessor{request: request}, nil, requestKey, pools)
 				if err != nil {
-					return nil, err
+					return nil, 0, err
 				}
 				alloc.requestData[requestKey] = reqData
 				minDevicesPerClaim += reqData.numDevices
@@ -265,7 +490,7 @@ func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resou
 		// This isn't perfectly reliable because numDevicesPerClaim is
 		// only a lower bound, so allocation also has to check this.
 		if minDevicesPerClaim > resourceapi.AllocationResultsMaxSize {
-			return nil, fmt.Errorf("claim %s: number of requested devices %d exceeds the claim limit of %d", klog.KObj(claim), minDevicesPerClaim, resourceapi.AllocationResultsMaxSize)
+			return nil, 0, fmt.Errorf("claim %s: number of requested devices %d exceeds the claim limit of %d", klog.KObj(claim), minDevicesPerClaim, resourceapi.AllocationResultsMaxSize)
 		}
 
 		// If we don't, then we can pre-allocate the result slices for
@@ -277,52 +502,27 @@ func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resou
 		// allows the search to stop early once a constraint returns false.
 		constraints := make([]constraint, len(claim.Spec.Devices.Constraints))
 		for i, constraint := range claim.Spec.Devices.Constraints {
-			switch {
-			case constraint.MatchAttribute != nil:
-				matchAttribute := draapi.FullyQualifiedName(*constraint.MatchAttribute)
-				logger := alloc.logger
-				if loggerV := alloc.logger.V(6); loggerV.Enabled() {
-					logger = klog.LoggerWithName(logger, "matchAttributeConstraint")
-					logger = klog.LoggerWithValues(logger, "matchAttribute", matchAttribute)
-				}
-				m := &matchAttributeConstraint{
-					logger:        logger,
-					requestNames:  sets.New(constraint.Requests...),
-					attributeName: matchAttribute,
-				}
-				constraints[i] = m
-			case constraint.DistinctAttribute != nil:
-				distinctAttribute := draapi.FullyQualifiedName(*constraint.DistinctAttribute)
-				logger := alloc.logger
-				if loggerV := alloc.logger.V(6); loggerV.Enabled() {
-					logger = klog.LoggerWithName(logger, "distinctAttributeConstraint")
-					logger = klog.LoggerWithValues(logger, "distinctAttribute", distinctAttribute)
-				}
-				m := &distinctAttributeConstraint{
-					logger:        logger,
-					requestNames:  sets.New(constraint.Requests...),
-					attributeName: distinctAttribute,
-					attributes:    make(map[string]draapi.DeviceAttribute),
-				}
-				constraints[i] = m
-			default:
-				// Unknown constraint type!
-				return nil, fmt.Errorf("claim %s, constraint #%d: empty constraint (unsupported constraint type?)", klog.KObj(claim), i)
+			c, err := alloc.newConstraint(constraint, sets.New(constraint.Requests...))
+			if err != nil {
+				return nil, 0, fmt.Errorf("claim %s, constraint #%d: %w", klog.KObj(claim), i, err)
+			}
+			constraints[i] = c
+		}
+		for _, provider := range constraintProviders {
+			additionalConstraints, err := provider.Constraints(ctx, claim)
+			if err != nil {
+				return nil, 0, fmt.Errorf("claim %s: additional constraints: %w", klog.KObj(claim), err)
+			}
+			for _, c := range additionalConstraints {
+				constraints = append(constraints, newPluggedConstraint(c))
 			}
 		}
 		alloc.constraints[claimIndex] = constraints
 		minDevicesTotal += minDevicesPerClaim
 	}
-
-	// Selecting a device for a request is independent of what has been
-	// allocated already. Therefore the result of checking a request against
-	// a device instance in the pool can be cached. The pointer to both
-	// can serve as key because they are static for the duration of
-	// the Allocate call and can be compared in Go.
-	alloc.deviceMatchesRequest = make(map[matchKey]bool)
-
-	// We can estimate the size based on what we need to allocate.
-	alloc.allocatingDevices = make(map[DeviceID]sets.Set[int], minDevicesTotal)
+	if err := alloc.addPodConstraints(podConstraints); err != nil {
+		return nil, 0, err
+	}
 
 	alloc.logger.V(6).Info("Gathered information about devices", "numAllocated", len(alloc.allocatedState.AllocatedDevices), "minDevicesToBeAllocated", minDevicesTotal)
 
@@ -341,15 +541,28 @@ func (a *Allocator) Allocate(ctx context.Context, node *v1.Node, claims []*resou
 	// without further wrapping.
 	done, err := alloc.allocateOne(deviceIndices{}, false)
 	if errors.Is(err, errStop) {
-		return nil, nil
+		return nil, 0, nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	if !done {
-		return nil, nil
+	if !done || onResult != nil {
+		// When streaming, all results were passed to the callback.
+		return nil, 0, nil
 	}
 
+	result, err := alloc.buildResult()
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, alloc.score, nil
+}
+
+// buildResult converts the devices which are currently being allocated
+// into the final allocation results.
+func (alloc *allocator) buildResult() ([]resourceapi.AllocationResult, error) {
+	a := alloc.Allocator
+	node := alloc.node
 	result := make([]resourceapi.AllocationResult, len(alloc.result))
 	for claimIndex, internalResult := range alloc.result {
 		claim := alloc.claimsToAllocate[claimIndex]
@@ -512,6 +725,13 @@ func (alloc *allocator) validateDeviceRequest(request requestAccessor, parentReq
 		// pulling from an incomplete might not pick the best solution and it's
 		// better to wait. This does not matter yet as long the incomplete pool
 		// has some matching device.
+		//
+		// "All" means all devices which match the request, not all devices
+		// which could be allocated together. When partitions and the device
+		// that they are part of both match, their counters cannot be
+		// consumed by all of them and allocation fails. Devices which
+		// allow multiple allocations get allocated even when they are
+		// already allocated, as long as they have enough capacity left.
 		requestData.allDevices = make([]deviceWithID, 0, resourceapi.AllocationResultsMaxSize)
 		for _, pool := range pools {
 			if pool.IsIncomplete {
@@ -533,16 +753,22 @@ func (alloc *allocator) validateDeviceRequest(request requestAccessor, parentReq
 							Device: &slice.Spec.Devices[deviceIndex],
 							slice:  slice,
 						}
-						if alloc.features.ConsumableCapacity {
-							// Next validate whether resource request over capacity
-							success, err := alloc.CmpRequestOverCapacity(requestData.request, slice, deviceIndex)
+						if alloc.features.ConsumableCapacity && !request.adminAccess() {
+							// Devices which could not satisfy the request even when
+							// unused are not candidates. Devices which have enough
+							// capacity, but not enough of it remaining, are treated
+							// like devices which are in use: allocation fails for
+							// them later.
+							success, err := CmpRequestOverCapacity(NewConsumedCapacity(), request.capacities(), device.AllowMultipleAllocations, device.Capacity, nil)
 							if err != nil {
 								alloc.logger.V(7).Info("Skip comparing device capacity request",
 									"device", device, "request", requestData.request.name(), "err", err)
+								alloc.explainer.record(requestKey, device.id, stageExcludedByCapacity)
 								continue
 							}
 							if !success {
 								alloc.logger.V(7).Info("Device capacity not enough", "device", device)
+								alloc.explainer.record(requestKey, device.id, stageExcludedByCapacity)
 								continue
 							}
 						}
@@ -574,29 +800,36 @@ var errAllocationResultMaxSizeExceeded = errors.New("allocation max size exceede
 // goroutine works with it, so there is no need for locking.
 type allocator struct {
 	*Allocator
-	ctx                  context.Context
-	logger               klog.Logger
-	node                 *v1.Node
-	pools                []*Pool
-	deviceMatchesRequest map[matchKey]bool
-	constraints          [][]constraint // one list of constraints per claim
-	// consumedCounters keeps track of the counters consumed by all devices
-	// that are in the process of being allocated.
-	// The keys in the map are ResourceSlice names.
-	consumedCounters map[string]counterSets
-	requestData      map[requestIndices]requestData // one entry per request with no subrequests and one entry per subrequest
-	// allocatingDevices tracks which devices will be newly allocated for a
-	// particular attempt to find a solution. The map is indexed by device
-	// and its values represent for which of a pod's claims the device will
-	// be allocated.
-	// Claims are identified by their index in claimsToAllocate.
-	allocatingDevices map[DeviceID]sets.Set[int]
-	// allocatingCapacity tracks the amount of device capacity that will be newly allocated
-	// for a particular attempt to find a solution.
-	// The map is indexed by device ID, and each value represents the accumulated capacity
-	// requested by all allocations targeting that device.
-	allocatingCapacity ConsumedCapacityCollection
+	// scratch contains the maps which get reused across allocate calls.
+	*scratch
+	ctx         context.Context
+	logger      klog.Logger
+	node        *v1.Node
+	pools       []*Pool
+	constraints [][]constraint // one list of constraints per claim
+	// sharedDevicesInUse gets computed from the allocated state when
+	// needed for the first time.
+	sharedDevicesInUse sets.Set[DeviceID]
 	result             []internalAllocationResult
+	// scorer is nil unless allocating with AllocateWithScore and a scorer.
+	scorer DeviceScorer
+	// score is the sum of the scores of all devices which are
+	// currently being allocated.
+	score int64
+	// explainer is nil unless allocating with AllocateWithExplanation.
+	explainer *explainer
+	// onResult is nil unless allocating with AllocateStream.
+	onResult ResultCallback
+	// numResults counts the results passed to onResult.
+	numResults int
+	// limits is a copy of the Allocator limits, taken at the start.
+	limits Limits
+	// numCombinations counts the allocateOne invocations of this
+	// allocation, for comparison against limits.MaxCombinations.
+	numCombinations int64
+	metrics         allocationMetrics
+	// selectorCache is a copy of the Allocator cache, taken at the start.
+	selectorCache *SelectorCache
 }
 
 // counterSets is a map with the name of counter sets to the counters in
@@ -802,6 +1035,53 @@ func lookupAttribute(device *draapi.Device, deviceID DeviceID, attributeName dra
 	return nil
 }
 
+// newConstraint creates the constraint for the requests.
+// An empty set of request names means "all requests".
+func (alloc *allocator) newConstraint(constraint resourceapi.DeviceConstraint, requestNames sets.Set[string]) (constraint, error) {
+	switch {
+	case constraint.MatchAttribute != nil:
+		matchAttribute := draapi.FullyQualifiedName(*constraint.MatchAttribute)
+		logger := alloc.logger
+		if loggerV := alloc.logger.V(6); loggerV.Enabled() {
+			logger = klog.LoggerWithName(logger, "matchAttributeConstraint")
+			logger = klog.LoggerWithValues(logger, "matchAttribute", matchAttribute)
+		}
+		return &matchAttributeConstraint{
+			logger:        logger,
+			requestNames:  requestNames,
+			attributeName: matchAttribute,
+		}, nil
+	case constraint.DistinctAttribute != nil:
+		distinctAttribute := draapi.FullyQualifiedName(*constraint.DistinctAttribute)
+		logger := alloc.logger
+		if loggerV := alloc.logger.V(6); loggerV.Enabled() {
+			logger = klog.LoggerWithName(logger, "distinctAttributeConstraint")
+			logger = klog.LoggerWithValues(logger, "distinctAttribute", distinctAttribute)
+		}
+		return &distinctAttributeConstraint{
+			logger:        logger,
+			requestNames:  requestNames,
+			attributeName: distinctAttribute,
+			attributes:    make(map[string]draapi.DeviceAttribute),
+		}, nil
+	default:
+		// Unknown constraint type!
+		return nil, errors.New("empty constraint (unsupported constraint type?)")
+	}
+}
+
+// checkLimits returns an error if the context was canceled or
+// the search has to stop because of the limits.
+func (alloc *allocator) checkLimits() error {
+	if alloc.ctx.Err() != nil {
+		return fmt.Errorf("filter operation aborted: %w", context.Cause(alloc.ctx))
+	}
+	if alloc.limits.MaxCombinations > 0 && alloc.numCombinations > alloc.limits.MaxCombinations {
+		return fmt.Errorf("filter operation aborted: %w: explored more than %d combinations", ErrLimitExceeded, alloc.limits.MaxCombinations)
+	}
+	return nil
+}
+
 // allocateOne iterates over all eligible devices (not in use, match selector,
 // satisfy constraints) for a specific required device. It returns true if
 // everything got allocated, an error if allocation needs to stop.
@@ -811,16 +1091,30 @@ func lookupAttribute(device *draapi.Device, deviceID DeviceID, attributeName dra
 // device index without causing infinite recursion.
 func (alloc *allocator) allocateOne(r deviceIndices, allocateSubRequest bool) (bool, error) {
 	alloc.numAllocateOneInvocations.Add(1)
+	alloc.numCombinations++
 
-	if alloc.ctx.Err() != nil {
-		return false, fmt.Errorf("filter operation aborted: %w", context.Cause(alloc.ctx))
+	if err := alloc.checkLimits(); err != nil {
+		return false, err
 	}
 
 	if r.claimIndex >= len(alloc.claimsToAllocate) {
-		// Done! If we were doing scoring, we would compare the current allocation result
-		// against the previous one, keep the best, and continue. Without scoring, we stop
-		// and use the first solution.
-		alloc.logger.V(6).Info("Allocation result found")
+		// Done! Even with a scorer we stop and use the first solution. The scorer
+		// only determines the order in which devices are tried, so the first
+		// solution is one where the preferred devices were picked whenever
+		// possible. Comparing against other solutions would be more accurate,
+		// but also much more expensive.
+		alloc.logger.V(6).Info("Allocation result found", "score", alloc.score)
+		if alloc.onResult != nil {
+			result, err := alloc.buildResult()
+			if err != nil {
+				return false, err
+			}
+			alloc.numResults++
+			if alloc.onResult(result, alloc.score) {
+				// Backtrack to find the next solution.
+				return false, nil
+			}
+		}
 		return true, nil
 	}
 
@@ -944,6 +1238,10 @@ func (alloc *allocator) allocateOne(r deviceIndices, allocateSubRequest bool) (b
 		// For "all" devices we already know which ones we need. We
 		// just need to check whether we can use them.
 		deviceWithID := requestData.allDevices[r.deviceIndex]
+		score, err := alloc.scoreDevice(r, requestData, deviceWithID)
+		if err != nil {
+			return false, err
+		}
 		success, deallocate, err := alloc.allocateDevice(r, deviceWithID, true)
 		if err != nil {
 			return false, err
@@ -954,102 +1252,58 @@ func (alloc *allocator) allocateOne(r deviceIndices, allocateSubRequest bool) (b
 			// get all of them, then there is no solution and we have to stop.
 			return false, nil
 		}
+		alloc.score += score
 		done, err := alloc.allocateOne(deviceIndices{claimIndex: r.claimIndex, requestIndex: r.requestIndex, deviceIndex: r.deviceIndex + 1}, allocateSubRequest)
 		if err != nil || !done {
 			// If we get an error or didn't complete, we need to backtrack. Depending
 			// on the situation we might be able to retry, so we make sure we
 			// deallocate.
 			deallocate()
+			alloc.metrics.backtracks++
+			alloc.score -= score
 			return false, err
 		}
 		return done, nil
 	}
 
 	// We need to find suitable devices.
-	for _, pool := range alloc.pools {
-		// If the pool is not valid, then fail now. It's okay when pools of one driver
-		// are invalid if we allocate from some other pool, but it's not safe to
-		// allocated from an invalid pool.
-		if pool.IsInvalid {
-			return false, fmt.Errorf("pool %s is invalid: %s", pool.Pool, pool.InvalidReason)
-		}
-		for _, slice := range pool.Slices {
-			for deviceIndex := range slice.Spec.Devices {
-				deviceID := DeviceID{Driver: pool.Driver, Pool: pool.Pool, Device: slice.Spec.Devices[deviceIndex].Name}
-
-				// Checking for "in use" is cheap and thus gets done first.
-				if request.adminAccess() && alloc.allocatingDeviceForClaim(deviceID, r.claimIndex) {
-					alloc.logger.V(7).Info("Device in use in same claim", "device", deviceID)
-					continue
-				}
-				if !request.adminAccess() && alloc.deviceInUse(deviceID) {
-					alloc.logger.V(7).Info("Device in use", "device", deviceID)
-					continue
-				}
-
-				// Next check selectors.
-				requestKey := requestIndices{claimIndex: r.claimIndex, requestIndex: r.requestIndex, subRequestIndex: r.subRequestIndex}
-				selectable, err := alloc.isSelectable(requestKey, requestData, slice, deviceIndex)
-				if err != nil {
-					return false, err
-				}
-				if !selectable {
-					alloc.logger.V(7).Info("Device not selectable", "device", deviceID)
-					continue
-				}
-				if alloc.features.ConsumableCapacity {
-					// Next validate whether resource request over capacity
-					success, err := alloc.CmpRequestOverCapacity(requestData.request, slice, deviceIndex)
-					if err != nil {
-						alloc.logger.V(7).Info("Skip comparing device capacity request",
-							"device", deviceID, "request", requestData.request.name(), "err", err)
-						continue
-					}
-					if !success {
-						alloc.logger.V(7).Info("Device capacity not enough", "device", deviceID)
-						continue
-					}
-				}
-
-				// Finally treat as allocated and move on to the next device.
-				device := deviceWithID{
-					id:     deviceID,
-					Device: &slice.Spec.Devices[deviceIndex],
-					slice:  slice,
-				}
-				allocated, deallocate, err := alloc.allocateDevice(r, device, false)
-				if err != nil {
-					return false, err
-				}
-				if !allocated {
-					// In use or constraint violated...
-					alloc.logger.V(7).Info("Device not usable", "device", deviceID)
-					continue
-				}
-				deviceKey := deviceIndices{
-					claimIndex:      r.claimIndex,
-					requestIndex:    r.requestIndex,
-					subRequestIndex: r.subRequestIndex,
-					deviceIndex:     r.deviceIndex + 1,
-				}
-				done, err := alloc.allocateOne(deviceKey, allocateSubRequest)
-				// If we found a solution, we can stop.
-				if err == nil && done {
-					return done, nil
-				}
+	if alloc.scorer != nil {
+		return alloc.allocateScored(r, requestData, allocateSubRequest)
+	}
+	// If this returns without finding a solution, then there is none.
+	return alloc.suitableDevices(r, requestData, func(device deviceWithID) (bool, error) {
+		return alloc.tryDevice(r, device, 0, allocateSubRequest)
+	})
+}
 
-				// Otherwise we didn't find a solution, and we need to deallocate
-				// so the temporary allocation is correct for trying other devices.
-				deallocate()
+// allocateScored is the variant of the device search in allocateOne which
+// first collects all suitable devices and then tries them in order of
+// decreasing score.
+func (alloc *allocator) allocateScored(r deviceIndices, requestData requestData, allocateSubRequest bool) (bool, error) {
+	type scoredDevice struct {
+		device deviceWithID
+		score  int64
+	}
+	var candidates []scoredDevice
+	if _, err := alloc.suitableDevices(r, requestData, func(device deviceWithID) (bool, error) {
+		score, err := alloc.scoreDevice(r, requestData, device)
+		if err != nil {
+			return false, err
+		}
+		candidates = append(candidates, scoredDevice{device: device, score: score})
+		return false, nil
+	}); err != nil {
+		return false, err
+	}
 
-				if err != nil {
-					// If we hit an error, we return. This might be that we reached
-					// the allocation size limit, and if so, it will be caught further
-					// up the stack and other subrequests will be attempted if there
-					// are any.
-					return false, err
-				}
-			}
+	// Stable sorting keeps the normal order for devices with the same score.
+	slices.SortStableFunc(candidates, func(a, b scoredDevice) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	for _, candidate := range candidates {
+		done, err := alloc.tryDevice(r, candidate.device, candidate.score, allocateSubRequest)
+		if err != nil || done {
+			return done, err
 		}
 	}
 
@@ -1057,16 +1311,144 @@ func (alloc *allocator) allocateOne(r deviceIndices, allocateSubRequest bool) (b
 	return false, nil
 }
 
+// suitableDevice checks whether a device is available and satisfies the request.
+// This is everything that can be checked without tentatively allocating it.
+// Checking the selectors is skipped if the device is already known to be
+// selectable.
+func (alloc *allocator) suitableDevice(r deviceIndices, requestData requestData, pool *Pool, slice *draapi.ResourceSlice, deviceIndex int, selectable bool) (deviceWithID, bool, error) {
+	request := requestData.request
+	deviceID := DeviceID{Driver: pool.Driver, Pool: pool.Pool, Device: slice.Spec.Devices[deviceIndex].Name}
+
+	requestKey := requestIndices{claimIndex: r.claimIndex, requestIndex: r.requestIndex, subRequestIndex: r.subRequestIndex}
+
+	// Checking for "in use" is cheap and thus gets done first.
+	if request.adminAccess() && alloc.allocatingDeviceForClaim(deviceID, r.claimIndex) {
+		alloc.logger.V(7).Info("Device in use in same claim", "device", deviceID)
+		alloc.explainer.record(requestKey, deviceID, stageInUse)
+		return deviceWithID{}, false, nil
+	}
+	if !request.adminAccess() && alloc.deviceInUse(deviceID) {
+		alloc.logger.V(7).Info("Device in use", "device", deviceID)
+		alloc.explainer.record(requestKey, deviceID, stageInUse)
+		return deviceWithID{}, false, nil
+	}
+
+	// Next check selectors.
+	if !selectable {
+		var err error
+		selectable, err = alloc.isSelectable(requestKey, requestData, slice, deviceIndex)
+		if err != nil {
+			return deviceWithID{}, false, err
+		}
+		if !selectable {
+			alloc.logger.V(7).Info("Device not selectable", "device", deviceID)
+			return deviceWithID{}, false, nil
+		}
+	}
+	if alloc.features.ConsumableCapacity && !request.adminAccess() {
+		// Next validate whether resource request over capacity
+		success, err := alloc.CmpRequestOverCapacity(requestData.request, deviceID, &slice.Spec.Devices[deviceIndex])
+		if err != nil {
+			alloc.logger.V(7).Info("Skip comparing device capacity request",
+				"device", deviceID, "request", requestData.request.name(), "err", err)
+			alloc.explainer.record(requestKey, deviceID, stageExcludedByCapacity)
+			return deviceWithID{}, false, nil
+		}
+		if !success {
+			alloc.logger.V(7).Info("Device capacity not enough", "device", deviceID)
+			alloc.explainer.record(requestKey, deviceID, stageExcludedByCapacity)
+			return deviceWithID{}, false, nil
+		}
+	}
+
+	device := deviceWithID{
+		id:     deviceID,
+		Device: &slice.Spec.Devices[deviceIndex],
+		slice:  slice,
+	}
+	return device, true, nil
+}
+
+// tryDevice treats the device as allocated and moves on to the next device.
+// It returns true if everything got allocated, an error if allocation needs
+// to stop. Otherwise the device is not allocated anymore when it returns.
+func (alloc *allocator) tryDevice(r deviceIndices, device deviceWithID, score int64, allocateSubRequest bool) (bool, error) {
+	allocated, deallocate, err := alloc.allocateDevice(r, device, false)
+	if err != nil {
+		return false, err
+	}
+	if !allocated {
+		// In use or constraint violated...
+		alloc.logger.V(7).Info("Device not usable", "device", device.id)
+		return false, nil
+	}
+	alloc.score += score
+	deviceKey := deviceIndices{
+		claimIndex:      r.claimIndex,
+		requestIndex:    r.requestIndex,
+		subRequestIndex: r.subRequestIndex,
+		deviceIndex:     r.deviceIndex + 1,
+	}
+	done, err := alloc.allocateOne(deviceKey, allocateSubRequest)
+	// If we found a solution, we can stop.
+	if err == nil && done {
+		return done, nil
+	}
+
+	// Otherwise we didn't find a solution, and we need to deallocate
+	// so the temporary allocation is correct for trying other devices.
+	deallocate()
+	alloc.metrics.backtracks++
+	alloc.score -= score
+
+	// If we hit an error, we return. This might be that we reached
+	// the allocation size limit, and if so, it will be caught further
+	// up the stack and other subrequests will be attempted if there
+	// are any.
+	return false, err
+}
+
+// scoreDevice returns the score of a suitable device for a request.
+// It is zero without a scorer.
+func (alloc *allocator) scoreDevice(r deviceIndices, requestData requestData, device deviceWithID) (int64, error) {
+	if alloc.scorer == nil {
+		return 0, nil
+	}
+	key := matchKey{DeviceID: device.id, requestIndices: requestIndices{claimIndex: r.claimIndex, requestIndex: r.requestIndex, subRequestIndex: r.subRequestIndex}}
+	if score, ok := alloc.deviceScores[key]; ok {
+		return score, nil
+	}
+
+	claim := alloc.claimsToAllocate[r.claimIndex]
+	requestName := requestData.request.name()
+	if requestData.parentRequest != nil {
+		requestName = requestData.parentRequest.name() + "/" + requestName
+	}
+	var d resourceapi.Device
+	if err := draapi.Convert_api_Device_To_v1_Device(device.Device, &d, nil); err != nil {
+		return 0, fmt.Errorf("convert Device: %w", err)
+	}
+	score, err := alloc.scorer.ScoreDevice(alloc.ctx, claim, requestName, device.id, &d)
+	if err != nil {
+		return 0, fmt.Errorf("claim %s, request %s: score device %s: %w", klog.KObj(claim), requestName, device.id, err)
+	}
+	alloc.logger.V(7).Info("Device score", "device", device.id, "request", requestName, "score", score)
+	alloc.deviceScores[key] = score
+	return score, nil
+}
+
 // isSelectable checks whether a device satisfies the request and class selectors.
 func (alloc *allocator) isSelectable(r requestIndices, requestData requestData, slice *draapi.ResourceSlice, deviceIndex int) (bool, error) {
 	device := &slice.Spec.Devices[deviceIndex]
+	deviceID := DeviceID{Driver: slice.Spec.Driver, Pool: slice.Spec.Pool.Name, Device: slice.Spec.Devices[deviceIndex].Name}
+	alloc.metrics.devices[requestData.className()]++
 	if (!alloc.features.DeviceBinding || !alloc.features.DeviceStatus) &&
 		len(device.BindingConditions) > 0 {
 		// Devices with binding conditions are not supported, feature is off.
+		alloc.explainer.record(r, deviceID, stageUnsupported)
 		return false, nil
 	}
 
-	deviceID := DeviceID{Driver: slice.Spec.Driver, Pool: slice.Spec.Pool.Name, Device: slice.Spec.Devices[deviceIndex].Name}
 	matchKey := matchKey{DeviceID: deviceID, requestIndices: r}
 	if matches, ok := alloc.deviceMatchesRequest[matchKey]; ok {
 		// No need to check again.
@@ -1074,23 +1456,25 @@ func (alloc *allocator) isSelectable(r requestIndices, requestData requestData,
 	}
 
 	if requestData.class != nil {
-		match, err := alloc.selectorsMatch(r, device, deviceID, requestData.class, requestData.class.Spec.Selectors)
+		match, err := alloc.selectorsMatch(r, slice, device, deviceID, requestData.class, requestData.class.Spec.Selectors)
 		if err != nil {
 			return false, err
 		}
 		if !match {
 			alloc.deviceMatchesRequest[matchKey] = false
+			alloc.explainer.record(r, deviceID, stageNotMatchingClass)
 			return false, nil
 		}
 	}
 
 	request := requestData.request
-	match, err := alloc.selectorsMatch(r, device, deviceID, nil, request.selectors())
+	match, err := alloc.selectorsMatch(r, slice, device, deviceID, nil, request.selectors())
 	if err != nil {
 		return false, err
 	}
 	if !match {
 		alloc.deviceMatchesRequest[matchKey] = false
+		alloc.explainer.record(r, deviceID, stageExcludedBySelectors)
 		return false, nil
 	}
 
@@ -1101,6 +1485,7 @@ func (alloc *allocator) isSelectable(r requestIndices, requestData requestData,
 		}
 		if !matches {
 			alloc.deviceMatchesRequest[matchKey] = false
+			alloc.explainer.record(r, deviceID, stageExcludedByNodeSelection)
 			return false, nil
 		}
 	}
@@ -1112,19 +1497,27 @@ func (alloc *allocator) isSelectable(r requestIndices, requestData requestData,
 
 // CmpRequestOverCapacity checks whether a device with remaining resources is consumable by the request.
 // Return true if success.
-func (alloc *allocator) CmpRequestOverCapacity(request requestAccessor, slice *draapi.ResourceSlice, deviceIndex int) (bool, error) {
-	deviceID := DeviceID{Driver: slice.Spec.Driver, Pool: slice.Spec.Pool.Name, Device: slice.Spec.Devices[deviceIndex].Name}
+func (alloc *allocator) CmpRequestOverCapacity(request requestAccessor, deviceID DeviceID, device *draapi.Device) (bool, error) {
 	allocatingCapacity := alloc.allocatingCapacity[deviceID]
-	allowMultipleAllocations := slice.Spec.Devices[deviceIndex].AllowMultipleAllocations
-	capacities := slice.Spec.Devices[deviceIndex].Capacity
+	allowMultipleAllocations := device.AllowMultipleAllocations
+	capacities := device.Capacity
 	if allocatedCapacity, found := alloc.allocatedState.AggregatedCapacity[deviceID]; found {
 		return CmpRequestOverCapacity(allocatedCapacity, request.capacities(), allowMultipleAllocations, capacities, allocatingCapacity)
 	}
 	return CmpRequestOverCapacity(NewConsumedCapacity(), request.capacities(), allowMultipleAllocations, capacities, allocatingCapacity)
 }
 
-func (alloc *allocator) selectorsMatch(r requestIndices, device *draapi.Device, deviceID DeviceID, class *resourceapi.DeviceClass, selectors []resourceapi.DeviceSelector) (bool, error) {
+func (alloc *allocator) selectorsMatch(r requestIndices, slice *draapi.ResourceSlice, device *draapi.Device, deviceID DeviceID, class *resourceapi.DeviceClass, selectors []resourceapi.DeviceSelector) (bool, error) {
 	for i, selector := range selectors {
+		if alloc.selectorCache != nil {
+			if matches, found := alloc.selectorCache.Get(slice.Name, slice.ResourceVersion, device.Name.String(), device.Taints, selector.CEL.Expression); found {
+				if !matches {
+					return false, nil
+				}
+				continue
+			}
+		}
+
 		expr := alloc.celCache.GetOrCompile(selector.CEL.Expression)
 		if expr.Error != nil {
 			// Could happen if some future apiserver accepted some
@@ -1144,7 +1537,8 @@ func (alloc *allocator) selectorsMatch(r requestIndices, device *draapi.Device,
 		if err := draapi.Convert_api_Device_To_v1_Device(device, &d, nil); err != nil {
 			return false, fmt.Errorf("convert Device: %w", err)
 		}
-		matches, details, err := expr.DeviceMatches(alloc.ctx, cel.Device{Driver: deviceID.Driver.String(), AllowMultipleAllocations: d.AllowMultipleAllocations, Attributes: d.Attributes, Capacity: d.Capacity})
+		alloc.metrics.celEvaluations[alloc.requestData[r].className()]++
+		matches, details, err := expr.DeviceMatches(alloc.ctx, cel.Device{Driver: deviceID.Driver.String(), AllowMultipleAllocations: d.AllowMultipleAllocations, Attributes: d.Attributes, Capacity: d.Capacity, Taints: d.Taints})
 		if class != nil {
 			alloc.logger.V(7).Info("CEL result", "device", deviceID, "class", klog.KObj(class), "selector", i, "expression", selector.CEL.Expression, "matches", matches, "actualCost", ptr.Deref(details.ActualCost(), 0), "err", err)
 		} else {
@@ -1158,6 +1552,9 @@ func (alloc *allocator) selectorsMatch(r requestIndices, device *draapi.Device,
 			}
 			return false, fmt.Errorf("claim %s: selector #%d: CEL runtime error: %w", klog.KObj(alloc.claimsToAllocate[r.claimIndex]), i, err)
 		}
+		if alloc.selectorCache != nil {
+			alloc.selectorCache.Set(slice.Name, slice.ResourceVersion, device.Name.String(), device.Taints, selector.CEL.Expression, matches)
+		}
 		if !matches {
 			return false, nil
 		}
@@ -1188,10 +1585,12 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 	}
 	if !allowMultipleAllocations && request.adminAccess() && alloc.allocatingDeviceForClaim(device.id, r.claimIndex) {
 		alloc.logger.V(7).Info("Device in use in same claim", "device", device.id)
+		alloc.explainer.record(requestKey, device.id, stageInUse)
 		return false, nil, nil
 	}
 	if !request.adminAccess() && alloc.deviceInUse(device.id) {
 		alloc.logger.V(7).Info("Device in use", "device", device.id)
+		alloc.explainer.record(requestKey, device.id, stageInUse)
 		return false, nil, nil
 	}
 
@@ -1199,11 +1598,50 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 	// is not enabled.
 	if !alloc.features.PartitionableDevices && len(device.ConsumesCounters) > 0 {
 		alloc.logger.V(7).Info("Device consumes counters, but the partitionable devices feature is not enabled", "device", device.id)
+		alloc.explainer.record(requestKey, device.id, stageUnsupported)
+		return false, nil, nil
+	}
+
+	// Might be tainted, in which case the taint has to be tolerated.
+	// The check is skipped if the feature is disabled.
+	if alloc.features.DeviceTaints && !allTaintsTolerated(device.Device, request) {
+		alloc.explainer.record(requestKey, device.id, stageExcludedByTaints)
 		return false, nil, nil
 	}
 
+	// Validate whether resource request over capacity. This must be done before
+	// changing any state because nothing gets rolled back when returning here.
+	// Admin access does not consume capacity, so it is also not limited by it.
+	if alloc.features.ConsumableCapacity && !request.adminAccess() {
+		success, err := alloc.CmpRequestOverCapacity(requestData.request, device.id, device.Device)
+		if err != nil {
+			alloc.logger.V(7).Info("Failed to compare device capacity request",
+				"device", device, "request", requestData.request.name(), "err", err)
+			alloc.explainer.record(requestKey, device.id, stageExcludedByCapacity)
+			return false, nil, nil
+		}
+		if !success {
+			alloc.logger.V(7).Info("Device capacity not enough", "device", device)
+			alloc.explainer.record(requestKey, device.id, stageExcludedByCapacity)
+			return false, nil, nil
+		}
+	}
+
+	// A device which allows multiple allocations consumes its counters
+	// only once, regardless of how often it gets allocated. If it was
+	// allocated before, its counters are already accounted for in the
+	// available counters.
+	//
+	// Admin access does not consume counters either. The device is
+	// typically in use already, in which case its counters are consumed
+	// by that other allocation.
+	consumeCounters := len(device.ConsumesCounters) > 0 && !request.adminAccess()
+	if consumeCounters && allowMultipleAllocations {
+		consumeCounters = alloc.sharedDeviceUsers[device.id] == 0 && !alloc.sharedDeviceAllocated(device.id)
+	}
+
 	// The API validation logic has checked the ConsumesCounters referred should exist inside SharedCounters.
-	if len(device.ConsumesCounters) > 0 {
+	if consumeCounters {
 		// If a device consumes counters from a counter set, verify that
 		// there is sufficient counters available.
 		ok, err := alloc.checkAvailableCounters(device)
@@ -1212,6 +1650,7 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 		}
 		if !ok {
 			alloc.logger.V(7).Info("Insufficient counters", "device", device.id)
+			alloc.explainer.record(requestKey, device.id, stageExcludedByCapacity)
 			return false, nil, nil
 		}
 	}
@@ -1227,12 +1666,6 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 		subRequestName = requestData.request.name()
 	}
 
-	// Might be tainted, in which case the taint has to be tolerated.
-	// The check is skipped if the feature is disabled.
-	if alloc.features.DeviceTaints && !allTaintsTolerated(device.Device, request) {
-		return false, nil, nil
-	}
-
 	// It's available. Now check constraints.
 	for i, constraint := range alloc.constraints[r.claimIndex] {
 		added := constraint.add(baseRequestName, subRequestName, device.Device, device.id)
@@ -1243,10 +1676,14 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 				return false, nil, fmt.Errorf("claim %s, request %s: cannot add device %s because a claim constraint would not be satisfied", klog.KObj(claim), request.name(), device.id)
 			}
 
-			// Roll back for all previous constraints before we return.
+			// Roll back for all previous constraints and the counters before we return.
 			for e := 0; e < i; e++ {
 				alloc.constraints[r.claimIndex][e].remove(baseRequestName, subRequestName, device.Device, device.id)
 			}
+			if consumeCounters {
+				alloc.deallocateCountersForDevice(device)
+			}
+			alloc.explainer.record(requestKey, device.id, stageExcludedByConstraints)
 			return false, nil, nil
 		}
 	}
@@ -1260,24 +1697,15 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 	}
 	if !allowMultipleAllocations {
 		alloc.allocatingDevices[device.id].Insert(r.claimIndex)
+	} else if !request.adminAccess() {
+		alloc.sharedDeviceUsers[device.id]++
 	}
 
 	consumedCapacity := make(map[resourceapi.QualifiedName]resource.Quantity, 0)
 	var shareID *types.UID
 	if alloc.features.ConsumableCapacity {
-		// Validate whether resource request over capacity
-		success, err := alloc.CmpRequestOverCapacity(requestData.request, device.slice, r.deviceIndex)
-		if err != nil {
-			alloc.logger.V(7).Info("Failed to compare device capacity request",
-				"device", device, "request", requestData.request.name(), "err", err)
-			return false, nil, nil
-		}
-		if !success {
-			alloc.logger.V(7).Info("Device capacity not enough", "device", device)
-			return false, nil, nil
-		}
-
-		if allowMultipleAllocations {
+		// Admin access neither consumes capacity nor gets a share.
+		if allowMultipleAllocations && !request.adminAccess() {
 			convertedCapacities := make(map[resourceapi.QualifiedName]resourceapi.DeviceCapacity)
 			for key, value := range device.Capacity {
 				var convertedCapacity resourceapi.DeviceCapacity
@@ -1312,6 +1740,7 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 	}
 	previousNumResults := len(alloc.result[r.claimIndex].devices)
 	alloc.result[r.claimIndex].devices = append(alloc.result[r.claimIndex].devices, result)
+	alloc.explainer.record(requestKey, device.id, stageAvailable)
 
 	return true, func() {
 		for _, constraint := range alloc.constraints[r.claimIndex] {
@@ -1323,11 +1752,14 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 			if requestedResource != nil {
 				alloc.allocatingCapacity.Remove(NewDeviceConsumedCapacity(device.id, requestedResource))
 			}
+			if !request.adminAccess() {
+				alloc.sharedDeviceUsers[device.id]--
+			}
 		} else {
 			alloc.allocatingDevices[device.id].Delete(r.claimIndex)
-			if alloc.features.PartitionableDevices && len(device.ConsumesCounters) > 0 {
-				alloc.deallocateCountersForDevice(device)
-			}
+		}
+		if consumeCounters {
+			alloc.deallocateCountersForDevice(device)
 		}
 		// Truncate, but keep the underlying slice.
 		alloc.result[r.claimIndex].devices = alloc.result[r.claimIndex].devices[:previousNumResults]
@@ -1345,6 +1777,13 @@ func allTaintsTolerated(device *draapi.Device, request requestAccessor) bool {
 }
 
 func taintTolerated(taint resourceapi.DeviceTaint, request requestAccessor) bool {
+	switch taint.Effect {
+	case resourceapi.DeviceTaintEffectNoSchedule, resourceapi.DeviceTaintEffectNoExecute:
+	default:
+		// Other effects, for example those added in a future API
+		// version, do not prevent allocation.
+		return true
+	}
 	for _, toleration := range request.tolerations() {
 		if resourceclaim.ToleratesTaint(toleration, taint) {
 			return true
@@ -1393,7 +1832,7 @@ func (alloc *allocator) checkAvailableCounters(device deviceWithID) (bool, error
 			}
 			// Devices that aren't allocated doesn't consume any counters, so we don't
 			// need to consider them.
-			if !alloc.allocatedState.AllocatedDevices.Has(deviceID) {
+			if !alloc.allocatedState.AllocatedDevices.Has(deviceID) && !alloc.sharedDeviceAllocated(deviceID) {
 				continue
 			}
 			for _, deviceCounterConsumption := range device.ConsumesCounters {
@@ -1469,6 +1908,21 @@ func (alloc *allocator) deviceInUse(deviceID DeviceID) bool {
 	return alloc.allocatedState.AllocatedDevices.Has(deviceID) || alloc.allocatingDeviceForAnyClaim(deviceID)
 }
 
+// sharedDeviceAllocated checks whether a device which allows multiple
+// allocations is allocated at least once.
+func (alloc *allocator) sharedDeviceAllocated(deviceID DeviceID) bool {
+	if alloc.sharedDevicesInUse == nil {
+		alloc.sharedDevicesInUse = make(sets.Set[DeviceID], len(alloc.allocatedState.AllocatedSharedDeviceIDs))
+		for id := range alloc.allocatedState.AllocatedSharedDeviceIDs {
+			alloc.sharedDevicesInUse.Insert(DeviceID{Driver: id.Driver, Pool: id.Pool, Device: id.Device})
+		}
+		for id := range alloc.allocatedState.AggregatedCapacity {
+			alloc.sharedDevicesInUse.Insert(id)
+		}
+	}
+	return alloc.sharedDevicesInUse.Has(deviceID)
+}
+
 func (alloc *allocator) allocatingDeviceForAnyClaim(deviceID DeviceID) bool {
 	return alloc.allocatingDevices[deviceID].Len() > 0
 }
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"fmt"
+
+	draapi "k8s.io/dynamic-resource-allocation/api"
+)
+
+// candidateDevice is a device which may be suitable for a request.
+// An entry without a slice stands for an invalid pool.
+type candidateDevice struct {
+	pool        *Pool
+	slice       *draapi.ResourceSlice
+	deviceIndex int
+	// selectable is true if the device is known to match the request.
+	// Otherwise suitableDevice needs to check that.
+	selectable bool
+}
+
+// candidateIndex lists the devices which may be suitable for a request,
+// in the same order as the pools, slices and devices. Which devices match
+// the class and selectors of a request does not change during allocate,
+// so devices which do not match can be skipped when the search needs
+// another device for the same request. In clusters with many drivers,
+// that is most of them.
+//
+// The index gets extended while searching, so devices are only checked
+// when the search gets to them, as without the index.
+type candidateIndex struct {
+	devices []candidateDevice
+	// poolIndex, sliceIndex and deviceIndex point to the next
+	// device which needs to be checked.
+	poolIndex, sliceIndex, deviceIndex int
+}
+
+// suitableDevices calls the callback for each device which is suitable for
+// the request, until the callback returns true or an error.
+func (alloc *allocator) suitableDevices(r deviceIndices, requestData requestData, callback func(device deviceWithID) (bool, error)) (bool, error) {
+	if alloc.explainer != nil {
+		// Explanations need to know for all devices why they are not
+		// suitable, in the order in which the checks are done.
+		return alloc.scanDevices(r, requestData, callback)
+	}
+
+	requestKey := requestIndices{claimIndex: r.claimIndex, requestIndex: r.requestIndex, subRequestIndex: r.subRequestIndex}
+	index := alloc.candidates[requestKey]
+	if index == nil {
+		index = &candidateIndex{}
+		alloc.candidates[requestKey] = index
+	}
+	for i := 0; ; i++ {
+		if i == len(index.devices) {
+			more, err := alloc.extendCandidates(index, r, requestKey, requestData)
+			if err != nil {
+				return false, err
+			}
+			if !more {
+				return false, nil
+			}
+		}
+		candidate := index.devices[i]
+		if candidate.slice == nil {
+			pool := candidate.pool
+			return false, fmt.Errorf("pool %s is invalid: %s", pool.Pool, pool.InvalidReason)
+		}
+		device, suitable, err := alloc.suitableDevice(r, requestData, candidate.pool, candidate.slice, candidate.deviceIndex, candidate.selectable)
+		if err != nil {
+			return false, err
+		}
+		if !suitable {
+			continue
+		}
+		done, err := callback(device)
+		if err != nil || done {
+			return done, err
+		}
+	}
+}
+
+// extendCandidates checks devices until it finds one more candidate.
+// It returns false if there are no more devices.
+func (alloc *allocator) extendCandidates(index *candidateIndex, r deviceIndices, requestKey requestIndices, requestData requestData) (bool, error) {
+	request := requestData.request
+	for ; index.poolIndex < len(alloc.pools); index.poolIndex, index.sliceIndex = index.poolIndex+1, 0 {
+		pool := alloc.pools[index.poolIndex]
+		if pool.IsInvalid {
+			index.devices = append(index.devices, candidateDevice{pool: pool})
+			index.poolIndex++
+			return true, nil
+		}
+		for ; index.sliceIndex < len(pool.Slices); index.sliceIndex, index.deviceIndex = index.sliceIndex+1, 0 {
+			slice := pool.Slices[index.sliceIndex]
+			if index.deviceIndex == 0 {
+				// Checking all devices can take a while in large pools.
+				if err := alloc.checkLimits(); err != nil {
+					return false, err
+				}
+			}
+			for index.deviceIndex < len(slice.Spec.Devices) {
+				deviceIndex := index.deviceIndex
+				index.deviceIndex++
+				candidate := candidateDevice{pool: pool, slice: slice, deviceIndex: deviceIndex}
+				deviceID := DeviceID{Driver: pool.Driver, Pool: pool.Pool, Device: slice.Spec.Devices[deviceIndex].Name}
+				inUse := alloc.deviceInUse(deviceID)
+				if request.adminAccess() {
+					inUse = alloc.allocatingDeviceForClaim(deviceID, r.claimIndex)
+				}
+				if !inUse {
+					// Devices which are in use get checked later when
+					// they are not in use anymore, like without the index.
+					// Errors get reported by suitableDevice.
+					selectable, err := alloc.isSelectable(requestKey, requestData, slice, deviceIndex)
+					if err == nil && !selectable {
+						continue
+					}
+					candidate.selectable = err == nil
+				}
+				index.devices = append(index.devices, candidate)
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// scanDevices is the variant of suitableDevices which checks all devices.
+func (alloc *allocator) scanDevices(r deviceIndices, requestData requestData, callback func(device deviceWithID) (bool, error)) (bool, error) {
+	for _, pool := range alloc.pools {
+		// If the pool is not valid, then fail now. It's okay when pools of one driver
+		// are invalid if we allocate from some other pool, but it's not safe to
+		// allocated from an invalid pool.
+		if pool.IsInvalid {
+			return false, fmt.Errorf("pool %s is invalid: %s", pool.Pool, pool.InvalidReason)
+		}
+		for _, slice := range pool.Slices {
+			// Checking all devices can take a while in large pools.
+			if err := alloc.checkLimits(); err != nil {
+				return false, err
+			}
+			for deviceIndex := range slice.Spec.Devices {
+				device, suitable, err := alloc.suitableDevice(r, requestData, pool, slice, deviceIndex, false)
+				if err != nil {
+					return false, err
+				}
+				if !suitable {
+					continue
+				}
+				done, err := callback(device)
+				if err != nil || done {
+					return done, err
+				}
+			}
+		}
+	}
+	return false, nil
+}
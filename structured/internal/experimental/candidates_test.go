@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+// manyDriversTestData returns slices with devices from many drivers and a
+// claim which needs several devices of the last driver.
+func manyDriversTestData(numDrivers, numDevices int, count int64) (*resourceapi.DeviceClass, []*resourceapi.ResourceSlice, *resourceapi.ResourceClaim) {
+	var slices []*resourceapi.ResourceSlice
+	for i := range numDrivers {
+		slice := &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("slice-%d", i), ResourceVersion: "1"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   fmt.Sprintf("driver-%d.example.com", i),
+				Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+				AllNodes: ptr.To(true),
+			},
+		}
+		for j := range numDevices {
+			slice.Spec.Devices = append(slice.Spec.Devices, resourceapi.Device{Name: fmt.Sprintf("device-%d", j)})
+		}
+		slices = append(slices, slice)
+	}
+	class := &resourceapi.DeviceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "class"},
+		Spec: resourceapi.DeviceClassSpec{
+			Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: fmt.Sprintf(`device.driver == "driver-%d.example.com"`, numDrivers-1)}}},
+		},
+	}
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: class.Name,
+						AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+						Count:           count,
+					},
+				}},
+			},
+		},
+	}
+	return class, slices, claim
+}
+
+func TestCandidates(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class, slices, claim := manyDriversTestData(3, 4, 3)
+
+	allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, slices, cel.NewCache(1, cel.Features{}))
+	g.Expect(err).ToNot(HaveOccurred())
+	results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(1))
+	var devices []string
+	for _, result := range results[0].Devices.Results {
+		g.Expect(result.Driver).To(Equal("driver-2.example.com"))
+		devices = append(devices, result.Device)
+	}
+	g.Expect(devices).To(Equal([]string{"device-0", "device-1", "device-2"}))
+
+	// One more than available: all permutations get tried.
+	claim.Spec.Devices.Requests[0].Exactly.Count = 5
+	results, err = allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(BeNil())
+}
+
+// BenchmarkCandidates allocates several devices of one driver in a
+// cluster with many drivers.
+func BenchmarkCandidates(b *testing.B) {
+	ctx := klog.NewContext(b.Context(), klog.Background().V(0))
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class, slices, claim := manyDriversTestData(20, 500, 8)
+	allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, slices, cel.NewCache(10, cel.Features{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	cache := internal.NewSelectorCache()
+	allocator.SetSelectorCache(cache)
+	for b.Loop() {
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(results) != 1 {
+			b.Fatal("expected one allocation result")
+		}
+	}
+}
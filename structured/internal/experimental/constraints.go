@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	resourceapi "k8s.io/api/resource/v1"
+	draapi "k8s.io/dynamic-resource-allocation/api"
+)
+
+// pluggedConstraint adapts a Constraint from a ConstraintProvider to the
+// internal constraint interface.
+type pluggedConstraint struct {
+	constraint Constraint
+	// devices caches the converted devices, Add and Remove
+	// get called with the same device.
+	devices map[DeviceID]*resourceapi.Device
+}
+
+func newPluggedConstraint(constraint Constraint) *pluggedConstraint {
+	return &pluggedConstraint{
+		constraint: constraint,
+		devices:    make(map[DeviceID]*resourceapi.Device),
+	}
+}
+
+func (p *pluggedConstraint) add(requestName, subRequestName string, device *draapi.Device, deviceID DeviceID) bool {
+	d, ok := p.devices[deviceID]
+	if !ok {
+		d = &resourceapi.Device{}
+		if err := draapi.Convert_api_Device_To_v1_Device(device, d, nil); err != nil {
+			// Cannot happen in practice, the conversion never fails.
+			return false
+		}
+		p.devices[deviceID] = d
+	}
+	return p.constraint.Add(fullRequestName(requestName, subRequestName), deviceID, d)
+}
+
+func (p *pluggedConstraint) remove(requestName, subRequestName string, device *draapi.Device, deviceID DeviceID) {
+	p.constraint.Remove(fullRequestName(requestName, subRequestName), deviceID, p.devices[deviceID])
+}
+
+func fullRequestName(requestName, subRequestName string) string {
+	if subRequestName == "" {
+		return requestName
+	}
+	return requestName + "/" + subRequestName
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+)
+
+type Explanation = internal.Explanation
+type RequestExplanation = internal.RequestExplanation
+
+// deviceStage describes how far a device got in the checks for a request.
+// The values are sorted in the order in which the checks are done.
+type deviceStage int
+
+const (
+	stageNone deviceStage = iota
+	stageInUse
+	stageUnsupported
+	stageNotMatchingClass
+	stageExcludedBySelectors
+	stageExcludedByNodeSelection
+	stageExcludedByCapacity
+	stageExcludedByTaints
+	stageExcludedByConstraints
+	stageAvailable
+)
+
+// explainer records why devices were not picked. The same device
+// may be checked several times for a request while backtracking,
+// only the furthest stage is kept.
+type explainer struct {
+	// requests is in the order in which requests were first considered.
+	requests []requestIndices
+	stages   map[requestIndices]map[DeviceID]deviceStage
+	// explanation gets set when allocation fails.
+	explanation *Explanation
+}
+
+func newExplainer() *explainer {
+	return &explainer{
+		stages: make(map[requestIndices]map[DeviceID]deviceStage),
+	}
+}
+
+// record is a no-op if the explainer is nil, so it can be called unconditionally.
+func (e *explainer) record(r requestIndices, deviceID DeviceID, stage deviceStage) {
+	if e == nil {
+		return
+	}
+	stages := e.stages[r]
+	if stages == nil {
+		stages = make(map[DeviceID]deviceStage)
+		e.stages[r] = stages
+		e.requests = append(e.requests, r)
+	}
+	if stage > stages[deviceID] {
+		stages[deviceID] = stage
+	}
+}
+
+// explain summarizes the recorded information.
+func (e *explainer) explain(alloc *allocator) *Explanation {
+	explanation := &Explanation{
+		Requests: make([]RequestExplanation, 0, len(e.requests)),
+	}
+	for _, r := range e.requests {
+		claim := alloc.claimsToAllocate[r.claimIndex]
+		requestData := alloc.requestData[r]
+		requestName := requestData.request.name()
+		if requestData.parentRequest != nil {
+			requestName = requestData.parentRequest.name() + "/" + requestName
+		}
+		request := RequestExplanation{
+			Claim:            types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name},
+			Request:          requestName,
+			NumDevicesNeeded: requestData.numDevices,
+			NumDevices:       len(e.stages[r]),
+		}
+		for _, stage := range e.stages[r] {
+			switch stage {
+			case stageInUse:
+				request.NumInUse++
+			case stageUnsupported:
+				request.NumUnsupported++
+			case stageNotMatchingClass:
+				request.NumNotMatchingClass++
+			case stageExcludedBySelectors:
+				request.NumExcludedBySelectors++
+			case stageExcludedByNodeSelection:
+				request.NumExcludedByNodeSelection++
+			case stageExcludedByCapacity:
+				request.NumExcludedByCapacity++
+			case stageExcludedByTaints:
+				request.NumExcludedByTaints++
+			case stageExcludedByConstraints:
+				request.NumExcludedByConstraints++
+			case stageAvailable:
+				request.NumAvailable++
+			}
+		}
+		explanation.Requests = append(explanation.Requests, request)
+	}
+	return explanation
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestAllocateWithExplanation(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "class"},
+		Spec: resourceapi.DeviceClassSpec{
+			Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `device.attributes["driver-a"].model != "b"`}}},
+		},
+	}
+	model := func(name, model string) resourceapi.Device {
+		return resourceapi.Device{
+			Name:       name,
+			Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": {StringValue: ptr.To(model)}},
+		}
+	}
+	tainted := model("device-4", "a")
+	tainted.Taints = []resourceapi.DeviceTaint{{Key: "example.com/broken", Effect: resourceapi.DeviceTaintEffectNoSchedule}}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			Devices: []resourceapi.Device{
+				model("device-1", "a"),
+				model("device-2", "b"),
+				model("device-3", "c"),
+				tainted,
+				model("device-5", "a"),
+			},
+		},
+	}
+	claim := func(count int64) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           count,
+							Selectors:       []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `device.attributes["driver-a"].model != "c"`}}},
+						},
+					}},
+				},
+			},
+		}
+	}
+	allocatedState := AllocatedState{AllocatedDevices: sets.New(MakeDeviceID(driverA, pool1, "device-1"))}
+
+	t.Run("failure", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		g := NewWithT(t)
+		allocator, err := NewAllocator(ctx, Features{DeviceTaints: true}, allocatedState, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		results, explanation, err := allocator.AllocateWithExplanation(ctx, node, []*resourceapi.ResourceClaim{claim(2)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(BeNil())
+		g.Expect(explanation).To(Equal(&Explanation{
+			Requests: []RequestExplanation{{
+				Claim:                  types.NamespacedName{Namespace: "default", Name: "claim"},
+				Request:                "req",
+				NumDevicesNeeded:       2,
+				NumDevices:             5,
+				NumInUse:               1,
+				NumNotMatchingClass:    1,
+				NumExcludedBySelectors: 1,
+				NumExcludedByTaints:    1,
+				NumAvailable:           1,
+			}},
+		}))
+		g.Expect(explanation.String()).To(Equal("claim default/claim, request req: 1 of 5 devices available, 2 needed (1 in use, 1 not matching the class, 1 excluded by selectors, 1 with taints that are not tolerated)"))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		g := NewWithT(t)
+		allocator, err := NewAllocator(ctx, Features{DeviceTaints: true}, allocatedState, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		results, explanation, err := allocator.AllocateWithExplanation(ctx, node, []*resourceapi.ResourceClaim{claim(1)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(explanation).To(BeNil())
+	})
+}
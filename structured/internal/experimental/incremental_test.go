@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestAssume(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			Devices:  []resourceapi.Device{{Name: "device-1"}, {Name: "device-2"}, {Name: "device-3"}},
+		},
+	}
+	claim := func(name string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           1,
+						},
+					}},
+				},
+			},
+		}
+	}
+	allocate := func(allocator *Allocator, claim *resourceapi.ResourceClaim) []resourceapi.AllocationResult {
+		t.Helper()
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		g.Expect(err).ToNot(HaveOccurred())
+		return results
+	}
+	device := func(results []resourceapi.AllocationResult) string {
+		t.Helper()
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(results[0].Devices.Results).To(HaveLen(1))
+		return results[0].Devices.Results[0].Device
+	}
+
+	allocatedState := AllocatedState{AllocatedDevices: sets.New(MakeDeviceID(driverA, pool1, "device-1"))}
+	allocator, err := NewAllocator(ctx, Features{}, allocatedState, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	resultsA := allocate(allocator, claim("a"))
+	g.Expect(device(resultsA)).To(Equal("device-2"))
+	rollbackA := allocator.Assume(resultsA)
+
+	resultsB := allocate(allocator, claim("b"))
+	g.Expect(device(resultsB)).To(Equal("device-3"))
+	rollbackB := allocator.Assume(resultsB)
+
+	g.Expect(allocate(allocator, claim("c"))).To(BeNil(), "all devices in use")
+
+	rollbackA()
+	g.Expect(device(allocate(allocator, claim("c")))).To(Equal("device-2"), "device of claim a available again")
+
+	rollbackB()
+	g.Expect(allocatedState.AllocatedDevices.UnsortedList()).To(ConsistOf(MakeDeviceID(driverA, pool1, "device-1")), "caller's state unchanged")
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestSetLimits(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+
+	// One more request than there are devices: the search has to try
+	// all permutations before it can give up.
+	const numDevices = 6
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+		},
+	}
+	for i := range numDevices {
+		slice.Spec.Devices = append(slice.Spec.Devices, resourceapi.Device{Name: fmt.Sprintf("device-%d", i)})
+	}
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+	}
+	for i := range numDevices + 1 {
+		claim.Spec.Devices.Requests = append(claim.Spec.Devices.Requests, resourceapi.DeviceRequest{
+			Name: fmt.Sprintf("req-%d", i),
+			Exactly: &resourceapi.ExactDeviceRequest{
+				DeviceClassName: class.Name,
+				AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+				Count:           1,
+			},
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		limits      Limits
+		cancel      bool
+		expectError string
+	}{
+		"no-limits": {},
+		"max-combinations": {
+			limits:      Limits{MaxCombinations: 100},
+			expectError: "filter operation aborted: allocation limit exceeded: explored more than 100 combinations",
+		},
+		"high-max-combinations": {
+			limits: Limits{MaxCombinations: 1000000},
+		},
+		"timeout": {
+			limits:      Limits{Timeout: time.Nanosecond},
+			expectError: "filter operation aborted: allocation limit exceeded: timeout after 1ns",
+		},
+		"canceled": {
+			cancel:      true,
+			expectError: "filter operation aborted: context canceled",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			g := NewWithT(t)
+
+			allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+			g.Expect(err).ToNot(HaveOccurred())
+			allocator.SetLimits(tc.limits)
+			if tc.cancel {
+				var cancel func()
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			}
+
+			results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+			if tc.expectError != "" {
+				g.Expect(err).To(MatchError(tc.expectError))
+				if tc.limits != (Limits{}) {
+					g.Expect(err).To(MatchError(ErrLimitExceeded))
+				}
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(results).To(BeNil())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"time"
+
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+)
+
+// allocationMetrics collects the counters for one allocation. They get
+// added to the global metrics once at the end, which is cheaper than
+// updating those while searching.
+type allocationMetrics struct {
+	start          time.Time
+	backtracks     int64
+	devices        map[string]int64
+	celEvaluations map[string]int64
+}
+
+func newAllocationMetrics() allocationMetrics {
+	return allocationMetrics{
+		start:          time.Now(),
+		devices:        make(map[string]int64),
+		celEvaluations: make(map[string]int64),
+	}
+}
+
+// record updates the global metrics. The device classes are those of all
+// requests which were validated.
+func (m *allocationMetrics) record(result string, classes []string) {
+	duration := time.Since(m.start).Seconds()
+	internal.AllocationAttempts.WithLabelValues(result).Inc()
+	internal.AllocationDuration.WithLabelValues(result).Observe(duration)
+	for _, class := range classes {
+		internal.DeviceClassAllocationDuration.WithLabelValues(class).Observe(duration)
+	}
+	if m.backtracks > 0 {
+		internal.AllocationBacktracks.Add(float64(m.backtracks))
+	}
+	for class, count := range m.devices {
+		internal.DevicesConsidered.WithLabelValues(class).Add(float64(count))
+	}
+	for class, count := range m.celEvaluations {
+		internal.CELEvaluations.WithLabelValues(class).Add(float64(count))
+	}
+}
+
+// classNames returns the names of the device classes used by the requests,
+// without duplicates.
+func (alloc *allocator) classNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, requestData := range alloc.requestData {
+		name := requestData.className()
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func (r requestData) className() string {
+	if r.class == nil {
+		return ""
+	}
+	return r.class.Name
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestMetrics(t *testing.T) {
+	internal.RegisterMetrics()
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-class"},
+		Spec: resourceapi.DeviceClassSpec{
+			Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: "true"}}},
+		},
+	}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			Devices:  []resourceapi.Device{{Name: "device-1"}, {Name: "device-2"}},
+		},
+	}
+	claim := func(numRequests int) *resourceapi.ResourceClaim {
+		claim := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"}}
+		for i := range numRequests {
+			claim.Spec.Devices.Requests = append(claim.Spec.Devices.Requests, resourceapi.DeviceRequest{
+				Name: fmt.Sprintf("req-%d", i),
+				Exactly: &resourceapi.ExactDeviceRequest{
+					DeviceClassName: class.Name,
+					AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+					Count:           1,
+				},
+			})
+		}
+		return claim
+	}
+
+	counter := func(get func() (float64, error)) float64 {
+		t.Helper()
+		value, err := get()
+		g.Expect(err).ToNot(HaveOccurred())
+		return value
+	}
+	attempts := func(result string) float64 {
+		return counter(func() (float64, error) {
+			return testutil.GetCounterMetricValue(internal.AllocationAttempts.WithLabelValues(result))
+		})
+	}
+	backtracks := func() float64 {
+		return counter(func() (float64, error) { return testutil.GetCounterMetricValue(internal.AllocationBacktracks) })
+	}
+	devices := func() float64 {
+		return counter(func() (float64, error) {
+			return testutil.GetCounterMetricValue(internal.DevicesConsidered.WithLabelValues(class.Name))
+		})
+	}
+	celEvaluations := func() float64 {
+		return counter(func() (float64, error) {
+			return testutil.GetCounterMetricValue(internal.CELEvaluations.WithLabelValues(class.Name))
+		})
+	}
+	classDurations := func() uint64 {
+		t.Helper()
+		count, err := testutil.GetHistogramMetricCount(internal.DeviceClassAllocationDuration.WithLabelValues(class.Name))
+		g.Expect(err).ToNot(HaveOccurred())
+		return count
+	}
+
+	allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The first device is suitable, nothing else needs to be checked.
+	successBefore, backtracksBefore, devicesBefore, celBefore, durationsBefore := attempts(AllocationResultSuccess), backtracks(), devices(), celEvaluations(), classDurations()
+	results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim(1)})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(attempts(AllocationResultSuccess)-successBefore).To(Equal(1.0), "successful attempts")
+	g.Expect(backtracks()-backtracksBefore).To(Equal(0.0), "backtracks")
+	g.Expect(devices()-devicesBefore).To(Equal(1.0), "devices considered")
+	g.Expect(celEvaluations()-celBefore).To(Equal(1.0), "CEL evaluations")
+	g.Expect(classDurations()-durationsBefore).To(Equal(uint64(1)), "class durations")
+
+	// One request more than there are devices.
+	unschedulableBefore, backtracksBefore := attempts(AllocationResultUnschedulable), backtracks()
+	results, err = allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim(3)})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(BeNil())
+	g.Expect(attempts(AllocationResultUnschedulable)-unschedulableBefore).To(Equal(1.0), "unschedulable attempts")
+	g.Expect(backtracks()-backtracksBefore).To(BeNumerically(">", 0.0), "backtracks")
+
+	// Unknown class.
+	errorBefore := attempts(AllocationResultError)
+	c := claim(1)
+	c.Spec.Devices.Requests[0].Exactly.DeviceClassName = "no-such-class"
+	_, err = allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{c})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(attempts(AllocationResultError)-errorBefore).To(Equal(1.0), "failed attempts")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"cmp"
+	"context"
+	"math/rand/v2"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	draapi "k8s.io/dynamic-resource-allocation/api"
+)
+
+// gatherOrderedPools calls GatherPools and, if enabled, brings the result
+// into a deterministic order.
+func (a *Allocator) gatherOrderedPools(ctx context.Context, node *v1.Node) ([]*Pool, error) {
+	pools, err := GatherPools(ctx, a.slices, node, a.features)
+	if err != nil {
+		return nil, err
+	}
+	a.mutex.RLock()
+	seed := a.orderSeed
+	a.mutex.RUnlock()
+	if seed != nil {
+		orderPools(pools, *seed)
+	}
+	return pools, nil
+}
+
+// orderPools sorts slices by name inside each pool and pools by ID, then
+// shuffles the pools with the seed. The shuffling spreads allocations
+// across pools the same way for all users of the same seed.
+//
+// Slices and pools with binding conditions remain at the end, as in
+// the result of GatherPools. Devices stay in the order in which the
+// driver listed them in their slice.
+func orderPools(pools []*Pool, seed uint64) {
+	for _, pool := range pools {
+		slices.SortFunc(pool.Slices, func(a, b *draapi.ResourceSlice) int {
+			return cmp.Or(
+				compareBool(sliceHasBindingConditions(a), sliceHasBindingConditions(b)),
+				cmp.Compare(a.Name, b.Name),
+			)
+		})
+	}
+	slices.SortFunc(pools, func(a, b *Pool) int {
+		return cmp.Or(
+			compareBool(poolHasBindingConditions(*a), poolHasBindingConditions(*b)),
+			cmp.Compare(a.Driver.String(), b.Driver.String()),
+			cmp.Compare(a.Pool.String(), b.Pool.String()),
+		)
+	})
+	numWithoutBindingConditions := slices.IndexFunc(pools, func(pool *Pool) bool { return poolHasBindingConditions(*pool) })
+	if numWithoutBindingConditions < 0 {
+		numWithoutBindingConditions = len(pools)
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	shuffle := func(pools []*Pool) {
+		rng.Shuffle(len(pools), func(i, j int) { pools[i], pools[j] = pools[j], pools[i] })
+	}
+	shuffle(pools[:numWithoutBindingConditions])
+	shuffle(pools[numWithoutBindingConditions:])
+}
+
+func sliceHasBindingConditions(slice *draapi.ResourceSlice) bool {
+	for _, device := range slice.Spec.Devices {
+		if device.BindingConditions != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// compareBool sorts false before true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestSetDeterministicOrder(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	var resourceSlices []*resourceapi.ResourceSlice
+	for i := range 10 {
+		resourceSlices = append(resourceSlices, &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("slice-%d", i)},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   driverA,
+				Pool:     resourceapi.ResourcePool{Name: fmt.Sprintf("pool-%d", i), ResourceSliceCount: 1},
+				AllNodes: ptr.To(true),
+				Devices:  []resourceapi.Device{{Name: "device"}},
+			},
+		})
+	}
+	// The only pool with binding conditions must be tried last.
+	resourceSlices[0].Spec.Devices[0].BindingConditions = []string{"attached"}
+	resourceSlices[0].Spec.Devices[0].BindingFailureConditions = []string{"failed"}
+	features := Features{DeviceBinding: true, DeviceStatus: true}
+
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: class.Name,
+						AllocationMode:  resourceapi.DeviceAllocationModeAll,
+					},
+				}},
+			},
+		},
+	}
+	// With "all" devices, the result lists the pools in the order
+	// in which they were tried.
+	allocate := func(resourceSlices []*resourceapi.ResourceSlice, seed uint64) []string {
+		t.Helper()
+		allocator, err := NewAllocator(ctx, features, AllocatedState{}, classList{class}, resourceSlices, cel.NewCache(1, cel.Features{}))
+		g.Expect(err).ToNot(HaveOccurred())
+		allocator.SetDeterministicOrder(seed)
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+		var pools []string
+		for _, result := range results[0].Devices.Results {
+			pools = append(pools, result.Pool)
+		}
+		return pools
+	}
+
+	reversed := slices.Clone(resourceSlices)
+	slices.Reverse(reversed)
+	orders := sets.New[string]()
+	for seed := range uint64(5) {
+		order := allocate(resourceSlices, seed)
+		g.Expect(allocate(reversed, seed)).To(Equal(order), "seed %d: order of slices must not matter", seed)
+		g.Expect(order[len(order)-1]).To(Equal("pool-0"), "seed %d: pool with binding conditions must be last", seed)
+		orders.Insert(fmt.Sprint(order))
+	}
+	g.Expect(orders.Len()).To(BeNumerically(">", 1), "different seeds should lead to different orders")
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	draapi "k8s.io/dynamic-resource-allocation/api"
+)
+
+// AllocatePod allocates all claims of a pod which are not allocated yet,
+// or none of them. Constraints with request names of the form
+// "<claim name>/<request>[/<subrequest>]" are checked across claims.
+//
+// On success, the new allocations are assumed (see [Allocator.Assume]) and
+// the returned function reverts that. This is the single rollback point
+// if the pod cannot be scheduled after all.
+func (a *Allocator) AllocatePod(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim, constraints []resourceapi.DeviceConstraint) (finalResult []resourceapi.AllocationResult, rollback func(), finalErr error) {
+	claimNames := sets.New[string]()
+	var toAllocate []*resourceapi.ResourceClaim
+	for _, claim := range claims {
+		claimNames.Insert(claim.Name)
+		if claim.Status.Allocation == nil {
+			toAllocate = append(toAllocate, claim)
+		}
+	}
+	for i, constraint := range constraints {
+		for _, requestName := range constraint.Requests {
+			claimName, _, _ := strings.Cut(requestName, "/")
+			if !claimNames.Has(claimName) {
+				return nil, nil, fmt.Errorf("pod constraint #%d: request %s: unknown claim %s", i, requestName, claimName)
+			}
+		}
+	}
+
+	var allocated []resourceapi.AllocationResult
+	if len(toAllocate) > 0 {
+		var err error
+		allocated, _, err = a.allocate(ctx, node, toAllocate, constraints, nil, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if allocated == nil {
+			return nil, nil, nil
+		}
+	}
+
+	results := make([]resourceapi.AllocationResult, 0, len(claims))
+	for _, claim := range claims {
+		if claim.Status.Allocation != nil {
+			results = append(results, *claim.Status.Allocation.DeepCopy())
+			continue
+		}
+		results = append(results, allocated[0])
+		allocated = allocated[1:]
+	}
+	return results, a.Assume(results[len(results)-len(toAllocate):]), nil
+}
+
+// addPodConstraints adds the constraints across claims to the constraints
+// of each claim that they apply to. Constraints for claims which are not
+// getting allocated are ignored.
+func (alloc *allocator) addPodConstraints(podConstraints []resourceapi.DeviceConstraint) error {
+	for i, podConstraint := range podConstraints {
+		// The shared constraint applies to all devices that
+		// get passed to it by the per-claim wrappers.
+		shared, err := alloc.newConstraint(podConstraint, nil)
+		if err != nil {
+			return fmt.Errorf("pod constraint #%d: %w", i, err)
+		}
+		for claimIndex, claim := range alloc.claimsToAllocate {
+			requestNames := sets.New[string]()
+			for _, requestName := range podConstraint.Requests {
+				if claimName, name, ok := strings.Cut(requestName, "/"); ok && claimName == claim.Name {
+					requestNames.Insert(name)
+				}
+			}
+			if len(podConstraint.Requests) > 0 && requestNames.Len() == 0 {
+				// Does not apply to this claim.
+				continue
+			}
+			alloc.constraints[claimIndex] = append(alloc.constraints[claimIndex], &claimPodConstraint{requestNames: requestNames, constraint: shared})
+		}
+	}
+	return nil
+}
+
+// claimPodConstraint passes devices for the requests of one claim
+// to a constraint which is shared between claims.
+type claimPodConstraint struct {
+	requestNames sets.Set[string]
+	constraint   constraint
+}
+
+func (p *claimPodConstraint) add(requestName, subRequestName string, device *draapi.Device, deviceID DeviceID) bool {
+	if !p.matches(requestName, subRequestName) {
+		return true
+	}
+	return p.constraint.add(requestName, subRequestName, device, deviceID)
+}
+
+func (p *claimPodConstraint) remove(requestName, subRequestName string, device *draapi.Device, deviceID DeviceID) {
+	if !p.matches(requestName, subRequestName) {
+		return
+	}
+	p.constraint.remove(requestName, subRequestName, device, deviceID)
+}
+
+func (p *claimPodConstraint) matches(requestName, subRequestName string) bool {
+	if p.requestNames.Len() == 0 {
+		return true
+	}
+	return p.requestNames.Has(requestName) ||
+		subRequestName != "" && p.requestNames.Has(requestName+"/"+subRequestName)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestAllocatePod(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	numa := func(value int64) map[resourceapi.QualifiedName]resourceapi.DeviceAttribute {
+		return map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"numa": {IntValue: ptr.To(value)}}
+	}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			Devices: []resourceapi.Device{
+				{Name: "device-1", Attributes: numa(0)},
+				{Name: "device-2", Attributes: numa(1)},
+				{Name: "device-3", Attributes: numa(1)},
+			},
+		},
+	}
+	claim := func(name string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           1,
+						},
+					}},
+				},
+			},
+		}
+	}
+	allocated := func(claim *resourceapi.ResourceClaim, device string) *resourceapi.ResourceClaim {
+		claim = claim.DeepCopy()
+		claim.Status.Allocation = &resourceapi.AllocationResult{
+			Devices: resourceapi.DeviceAllocationResult{
+				Results: []resourceapi.DeviceRequestAllocationResult{{Request: "req", Driver: driverA, Pool: pool1, Device: device}},
+			},
+		}
+		return claim
+	}
+	sameNUMA := resourceapi.DeviceConstraint{
+		Requests:       []string{"claim-1/req", "claim-2/req"},
+		MatchAttribute: ptr.To(resourceapi.FullyQualifiedName(driverA + "/numa")),
+	}
+	devices := func(results []resourceapi.AllocationResult) []string {
+		var devices []string
+		for _, result := range results {
+			for _, device := range result.Devices.Results {
+				devices = append(devices, device.Device)
+			}
+		}
+		return devices
+	}
+
+	for name, tc := range map[string]struct {
+		claims        []*resourceapi.ResourceClaim
+		constraints   []resourceapi.DeviceConstraint
+		expectDevices []string
+		expectError   string
+	}{
+		"no-constraints": {
+			claims:        []*resourceapi.ResourceClaim{claim("claim-1"), claim("claim-2")},
+			expectDevices: []string{"device-1", "device-2"},
+		},
+		"match-across-claims": {
+			claims:        []*resourceapi.ResourceClaim{claim("claim-1"), claim("claim-2")},
+			constraints:   []resourceapi.DeviceConstraint{sameNUMA},
+			expectDevices: []string{"device-2", "device-3"},
+		},
+		"all-requests": {
+			claims:        []*resourceapi.ResourceClaim{claim("claim-1"), claim("claim-2")},
+			constraints:   []resourceapi.DeviceConstraint{{MatchAttribute: sameNUMA.MatchAttribute}},
+			expectDevices: []string{"device-2", "device-3"},
+		},
+		"already-allocated": {
+			claims:        []*resourceapi.ResourceClaim{allocated(claim("claim-1"), "device-1"), claim("claim-2")},
+			expectDevices: []string{"device-1", "device-2"},
+		},
+		"unschedulable": {
+			claims:      []*resourceapi.ResourceClaim{claim("claim-1"), claim("claim-2"), claim("claim-3")},
+			constraints: []resourceapi.DeviceConstraint{{MatchAttribute: sameNUMA.MatchAttribute}},
+		},
+		"unknown-claim": {
+			claims:      []*resourceapi.ResourceClaim{claim("claim-1")},
+			constraints: []resourceapi.DeviceConstraint{sameNUMA},
+			expectError: "pod constraint #0: request claim-2/req: unknown claim claim-2",
+		},
+		"empty-constraint": {
+			claims:      []*resourceapi.ResourceClaim{claim("claim-1")},
+			constraints: []resourceapi.DeviceConstraint{{}},
+			expectError: "pod constraint #0: empty constraint (unsupported constraint type?)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			g := NewWithT(t)
+
+			// The allocated state of the caller includes the devices
+			// of claims which were already allocated.
+			allocatedState := AllocatedState{AllocatedDevices: sets.New[DeviceID]()}
+			for _, claim := range tc.claims {
+				if claim.Status.Allocation != nil {
+					allocatedState.AddAllocation(claim.Status.Allocation)
+				}
+			}
+			allocator, err := NewAllocator(ctx, Features{}, allocatedState, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+			g.Expect(err).ToNot(HaveOccurred())
+			results, rollback, err := allocator.AllocatePod(ctx, node, tc.claims, tc.constraints)
+			if tc.expectError != "" {
+				g.Expect(err).To(MatchError(tc.expectError))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			if tc.expectDevices == nil {
+				g.Expect(results).To(BeNil())
+				g.Expect(rollback).To(BeNil())
+				return
+			}
+			g.Expect(results).To(HaveLen(len(tc.claims)))
+			g.Expect(devices(results)).To(Equal(tc.expectDevices))
+
+			// The newly allocated devices are in use until the rollback.
+			again, _, err := allocator.AllocatePod(ctx, node, tc.claims, tc.constraints)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(devices(again)).ToNot(Equal(tc.expectDevices))
+			rollback()
+			again, _, err = allocator.AllocatePod(ctx, node, tc.claims, tc.constraints)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(devices(again)).To(Equal(tc.expectDevices))
+		})
+	}
+}
@@ -211,10 +211,4 @@ type Pool struct {
 	Slices        []*draapi.ResourceSlice
 }
 
-type PoolID struct {
-	Driver, Pool draapi.UniqueString
-}
-
-func (p PoolID) String() string {
-	return p.Driver.String() + "/" + p.Pool.String()
-}
+type PoolID = draapi.PoolID
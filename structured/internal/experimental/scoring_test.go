@@ -0,0 +1,261 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+type scoreFunc func(requestName string, deviceID DeviceID) (int64, error)
+
+func (f scoreFunc) ScoreDevice(ctx context.Context, claim *resourceapi.ResourceClaim, requestName string, deviceID DeviceID, device *resourceapi.Device) (int64, error) {
+	return f(requestName, deviceID)
+}
+
+type classList []*resourceapi.DeviceClass
+
+func (l classList) List() ([]*resourceapi.DeviceClass, error) {
+	return l, nil
+}
+
+func (l classList) Get(className string) (*resourceapi.DeviceClass, error) {
+	for _, class := range l {
+		if class.Name == className {
+			return class, nil
+		}
+	}
+	return nil, fmt.Errorf("class %s not found", className)
+}
+
+func TestAllocateWithScore(t *testing.T) {
+	scores := func(scores map[string]int64) scoreFunc {
+		return func(requestName string, deviceID DeviceID) (int64, error) {
+			return scores[deviceID.Device.String()], nil
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		count         int64
+		mode          resourceapi.DeviceAllocationMode
+		scorer        DeviceScorer
+		expectDevices []string
+		expectScore   int64
+		expectError   string
+	}{
+		"no-scorer": {
+			count:         1,
+			expectDevices: []string{"device-1"},
+		},
+		"prefer-last": {
+			count:         1,
+			scorer:        scores(map[string]int64{"device-3": 10}),
+			expectDevices: []string{"device-3"},
+			expectScore:   10,
+		},
+		"order-by-score": {
+			count:         2,
+			scorer:        scores(map[string]int64{"device-1": 1, "device-2": 3, "device-3": 2}),
+			expectDevices: []string{"device-2", "device-3"},
+			expectScore:   5,
+		},
+		"same-score": {
+			count:         2,
+			scorer:        scores(map[string]int64{"device-1": 1, "device-2": 1, "device-3": 1}),
+			expectDevices: []string{"device-1", "device-2"},
+			expectScore:   2,
+		},
+		"all": {
+			mode:          resourceapi.DeviceAllocationModeAll,
+			scorer:        scores(map[string]int64{"device-1": 1, "device-2": 2, "device-3": 3}),
+			expectDevices: []string{"device-1", "device-2", "device-3"},
+			expectScore:   6,
+		},
+		"request-name": {
+			count: 1,
+			scorer: scoreFunc(func(requestName string, deviceID DeviceID) (int64, error) {
+				if requestName != "req" {
+					return 0, fmt.Errorf("unexpected request name %q", requestName)
+				}
+				return 1, nil
+			}),
+			expectDevices: []string{"device-1"},
+			expectScore:   1,
+		},
+		"error": {
+			count: 1,
+			scorer: scoreFunc(func(requestName string, deviceID DeviceID) (int64, error) {
+				return 0, errors.New("fake error")
+			}),
+			expectError: "claim default/claim, request req: score device driver-a/pool-1/device-1: fake error",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			g := NewWithT(t)
+
+			class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+			slice := &resourceapi.ResourceSlice{
+				ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+				Spec: resourceapi.ResourceSliceSpec{
+					Driver:   driverA,
+					Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+					AllNodes: ptr.To(true),
+					Devices:  []resourceapi.Device{{Name: "device-1"}, {Name: "device-2"}, {Name: "device-3"}},
+				},
+			}
+			mode := tc.mode
+			if mode == "" {
+				mode = resourceapi.DeviceAllocationModeExactCount
+			}
+			claim := &resourceapi.ResourceClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec: resourceapi.ResourceClaimSpec{
+					Devices: resourceapi.DeviceClaim{
+						Requests: []resourceapi.DeviceRequest{{
+							Name: "req",
+							Exactly: &resourceapi.ExactDeviceRequest{
+								DeviceClassName: class.Name,
+								AllocationMode:  mode,
+								Count:           tc.count,
+							},
+						}},
+					},
+				},
+			}
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+
+			allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+			g.Expect(err).ToNot(HaveOccurred())
+			results, score, err := allocator.AllocateWithScore(ctx, node, []*resourceapi.ResourceClaim{claim}, tc.scorer)
+			if tc.expectError != "" {
+				g.Expect(err).To(MatchError(tc.expectError))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(results).To(HaveLen(1))
+			var devices []string
+			for _, result := range results[0].Devices.Results {
+				devices = append(devices, result.Device)
+			}
+			g.Expect(devices).To(Equal(tc.expectDevices))
+			g.Expect(score).To(Equal(tc.expectScore))
+		})
+	}
+}
+
+func TestAllocateWithScorePrioritizedList(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	model := func(name, model string) resourceapi.Device {
+		return resourceapi.Device{
+			Name:       name,
+			Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": {StringValue: ptr.To(model)}},
+		}
+	}
+	scorer := scoreFunc(func(requestName string, deviceID DeviceID) (int64, error) {
+		if requestName != "req/large" && requestName != "req/small" {
+			return 0, fmt.Errorf("unexpected request name %q", requestName)
+		}
+		if deviceID.Device.String() == "device-3" {
+			return 10, nil
+		}
+		return 0, nil
+	})
+
+	for name, tc := range map[string]struct {
+		devices       []resourceapi.Device
+		expectRequest string
+		expectDevices []string
+		expectScore   int64
+	}{
+		"first-alternative": {
+			devices:       []resourceapi.Device{model("device-1", "a"), model("device-2", "a"), model("device-3", "a")},
+			expectRequest: "req/large",
+			expectDevices: []string{"device-3", "device-1"},
+			expectScore:   10,
+		},
+		"constraint": {
+			devices:       []resourceapi.Device{model("device-1", "a"), model("device-2", "b"), model("device-3", "b")},
+			expectRequest: "req/large",
+			expectDevices: []string{"device-3", "device-2"},
+			expectScore:   10,
+		},
+		"second-alternative": {
+			devices:       []resourceapi.Device{model("device-1", "a"), model("device-2", "b"), model("device-3", "c")},
+			expectRequest: "req/small",
+			expectDevices: []string{"device-3"},
+			expectScore:   10,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			g := NewWithT(t)
+
+			slice := &resourceapi.ResourceSlice{
+				ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+				Spec: resourceapi.ResourceSliceSpec{
+					Driver:   driverA,
+					Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+					AllNodes: ptr.To(true),
+					Devices:  tc.devices,
+				},
+			}
+			claim := &resourceapi.ResourceClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec: resourceapi.ResourceClaimSpec{
+					Devices: resourceapi.DeviceClaim{
+						Requests: []resourceapi.DeviceRequest{{
+							Name: "req",
+							FirstAvailable: []resourceapi.DeviceSubRequest{
+								{Name: "large", DeviceClassName: class.Name, AllocationMode: resourceapi.DeviceAllocationModeExactCount, Count: 2},
+								{Name: "small", DeviceClassName: class.Name, AllocationMode: resourceapi.DeviceAllocationModeExactCount, Count: 1},
+							},
+						}},
+						Constraints: []resourceapi.DeviceConstraint{{
+							Requests:       []string{"req"},
+							MatchAttribute: ptr.To(resourceapi.FullyQualifiedName(driverA + "/model")),
+						}},
+					},
+				},
+			}
+
+			allocator, err := NewAllocator(ctx, Features{PrioritizedList: true}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+			g.Expect(err).ToNot(HaveOccurred())
+			results, score, err := allocator.AllocateWithScore(ctx, node, []*resourceapi.ResourceClaim{claim}, scorer)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(results).To(HaveLen(1))
+			var devices []string
+			for _, result := range results[0].Devices.Results {
+				g.Expect(result.Request).To(Equal(tc.expectRequest))
+				devices = append(devices, result.Device)
+			}
+			g.Expect(devices).To(Equal(tc.expectDevices))
+			g.Expect(score).To(Equal(tc.expectScore))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// scratch contains the bookkeeping maps of one allocate call. Each
+// scheduling attempt used to allocate them anew, which produced a lot
+// of garbage in large clusters. Now they come from scratchPool and get
+// cleared when done, which keeps the memory of their entries for the
+// next call.
+type scratch struct {
+	// Selecting a device for a request is independent of what has been
+	// allocated already. Therefore the result of checking a request against
+	// a device instance in the pool can be cached. The pointer to both
+	// can serve as key because they are static for the duration of
+	// the Allocate call and can be compared in Go.
+	deviceMatchesRequest map[matchKey]bool
+	// deviceScores caches the result of the scorer.
+	deviceScores map[matchKey]int64
+	// consumedCounters keeps track of the counters consumed by all devices
+	// that are in the process of being allocated.
+	// The keys in the map are ResourceSlice names.
+	consumedCounters map[string]counterSets
+	requestData      map[requestIndices]requestData // one entry per request with no subrequests and one entry per subrequest
+	// allocatingDevices tracks which devices will be newly allocated for a
+	// particular attempt to find a solution. The map is indexed by device
+	// and its values represent for which of a pod's claims the device will
+	// be allocated.
+	// Claims are identified by their index in claimsToAllocate.
+	allocatingDevices map[DeviceID]sets.Set[int]
+	// allocatingCapacity tracks the amount of device capacity that will be newly allocated
+	// for a particular attempt to find a solution.
+	// The map is indexed by device ID, and each value represents the accumulated capacity
+	// requested by all allocations targeting that device.
+	allocatingCapacity ConsumedCapacityCollection
+	// sharedDeviceUsers counts how often a device which allows multiple
+	// allocations is being allocated. Its counters are consumed only once.
+	sharedDeviceUsers map[DeviceID]int
+	// candidates contains the candidateIndex of each request.
+	candidates map[requestIndices]*candidateIndex
+}
+
+var scratchPool = sync.Pool{
+	New: func() any {
+		return &scratch{
+			deviceMatchesRequest: make(map[matchKey]bool),
+			deviceScores:         make(map[matchKey]int64),
+			consumedCounters:     make(map[string]counterSets),
+			requestData:          make(map[requestIndices]requestData),
+			allocatingDevices:    make(map[DeviceID]sets.Set[int]),
+			allocatingCapacity:   NewConsumedCapacityCollection(),
+			sharedDeviceUsers:    make(map[DeviceID]int),
+			candidates:           make(map[requestIndices]*candidateIndex),
+		}
+	},
+}
+
+// getScratch returns empty maps. They must be returned with release
+// when no longer needed.
+func getScratch() *scratch {
+	return scratchPool.Get().(*scratch)
+}
+
+// release clears the maps and puts them back into the pool. Nothing
+// that was stored in them may be used afterwards.
+func (s *scratch) release() {
+	clear(s.deviceMatchesRequest)
+	clear(s.deviceScores)
+	clear(s.consumedCounters)
+	clear(s.requestData)
+	clear(s.allocatingDevices)
+	clear(s.allocatingCapacity)
+	clear(s.sharedDeviceUsers)
+	clear(s.candidates)
+	scratchPool.Put(s)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/ktesting"
+)
+
+func TestScratchReuse(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class, slice, claim := selectorCacheTestData(10)
+	tooLarge := claim.DeepCopy()
+	tooLarge.Spec.Devices.Requests[0].Exactly.Count = 2
+
+	allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// A failed attempt leaves entries in the maps. They must not
+	// influence the following attempts.
+	for range 3 {
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{tooLarge})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(BeNil())
+
+		results, err = allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(results[0].Devices.Results[0].Device).To(Equal("device-9"))
+	}
+
+	s := getScratch()
+	defer s.release()
+	g.Expect(s.deviceMatchesRequest).To(BeEmpty())
+	g.Expect(s.requestData).To(BeEmpty())
+	g.Expect(s.allocatingDevices).To(BeEmpty())
+}
+
+// BenchmarkAllocate measures the memory allocated by repeated scheduling
+// attempts with the same Allocator.
+func BenchmarkAllocate(b *testing.B) {
+	ctx := klog.NewContext(b.Context(), klog.Background().V(0))
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class, slice, claim := selectorCacheTestData(1000)
+	allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(10, cel.Features{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(results) != 1 {
+			b.Fatal("expected one allocation result")
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+// selectorCacheTestData returns a class which selects healthy devices,
+// a slice with the given number of devices of which only the last one
+// is healthy and a claim for one device.
+func selectorCacheTestData(numDevices int) (*resourceapi.DeviceClass, *resourceapi.ResourceSlice, *resourceapi.ResourceClaim) {
+	class := &resourceapi.DeviceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "class"},
+		Spec: resourceapi.DeviceClassSpec{
+			Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: fmt.Sprintf(`device.attributes["%s"].healthy`, driverA)}}},
+		},
+	}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice", ResourceVersion: "1"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+		},
+	}
+	for i := range numDevices {
+		slice.Spec.Devices = append(slice.Spec.Devices, resourceapi.Device{
+			Name:       fmt.Sprintf("device-%d", i),
+			Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"healthy": {BoolValue: ptr.To(i == numDevices-1)}},
+		})
+	}
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: class.Name,
+						AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+						Count:           1,
+					},
+				}},
+			},
+		},
+	}
+	return class, slice, claim
+}
+
+func TestSelectorCache(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class, slice, claim := selectorCacheTestData(2)
+	cache := internal.NewSelectorCache()
+
+	allocate := func(slice *resourceapi.ResourceSlice) []resourceapi.AllocationResult {
+		t.Helper()
+		allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+		g.Expect(err).ToNot(HaveOccurred())
+		allocator.SetSelectorCache(cache)
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		g.Expect(err).ToNot(HaveOccurred())
+		return results
+	}
+
+	results := allocate(slice)
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].Devices.Results[0].Device).To(Equal("device-1"))
+	g.Expect(cache.Len()).To(Equal(2), "cached results")
+
+	// Same resource version: the cached results are used, even though
+	// the device isn't healthy anymore. Updating a slice without changing
+	// the resource version is not possible in practice.
+	unhealthy := slice.DeepCopy()
+	unhealthy.Spec.Devices[1].Attributes["healthy"] = resourceapi.DeviceAttribute{BoolValue: ptr.To(false)}
+	results = allocate(unhealthy)
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].Devices.Results[0].Device).To(Equal("device-1"))
+
+	// New resource version: evaluated again.
+	unhealthy.ResourceVersion = "2"
+	g.Expect(allocate(unhealthy)).To(BeNil())
+	g.Expect(cache.Len()).To(Equal(2), "cached results")
+
+	// Without resource version, nothing gets cached.
+	unhealthy.ResourceVersion = ""
+	unhealthy.Name = "other-slice"
+	g.Expect(allocate(unhealthy)).To(BeNil())
+	g.Expect(cache.Len()).To(Equal(2), "cached results")
+
+	cache.Prune(nil)
+	g.Expect(cache.Len()).To(Equal(0), "cached results after pruning")
+}
+
+// TestSelectorCacheTaints covers taints which get added by a DeviceTaintRule
+// without changing the ResourceVersion of the ResourceSlice.
+func TestSelectorCacheTaints(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class, slice, claim := selectorCacheTestData(1)
+	class.Spec.Selectors = []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `!device.taints.exists(t, t.key == "example.com/broken")`}}}
+	cache := internal.NewSelectorCache()
+
+	allocate := func(slice *resourceapi.ResourceSlice) []resourceapi.AllocationResult {
+		t.Helper()
+		allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+		g.Expect(err).ToNot(HaveOccurred())
+		allocator.SetSelectorCache(cache)
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		g.Expect(err).ToNot(HaveOccurred())
+		return results
+	}
+
+	g.Expect(allocate(slice)).To(HaveLen(1))
+	g.Expect(cache.Len()).To(Equal(1), "cached results")
+
+	tainted := slice.DeepCopy()
+	tainted.Spec.Devices[0].Taints = []resourceapi.DeviceTaint{{Key: "example.com/broken", Effect: resourceapi.DeviceTaintEffectNoSchedule}}
+	g.Expect(allocate(tainted)).To(BeNil(), "tainted device with the same resource version")
+	g.Expect(cache.Len()).To(Equal(2), "cached results")
+
+	g.Expect(allocate(slice)).To(HaveLen(1), "taint removed again")
+	g.Expect(cache.Len()).To(Equal(2), "cached results")
+}
+
+// BenchmarkSelectorCache simulates repeated scheduling attempts for a
+// pending pod on a node with many devices, of which only one matches.
+func BenchmarkSelectorCache(b *testing.B) {
+	const numDevices = 5000
+	ctx := klog.NewContext(b.Context(), klog.Background().V(0))
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class, slice, claim := selectorCacheTestData(numDevices)
+	celCache := cel.NewCache(10, cel.Features{})
+
+	for name, cache := range map[string]*SelectorCache{
+		"uncached": nil,
+		"cached":   internal.NewSelectorCache(),
+	} {
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, celCache)
+				if err != nil {
+					b.Fatal(err)
+				}
+				allocator.SetSelectorCache(cache)
+				results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(results) != 1 {
+					b.Fatal("expected one allocation result")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestAllocateStream(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+		},
+	}
+	for i := range 3 {
+		slice.Spec.Devices = append(slice.Spec.Devices, resourceapi.Device{Name: fmt.Sprintf("device-%d", i)})
+	}
+	claim := func(count int64) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           count,
+						},
+					}},
+				},
+			},
+		}
+	}
+	devices := func(results []resourceapi.AllocationResult) []string {
+		var devices []string
+		for _, result := range results[0].Devices.Results {
+			devices = append(devices, result.Device)
+		}
+		return devices
+	}
+
+	for name, tc := range map[string]struct {
+		count         int64
+		maxResults    int
+		expectDevices [][]string
+	}{
+		"all": {
+			count:         1,
+			expectDevices: [][]string{{"device-0"}, {"device-1"}, {"device-2"}},
+		},
+		"first": {
+			count:         1,
+			maxResults:    1,
+			expectDevices: [][]string{{"device-0"}},
+		},
+		"two-of-three": {
+			count:      2,
+			maxResults: 3,
+			expectDevices: [][]string{
+				{"device-0", "device-1"},
+				{"device-0", "device-2"},
+				{"device-1", "device-0"},
+			},
+		},
+		"unschedulable": {
+			count: 4,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			g := NewWithT(t)
+
+			allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var actualDevices [][]string
+			err = allocator.AllocateStream(ctx, node, []*resourceapi.ResourceClaim{claim(tc.count)}, nil, func(results []resourceapi.AllocationResult, score int64) bool {
+				g.Expect(results).To(HaveLen(1))
+				actualDevices = append(actualDevices, devices(results))
+				return tc.maxResults == 0 || len(actualDevices) < tc.maxResults
+			})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actualDevices).To(Equal(tc.expectDevices))
+
+			// Normal allocation still returns the first solution.
+			results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim(tc.count)})
+			g.Expect(err).ToNot(HaveOccurred())
+			if len(tc.expectDevices) == 0 {
+				g.Expect(results).To(BeNil())
+			} else {
+				g.Expect(devices(results)).To(Equal(tc.expectDevices[0]))
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"context"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/dynamic-resource-allocation/tracing"
+)
+
+// instrumentationName is used for the OpenTelemetry tracer.
+const instrumentationName = "k8s.io/dynamic-resource-allocation/structured"
+
+// startAllocateSpan starts a child of the span in the context. Without
+// such a span, the tracer provider and thus the new span are no-ops,
+// so callers decide about tracing through their context.
+func startAllocateSpan(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(instrumentationName)
+	ctx, span := tracer.Start(ctx, "Allocate", trace.WithAttributes(tracing.ClaimUIDs(claims)))
+	if node != nil {
+		span.SetAttributes(tracing.NodeKey.String(node.Name))
+	}
+	return ctx, span
+}
+
+// endAllocateSpan records the outcome of an allocation.
+func endAllocateSpan(span trace.Span, result string, results []resourceapi.AllocationResult, err error) {
+	span.SetAttributes(tracing.AllocationResultKey.String(result))
+	if len(results) > 0 {
+		span.SetAttributes(tracing.AllocatedDevices(results))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimental
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/trace"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/internal/tracetesting"
+	"k8s.io/dynamic-resource-allocation/tracing"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestTracing(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	g := NewWithT(t)
+
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "tracing-class"}}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driverA,
+			Pool:     resourceapi.ResourcePool{Name: pool1, ResourceSliceCount: 1},
+			AllNodes: ptr.To(true),
+			Devices:  []resourceapi.Device{{Name: "device-1"}},
+		},
+	}
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default", UID: "claim-uid"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: class.Name,
+						AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+						Count:           1,
+					},
+				}},
+			},
+		},
+	}
+	allocator, err := NewAllocator(ctx, Features{}, AllocatedState{}, classList{class}, []*resourceapi.ResourceSlice{slice}, cel.NewCache(1, cel.Features{}))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Without a span in the context, nothing gets traced.
+	tracerProvider := &tracetesting.TracerProvider{}
+	_, err = allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tracerProvider.Spans()).To(BeEmpty())
+
+	parentCtx, parent := tracerProvider.Tracer("test").Start(ctx, "Schedule")
+	defer parent.End()
+	results, err := allocator.Allocate(parentCtx, node, []*resourceapi.ResourceClaim{claim})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(1))
+
+	spans := tracerProvider.SpansByName("Allocate")
+	g.Expect(spans).To(HaveLen(1))
+	span := spans[0]
+	g.Expect(span.Parent).To(BeIdenticalTo(trace.SpanFromContext(parentCtx)))
+	g.Expect(span.Ended()).To(BeTrue(), "ended")
+	g.Expect(span.Attributes()).To(ContainElements(
+		tracing.ClaimUIDsKey.StringSlice([]string{"claim-uid"}),
+		tracing.NodeKey.String("node"),
+		tracing.AllocationResultKey.String(AllocationResultSuccess),
+		tracing.DevicesKey.StringSlice([]string{tracing.DeviceID(driverA, pool1, "device-1")}),
+	))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Explanation describes why claims could not be allocated.
+type Explanation struct {
+	// Requests contains one entry for each request or subrequest which
+	// was considered, in the order in which they were considered.
+	// Requests which were never reached because allocation already
+	// failed for earlier ones are not included.
+	Requests []RequestExplanation
+}
+
+// RequestExplanation summarizes how the devices on a node fared when trying
+// to allocate them for one request. Each device is counted once, for the
+// check where it got furthest: a device which was excluded by a selector and
+// also is tainted counts as excluded by the selector.
+type RequestExplanation struct {
+	Claim types.NamespacedName
+	// Request is the name of the request, "<request>/<subrequest>" for subrequests.
+	Request string
+
+	// NumDevicesNeeded is the number of devices requested.
+	NumDevicesNeeded int
+	// NumDevices is the number of devices which were considered.
+	NumDevices int
+
+	NumInUse                   int
+	NumUnsupported             int
+	NumNotMatchingClass        int
+	NumExcludedBySelectors     int
+	NumExcludedByNodeSelection int
+	NumExcludedByCapacity      int
+	NumExcludedByTaints        int
+	NumExcludedByConstraints   int
+	// NumAvailable is the number of devices which passed all checks
+	// for this request. They still might not have been usable
+	// in combination with devices for other requests.
+	NumAvailable int
+}
+
+// String returns a summary which is suitable for a Pod event.
+func (e *Explanation) String() string {
+	if e == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(e.Requests))
+	for _, request := range e.Requests {
+		parts = append(parts, request.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// String returns a summary like "claim default/gpu, request gpu: 2 of 8
+// devices available, 1 needed (4 in use, 2 not matching the class)".
+func (e RequestExplanation) String() string {
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "claim %s, request %s: %d of %d devices available, %d needed", e.Claim, e.Request, e.NumAvailable, e.NumDevices, e.NumDevicesNeeded)
+	var reasons []string
+	for _, reason := range []struct {
+		count int
+		what  string
+	}{
+		{e.NumInUse, "in use"},
+		{e.NumUnsupported, "not supported"},
+		{e.NumNotMatchingClass, "not matching the class"},
+		{e.NumExcludedBySelectors, "excluded by selectors"},
+		{e.NumExcludedByNodeSelection, "not available on the node"},
+		{e.NumExcludedByCapacity, "with insufficient capacity"},
+		{e.NumExcludedByTaints, "with taints that are not tolerated"},
+		{e.NumExcludedByConstraints, "excluded by constraints"},
+	} {
+		if reason.count > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d %s", reason.count, reason.what))
+		}
+	}
+	if len(reasons) > 0 {
+		fmt.Fprintf(&buffer, " (%s)", strings.Join(reasons, ", "))
+	}
+	return buffer.String()
+}
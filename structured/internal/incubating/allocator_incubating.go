@@ -1001,7 +1001,7 @@ func (alloc *allocator) selectorsMatch(r requestIndices, device *draapi.Device,
 		if err := draapi.Convert_api_Device_To_v1_Device(device, &d, nil); err != nil {
 			return false, fmt.Errorf("convert Device: %w", err)
 		}
-		matches, details, err := expr.DeviceMatches(alloc.ctx, cel.Device{Driver: deviceID.Driver.String(), Attributes: d.Attributes, Capacity: d.Capacity})
+		matches, details, err := expr.DeviceMatches(alloc.ctx, cel.Device{Driver: deviceID.Driver.String(), Attributes: d.Attributes, Capacity: d.Capacity, Taints: d.Taints})
 		if class != nil {
 			alloc.logger.V(7).Info("CEL result", "device", deviceID, "class", klog.KObj(class), "selector", i, "expression", selector.CEL.Expression, "matches", matches, "actualCost", ptr.Deref(details.ActualCost(), 0), "err", err)
 		} else {
@@ -1055,8 +1055,13 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 		return false, nil, nil
 	}
 
+	// Admin access does not consume counters. The device is typically
+	// in use already, in which case its counters are consumed by that
+	// other allocation.
+	consumeCounters := len(device.ConsumesCounters) > 0 && !request.adminAccess()
+
 	// The API validation logic has checked the ConsumesCounters referred should exist inside SharedCounters.
-	if len(device.ConsumesCounters) > 0 {
+	if consumeCounters {
 		// If a device consumes counters from a counter set, verify that
 		// there is sufficient counters available.
 		ok, err := alloc.checkAvailableCounters(device)
@@ -1131,7 +1136,7 @@ func (alloc *allocator) allocateDevice(r deviceIndices, device deviceWithID, mus
 			constraint.remove(baseRequestName, subRequestName, device.Device, device.id)
 		}
 		alloc.allocatingDevices[device.id].Delete(r.claimIndex)
-		if alloc.features.PartitionableDevices && len(device.ConsumesCounters) > 0 {
+		if consumeCounters {
 			alloc.deallocateCountersForDevice(device)
 		}
 		// Truncate, but keep the underlying slice.
@@ -1150,6 +1155,13 @@ func allTaintsTolerated(device *draapi.Device, request requestAccessor) bool {
 }
 
 func taintTolerated(taint resourceapi.DeviceTaint, request requestAccessor) bool {
+	switch taint.Effect {
+	case resourceapi.DeviceTaintEffectNoSchedule, resourceapi.DeviceTaintEffectNoExecute:
+	default:
+		// Other effects, for example those added in a future API
+		// version, do not prevent allocation.
+		return true
+	}
 	for _, toleration := range request.tolerations() {
 		if resourceclaim.ToleratesTaint(toleration, taint) {
 			return true
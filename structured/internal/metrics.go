@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/metrics"
+	drametrics "k8s.io/dynamic-resource-allocation/metrics"
+)
+
+const (
+	metricsNamespace = drametrics.Namespace
+	metricsSubsystem = drametrics.SubsystemAllocator
+)
+
+// Values of the "result" label.
+const (
+	AllocationResultSuccess       = "success"
+	AllocationResultUnschedulable = "unschedulable"
+	AllocationResultError         = "error"
+)
+
+// Metrics for the allocators. An allocation is one Allocate call, typically
+// for all claims of one pod. Implementations record them at the end of
+// each allocation instead of updating them while searching for a solution.
+var (
+	AllocationAttempts = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "attempts_total",
+			Help:           "Number of allocations, by result (success, unschedulable, error).",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+	AllocationDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "duration_seconds",
+			Help:           "Duration of allocating the claims of one pod on one node, by result (success, unschedulable, error).",
+			Buckets:        metrics.ExponentialBuckets(0.0001, 4, 10),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+	DeviceClassAllocationDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "device_class_duration_seconds",
+			Help:           "Duration of allocations which included requests for devices of the class. Observed once per class and allocation.",
+			Buckets:        metrics.ExponentialBuckets(0.0001, 4, 10),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"device_class"},
+	)
+	AllocationBacktracks = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "backtracks_total",
+			Help:           "Number of times that a tentatively allocated device had to be given up again because no solution was found with it.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	DevicesConsidered = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "devices_considered_total",
+			Help:           "Number of times that a device was checked for a request of the device class.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"device_class"},
+	)
+	CELEvaluations = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "cel_evaluations_total",
+			Help:           "Number of CEL selector evaluations for requests of the device class, including the selectors of the class itself.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"device_class"},
+	)
+)
+
+func init() {
+	drametrics.Add(metricsSubsystem, AllocationAttempts, AllocationDuration, DeviceClassAllocationDuration, AllocationBacktracks, DevicesConsidered, CELEvaluations)
+}
+
+// RegisterMetrics registers the allocator metrics in the legacy registry.
+// Calling it more than once is okay.
+func RegisterMetrics() {
+	utilruntime.Must(drametrics.RegisterMetrics(drametrics.LegacyRegistry, drametrics.EnableSubsystems(metricsSubsystem)))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"strings"
+	"sync"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// SelectorCache stores the outcome of evaluating CEL selectors for devices.
+// The results for the devices in a ResourceSlice are dropped when the
+// ResourceSlice changes, as indicated by its ResourceVersion. ResourceSlices
+// without a ResourceVersion are not cached.
+//
+// The taints of a device are part of the key because selectors can check
+// them. Taints from DeviceTaintRules get added to a copy of the
+// ResourceSlice without changing its ResourceVersion.
+//
+// Results are only valid for the same CEL environment, so the same cache
+// must not be used by allocators with different CEL features.
+//
+// A SelectorCache is thread-safe. The zero value is not usable,
+// use NewSelectorCache.
+type SelectorCache struct {
+	mutex  sync.Mutex
+	slices map[string]*sliceSelectorResults
+}
+
+type sliceSelectorResults struct {
+	resourceVersion string
+	results         map[selectorKey]bool
+}
+
+type selectorKey struct {
+	expression string
+	device     string
+	taints     string
+}
+
+// newSelectorKey includes those fields of the taints which are
+// visible in CEL expressions.
+func newSelectorKey(deviceName string, taints []resourceapi.DeviceTaint, expression string) selectorKey {
+	key := selectorKey{expression: expression, device: deviceName}
+	if len(taints) > 0 {
+		var fingerprint strings.Builder
+		for _, taint := range taints {
+			fingerprint.WriteString(taint.Key)
+			fingerprint.WriteByte(0)
+			fingerprint.WriteString(taint.Value)
+			fingerprint.WriteByte(0)
+			fingerprint.WriteString(string(taint.Effect))
+			fingerprint.WriteByte(0)
+		}
+		key.taints = fingerprint.String()
+	}
+	return key
+}
+
+// NewSelectorCache returns an empty cache.
+func NewSelectorCache() *SelectorCache {
+	return &SelectorCache{
+		slices: make(map[string]*sliceSelectorResults),
+	}
+}
+
+// Get returns the cached result of the expression for the device with
+// the given taints in the ResourceSlice with the given name and resource
+// version.
+func (c *SelectorCache) Get(sliceName, resourceVersion, deviceName string, taints []resourceapi.DeviceTaint, expression string) (matches, found bool) {
+	if resourceVersion == "" {
+		return false, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	slice := c.slices[sliceName]
+	if slice == nil || slice.resourceVersion != resourceVersion {
+		return false, false
+	}
+	matches, found = slice.results[newSelectorKey(deviceName, taints, expression)]
+	return matches, found
+}
+
+// Set stores the result of the expression for the device. It replaces all
+// results for the ResourceSlice if they are for a different resource version.
+func (c *SelectorCache) Set(sliceName, resourceVersion, deviceName string, taints []resourceapi.DeviceTaint, expression string, matches bool) {
+	if resourceVersion == "" {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	slice := c.slices[sliceName]
+	if slice == nil || slice.resourceVersion != resourceVersion {
+		slice = &sliceSelectorResults{
+			resourceVersion: resourceVersion,
+			results:         make(map[selectorKey]bool),
+		}
+		c.slices[sliceName] = slice
+	}
+	slice.results[newSelectorKey(deviceName, taints, expression)] = matches
+}
+
+// Prune removes the results for all ResourceSlices which are not
+// in the list. It should be called with all current ResourceSlices
+// from time to time, for example at the start of each scheduling cycle.
+func (c *SelectorCache) Prune(slices []*resourceapi.ResourceSlice) {
+	names := sets.New[string]()
+	for _, slice := range slices {
+		names.Insert(slice.Name)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for name := range c.slices {
+		if !names.Has(name) {
+			delete(c.slices, name)
+		}
+	}
+}
+
+// Len returns the number of cached results.
+func (c *SelectorCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	l := 0
+	for _, slice := range c.slices {
+		l += len(slice.results)
+	}
+	return l
+}
@@ -18,10 +18,12 @@ package internal
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	resourceapi "k8s.io/api/resource/v1"
-	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/featuregates"
 )
 
 type DeviceClassLister interface {
@@ -45,6 +47,183 @@ type AllocatorExtended interface {
 	GetStats() Stats
 }
 
+// AllocatorScoring is an optional interface. Not all variants implement it.
+type AllocatorScoring interface {
+	// AllocateWithScore is like Allocate, except that devices which are
+	// suitable for a request are tried in order of decreasing score.
+	// The score of the result is the sum of the scores of all allocated
+	// devices. It is zero if the claims cannot be allocated.
+	//
+	// A nil scorer is valid and has the same effect as calling Allocate.
+	AllocateWithScore(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim, scorer DeviceScorer) (finalResult []resourceapi.AllocationResult, score int64, finalErr error)
+}
+
+// AllocatorStreaming is an optional interface. Not all variants implement it.
+type AllocatorStreaming interface {
+	// AllocateStream passes each allocation of the claims to the callback
+	// as soon as it is found, in the order in which AllocateWithScore
+	// would have tried them. The search stops when the callback returns
+	// false or all possible allocations were tried. Nothing is passed to
+	// the callback if the claims cannot be allocated.
+	//
+	// A nil scorer is valid.
+	AllocateStream(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim, scorer DeviceScorer, onResult ResultCallback) error
+}
+
+// ResultCallback receives one possible allocation of all claims, one entry
+// per claim, and its score. The results belong to the callback. Returning
+// false stops the search.
+type ResultCallback func(results []resourceapi.AllocationResult, score int64) (more bool)
+
+// AllocatorIncremental is an optional interface. Not all variants implement it.
+type AllocatorIncremental interface {
+	// Assume treats the devices in the allocation results as allocated
+	// in all following Allocate calls. The returned function reverts that.
+	// Rolling back is cheap, so a scheduler can create one Allocator per
+	// scheduling cycle and undo tentative allocations when a pod cannot
+	// be scheduled after all.
+	//
+	// Must not be called while Allocate runs.
+	Assume(results []resourceapi.AllocationResult) (rollback func())
+}
+
+// AllocatorExplaining is an optional interface. Not all variants implement it.
+type AllocatorExplaining interface {
+	// AllocateWithExplanation is like Allocate. When the claims cannot be
+	// allocated, it also returns an explanation why. Recording that
+	// information makes allocation slower.
+	AllocateWithExplanation(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim) (finalResult []resourceapi.AllocationResult, explanation *Explanation, finalErr error)
+}
+
+// AllocatorPod is an optional interface. Not all variants implement it.
+type AllocatorPod interface {
+	// AllocatePod allocates all claims of a pod which are not allocated
+	// yet, or none of them. The result has one entry per claim, in the
+	// same order, with the existing allocation for claims which were
+	// already allocated. It is nil if the claims cannot be allocated.
+	// Like all other allocated devices, those of claims which were
+	// already allocated must be included in the AllocatedState.
+	//
+	// The constraints apply across claims. Their request names have the
+	// format "<claim name>/<request>" or "<claim name>/<request>/<subrequest>".
+	// An empty list of requests means "all requests of all claims".
+	// Devices of claims which were already allocated are not checked
+	// against these constraints.
+	//
+	// On success, the new allocations are treated as allocated in all
+	// following Allocate calls, as with Assume. Calling rollback reverts
+	// that.
+	AllocatePod(ctx context.Context, node *v1.Node, claims []*resourceapi.ResourceClaim, constraints []resourceapi.DeviceConstraint) (finalResult []resourceapi.AllocationResult, rollback func(), finalErr error)
+}
+
+// AllocatorConstraints is an optional interface. Not all variants implement it.
+type AllocatorConstraints interface {
+	// SetConstraintProviders replaces the providers of additional
+	// constraints for all following Allocate calls.
+	//
+	// Must not be called while Allocate runs.
+	SetConstraintProviders(providers ...ConstraintProvider)
+}
+
+// ConstraintProvider creates additional constraints for a claim.
+type ConstraintProvider interface {
+	// Constraints gets called at the start of each allocation for each
+	// claim which needs to be allocated. Constraints have state, so
+	// new instances must be returned each time. Returning an error
+	// aborts the allocation.
+	Constraints(ctx context.Context, claim *resourceapi.ResourceClaim) ([]Constraint, error)
+}
+
+// ConstraintProviderFunc implements ConstraintProvider with a function.
+type ConstraintProviderFunc func(ctx context.Context, claim *resourceapi.ResourceClaim) ([]Constraint, error)
+
+func (f ConstraintProviderFunc) Constraints(ctx context.Context, claim *resourceapi.ResourceClaim) ([]Constraint, error) {
+	return f(ctx, claim)
+}
+
+// Constraint restricts which devices can be allocated together for
+// the requests of one claim. Constraints are checked in addition
+// to those in the claim.
+//
+// Constraints are assumed to be monotonic: once Add returns false,
+// adding more devices will not cause it to return true.
+type Constraint interface {
+	// Add gets called whenever a device is about to be allocated for
+	// a request of the claim. It must check whether the device satisfies
+	// the constraint together with the devices which were added before
+	// and if yes, track that it is allocated. The request name is
+	// "<request>" or "<request>/<subrequest>".
+	Add(requestName string, deviceID DeviceID, device *resourceapi.Device) bool
+
+	// Remove gets called exactly once for each successful Add with the
+	// same parameters when the device is not allocated anymore.
+	Remove(requestName string, deviceID DeviceID, device *resourceapi.Device)
+}
+
+// AllocatorSelectorCaching is an optional interface. Not all variants implement it.
+type AllocatorSelectorCaching interface {
+	// SetSelectorCache enables caching of CEL selector results across
+	// Allocate calls and allocators. Nil disables it.
+	//
+	// Must not be called while Allocate runs.
+	SetSelectorCache(cache *SelectorCache)
+}
+
+// AllocatorDeterministic is an optional interface. Not all variants implement it.
+type AllocatorDeterministic interface {
+	// SetDeterministicOrder makes the allocation result depend only on
+	// the input and the seed, not on the order of the ResourceSlices or
+	// map iteration. Two allocators with the same seed and input
+	// produce the same results.
+	//
+	// Must not be called while Allocate runs.
+	SetDeterministicOrder(seed uint64)
+}
+
+// AllocatorLimited is an optional interface. Not all variants implement it.
+type AllocatorLimited interface {
+	// SetLimits changes the limits for all following Allocate calls.
+	//
+	// Must not be called while Allocate runs.
+	SetLimits(limits Limits)
+}
+
+// Limits bound the amount of work done by one Allocate call. When a limit
+// is reached, Allocate returns an error which wraps ErrLimitExceeded.
+// The zero value means "no limits".
+type Limits struct {
+	// MaxCombinations is the maximum number of candidate device
+	// combinations that are explored. More precisely, it limits how often
+	// the search tries to allocate a device for a request, which is what
+	// Stats.NumAllocateOneInvocations counts.
+	MaxCombinations int64
+
+	// Timeout is the time budget for allocating the claims passed
+	// to Allocate, typically the claims of one pod.
+	//
+	// This is a budget for the entire call, not for each claim: the
+	// claims are allocated together and backtracking revisits earlier
+	// claims, so the time spent cannot be attributed to a single claim.
+	// Callers who want a budget per claim can multiply it by the number
+	// of claims before calling SetLimits.
+	Timeout time.Duration
+}
+
+// ErrLimitExceeded gets wrapped by the error returned by Allocate when
+// one of the Limits was reached before a solution was found.
+var ErrLimitExceeded = errors.New("allocation limit exceeded")
+
+// DeviceScorer ranks the devices which are suitable for a request.
+type DeviceScorer interface {
+	// ScoreDevice returns a score for allocating the device for the request
+	// of the claim. Higher is better. The request name is "<request>/<subrequest>"
+	// for subrequests. Returning an error aborts the allocation.
+	//
+	// Only called for devices which satisfy the request, at most once per
+	// device and request during one allocation.
+	ScoreDevice(ctx context.Context, claim *resourceapi.ResourceClaim, requestName string, deviceID DeviceID, device *resourceapi.Device) (int64, error)
+}
+
 // Stats shows statistics from the allocation process.
 type Stats struct {
 	// NumAllocateOneInvocations counts the number of times the allocateOne function
@@ -53,56 +232,10 @@ type Stats struct {
 }
 
 // Features contains all feature gates that may influence the behavior of ResourceClaim allocation.
-type Features struct {
-	// Sorted alphabetically. When adding a new entry, also extend Set and FeaturesAll.
-
-	AdminAccess          bool
-	ConsumableCapacity   bool
-	DeviceBinding        bool
-	DeviceStatus         bool
-	DeviceTaints         bool
-	PartitionableDevices bool
-	PrioritizedList      bool
-}
-
-// Set returns all features which are set to true.
-// The names of the features happen to match the Kubernetes
-// feature gates where applicable. Plain strings are used
-// because not all allocator features necessarily have to
-// be Kubernetes feature gates and this package must not
-// depend on those definitions.
-func (f Features) Set() sets.Set[string] {
-	enabled := sets.New[string]()
-	if f.AdminAccess {
-		enabled.Insert("DRAAdminAccess")
-	}
-	if f.ConsumableCapacity {
-		enabled.Insert("DRAConsumableCapacity")
-	}
-	if f.DeviceTaints {
-		enabled.Insert("DRADeviceTaints")
-	}
-	if f.PartitionableDevices {
-		enabled.Insert("DRAPartitionableDevices")
-	}
-	if f.PrioritizedList {
-		enabled.Insert("DRAPrioritizedList")
-	}
-	if f.DeviceBinding {
-		enabled.Insert("DRADeviceBindingConditions")
-	}
-	if f.DeviceStatus {
-		enabled.Insert("DRAResourceClaimDeviceStatus")
-	}
-	return enabled
-}
-
-var FeaturesAll = Features{
-	AdminAccess:          true,
-	ConsumableCapacity:   true,
-	DeviceBinding:        true,
-	DeviceStatus:         true,
-	DeviceTaints:         true,
-	PartitionableDevices: true,
-	PrioritizedList:      true,
-}
+type Features = featuregates.Features
+
+// FeaturesDefault contains the features which are enabled by
+// default in Kubernetes.
+var FeaturesDefault = featuregates.Default()
+
+var FeaturesAll = featuregates.All()
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2"
+)
+
+// FindClaimsToPreempt determines which of the candidates would have to be
+// deallocated so that the claims can be allocated on the node. This is
+// meant for scheduler preemption and descheduler integrations.
+//
+// The candidates are allocated claims whose devices must be included in
+// options.AllocatedState. They should be sorted by preference: those which
+// should be preempted first come first. The result is minimal in the sense
+// that keeping any of the returned claims would prevent the allocation. It
+// is not necessarily the smallest possible set, because finding that would
+// require trying all combinations of candidates.
+//
+// If the claims can be allocated without preemption, the result is empty
+// and found is true. If they cannot be allocated even after deallocating
+// all candidates, found is false. Errors are returned for invalid input,
+// for example unknown classes or CEL errors.
+func FindClaimsToPreempt(ctx context.Context, claims []*resourceapi.ResourceClaim, slices []*resourceapi.ResourceSlice, node *v1.Node, candidates []*resourceapi.ResourceClaim, options SimulateOptions) (toPreempt []*resourceapi.ResourceClaim, found bool, finalErr error) {
+	logger := klog.FromContext(ctx)
+	if options.CELCache == nil {
+		// Shared by all simulations.
+		options.CELCache = cel.NewCache(10, options.Features.CEL())
+	}
+	allocatedState := options.AllocatedState
+	fits := func(preempt []bool) (bool, error) {
+		options.AllocatedState = allocatedState.Clone()
+		for i, candidate := range candidates {
+			if preempt[i] && candidate.Status.Allocation != nil {
+				options.AllocatedState.RemoveAllocation(candidate.Status.Allocation)
+			}
+		}
+		results, err := Simulate(ctx, claims, slices, node, options)
+		return results != nil, err
+	}
+
+	preempt := make([]bool, len(candidates))
+	ok, err := fits(preempt)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+	for i := range preempt {
+		preempt[i] = true
+	}
+	ok, err = fits(preempt)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		logger.V(5).Info("Claims cannot be allocated, not even after preemption", "node", klog.KObj(node), "numCandidates", len(candidates))
+		return nil, false, nil
+	}
+
+	// Try to keep candidates, starting with those that should be
+	// preempted last. Each candidate which is not needed stays.
+	for i := len(candidates) - 1; i >= 0; i-- {
+		preempt[i] = false
+		ok, err := fits(preempt)
+		if err != nil {
+			return nil, false, fmt.Errorf("check claim %s: %w", klog.KObj(candidates[i]), err)
+		}
+		if !ok {
+			preempt[i] = true
+		}
+	}
+	for i, candidate := range candidates {
+		if preempt[i] {
+			toPreempt = append(toPreempt, candidate)
+		}
+	}
+	logger.V(5).Info("Found claims to preempt", "node", klog.KObj(node), "claims", klog.KObjSlice(toPreempt))
+	return toPreempt, true, nil
+}
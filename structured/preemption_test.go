@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestFindClaimsToPreempt(t *testing.T) {
+	const (
+		driver   = "dra.example.com"
+		nodeName = "node"
+	)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driver,
+			Pool:     resourceapi.ResourcePool{Name: nodeName, ResourceSliceCount: 1},
+			NodeName: ptr.To(nodeName),
+			Devices:  []resourceapi.Device{{Name: "gpu-0"}, {Name: "gpu-1"}, {Name: "gpu-2"}, {Name: "gpu-3"}},
+		},
+	}
+	claim := func(name string, count int64) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           count,
+						},
+					}},
+				},
+			},
+		}
+	}
+	allocated := func(name string, devices ...string) *resourceapi.ResourceClaim {
+		claim := claim(name, int64(len(devices)))
+		claim.Status.Allocation = &resourceapi.AllocationResult{}
+		for _, device := range devices {
+			claim.Status.Allocation.Devices.Results = append(claim.Status.Allocation.Devices.Results, resourceapi.DeviceRequestAllocationResult{
+				Request: "req",
+				Driver:  driver,
+				Pool:    nodeName,
+				Device:  device,
+			})
+		}
+		return claim
+	}
+	small := allocated("small", "gpu-0")
+	large := allocated("large", "gpu-1", "gpu-2")
+	other := allocated("other", "gpu-3")
+
+	for name, tc := range map[string]struct {
+		count           int64
+		allocated       []*resourceapi.ResourceClaim
+		candidates      []*resourceapi.ResourceClaim
+		expectFound     bool
+		expectPreempted []string
+	}{
+		"no-preemption-needed": {
+			count:       1,
+			allocated:   []*resourceapi.ResourceClaim{small},
+			candidates:  []*resourceapi.ResourceClaim{small},
+			expectFound: true,
+		},
+		"one-of-two": {
+			count:           1,
+			allocated:       []*resourceapi.ResourceClaim{small, large, other},
+			candidates:      []*resourceapi.ResourceClaim{small, large},
+			expectFound:     true,
+			expectPreempted: []string{"small"},
+		},
+		"preference": {
+			count:           1,
+			allocated:       []*resourceapi.ResourceClaim{small, large, other},
+			candidates:      []*resourceapi.ResourceClaim{large, small},
+			expectFound:     true,
+			expectPreempted: []string{"large"},
+		},
+		"two-of-three": {
+			count:           2,
+			allocated:       []*resourceapi.ResourceClaim{small, large, other},
+			candidates:      []*resourceapi.ResourceClaim{small, other, large},
+			expectFound:     true,
+			expectPreempted: []string{"small", "other"},
+		},
+		"not-enough": {
+			count:      3,
+			allocated:  []*resourceapi.ResourceClaim{small, large, other},
+			candidates: []*resourceapi.ResourceClaim{small, other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			options := SimulateOptions{
+				Classes:        []*resourceapi.DeviceClass{class},
+				AllocatedState: GatherAllocatedState(tc.allocated),
+			}
+			numAllocated := options.AllocatedState.AllocatedDevices.Len()
+			toPreempt, found, err := FindClaimsToPreempt(ctx, []*resourceapi.ResourceClaim{claim("pending", tc.count)}, []*resourceapi.ResourceSlice{slice}, node, tc.candidates, options)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectFound, found, "found")
+			var preempted []string
+			for _, claim := range toPreempt {
+				preempted = append(preempted, claim.Name)
+			}
+			assert.Equal(t, tc.expectPreempted, preempted)
+			assert.Equal(t, numAllocated, options.AllocatedState.AllocatedDevices.Len(), "caller's state should not be modified")
+		})
+	}
+
+	t.Run("unknown-class", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		_, _, err := FindClaimsToPreempt(ctx, []*resourceapi.ResourceClaim{claim("pending", 1)}, []*resourceapi.ResourceSlice{slice}, node, nil, SimulateOptions{})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+)
+
+// PreferredAttributes is a [DeviceScorer] which prefers devices with
+// certain attribute values. The score of a device is the number of
+// attributes which have the preferred value.
+//
+// Attributes of a device which are not qualified with a domain
+// are looked up with the driver name as domain.
+type PreferredAttributes map[resourceapi.FullyQualifiedName]resourceapi.DeviceAttribute
+
+var _ DeviceScorer = PreferredAttributes{}
+
+func (p PreferredAttributes) ScoreDevice(ctx context.Context, claim *resourceapi.ResourceClaim, requestName string, deviceID DeviceID, device *resourceapi.Device) (int64, error) {
+	var score int64
+	for name, preferred := range p {
+		if value, ok := lookupAttribute(device, deviceID, name); ok && apiequality.Semantic.DeepEqual(value, preferred) {
+			score++
+		}
+	}
+	return score, nil
+}
+
+func lookupAttribute(device *resourceapi.Device, deviceID DeviceID, attributeName resourceapi.FullyQualifiedName) (resourceapi.DeviceAttribute, bool) {
+	// Fully-qualified match?
+	if value, ok := device.Attributes[resourceapi.QualifiedName(attributeName)]; ok {
+		return value, true
+	}
+	domain, id, ok := strings.Cut(string(attributeName), "/")
+	if !ok || domain != deviceID.Driver.String() {
+		return resourceapi.DeviceAttribute{}, false
+	}
+	// Domain matches the driver, so let's check just the ID.
+	value, ok := device.Attributes[resourceapi.QualifiedName(id)]
+	return value, ok
+}
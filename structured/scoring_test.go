@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestPreferredAttributes(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	scorer := PreferredAttributes{
+		"dra.example.com/model": {StringValue: ptr.To("a100")},
+		"example.com/numa":      {IntValue: ptr.To(int64(0))},
+	}
+	deviceID := MakeDeviceID("dra.example.com", "pool", "device")
+
+	for name, tc := range map[string]struct {
+		attributes  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute
+		expectScore int64
+	}{
+		"none": {},
+		"unqualified": {
+			attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": {StringValue: ptr.To("a100")}},
+			expectScore: 1,
+		},
+		"qualified": {
+			attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+				"dra.example.com/model": {StringValue: ptr.To("a100")},
+				"example.com/numa":      {IntValue: ptr.To(int64(0))},
+			},
+			expectScore: 2,
+		},
+		"other-domain": {
+			attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"numa": {IntValue: ptr.To(int64(0))}},
+			expectScore: 0,
+		},
+		"other-value": {
+			attributes:  map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"model": {StringValue: ptr.To("h100")}},
+			expectScore: 0,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			score, err := scorer.ScoreDevice(ctx, nil, "req", deviceID, &resourceapi.Device{Name: "device", Attributes: tc.attributes})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectScore, score)
+		})
+	}
+}
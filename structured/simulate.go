@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+)
+
+// SimulateOptions contains the cluster state which is needed in
+// addition to the hypothetical ResourceSlices by [Simulate].
+type SimulateOptions struct {
+	// Features determines which allocator gets used, see [NewAllocator].
+	Features Features
+
+	// Classes are all DeviceClasses which may be referenced by the claims.
+	Classes []*resourceapi.DeviceClass
+
+	// AllocatedState describes devices which are in use by claims
+	// other than the ones passed to Simulate. May be empty.
+	AllocatedState AllocatedState
+
+	// CELCache is optional. When running many simulations, a shared
+	// cache avoids compiling the same expressions again.
+	CELCache *cel.Cache
+}
+
+// Simulate determines whether the claims could be allocated on the node if
+// it had the given ResourceSlices. Neither the node nor the slices need to
+// exist in the cluster: this is meant for tools like the Cluster Autoscaler
+// which need to decide whether creating a node from a certain template
+// would help pending pods.
+//
+// All input comes from the caller, no informers are involved.
+// Claims which already have an allocation are not allocated again. Their
+// devices are treated as in use. The result has one entry per claim, in the
+// same order, with the existing allocation for such claims. If the claims
+// cannot be allocated, nil and no error is returned.
+//
+// Errors are returned for invalid input, for example unknown classes or
+// CEL errors.
+func Simulate(ctx context.Context, claims []*resourceapi.ResourceClaim, hypotheticalSlices []*resourceapi.ResourceSlice, node *v1.Node, options SimulateOptions) ([]resourceapi.AllocationResult, error) {
+	celCache := options.CELCache
+	if celCache == nil {
+		celCache = cel.NewCache(10, options.Features.CEL())
+	}
+
+	allocatedState := options.AllocatedState.Clone()
+	var unallocated []*resourceapi.ResourceClaim
+	for _, claim := range claims {
+		if claim.Status.Allocation == nil {
+			unallocated = append(unallocated, claim)
+			continue
+		}
+		allocatedState.AddAllocation(claim.Status.Allocation)
+	}
+
+	allocator, err := NewAllocator(ctx, options.Features, allocatedState, deviceClasses(options.Classes), hypotheticalSlices, celCache)
+	if err != nil {
+		return nil, fmt.Errorf("create allocator: %w", err)
+	}
+	var results []resourceapi.AllocationResult
+	if len(unallocated) > 0 {
+		results, err = allocator.Allocate(ctx, node, unallocated)
+		if err != nil {
+			return nil, err
+		}
+		if results == nil {
+			return nil, nil
+		}
+	}
+
+	// Merge existing and new allocations.
+	allResults := make([]resourceapi.AllocationResult, 0, len(claims))
+	for _, claim := range claims {
+		if claim.Status.Allocation != nil {
+			allResults = append(allResults, *claim.Status.Allocation)
+			continue
+		}
+		allResults = append(allResults, results[0])
+		results = results[1:]
+	}
+	return allResults, nil
+}
+
+// deviceClasses implements DeviceClassLister for a fixed set of classes.
+type deviceClasses []*resourceapi.DeviceClass
+
+func (c deviceClasses) List() ([]*resourceapi.DeviceClass, error) {
+	return c, nil
+}
+
+func (c deviceClasses) Get(className string) (*resourceapi.DeviceClass, error) {
+	for _, class := range c {
+		if class.Name == className {
+			return class, nil
+		}
+	}
+	return nil, fmt.Errorf("device class %s does not exist", className)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestSimulate(t *testing.T) {
+	const (
+		driver   = "dra.example.com"
+		nodeName = "template-node"
+	)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	slice := &resourceapi.ResourceSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+		Spec: resourceapi.ResourceSliceSpec{
+			Driver:   driver,
+			Pool:     resourceapi.ResourcePool{Name: nodeName, ResourceSliceCount: 1},
+			NodeName: ptr.To(nodeName),
+			Devices:  []resourceapi.Device{{Name: "gpu-0"}, {Name: "gpu-1"}},
+		},
+	}
+	claim := func(name string, count int64) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           count,
+						},
+					}},
+				},
+			},
+		}
+	}
+	allocated := func(claim *resourceapi.ResourceClaim, devices ...string) *resourceapi.ResourceClaim {
+		claim = claim.DeepCopy()
+		claim.Status.Allocation = &resourceapi.AllocationResult{}
+		for _, device := range devices {
+			claim.Status.Allocation.Devices.Results = append(claim.Status.Allocation.Devices.Results, resourceapi.DeviceRequestAllocationResult{
+				Request: "req",
+				Driver:  driver,
+				Pool:    nodeName,
+				Device:  device,
+			})
+		}
+		return claim
+	}
+	devices := func(result resourceapi.AllocationResult) []string {
+		var devices []string
+		for _, device := range result.Devices.Results {
+			devices = append(devices, device.Device)
+		}
+		return devices
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		results, err := Simulate(ctx, []*resourceapi.ResourceClaim{claim("a", 1), claim("b", 1)}, []*resourceapi.ResourceSlice{slice}, node, SimulateOptions{Classes: []*resourceapi.DeviceClass{class}})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, []string{"gpu-0"}, devices(results[0]))
+		assert.Equal(t, []string{"gpu-1"}, devices(results[1]))
+	})
+
+	t.Run("does-not-fit", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		results, err := Simulate(ctx, []*resourceapi.ResourceClaim{claim("a", 3)}, []*resourceapi.ResourceSlice{slice}, node, SimulateOptions{Classes: []*resourceapi.DeviceClass{class}})
+		require.NoError(t, err)
+		assert.Nil(t, results)
+	})
+
+	t.Run("already-allocated", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		results, err := Simulate(ctx, []*resourceapi.ResourceClaim{allocated(claim("a", 1), "gpu-0"), claim("b", 1)}, []*resourceapi.ResourceSlice{slice}, node, SimulateOptions{Classes: []*resourceapi.DeviceClass{class}})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, []string{"gpu-0"}, devices(results[0]))
+		assert.Equal(t, []string{"gpu-1"}, devices(results[1]))
+	})
+
+	t.Run("allocated-state", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		allocatedState := AllocatedState{AllocatedDevices: sets.New(MakeDeviceID(driver, nodeName, "gpu-0"))}
+		results, err := Simulate(ctx, []*resourceapi.ResourceClaim{claim("a", 2)}, []*resourceapi.ResourceSlice{slice}, node, SimulateOptions{Classes: []*resourceapi.DeviceClass{class}, AllocatedState: allocatedState})
+		require.NoError(t, err)
+		assert.Nil(t, results)
+		assert.Equal(t, 1, allocatedState.AllocatedDevices.Len(), "caller's state should not be modified")
+	})
+
+	t.Run("unknown-class", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		_, err := Simulate(ctx, []*resourceapi.ResourceClaim{claim("a", 1)}, []*resourceapi.ResourceSlice{slice}, node, SimulateOptions{})
+		require.Error(t, err)
+	})
+}
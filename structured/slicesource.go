@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/dynamic-resource-allocation/cel"
+)
+
+// PatchedResourceSliceTracker is a [PatchedResourceSliceLister] which also
+// reports changes. It is implemented by
+// [k8s.io/dynamic-resource-allocation/resourceslice/tracker.Tracker].
+type PatchedResourceSliceTracker interface {
+	PatchedResourceSliceLister
+	AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error)
+}
+
+// SliceSource provides the patched ResourceSlices of a tracker to
+// allocators. It keeps the list of slices and only asks the tracker
+// again after the tracker reported a change, so creating an allocator
+// for each scheduling attempt is cheap while nothing changes.
+//
+// A SliceSource is thread-safe. It cannot be stopped because the tracker
+// does not support removing event handlers, so it should be created once
+// per tracker.
+type SliceSource struct {
+	tracker      PatchedResourceSliceTracker
+	registration cache.ResourceEventHandlerRegistration
+
+	mutex sync.Mutex
+	// slices is nil when it needs to be listed again.
+	slices []*resourceapi.ResourceSlice
+}
+
+// NewSliceSource subscribes to the events of the tracker.
+func NewSliceSource(tracker PatchedResourceSliceTracker) (*SliceSource, error) {
+	s := &SliceSource{tracker: tracker}
+	registration, err := tracker.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { s.invalidate() },
+		UpdateFunc: func(any, any) { s.invalidate() },
+		DeleteFunc: func(any) { s.invalidate() },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("add event handler: %w", err)
+	}
+	s.registration = registration
+	return s, nil
+}
+
+// HasSynced returns true once the tracker has delivered all
+// ResourceSlices which existed when NewSliceSource was called.
+func (s *SliceSource) HasSynced() bool {
+	return s.registration.HasSynced()
+}
+
+// ListPatchedResourceSlices returns the current patched ResourceSlices.
+// The caller must not modify them.
+func (s *SliceSource) ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.slices != nil {
+		return s.slices, nil
+	}
+	slices, err := s.tracker.ListPatchedResourceSlices()
+	if err != nil {
+		return nil, err
+	}
+	if slices == nil {
+		// Distinguish "no slices" from "needs to be listed".
+		slices = []*resourceapi.ResourceSlice{}
+	}
+	s.slices = slices
+	return slices, nil
+}
+
+// NewAllocator is like [NewAllocatorForPatchedSlices] with the
+// slices from the source.
+func (s *SliceSource) NewAllocator(ctx context.Context,
+	features Features,
+	allocatedState AllocatedState,
+	classLister DeviceClassLister,
+	celCache *cel.Cache,
+) (Allocator, error) {
+	return NewAllocatorForPatchedSlices(ctx, features, allocatedState, classLister, s, celCache)
+}
+
+func (s *SliceSource) invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.slices = nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/resourceslice/tracker"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+var _ PatchedResourceSliceTracker = &tracker.Tracker{}
+
+// fakeSliceTracker counts how often the slices get listed.
+type fakeSliceTracker struct {
+	slices   []*resourceapi.ResourceSlice
+	err      error
+	numLists int
+	handler  cache.ResourceEventHandler
+}
+
+func (t *fakeSliceTracker) ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error) {
+	t.numLists++
+	return t.slices, t.err
+}
+
+func (t *fakeSliceTracker) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	t.handler = handler
+	return fakeRegistration{}, nil
+}
+
+type fakeRegistration struct{}
+
+func (fakeRegistration) HasSynced() bool { return true }
+
+func TestSliceSource(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	slice := func(devices ...string) *resourceapi.ResourceSlice {
+		slice := &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   "dra.example.com",
+				Pool:     resourceapi.ResourcePool{Name: "pool", ResourceSliceCount: 1},
+				AllNodes: ptr.To(true),
+			},
+		}
+		for _, device := range devices {
+			slice.Spec.Devices = append(slice.Spec.Devices, resourceapi.Device{Name: device})
+		}
+		return slice
+	}
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{{
+					Name: "req",
+					Exactly: &resourceapi.ExactDeviceRequest{
+						DeviceClassName: class.Name,
+						AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+						Count:           1,
+					},
+				}},
+			},
+		},
+	}
+	allocate := func(t *testing.T, source *SliceSource) []resourceapi.AllocationResult {
+		_, ctx := ktesting.NewTestContext(t)
+		allocator, err := source.NewAllocator(ctx, Features{}, AllocatedState{}, deviceClasses{class}, cel.NewCache(1, cel.Features{}))
+		require.NoError(t, err)
+		results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{claim})
+		require.NoError(t, err)
+		return results
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		tracker := &fakeSliceTracker{}
+		source, err := NewSliceSource(tracker)
+		require.NoError(t, err)
+		assert.True(t, source.HasSynced())
+
+		assert.Nil(t, allocate(t, source), "no slices")
+		assert.Nil(t, allocate(t, source), "no slices")
+		assert.Equal(t, 1, tracker.numLists, "number of list calls without changes")
+
+		tracker.slices = []*resourceapi.ResourceSlice{slice("gpu-0")}
+		tracker.handler.OnAdd(tracker.slices[0], false)
+		results := allocate(t, source)
+		require.Len(t, results, 1)
+		assert.Equal(t, "gpu-0", results[0].Devices.Results[0].Device)
+		assert.Equal(t, 2, tracker.numLists, "number of list calls after add")
+
+		oldSlice := tracker.slices[0]
+		tracker.slices = []*resourceapi.ResourceSlice{slice("gpu-1")}
+		tracker.handler.OnUpdate(oldSlice, tracker.slices[0])
+		results = allocate(t, source)
+		require.Len(t, results, 1)
+		assert.Equal(t, "gpu-1", results[0].Devices.Results[0].Device)
+
+		tracker.slices = nil
+		tracker.handler.OnDelete(oldSlice)
+		assert.Nil(t, allocate(t, source), "no slices after delete")
+		assert.Equal(t, 4, tracker.numLists, "number of list calls at the end")
+	})
+
+	t.Run("list-error", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		tracker := &fakeSliceTracker{}
+		source, err := NewSliceSource(tracker)
+		require.NoError(t, err)
+		tracker.err = errors.New("fake error")
+		_, err = source.NewAllocator(ctx, Features{}, AllocatedState{}, deviceClasses{class}, cel.NewCache(1, cel.Features{}))
+		require.EqualError(t, err, "list patched ResourceSlices: fake error")
+	})
+
+	t.Run("handler-error", func(t *testing.T) {
+		_, err := NewSliceSource(&fakeSliceTracker{err: errors.New("fake error")})
+		require.EqualError(t, err, "add event handler: fake error")
+	})
+}
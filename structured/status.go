@@ -0,0 +1,115 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	resourceapply "k8s.io/client-go/applyconfigurations/resource/v1"
+)
+
+// AllocatedClaim returns a copy of the claim with the allocation in its
+// status. The consumers get added to the ReservedFor list unless it
+// already contains them. The original claim is not modified.
+//
+// The result can be passed to UpdateStatus or be used to create a patch
+// with [StatusPatch] or an apply configuration with [StatusApplyConfiguration].
+func AllocatedClaim(claim *resourceapi.ResourceClaim, allocation *resourceapi.AllocationResult, consumers ...resourceapi.ResourceClaimConsumerReference) *resourceapi.ResourceClaim {
+	claim = claim.DeepCopy()
+	claim.Status.Allocation = allocation.DeepCopy()
+	for _, consumer := range consumers {
+		if !isReservedFor(claim, consumer) {
+			claim.Status.ReservedFor = append(claim.Status.ReservedFor, consumer)
+		}
+	}
+	return claim
+}
+
+func isReservedFor(claim *resourceapi.ResourceClaim, consumer resourceapi.ResourceClaimConsumerReference) bool {
+	for _, reserved := range claim.Status.ReservedFor {
+		if reserved.UID == consumer.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusPatch returns a strategic merge patch for the status subresource
+// which changes the status of the original claim into the status of the
+// modified claim. It includes the UID and ResourceVersion of the original
+// claim, so the patch fails with a conflict if the claim was replaced or
+// modified in the meantime. Nil is returned if there is nothing to change.
+func StatusPatch(original, modified *resourceapi.ResourceClaim) ([]byte, error) {
+	originalJSON, err := json.Marshal(resourceapi.ResourceClaim{Status: original.Status})
+	if err != nil {
+		return nil, fmt.Errorf("encode original status: %w", err)
+	}
+	modifiedJSON, err := json.Marshal(resourceapi.ResourceClaim{Status: modified.Status})
+	if err != nil {
+		return nil, fmt.Errorf("encode modified status: %w", err)
+	}
+	patchJSON, err := strategicpatch.CreateTwoWayMergePatch(originalJSON, modifiedJSON, resourceapi.ResourceClaim{})
+	if err != nil {
+		return nil, fmt.Errorf("create patch: %w", err)
+	}
+	var patch map[string]any
+	if err := json.Unmarshal(patchJSON, &patch); err != nil {
+		return nil, fmt.Errorf("decode patch: %w", err)
+	}
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	patch["metadata"] = map[string]any{
+		"uid":             original.UID,
+		"resourceVersion": original.ResourceVersion,
+	}
+	return json.Marshal(patch)
+}
+
+// StatusApplyConfiguration returns an apply configuration which sets
+// the allocation and ReservedFor list of the claim, for use with
+// ApplyStatus. The device status is not included because it is owned
+// by the drivers.
+func StatusApplyConfiguration(claim *resourceapi.ResourceClaim) (*resourceapply.ResourceClaimApplyConfiguration, error) {
+	status := resourceapply.ResourceClaimStatus()
+	if claim.Status.Allocation != nil {
+		// The apply configuration types have the same JSON representation
+		// as the API types, which avoids copying each nested field.
+		allocationJSON, err := json.Marshal(claim.Status.Allocation)
+		if err != nil {
+			return nil, fmt.Errorf("encode allocation: %w", err)
+		}
+		var allocation resourceapply.AllocationResultApplyConfiguration
+		if err := json.Unmarshal(allocationJSON, &allocation); err != nil {
+			return nil, fmt.Errorf("decode allocation: %w", err)
+		}
+		status.WithAllocation(&allocation)
+	}
+	for _, consumer := range claim.Status.ReservedFor {
+		status.WithReservedFor(resourceapply.ResourceClaimConsumerReference().
+			WithAPIGroup(consumer.APIGroup).
+			WithResource(consumer.Resource).
+			WithName(consumer.Name).
+			WithUID(consumer.UID))
+	}
+	return resourceapply.ResourceClaim(claim.Name, claim.Namespace).
+		WithUID(claim.UID).
+		WithStatus(status), nil
+}
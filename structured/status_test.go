@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/utils/ptr"
+)
+
+func TestAllocationStatus(t *testing.T) {
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default", UID: "claim-uid", ResourceVersion: "42"},
+	}
+	allocation := &resourceapi.AllocationResult{
+		Devices: resourceapi.DeviceAllocationResult{
+			Results: []resourceapi.DeviceRequestAllocationResult{{
+				Request:     "req",
+				Driver:      "dra.example.com",
+				Pool:        "pool",
+				Device:      "gpu-0",
+				AdminAccess: ptr.To(false),
+			}},
+		},
+		NodeSelector: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchFields: []v1.NodeSelectorRequirement{{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"node"}}},
+			}},
+		},
+	}
+	pod := resourceapi.ResourceClaimConsumerReference{Resource: "pods", Name: "pod", UID: "pod-uid"}
+	otherPod := resourceapi.ResourceClaimConsumerReference{Resource: "pods", Name: "other-pod", UID: "other-pod-uid"}
+
+	allocated := AllocatedClaim(claim, allocation, pod)
+	assert.Nil(t, claim.Status.Allocation, "original claim should not be modified")
+	assert.Equal(t, allocation, allocated.Status.Allocation)
+	assert.Equal(t, []resourceapi.ResourceClaimConsumerReference{pod}, allocated.Status.ReservedFor)
+	reserved := AllocatedClaim(allocated, allocation, pod, otherPod)
+	assert.Equal(t, []resourceapi.ResourceClaimConsumerReference{pod, otherPod}, reserved.Status.ReservedFor, "no duplicates")
+
+	t.Run("patch", func(t *testing.T) {
+		patch, err := StatusPatch(claim, allocated)
+		require.NoError(t, err)
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(patch, &decoded))
+		assert.Equal(t, map[string]any{"uid": "claim-uid", "resourceVersion": "42"}, decoded["metadata"])
+
+		claimJSON, err := json.Marshal(claim)
+		require.NoError(t, err)
+		patchedJSON, err := strategicpatch.StrategicMergePatch(claimJSON, patch, resourceapi.ResourceClaim{})
+		require.NoError(t, err)
+		var patched resourceapi.ResourceClaim
+		require.NoError(t, json.Unmarshal(patchedJSON, &patched))
+		assert.Equal(t, allocated.Status, patched.Status)
+
+		// Adding a consumer only adds that entry.
+		patch, err = StatusPatch(allocated, reserved)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(patch, &decoded))
+		assert.NotContains(t, decoded["status"], "allocation")
+		assert.Contains(t, decoded["status"], "reservedFor")
+
+		patch, err = StatusPatch(allocated, allocated)
+		require.NoError(t, err)
+		assert.Nil(t, patch, "no change")
+	})
+
+	t.Run("apply-configuration", func(t *testing.T) {
+		applyConfig, err := StatusApplyConfiguration(reserved)
+		require.NoError(t, err)
+		assert.Equal(t, "claim", *applyConfig.Name)
+		assert.Equal(t, "default", *applyConfig.Namespace)
+
+		// Same JSON representation as the API type.
+		applyJSON, err := json.Marshal(applyConfig.Status)
+		require.NoError(t, err)
+		var status resourceapi.ResourceClaimStatus
+		require.NoError(t, json.Unmarshal(applyJSON, &status))
+		assert.Equal(t, reserved.Status, status)
+
+		applyConfig, err = StatusApplyConfiguration(claim)
+		require.NoError(t, err)
+		assert.Nil(t, applyConfig.Status.Allocation)
+		assert.Empty(t, applyConfig.Status.ReservedFor)
+	})
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// NodeSuitability is the outcome of checking one node in [SummarizeNodes].
+type NodeSuitability string
+
+const (
+	// NodeFits means that the claims can be allocated on the node
+	// with the devices that are currently available.
+	NodeFits NodeSuitability = "Fits"
+
+	// NodeFitsAfterPreemption means that the claims can only be
+	// allocated on the node if some of the preemption candidates
+	// get deallocated first.
+	NodeFitsAfterPreemption NodeSuitability = "FitsAfterPreemption"
+
+	// NodeDoesNotFit means that the claims cannot be allocated on
+	// the node, not even after deallocating all preemption candidates.
+	NodeDoesNotFit NodeSuitability = "DoesNotFit"
+)
+
+// NodeSummary is the result of [SummarizeNodes] for one node.
+type NodeSummary struct {
+	// NodeName is the name of the node that was checked.
+	NodeName string
+
+	// Suitability describes whether the claims fit onto the node.
+	Suitability NodeSuitability
+
+	// PreemptedClaims are those preemption candidates which have
+	// devices that were needed for the claims. Only set for
+	// NodeFitsAfterPreemption.
+	PreemptedClaims []*resourceapi.ResourceClaim
+}
+
+// SummarizeOptions contains the cluster state which is needed by
+// [SummarizeNodes] in addition to the pending claims.
+type SummarizeOptions struct {
+	// Features determines which allocator gets used, see [NewAllocator].
+	Features Features
+
+	// Classes are all DeviceClasses which may be referenced by the claims.
+	Classes []*resourceapi.DeviceClass
+
+	// Slices are all ResourceSlices which may provide devices on
+	// the candidate nodes.
+	Slices []*resourceapi.ResourceSlice
+
+	// AllocatedState describes devices which are in use by other
+	// claims. It must include the devices of the preemption candidates.
+	// May be empty.
+	AllocatedState AllocatedState
+
+	// PreemptionCandidates are allocated claims whose devices may get
+	// freed, for example because they belong to pods with a lower
+	// priority. May be empty, in which case NodeFitsAfterPreemption is
+	// never reported.
+	PreemptionCandidates []*resourceapi.ResourceClaim
+
+	// CELCache is optional. When checking many nodes repeatedly, a
+	// shared cache avoids compiling the same expressions again.
+	CELCache *cel.Cache
+}
+
+// SummarizeNodes checks for each candidate node whether the pending claims
+// could be allocated there. This is meant for tools like the Cluster
+// Autoscaler which need to compare node groups: the allocators are set up
+// once and then get reused for all nodes.
+//
+// The claims must not be allocated yet. The result has one entry per node,
+// in the same order. Errors are returned for invalid input, for example
+// unknown classes or CEL errors.
+func SummarizeNodes(ctx context.Context, claims []*resourceapi.ResourceClaim, nodes []*v1.Node, options SummarizeOptions) ([]NodeSummary, error) {
+	logger := klog.FromContext(ctx)
+	for _, claim := range claims {
+		if claim.Status.Allocation != nil {
+			return nil, fmt.Errorf("claim %s is already allocated", klog.KObj(claim))
+		}
+	}
+
+	celCache := options.CELCache
+	if celCache == nil {
+		celCache = cel.NewCache(10, options.Features.CEL())
+	}
+	classes := deviceClasses(options.Classes)
+	allocator, err := NewAllocator(ctx, options.Features, options.AllocatedState, classes, options.Slices, celCache)
+	if err != nil {
+		return nil, fmt.Errorf("create allocator: %w", err)
+	}
+
+	// The second allocator pretends that all preemption candidates
+	// are gone. It only gets created when needed.
+	var preemptionAllocator Allocator
+	candidates := make(map[DeviceID][]*resourceapi.ResourceClaim)
+	if len(options.PreemptionCandidates) > 0 {
+		freedState := options.AllocatedState.Clone()
+		for _, claim := range options.PreemptionCandidates {
+			if claim.Status.Allocation == nil {
+				continue
+			}
+			freedState.RemoveAllocation(claim.Status.Allocation)
+			for _, result := range claim.Status.Allocation.Devices.Results {
+				if ptr.Deref(result.AdminAccess, false) {
+					// Does not block other claims.
+					continue
+				}
+				deviceID := MakeDeviceID(result.Driver, result.Pool, result.Device)
+				candidates[deviceID] = append(candidates[deviceID], claim)
+			}
+		}
+		preemptionAllocator, err = NewAllocator(ctx, options.Features, freedState, classes, options.Slices, celCache)
+		if err != nil {
+			return nil, fmt.Errorf("create allocator for preemption: %w", err)
+		}
+	}
+
+	summaries := make([]NodeSummary, 0, len(nodes))
+	for _, node := range nodes {
+		summary := NodeSummary{NodeName: node.Name, Suitability: NodeDoesNotFit}
+		results, err := allocator.Allocate(ctx, node, claims)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.Name, err)
+		}
+		switch {
+		case results != nil:
+			summary.Suitability = NodeFits
+		case preemptionAllocator != nil:
+			results, err = preemptionAllocator.Allocate(ctx, node, claims)
+			if err != nil {
+				return nil, fmt.Errorf("node %s: %w", node.Name, err)
+			}
+			if results != nil {
+				summary.Suitability = NodeFitsAfterPreemption
+				summary.PreemptedClaims = preemptedClaims(results, candidates)
+			}
+		}
+		logger.V(5).Info("Checked node", "node", klog.KObj(node), "suitability", summary.Suitability, "preemptedClaims", klog.KObjSlice(summary.PreemptedClaims))
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// preemptedClaims returns the preemption candidates which hold devices
+// that are used by the results, without duplicates.
+func preemptedClaims(results []resourceapi.AllocationResult, candidates map[DeviceID][]*resourceapi.ResourceClaim) []*resourceapi.ResourceClaim {
+	var claims []*resourceapi.ResourceClaim
+	seen := make(map[*resourceapi.ResourceClaim]bool)
+	for _, result := range results {
+		for _, device := range result.Devices.Results {
+			for _, claim := range candidates[MakeDeviceID(device.Driver, device.Pool, device.Device)] {
+				if !seen[claim] {
+					seen[claim] = true
+					claims = append(claims, claim)
+				}
+			}
+		}
+	}
+	return claims
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestSummarizeNodes(t *testing.T) {
+	const driver = "dra.example.com"
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	node := func(name string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	slice := func(nodeName string, devices ...string) *resourceapi.ResourceSlice {
+		slice := &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName + "-slice"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   driver,
+				Pool:     resourceapi.ResourcePool{Name: nodeName, ResourceSliceCount: 1},
+				NodeName: ptr.To(nodeName),
+			},
+		}
+		for _, device := range devices {
+			slice.Spec.Devices = append(slice.Spec.Devices, resourceapi.Device{Name: device})
+		}
+		return slice
+	}
+	claim := func(name string, count int64) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           count,
+						},
+					}},
+				},
+			},
+		}
+	}
+	allocated := func(claim *resourceapi.ResourceClaim, nodeName string, devices ...string) *resourceapi.ResourceClaim {
+		claim = claim.DeepCopy()
+		claim.Status.Allocation = &resourceapi.AllocationResult{}
+		for _, device := range devices {
+			claim.Status.Allocation.Devices.Results = append(claim.Status.Allocation.Devices.Results, resourceapi.DeviceRequestAllocationResult{
+				Request: "req",
+				Driver:  driver,
+				Pool:    nodeName,
+				Device:  device,
+			})
+		}
+		return claim
+	}
+
+	// node-a has two free devices, node-b one free device and one used
+	// by a preemption candidate, node-c one device used by a claim which
+	// cannot be preempted.
+	victim := allocated(claim("victim", 1), "node-b", "gpu-1")
+	other := allocated(claim("other", 1), "node-c", "gpu-0")
+	nodes := []*v1.Node{node("node-a"), node("node-b"), node("node-c")}
+	options := SummarizeOptions{
+		Classes: []*resourceapi.DeviceClass{class},
+		Slices: []*resourceapi.ResourceSlice{
+			slice("node-a", "gpu-0", "gpu-1"),
+			slice("node-b", "gpu-0", "gpu-1"),
+			slice("node-c", "gpu-0"),
+		},
+		AllocatedState: GatherAllocatedState([]*resourceapi.ResourceClaim{victim, other}),
+	}
+
+	t.Run("without-preemption", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		summaries, err := SummarizeNodes(ctx, []*resourceapi.ResourceClaim{claim("a", 2)}, nodes, options)
+		require.NoError(t, err)
+		assert.Equal(t, []NodeSummary{
+			{NodeName: "node-a", Suitability: NodeFits},
+			{NodeName: "node-b", Suitability: NodeDoesNotFit},
+			{NodeName: "node-c", Suitability: NodeDoesNotFit},
+		}, summaries)
+	})
+
+	t.Run("with-preemption", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		options := options
+		options.PreemptionCandidates = []*resourceapi.ResourceClaim{victim}
+		summaries, err := SummarizeNodes(ctx, []*resourceapi.ResourceClaim{claim("a", 2)}, nodes, options)
+		require.NoError(t, err)
+		assert.Equal(t, []NodeSummary{
+			{NodeName: "node-a", Suitability: NodeFits},
+			{NodeName: "node-b", Suitability: NodeFitsAfterPreemption, PreemptedClaims: []*resourceapi.ResourceClaim{victim}},
+			{NodeName: "node-c", Suitability: NodeDoesNotFit},
+		}, summaries)
+		assert.True(t, options.AllocatedState.AllocatedDevices.Has(MakeDeviceID(driver, "node-b", "gpu-1")), "caller's state should not be modified")
+	})
+
+	t.Run("already-allocated", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		_, err := SummarizeNodes(ctx, []*resourceapi.ResourceClaim{victim}, nodes, options)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown-class", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		options := options
+		options.Classes = nil
+		_, err := SummarizeNodes(ctx, []*resourceapi.ResourceClaim{claim("a", 1)}, nodes, options)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/dynamic-resource-allocation/structured/internal/experimental"
+	"k8s.io/dynamic-resource-allocation/structured/internal/incubating"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+type fakeSliceLister struct {
+	slices []*resourceapi.ResourceSlice
+	err    error
+}
+
+func (l fakeSliceLister) ListPatchedResourceSlices() ([]*resourceapi.ResourceSlice, error) {
+	return l.slices, l.err
+}
+
+func TestNewAllocatorForPatchedSlices(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	taint := func(effect resourceapi.DeviceTaintEffect) []resourceapi.DeviceTaint {
+		return []resourceapi.DeviceTaint{{Key: "example.com/unhealthy", Effect: effect}}
+	}
+	lister := fakeSliceLister{
+		slices: []*resourceapi.ResourceSlice{{
+			ObjectMeta: metav1.ObjectMeta{Name: "slice"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   "dra.example.com",
+				Pool:     resourceapi.ResourcePool{Name: "pool", ResourceSliceCount: 1},
+				AllNodes: ptr.To(true),
+				Devices: []resourceapi.Device{
+					{Name: "no-schedule", Taints: taint(resourceapi.DeviceTaintEffectNoSchedule)},
+					{Name: "no-execute", Taints: taint(resourceapi.DeviceTaintEffectNoExecute)},
+					{Name: "other-effect", Taints: taint("None")},
+				},
+			},
+		}},
+	}
+	claim := func(tolerations ...resourceapi.DeviceToleration) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{{
+						Name: "req",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: class.Name,
+							AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+							Count:           1,
+							Tolerations:     tolerations,
+						},
+					}},
+				},
+			},
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		claim        *resourceapi.ResourceClaim
+		expectDevice string
+	}{
+		"not-tolerated": {
+			claim:        claim(),
+			expectDevice: "other-effect",
+		},
+		"no-schedule-tolerated": {
+			claim:        claim(resourceapi.DeviceToleration{Key: "example.com/unhealthy", Operator: resourceapi.DeviceTolerationOpExists, Effect: resourceapi.DeviceTaintEffectNoSchedule}),
+			expectDevice: "no-schedule",
+		},
+		"no-execute-tolerated": {
+			claim:        claim(resourceapi.DeviceToleration{Key: "example.com/unhealthy", Operator: resourceapi.DeviceTolerationOpExists, Effect: resourceapi.DeviceTaintEffectNoExecute}),
+			expectDevice: "no-execute",
+		},
+	} {
+		// The stable allocator doesn't support taints.
+		for featuresName, features := range map[string]Features{
+			"incubating":   incubating.SupportedFeatures,
+			"experimental": experimental.SupportedFeatures,
+		} {
+			t.Run(featuresName+"/"+name, func(t *testing.T) {
+				_, ctx := ktesting.NewTestContext(t)
+				allocator, err := NewAllocatorForPatchedSlices(ctx, features, AllocatedState{}, deviceClasses{class}, lister, cel.NewCache(1, cel.Features{}))
+				require.NoError(t, err)
+				results, err := allocator.Allocate(ctx, node, []*resourceapi.ResourceClaim{tc.claim})
+				require.NoError(t, err)
+				require.Len(t, results, 1)
+				require.Len(t, results[0].Devices.Results, 1)
+				assert.Equal(t, tc.expectDevice, results[0].Devices.Results[0].Device)
+			})
+		}
+	}
+
+	t.Run("list-error", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		_, err := NewAllocatorForPatchedSlices(ctx, internal.FeaturesAll, AllocatedState{}, deviceClasses{class}, fakeSliceLister{err: errors.New("fake error")}, cel.NewCache(1, cel.Features{}))
+		require.EqualError(t, err, "list patched ResourceSlices: fake error")
+	})
+}
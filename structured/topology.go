@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"context"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+// TopologyAlignment ensures that all devices which get allocated for a
+// claim have the same value for an attribute, for example the same NUMA
+// node or PCIe root. It is a [ConstraintProvider] for an
+// [AllocatorConstraints] and a [DeviceScorer] for an [AllocatorScoring].
+//
+// The constraint alone is sufficient for correctness. The scorer makes
+// the allocator try devices in the topology domain with the most available
+// devices first, which is more likely to be large enough for all requests
+// of a claim than a domain where some devices are in use already. Devices
+// which consume counters only count as available if enough of their
+// counters remain.
+//
+// The available devices are determined when creating the TopologyAlignment,
+// so it should be created anew for each allocation.
+type TopologyAlignment struct {
+	attribute resourceapi.FullyQualifiedName
+	applies   func(claim *resourceapi.ResourceClaim) bool
+	// domains maps each device which has the attribute to its domain.
+	domains map[DeviceID]topologyDomain
+	// available counts the available devices per domain.
+	available map[topologyDomain]int64
+}
+
+var _ ConstraintProvider = &TopologyAlignment{}
+var _ DeviceScorer = &TopologyAlignment{}
+
+// topologyDomain identifies the devices on the same node with the same
+// value for the attribute.
+type topologyDomain struct {
+	nodeName string
+	value    string
+}
+
+// NewTopologyAlignment creates a TopologyAlignment for the attribute.
+// Attributes of a device which are not qualified with a domain are looked
+// up with the driver name as domain. Devices without the attribute cannot
+// be allocated for claims which get aligned.
+//
+// The applies function is optional. If non-nil, only claims for which it
+// returns true get aligned. The slices and allocated state are only used
+// for scoring.
+func NewTopologyAlignment(attribute resourceapi.FullyQualifiedName, applies func(claim *resourceapi.ResourceClaim) bool, slices []*resourceapi.ResourceSlice, allocatedState AllocatedState) *TopologyAlignment {
+	t := &TopologyAlignment{
+		attribute: attribute,
+		applies:   applies,
+		domains:   make(map[DeviceID]topologyDomain),
+		available: make(map[topologyDomain]int64),
+	}
+	remaining := RemainingCounters(slices, allocatedState)
+	for _, slice := range slices {
+		for i := range slice.Spec.Devices {
+			device := &slice.Spec.Devices[i]
+			deviceID := MakeDeviceID(slice.Spec.Driver, slice.Spec.Pool.Name, device.Name)
+			value, ok := lookupAttribute(device, deviceID, attribute)
+			if !ok {
+				continue
+			}
+			domain := topologyDomain{nodeName: ptr.Deref(slice.Spec.NodeName, ""), value: attributeString(value)}
+			t.domains[deviceID] = domain
+			if allocatedState.AllocatedDevices.Has(deviceID) || !countersAvailable(slice.Name, device, remaining) {
+				continue
+			}
+			t.available[domain]++
+		}
+	}
+	return t
+}
+
+// Constraints returns a constraint which covers all requests of the claim,
+// unless the claim is not meant to be aligned.
+func (t *TopologyAlignment) Constraints(ctx context.Context, claim *resourceapi.ResourceClaim) ([]Constraint, error) {
+	if t.applies != nil && !t.applies(claim) {
+		return nil, nil
+	}
+	return []Constraint{NewMatchAttribute(nil, t.attribute)}, nil
+}
+
+// ScoreDevice returns the number of available devices in the topology
+// domain of the device. Devices without the attribute have a zero score.
+func (t *TopologyAlignment) ScoreDevice(ctx context.Context, claim *resourceapi.ResourceClaim, requestName string, deviceID DeviceID, device *resourceapi.Device) (int64, error) {
+	domain, ok := t.domains[deviceID]
+	if !ok {
+		return 0, nil
+	}
+	return t.available[domain], nil
+}
+
+// countersAvailable checks whether the remaining counters are
+// sufficient for the device.
+func countersAvailable(sliceName string, device *resourceapi.Device, remaining map[CounterSetID]map[string]resource.Quantity) bool {
+	for _, consumption := range device.ConsumesCounters {
+		counters := remaining[CounterSetID{Slice: sliceName, CounterSet: consumption.CounterSet}]
+		for name, counter := range consumption.Counters {
+			value, ok := counters[name]
+			if !ok || value.Cmp(counter.Value) < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// attributeString turns the value into a string which is unique
+// for each value and type.
+func attributeString(value resourceapi.DeviceAttribute) string {
+	switch {
+	case value.IntValue != nil:
+		return fmt.Sprintf("int:%d", *value.IntValue)
+	case value.BoolValue != nil:
+		return fmt.Sprintf("bool:%t", *value.BoolValue)
+	case value.StringValue != nil:
+		return "string:" + *value.StringValue
+	case value.VersionValue != nil:
+		return "version:" + *value.VersionValue
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/structured/internal"
+	"k8s.io/klog/v2/ktesting"
+	"k8s.io/utils/ptr"
+)
+
+func TestTopologyAlignment(t *testing.T) {
+	const (
+		driver   = "dra.example.com"
+		nodeName = "node"
+	)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	class := &resourceapi.DeviceClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	device := func(name string, numaNode int64) resourceapi.Device {
+		return resourceapi.Device{
+			Name:       name,
+			Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"numaNode": {IntValue: ptr.To(numaNode)}},
+		}
+	}
+	partition := func(name string, numaNode int64, memory string) resourceapi.Device {
+		device := device(name, numaNode)
+		device.ConsumesCounters = []resourceapi.DeviceCounterConsumption{{
+			CounterSet: "gpu",
+			Counters:   map[string]resourceapi.Counter{"memory": {Value: resource.MustParse(memory)}},
+		}}
+		return device
+	}
+	slices := []*resourceapi.ResourceSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpus"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   driver,
+				Pool:     resourceapi.ResourcePool{Name: "gpus", ResourceSliceCount: 1},
+				NodeName: ptr.To(nodeName),
+				Devices: []resourceapi.Device{
+					device("gpu-0", 0),
+					device("gpu-1", 0),
+					device("gpu-2", 1),
+					device("gpu-3", 1),
+					{Name: "no-numa"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "partitions"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   driver,
+				Pool:     resourceapi.ResourcePool{Name: "partitions", ResourceSliceCount: 1},
+				NodeName: ptr.To(nodeName),
+				SharedCounters: []resourceapi.CounterSet{{
+					Name:     "gpu",
+					Counters: map[string]resourceapi.Counter{"memory": {Value: resource.MustParse("80Gi")}},
+				}},
+				Devices: []resourceapi.Device{
+					partition("half", 2, "40Gi"),
+					partition("full", 2, "80Gi"),
+				},
+			},
+		},
+	}
+	allocatedState := AllocatedState{AllocatedDevices: sets.New(
+		MakeDeviceID(driver, "gpus", "gpu-0"),
+		MakeDeviceID(driver, "partitions", "half"),
+	)}
+	claim := func(name string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{
+						{
+							Name: "a",
+							Exactly: &resourceapi.ExactDeviceRequest{
+								DeviceClassName: class.Name,
+								AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+								Count:           1,
+							},
+						},
+						{
+							Name: "b",
+							Exactly: &resourceapi.ExactDeviceRequest{
+								DeviceClassName: class.Name,
+								AllocationMode:  resourceapi.DeviceAllocationModeExactCount,
+								Count:           1,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	devices := func(result resourceapi.AllocationResult) []string {
+		var devices []string
+		for _, device := range result.Devices.Results {
+			devices = append(devices, device.Device)
+		}
+		return devices
+	}
+
+	alignment := NewTopologyAlignment(driver+"/numaNode", func(claim *resourceapi.ResourceClaim) bool { return claim.Name != "unaligned" }, slices, allocatedState)
+
+	t.Run("scores", func(t *testing.T) {
+		_, ctx := ktesting.NewTestContext(t)
+		for _, slice := range slices {
+			for i := range slice.Spec.Devices {
+				device := &slice.Spec.Devices[i]
+				score, err := alignment.ScoreDevice(ctx, nil, "a", MakeDeviceID(driver, slice.Spec.Pool.Name, device.Name), device)
+				require.NoError(t, err)
+				expectScore := map[string]int64{
+					"gpu-0": 1, // Only gpu-1 remains in NUMA node 0.
+					"gpu-1": 1,
+					"gpu-2": 2,
+					"gpu-3": 2,
+					"half":  0, // The remaining 40Gi are not enough for "full".
+					"full":  0,
+				}[device.Name]
+				assert.Equal(t, expectScore, score, device.Name)
+			}
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		claim         *resourceapi.ResourceClaim
+		scorer        DeviceScorer
+		expectDevices []string
+	}{
+		"unaligned": {
+			claim:         claim("unaligned"),
+			expectDevices: []string{"gpu-1", "gpu-2"},
+		},
+		"aligned": {
+			claim:         claim("aligned"),
+			expectDevices: []string{"gpu-2", "gpu-3"},
+		},
+		"aligned-with-scoring": {
+			claim:         claim("aligned"),
+			scorer:        alignment,
+			expectDevices: []string{"gpu-2", "gpu-3"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ctx := ktesting.NewTestContext(t)
+			allocator, err := NewAllocator(ctx, internal.FeaturesAll, allocatedState, deviceClasses{class}, slices, cel.NewCache(1, cel.Features{}))
+			require.NoError(t, err)
+			allocator.(AllocatorConstraints).SetConstraintProviders(alignment)
+			results, _, err := allocator.(AllocatorScoring).AllocateWithScore(ctx, node, []*resourceapi.ResourceClaim{tc.claim}, tc.scorer)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, tc.expectDevices, devices(results[0]))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/dynamic-resource-allocation/resourceclaim"
+)
+
+// runClaims lists ResourceClaims with their state and allocated devices.
+func runClaims(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("claims", flag.ContinueOnError)
+	namespace := fs.String("namespace", "", "only show claims in this namespace, the default is all namespaces")
+	node := fs.String("node", "", "only show claims which are allocated for this node")
+	if err := parseFlags(fs, e, args, false); err != nil {
+		return err
+	}
+	_, dra, err := e.draClient()
+	if err != nil {
+		return err
+	}
+	claims, err := dra.ResourceClaims(*namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list ResourceClaims: %w", err)
+	}
+	slices.SortFunc(claims.Items, func(a, b resourceapi.ResourceClaim) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	table := newTable(e.out, "NAMESPACE", "NAME", "STATE", "NODE", "RESERVED FOR", "DEVICES")
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		allocation := claim.Status.Allocation
+		var state, allocationNode string
+		var devices []string
+		switch {
+		case allocation == nil:
+			state = "pending"
+		case len(claim.Status.ReservedFor) > 0:
+			state = "reserved"
+		default:
+			state = "allocated"
+		}
+		if allocation != nil {
+			allocationNode = allNodes
+			if allocation.NodeSelector != nil {
+				allocationNode = singleNode(allocation.NodeSelector)
+			}
+			for _, result := range allocation.Devices.Results {
+				devices = append(devices, fmt.Sprintf("%s:%s/%s/%s", result.Request, result.Driver, result.Pool, result.Device))
+			}
+		}
+		if *node != "" && allocationNode != *node {
+			continue
+		}
+		var reservedFor []string
+		for _, consumer := range claim.Status.ReservedFor {
+			reservedFor = append(reservedFor, strings.ToLower(consumer.Resource)+"/"+consumer.Name)
+		}
+		table.row(claim.Namespace, claim.Name, state, allocationNode, strings.Join(reservedFor, ","), strings.Join(devices, ","))
+	}
+	return table.flush()
+}
+
+// runUsage shows how many devices are allocated per node and driver and
+// how much of the shared counters is consumed, as computed by
+// [resourceclaim.SummarizeUsage].
+func runUsage(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("usage", flag.ContinueOnError)
+	if err := parseFlags(fs, e, args, false); err != nil {
+		return err
+	}
+	_, dra, err := e.draClient()
+	if err != nil {
+		return err
+	}
+	claimList, err := dra.ResourceClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list ResourceClaims: %w", err)
+	}
+	sliceList, err := dra.ResourceSlices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list ResourceSlices: %w", err)
+	}
+	claims := make([]*resourceapi.ResourceClaim, 0, len(claimList.Items))
+	for i := range claimList.Items {
+		claims = append(claims, &claimList.Items[i])
+	}
+	resourceSlices := make([]*resourceapi.ResourceSlice, 0, len(sliceList.Items))
+	for i := range sliceList.Items {
+		resourceSlices = append(resourceSlices, &sliceList.Items[i])
+	}
+
+	summary := resourceclaim.SummarizeUsage(claims, resourceSlices)
+	keys := slices.SortedFunc(maps.Keys(summary), func(a, b resourceclaim.UsageKey) int {
+		return cmp.Or(
+			cmp.Compare(a.NodeName, b.NodeName),
+			cmp.Compare(a.Driver, b.Driver),
+		)
+	})
+	table := newTable(e.out, "NODE", "DRIVER", "DEVICES", "ALLOCATED", "FREE")
+	for _, key := range keys {
+		usage := summary[key]
+		table.row(key.NodeName, key.Driver, strconv.Itoa(usage.TotalDevices), strconv.Itoa(usage.AllocatedDevices), strconv.Itoa(usage.FreeDevices()))
+	}
+	if err := table.flush(); err != nil {
+		return err
+	}
+
+	hasCounters := false
+	for _, key := range keys {
+		hasCounters = hasCounters || len(summary[key].Counters) > 0
+	}
+	if !hasCounters {
+		return nil
+	}
+	fmt.Fprintln(e.out)
+	table = newTable(e.out, "NODE", "DRIVER", "COUNTER SET", "COUNTER", "TOTAL", "CONSUMED")
+	for _, key := range keys {
+		counterSets := summary[key].Counters
+		for _, counterSet := range slices.Sorted(maps.Keys(counterSets)) {
+			counters := counterSets[counterSet]
+			for _, counter := range slices.Sorted(maps.Keys(counters)) {
+				usage := counters[counter]
+				table.row(key.NodeName, key.Driver, counterSet, counter, usage.Total.String(), usage.Consumed.String())
+			}
+		}
+	}
+	return table.flush()
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/builders"
+	"k8s.io/klog/v2/ktesting"
+)
+
+const driver = builders.DefaultDriver
+
+func testObjects() []runtime.Object {
+	return []runtime.Object{
+		builders.MakeResourceSlice("worker-slice").NodeName("worker").Devices(
+			builders.MakeDevice("dev-0").IntAttribute("index", 0).Taints(builders.MakeDeviceTaint("example.com/broken").Obj()).Obj(),
+			builders.MakeDevice("dev-1").IntAttribute("index", 1).Obj(),
+		).Obj(),
+		builders.MakeResourceSlice("other-slice").Driver("other.example.com").NodeName("other").Devices(
+			builders.MakeDevice("dev-0").Obj(),
+		).Obj(),
+		builders.MakeDeviceTaintRule("maintenance", builders.MakeDeviceTaint("example.com/maintenance").Value("true").Obj()).
+			Driver(driver).Selectors(`device.attributes["` + driver + `"].index == 1`).Obj(),
+		builders.MakeDeviceClass("gpu").DriverSelector(driver).Obj(),
+		builders.MakeResourceClaim("running").Request("req", "gpu", 1).
+			Allocated("worker").AllocatedDevice("req", driver, builders.DefaultPool, "dev-1").
+			ReservedFor("pod", "pod-uid").Obj(),
+		builders.MakeResourceClaim("pending").Request("req", "gpu", 1).Selectors("device.attributes.foo").Obj(),
+	}
+}
+
+func runCommand(t *testing.T, cmd func(context.Context, env, []string) error, args ...string) (string, error) {
+	t.Helper()
+	_, ctx := ktesting.NewTestContext(t)
+	var out, errOut bytes.Buffer
+	client := fake.NewClientset(testObjects()...)
+	client.Fake.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "resource.k8s.io/v1"},
+		{GroupVersion: "resource.k8s.io/v1alpha3"},
+	}
+	e := env{
+		out:       &out,
+		errOut:    &errOut,
+		newClient: func() (kubernetes.Interface, error) { return client, nil },
+	}
+	err := cmd(ctx, e, args)
+	return out.String(), err
+}
+
+func TestSlices(t *testing.T) {
+	out, err := runCommand(t, runSlices)
+	require.NoError(t, err)
+	assert.Equal(t, `NODE    DRIVER              POOL  DEVICE  DEVICE TAINTS                  RULE TAINTS
+other   other.example.com   pool  dev-0   <none>                         <none>
+worker  driver.example.com  pool  dev-0   example.com/broken:NoSchedule  <none>
+worker  driver.example.com  pool  dev-1   <none>                         example.com/maintenance=true:NoSchedule
+`, out)
+
+	out, err = runCommand(t, runSlices, "-node", "other")
+	require.NoError(t, err)
+	assert.Equal(t, `NODE   DRIVER             POOL  DEVICE  DEVICE TAINTS  RULE TAINTS
+other  other.example.com  pool  dev-0   <none>         <none>
+`, out)
+}
+
+func TestExplain(t *testing.T) {
+	out, err := runCommand(t, runExplain)
+	require.NoError(t, err)
+	assert.Equal(t, `RULE         TAINT                                    DRIVER              POOL  DEVICE  MATCH  REASON
+maintenance  example.com/maintenance=true:NoSchedule  driver.example.com  pool  dev-1   true   device matches all criteria
+`, out)
+
+	out, err = runCommand(t, runExplain, "-all")
+	require.NoError(t, err)
+	assert.Equal(t, `RULE         TAINT                                    DRIVER              POOL  DEVICE  MATCH  REASON
+maintenance  example.com/maintenance=true:NoSchedule  driver.example.com  pool  dev-0   false  selector #0 does not match
+maintenance  example.com/maintenance=true:NoSchedule  driver.example.com  pool  dev-1   true   device matches all criteria
+maintenance  example.com/maintenance=true:NoSchedule  other.example.com   pool  dev-0   false  driver is not driver.example.com
+`, out)
+
+	_, err = runCommand(t, runExplain, "-rule", "no-such-rule")
+	require.ErrorContains(t, err, `DeviceTaintRule "no-such-rule" not found`)
+}
+
+func TestValidate(t *testing.T) {
+	out, err := runCommand(t, runValidate)
+	require.ErrorIs(t, err, errSilent)
+	assert.Equal(t, `deviceclass/gpu selector #0: OK
+devicetaintrule/maintenance selector #0: OK
+resourceclaim/default/pending request req selector #0: Error: must evaluate to bool or the unknown type, not map(string, google.protobuf.Any)
+`, out)
+
+	out, err = runCommand(t, runValidate, "true", `device.driver == "`+driver+`"`)
+	require.NoError(t, err)
+	assert.Equal(t, `argument #0: OK
+argument #1: OK
+`, out)
+}
+
+func TestClaims(t *testing.T) {
+	out, err := runCommand(t, runClaims)
+	require.NoError(t, err)
+	assert.Equal(t, `NAMESPACE  NAME     STATE     NODE    RESERVED FOR  DEVICES
+default    pending  pending   <none>  <none>        <none>
+default    running  reserved  worker  pods/pod      req:driver.example.com/pool/dev-1
+`, out)
+
+	out, err = runCommand(t, runClaims, "-node", "worker")
+	require.NoError(t, err)
+	assert.Equal(t, `NAMESPACE  NAME     STATE     NODE    RESERVED FOR  DEVICES
+default    running  reserved  worker  pods/pod      req:driver.example.com/pool/dev-1
+`, out)
+}
+
+func TestUsage(t *testing.T) {
+	out, err := runCommand(t, runUsage)
+	require.NoError(t, err)
+	assert.Equal(t, `NODE    DRIVER              DEVICES  ALLOCATED  FREE
+other   other.example.com   1        0          1
+worker  driver.example.com  2        1          1
+`, out)
+}
+
+func TestRun(t *testing.T) {
+	_, ctx := ktesting.NewTestContext(t)
+	var out, errOut bytes.Buffer
+	require.ErrorIs(t, run(ctx, nil, &out, &errOut), errSilent)
+	assert.Contains(t, errOut.String(), "Usage: draadm")
+	require.ErrorContains(t, run(ctx, []string{"no-such-command"}, &out, &errOut), `unknown command "no-such-command"`)
+	require.NoError(t, run(ctx, []string{"validate", "-h"}, &out, &errOut))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	resourcealphaapi "k8s.io/api/resource/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/dynamic-resource-allocation/api/convert"
+	"k8s.io/dynamic-resource-allocation/cel"
+	"k8s.io/dynamic-resource-allocation/resourceslice/tracker"
+)
+
+// runExplain shows for each DeviceTaintRule which devices it taints.
+// With -all, it also shows why the other devices are not tainted.
+func runExplain(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	ruleName := fs.String("rule", "", "only explain this DeviceTaintRule")
+	all := fs.Bool("all", false, "also show devices which are not tainted by a rule")
+	if err := parseFlags(fs, e, args, false); err != nil {
+		return err
+	}
+	_, dra, err := e.draClient()
+	if err != nil {
+		return err
+	}
+	if !dra.ServesDeviceTaintRules() {
+		return errors.New("the cluster does not serve DeviceTaintRules")
+	}
+
+	rules, err := dra.DeviceTaintRules().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list DeviceTaintRules: %w", err)
+	}
+	resourceSlices, err := dra.ResourceSlices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list ResourceSlices: %w", err)
+	}
+	classList, err := dra.DeviceClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list DeviceClasses: %w", err)
+	}
+	classes := make(map[string]*resourceapi.DeviceClass, len(classList.Items))
+	for i := range classList.Items {
+		classes[classList.Items[i].Name] = &classList.Items[i]
+	}
+
+	found := *ruleName == ""
+	m := matcher{celCache: cel.NewCache(10, cel.Features{}), classes: classes}
+	slices.SortFunc(resourceSlices.Items, func(a, b resourceapi.ResourceSlice) int {
+		return cmp.Or(
+			cmp.Compare(a.Spec.Driver, b.Spec.Driver),
+			cmp.Compare(a.Spec.Pool.Name, b.Spec.Pool.Name),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	table := newTable(e.out, "RULE", "TAINT", "DRIVER", "POOL", "DEVICE", "MATCH", "REASON")
+	for i := range rules.Items {
+		rule := &rules.Items[i]
+		if *ruleName != "" && rule.Name != *ruleName {
+			continue
+		}
+		found = true
+		taint := formatTaints([]resourceapi.DeviceTaint{convert.DeviceTaintFromV1Alpha3(rule.Spec.Taint)})
+		for j := range resourceSlices.Items {
+			slice := &resourceSlices.Items[j]
+			for k := range slice.Spec.Devices {
+				device := &slice.Spec.Devices[k]
+				matches, reason := m.match(ctx, rule, slice, device)
+				if !matches && !*all {
+					continue
+				}
+				table.row(rule.Name, taint, slice.Spec.Driver, slice.Spec.Pool.Name, device.Name, fmt.Sprintf("%t", matches), reason)
+			}
+		}
+	}
+	if err := table.flush(); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("DeviceTaintRule %q not found", *ruleName)
+	}
+	return nil
+}
+
+// matcher checks DeviceTaintRules against devices the same way as
+// [k8s.io/dynamic-resource-allocation/resourceslice/tracker.Tracker]
+// when it patches ResourceSlices, but explains the outcome.
+type matcher struct {
+	celCache *cel.Cache
+	classes  map[string]*resourceapi.DeviceClass
+}
+
+func (m matcher) match(ctx context.Context, rule *resourcealphaapi.DeviceTaintRule, slice *resourceapi.ResourceSlice, device *resourceapi.Device) (bool, string) {
+	sliceSelector, err := tracker.SliceSelector(rule)
+	if err != nil {
+		return false, err.Error()
+	}
+	if !sliceSelector.Matches(labels.Set(slice.Labels)) {
+		return false, fmt.Sprintf("slice labels do not match %s", sliceSelector)
+	}
+	expires, err := tracker.TaintExpiry(rule)
+	if err != nil {
+		return false, err.Error()
+	}
+	if !expires.IsZero() && !expires.After(time.Now()) {
+		return false, fmt.Sprintf("rule expired at %s", expires.Format(time.RFC3339))
+	}
+	selector := rule.Spec.DeviceSelector
+	if selector == nil {
+		return true, "rule has no device selector"
+	}
+	if selector.Driver != nil && *selector.Driver != slice.Spec.Driver {
+		return false, fmt.Sprintf("driver is not %s", *selector.Driver)
+	}
+	if selector.Pool != nil && *selector.Pool != slice.Spec.Pool.Name {
+		return false, fmt.Sprintf("pool is not %s", *selector.Pool)
+	}
+	if selector.Device != nil && *selector.Device != device.Name {
+		return false, fmt.Sprintf("device is not %s", *selector.Device)
+	}
+	input := cel.Device{Driver: slice.Spec.Driver, Attributes: device.Attributes, Capacity: device.Capacity, Taints: device.Taints}
+	if selector.DeviceClassName != nil {
+		class := m.classes[*selector.DeviceClassName]
+		if class == nil {
+			return false, fmt.Sprintf("DeviceClass %s does not exist", *selector.DeviceClassName)
+		}
+		for i, classSelector := range class.Spec.Selectors {
+			if classSelector.CEL == nil {
+				continue
+			}
+			if matches, reason := m.matchCEL(ctx, classSelector.CEL.Expression, input); !matches {
+				return false, fmt.Sprintf("DeviceClass %s selector #%d %s", class.Name, i, reason)
+			}
+		}
+	}
+	for i, ruleSelector := range selector.Selectors {
+		if ruleSelector.CEL == nil {
+			continue
+		}
+		if matches, reason := m.matchCEL(ctx, ruleSelector.CEL.Expression, input); !matches {
+			return false, fmt.Sprintf("selector #%d %s", i, reason)
+		}
+	}
+	return true, "device matches all criteria"
+}
+
+func (m matcher) matchCEL(ctx context.Context, expression string, input cel.Device) (bool, string) {
+	expr := m.celCache.GetOrCompile(expression)
+	if expr.Error != nil {
+		// The tracker does not patch the entire slice in this case.
+		return false, fmt.Sprintf("failed to compile: %v", expr.Error)
+	}
+	matches, _, err := expr.DeviceMatches(ctx, input)
+	if err != nil {
+		return false, fmt.Sprintf("failed: %v", err)
+	}
+	if !matches {
+		return false, "does not match"
+	}
+	return true, ""
+}
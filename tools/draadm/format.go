@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// none is shown for empty cells, like kubectl does.
+	none = "<none>"
+	// allNodes is shown as node for devices which are available on all nodes.
+	allNodes = "<all>"
+	// nodeSelector is shown as node for devices which are available on
+	// the nodes matched by a node selector.
+	nodeSelector = "<selector>"
+)
+
+// table writes tab-separated rows as aligned columns.
+type table struct {
+	w *tabwriter.Writer
+}
+
+func newTable(out io.Writer, header ...string) *table {
+	t := &table{w: tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)}
+	t.row(header...)
+	return t
+}
+
+func (t *table) row(cells ...string) {
+	for i, cell := range cells {
+		if cell == "" {
+			cells[i] = none
+		}
+	}
+	fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+}
+
+func (t *table) flush() error {
+	return t.w.Flush()
+}
+
+// formatTaints formats taints as "<key>=<value>:<effect>", like kubectl
+// does for node taints.
+func formatTaints(taints []resourceapi.DeviceTaint) string {
+	formatted := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		s := taint.Key
+		if taint.Value != "" {
+			s += "=" + taint.Value
+		}
+		formatted = append(formatted, s+":"+string(taint.Effect))
+	}
+	return strings.Join(formatted, ",")
+}
+
+// deviceNode determines where a device is available.
+func deviceNode(slice *resourceapi.ResourceSlice, device *resourceapi.Device) string {
+	if ptr.Deref(slice.Spec.PerDeviceNodeSelection, false) {
+		return nodeName(device.NodeName, device.AllNodes, device.NodeSelector)
+	}
+	return nodeName(slice.Spec.NodeName, slice.Spec.AllNodes, slice.Spec.NodeSelector)
+}
+
+func nodeName(name *string, all *bool, selector *v1.NodeSelector) string {
+	switch {
+	case ptr.Deref(name, "") != "":
+		return *name
+	case ptr.Deref(all, false):
+		return allNodes
+	case selector != nil:
+		return singleNode(selector)
+	default:
+		return ""
+	}
+}
+
+// singleNode returns the node name if the selector matches exactly one
+// node by name, as the allocator does for node-local devices.
+func singleNode(selector *v1.NodeSelector) string {
+	if len(selector.NodeSelectorTerms) == 1 {
+		term := selector.NodeSelectorTerms[0]
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 1 {
+			field := term.MatchFields[0]
+			if field.Key == "metadata.name" && field.Operator == v1.NodeSelectorOpIn && len(field.Values) == 1 {
+				return field.Values[0]
+			}
+		}
+	}
+	return nodeSelector
+}
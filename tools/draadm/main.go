@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command draadm inspects the dynamic resource allocation state of a
+// cluster. In contrast to kubectl, it shows the information which is
+// derived from several objects:
+//
+//	draadm slices    ResourceSlices with the taints from DeviceTaintRules applied
+//	draadm explain   which DeviceTaintRules match which devices and why
+//	draadm validate  lint CEL selector expressions, from the command line or the cluster
+//	draadm claims    ResourceClaims with their allocated devices
+//	draadm usage     allocated and free devices and counters per node and driver
+//
+// All commands besides "validate" with expressions as arguments need
+// access to a cluster, configured the same way as for kubectl.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	draclient "k8s.io/dynamic-resource-allocation/client"
+	"k8s.io/klog/v2"
+)
+
+// env is what commands need to run. It gets replaced in tests.
+type env struct {
+	out    io.Writer
+	errOut io.Writer
+	// newClient gets called only by commands which need a cluster.
+	newClient func() (kubernetes.Interface, error)
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, e env, args []string) error
+}
+
+var commands = []command{
+	{name: "slices", summary: "list devices with the taints from DeviceTaintRules applied", run: runSlices},
+	{name: "explain", summary: "explain which DeviceTaintRules match which devices", run: runExplain},
+	{name: "validate", summary: "lint CEL selector expressions", run: runValidate},
+	{name: "claims", summary: "list ResourceClaims with their allocated devices", run: runClaims},
+	{name: "usage", summary: "summarize allocated devices and counters per node and driver", run: runUsage},
+}
+
+// errSilent is returned by commands which already reported the problem
+// in their output. main then only sets the exit code.
+var errSilent = errors.New("command failed")
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+	if err != nil {
+		if !errors.Is(err, errSilent) {
+			fmt.Fprintf(os.Stderr, "draadm: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags and invokes the command.
+func run(ctx context.Context, args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("draadm", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	kubeconfig := fs.String("kubeconfig", "", "path to the kubeconfig file, the default is the same as for kubectl")
+	kubeContext := fs.String("context", "", "name of the kubeconfig context to use")
+	klog.InitFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(errOut, "Usage: draadm [global flags] <command> [flags] [args]\n\nCommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(errOut, "  %-10s %s\n", cmd.name, cmd.summary)
+		}
+		fmt.Fprintf(errOut, "\nGlobal flags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errSilent
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errSilent
+	}
+
+	e := env{
+		out:    out,
+		errOut: errOut,
+		newClient: func() (kubernetes.Interface, error) {
+			return newClient(*kubeconfig, *kubeContext)
+		},
+	}
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			err := cmd.run(ctx, e, fs.Args()[1:])
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+	names := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+	return fmt.Errorf("unknown command %q, must be one of %s", name, strings.Join(names, ", "))
+}
+
+// newClient loads the kubeconfig with the same rules as kubectl.
+func newClient(kubeconfig, kubeContext string) (kubernetes.Interface, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	config.UserAgent = "draadm"
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+	return client, nil
+}
+
+// draClient returns the client set and a client for the version of the
+// resource.k8s.io API which is served by the cluster.
+func (e env) draClient() (kubernetes.Interface, *draclient.Client, error) {
+	client, err := e.newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	dra, err := draclient.NewWithDiscovery(client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, dra, nil
+}
+
+// parseFlags parses the flags of a command. Positional arguments are
+// rejected unless the command accepts them.
+func parseFlags(fs *flag.FlagSet, e env, args []string, allowArgs bool) error {
+	fs.SetOutput(e.errOut)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		// Already reported by the flag set.
+		return errSilent
+	}
+	if !allowArgs && fs.NArg() > 0 {
+		return fmt.Errorf("%s: unexpected arguments: %s", fs.Name(), strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
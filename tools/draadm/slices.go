@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/dynamic-resource-allocation/featuregates"
+	"k8s.io/dynamic-resource-allocation/resourceslice/tracker"
+)
+
+// runSlices lists all devices with the taints that they have according
+// to the ResourceSlice and those which get added by DeviceTaintRules.
+// The patched slices are computed by the same [tracker.Tracker] as in
+// the scheduler. DeviceTaintRules are ignored when the cluster does not
+// serve them.
+func runSlices(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("slices", flag.ContinueOnError)
+	node := fs.String("node", "", "only show devices which are or may be available on this node")
+	driver := fs.String("driver", "", "only show devices of this driver")
+	if err := parseFlags(fs, e, args, false); err != nil {
+		return err
+	}
+	client, dra, err := e.draClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	defer informerFactory.Shutdown()
+	defer cancel()
+	sliceInformer := informerFactory.Resource().V1().ResourceSlices()
+	// KubeClient is not set because the tracker must not emit
+	// events on behalf of this tool.
+	t, err := tracker.StartTracker(ctx, tracker.Options{
+		Features:      featuregates.Features{DeviceTaints: dra.ServesDeviceTaintRules()},
+		SliceInformer: sliceInformer,
+		TaintInformer: informerFactory.Resource().V1alpha3().DeviceTaintRules(),
+		ClassInformer: informerFactory.Resource().V1().DeviceClasses(),
+	})
+	if err != nil {
+		return fmt.Errorf("start tracker: %w", err)
+	}
+	defer t.Stop()
+	informerFactory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), t.HasSynced) {
+		return fmt.Errorf("sync ResourceSlices: %w", context.Cause(ctx))
+	}
+	patchedSlices, err := t.ListPatchedResourceSlices()
+	if err != nil {
+		return fmt.Errorf("list patched ResourceSlices: %w", err)
+	}
+
+	type row struct {
+		node, driver, pool, device string
+		deviceTaints, ruleTaints   []resourceapi.DeviceTaint
+	}
+	var rows []row
+	for _, patched := range patchedSlices {
+		if *driver != "" && patched.Spec.Driver != *driver {
+			continue
+		}
+		// The tracker appends the taints of rules after
+		// the taints from the ResourceSlice.
+		original, err := sliceInformer.Lister().Get(patched.Name)
+		if err != nil {
+			// Removed in the meantime.
+			continue
+		}
+		for i := range patched.Spec.Devices {
+			device := &patched.Spec.Devices[i]
+			deviceNode := deviceNode(patched, device)
+			if *node != "" && deviceNode != *node && deviceNode != allNodes && deviceNode != nodeSelector {
+				continue
+			}
+			numOriginalTaints := 0
+			if i < len(original.Spec.Devices) {
+				numOriginalTaints = min(len(original.Spec.Devices[i].Taints), len(device.Taints))
+			}
+			rows = append(rows, row{
+				node:         deviceNode,
+				driver:       patched.Spec.Driver,
+				pool:         patched.Spec.Pool.Name,
+				device:       device.Name,
+				deviceTaints: device.Taints[:numOriginalTaints],
+				ruleTaints:   device.Taints[numOriginalTaints:],
+			})
+		}
+	}
+	slices.SortFunc(rows, func(a, b row) int {
+		return cmp.Or(
+			cmp.Compare(a.node, b.node),
+			cmp.Compare(a.driver, b.driver),
+			cmp.Compare(a.pool, b.pool),
+			cmp.Compare(a.device, b.device),
+		)
+	})
+
+	table := newTable(e.out, "NODE", "DRIVER", "POOL", "DEVICE", "DEVICE TAINTS", "RULE TAINTS")
+	for _, r := range rows {
+		table.row(r.node, r.driver, r.pool, r.device, formatTaints(r.deviceTaints), formatTaints(r.ruleTaints))
+	}
+	return table.flush()
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/dynamic-resource-allocation/cel"
+)
+
+// expression is a CEL selector expression and where it came from.
+type expression struct {
+	source     string
+	expression string
+}
+
+// runValidate lints the expressions given as arguments. Without arguments,
+// it lints all selector expressions in DeviceClasses, DeviceTaintRules,
+// ResourceClaimTemplates and ResourceClaims of the cluster.
+func runValidate(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	compatibilityVersion := fs.String("compatibility-version", "", "oldest Kubernetes version <major>.<minor> which has to accept the expressions, the default is the version of this tool")
+	consumableCapacity := fs.Bool("consumable-capacity", false, "enable the DRAConsumableCapacity feature")
+	if err := parseFlags(fs, e, args, true); err != nil {
+		return err
+	}
+	var options cel.Options
+	if *compatibilityVersion != "" {
+		v, err := version.ParseMajorMinor(*compatibilityVersion)
+		if err != nil {
+			return fmt.Errorf("parse -compatibility-version: %w", err)
+		}
+		options.CompatibilityVersion = v
+	}
+
+	var expressions []expression
+	if fs.NArg() > 0 {
+		for i, arg := range fs.Args() {
+			expressions = append(expressions, expression{source: fmt.Sprintf("argument #%d", i), expression: arg})
+		}
+	} else {
+		var err error
+		expressions, err = clusterExpressions(ctx, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	compiler := cel.GetCompiler(cel.Features{EnableConsumableCapacity: *consumableCapacity})
+	numInvalid := 0
+	for _, expr := range expressions {
+		diagnostics := compiler.Lint(expr.expression, options)
+		if len(diagnostics) == 0 {
+			fmt.Fprintf(e.out, "%s: OK\n", expr.source)
+			continue
+		}
+		invalid := false
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintf(e.out, "%s: %s\n", expr.source, diagnostic)
+			invalid = invalid || diagnostic.Severity == cel.SeverityError
+		}
+		if invalid {
+			numInvalid++
+		}
+	}
+	if numInvalid > 0 {
+		fmt.Fprintf(e.errOut, "%d of %d expressions are invalid\n", numInvalid, len(expressions))
+		return errSilent
+	}
+	return nil
+}
+
+// clusterExpressions collects the selector expressions from the cluster.
+func clusterExpressions(ctx context.Context, e env) ([]expression, error) {
+	_, dra, err := e.draClient()
+	if err != nil {
+		return nil, err
+	}
+	var expressions []expression
+	add := func(source string, selectors []resourceapi.DeviceSelector) {
+		for i, selector := range selectors {
+			if selector.CEL != nil {
+				expressions = append(expressions, expression{source: fmt.Sprintf("%s selector #%d", source, i), expression: selector.CEL.Expression})
+			}
+		}
+	}
+	addRequests := func(source string, spec resourceapi.ResourceClaimSpec) {
+		for _, request := range spec.Devices.Requests {
+			if request.Exactly != nil {
+				add(fmt.Sprintf("%s request %s", source, request.Name), request.Exactly.Selectors)
+			}
+			for _, subRequest := range request.FirstAvailable {
+				add(fmt.Sprintf("%s request %s/%s", source, request.Name, subRequest.Name), subRequest.Selectors)
+			}
+		}
+	}
+
+	classes, err := dra.DeviceClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list DeviceClasses: %w", err)
+	}
+	for _, class := range classes.Items {
+		add("deviceclass/"+class.Name, class.Spec.Selectors)
+	}
+
+	if dra.ServesDeviceTaintRules() {
+		rules, err := dra.DeviceTaintRules().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list DeviceTaintRules: %w", err)
+		}
+		for _, rule := range rules.Items {
+			if rule.Spec.DeviceSelector == nil {
+				continue
+			}
+			for i, selector := range rule.Spec.DeviceSelector.Selectors {
+				if selector.CEL != nil {
+					expressions = append(expressions, expression{source: fmt.Sprintf("devicetaintrule/%s selector #%d", rule.Name, i), expression: selector.CEL.Expression})
+				}
+			}
+		}
+	}
+
+	templates, err := dra.ResourceClaimTemplates("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list ResourceClaimTemplates: %w", err)
+	}
+	for _, template := range templates.Items {
+		addRequests(fmt.Sprintf("resourceclaimtemplate/%s/%s", template.Namespace, template.Name), template.Spec.Spec)
+	}
+
+	claims, err := dra.ResourceClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list ResourceClaims: %w", err)
+	}
+	for _, claim := range claims.Items {
+		// Claims generated from a template repeat its expressions.
+		if owner := metav1.GetControllerOf(&claim); owner != nil && owner.APIVersion == "v1" && owner.Kind == "Pod" {
+			continue
+		}
+		addRequests(fmt.Sprintf("resourceclaim/%s/%s", claim.Namespace, claim.Name), claim.Spec)
+	}
+	return expressions, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing defines the OpenTelemetry span attributes which are
+// shared by the packages in this module. Spans from different components
+// can be correlated through these attributes, for example by searching
+// for all spans with a certain device in [DevicesKey]:
+//
+//	resourceslice   "PublishPool" span per synchronized pool (optional, see Options.TracerProvider)
+//	tracker         "PatchResourceSlice" span per slice with taints (optional, see Options.TracerProvider)
+//	structured      "Allocate" span, a child of the span in the context (if any)
+//	kubeletplugin   span per NodePrepareResources and NodeUnprepareResources call
+//
+// Allocation and the kubelet plugin calls become part of the caller's
+// trace. Publishing and patching are triggered by events which are
+// unrelated to a particular pod, so their spans start new traces.
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+const (
+	// ClaimUIDsKey lists the UIDs of the ResourceClaims handled by an operation.
+	ClaimUIDsKey = attribute.Key("dra.claim.uids")
+	// ClaimFailuresKey is the number of ResourceClaims for which an operation failed.
+	ClaimFailuresKey = attribute.Key("dra.claim.failures")
+	// DevicesKey lists devices in the format returned by [DeviceID].
+	DevicesKey = attribute.Key("dra.devices")
+	// DriverKey is the name of the DRA driver.
+	DriverKey = attribute.Key("dra.driver")
+	// PoolKey is the name of a resource pool.
+	PoolKey = attribute.Key("dra.pool")
+	// NodeKey is the name of a node.
+	NodeKey = attribute.Key("dra.node")
+	// ResourceSliceKey is the name of a ResourceSlice.
+	ResourceSliceKey = attribute.Key("dra.resourceslice")
+	// AllocationResultKey is "success", "unschedulable" or "error",
+	// like the result label of the allocator metrics.
+	AllocationResultKey = attribute.Key("dra.allocation.result")
+)
+
+// DeviceID returns "<driver>/<pool>/<device>", the same format as
+// the String method of the DeviceID type in the api package.
+func DeviceID(driver, pool, device string) string {
+	return driver + "/" + pool + "/" + device
+}
+
+// ClaimUIDs returns a [ClaimUIDsKey] attribute for the claims.
+func ClaimUIDs(claims []*resourceapi.ResourceClaim) attribute.KeyValue {
+	uids := make([]string, 0, len(claims))
+	for _, claim := range claims {
+		uids = append(uids, string(claim.UID))
+	}
+	return ClaimUIDsKey.StringSlice(uids)
+}
+
+// AllocatedDevices returns a [DevicesKey] attribute for all devices
+// in the allocation results.
+func AllocatedDevices(results []resourceapi.AllocationResult) attribute.KeyValue {
+	var devices []string
+	for _, result := range results {
+		for _, device := range result.Devices.Results {
+			devices = append(devices, DeviceID(device.Driver, device.Pool, device.Device))
+		}
+	}
+	return DevicesKey.StringSlice(devices)
+}